@@ -0,0 +1,266 @@
+package ceremony
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// ErrDisconnected is returned by the HSM steps when no connection is open.
+var ErrDisconnected = errors.New("not connected to hsm")
+
+// auditLog records ceremony steps. Like the crypto audit entries it never
+// carries key material.
+var auditLog = logger.WithModule("ceremony")
+
+// Plan describes the key a ceremony produces.
+type Plan struct {
+	KeyBits    int // 64, 128 or 192.
+	Components int // Number of custodians, at most what A4 can combine.
+	OddParity  bool
+}
+
+// Validate checks that the key and its components can go through every
+// ceremony step, including forming the key in the HSM.
+func (p Plan) Validate() error {
+	switch p.KeyBits {
+	case crypto.KeyLength64, crypto.KeyLength128, crypto.KeyLength192:
+	default:
+		return fmt.Errorf("key length must be 64, 128 or 192 bits, got %d", p.KeyBits)
+	}
+	if p.Components < utils.MinA4Components || p.Components > utils.MaxA4Components {
+		return fmt.Errorf(
+			"number of components must be between %d and %d, got %d",
+			utils.MinA4Components,
+			utils.MaxA4Components,
+			p.Components,
+		)
+	}
+
+	return nil
+}
+
+// Ceremony carries the outputs of each step into the next. It never holds
+// the clear key: Prepare drops it as soon as the components exist, and
+// Export drops the components once their mailers are written.
+type Ceremony struct {
+	Plan
+	KCV      string   // Check value of the combined key.
+	Mailers  []Mailer // One per component; components are cleared on Export.
+	Exported bool
+
+	// Set by Import and Verify.
+	KeyType  string
+	Key      utils.A0Response
+	Verified bool
+}
+
+// Prepare generates a key locally, splits it into components and builds
+// their mailers dated date.
+func Prepare(p Plan, date time.Time) (*Ceremony, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	components, kcv, err := generateComponents(p)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Ceremony{Plan: p, KCV: strings.ToUpper(kcv), Mailers: make([]Mailer, len(components))}
+	for i, comp := range components {
+		c.Mailers[i] = Mailer{
+			Index:        i + 1,
+			Total:        len(components),
+			Component:    comp,
+			ComponentKCV: crypto.CheckValueOf(comp),
+			CombinedKCV:  c.KCV,
+			KeyBits:      p.KeyBits,
+			Date:         date,
+		}
+	}
+
+	return c, nil
+}
+
+// generateComponents generates a key and returns its components and check
+// value. The clear key never leaves this function.
+func generateComponents(p Plan) ([]string, string, error) {
+	keyHex, kcv, err := crypto.GenerateKey(p.KeyBits, p.OddParity)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate key: %v", err)
+	}
+	crypto.AuditKeyGenerate(p.KeyBits, p.OddParity, kcv)
+
+	components, _, err := crypto.SplitKey(keyHex, p.Components)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to split key: %v", err)
+	}
+	crypto.AuditKeySplit(len(components), kcv)
+
+	return components, kcv, nil
+}
+
+// Export writes the mailers into dir, named after pattern, and then forgets
+// the clear components. See WriteMailers for overwrite.
+func (c *Ceremony) Export(dir, pattern string, overwrite bool) ([]string, error) {
+	if c.Exported {
+		return nil, errors.New("components were already exported")
+	}
+
+	paths, err := WriteMailers(dir, pattern, c.Mailers, overwrite)
+	if err != nil {
+		return paths, err
+	}
+	crypto.AuditComponentsExport(len(paths), c.KCV, dir)
+
+	for i := range c.Mailers {
+		c.Mailers[i].Component = ""
+	}
+	c.Exported = true
+
+	return paths, nil
+}
+
+// Import forms the key in the HSM with A4 from components, the custodians'
+// components as encrypted under the LMK when they were entered at the
+// console. The formed key must have the check value of the ceremony key.
+func (c *Ceremony) Import(
+	ctx context.Context,
+	exec sender.Executor,
+	keyType string,
+	scheme byte,
+	components []string,
+) error {
+	if got, want := utils.SchemeKeyLength(scheme), c.KeyBits/8; got != want {
+		return fmt.Errorf("scheme %c holds %d byte keys, the ceremony key is %d bytes", scheme, got, want)
+	}
+	if len(components) != c.Components {
+		return fmt.Errorf("expected %d components, got %d", c.Components, len(components))
+	}
+	cmd, err := utils.A4Request{KeyType: keyType, Scheme: scheme, Components: components}.Command()
+	if err != nil {
+		return err
+	}
+
+	resp, err := execute(ctx, exec, cmd)
+	if err != nil {
+		return err
+	}
+	key, err := utils.ParseA4Response(resp, scheme)
+	if err != nil {
+		return fmt.Errorf("failed to form key: %v", err)
+	}
+	if !utils.CheckValuesMatch(key.CheckValue, c.KCV) {
+		audit("key_ceremony_import", "Failure", c.KCV, keyType)
+		return fmt.Errorf("formed key check value %s does not match %s", key.CheckValue, c.KCV)
+	}
+
+	c.KeyType = strings.ToUpper(keyType)
+	c.Key = key
+	c.Verified = false
+	audit("key_ceremony_import", "Success", c.KCV, c.KeyType)
+
+	return nil
+}
+
+// Verify checks the imported key with BU against the ceremony check value.
+func (c *Ceremony) Verify(ctx context.Context, exec sender.Executor) error {
+	if c.Key.Cryptogram == "" {
+		return errors.New("no imported key to verify")
+	}
+	cmd, err := utils.BuildBUCommand(c.KeyType, c.Key.Cryptogram)
+	if err != nil {
+		return err
+	}
+
+	resp, err := execute(ctx, exec, cmd)
+	if err != nil {
+		return err
+	}
+	kcv, err := utils.ParseBUResponse(resp)
+	if err != nil {
+		return fmt.Errorf("failed to verify key: %v", err)
+	}
+	if !utils.CheckValuesMatch(kcv, c.KCV) {
+		audit("key_ceremony_verify", "Failure", c.KCV, c.KeyType)
+		return fmt.Errorf("hsm check value %s does not match %s", kcv, c.KCV)
+	}
+
+	c.Verified = true
+	audit("key_ceremony_verify", "Success", c.KCV, c.KeyType)
+
+	return nil
+}
+
+// Entry returns the key store record for the ceremony key named name. An
+// imported key must have been verified and is stored as its cryptogram under
+// the LMK with check value lmkCheckValue; otherwise only the key metadata is
+// kept.
+func (c *Ceremony) Entry(name string, keyType storage.KeyType, lmkCheckValue string) (storage.KeyEntry, error) {
+	if c.Key.Cryptogram == "" {
+		if err := utils.ValidateKeyName(name); err != nil {
+			return storage.KeyEntry{}, err
+		}
+
+		return storage.KeyEntry{
+			Name:       name,
+			Type:       keyType,
+			Length:     c.KeyBits / 8,
+			CheckValue: c.KCV,
+		}, nil
+	}
+	if !c.Verified {
+		return storage.KeyEntry{}, errors.New("imported key has not been verified")
+	}
+
+	return storage.GeneratedKeyEntry(name, keyType, c.Key, lmkCheckValue)
+}
+
+// Record stores the ceremony key in store; see Entry.
+func (c *Ceremony) Record(
+	store *storage.KeyStore,
+	name string,
+	keyType storage.KeyType,
+	lmkCheckValue string,
+) (storage.KeyEntry, error) {
+	entry, err := c.Entry(name, keyType, lmkCheckValue)
+	if err != nil {
+		return entry, err
+	}
+	if err := store.Store(entry); err != nil {
+		return entry, fmt.Errorf("failed to store key: %v", err)
+	}
+	audit("key_ceremony_record", "Success", c.KCV, string(keyType))
+
+	return entry, nil
+}
+
+// execute sends cmd, failing fast when the HSM is not connected.
+func execute(ctx context.Context, exec sender.Executor, cmd string) ([]byte, error) {
+	if !exec.Connected() {
+		return nil, ErrDisconnected
+	}
+	resp, err := exec.ExecuteCommandContext(ctx, []byte(cmd))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send %s: %v", cmd[:2], err)
+	}
+
+	return resp, nil
+}
+
+// audit records a ceremony step for the key with check value kcv.
+func audit(event, status, kcv, keyType string) {
+	auditLog.LogFields(logger.INFO, event, status, "", map[string]string{
+		"kcv":      strings.ToUpper(kcv),
+		"key_type": keyType,
+	})
+}
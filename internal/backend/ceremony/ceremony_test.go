@@ -0,0 +1,243 @@
+// nolint:all // test package
+package ceremony
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// identityHSM answers A4 and BU as an HSM whose LMK leaves keys unchanged, so
+// a component under the LMK is its clear value behind a scheme tag. A4 really
+// combines the components and BU really computes the check value.
+type identityHSM struct {
+	connected bool
+	commands  []string
+	kcv       string // Overrides the computed check values when set.
+}
+
+func (h *identityHSM) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	cmd := string(command)
+	h.commands = append(h.commands, cmd)
+
+	switch cmd[:2] {
+	case "A4":
+		n := int(cmd[2] - '0')
+		body := cmd[7:]
+		size := len(body) / n
+		clear := make([]string, n)
+		for i := range clear {
+			clear[i] = body[i*size+1 : (i+1)*size]
+		}
+		key, err := crypto.CombineComponents(clear)
+		if err != nil {
+			return []byte("A515"), nil
+		}
+
+		return []byte("A500" + string(cmd[6]) + strings.ToUpper(key) + h.checkValue(key)), nil
+	case "BU":
+		key := cmd[6:strings.Index(cmd, ";")]
+
+		return []byte("BV00" + h.checkValue(key)), nil
+	}
+
+	return []byte(cmd[:1] + string(cmd[1]+1) + "68"), nil
+}
+
+func (h *identityHSM) Connected() bool { return h.connected }
+
+func (h *identityHSM) checkValue(keyHex string) string {
+	if h.kcv != "" {
+		return h.kcv
+	}
+
+	return crypto.CheckValueOf(keyHex)
+}
+
+// underLMK returns the clear components of c as the identity LMK encrypts
+// them.
+func underLMK(c *Ceremony) []string {
+	components := make([]string, len(c.Mailers))
+	for i, m := range c.Mailers {
+		components[i] = "U" + strings.ToUpper(m.Component)
+	}
+
+	return components
+}
+
+func TestCeremony_EndToEnd(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+
+	c, err := Prepare(Plan{KeyBits: 128, Components: 3, OddParity: true}, date)
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	if len(c.KCV) != 6 || len(c.Mailers) != 3 {
+		t.Fatalf("Prepare() KCV = %q, %d mailers", c.KCV, len(c.Mailers))
+	}
+	components := make([]string, len(c.Mailers))
+	for i, m := range c.Mailers {
+		if m.Index != i+1 || m.Total != 3 || m.CombinedKCV != c.KCV || m.KeyBits != 128 || !m.Date.Equal(date) {
+			t.Errorf("mailer %d = %+v", i, m)
+		}
+		if m.ComponentKCV != crypto.CheckValueOf(m.Component) {
+			t.Errorf("mailer %d component KCV = %s", i, m.ComponentKCV)
+		}
+		components[i] = m.Component
+	}
+	key, err := crypto.CombineComponents(components)
+	if err != nil {
+		t.Fatalf("CombineComponents() error = %v", err)
+	}
+	if got := crypto.CheckValueOf(key); got != c.KCV {
+		t.Fatalf("components combine to KCV %s, want %s", got, c.KCV)
+	}
+	encrypted := underLMK(c)
+
+	paths, err := c.Export(dir, "", false)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(paths) != 3 || !c.Exported {
+		t.Fatalf("Export() = %v, exported %v", paths, c.Exported)
+	}
+	for i, m := range c.Mailers {
+		if m.Component != "" {
+			t.Errorf("mailer %d still holds its component after Export", i)
+		}
+		data, err := os.ReadFile(paths[i])
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if !strings.Contains(strings.ReplaceAll(string(data), " ", ""), strings.ToUpper(components[i])) {
+			t.Errorf("mailer %d does not show its component", i)
+		}
+	}
+	if _, err := c.Export(dir, "", true); err == nil {
+		t.Error("second Export() succeeded")
+	}
+
+	hsm := &identityHSM{connected: true}
+	if err := c.Import(context.Background(), hsm, "000", 'U', encrypted); err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if c.Key.Cryptogram != "U"+strings.ToUpper(key) || c.KeyType != "000" {
+		t.Fatalf("Import() key = %+v, type %q", c.Key, c.KeyType)
+	}
+	if want := "A43000U" + strings.Join(encrypted, ""); hsm.commands[0] != want {
+		t.Errorf("A4 command = %q, want %q", hsm.commands[0], want)
+	}
+	if _, err := c.Entry("zmk-1", storage.ZMK, "268604"); err == nil {
+		t.Error("Entry() accepted an unverified key")
+	}
+
+	if err := c.Verify(context.Background(), hsm); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if want := "BUFF1" + c.Key.Cryptogram + ";000"; hsm.commands[1] != want {
+		t.Errorf("BU command = %q, want %q", hsm.commands[1], want)
+	}
+
+	store, err := storage.NewKeyStore(filepath.Join(dir, "keys.json"))
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+	entry, err := c.Record(store, "zmk-1", storage.ZMK, "268604")
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	got, ok := store.Get("zmk-1")
+	if !ok {
+		t.Fatal("Record() did not store the key")
+	}
+	if got.Value != c.Key.Cryptogram || got.CheckValue != c.KCV || got.Length != 16 ||
+		got.Type != storage.ZMK || got.LMKCheckValue != "268604" || entry.Name != "zmk-1" {
+		t.Errorf("stored entry = %+v", got)
+	}
+}
+
+func TestCeremony_WithoutImport(t *testing.T) {
+	c, err := Prepare(Plan{KeyBits: 64, Components: 2}, time.Now())
+	if err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+
+	entry, err := c.Entry("pin-key", storage.PVK, "")
+	if err != nil {
+		t.Fatalf("Entry() error = %v", err)
+	}
+	if entry.Value != "" || entry.CheckValue != c.KCV || entry.Length != 8 {
+		t.Errorf("Entry() = %+v, want metadata only", entry)
+	}
+	if _, err := c.Entry("bad name!", storage.PVK, ""); err == nil {
+		t.Error("Entry() accepted an invalid name")
+	}
+	if err := c.Verify(context.Background(), &identityHSM{connected: true}); err == nil {
+		t.Error("Verify() succeeded without an imported key")
+	}
+}
+
+func TestCeremony_ImportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		hsm     *identityHSM
+		scheme  byte
+		drop    int
+		wantErr string
+	}{
+		{"disconnected", &identityHSM{}, 'U', 0, "not connected"},
+		{"kcv_mismatch", &identityHSM{connected: true, kcv: "000000"}, 'U', 0, "does not match"},
+		{"wrong_scheme", &identityHSM{connected: true}, 'T', 0, "16 bytes"},
+		{"missing_component", &identityHSM{connected: true}, 'U', 1, "expected 2 components"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Prepare(Plan{KeyBits: 128, Components: 2}, time.Now())
+			if err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			components := underLMK(c)
+			err = c.Import(context.Background(), tt.hsm, "000", tt.scheme, components[tt.drop:])
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Import() error = %v, want %q", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrDisconnected) != (tt.name == "disconnected") {
+				t.Errorf("Import() error = %v, ErrDisconnected mismatch", err)
+			}
+			if c.Key.Cryptogram != "" {
+				t.Errorf("Import() kept key %q after failing", c.Key.Cryptogram)
+			}
+		})
+	}
+}
+
+func TestPlan_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    Plan
+		wantErr bool
+	}{
+		{"double_length", Plan{KeyBits: 128, Components: 2}, false},
+		{"nine_components", Plan{KeyBits: 192, Components: 9}, false},
+		{"aes_256", Plan{KeyBits: 256, Components: 2}, true},
+		{"one_component", Plan{KeyBits: 128, Components: 1}, true},
+		{"ten_components", Plan{KeyBits: 128, Components: 10}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.plan.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
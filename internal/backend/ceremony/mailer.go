@@ -0,0 +1,112 @@
+// Package ceremony runs offline key ceremonies and produces the paperwork
+// handed to key component custodians.
+package ceremony
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// DefaultFilePattern names mailer files. {n} is replaced by the component
+// number and {total} by the number of components.
+const DefaultFilePattern = "component_{n}_of_{total}.txt"
+
+// ErrFilesExist is returned by WriteMailers when target files already exist
+// and overwriting was not allowed.
+var ErrFilesExist = errors.New("mailer files already exist")
+
+// Mailer describes one key component handed to a custodian. It never holds
+// the combined key itself.
+type Mailer struct {
+	Index        int    // 1-based component number.
+	Total        int    // Number of components the key was split into.
+	Component    string // Component value in hex.
+	ComponentKCV string
+	CombinedKCV  string
+	KeyBits      int
+	Date         time.Time
+}
+
+// FileName expands pattern for component n of total.
+func FileName(pattern string, n, total int) string {
+	return strings.NewReplacer(
+		"{n}", strconv.Itoa(n),
+		"{total}", strconv.Itoa(total),
+	).Replace(pattern)
+}
+
+// Render returns the printable mailer text.
+func (m Mailer) Render() string {
+	component, err := utils.FormatHex(m.Component, 4, " ")
+	if err != nil {
+		component = strings.ToUpper(m.Component)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "KEY COMPONENT %d OF %d\n", m.Index, m.Total)
+	b.WriteString(strings.Repeat("=", 40) + "\n\n")
+	fmt.Fprintf(&b, "Date:           %s\n", m.Date.Format("2006-01-02"))
+	fmt.Fprintf(&b, "Key length:     %d bits\n", m.KeyBits)
+	fmt.Fprintf(&b, "Component:      %s\n", component)
+	fmt.Fprintf(&b, "Component KCV:  %s\n", strings.ToUpper(m.ComponentKCV))
+	fmt.Fprintf(&b, "Combined KCV:   %s\n\n", strings.ToUpper(m.CombinedKCV))
+	b.WriteString("Custodian\n")
+	b.WriteString("Name:       ______________________________\n\n")
+	b.WriteString("Signature:  ______________________________\n\n")
+	b.WriteString("Date:       ______________________________\n")
+
+	return b.String()
+}
+
+// ExistingFiles returns the paths in dir that WriteMailers would overwrite.
+func ExistingFiles(dir, pattern string, total int) []string {
+	var existing []string
+	for n := 1; n <= total; n++ {
+		path := filepath.Join(dir, FileName(pattern, n, total))
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+
+	return existing
+}
+
+// WriteMailers writes one file per mailer into dir, named after pattern, and
+// returns the written paths. Unless overwrite is set, nothing is written when
+// any target already exists.
+func WriteMailers(dir, pattern string, mailers []Mailer, overwrite bool) ([]string, error) {
+	if len(mailers) == 0 {
+		return nil, errors.New("no components to export")
+	}
+	if pattern == "" {
+		pattern = DefaultFilePattern
+	}
+	if !strings.Contains(pattern, "{n}") {
+		return nil, fmt.Errorf("file pattern %q must contain {n}", pattern)
+	}
+
+	total := len(mailers)
+	if !overwrite {
+		if existing := ExistingFiles(dir, pattern, total); len(existing) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrFilesExist, strings.Join(existing, ", "))
+		}
+	}
+
+	paths := make([]string, 0, total)
+	for _, m := range mailers {
+		path := filepath.Join(dir, FileName(pattern, m.Index, total))
+		if err := os.WriteFile(path, []byte(m.Render()), 0o600); err != nil {
+			return paths, fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,118 @@
+// nolint:all // test package
+package ceremony
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testMailers() []Mailer {
+	date := time.Date(2026, 3, 14, 0, 0, 0, 0, time.UTC)
+
+	return []Mailer{
+		{1, 2, "0123456789abcdef", "D5D44F", "08D7B4", 64, date},
+		{2, 2, "fedcba9876543210", "A1B2C3", "08D7B4", 64, date},
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		n       int
+		total   int
+		want    string
+	}{
+		{"default", DefaultFilePattern, 1, 3, "component_1_of_3.txt"},
+		{"custom", "zmk-{n}.txt", 2, 2, "zmk-2.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FileName(tt.pattern, tt.n, tt.total); got != tt.want {
+				t.Errorf("FileName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteMailers(t *testing.T) {
+	dir := t.TempDir()
+
+	paths, err := WriteMailers(dir, "", testMailers(), false)
+	if err != nil {
+		t.Fatalf("WriteMailers() error = %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("WriteMailers() wrote %d files, want 2", len(paths))
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "component_1_of_2.txt"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{
+		"KEY COMPONENT 1 OF 2",
+		"0123 4567 89AB CDEF",
+		"Component KCV:  D5D44F",
+		"Combined KCV:   08D7B4",
+		"64 bits",
+		"2026-03-14",
+		"Signature:",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("mailer missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "FEDCBA9876543210") || strings.Contains(text, "FEDC BA98") {
+		t.Error("mailer 1 contains another component")
+	}
+
+	info, err := os.Stat(paths[0])
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+}
+
+func TestWriteMailers_Overwrite(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "component_2_of_2.txt")
+	if err := os.WriteFile(existing, []byte("keep"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := WriteMailers(dir, "", testMailers(), false)
+	if !errors.Is(err, ErrFilesExist) {
+		t.Fatalf("WriteMailers() error = %v, want ErrFilesExist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "component_1_of_2.txt")); !os.IsNotExist(err) {
+		t.Error("WriteMailers() wrote files despite a conflict")
+	}
+
+	if _, err := WriteMailers(dir, "", testMailers(), true); err != nil {
+		t.Fatalf("WriteMailers(overwrite) error = %v", err)
+	}
+	data, _ := os.ReadFile(existing)
+	if string(data) == "keep" {
+		t.Error("existing file not overwritten")
+	}
+}
+
+func TestWriteMailers_InvalidInput(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := WriteMailers(dir, "", nil, false); err == nil {
+		t.Error("WriteMailers(nil) expected error")
+	}
+	if _, err := WriteMailers(dir, "component.txt", testMailers(), false); err == nil {
+		t.Error("WriteMailers() with pattern lacking {n} expected error")
+	}
+}
@@ -0,0 +1,371 @@
+// Package config exports and imports the application configuration:
+// connection profiles, preferences and Command Sender scenarios. Key store
+// contents are never part of it.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// Version is the schema version of exported configurations.
+const Version = 1
+
+// ErrVersion is returned when importing a configuration of another schema
+// version.
+var ErrVersion = errors.New("unsupported configuration version")
+
+// PrefKind is the type of a preference value.
+type PrefKind int
+
+// Preference value types.
+const (
+	PrefInt PrefKind = iota
+	PrefBool
+	PrefString
+	PrefStringList
+)
+
+// Pref describes a preference that is exported. Sensitive preferences are
+// only exported on request.
+type Pref struct {
+	Key       string
+	Kind      PrefKind
+	Sensitive bool
+}
+
+// Preferences is the subset of fyne.Preferences used to read and write
+// exported preferences.
+type Preferences interface {
+	IntWithFallback(key string, fallback int) int
+	SetInt(key string, value int)
+	BoolWithFallback(key string, fallback bool) bool
+	SetBool(key string, value bool)
+	StringWithFallback(key, fallback string) string
+	SetString(key, value string)
+	StringListWithFallback(key string, fallback []string) []string
+	SetStringList(key string, value []string)
+}
+
+// Sources are the stores a configuration is exported from and imported
+// into. Nil stores are skipped.
+type Sources struct {
+	Profiles    *storage.ProfileStore
+	Scenarios   *storage.ScenarioStore
+	Preferences Preferences
+	Prefs       []Pref // Preferences that are exported.
+}
+
+// Bundle is an exported configuration.
+type Bundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Sensitive  bool      `json:"sensitive,omitempty"` // Sensitive items are included.
+
+	Profiles    []storage.ConnectionProfile `json:"profiles,omitempty"`
+	Preferences map[string]json.RawMessage  `json:"preferences,omitempty"`
+	Scenarios   []storage.Scenario          `json:"scenarios,omitempty"`
+}
+
+// Export bundles the configuration in src. Sensitive preferences and
+// scenarios that hold what looks like a clear key are only included when
+// sensitive is set.
+func Export(src Sources, sensitive bool, now time.Time) (Bundle, error) {
+	b := Bundle{Version: Version, ExportedAt: now.UTC(), Sensitive: sensitive}
+
+	if src.Profiles != nil {
+		for _, name := range src.Profiles.List() {
+			if p, ok := src.Profiles.Get(name); ok {
+				b.Profiles = append(b.Profiles, p)
+			}
+		}
+	}
+
+	if src.Scenarios != nil {
+		names, err := src.Scenarios.List()
+		if err != nil {
+			return Bundle{}, err
+		}
+		for _, name := range names {
+			s, err := src.Scenarios.Load(name)
+			if err != nil {
+				return Bundle{}, err
+			}
+			if s.ContainsClearKey() && !sensitive {
+				continue
+			}
+			b.Scenarios = append(b.Scenarios, s)
+		}
+	}
+
+	if src.Preferences != nil {
+		for _, p := range src.Prefs {
+			if p.Sensitive && !sensitive {
+				continue
+			}
+			v, ok := readPref(src.Preferences, p)
+			if !ok {
+				continue
+			}
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return Bundle{}, fmt.Errorf("failed to marshal preference %q: %v", p.Key, err)
+			}
+			if b.Preferences == nil {
+				b.Preferences = make(map[string]json.RawMessage)
+			}
+			b.Preferences[p.Key] = raw
+		}
+	}
+
+	return b, nil
+}
+
+// Marshal encodes b for writing to a file.
+func (b Bundle) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal configuration: %v", err)
+	}
+
+	return data, nil
+}
+
+// Parse decodes an exported configuration and checks its version and names.
+func Parse(data []byte) (Bundle, error) {
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return Bundle{}, fmt.Errorf("failed to parse configuration: %v", err)
+	}
+	if head.Version != Version {
+		return Bundle{}, fmt.Errorf("%w %d: this version reads version %d", ErrVersion, head.Version, Version)
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return Bundle{}, fmt.Errorf("failed to parse configuration: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, p := range b.Profiles {
+		if err := storage.ValidateProfileName(p.Name); err != nil {
+			return Bundle{}, fmt.Errorf("profile %q: %v", p.Name, err)
+		}
+		if seen["p:"+p.Name] {
+			return Bundle{}, fmt.Errorf("profile %q appears twice", p.Name)
+		}
+		seen["p:"+p.Name] = true
+	}
+	for _, s := range b.Scenarios {
+		if err := storage.ValidateScenarioName(s.Name); err != nil {
+			return Bundle{}, fmt.Errorf("scenario %q: %v", s.Name, err)
+		}
+		if seen["s:"+s.Name] {
+			return Bundle{}, fmt.Errorf("scenario %q appears twice", s.Name)
+		}
+		seen["s:"+s.Name] = true
+	}
+
+	return b, nil
+}
+
+// Action is what importing does to an item.
+type Action int
+
+// Import actions.
+const (
+	Add Action = iota
+	Overwrite
+)
+
+// Change is one item an import adds or overwrites.
+type Change struct {
+	Action Action
+	Kind   string // "profile", "scenario" or "preference".
+	Name   string
+}
+
+// String describes c as a diff line.
+func (c Change) String() string {
+	if c.Action == Overwrite {
+		return fmt.Sprintf("~ %s %s (overwrite)", c.Kind, c.Name)
+	}
+
+	return fmt.Sprintf("+ %s %s", c.Kind, c.Name)
+}
+
+// Plan lists what importing b into dst changes. Items identical to the
+// stored ones are left out. Preferences dst does not know are ignored.
+func (b Bundle) Plan(dst Sources) ([]Change, error) {
+	var changes []Change
+
+	if dst.Profiles != nil {
+		for _, p := range b.Profiles {
+			old, ok := dst.Profiles.Get(p.Name)
+			switch {
+			case !ok:
+				changes = append(changes, Change{Add, "profile", p.Name})
+			case !sameJSON(old, p):
+				changes = append(changes, Change{Overwrite, "profile", p.Name})
+			}
+		}
+	}
+
+	if dst.Scenarios != nil {
+		for _, s := range b.Scenarios {
+			if !dst.Scenarios.Exists(s.Name) {
+				changes = append(changes, Change{Add, "scenario", s.Name})
+				continue
+			}
+			old, err := dst.Scenarios.Load(s.Name)
+			if err != nil || !sameJSON(old, s) {
+				changes = append(changes, Change{Overwrite, "scenario", s.Name})
+			}
+		}
+	}
+
+	if dst.Preferences != nil {
+		prefs, err := b.prefs(dst.Prefs)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range dst.Prefs {
+			v, ok := prefs[p.Key]
+			if !ok {
+				continue
+			}
+			old, set := readPref(dst.Preferences, p)
+			switch {
+			case !set:
+				changes = append(changes, Change{Add, "preference", p.Key})
+			case !sameJSON(old, v):
+				changes = append(changes, Change{Overwrite, "preference", p.Key})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// Apply imports b into dst. Scenarios and profiles are either all stored or
+// none is; preferences are set once both stores succeeded.
+func (b Bundle) Apply(dst Sources) error {
+	var prefs map[string]any
+	if dst.Preferences != nil {
+		var err error
+		if prefs, err = b.prefs(dst.Prefs); err != nil {
+			return err
+		}
+	}
+
+	undo := func() error { return nil }
+	if dst.Scenarios != nil && len(b.Scenarios) > 0 {
+		var err error
+		if undo, err = dst.Scenarios.SaveAll(b.Scenarios); err != nil {
+			return err
+		}
+	}
+	if dst.Profiles != nil && len(b.Profiles) > 0 {
+		if err := dst.Profiles.SaveAll(b.Profiles); err != nil {
+			if undoErr := undo(); undoErr != nil {
+				return fmt.Errorf("%v; restoring scenarios also failed: %v", err, undoErr)
+			}
+
+			return err
+		}
+	}
+
+	for _, p := range dst.Prefs {
+		if v, ok := prefs[p.Key]; ok {
+			writePref(dst.Preferences, p, v)
+		}
+	}
+
+	return nil
+}
+
+// prefs decodes the bundled preferences known to known, checking their
+// types.
+func (b Bundle) prefs(known []Pref) (map[string]any, error) {
+	values := make(map[string]any)
+	for _, p := range known {
+		raw, ok := b.Preferences[p.Key]
+		if !ok {
+			continue
+		}
+		var v any
+		var err error
+		switch p.Kind {
+		case PrefInt:
+			var n int
+			err = json.Unmarshal(raw, &n)
+			v = n
+		case PrefBool:
+			var x bool
+			err = json.Unmarshal(raw, &x)
+			v = x
+		case PrefString:
+			var x string
+			err = json.Unmarshal(raw, &x)
+			v = x
+		case PrefStringList:
+			var x []string
+			err = json.Unmarshal(raw, &x)
+			v = x
+		}
+		if err != nil {
+			return nil, fmt.Errorf("preference %q: %v", p.Key, err)
+		}
+		values[p.Key] = v
+	}
+
+	return values, nil
+}
+
+// readPref returns the value of p and whether it is set. A value is set
+// when reading it with two different fallbacks gives the same result.
+func readPref(prefs Preferences, p Pref) (any, bool) {
+	switch p.Kind {
+	case PrefInt:
+		v := prefs.IntWithFallback(p.Key, 0)
+		return v, v == prefs.IntWithFallback(p.Key, 1)
+	case PrefBool:
+		v := prefs.BoolWithFallback(p.Key, false)
+		return v, v == prefs.BoolWithFallback(p.Key, true)
+	case PrefString:
+		v := prefs.StringWithFallback(p.Key, "")
+		return v, v == prefs.StringWithFallback(p.Key, "\x00")
+	case PrefStringList:
+		v := prefs.StringListWithFallback(p.Key, nil)
+		return v, v != nil
+	default:
+		return nil, false
+	}
+}
+
+// writePref sets p to v, which has the type of p.Kind.
+func writePref(prefs Preferences, p Pref, v any) {
+	switch p.Kind {
+	case PrefInt:
+		prefs.SetInt(p.Key, v.(int))
+	case PrefBool:
+		prefs.SetBool(p.Key, v.(bool))
+	case PrefString:
+		prefs.SetString(p.Key, v.(string))
+	case PrefStringList:
+		prefs.SetStringList(p.Key, v.([]string))
+	}
+}
+
+// sameJSON reports whether a and b encode to the same JSON.
+func sameJSON(a, b any) bool {
+	x, errX := json.Marshal(a)
+	y, errY := json.Marshal(b)
+
+	return errX == nil && errY == nil && string(x) == string(y)
+}
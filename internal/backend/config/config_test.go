@@ -0,0 +1,296 @@
+// nolint:all // test package
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// memPrefs keeps preferences in memory.
+type memPrefs map[string]any
+
+func (m memPrefs) IntWithFallback(k string, f int) int {
+	if v, ok := m[k].(int); ok {
+		return v
+	}
+	return f
+}
+func (m memPrefs) SetInt(k string, v int) { m[k] = v }
+func (m memPrefs) BoolWithFallback(k string, f bool) bool {
+	if v, ok := m[k].(bool); ok {
+		return v
+	}
+	return f
+}
+func (m memPrefs) SetBool(k string, v bool) { m[k] = v }
+func (m memPrefs) StringWithFallback(k, f string) string {
+	if v, ok := m[k].(string); ok {
+		return v
+	}
+	return f
+}
+func (m memPrefs) SetString(k, v string) { m[k] = v }
+func (m memPrefs) StringListWithFallback(k string, f []string) []string {
+	if v, ok := m[k].([]string); ok {
+		return v
+	}
+	return f
+}
+func (m memPrefs) SetStringList(k string, v []string) { m[k] = v }
+
+var testPrefs = []Pref{
+	{Key: "sender.timeout_ms", Kind: PrefInt},
+	{Key: "sender.remember_commands", Kind: PrefBool},
+	{Key: "sender.recent_commands", Kind: PrefStringList, Sensitive: true},
+	{Key: "ui.theme", Kind: PrefString},
+}
+
+// newSources returns empty stores in a temporary directory.
+func newSources(t *testing.T) Sources {
+	t.Helper()
+	dir := t.TempDir()
+	profiles, err := storage.NewProfileStore(filepath.Join(dir, "profiles.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenarios, err := storage.NewScenarioStore(filepath.Join(dir, "scenarios"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return Sources{Profiles: profiles, Scenarios: scenarios, Preferences: memPrefs{}, Prefs: testPrefs}
+}
+
+// populated returns stores holding a profile, two scenarios, one with a
+// clear key, and preferences including recent commands.
+func populated(t *testing.T) Sources {
+	t.Helper()
+	src := newSources(t)
+	if err := src.Profiles.Save(storage.ConnectionProfile{Name: "UAT", Host: "10.0.0.5", Port: "1500", Connections: 2}, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []storage.Scenario{
+		{Name: "soak", Command: "NC", Count: 100, TimeoutMillis: 2000},
+		{Name: "clear key", Command: "A40020123456789ABCDEF0123456789ABCDEF", TimeoutMillis: 2000},
+	} {
+		if err := src.Scenarios.Save(s, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	src.Preferences.SetInt("sender.timeout_ms", 750)
+	src.Preferences.SetBool("sender.remember_commands", true)
+	src.Preferences.SetStringList("sender.recent_commands", []string{"NC", "A0..."})
+
+	return src
+}
+
+func TestExport_Sensitive(t *testing.T) {
+	src := populated(t)
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		sensitive     bool
+		wantScenarios []string
+		wantPrefs     []string
+	}{
+		{"default", false, []string{"soak"}, []string{"sender.remember_commands", "sender.timeout_ms"}},
+		{
+			"include_sensitive",
+			true,
+			[]string{"clear key", "soak"},
+			[]string{"sender.recent_commands", "sender.remember_commands", "sender.timeout_ms"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := Export(src, tt.sensitive, now)
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if b.Version != Version || !b.ExportedAt.Equal(now) || b.Sensitive != tt.sensitive {
+				t.Errorf("Export() header = %d, %v, %v", b.Version, b.ExportedAt, b.Sensitive)
+			}
+			if len(b.Profiles) != 1 || b.Profiles[0].Host != "10.0.0.5" {
+				t.Errorf("Export() profiles = %+v", b.Profiles)
+			}
+			var scenarios, prefs []string
+			for _, s := range b.Scenarios {
+				scenarios = append(scenarios, s.Name)
+			}
+			for k := range b.Preferences {
+				prefs = append(prefs, k)
+			}
+			sort.Strings(prefs)
+			if !reflect.DeepEqual(scenarios, tt.wantScenarios) || !reflect.DeepEqual(prefs, tt.wantPrefs) {
+				t.Errorf("Export() scenarios = %v, prefs = %v, want %v, %v", scenarios, prefs, tt.wantScenarios, tt.wantPrefs)
+			}
+
+			data, err := b.Marshal()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(string(data), "A0..."); got != tt.sensitive {
+				t.Errorf("exported file contains recent commands = %v, want %v", got, tt.sensitive)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+		errText string
+	}{
+		{"current", `{"version":1,"profiles":[{"name":"UAT"}]}`, nil, ""},
+		{"newer", `{"version":2,"profiles":[]}`, ErrVersion, "version 2"},
+		{"missing_version", `{"profiles":[]}`, ErrVersion, "version 0"},
+		{"not_json", `profiles`, nil, "failed to parse"},
+		{"wrong_shape", `{"version":1,"profiles":{}}`, nil, "failed to parse"},
+		{"bad_profile_name", `{"version":1,"profiles":[{"name":"../x"}]}`, nil, "profile"},
+		{"duplicate_scenario", `{"version":1,"scenarios":[{"name":"a"},{"name":"a"}]}`, nil, "twice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.errText == "" {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Fatalf("Parse() error = %v, want %q", err, tt.errText)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestImport_PlanAndApply(t *testing.T) {
+	src := populated(t)
+	b, err := Export(src, false, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := b.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err = Parse(data)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	dst := newSources(t)
+	if err := dst.Profiles.Save(storage.ConnectionProfile{Name: "UAT", Host: "10.0.0.9", Port: "1500"}, false); err != nil {
+		t.Fatal(err)
+	}
+	dst.Preferences.SetInt("sender.timeout_ms", 750)
+
+	changes, err := b.Plan(dst)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	want := []string{
+		"~ profile UAT (overwrite)",
+		"+ scenario soak",
+		"+ preference sender.remember_commands",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("Plan() = %q, want %q", lines, want)
+	}
+
+	if err := b.Apply(dst); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if p, _ := dst.Profiles.Get("UAT"); p.Host != "10.0.0.5" || dst.Profiles.LastUsed() != "UAT" {
+		t.Errorf("profile after Apply() = %+v, last used %q", p, dst.Profiles.LastUsed())
+	}
+	if s, err := dst.Scenarios.Load("soak"); err != nil || s.Count != 100 {
+		t.Errorf("scenario after Apply() = %+v, %v", s, err)
+	}
+	if dst.Scenarios.Exists("clear key") {
+		t.Error("Apply() imported a scenario left out of the export")
+	}
+	prefs := dst.Preferences.(memPrefs)
+	if prefs["sender.remember_commands"] != true || prefs["sender.recent_commands"] != nil {
+		t.Errorf("preferences after Apply() = %v", prefs)
+	}
+	if changes, _ := b.Plan(dst); len(changes) != 0 {
+		t.Errorf("Plan() after Apply() = %v, want no changes", changes)
+	}
+}
+
+func TestApply_Atomic(t *testing.T) {
+	dir := t.TempDir()
+	profilePath := filepath.Join(dir, "profiles.json")
+	profiles, err := storage.NewProfileStore(profilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenarios, err := storage.NewScenarioStore(filepath.Join(dir, "scenarios"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := Sources{Profiles: profiles, Scenarios: scenarios, Preferences: memPrefs{}, Prefs: testPrefs}
+	if err := scenarios.Save(storage.Scenario{Name: "soak", Command: "NC", Count: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	b := Bundle{
+		Version:     Version,
+		Profiles:    []storage.ConnectionProfile{{Name: "UAT", Host: "10.0.0.5"}},
+		Scenarios:   []storage.Scenario{{Name: "soak", Command: "NC", Count: 500}, {Name: "new", Command: "NC"}},
+		Preferences: map[string]json.RawMessage{"sender.timeout_ms": json.RawMessage("750")},
+	}
+
+	// A preference of the wrong type stops the import before any write.
+	bad := b
+	bad.Preferences = map[string]json.RawMessage{"sender.timeout_ms": json.RawMessage(`"fast"`)}
+	if err := bad.Apply(dst); err == nil || !strings.Contains(err.Error(), "sender.timeout_ms") {
+		t.Fatalf("Apply() with a bad preference error = %v", err)
+	}
+	if scenarios.Exists("new") {
+		t.Fatal("Apply() wrote scenarios despite a bad preference")
+	}
+
+	// A directory in place of the profile file makes saving profiles fail.
+	if err := os.MkdirAll(filepath.Join(profilePath, "blocked"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Apply(dst); err == nil {
+		t.Fatal("Apply() error = nil, want the profile save failure")
+	}
+	if s, err := scenarios.Load("soak"); err != nil || s.Count != 1 {
+		t.Errorf("overwritten scenario not restored: %+v, %v", s, err)
+	}
+	if scenarios.Exists("new") {
+		t.Error("added scenario not removed")
+	}
+	if _, ok := profiles.Get("UAT"); ok {
+		t.Error("profile kept after a failed save")
+	}
+	if len(dst.Preferences.(memPrefs)) != 0 {
+		t.Errorf("preferences set after a failed import: %v", dst.Preferences)
+	}
+}
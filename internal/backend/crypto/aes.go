@@ -0,0 +1,170 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CMAC subkey constants for 128-bit (RFC 4493) and 64-bit (NIST SP 800-38B)
+// block ciphers.
+const (
+	cmacRb   = 0x87
+	cmacRb64 = 0x1B
+)
+
+// AESParams holds parameters for AES operation.
+type AESParams struct {
+	Data    []byte
+	Key     []byte
+	IV      []byte // iv for CBC mode.
+	Mode    CipherMode
+	Padding PaddingMode
+	Encrypt bool
+}
+
+// ProcessAES performs AES encryption/decryption according to parameters.
+func ProcessAES(params *AESParams) ([]byte, error) {
+	if params == nil {
+		return nil, errors.New("params cannot be nil")
+	}
+
+	block, err := newAESCipher(params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Pad plaintext; ciphertext must already fill whole blocks.
+	paddedData, err := padInput(params.Data, block.BlockSize(), params.Padding, params.Encrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process data according to mode.
+	result := make([]byte, len(paddedData))
+	switch params.Mode {
+	case ECB:
+		processECB(block, paddedData, result, params.Encrypt)
+
+	case CBC:
+		// Validate iv length.
+		if len(params.IV) != block.BlockSize() {
+			return nil, fmt.Errorf("invalid iv length: must be %d bytes", block.BlockSize())
+		}
+		if params.Encrypt {
+			cipher.NewCBCEncrypter(block, params.IV).CryptBlocks(result, paddedData)
+		} else {
+			cipher.NewCBCDecrypter(block, params.IV).CryptBlocks(result, paddedData)
+		}
+	default:
+
+		return nil, errors.New("unsupported mode")
+	}
+
+	if !params.Encrypt {
+		return unpad(result, block.BlockSize(), params.Padding)
+	}
+
+	return result, nil
+}
+
+// CalculateAESKCV returns the AES key check value, the first 3 bytes of the
+// CMAC of a zero block, as an uppercase hex string.
+func CalculateAESKCV(key []byte) (string, error) {
+	mac, err := CMAC(key, make([]byte, aes.BlockSize))
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate KCV: %v", err)
+	}
+
+	return strings.ToUpper(hex.EncodeToString(mac[:3])), nil
+}
+
+// CMAC computes the AES-CMAC of data as defined in RFC 4493.
+func CMAC(key, data []byte) ([]byte, error) {
+	block, err := newAESCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmac(block, data), nil
+}
+
+// cmac computes the CMAC of data under block, whose block size is 8 or 16
+// bytes.
+func cmac(block cipher.Block, data []byte) []byte {
+	size := block.BlockSize()
+	rb := byte(cmacRb)
+	if size == 8 {
+		rb = cmacRb64
+	}
+
+	// Derive subkeys K1 and K2 from the encrypted zero block.
+	l := make([]byte, size)
+	block.Encrypt(l, l)
+	k1 := cmacShift(l, rb)
+	k2 := cmacShift(k1, rb)
+
+	// Pad the last block when it is partial or missing.
+	n := (len(data) + size - 1) / size
+	complete := n > 0 && len(data)%size == 0
+	if n == 0 {
+		n = 1
+	}
+	last := make([]byte, size)
+	copy(last, data[(n-1)*size:])
+	subkey := k1
+	if !complete {
+		last[len(data)-(n-1)*size] = 0x80
+		subkey = k2
+	}
+	for i := range last {
+		last[i] ^= subkey[i]
+	}
+
+	mac := make([]byte, size)
+	for i := 0; i < n-1; i++ {
+		for j := range mac {
+			mac[j] ^= data[i*size+j]
+		}
+		block.Encrypt(mac, mac)
+	}
+	for j := range mac {
+		mac[j] ^= last[j]
+	}
+	block.Encrypt(mac, mac)
+
+	return mac
+}
+
+// newAESCipher validates the key length and creates the AES block cipher.
+func newAESCipher(key []byte) (cipher.Block, error) {
+	keyLen := len(key)
+	if keyLen != 16 && keyLen != 24 && keyLen != 32 {
+		return nil, errors.New("invalid key length: must be 16, 24, or 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return block, nil
+}
+
+// cmacShift shifts b left by one bit and applies the CMAC reduction rb.
+func cmacShift(b []byte, rb byte) []byte {
+	out := make([]byte, len(b))
+	var carry byte
+	for i := len(b) - 1; i >= 0; i-- {
+		out[i] = b[i]<<1 | carry
+		carry = b[i] >> 7
+	}
+	if carry != 0 {
+		out[len(out)-1] ^= rb
+	}
+
+	return out
+}
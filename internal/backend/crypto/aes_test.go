@@ -0,0 +1,215 @@
+// nolint:all // test package
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// rfc4493Key is the AES-128 key used by the RFC 4493 test vectors.
+const rfc4493Key = "2B7E151628AED2A6ABF7158809CF4F3C"
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+
+	return b
+}
+
+func TestCMAC(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "BB1D6929E95937287FA37D129B756746"},
+		{"one_block", "6BC1BEE22E409F96E93D7E117393172A", "070A16B46B4D4144F79BDD9DD04A287C"},
+		{
+			"partial_block",
+			"6BC1BEE22E409F96E93D7E117393172AAE2D8A571E03AC9C9EB76FAC45AF8E5130C81C46A35CE411",
+			"DFA66747DE9AE63030CA32611497C827",
+		},
+		{
+			"four_blocks",
+			"6BC1BEE22E409F96E93D7E117393172AAE2D8A571E03AC9C9EB76FAC45AF8E51" +
+				"30C81C46A35CE411E5FBC1191A0A52EFF69F2445DF4F9B17AD2B417BE66C3710",
+			"51F0BEBF7E3B9D92FC49741779363CFE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CMAC(mustHex(t, rfc4493Key), mustHex(t, tt.data))
+			if err != nil {
+				t.Fatalf("CMAC() error = %v", err)
+			}
+			if !bytes.Equal(got, mustHex(t, tt.want)) {
+				t.Errorf("CMAC() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateAESKCV(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyHex  string
+		wantErr bool
+	}{
+		{"aes_128", rfc4493Key, false},
+		{"aes_192", "8E73B0F7DA0E6452C810F32B809079E562F8EAD2522C6B7B", false},
+		{"aes_256", "603DEB1015CA71BE2B73AEF0857D77811F352C073B6108D72D9810A30914DFF4", false},
+		{"invalid_length", "0123456789ABCDEF", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := mustHex(t, tt.keyHex)
+			got, err := CalculateAESKCV(key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateAESKCV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			mac, _ := CMAC(key, make([]byte, 16))
+			if want := hex.EncodeToString(mac[:3]); got != string(bytes.ToUpper([]byte(want))) {
+				t.Errorf("CalculateAESKCV() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestProcessAES(t *testing.T) {
+	// FIPS 197 / SP 800-38A vectors.
+	plain := "6BC1BEE22E409F96E93D7E117393172A"
+	iv := "000102030405060708090A0B0C0D0E0F"
+
+	tests := []struct {
+		name    string
+		params  AESParams
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "ecb_aes128",
+			params: AESParams{
+				Data: mustHex(t, plain), Key: mustHex(t, rfc4493Key),
+				Mode: ECB, Padding: NoPadding, Encrypt: true,
+			},
+			want: "3AD77BB40D7A3660A89ECAF32466EF97",
+		},
+		{
+			name: "ecb_aes128_decrypt",
+			params: AESParams{
+				Data: mustHex(t, "3AD77BB40D7A3660A89ECAF32466EF97"), Key: mustHex(t, rfc4493Key),
+				Mode: ECB, Padding: NoPadding, Encrypt: false,
+			},
+			want: plain,
+		},
+		{
+			name: "cbc_aes128",
+			params: AESParams{
+				Data: mustHex(t, plain), Key: mustHex(t, rfc4493Key), IV: mustHex(t, iv),
+				Mode: CBC, Padding: NoPadding, Encrypt: true,
+			},
+			want: "7649ABAC8119B246CEE98E9B12E9197D",
+		},
+		{
+			name: "cbc_missing_iv",
+			params: AESParams{
+				Data: mustHex(t, plain), Key: mustHex(t, rfc4493Key),
+				Mode: CBC, Padding: NoPadding, Encrypt: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unaligned_without_padding",
+			params: AESParams{
+				Data: []byte("short"), Key: mustHex(t, rfc4493Key),
+				Mode: ECB, Padding: NoPadding, Encrypt: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "decrypt_unaligned_ciphertext",
+			params: AESParams{
+				Data: []byte("short"), Key: mustHex(t, rfc4493Key),
+				Mode: ECB, Padding: ISO97972, Encrypt: false,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid_key_length",
+			params: AESParams{
+				Data: mustHex(t, plain), Key: mustHex(t, "0123456789ABCDEF"),
+				Mode: ECB, Padding: NoPadding, Encrypt: true,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessAES(&tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ProcessAES() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, mustHex(t, tt.want)) {
+				t.Errorf("ProcessAES() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ProcessAES(nil); err == nil {
+		t.Error("ProcessAES(nil) expected error")
+	}
+}
+
+func TestProcessAES_PaddedRoundTrip(t *testing.T) {
+	key := mustHex(t, rfc4493Key)
+	iv := mustHex(t, "000102030405060708090A0B0C0D0E0F")
+
+	tests := []struct {
+		name    string
+		data    []byte
+		mode    CipherMode
+		padding PaddingMode
+		want    []byte // Decrypted data when it differs from data.
+	}{
+		{"iso97972_ecb_short", []byte("HELLO"), ECB, ISO97972, nil},
+		{"iso97972_cbc_multi_block", []byte("PIN BLOCK TEST, 21 B"), CBC, ISO97972, nil},
+		{"iso97972_full_block", bytes.Repeat([]byte{0x80}, 16), ECB, ISO97972, nil},
+		{"iso97971_keeps_zeros", []byte("HELLO"), ECB, ISO97971, append([]byte("HELLO"), make([]byte, 11)...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := AESParams{Data: tt.data, Key: key, IV: iv, Mode: tt.mode, Padding: tt.padding, Encrypt: true}
+			enc, err := ProcessAES(&params)
+			if err != nil {
+				t.Fatalf("encrypt error = %v", err)
+			}
+			if len(enc)%16 != 0 {
+				t.Fatalf("ciphertext length = %d, want a multiple of 16", len(enc))
+			}
+
+			params.Data, params.Encrypt = enc, false
+			got, err := ProcessAES(&params)
+			if err != nil {
+				t.Fatalf("decrypt error = %v", err)
+			}
+			want := tt.want
+			if want == nil {
+				want = tt.data
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("round trip = %X, want %X", got, want)
+			}
+		})
+	}
+}
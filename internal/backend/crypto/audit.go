@@ -0,0 +1,84 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// auditLog records local key operations. Entries never carry key material,
+// only lengths, counts and check values.
+var auditLog = logger.WithModule("crypto")
+
+// AuditKeyGenerate records the generation of a key of the given bit length.
+func AuditKeyGenerate(bits int, oddParity bool, kcv string) {
+	auditLog.LogFields(logger.INFO, "key_generate", "Success", "", map[string]string{
+		"length": strconv.Itoa(bits),
+		"parity": parityName(oddParity),
+		"kcv":    strings.ToUpper(kcv),
+	})
+}
+
+// AuditKeySplit records a key being split into count components.
+func AuditKeySplit(count int, kcv string) {
+	auditLog.LogFields(logger.INFO, "key_split", "Success", "", map[string]string{
+		"components": strconv.Itoa(count),
+		"kcv":        strings.ToUpper(kcv),
+	})
+}
+
+// AuditKeyCombine records components being combined into a key with the given
+// check value. Only the check values of the hex components are logged.
+func AuditKeyCombine(components []string, kcv string) {
+	kcvs := make([]string, len(components))
+	for i, c := range components {
+		kcvs[i] = CheckValueOf(c)
+	}
+
+	auditLog.LogFields(logger.INFO, "key_combine", "Success", "", map[string]string{
+		"components":     strconv.Itoa(len(components)),
+		"component_kcvs": strings.Join(kcvs, ","),
+		"kcv":            strings.ToUpper(kcv),
+	})
+}
+
+// CheckValueOf returns the KCV of a hex key, "N/A" for AES-256 keys and
+// "ERROR" when none can be computed.
+func CheckValueOf(keyHex string) string {
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return "ERROR"
+	}
+	defer cleanBytes(key)
+	if len(key) == 32 {
+		return "N/A"
+	}
+
+	kcv, err := CalculateKCV(key)
+	if err != nil {
+		return "ERROR"
+	}
+
+	return strings.ToUpper(kcv)
+}
+
+// parityName describes the parity option used for audit entries.
+func parityName(oddParity bool) string {
+	if oddParity {
+		return "odd"
+	}
+
+	return "none"
+}
+
+// AuditComponentsExport records count component mailers for the key with the
+// given check value being written to dir.
+func AuditComponentsExport(count int, kcv, dir string) {
+	auditLog.LogFields(logger.INFO, "key_components_export", "Success", "", map[string]string{
+		"components": strconv.Itoa(count),
+		"kcv":        strings.ToUpper(kcv),
+		"directory":  dir,
+	})
+}
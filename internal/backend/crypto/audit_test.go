@@ -0,0 +1,85 @@
+// nolint:all // test package
+package crypto
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+func TestAuditKeyOperations(t *testing.T) {
+	var entries []logger.Entry
+	l, err := logger.NewLogger(filepath.Join(t.TempDir(), "audit.log"), logger.INFO, func(e logger.Entry) {
+		entries = append(entries, e)
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer l.Close()
+
+	saved := auditLog
+	auditLog = l.WithModule("crypto")
+	defer func() { auditLog = saved }()
+
+	keyHex, kcv, err := GenerateKey(128, true)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	components, _, err := SplitKey(keyHex, 3)
+	if err != nil {
+		t.Fatalf("SplitKey() error = %v", err)
+	}
+
+	AuditKeyGenerate(128, true, kcv)
+	AuditKeySplit(len(components), kcv)
+	AuditKeyCombine(components, kcv)
+	AuditComponentsExport(len(components), kcv, "/tmp/mailers")
+	l.Close() // Wait for asynchronous callback delivery.
+
+	tests := []struct {
+		event  string
+		fields map[string]string
+	}{
+		{"key_generate", map[string]string{"length": "128", "parity": "odd", "kcv": strings.ToUpper(kcv)}},
+		{"key_split", map[string]string{"components": "3", "kcv": strings.ToUpper(kcv)}},
+		{"key_combine", map[string]string{"components": "3", "kcv": strings.ToUpper(kcv)}},
+		{"key_components_export", map[string]string{
+			"components": "3", "kcv": strings.ToUpper(kcv), "directory": "/tmp/mailers",
+		}},
+	}
+	if len(entries) != len(tests) {
+		t.Fatalf("logged %d entries, want %d", len(entries), len(tests))
+	}
+
+	secrets := append([]string{keyHex}, components...)
+	for i, tt := range tests {
+		e := entries[i]
+		if e.Event != tt.event || e.Module != "crypto" || e.Level != logger.INFO {
+			t.Errorf("entry %d = %s/%s/%s, want crypto/%s/INFO", i, e.Module, e.Event, e.Level, tt.event)
+		}
+		for k, want := range tt.fields {
+			if got := e.Fields[k]; got != want {
+				t.Errorf("%s field %q = %q, want %q", tt.event, k, got, want)
+			}
+		}
+		for _, v := range e.Fields {
+			for _, secret := range secrets {
+				if strings.Contains(strings.ToUpper(v), strings.ToUpper(secret)) {
+					t.Errorf("%s leaks key material in field value %q", tt.event, v)
+				}
+			}
+		}
+	}
+
+	kcvs := strings.Split(entries[2].Fields["component_kcvs"], ",")
+	if len(kcvs) != 3 {
+		t.Fatalf("component_kcvs = %q, want 3 values", entries[2].Fields["component_kcvs"])
+	}
+	for i, c := range kcvs {
+		if want := CheckValueOf(components[i]); c != want || len(c) != 6 {
+			t.Errorf("component_kcvs[%d] = %q, want %q", i, c, want)
+		}
+	}
+}
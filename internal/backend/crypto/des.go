@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/cipher"
 	"crypto/des"
 	"encoding/hex"
@@ -71,43 +72,43 @@ func CalculateKCV(key []byte) (string, error) {
 	return strings.ToUpper(hex.EncodeToString(result[:3])), nil
 }
 
-// ProcessDES performs DES encryption/decryption according to parameters.
-func ProcessDES(params *DESParams) ([]byte, error) {
-	if params == nil {
-		return nil, errors.New("params cannot be nil")
+// CalculateCheckValue returns the first hexDigits digits of the DES
+// encryption of a zero block under key. hexDigits must be 4, 6 or 16, the
+// check value lengths used on key component forms.
+func CalculateCheckValue(key []byte, hexDigits int) (string, error) {
+	if hexDigits != 4 && hexDigits != 6 && hexDigits != 16 {
+		return "", fmt.Errorf("invalid check value length: %d", hexDigits)
 	}
 
-	// Validate key length.
-	keyLen := len(params.Key)
-	if keyLen != 8 && keyLen != 16 && keyLen != 24 {
-		return nil, errors.New("invalid key length: must be 8, 16, or 24 bytes")
+	result, err := ProcessDES(&DESParams{
+		Data:    make([]byte, 8),
+		Key:     key,
+		Mode:    ECB,
+		Padding: NoPadding,
+		Encrypt: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to calculate check value: %v", err)
 	}
 
-	// Create block cipher.
-	var block cipher.Block
-	var err error
+	return strings.ToUpper(hex.EncodeToString(result))[:hexDigits], nil
+}
 
-	switch keyLen {
-	case 8:
-		block, err = des.NewCipher(params.Key)
-	case 16:
-		// For double length key, use K1,K2,K1 mode
-		tripleKey := make([]byte, 24)
-		copy(tripleKey[:16], params.Key)     // Copy K1,K2
-		copy(tripleKey[16:], params.Key[:8]) // Copy K1 again
-		block, err = des.NewTripleDESCipher(tripleKey)
-	case 24:
-		block, err = des.NewTripleDESCipher(params.Key)
+// ProcessDES performs DES encryption/decryption according to parameters.
+func ProcessDES(params *DESParams) ([]byte, error) {
+	if params == nil {
+		return nil, errors.New("params cannot be nil")
 	}
 
+	block, err := newDESCipher(params.Key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create cipher: %w", err)
+		return nil, err
 	}
 
-	// Apply padding if needed.
-	paddedData, err := pad(params.Data, block.BlockSize(), params.Padding)
+	// Pad plaintext; ciphertext must already fill whole blocks.
+	paddedData, err := padInput(params.Data, block.BlockSize(), params.Padding, params.Encrypt)
 	if err != nil {
-		return nil, fmt.Errorf("padding error: %w", err)
+		return nil, err
 	}
 
 	// Process data according to mode.
@@ -133,9 +134,50 @@ func ProcessDES(params *DESParams) ([]byte, error) {
 		return nil, errors.New("unsupported mode")
 	}
 
+	if !params.Encrypt {
+		return unpad(result, block.BlockSize(), params.Padding)
+	}
+
 	return result, nil
 }
 
+// TDESCMAC computes the CMAC of data under a single, double or triple length
+// DES key as defined in NIST SP 800-38B.
+func TDESCMAC(key, data []byte) ([]byte, error) {
+	block, err := newDESCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cmac(block, data), nil
+}
+
+// newDESCipher validates the key length and creates the DES or triple DES
+// block cipher. Double length keys are used as K1, K2, K1.
+func newDESCipher(key []byte) (cipher.Block, error) {
+	var block cipher.Block
+	var err error
+
+	switch len(key) {
+	case 8:
+		block, err = des.NewCipher(key)
+	case 16:
+		tripleKey := make([]byte, 24)
+		copy(tripleKey[:16], key)
+		copy(tripleKey[16:], key[:8])
+		block, err = des.NewTripleDESCipher(tripleKey)
+	case 24:
+		block, err = des.NewTripleDESCipher(key)
+	default:
+		return nil, errors.New("invalid key length: must be 8, 16, or 24 bytes")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return block, nil
+}
+
 // pad adds padding according to the specified mode.
 func pad(data []byte, blockSize int, mode PaddingMode) ([]byte, error) {
 	if mode == NoPadding {
@@ -180,6 +222,44 @@ func pad(data []byte, blockSize int, mode PaddingMode) ([]byte, error) {
 	}
 }
 
+// padInput pads data to be encrypted. Data to be decrypted is never padded
+// and must be a whole number of blocks.
+func padInput(data []byte, blockSize int, mode PaddingMode, encrypt bool) ([]byte, error) {
+	if !encrypt {
+		if len(data)%blockSize != 0 {
+			return nil, fmt.Errorf("ciphertext length must be a multiple of %d bytes", blockSize)
+		}
+
+		return data, nil
+	}
+
+	padded, err := pad(data, blockSize, mode)
+	if err != nil {
+		return nil, fmt.Errorf("padding error: %w", err)
+	}
+
+	return padded, nil
+}
+
+// unpad removes ISO 9797-1 method 2 padding from decrypted data. Method 1
+// padding cannot be told from trailing zero bytes of the data, so it is
+// left in place.
+func unpad(data []byte, blockSize int, mode PaddingMode) ([]byte, error) {
+	if mode != ISO97972 {
+		return data, nil
+	}
+
+	i := len(data) - 1
+	for i >= 0 && data[i] == 0 {
+		i--
+	}
+	if i < 0 || data[i] != 0x80 || len(data)-i > blockSize {
+		return nil, errors.New("invalid ISO 9797-1 method 2 padding")
+	}
+
+	return data[:i], nil
+}
+
 // processECB performs ECB mode encryption/decryption.
 func processECB(block cipher.Block, in, out []byte, encrypt bool) {
 	blockSize := block.BlockSize()
@@ -191,3 +271,44 @@ func processECB(block cipher.Block, in, out []byte, encrypt bool) {
 		}
 	}
 }
+
+// DESKeyPart describes one 8-byte part of a DES key.
+type DESKeyPart struct {
+	KCV       string
+	EvenBytes []int // Zero-based indexes of bytes without odd parity.
+}
+
+// DESKeyAnalysis reports the parts of a single, double or triple length DES
+// key.
+type DESKeyAnalysis struct {
+	Parts []DESKeyPart
+	// SingleDES is set when equal adjacent parts reduce triple DES to single
+	// DES strength (K1 == K2, or K2 == K3 for triple length keys).
+	SingleDES bool
+}
+
+// AnalyzeDESKey computes the KCV and parity of each 8-byte part of key.
+func AnalyzeDESKey(key []byte) (DESKeyAnalysis, error) {
+	if len(key) != 8 && len(key) != 16 && len(key) != 24 {
+		return DESKeyAnalysis{}, errors.New("invalid key length: must be 8, 16, or 24 bytes")
+	}
+
+	var a DESKeyAnalysis
+	for off := 0; off < len(key); off += 8 {
+		part := key[off : off+8]
+		kcv, err := CalculateKCV(part)
+		if err != nil {
+			return DESKeyAnalysis{}, err
+		}
+		a.Parts = append(a.Parts, DESKeyPart{KCV: kcv, EvenBytes: CheckOddParity(part)})
+	}
+
+	if len(key) >= 16 && bytes.Equal(key[:8], key[8:16]) {
+		a.SingleDES = true
+	}
+	if len(key) == 24 && bytes.Equal(key[8:16], key[16:]) {
+		a.SingleDES = true
+	}
+
+	return a, nil
+}
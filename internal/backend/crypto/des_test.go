@@ -241,6 +241,28 @@ func TestProcessDES(t *testing.T) {
 			wantErr:     false,
 			checkOutput: true,
 		},
+		{
+			name: "decrypt_unaligned_ciphertext",
+			params: &DESParams{
+				Data:    data8[:5],
+				Key:     key8,
+				Mode:    ECB,
+				Padding: ISO97972,
+				Encrypt: false,
+			},
+			wantErr: true,
+		},
+		{
+			name: "decrypt_invalid_iso97972_padding",
+			params: &DESParams{
+				Data:    data16,
+				Key:     key16,
+				Mode:    ECB,
+				Padding: ISO97972,
+				Encrypt: false,
+			},
+			wantErr: true,
+		},
 		{
 			name: "unsupported_padding_mode",
 			params: &DESParams{
@@ -276,7 +298,7 @@ func TestProcessDES(t *testing.T) {
 				decryptParams.Data = encrypted // Use encrypted data as input.
 				decryptParams.Encrypt = false
 				// IV for CBC decryption must be the same as for encryption.
-				// Padding is handled by the ProcessDES logic (implicitly, as decryption doesn't unpad here).
+				// Method 2 padding is removed on decryption; method 1 padding stays.
 
 				decrypted, err := ProcessDES(&decryptParams)
 				if err != nil {
@@ -296,11 +318,11 @@ func TestProcessDES(t *testing.T) {
 					return
 				}
 
-				// For NoPadding, lengths must match exactly.
-				if tt.params.Padding == NoPadding {
+				// Without method 1 padding, lengths must match exactly.
+				if tt.params.Padding != ISO97971 {
 					if !bytes.Equal(decrypted, originalData) {
 						t.Errorf(
-							"ProcessDES() decrypted data = %X, want %X (NoPadding)",
+							"ProcessDES() decrypted data = %X, want %X",
 							decrypted,
 							originalData,
 						)
@@ -310,9 +332,150 @@ func TestProcessDES(t *testing.T) {
 					if !bytes.Equal(decrypted[:len(originalData)], originalData) {
 						t.Errorf("ProcessDES() decrypted data prefix = %X, want %X (With Padding)", decrypted[:len(originalData)], originalData)
 					}
-					// Further checks for padding bytes could be added if unpad function was available and used.
 				}
 			}
 		})
 	}
 }
+
+func TestCalculateCheckValue(t *testing.T) {
+	key, _ := hex.DecodeString("0123456789ABCDEF")
+
+	tests := []struct {
+		name    string
+		key     []byte
+		digits  int
+		want    string
+		wantErr bool
+	}{
+		{"four_digits", key, 4, "D5D4", false},
+		{"six_digits", key, 6, "D5D44F", false},
+		{"sixteen_digits", key, 16, "D5D44FF720683D0D", false},
+		{"unsupported_digits", key, 8, "", true},
+		{"invalid_key", []byte{0x01}, 6, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CalculateCheckValue(tt.key, tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CalculateCheckValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CalculateCheckValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTDESCMAC(t *testing.T) {
+	// NIST SP 800-38B three key TDEA examples.
+	const key = "8AA83BF8CBDA10620BC1BF19FBB6CD58BC313D4A371CA8B5"
+	tests := []struct {
+		name    string
+		key     string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{"empty", key, "", "B7A688E122FFAF95", false},
+		{"one_block", key, "6BC1BEE22E409F96", "8E8F293136283797", false},
+		{"partial_block", key, "6BC1BEE22E409F96E93D7E117393172AAE2D8A57", "743DDBE0CE2DC2ED", false},
+		{
+			"four_blocks",
+			key,
+			"6BC1BEE22E409F96E93D7E117393172AAE2D8A571E03AC9C9EB76FAC45AF8E51",
+			"33E6B1092400EAE5",
+			false,
+		},
+		{"invalid_key", "0123", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k, _ := hex.DecodeString(tt.key)
+			data, _ := hex.DecodeString(tt.data)
+			got, err := TDESCMAC(k, data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TDESCMAC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want, _ := hex.DecodeString(tt.want); !bytes.Equal(got, want) {
+				t.Errorf("TDESCMAC() = %X, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDESKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		keyHex        string
+		wantKCVs      []string
+		wantEven      [][]int
+		wantSingleDES bool
+		wantErr       bool
+	}{
+		{
+			name:     "single_length",
+			keyHex:   "0123456789ABCDEF",
+			wantKCVs: []string{"D5D44F"},
+			wantEven: [][]int{nil},
+		},
+		{
+			name:     "double_length_bad_k2",
+			keyHex:   "0123456789ABCDEFFEDCBA9876543211",
+			wantKCVs: []string{"D5D44F", ""},
+			wantEven: [][]int{nil, {7}},
+		},
+		{
+			name:          "double_length_k1_equals_k2",
+			keyHex:        "0123456789ABCDEF0123456789ABCDEF",
+			wantKCVs:      []string{"D5D44F", "D5D44F"},
+			wantEven:      [][]int{nil, nil},
+			wantSingleDES: true,
+		},
+		{
+			name:          "triple_length_k2_equals_k3",
+			keyHex:        "0123456789ABCDEFFEDCBA9876543210FEDCBA9876543210",
+			wantKCVs:      []string{"D5D44F", "", ""},
+			wantEven:      [][]int{nil, nil, nil},
+			wantSingleDES: true,
+		},
+		{
+			name:    "invalid_length",
+			keyHex:  "0123456789",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, _ := hex.DecodeString(tt.keyHex)
+			got, err := AnalyzeDESKey(key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AnalyzeDESKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Parts) != len(tt.wantKCVs) {
+				t.Fatalf("AnalyzeDESKey() parts = %d, want %d", len(got.Parts), len(tt.wantKCVs))
+			}
+			for i, p := range got.Parts {
+				wantKCV, _ := CalculateKCV(key[i*8 : i*8+8])
+				if tt.wantKCVs[i] != "" {
+					wantKCV = tt.wantKCVs[i]
+				}
+				if p.KCV != wantKCV {
+					t.Errorf("part %d KCV = %q, want %q", i+1, p.KCV, wantKCV)
+				}
+				if len(p.EvenBytes) != len(tt.wantEven[i]) {
+					t.Errorf("part %d even bytes = %v, want %v", i+1, p.EvenBytes, tt.wantEven[i])
+				}
+			}
+			if got.SingleDES != tt.wantSingleDES {
+				t.Errorf("SingleDES = %v, want %v", got.SingleDES, tt.wantSingleDES)
+			}
+		})
+	}
+}
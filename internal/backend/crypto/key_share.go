@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math/bits"
 )
 
 // Constants for key handling.
@@ -299,3 +300,16 @@ func ValidateKeyParity(key []byte) bool {
 
 	return true
 }
+
+// CheckOddParity returns the zero-based indexes of the bytes in key that do
+// not have odd parity. An empty result means the whole key has odd parity.
+func CheckOddParity(key []byte) []int {
+	var even []int
+	for i, b := range key {
+		if bits.OnesCount8(b)%2 == 0 {
+			even = append(even, i)
+		}
+	}
+
+	return even
+}
@@ -0,0 +1,34 @@
+// nolint:all // test package
+package crypto
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestCheckOddParity(t *testing.T) {
+	tests := []struct {
+		name   string
+		keyHex string
+		want   []int
+	}{
+		{"all_odd", "0123456789ABCDEF", nil},
+		{"one_even_byte", "0123456789ABCDEE", []int{7}},
+		{"all_even", "0022446688AACCEE", []int{0, 1, 2, 3, 4, 5, 6, 7}},
+		{"mixed", "00234567880CCDEF", []int{0, 4, 5}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := hex.DecodeString(tt.keyHex)
+			if err != nil {
+				t.Fatalf("invalid test key: %v", err)
+			}
+			if got := CheckOddParity(key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckOddParity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
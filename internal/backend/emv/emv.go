@@ -0,0 +1,252 @@
+// Package emv derives EMV card keys with triple DES and computes application
+// cryptograms (ARQC) and their responses (ARPC).
+package emv
+
+import (
+	"crypto/sha1" // nolint:gosec // EMV option B derivation is defined with SHA-1.
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+)
+
+// Option selects how the ICC master key is derived from the PAN.
+type Option int
+
+// Derivation options of EMV Book 2, Annex A1.4.
+const (
+	// OptionA uses the rightmost 16 digits of PAN and PSN.
+	OptionA Option = iota
+	// OptionB hashes PAN and PSN with SHA-1 for PANs longer than 16 digits,
+	// and falls back to option A otherwise.
+	OptionB
+)
+
+// ARPCMethod selects how the ARPC is generated.
+type ARPCMethod int
+
+// ARPC methods of EMV Book 2, section 8.2.
+const (
+	// ARPCMethod1 encrypts the ARQC XORed with the two-byte ARC.
+	ARPCMethod1 ARPCMethod = iota
+	// ARPCMethod2 MACs the ARQC, the four-byte CSU and optional
+	// proprietary authentication data.
+	ARPCMethod2
+)
+
+// DeriveICCMK derives the ICC master key from the issuer master key mk, the
+// PAN and the PAN sequence number (two digits, "00" when empty). The key has
+// odd parity.
+func DeriveICCMK(mk []byte, pan, psn string, opt Option) ([]byte, error) {
+	if len(mk) != 16 {
+		return nil, errors.New("issuer master key must be a double length key")
+	}
+	if psn == "" {
+		psn = "00"
+	}
+	if len(pan) < 12 || len(pan) > 19 || !isDigits(pan) {
+		return nil, errors.New("PAN must be 12 to 19 digits")
+	}
+	if len(psn) != 2 || !isDigits(psn) {
+		return nil, errors.New("PSN must be 2 digits")
+	}
+
+	digits := pan + psn
+	if opt == OptionB && len(pan) > 16 {
+		digits = decimalizeHash(digits)
+	}
+	if len(digits) < 16 {
+		digits = strings.Repeat("0", 16-len(digits)) + digits
+	}
+	y, _ := hex.DecodeString(digits[len(digits)-16:])
+
+	left, err := encryptBlock(mk, y)
+	if err != nil {
+		return nil, err
+	}
+	for i := range y {
+		y[i] ^= 0xFF
+	}
+	right, err := encryptBlock(mk, y)
+	if err != nil {
+		return nil, err
+	}
+
+	return oddParity(append(left, right...)), nil
+}
+
+// decimalizeHash returns the 16 digits option B derives from PAN and PSN:
+// the decimal digits of the SHA-1 of the digits, in order, followed by its
+// hex letters A to F as 0 to 5 when there are fewer than 16.
+func decimalizeHash(digits string) string {
+	if len(digits)%2 != 0 {
+		digits = "0" + digits
+	}
+	packed, _ := hex.DecodeString(digits)
+	sum := sha1.Sum(packed) // nolint:gosec // Required by EMV option B.
+	h := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var b strings.Builder
+	for i := 0; i < len(h) && b.Len() < 16; i++ {
+		if h[i] <= '9' {
+			b.WriteByte(h[i])
+		}
+	}
+	for i := 0; i < len(h) && b.Len() < 16; i++ {
+		if h[i] >= 'A' {
+			b.WriteByte(h[i] - 'A' + '0')
+		}
+	}
+
+	return b.String()
+}
+
+// DeriveSessionKey derives the common session key (EMV Book 2, Annex
+// A1.3.1) from the ICC master key and the two-byte ATC. The key has odd
+// parity.
+func DeriveSessionKey(iccMK, atc []byte) ([]byte, error) {
+	if len(iccMK) != 16 {
+		return nil, errors.New("ICC master key must be a double length key")
+	}
+	if len(atc) != 2 {
+		return nil, errors.New("ATC must be 2 bytes")
+	}
+
+	f := make([]byte, 8)
+	copy(f, atc)
+	f[2] = 0xF0
+	left, err := encryptBlock(iccMK, f)
+	if err != nil {
+		return nil, err
+	}
+	f[2] = 0x0F
+	right, err := encryptBlock(iccMK, f)
+	if err != nil {
+		return nil, err
+	}
+
+	return oddParity(append(left, right...)), nil
+}
+
+// GenerateAC computes the application cryptogram (ARQC, TC or AAC) of the
+// transaction data: the ISO 9797-1 algorithm 3 MAC under the session key,
+// padded with padding method 1 or 2.
+func GenerateAC(sk, data []byte, padding crypto.PaddingMode) ([]byte, error) {
+	if len(sk) != 16 {
+		return nil, errors.New("session key must be a double length key")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("transaction data is empty")
+	}
+
+	return retailMAC(sk, data, padding)
+}
+
+// GenerateARPC computes the authorisation response cryptogram for arqc.
+// With method 1, arc is the two-byte authorisation response code and the
+// ARPC has 8 bytes. With method 2, arc is the four-byte card status update,
+// pad the optional proprietary authentication data and the ARPC has 4 bytes.
+func GenerateARPC(sk, arqc, arc, pad []byte, method ARPCMethod) ([]byte, error) {
+	if len(sk) != 16 {
+		return nil, errors.New("session key must be a double length key")
+	}
+	if len(arqc) != 8 {
+		return nil, errors.New("ARQC must be 8 bytes")
+	}
+
+	switch method {
+	case ARPCMethod1:
+		if len(arc) != 2 {
+			return nil, errors.New("ARC must be 2 bytes")
+		}
+		x := make([]byte, 8)
+		copy(x, arc)
+		for i := range x {
+			x[i] ^= arqc[i]
+		}
+
+		return encryptBlock(sk, x)
+	case ARPCMethod2:
+		if len(arc) != 4 {
+			return nil, errors.New("CSU must be 4 bytes")
+		}
+		if len(pad) > 8 {
+			return nil, errors.New("proprietary authentication data must be at most 8 bytes")
+		}
+		data := append(append(append([]byte{}, arqc...), arc...), pad...)
+		mac, err := retailMAC(sk, data, crypto.ISO97972)
+		if err != nil {
+			return nil, err
+		}
+
+		return mac[:4], nil
+	default:
+		return nil, fmt.Errorf("unsupported ARPC method %d", method)
+	}
+}
+
+// retailMAC computes the ISO 9797-1 algorithm 3 MAC of data under a double
+// length key: single DES CBC under the left half, then the last block
+// decrypted under the right and encrypted under the left half.
+func retailMAC(key, data []byte, padding crypto.PaddingMode) ([]byte, error) {
+	cbc, err := crypto.ProcessDES(&crypto.DESParams{
+		Data:    data,
+		Key:     key[:8],
+		IV:      make([]byte, 8),
+		Mode:    crypto.CBC,
+		Padding: padding,
+		Encrypt: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute MAC: %v", err)
+	}
+	last := cbc[len(cbc)-8:]
+
+	last, err = crypto.ProcessDES(&crypto.DESParams{Data: last, Key: key[8:], Mode: crypto.ECB})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute MAC: %v", err)
+	}
+
+	return encryptBlock(key[:8], last)
+}
+
+// encryptBlock encrypts one 8-byte block under a DES or triple DES key.
+func encryptBlock(key, block []byte) ([]byte, error) {
+	return crypto.ProcessDES(&crypto.DESParams{
+		Data:    block,
+		Key:     key,
+		Mode:    crypto.ECB,
+		Padding: crypto.NoPadding,
+		Encrypt: true,
+	})
+}
+
+// oddParity sets the low bit of every byte of key so each has odd parity.
+func oddParity(key []byte) []byte {
+	for i, b := range key {
+		b &^= 1
+		ones := 0
+		for x := b; x != 0; x &= x - 1 {
+			ones++
+		}
+		if ones%2 == 0 {
+			b |= 1
+		}
+		key[i] = b
+	}
+
+	return key
+}
+
+// isDigits reports whether s holds only decimal digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,154 @@
+// nolint:all // test package
+package emv
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+)
+
+const issuerMK = "0123456789ABCDEFFEDCBA9876543210"
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+
+	return b
+}
+
+func TestDeriveICCMK(t *testing.T) {
+	tests := []struct {
+		name    string
+		pan     string
+		psn     string
+		opt     Option
+		want    string
+		wantErr string
+	}{
+		{"option_a", "99012345678901234", "45", OptionA, "67F8292358083E5EA7AB7FDA58D53B6B", ""},
+		{"option_a_16_digits", "1234567890123456", "45", OptionA, "25011ACE8F3D7698259BE6B615403EB3", ""},
+		{"option_b_long_pan", "99012345678901234", "45", OptionB, "985EC4FD3EDF6162E31AF1C7D0543416", ""},
+		{"option_b_short_pan_is_a", "1234567890123456", "45", OptionB, "25011ACE8F3D7698259BE6B615403EB3", ""},
+		{"empty_psn_is_00", "1234567890123456", "", OptionA, "", ""},
+		{"pan_letters", "12345678901234AB", "00", OptionA, "", "PAN"},
+		{"pan_short", "12345678901", "00", OptionA, "", "PAN"},
+		{"psn_length", "1234567890123456", "1", OptionA, "", "PSN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeriveICCMK(mustHex(t, issuerMK), tt.pan, tt.psn, tt.opt)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DeriveICCMK() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeriveICCMK() error = %v", err)
+			}
+			if tt.want != "" && !bytes.Equal(got, mustHex(t, tt.want)) {
+				t.Errorf("DeriveICCMK() = %X, want %s", got, tt.want)
+			}
+			if !crypto.ValidateKeyParity(got) {
+				t.Errorf("DeriveICCMK() = %X, want odd parity", got)
+			}
+		})
+	}
+
+	zero, _ := DeriveICCMK(mustHex(t, issuerMK), "1234567890123456", "00", OptionA)
+	empty, _ := DeriveICCMK(mustHex(t, issuerMK), "1234567890123456", "", OptionA)
+	if !bytes.Equal(zero, empty) {
+		t.Errorf("empty PSN = %X, want PSN 00 = %X", empty, zero)
+	}
+}
+
+func TestDeriveSessionKey(t *testing.T) {
+	iccMK := mustHex(t, "67F8292358083E5EA7AB7FDA58D53B6B")
+	sk, err := DeriveSessionKey(iccMK, []byte{0x00, 0x1C})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// SK = 3DES(ICC MK)[ATC || F0 || 00..] || 3DES(ICC MK)[ATC || 0F || 00..].
+	for i, f := range []string{"001CF00000000000", "001C0F0000000000"} {
+		want, _ := crypto.ProcessDES(&crypto.DESParams{Data: mustHex(t, f), Key: iccMK, Mode: crypto.ECB, Encrypt: true})
+		for j := range want {
+			if want[j]&^1 != sk[8*i+j]&^1 {
+				t.Fatalf("DeriveSessionKey() = %X, half %d want %X", sk, i, want)
+			}
+		}
+	}
+	if !crypto.ValidateKeyParity(sk) {
+		t.Errorf("DeriveSessionKey() = %X, want odd parity", sk)
+	}
+
+	if _, err := DeriveSessionKey(iccMK, []byte{0x01}); err == nil {
+		t.Error("DeriveSessionKey() accepted a one-byte ATC")
+	}
+}
+
+func TestGenerateAC(t *testing.T) {
+	// ANSI X9.19 retail MAC example: "Now is the time for all ".
+	data := []byte("Now is the time for all ")
+	got, err := GenerateAC(mustHex(t, issuerMK), data, crypto.ISO97971)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "A1C72E74EA3FA9B6"; !bytes.Equal(got, mustHex(t, want)) {
+		t.Errorf("GenerateAC() = %X, want %s", got, want)
+	}
+
+	// Padding method 2 always adds a block to aligned data.
+	m2, _ := GenerateAC(mustHex(t, issuerMK), data, crypto.ISO97972)
+	if bytes.Equal(m2, got) {
+		t.Error("GenerateAC() ignores the padding method")
+	}
+
+	if _, err := GenerateAC(mustHex(t, issuerMK), nil, crypto.ISO97972); err == nil {
+		t.Error("GenerateAC() accepted empty data")
+	}
+}
+
+func TestGenerateARPC(t *testing.T) {
+	sk := mustHex(t, issuerMK)
+	arqc := mustHex(t, "A1C72E74EA3FA9B6")
+
+	arpc, err := GenerateARPC(sk, arqc, mustHex(t, "3030"), nil, ARPCMethod1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := crypto.ProcessDES(&crypto.DESParams{Data: arpc, Key: sk, Mode: crypto.ECB})
+	if want := mustHex(t, "91F72E74EA3FA9B6"); !bytes.Equal(plain, want) {
+		t.Errorf("method 1: decrypted ARPC = %X, want ARQC XOR ARC %X", plain, want)
+	}
+
+	m2, err := GenerateARPC(sk, arqc, mustHex(t, "00820000"), nil, ARPCMethod2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac, _ := retailMAC(sk, mustHex(t, "A1C72E74EA3FA9B600820000"), crypto.ISO97972)
+	if len(m2) != 4 || !bytes.Equal(m2, mac[:4]) {
+		t.Errorf("method 2: ARPC = %X, want %X", m2, mac[:4])
+	}
+
+	errs := []struct {
+		name   string
+		arc    string
+		method ARPCMethod
+	}{
+		{"method_1_csu", "00820000", ARPCMethod1},
+		{"method_2_arc", "3030", ARPCMethod2},
+	}
+	for _, e := range errs {
+		if _, err := GenerateARPC(sk, arqc, mustHex(t, e.arc), nil, e.method); err == nil {
+			t.Errorf("%s: GenerateARPC() error = nil", e.name)
+		}
+	}
+}
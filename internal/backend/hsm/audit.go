@@ -0,0 +1,51 @@
+package hsm
+
+import (
+	"strconv"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// auditEvent is the log event name for audited HSM commands.
+const auditEvent = "hsm_command"
+
+// EnableAuditLogging registers a command hook on conn that writes an audit
+// entry to l for every command sent: INFO for completed exchanges and ERROR
+// for transport failures. The returned function disables auditing.
+func EnableAuditLogging(conn *Connection, l *logger.Logger) func() {
+	audit := l.WithModule("hsm")
+
+	return conn.RegisterCommandHook(func(event CommandEvent) {
+		fields := map[string]string{
+			"command_code": prefix(event.Command, 2),
+			"payload":      maskPayload(string(event.Command)),
+			"latency_ms":   strconv.FormatInt(event.Latency.Milliseconds(), 10),
+			"endpoint":     event.Endpoint,
+		}
+
+		if event.Err != nil {
+			audit.LogFields(logger.ERROR, auditEvent, "Failed", event.Err.Error(), fields)
+			return
+		}
+
+		fields["response_code"] = prefix(event.Response, 2)
+		if len(event.Response) >= 4 {
+			fields["error_code"] = string(event.Response[2:4])
+		}
+		audit.LogFields(logger.INFO, auditEvent, "Success", "", fields)
+	})
+}
+
+// prefix returns up to n leading bytes of b as a string.
+func prefix(b []byte, n int) string {
+	if len(b) < n {
+		n = len(b)
+	}
+
+	return string(b[:n])
+}
+
+// maskPayload redacts the middle of every key-like hex run in a command.
+func maskPayload(s string) string {
+	return logger.MaskHexRuns(s)
+}
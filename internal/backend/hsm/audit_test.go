@@ -0,0 +1,112 @@
+// nolint:all // test package
+package hsm
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+func TestMaskPayload(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "short_command",
+			input: "NC",
+			want:  "NC",
+		},
+		{
+			name:  "embedded_key",
+			input: "A0U0123456789ABCDEF0123456789ABCDEF",
+			want:  "A0U01" + strings.Repeat("*", 28) + "EF",
+		},
+		{
+			name:  "short_hex_kept",
+			input: "CA0123ABCD",
+			want:  "CA0123ABCD",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskPayload(tt.input); got != tt.want {
+				t.Errorf("maskPayload(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableAuditLogging(t *testing.T) {
+	key := "0123456789ABCDEF0123456789ABCDEF"
+	sendErr := errors.New("broker send failed")
+
+	l, err := logger.NewLogger(filepath.Join(t.TempDir(), "audit.log"), logger.DEBUG, nil)
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer l.Close()
+
+	fail := false
+	c := NewConnection(nil)
+	c.host, c.port = "127.0.0.1", "1500"
+	c.state.Store(int32(Connected))
+	c.broker = &mockBroker{
+		SendFunc: func(request *[]byte) ([]byte, error) {
+			if fail {
+				return nil, sendErr
+			}
+			return []byte("A100" + key), nil
+		},
+	}
+
+	disable := EnableAuditLogging(c, l)
+
+	if _, err := c.ExecuteCommand([]byte("A0U"+key), time.Second); err != nil {
+		t.Fatalf("ExecuteCommand() error = %v", err)
+	}
+	fail = true
+	if _, err := c.ExecuteCommand([]byte("NC"), time.Second); err == nil {
+		t.Fatal("ExecuteCommand() error = nil, want error")
+	}
+
+	disable()
+	fail = false
+	if _, err := c.ExecuteCommand([]byte("NC"), time.Second); err != nil {
+		t.Fatalf("ExecuteCommand() error = %v", err)
+	}
+
+	entries, err := l.GetEntries(time.Time{}, time.Time{}, "hsm_command")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("GetEntries() returned %d entries, want 2", len(entries))
+	}
+
+	ok := entries[0]
+	if ok.Level != logger.INFO || ok.Status != "Success" {
+		t.Errorf("first entry = %s/%s, want INFO/Success", ok.Level, ok.Status)
+	}
+	if ok.Fields["command_code"] != "A0" || ok.Fields["response_code"] != "A1" ||
+		ok.Fields["error_code"] != "00" || ok.Fields["endpoint"] != "127.0.0.1:1500" {
+		t.Errorf("first entry fields = %v", ok.Fields)
+	}
+	if strings.Contains(ok.Fields["payload"], key) {
+		t.Errorf("payload %q contains unmasked key", ok.Fields["payload"])
+	}
+
+	failed := entries[1]
+	if failed.Level != logger.ERROR || failed.Details != sendErr.Error() {
+		t.Errorf("second entry = %s/%q, want ERROR/%q", failed.Level, failed.Details, sendErr.Error())
+	}
+	if _, ok := failed.Fields["response_code"]; ok {
+		t.Errorf("failed entry has response_code field: %v", failed.Fields)
+	}
+}
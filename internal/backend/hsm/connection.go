@@ -2,15 +2,17 @@ package hsm
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"math"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/andrei-cloud/anet"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
 )
 
 // ConnectionState represents the current state of the HSM connection.
@@ -22,6 +24,32 @@ const (
 
 type ConnectionState int32
 
+// hsmLog tags connection entries with the "hsm" module.
+var hsmLog = logger.WithModule("hsm")
+
+// String returns the string representation of a connection state.
+func (s ConnectionState) String() string {
+	switch s {
+	case Disconnected:
+		return "Disconnected"
+	case Connected:
+		return "Connected"
+	case Reconnecting:
+		return "Reconnecting"
+	default:
+		return "Unknown"
+	}
+}
+
+// CommandEvent describes a completed command exchange with the HSM.
+type CommandEvent struct {
+	Endpoint string
+	Command  []byte
+	Response []byte
+	Latency  time.Duration
+	Err      error
+}
+
 // Connection manages the HSM connection using anet broker.
 type Connection struct {
 	mu             sync.RWMutex
@@ -33,35 +61,45 @@ type Connection struct {
 	stateChanged   func(ConnectionState)
 	stateCallbacks []func(state ConnectionState, lastError error)
 	poolCap        uint32
+	options        ConnectOptions
+	tlsConfig      *tls.Config // nil for plain TCP
 	workerCount    int
 	stopChan       chan struct{}
 	lastError      error
 	defaultConfig  *anet.PoolConfig
 	reconnecting   atomic.Bool
 	sendMu         sync.Mutex // serialize command sends
+	commandHooks   map[int]func(CommandEvent)
+	nextHookID     int
+
+	// Health checks; healthStop is guarded by mu.
+	healthMu   sync.RWMutex
+	health     Health
+	healthStop chan struct{}
 }
 
 // NewConnection creates a new HSM connection manager.
 func NewConnection(stateChanged func(ConnectionState)) *Connection {
 	return &Connection{
-		state:        atomic.Int32{},
-		workerCount:  3,
-		stopChan:     make(chan struct{}),
-		stateChanged: stateChanged,
-		defaultConfig: &anet.PoolConfig{
-			DialTimeout:        5 * time.Second,
-			IdleTimeout:        60 * time.Second,
-			ValidationInterval: 30 * time.Second,
-			KeepAliveInterval:  30 * time.Second,
-		},
+		state:         atomic.Int32{},
+		workerCount:   3,
+		stopChan:      make(chan struct{}),
+		stateChanged:  stateChanged,
+		defaultConfig: ConnectOptions{}.poolConfig(),
 	}
 }
 
-// Connect attempts to connect to the HSM.
+// Connect attempts to connect to the HSM with the default command timeout
+// and reconnection policy.
 func (c *Connection) Connect(
 	host, port string,
 	numConns uint32,
 ) error {
+	return c.ConnectWithOptions(DefaultConnectOptions(host, port, numConns))
+}
+
+// ConnectWithOptions attempts to connect to the HSM described by opts.
+func (c *Connection) ConnectWithOptions(opts ConnectOptions) error {
 	if ConnectionState(c.state.Load()) == Connected {
 		return errors.New("already connected")
 	}
@@ -82,12 +120,31 @@ func (c *Connection) Connect(
 		c.pool = nil
 	}
 
-	if numConns < 1 {
-		numConns = 1
+	if opts.Connections < 1 {
+		opts.Connections = 1
+	}
+	if opts.CommandTimeout <= 0 {
+		opts.CommandTimeout = DefaultCommandTimeout
+	}
+	c.options = opts
+	c.defaultConfig = opts.poolConfig()
+	c.poolCap = opts.Connections
+	c.host = opts.Host
+	c.port = opts.Port
+	c.tlsConfig = nil
+	if opts.TLS != nil {
+		cfg, err := opts.TLS.Config()
+		if err != nil {
+			c.lastError = err
+			return err
+		}
+		c.tlsConfig = cfg
+		// The pool dials lazily; shake hands once so TLS problems surface here.
+		if err := c.checkTLS(); err != nil {
+			c.lastError = err
+			return err
+		}
 	}
-	c.poolCap = numConns
-	c.host = host
-	c.port = port
 
 	broker, pool, err := c.createBroker()
 	if err != nil {
@@ -134,6 +191,7 @@ func (c *Connection) Connect(
 
 	c.setState(Connected)
 	c.lastError = nil
+	c.startHealthCheck(opts.HealthCheckInterval)
 
 	return nil
 }
@@ -147,6 +205,7 @@ func (c *Connection) Disconnect() error {
 		return errors.New("already disconnected")
 	}
 
+	c.stopHealthCheck()
 	c.setState(Disconnected)
 
 	if c.pool != nil {
@@ -163,6 +222,27 @@ func (c *Connection) Disconnect() error {
 	return nil
 }
 
+// Options returns the options of the last connection attempt.
+func (c *Connection) Options() ConnectOptions {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.options
+}
+
+// CommandTimeout returns the configured command timeout, or
+// DefaultCommandTimeout before the first connection.
+func (c *Connection) CommandTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.options.CommandTimeout <= 0 {
+		return DefaultCommandTimeout
+	}
+
+	return c.options.CommandTimeout
+}
+
 // GetState returns the current connection state.
 func (c *Connection) GetState() ConnectionState {
 	return ConnectionState(c.state.Load())
@@ -190,6 +270,7 @@ func (c *Connection) GetLastError() error {
 // setState updates the connection state and notifies listeners.
 func (c *Connection) setState(state ConnectionState) {
 	c.state.Store(int32(state))
+	hsmLog.Info("hsm_connection", state.String(), net.JoinHostPort(c.host, c.port))
 	if c.stateChanged != nil {
 		c.stateChanged(state)
 	}
@@ -216,8 +297,68 @@ func (c *Connection) notifyStateChange() {
 	}
 }
 
+// RegisterCommandHook registers a function called after every command sent via
+// ExecuteCommand, whether it succeeded or not. The returned function removes
+// the hook.
+func (c *Connection) RegisterCommandHook(hook func(CommandEvent)) func() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.commandHooks == nil {
+		c.commandHooks = make(map[int]func(CommandEvent))
+	}
+	id := c.nextHookID
+	c.nextHookID++
+	c.commandHooks[id] = hook
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		delete(c.commandHooks, id)
+	}
+}
+
+// notifyCommandHooks calls every registered command hook with the event.
+func (c *Connection) notifyCommandHooks(event CommandEvent) {
+	c.mu.RLock()
+	event.Endpoint = net.JoinHostPort(c.host, c.port)
+	hooks := make([]func(CommandEvent), 0, len(c.commandHooks))
+	for _, hook := range c.commandHooks {
+		hooks = append(hooks, hook)
+	}
+	c.mu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
+
 // ExecuteCommand sends a command to the HSM and returns the response.
 func (c *Connection) ExecuteCommand(command []byte, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return c.ExecuteCommandContext(ctx, command)
+}
+
+// ExecuteCommandContext sends a command to the HSM and returns the response.
+// The request is abandoned when ctx is done.
+func (c *Connection) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	start := time.Now()
+	response, err := c.send(ctx, command)
+
+	c.notifyCommandHooks(CommandEvent{
+		Command:  command,
+		Response: response,
+		Latency:  time.Since(start),
+		Err:      err,
+	})
+
+	return response, err
+}
+
+// send performs a single broker round trip.
+func (c *Connection) send(ctx context.Context, command []byte) ([]byte, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -225,11 +366,9 @@ func (c *Connection) ExecuteCommand(command []byte, timeout time.Duration) ([]by
 		return nil, errors.New("broker is not initialized")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-	defer cancel()
-
 	response, err := c.broker.SendContext(ctx, &command)
 	if err != nil {
+		hsmLog.Error("hsm_command", "Failed", err.Error())
 		return nil, err
 	}
 
@@ -251,16 +390,7 @@ func (c *Connection) createBroker() (anet.Broker, anet.Pool, error) {
 	addr := fmt.Sprintf("%s:%s", c.host, c.port)
 
 	factory := func(address string) (anet.PoolItem, error) {
-		conn, err := net.DialTimeout("tcp", address, c.defaultConfig.DialTimeout)
-		if err != nil {
-			return nil, fmt.Errorf("failed to dial %s: %w", address, err)
-		}
-		if tcpConn, ok := conn.(*net.TCPConn); ok {
-			_ = tcpConn.SetKeepAlive(true)
-			_ = tcpConn.SetKeepAlivePeriod(c.defaultConfig.KeepAliveInterval)
-		}
-
-		return conn, nil
+		return c.dial(address)
 	}
 
 	pool := anet.NewPool(c.poolCap, factory, addr, c.defaultConfig)
@@ -274,6 +404,40 @@ func (c *Connection) createBroker() (anet.Broker, anet.Pool, error) {
 	return broker, pool, nil
 }
 
+// dial opens a connection to address, over TLS when configured.
+func (c *Connection) dial(address string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   c.defaultConfig.DialTimeout,
+		KeepAlive: c.defaultConfig.KeepAliveInterval,
+	}
+	if c.tlsConfig != nil {
+		conn, err := tls.DialWithDialer(dialer, "tcp", address, c.tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("tls handshake with %s failed: %w", address, err)
+		}
+
+		return conn, nil
+	}
+
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial %s: %w", address, err)
+	}
+
+	return conn, nil
+}
+
+// checkTLS dials the HSM once and closes the connection, reporting dial and
+// handshake failures.
+func (c *Connection) checkTLS() error {
+	conn, err := c.dial(net.JoinHostPort(c.host, c.port))
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
 // handleReconnection attempts to reconnect to the HSM.
 func (c *Connection) handleReconnection() {
 	// Ensure only one reconnection attempt runs at a time
@@ -284,22 +448,22 @@ func (c *Connection) handleReconnection() {
 
 	c.mu.Lock()
 	c.state.Store(int32(Reconnecting))
+	hsmLog.Warn("hsm_reconnect", "Started", net.JoinHostPort(c.host, c.port))
 	c.notifyStateChange()
 	c.mu.Unlock()
 
-	// Initialize reconnection parameters
-	maxAttempts := 5
-	backoffBase := time.Second
-	maxBackoff := 30 * time.Second
+	c.mu.RLock()
+	policy := c.options.Reconnect
+	c.mu.RUnlock()
 	attempt := 0
 
-	for attempt < maxAttempts {
-		// Calculate backoff duration with exponential increase
-		backoff := time.Duration(
-			math.Min(float64(backoffBase)*math.Pow(2, float64(attempt)), float64(maxBackoff)),
-		)
-		time.Sleep(backoff)
+	for !policy.Exhausted(attempt) {
+		time.Sleep(policy.Backoff(attempt))
 		attempt++
+		// Disconnect ends an unlimited policy.
+		if ConnectionState(c.state.Load()) != Reconnecting {
+			return
+		}
 
 		// Clean up existing connection
 		c.mu.Lock()
@@ -322,6 +486,7 @@ func (c *Connection) handleReconnection() {
 		if err != nil {
 			c.mu.Lock()
 			c.lastError = fmt.Errorf("reconnection attempt %d failed: %w", attempt, err)
+			hsmLog.Warn("hsm_reconnect", "Failed", c.lastError.Error())
 			c.mu.Unlock()
 
 			continue
@@ -337,6 +502,7 @@ func (c *Connection) handleReconnection() {
 			}
 			c.mu.Lock()
 			c.lastError = fmt.Errorf("broker start failed on attempt %d: %w", attempt, err)
+			hsmLog.Warn("hsm_reconnect", "Failed", c.lastError.Error())
 			c.mu.Unlock()
 
 			continue
@@ -348,6 +514,7 @@ func (c *Connection) handleReconnection() {
 		c.broker = broker
 		c.state.Store(int32(Connected))
 		c.lastError = nil
+		hsmLog.Info("hsm_reconnect", "Success", fmt.Sprintf("attempt %d", attempt))
 		c.notifyStateChange()
 		c.mu.Unlock()
 
@@ -358,8 +525,9 @@ func (c *Connection) handleReconnection() {
 	c.mu.Lock()
 	c.state.Store(int32(Disconnected))
 	if c.lastError == nil {
-		c.lastError = fmt.Errorf("failed to reconnect after %d attempts", maxAttempts)
+		c.lastError = fmt.Errorf("failed to reconnect after %d attempts", attempt)
 	}
+	hsmLog.Error("hsm_reconnect", "Failed", c.lastError.Error())
 	c.notifyStateChange()
 	c.mu.Unlock()
 }
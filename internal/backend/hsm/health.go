@@ -0,0 +1,124 @@
+package hsm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// healthCommand is the diagnostics command sent by health checks.
+const healthCommand = "NC"
+
+// Health is the outcome of the periodic health checks of a connection.
+type Health struct {
+	Enabled bool          // Health checks run on this connection.
+	RTT     time.Duration // Round trip of the last answered check.
+	// LastResponse is when the HSM last answered a check, or when the
+	// connection was made before the first answer.
+	LastResponse time.Time
+	Failures     int   // Consecutive failed checks.
+	Err          error // Error of the last failed check.
+}
+
+// GetHealth returns the outcome of the health checks so far.
+func (c *Connection) GetHealth() Health {
+	c.healthMu.RLock()
+	defer c.healthMu.RUnlock()
+
+	return c.health
+}
+
+// startHealthCheck sends NC every interval while connected, replacing any
+// running checks. A zero interval turns checks off.
+func (c *Connection) startHealthCheck(interval time.Duration) {
+	c.stopHealthCheck()
+	if interval <= 0 {
+		return
+	}
+
+	c.healthMu.Lock()
+	c.health = Health{Enabled: true, LastResponse: time.Now()}
+	c.healthMu.Unlock()
+
+	stop := make(chan struct{})
+	c.healthStop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if c.GetState() == Connected {
+					c.checkHealth()
+				}
+			}
+		}
+	}()
+}
+
+// stopHealthCheck ends the running health checks, if any, and clears their
+// outcome.
+func (c *Connection) stopHealthCheck() {
+	if c.healthStop != nil {
+		close(c.healthStop)
+		c.healthStop = nil
+	}
+
+	c.healthMu.Lock()
+	c.health = Health{}
+	c.healthMu.Unlock()
+}
+
+// checkHealth sends one NC and records the outcome. Checks bypass command
+// hooks, so they stay out of the audit log.
+func (c *Connection) checkHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.CommandTimeout())
+	defer cancel()
+
+	start := time.Now()
+	resp, err := c.send(ctx, []byte(healthCommand))
+	rtt := time.Since(start)
+	if err == nil {
+		err = checkHealthResponse(resp)
+	}
+
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	if !c.health.Enabled {
+		return // Stopped while the check was in flight.
+	}
+	if err != nil {
+		c.health.Failures++
+		c.health.Err = err
+		if c.health.Failures == 1 {
+			hsmLog.Warn("hsm_health", "Failed", err.Error())
+		}
+
+		return
+	}
+	if c.health.Failures > 0 {
+		hsmLog.Info("hsm_health", "Recovered", fmt.Sprintf("after %d failed checks", c.health.Failures))
+	}
+	c.health.RTT = rtt
+	c.health.LastResponse = time.Now()
+	c.health.Failures = 0
+	c.health.Err = nil
+}
+
+// checkHealthResponse accepts any ND response: an HSM reporting an error
+// still answers.
+func checkHealthResponse(resp []byte) error {
+	status, err := utils.ParseHSMResponse(resp)
+	if err != nil {
+		return err
+	}
+	if status.ResponseCode != "ND" {
+		return fmt.Errorf("unexpected response code: %s", status.ResponseCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// nolint:all // test package
+package hsm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConnection_CheckHealth(t *testing.T) {
+	var reply []byte
+	var sendErr error
+	c := NewConnection(nil)
+	c.broker = &mockBroker{SendFunc: func(request *[]byte) ([]byte, error) {
+		if string(*request) != healthCommand {
+			t.Errorf("health check sent %q", *request)
+		}
+		time.Sleep(time.Millisecond)
+		return reply, sendErr
+	}}
+	c.state.Store(int32(Connected))
+
+	if h := c.GetHealth(); h.Enabled {
+		t.Fatalf("GetHealth() before start = %+v", h)
+	}
+	c.startHealthCheck(time.Hour)
+	defer c.stopHealthCheck()
+	connected := c.GetHealth().LastResponse
+
+	steps := []struct {
+		name         string
+		reply        []byte
+		err          error
+		wantFailures int
+	}{
+		{"answer", []byte("ND007B44AC1DDEE2A94B0007-E000"), nil, 0},
+		{"no_answer", nil, errors.New("timeout"), 1},
+		{"hsm_error_still_answers", []byte("ND68"), nil, 0},
+		{"wrong_response", []byte("NE00"), nil, 1},
+		{"short_response", []byte("N"), nil, 2},
+	}
+	for _, step := range steps {
+		reply, sendErr = step.reply, step.err
+		before := c.GetHealth()
+		c.checkHealth()
+		h := c.GetHealth()
+
+		if !h.Enabled || h.Failures != step.wantFailures || (h.Err == nil) != (step.wantFailures == 0) {
+			t.Fatalf("%s: GetHealth() = %+v, want %d failures", step.name, h, step.wantFailures)
+		}
+		if step.wantFailures == 0 && (h.RTT <= 0 || !h.LastResponse.After(connected)) {
+			t.Errorf("%s: answered check not recorded: %+v", step.name, h)
+		}
+		if step.wantFailures > 0 && (h.RTT != before.RTT || h.LastResponse != before.LastResponse) {
+			t.Errorf("%s: failed check changed the last answer: %+v", step.name, h)
+		}
+	}
+
+	c.stopHealthCheck()
+	c.checkHealth()
+	if h := c.GetHealth(); h != (Health{}) {
+		t.Errorf("GetHealth() after stop = %+v, want zero", h)
+	}
+}
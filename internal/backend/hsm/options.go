@@ -0,0 +1,169 @@
+package hsm
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/andrei-cloud/anet"
+)
+
+// Defaults used when ConnectOptions leaves a value unset.
+const (
+	DefaultCommandTimeout    = 5 * time.Second
+	DefaultReconnectAttempts = 5
+	DefaultMaxBackoff        = 30 * time.Second
+
+	DefaultDialTimeout        = 5 * time.Second
+	DefaultIdleTimeout        = 60 * time.Second
+	DefaultKeepAliveInterval  = 30 * time.Second
+	DefaultValidationInterval = 30 * time.Second
+)
+
+// reconnectBackoffBase is the wait before the first reconnection attempt. It
+// doubles with every attempt up to the policy's MaxBackoff.
+const reconnectBackoffBase = time.Second
+
+// ReconnectPolicy controls how a dropped connection is re-established.
+type ReconnectPolicy struct {
+	MaxAttempts int           // 0 retries until Disconnect is called.
+	MaxBackoff  time.Duration // Longest wait between attempts.
+}
+
+// DefaultReconnectPolicy returns the policy used by Connect.
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{MaxAttempts: DefaultReconnectAttempts, MaxBackoff: DefaultMaxBackoff}
+}
+
+// Backoff returns the wait before the 0-based attempt.
+func (p ReconnectPolicy) Backoff(attempt int) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+
+	backoff := reconnectBackoffBase
+	for i := 0; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxBackoff)
+}
+
+// Exhausted reports whether attempts attempts use up the policy.
+func (p ReconnectPolicy) Exhausted(attempts int) bool {
+	return p.MaxAttempts > 0 && attempts >= p.MaxAttempts
+}
+
+// ConnectOptions configures a connection.
+type ConnectOptions struct {
+	Host        string
+	Port        string
+	Connections uint32 // Pool size; at least 1.
+
+	// CommandTimeout bounds commands sent without an explicit timeout.
+	CommandTimeout time.Duration
+	Reconnect      ReconnectPolicy
+
+	TLS *TLSOptions // Plain TCP when nil.
+
+	// Pool tuning; zero keeps the default.
+	IdleTimeout        time.Duration
+	KeepAliveInterval  time.Duration
+	ValidationInterval time.Duration
+
+	// HealthCheckInterval is how often NC is sent to measure the round
+	// trip; zero turns health checks off.
+	HealthCheckInterval time.Duration
+}
+
+// CheckTuning reports pool tuning that defeats itself, such as keep-alives
+// sent no more often than idle connections are dropped.
+func (o ConnectOptions) CheckTuning() error {
+	cfg := o.poolConfig()
+	if cfg.KeepAliveInterval >= cfg.IdleTimeout {
+		return fmt.Errorf("keep-alive interval %s is not shorter than idle timeout %s",
+			cfg.KeepAliveInterval, cfg.IdleTimeout)
+	}
+	if cfg.ValidationInterval >= cfg.IdleTimeout {
+		return fmt.Errorf("validation interval %s is not shorter than idle timeout %s",
+			cfg.ValidationInterval, cfg.IdleTimeout)
+	}
+
+	return nil
+}
+
+// poolConfig returns the connection pool configuration for o.
+func (o ConnectOptions) poolConfig() *anet.PoolConfig {
+	orDefault := func(d, def time.Duration) time.Duration {
+		if d > 0 {
+			return d
+		}
+
+		return def
+	}
+
+	return &anet.PoolConfig{
+		DialTimeout:        DefaultDialTimeout,
+		IdleTimeout:        orDefault(o.IdleTimeout, DefaultIdleTimeout),
+		ValidationInterval: orDefault(o.ValidationInterval, DefaultValidationInterval),
+		KeepAliveInterval:  orDefault(o.KeepAliveInterval, DefaultKeepAliveInterval),
+	}
+}
+
+// TLSOptions configures TLS on the HSM connection.
+type TLSOptions struct {
+	CAFile             string // PEM CA bundle; the system roots when empty.
+	CertFile           string // Optional PEM client certificate.
+	KeyFile            string // Key of CertFile.
+	ServerName         string // Overrides the host name the certificate is checked against.
+	InsecureSkipVerify bool
+}
+
+// Config loads the files of o into a TLS client configuration.
+func (o TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+
+	if o.CAFile != "" {
+		pem, err := os.ReadFile(o.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in %s", o.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (o.CertFile == "") != (o.KeyFile == "") {
+		return nil, errors.New("client certificate and key must be given together")
+	}
+	if o.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(o.CertFile, o.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// DefaultConnectOptions returns the options used by Connect.
+func DefaultConnectOptions(host, port string, connections uint32) ConnectOptions {
+	return ConnectOptions{
+		Host:           host,
+		Port:           port,
+		Connections:    connections,
+		CommandTimeout: DefaultCommandTimeout,
+		Reconnect:      DefaultReconnectPolicy(),
+	}
+}
@@ -0,0 +1,142 @@
+// nolint:all // test package
+package hsm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ReconnectPolicy
+		attempt int
+		want    time.Duration
+	}{
+		{"first", DefaultReconnectPolicy(), 0, time.Second},
+		{"doubles", DefaultReconnectPolicy(), 3, 8 * time.Second},
+		{"capped", DefaultReconnectPolicy(), 10, 30 * time.Second},
+		{"custom_cap", ReconnectPolicy{MaxBackoff: 5 * time.Second}, 4, 5 * time.Second},
+		{"cap_below_base", ReconnectPolicy{MaxBackoff: 500 * time.Millisecond}, 0, 500 * time.Millisecond},
+		{"unset_cap", ReconnectPolicy{}, 20, DefaultMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.attempt); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconnectPolicy_Exhausted(t *testing.T) {
+	limited := ReconnectPolicy{MaxAttempts: 3}
+	if limited.Exhausted(2) || !limited.Exhausted(3) {
+		t.Errorf("MaxAttempts 3: Exhausted(2) = %v, Exhausted(3) = %v", limited.Exhausted(2), limited.Exhausted(3))
+	}
+	if (ReconnectPolicy{}).Exhausted(1000) {
+		t.Error("MaxAttempts 0 must retry forever")
+	}
+}
+
+func TestConnection_Options(t *testing.T) {
+	c := NewConnection(nil)
+	if got := c.CommandTimeout(); got != DefaultCommandTimeout {
+		t.Errorf("CommandTimeout() before connecting = %v, want %v", got, DefaultCommandTimeout)
+	}
+
+	opts := ConnectOptions{
+		Host:           "127.0.0.1",
+		Port:           "1",
+		CommandTimeout: 750 * time.Millisecond,
+		Reconnect:      ReconnectPolicy{MaxAttempts: 1, MaxBackoff: 10 * time.Second},
+	}
+	_ = c.ConnectWithOptions(opts)
+	defer c.Disconnect()
+
+	got := c.Options()
+	opts.Connections = 1 // Raised to the minimum pool size.
+	if got != opts {
+		t.Errorf("Options() = %+v, want %+v", got, opts)
+	}
+	if c.CommandTimeout() != 750*time.Millisecond {
+		t.Errorf("CommandTimeout() = %v, want 750ms", c.CommandTimeout())
+	}
+}
+
+func TestTLSOptions_Config(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.txt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		opts    TLSOptions
+		wantErr bool
+	}{
+		{"system_roots", TLSOptions{ServerName: "hsm.local"}, false},
+		{"missing_ca", TLSOptions{CAFile: filepath.Join(dir, "none.pem")}, true},
+		{"ca_not_pem", TLSOptions{CAFile: notPEM}, true},
+		{"cert_without_key", TLSOptions{CertFile: notPEM}, true},
+		{"unreadable_pair", TLSOptions{CertFile: notPEM, KeyFile: notPEM}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.opts.Config()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Config() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (cfg.ServerName != tt.opts.ServerName || cfg.RootCAs != nil) {
+				t.Errorf("Config() = %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestConnectOptions_Tuning(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ConnectOptions
+		want    [3]time.Duration // Idle, keep-alive, validation.
+		wantErr bool
+	}{
+		{"defaults", ConnectOptions{}, [3]time.Duration{60 * time.Second, 30 * time.Second, 30 * time.Second}, false},
+		{
+			"firewall_120s",
+			ConnectOptions{IdleTimeout: 110 * time.Second, KeepAliveInterval: 20 * time.Second},
+			[3]time.Duration{110 * time.Second, 20 * time.Second, 30 * time.Second},
+			false,
+		},
+		{
+			"keepalive_not_shorter",
+			ConnectOptions{KeepAliveInterval: 60 * time.Second},
+			[3]time.Duration{60 * time.Second, 60 * time.Second, 30 * time.Second},
+			true,
+		},
+		{
+			"idle_below_default_keepalive",
+			ConnectOptions{IdleTimeout: 20 * time.Second},
+			[3]time.Duration{20 * time.Second, 30 * time.Second, 30 * time.Second},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.opts.poolConfig()
+			got := [3]time.Duration{cfg.IdleTimeout, cfg.KeepAliveInterval, cfg.ValidationInterval}
+			if got != tt.want || cfg.DialTimeout != DefaultDialTimeout {
+				t.Errorf("poolConfig() = %+v, want %v", cfg, tt.want)
+			}
+			if err := tt.opts.CheckTuning(); (err != nil) != tt.wantErr {
+				t.Errorf("CheckTuning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+// Package keyblock parses TR-31 and Thales key blocks and unwraps TR-31 key
+// blocks under their key block protection key (KBPK).
+package keyblock
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// headerLength is the length of the fixed key block header.
+const headerLength = 16
+
+// Format is the kind of a key block.
+type Format int
+
+// Key block formats.
+const (
+	TR31   Format = iota // ANSI TR-31 / X9.143.
+	Thales               // Thales key block, tagged 'S'.
+)
+
+// String names f.
+func (f Format) String() string {
+	if f == Thales {
+		return "Thales"
+	}
+
+	return "TR-31"
+}
+
+// Errors returned by Unwrap.
+var (
+	ErrNotTR31 = errors.New("only TR-31 key blocks can be unwrapped with a KBPK")
+	ErrMAC     = errors.New("key block MAC does not verify: wrong KBPK or corrupted key block")
+)
+
+// Versions describes the TR-31 key block version IDs and the Thales key
+// block versions.
+var Versions = map[Format]map[byte]string{
+	TR31: {
+		'A': "TDES key variant binding (deprecated)",
+		'B': "TDES key derivation binding",
+		'C': "TDES key variant binding",
+		'D': "AES key derivation binding",
+	},
+	Thales: {
+		'0': "protected by a 3DES LMK",
+		'1': "protected by an AES LMK",
+	},
+}
+
+// OptionalBlock is an optional header block.
+type OptionalBlock struct {
+	ID   string
+	Data string
+}
+
+// KeyBlock is a parsed key block.
+type KeyBlock struct {
+	Format         Format
+	Version        byte
+	Length         int // Length declared in the header.
+	KeyUsage       string
+	Algorithm      byte
+	ModeOfUse      byte
+	KeyVersion     string
+	Exportability  byte
+	OptionalBlocks []OptionalBlock
+	// Reserved is the TR-31 reserved field, or the LMK identifier of a
+	// Thales key block.
+	Reserved string
+
+	// Raw is the key block without the Thales 'S' tag.
+	Raw string
+	// HeaderLength is the length of the header including optional blocks.
+	HeaderLength int
+}
+
+// LengthOK reports whether the key block is as long as its header says.
+func (kb KeyBlock) LengthOK() bool {
+	return len(kb.Raw) == kb.Length
+}
+
+// Parse decodes the header of a TR-31 key block, or of a Thales key block
+// when s starts with 'S'. White space is ignored. A length that does not
+// match the header is not an error; see LengthOK.
+func Parse(s string) (KeyBlock, error) {
+	s = strings.Join(strings.Fields(s), "")
+	if s == "" {
+		return KeyBlock{}, errors.New("key block is empty")
+	}
+
+	var kb KeyBlock
+	if s[0] == 'S' {
+		h, err := utils.ParseThalesKeyBlock(s)
+		if err != nil {
+			return KeyBlock{}, err
+		}
+		kb = KeyBlock{
+			Format:        Thales,
+			Version:       h.Version,
+			Length:        h.Length,
+			KeyUsage:      h.KeyUsage,
+			Algorithm:     h.Algorithm,
+			ModeOfUse:     h.ModeOfUse,
+			KeyVersion:    h.KeyVersion,
+			Exportability: h.Exportability,
+			Reserved:      h.LMKID,
+			Raw:           s[1:],
+		}
+	} else {
+		var err error
+		if kb, err = parseTR31Header(s); err != nil {
+			return KeyBlock{}, err
+		}
+	}
+
+	count, err := strconv.Atoi(kb.Raw[12:14])
+	if err != nil {
+		return KeyBlock{}, fmt.Errorf("invalid number of optional blocks %q", kb.Raw[12:14])
+	}
+	blocks, n, err := parseOptionalBlocks(kb.Raw[headerLength:], count)
+	if err != nil {
+		return KeyBlock{}, err
+	}
+	kb.OptionalBlocks = blocks
+	kb.HeaderLength = headerLength + n
+
+	return kb, nil
+}
+
+// parseTR31Header decodes the fixed header of a TR-31 key block.
+func parseTR31Header(s string) (KeyBlock, error) {
+	if len(s) < headerLength {
+		return KeyBlock{}, fmt.Errorf(
+			"key block too short: got %d characters, want at least %d", len(s), headerLength)
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7E {
+			return KeyBlock{}, fmt.Errorf("invalid character at position %d", i+1)
+		}
+	}
+	if _, ok := Versions[TR31][s[0]]; !ok {
+		return KeyBlock{}, fmt.Errorf("unsupported key block version %q", s[0])
+	}
+	length, err := strconv.Atoi(s[1:5])
+	if err != nil || length < headerLength {
+		return KeyBlock{}, fmt.Errorf("invalid key block length %q", s[1:5])
+	}
+
+	return KeyBlock{
+		Format:        TR31,
+		Version:       s[0],
+		Length:        length,
+		KeyUsage:      s[5:7],
+		Algorithm:     s[7],
+		ModeOfUse:     s[8],
+		KeyVersion:    s[9:11],
+		Exportability: s[11],
+		Reserved:      s[14:16],
+		Raw:           s,
+	}, nil
+}
+
+// parseOptionalBlocks decodes count optional blocks at the start of s and
+// returns them with their total length. A block length of 00 introduces an
+// extended length: two digits giving the size in bytes of the length that
+// follows.
+func parseOptionalBlocks(s string, count int) ([]OptionalBlock, int, error) {
+	var blocks []OptionalBlock
+	pos := 0
+	for i := 1; i <= count; i++ {
+		if len(s)-pos < 4 {
+			return nil, 0, fmt.Errorf("optional block %d is truncated", i)
+		}
+		id := s[pos : pos+2]
+		size, err := strconv.ParseUint(s[pos+2:pos+4], 16, 8)
+		if err != nil {
+			return nil, 0, fmt.Errorf("optional block %s: invalid length %q", id, s[pos+2:pos+4])
+		}
+		head := 4
+		if size == 0 {
+			if len(s)-pos < 6 {
+				return nil, 0, fmt.Errorf("optional block %s is truncated", id)
+			}
+			n, err := strconv.ParseUint(s[pos+4:pos+6], 16, 8)
+			digits := 2 * int(n)
+			if err != nil || n == 0 || n > 4 || len(s)-pos < 6+digits {
+				return nil, 0, fmt.Errorf("optional block %s: invalid extended length", id)
+			}
+			if size, err = strconv.ParseUint(s[pos+6:pos+6+digits], 16, 32); err != nil {
+				return nil, 0, fmt.Errorf("optional block %s: invalid extended length", id)
+			}
+			head = 6 + digits
+		}
+		if int(size) < head || len(s)-pos < int(size) {
+			return nil, 0, fmt.Errorf("optional block %s: length %d exceeds the key block", id, size)
+		}
+		blocks = append(blocks, OptionalBlock{ID: id, Data: s[pos+head : pos+int(size)]})
+		pos += int(size)
+	}
+
+	return blocks, pos, nil
+}
+
+// Payload is the key recovered from a key block.
+type Payload struct {
+	Key []byte
+	KCV string // Empty when the algorithm has no check value.
+}
+
+// Unwrap verifies the MAC of a TR-31 key block and decrypts its key under
+// kbpk. Versions A and C use TDES key variants, B TDES and D AES derived
+// keys.
+func Unwrap(kb KeyBlock, kbpk []byte) (Payload, error) {
+	if kb.Format != TR31 {
+		return Payload{}, ErrNotTR31
+	}
+	if !kb.LengthOK() {
+		return Payload{}, fmt.Errorf(
+			"key block has %d characters, its header says %d", len(kb.Raw), kb.Length)
+	}
+
+	var macLen int
+	switch kb.Version {
+	case 'A', 'C':
+		macLen = 4
+	case 'B':
+		macLen = 8
+	case 'D':
+		macLen = 16
+	}
+	header := []byte(kb.Raw[:kb.HeaderLength])
+	body, err := hex.DecodeString(kb.Raw[kb.HeaderLength:])
+	if err != nil || len(body) <= macLen {
+		return Payload{}, errors.New("key block data must be hex followed by the MAC")
+	}
+	enc, mac := body[:len(body)-macLen], body[len(body)-macLen:]
+
+	var plain []byte
+	switch kb.Version {
+	case 'A', 'C':
+		plain, err = unwrapVariant(kbpk, header, enc, mac)
+	case 'B':
+		plain, err = unwrapDerived(kbpk, header, enc, mac, false)
+	case 'D':
+		plain, err = unwrapDerived(kbpk, header, enc, mac, true)
+	}
+	if err != nil {
+		return Payload{}, err
+	}
+
+	return payload(plain, kb.Algorithm)
+}
+
+// unwrapVariant decrypts a version A or C key block. The encryption and MAC
+// keys are the KBPK XORed with 'E' and 'M'; the IV is the start of the
+// header and the MAC a TDES CBC-MAC over header and encrypted data.
+func unwrapVariant(kbpk, header, enc, mac []byte) ([]byte, error) {
+	if len(kbpk) != 16 && len(kbpk) != 24 {
+		return nil, errors.New("KBPK must be a double or triple length TDES key")
+	}
+	kbek := xorKey(kbpk, 0x45)
+	kbak := xorKey(kbpk, 0x4D)
+
+	cbc, err := crypto.ProcessDES(&crypto.DESParams{
+		Data:    append(append([]byte{}, header...), enc...),
+		Key:     kbak,
+		IV:      make([]byte, 8),
+		Mode:    crypto.CBC,
+		Padding: crypto.ISO97971,
+		Encrypt: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(cbc[len(cbc)-8:][:len(mac)], mac) != 1 {
+		return nil, ErrMAC
+	}
+
+	return crypto.ProcessDES(&crypto.DESParams{
+		Data: enc, Key: kbek, IV: header[:8], Mode: crypto.CBC, Padding: crypto.NoPadding,
+	})
+}
+
+// unwrapDerived decrypts a version B (TDES) or D (AES) key block. The
+// encryption and MAC keys are derived from the KBPK with CMAC; the MAC is
+// the CMAC of header and clear key data, and the IV of the decryption.
+func unwrapDerived(kbpk, header, enc, mac []byte, useAES bool) ([]byte, error) {
+	cmac := crypto.TDESCMAC
+	var alg uint16
+	switch {
+	case !useAES && len(kbpk) == 16:
+		alg = 0x0000
+	case !useAES && len(kbpk) == 24:
+		alg = 0x0001
+	case useAES && (len(kbpk) == 16 || len(kbpk) == 24 || len(kbpk) == 32):
+		cmac = crypto.CMAC
+		alg = uint16(len(kbpk) / 8) // 0002, 0003 or 0004 for AES-128, -192, -256.
+	default:
+		if useAES {
+			return nil, errors.New("KBPK must be an AES-128, AES-192 or AES-256 key")
+		}
+
+		return nil, errors.New("KBPK must be a double or triple length TDES key")
+	}
+
+	kbek, kbak, err := deriveKeys(kbpk, alg, cmac)
+	if err != nil {
+		return nil, err
+	}
+
+	var plain []byte
+	if useAES {
+		plain, err = crypto.ProcessAES(&crypto.AESParams{
+			Data: enc, Key: kbek, IV: mac, Mode: crypto.CBC, Padding: crypto.NoPadding,
+		})
+	} else {
+		plain, err = crypto.ProcessDES(&crypto.DESParams{
+			Data: enc, Key: kbek, IV: mac, Mode: crypto.CBC, Padding: crypto.NoPadding,
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt key block: %v", err)
+	}
+
+	want, err := cmac(kbak, append(append([]byte{}, header...), plain...))
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(want[:len(mac)], mac) != 1 {
+		return nil, ErrMAC
+	}
+
+	return plain, nil
+}
+
+// deriveKeys derives the encryption and MAC keys from kbpk as TR-31
+// specifies: CMACs of a counter, the key usage (0000 encryption, 0001 MAC),
+// a separator, the algorithm alg and the key length in bits.
+func deriveKeys(kbpk []byte, alg uint16, cmac func(key, data []byte) ([]byte, error)) ([]byte, []byte, error) {
+	input := make([]byte, 8)
+	binary.BigEndian.PutUint16(input[4:6], alg)
+	binary.BigEndian.PutUint16(input[6:8], uint16(len(kbpk)*8))
+
+	keys := make([][]byte, 2)
+	for usage := range keys {
+		input[2] = byte(usage)
+		var key []byte
+		for counter := byte(1); len(key) < len(kbpk); counter++ {
+			input[0] = counter
+			mac, err := cmac(kbpk, input)
+			if err != nil {
+				return nil, nil, err
+			}
+			key = append(key, mac...)
+		}
+		keys[usage] = key[:len(kbpk)]
+	}
+
+	return keys[0], keys[1], nil
+}
+
+// payload extracts the key from decrypted key data: a two-byte key length
+// in bits, the key and padding.
+func payload(plain []byte, algorithm byte) (Payload, error) {
+	if len(plain) < 2 {
+		return Payload{}, errors.New("key block payload is empty")
+	}
+	bits := int(binary.BigEndian.Uint16(plain))
+	if bits == 0 || bits%8 != 0 || bits/8 > len(plain)-2 {
+		return Payload{}, fmt.Errorf("invalid key length %d bits in payload", bits)
+	}
+	p := Payload{Key: bytes.Clone(plain[2 : 2+bits/8])}
+
+	var err error
+	switch algorithm {
+	case 'A':
+		p.KCV, err = crypto.CalculateAESKCV(p.Key)
+	case 'D', 'T':
+		p.KCV, err = crypto.CalculateKCV(p.Key)
+	}
+	if err != nil {
+		return Payload{}, err
+	}
+
+	return p, nil
+}
+
+// xorKey returns key with every byte XORed with b.
+func xorKey(key []byte, b byte) []byte {
+	out := make([]byte, len(key))
+	for i := range key {
+		out[i] = key[i] ^ b
+	}
+
+	return out
+}
@@ -0,0 +1,183 @@
+// nolint:all // test package
+package keyblock
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TR-31 (X9.143) example key blocks.
+var examples = []struct {
+	name     string
+	kbpk     string
+	keyBlock string
+	key      string
+}{
+	{
+		"version_b",
+		"DD7515F2BFC17F85CE48F3CA25CB21F6",
+		"B0080P0TE00E000094B420079CC80BA3461F86FE26EFC4A3B8E4FA4C5F5341176EED7B727B8A248E",
+		"3F419E1CB7079442AA37474C2EFBF8B8",
+	},
+	{
+		"version_c_optional_block",
+		"B8ED59E0A279A295E9F5ED7944FD06B9",
+		"C0096B0TX12S0100KS1800604B120F9292800000BFB9B689CB567E66FC3FEE5AD5F52161FC6545B9D60989015D02155C",
+		"EDB380DD340BC2620247D445F5B8D678",
+	},
+	{
+		"version_d",
+		"88E1AB2A2E3DD38C1FA039A536500CC8A87AB9D62DC92C01058FA79F44657DE6",
+		"D0112P0AE00E0000B82679114F470F540165EDFBF7E250FCEA43F810D215F8D207E2E417C07156A27E8E31DA05F7425509593D03A457DC34",
+		"3F419E1CB7079442AA37474C2EFBF8B8",
+	},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    KeyBlock
+		wantErr string
+	}{
+		{
+			name:  "tr31",
+			input: examples[0].keyBlock,
+			want: KeyBlock{
+				Format: TR31, Version: 'B', Length: 80, KeyUsage: "P0", Algorithm: 'T', ModeOfUse: 'E',
+				KeyVersion: "00", Exportability: 'E', Reserved: "00", HeaderLength: 16,
+			},
+		},
+		{
+			name:  "tr31_optional_block",
+			input: examples[1].keyBlock,
+			want: KeyBlock{
+				Format: TR31, Version: 'C', Length: 96, KeyUsage: "B0", Algorithm: 'T', ModeOfUse: 'X',
+				KeyVersion: "12", Exportability: 'S', Reserved: "00", HeaderLength: 40,
+				OptionalBlocks: []OptionalBlock{{ID: "KS", Data: "00604B120F9292800000"}},
+			},
+		},
+		{
+			name:  "extended_length",
+			input: "D0048K0AB00E0100HM0002000CFF" + "00000000000000000000",
+			want: KeyBlock{
+				Format: TR31, Version: 'D', Length: 48, KeyUsage: "K0", Algorithm: 'A', ModeOfUse: 'B',
+				KeyVersion: "00", Exportability: 'E', Reserved: "00", HeaderLength: 28,
+				OptionalBlocks: []OptionalBlock{{ID: "HM", Data: "FF"}},
+			},
+		},
+		{
+			name:  "thales",
+			input: "S10096K0TB00S0000" + strings.Repeat("0", 80),
+			want: KeyBlock{
+				Format: Thales, Version: '1', Length: 96, KeyUsage: "K0", Algorithm: 'T', ModeOfUse: 'B',
+				KeyVersion: "00", Exportability: 'S', Reserved: "00", HeaderLength: 16,
+			},
+		},
+		{
+			name:  "length_mismatch_is_parsed",
+			input: "B0096P0TE00E0000ABCD",
+			want: KeyBlock{
+				Format: TR31, Version: 'B', Length: 96, KeyUsage: "P0", Algorithm: 'T', ModeOfUse: 'E',
+				KeyVersion: "00", Exportability: 'E', Reserved: "00", HeaderLength: 16,
+			},
+		},
+		{name: "empty", input: "  ", wantErr: "empty"},
+		{name: "short", input: "B0080P0TE00", wantErr: "too short"},
+		{name: "version", input: "X0080P0TE00E0000", wantErr: "version"},
+		{name: "length", input: "B00X0P0TE00E0000", wantErr: "length"},
+		{name: "optional_count", input: "B0080P0TE00EXX00", wantErr: "optional blocks"},
+		{name: "optional_truncated", input: "B0080P0TE00E0100KS", wantErr: "truncated"},
+		{name: "optional_overrun", input: "B0080P0TE00E0100KS40AB", wantErr: "exceeds"},
+		{name: "control_character", input: "B0080P0TE00E00\x0100", wantErr: "invalid character"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got.Raw = ""
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyBlock_LengthOK(t *testing.T) {
+	for _, ex := range examples {
+		kb, err := Parse(ex.keyBlock)
+		if err != nil || !kb.LengthOK() {
+			t.Errorf("%s: LengthOK() = false, err %v", ex.name, err)
+		}
+	}
+	kb, _ := Parse(examples[0].keyBlock + "00")
+	if kb.LengthOK() {
+		t.Error("LengthOK() = true for a longer key block")
+	}
+}
+
+func TestUnwrap(t *testing.T) {
+	for _, ex := range examples {
+		t.Run(ex.name, func(t *testing.T) {
+			kb, err := Parse(ex.keyBlock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			kbpk, _ := hex.DecodeString(ex.kbpk)
+			p, err := Unwrap(kb, kbpk)
+			if err != nil {
+				t.Fatalf("Unwrap() error = %v", err)
+			}
+			if got := strings.ToUpper(hex.EncodeToString(p.Key)); got != ex.key {
+				t.Errorf("Unwrap() key = %s, want %s", got, ex.key)
+			}
+			if len(p.KCV) != 6 {
+				t.Errorf("Unwrap() KCV = %q", p.KCV)
+			}
+
+			kbpk[0] ^= 0x02 // Not a DES parity bit.
+			if _, err := Unwrap(kb, kbpk); !errors.Is(err, ErrMAC) {
+				t.Errorf("Unwrap() with a wrong KBPK error = %v, want ErrMAC", err)
+			}
+		})
+	}
+}
+
+func TestUnwrap_Errors(t *testing.T) {
+	tr31, _ := Parse(examples[0].keyBlock)
+	aes, _ := Parse(examples[2].keyBlock)
+	thales, _ := Parse("S10096K0TB00S0000" + strings.Repeat("0", 80))
+	short, _ := Parse(examples[0].keyBlock[:70])
+	tdesKey := make([]byte, 16)
+
+	tests := []struct {
+		name string
+		kb   KeyBlock
+		kbpk []byte
+		want string
+	}{
+		{"thales", thales, tdesKey, "only TR-31"},
+		{"length", short, tdesKey, "header says"},
+		{"tdes_kbpk_length", tr31, make([]byte, 8), "double or triple length"},
+		{"aes_kbpk_length", aes, make([]byte, 8), "AES-128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unwrap(tt.kb, tt.kbpk); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Unwrap() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
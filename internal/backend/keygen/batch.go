@@ -0,0 +1,127 @@
+// Package keygen generates batches of keys in the HSM.
+package keygen
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// MaxBatchSize is the largest number of keys generated in one batch.
+const MaxBatchSize = 500
+
+// ErrDisconnected aborts a batch when the HSM connection drops.
+var ErrDisconnected = errors.New("hsm connection lost")
+
+// Batch describes keys generated one after another with the same command.
+type Batch struct {
+	Prefix  string                                 // Key names are Prefix followed by a counter.
+	Count   int                                    // Number of keys, at most MaxBatchSize.
+	Command []byte                                 // Command generating one key.
+	Parse   func([]byte) (utils.A0Response, error) // Parses the response to Command.
+	Timeout time.Duration                          // Per command timeout.
+}
+
+// Result is the outcome of generating one key of a batch.
+type Result struct {
+	Seq  int // 1-based position in the batch.
+	Name string
+	Key  utils.A0Response
+	Err  error
+}
+
+// ParseCount parses a batch size between 1 and MaxBatchSize.
+func ParseCount(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > MaxBatchSize {
+		return 0, fmt.Errorf("count must be a number between 1 and %d", MaxBatchSize)
+	}
+
+	return n, nil
+}
+
+// Names returns the key names of a batch of count keys: prefix followed by a
+// counter zero-padded to the width of count, e.g. zpk-01 to zpk-50.
+func Names(prefix string, count int) []string {
+	width := len(strconv.Itoa(count))
+	names := make([]string, count)
+	for i := range names {
+		names[i] = fmt.Sprintf("%s%0*d", prefix, width, i+1)
+	}
+
+	return names
+}
+
+// Run generates the keys of b in order, calling onResult after each one. A
+// failed key is reported in its result and the batch goes on, unless the
+// connection dropped or ctx was cancelled; Run then returns the results so
+// far with ErrDisconnected or the context error.
+func Run(ctx context.Context, exec sender.Executor, b Batch, onResult func(Result)) ([]Result, error) {
+	if b.Count < 1 || b.Count > MaxBatchSize {
+		return nil, fmt.Errorf("count must be between 1 and %d", MaxBatchSize)
+	}
+	if err := utils.ValidateKeyName(Names(b.Prefix, b.Count)[0]); err != nil {
+		return nil, fmt.Errorf("invalid name prefix: %v", err)
+	}
+
+	results := make([]Result, 0, b.Count)
+	for i, name := range Names(b.Prefix, b.Count) {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if !exec.Connected() {
+			return results, ErrDisconnected
+		}
+
+		r := Result{Seq: i + 1, Name: name}
+		cctx, cancel := context.WithTimeout(ctx, b.Timeout)
+		resp, err := exec.ExecuteCommandContext(cctx, b.Command)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return results, ctx.Err()
+		case err != nil && !exec.Connected():
+			return results, ErrDisconnected
+		case err != nil:
+			r.Err = err
+		default:
+			r.Key, r.Err = b.Parse(resp)
+		}
+
+		results = append(results, r)
+		if onResult != nil {
+			onResult(r)
+		}
+	}
+
+	return results, nil
+}
+
+// Export writes results to w as CSV with one row per key. Failed keys have
+// their error instead of a cryptogram and check value.
+func Export(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"seq", "name", "cryptogram", "kcv", "error"}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		row := []string{strconv.Itoa(r.Seq), r.Name, r.Key.Cryptogram, r.Key.CheckValue, ""}
+		if r.Err != nil {
+			row[2], row[3], row[4] = "", "", r.Err.Error()
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,156 @@
+// nolint:all // test package
+package keygen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// hsmStub answers call i with replies[i]. A nil reply fails the call, and
+// drops the connection from call dropAt (1-based) on when dropAt is set.
+type hsmStub struct {
+	replies [][]byte
+	dropAt  int64
+	calls   atomic.Int64
+}
+
+func (h *hsmStub) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	n := h.calls.Add(1)
+	reply := h.replies[int(n-1)%len(h.replies)]
+	if reply == nil {
+		return nil, errors.New("read: connection reset")
+	}
+
+	return reply, nil
+}
+
+func (h *hsmStub) Connected() bool {
+	return h.dropAt == 0 || h.calls.Load() < h.dropAt
+}
+
+func zpkBatch(count int) Batch {
+	return Batch{
+		Prefix:  "zpk-",
+		Count:   count,
+		Command: []byte("A00001U"),
+		Parse:   func(b []byte) (utils.A0Response, error) { return utils.ParseA0Response(b, 'U') },
+		Timeout: time.Second,
+	}
+}
+
+func TestRun_ExportCSV(t *testing.T) {
+	exec := &hsmStub{replies: [][]byte{
+		[]byte("A100U1111111111111111111111111111111108D7B4"),
+		[]byte("A100U22222222222222222222222222222222D5D44F"),
+		[]byte("A168"),
+		nil,
+		[]byte("A100U44444444444444444444444444444444AB12CD"),
+	}}
+
+	var seen []int
+	results, err := Run(context.Background(), exec, zpkBatch(5), func(r Result) { seen = append(seen, r.Seq) })
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !reflect.DeepEqual(seen, []int{1, 2, 3, 4, 5}) {
+		t.Errorf("onResult calls = %v", seen)
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, results); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	want := "seq,name,cryptogram,kcv,error\n" +
+		"1,zpk-1,U11111111111111111111111111111111,08D7B4,\n" +
+		"2,zpk-2,U22222222222222222222222222222222,D5D44F,\n" +
+		"3,zpk-3,,,command disabled\n" +
+		"4,zpk-4,,,read: connection reset\n" +
+		"5,zpk-5,U44444444444444444444444444444444,AB12CD,\n"
+	if buf.String() != want {
+		t.Errorf("Export() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestRun_ConnectionDrop(t *testing.T) {
+	exec := &hsmStub{
+		replies: [][]byte{[]byte("A100U1111111111111111111111111111111108D7B4"), nil},
+		dropAt:  2,
+	}
+
+	results, err := Run(context.Background(), exec, zpkBatch(5), nil)
+	if !errors.Is(err, ErrDisconnected) {
+		t.Fatalf("Run() error = %v, want ErrDisconnected", err)
+	}
+	if len(results) != 1 || results[0].Err != nil {
+		t.Errorf("results = %+v, want the key generated before the drop", results)
+	}
+}
+
+func TestRun_Cancel(t *testing.T) {
+	exec := &hsmStub{replies: [][]byte{[]byte("A100U1111111111111111111111111111111108D7B4")}}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	results, err := Run(ctx, exec, zpkBatch(5), func(r Result) {
+		if r.Seq == 2 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("got %d results, want 2", len(results))
+	}
+}
+
+func TestRun_InvalidBatch(t *testing.T) {
+	exec := &hsmStub{replies: [][]byte{[]byte("A168")}}
+	for _, b := range []Batch{zpkBatch(0), zpkBatch(MaxBatchSize + 1), {Prefix: "bad name ", Count: 1}} {
+		if _, err := Run(context.Background(), exec, b, nil); err == nil {
+			t.Errorf("Run(%+v) expected error", b)
+		}
+	}
+	if exec.calls.Load() != 0 {
+		t.Errorf("invalid batches sent %d commands", exec.calls.Load())
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := []struct {
+		count int
+		first string
+		last  string
+	}{
+		{1, "zpk-1", "zpk-1"},
+		{9, "zpk-1", "zpk-9"},
+		{50, "zpk-01", "zpk-50"},
+		{500, "zpk-001", "zpk-500"},
+	}
+
+	for _, tt := range tests {
+		names := Names("zpk-", tt.count)
+		if len(names) != tt.count || names[0] != tt.first || names[len(names)-1] != tt.last {
+			t.Errorf("Names(%d) = %s..%s (%d), want %s..%s", tt.count, names[0], names[len(names)-1], len(names), tt.first, tt.last)
+		}
+	}
+}
+
+func TestParseCount(t *testing.T) {
+	for _, s := range []string{"1", " 50 ", "500"} {
+		if _, err := ParseCount(s); err != nil {
+			t.Errorf("ParseCount(%q) error = %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "0", "501", "-1", "ten"} {
+		if _, err := ParseCount(s); err == nil {
+			t.Errorf("ParseCount(%q) expected error", s)
+		}
+	}
+}
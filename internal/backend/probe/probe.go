@@ -0,0 +1,178 @@
+// Package probe checks that an HSM is reachable and answering commands.
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// command is the diagnostics command sent by a probe.
+const command = "NC"
+
+// header is the message header sent on one-shot connections. The HSM echoes
+// it in front of its response.
+const header = "0000"
+
+// Kind classifies why a probe failed.
+type Kind int
+
+// Probe failure kinds.
+const (
+	KindOther Kind = iota
+	KindDNS
+	KindRefused
+	KindTimeout
+	KindBadResponse
+)
+
+// String describes k for display.
+func (k Kind) String() string {
+	switch k {
+	case KindDNS:
+		return "host name lookup failed"
+	case KindRefused:
+		return "connection refused"
+	case KindTimeout:
+		return "timed out"
+	case KindBadResponse:
+		return "bad response"
+	default:
+		return "connection failed"
+	}
+}
+
+// Error is a failed probe.
+type Error struct {
+	Kind Kind
+	Err  error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Result is a successful probe.
+type Result struct {
+	RTT           time.Duration // Round trip of the NC command.
+	Firmware      string
+	LMKCheckValue string
+}
+
+// Run sends NC over exec and parses the response.
+func Run(ctx context.Context, exec sender.Executor) (Result, error) {
+	if !exec.Connected() {
+		return Result{}, &Error{Kind: KindOther, Err: errors.New("not connected")}
+	}
+
+	start := time.Now()
+	resp, err := exec.ExecuteCommandContext(ctx, []byte(command))
+	rtt := time.Since(start)
+	if err != nil {
+		return Result{}, classify(err)
+	}
+	nc, err := utils.ParseNCResponse(resp)
+	if err != nil {
+		return Result{}, &Error{Kind: KindBadResponse, Err: err}
+	}
+
+	return Result{RTT: rtt, Firmware: nc.Firmware, LMKCheckValue: nc.LMKCheckValue}, nil
+}
+
+// Dial opens a connection to addr for a single NC round trip and closes it,
+// over TLS when tlsConfig is set. The dial, handshake and round trip together
+// are bounded by ctx.
+func Dial(ctx context.Context, addr string, tlsConfig *tls.Config) (Result, error) {
+	var conn net.Conn
+	var err error
+	if tlsConfig != nil {
+		d := tls.Dialer{Config: tlsConfig}
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	} else {
+		var d net.Dialer
+		conn, err = d.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return Result{}, classify(err)
+	}
+	defer conn.Close()
+
+	return Run(ctx, oneShot{conn})
+}
+
+// oneShot sends commands over a single connection, framed as the HSM
+// expects: a 2-byte big-endian length followed by the header and command.
+type oneShot struct {
+	conn net.Conn
+}
+
+// Connected implements sender.Executor.
+func (o oneShot) Connected() bool {
+	return true
+}
+
+// ExecuteCommandContext implements sender.Executor.
+func (o oneShot) ExecuteCommandContext(ctx context.Context, cmd []byte) ([]byte, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = o.conn.SetDeadline(deadline)
+	}
+
+	msg := make([]byte, 2, 2+len(header)+len(cmd))
+	binary.BigEndian.PutUint16(msg, uint16(len(header)+len(cmd)))
+	msg = append(append(msg, header...), cmd...)
+	if _, err := o.conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var size [2]byte
+	if _, err := io.ReadFull(o.conn, size[:]); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(size[:]))
+	if _, err := io.ReadFull(o.conn, resp); err != nil {
+		return nil, err
+	}
+	if len(resp) < len(header) || string(resp[:len(header)]) != header {
+		return nil, &Error{Kind: KindBadResponse, Err: fmt.Errorf("unexpected message header in %q", resp)}
+	}
+
+	return resp[len(header):], nil
+}
+
+// classify wraps err with the kind of failure it reports.
+func classify(err error) error {
+	var probeErr *Error
+	if errors.As(err, &probeErr) {
+		return err
+	}
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.As(err, &dnsErr):
+		return &Error{Kind: KindDNS, Err: err}
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return &Error{Kind: KindRefused, Err: err}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return &Error{Kind: KindTimeout, Err: err}
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Kind: KindBadResponse, Err: fmt.Errorf("connection closed before a response: %v", err)}
+	default:
+		return &Error{Kind: KindOther, Err: err}
+	}
+}
@@ -0,0 +1,160 @@
+// nolint:all // test package
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeHSM accepts connections on a local port and answers each framed
+// command with reply, echoing the message header. A nil reply keeps the
+// connection open without answering; an empty one closes it.
+func fakeHSM(t *testing.T, reply []byte) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				var size [2]byte
+				if _, err := io.ReadFull(conn, size[:]); err != nil {
+					return
+				}
+				msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+				if _, err := io.ReadFull(conn, msg); err != nil || string(msg[4:]) != "NC" {
+					return
+				}
+				switch {
+				case reply == nil:
+					io.Copy(io.Discard, conn)
+				case len(reply) == 0:
+				default:
+					resp := append(append([]byte{0, 0}, msg[:4]...), reply...)
+					binary.BigEndian.PutUint16(resp, uint16(len(resp)-2))
+					conn.Write(resp)
+				}
+			}()
+		}
+	}()
+
+	return ln.Addr().String()
+}
+
+// closedPort returns an address nothing listens on.
+func closedPort(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	return addr
+}
+
+func TestDial(t *testing.T) {
+	tests := []struct {
+		name     string
+		addr     func(t *testing.T) string
+		want     Result
+		wantKind Kind
+	}{
+		{
+			"reachable",
+			func(t *testing.T) string { return fakeHSM(t, []byte("ND007B44AC1DDEE2A94B0007-E000")) },
+			Result{Firmware: "0007-E000", LMKCheckValue: "7B44AC1DDEE2A94B"},
+			-1,
+		},
+		{"hsm_error", func(t *testing.T) string { return fakeHSM(t, []byte("ND68")) }, Result{}, KindBadResponse},
+		{"other_service", func(t *testing.T) string { return fakeHSM(t, []byte("\x00\x01garbage")) }, Result{}, KindBadResponse},
+		{"closed_without_reply", func(t *testing.T) string { return fakeHSM(t, []byte{}) }, Result{}, KindBadResponse},
+		{"no_reply", func(t *testing.T) string { return fakeHSM(t, nil) }, Result{}, KindTimeout},
+		{"unreachable_port", closedPort, Result{}, KindRefused},
+		{"unknown_host", func(*testing.T) string { return "hsm.invalid:1500" }, Result{}, KindDNS},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			got, err := Dial(ctx, tt.addr(t), nil)
+			if tt.wantKind < 0 {
+				if err != nil {
+					t.Fatalf("Dial() error = %v", err)
+				}
+				if got.RTT <= 0 {
+					t.Errorf("Dial() RTT = %v, want > 0", got.RTT)
+				}
+				got.RTT = 0
+				if got != tt.want {
+					t.Errorf("Dial() = %+v, want %+v", got, tt.want)
+				}
+
+				return
+			}
+			var probeErr *Error
+			if !errors.As(err, &probeErr) || probeErr.Kind != tt.wantKind {
+				t.Fatalf("Dial() error = %v, want kind %q", err, tt.wantKind)
+			}
+		})
+	}
+}
+
+// executorStub answers every command with reply or err.
+type executorStub struct {
+	connected bool
+	reply     []byte
+	err       error
+}
+
+func (e executorStub) ExecuteCommandContext(ctx context.Context, cmd []byte) ([]byte, error) {
+	return e.reply, e.err
+}
+
+func (e executorStub) Connected() bool { return e.connected }
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		exec     executorStub
+		wantKind Kind
+	}{
+		{"ok", executorStub{connected: true, reply: []byte("ND007B44AC1DDEE2A94B0007-E000")}, -1},
+		{"disconnected", executorStub{}, KindOther},
+		{"timeout", executorStub{connected: true, err: context.DeadlineExceeded}, KindTimeout},
+		{"bad_response", executorStub{connected: true, reply: []byte("NC")}, KindBadResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Run(context.Background(), tt.exec)
+			if tt.wantKind < 0 {
+				if err != nil || got.LMKCheckValue != "7B44AC1DDEE2A94B" {
+					t.Fatalf("Run() = %+v, %v", got, err)
+				}
+
+				return
+			}
+			var probeErr *Error
+			if !errors.As(err, &probeErr) || probeErr.Kind != tt.wantKind {
+				t.Fatalf("Run() error = %v, want kind %q", err, tt.wantKind)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+// Package rsa generates, imports and exports RSA key pairs and signs,
+// verifies, encrypts and decrypts with them.
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	gorsa "crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+)
+
+// KeySizes are the modulus sizes in bits Generate accepts.
+var KeySizes = []int{2048, 3072, 4096}
+
+// Encoding selects how keys are exported.
+type Encoding int
+
+const (
+	// PEM encodes keys as PEM text.
+	PEM Encoding = iota
+	// DER encodes keys as binary ASN.1.
+	DER
+)
+
+// SignScheme selects how data is signed.
+type SignScheme int
+
+const (
+	// SignRaw applies the private key to the data as an integer, without
+	// hashing or padding.
+	SignRaw SignScheme = iota
+	// SignPKCS1v15 signs the SHA-256 hash of the data with PKCS#1 v1.5
+	// padding.
+	SignPKCS1v15
+	// SignPSS signs the SHA-256 hash of the data with PSS padding and a salt
+	// as long as the hash.
+	SignPSS
+)
+
+// CryptPadding selects how data is padded for encryption.
+type CryptPadding int
+
+const (
+	// PaddingOAEP pads with OAEP using SHA-256 and no label.
+	PaddingOAEP CryptPadding = iota
+	// PaddingPKCS1v15 pads with PKCS#1 v1.5.
+	PaddingPKCS1v15
+)
+
+// ErrVerification is returned by Verify when the signature does not match.
+var ErrVerification = errors.New("signature verification failed")
+
+// pssOptions are the PSS parameters used to sign and verify.
+var pssOptions = &gorsa.PSSOptions{SaltLength: gorsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
+
+// Generate creates a key pair with a modulus of bits, one of KeySizes.
+func Generate(bits int) (*gorsa.PrivateKey, error) {
+	if !slices.Contains(KeySizes, bits) {
+		return nil, fmt.Errorf("unsupported key size %d, expected 2048, 3072 or 4096", bits)
+	}
+
+	key, err := gorsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+
+	return key, nil
+}
+
+// MarshalPrivateKey exports key as PKCS#8.
+func MarshalPrivateKey(key *gorsa.PrivateKey, enc Encoding) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %v", err)
+	}
+
+	return encode(der, "PRIVATE KEY", enc), nil
+}
+
+// MarshalPublicKey exports key as a PKIX SubjectPublicKeyInfo.
+func MarshalPublicKey(key *gorsa.PublicKey, enc Encoding) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %v", err)
+	}
+
+	return encode(der, "PUBLIC KEY", enc), nil
+}
+
+// encode returns der as is, or as a PEM block of type typ.
+func encode(der []byte, typ string, enc Encoding) []byte {
+	if enc == DER {
+		return der
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+// ParsePrivateKey reads a PKCS#1 or PKCS#8 private key, PEM or DER encoded.
+func ParsePrivateKey(data []byte) (*gorsa.PrivateKey, error) {
+	der, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.New("not a PKCS#1 or PKCS#8 private key")
+	}
+	key, ok := parsed.(*gorsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA private key: %T", parsed)
+	}
+
+	return key, nil
+}
+
+// ParsePublicKey reads a PKIX or PKCS#1 public key, or the public key of an
+// X.509 certificate, PEM or DER encoded.
+func ParsePublicKey(data []byte) (*gorsa.PublicKey, error) {
+	der, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(der); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		cert, certErr := x509.ParseCertificate(der)
+		if certErr != nil {
+			return nil, errors.New("not a PKIX or PKCS#1 public key or a certificate")
+		}
+		parsed = cert.PublicKey
+	}
+	key, ok := parsed.(*gorsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an RSA public key: %T", parsed)
+	}
+
+	return key, nil
+}
+
+// decode returns the DER of the first PEM block of data, or data itself
+// when it is not PEM.
+func decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, errors.New("key data is empty")
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		return block.Bytes, nil
+	}
+	if strings.Contains(string(data), "-----BEGIN") {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	return data, nil
+}
+
+// Fingerprint returns the SHA-256 hash of the key's PKIX encoding in
+// uppercase hex.
+func Fingerprint(key *gorsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %v", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return strings.ToUpper(hex.EncodeToString(sum[:])), nil
+}
+
+// Sign signs data with key using scheme.
+func Sign(key *gorsa.PrivateKey, scheme SignScheme, data []byte) ([]byte, error) {
+	switch scheme {
+	case SignRaw:
+		m, err := rawInput(&key.PublicKey, data)
+		if err != nil {
+			return nil, err
+		}
+		// The blinded, CRT based private operation is not exported, so this
+		// uses plain modular exponentiation, which is fine for a test tool.
+		return leftPad(new(big.Int).Exp(m, key.D, key.N), key.Size()), nil
+	case SignPKCS1v15:
+		h := sha256.Sum256(data)
+		return gorsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	case SignPSS:
+		h := sha256.Sum256(data)
+		return gorsa.SignPSS(rand.Reader, key, crypto.SHA256, h[:], pssOptions)
+	default:
+		return nil, fmt.Errorf("unsupported signature scheme %d", scheme)
+	}
+}
+
+// Verify checks that sig is a signature of data under key using scheme. It
+// returns ErrVerification when the signature does not match.
+func Verify(key *gorsa.PublicKey, scheme SignScheme, data, sig []byte) error {
+	var err error
+	switch scheme {
+	case SignRaw:
+		if len(sig) != key.Size() {
+			return fmt.Errorf("signature must be %d bytes, got %d", key.Size(), len(sig))
+		}
+		var m *big.Int
+		if m, err = rawInput(key, data); err != nil {
+			return err
+		}
+		s := new(big.Int).SetBytes(sig)
+		if s.Cmp(key.N) >= 0 || new(big.Int).Exp(s, big.NewInt(int64(key.E)), key.N).Cmp(m) != 0 {
+			return ErrVerification
+		}
+
+		return nil
+	case SignPKCS1v15:
+		h := sha256.Sum256(data)
+		err = gorsa.VerifyPKCS1v15(key, crypto.SHA256, h[:], sig)
+	case SignPSS:
+		h := sha256.Sum256(data)
+		err = gorsa.VerifyPSS(key, crypto.SHA256, h[:], sig, pssOptions)
+	default:
+		return fmt.Errorf("unsupported signature scheme %d", scheme)
+	}
+	if err != nil {
+		return ErrVerification
+	}
+
+	return nil
+}
+
+// rawInput returns data as an integer, which must be smaller than the
+// modulus.
+func rawInput(key *gorsa.PublicKey, data []byte) (*big.Int, error) {
+	if len(data) == 0 {
+		return nil, errors.New("data is empty")
+	}
+	if len(data) > key.Size() {
+		return nil, fmt.Errorf("data must be at most %d bytes, got %d", key.Size(), len(data))
+	}
+	m := new(big.Int).SetBytes(data)
+	if m.Cmp(key.N) >= 0 {
+		return nil, errors.New("data must be smaller than the modulus")
+	}
+
+	return m, nil
+}
+
+// leftPad returns n as a big-endian number of size bytes.
+func leftPad(n *big.Int, size int) []byte {
+	return n.FillBytes(make([]byte, size))
+}
+
+// Encrypt encrypts data under key with padding.
+func Encrypt(key *gorsa.PublicKey, padding CryptPadding, data []byte) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	switch padding {
+	case PaddingOAEP:
+		out, err = gorsa.EncryptOAEP(sha256.New(), rand.Reader, key, data, nil)
+	case PaddingPKCS1v15:
+		out, err = gorsa.EncryptPKCS1v15(rand.Reader, key, data)
+	default:
+		return nil, fmt.Errorf("unsupported padding %d", padding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %v", err)
+	}
+
+	return out, nil
+}
+
+// Decrypt decrypts data with key, removing padding.
+func Decrypt(key *gorsa.PrivateKey, padding CryptPadding, data []byte) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	switch padding {
+	case PaddingOAEP:
+		out, err = gorsa.DecryptOAEP(sha256.New(), nil, key, data, nil)
+	case PaddingPKCS1v15:
+		out, err = gorsa.DecryptPKCS1v15(nil, key, data)
+	default:
+		return nil, fmt.Errorf("unsupported padding %d", padding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %v", err)
+	}
+
+	return out, nil
+}
+
+// Wipe zeroes the private parts of key.
+func Wipe(key *gorsa.PrivateKey) {
+	if key == nil {
+		return
+	}
+	key.D.SetInt64(0)
+	for _, p := range key.Primes {
+		p.SetInt64(0)
+	}
+	key.Precomputed = gorsa.PrecomputedValues{}
+}
@@ -0,0 +1,142 @@
+package sender
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// Buckets of results that carry no HSM error code.
+const (
+	CodeTimeout     = "timeout"
+	CodeError       = "error"
+	CodeUnparseable = "unparseable"
+)
+
+// bucketMeanings describes the buckets without an HSM error code.
+var bucketMeanings = map[string]string{
+	CodeTimeout:     "no response within the timeout",
+	CodeError:       "transport error",
+	CodeUnparseable: "response without a valid status",
+}
+
+// CodeCount is the number of results that ended with a code.
+type CodeCount struct {
+	Code    string
+	Meaning string
+	Count   int
+}
+
+// CodeCounts is a response code distribution, most frequent code first.
+type CodeCounts []CodeCount
+
+// Total returns the number of results tallied.
+func (c CodeCounts) Total() int {
+	total := 0
+	for _, cc := range c {
+		total += cc.Count
+	}
+
+	return total
+}
+
+// percent returns the share of n in the distribution formatted for display.
+func (c CodeCounts) percent(n int) string {
+	return strconv.FormatFloat(100*float64(n)/float64(max(c.Total(), 1)), 'f', 2, 64)
+}
+
+// String renders the distribution as a table.
+func (c CodeCounts) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Response codes (%d requests):\n", c.Total())
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Code\tMeaning\tCount\t%")
+	for _, cc := range c {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", cc.Code, cc.Meaning, cc.Count, c.percent(cc.Count))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+// rows returns the distribution as CSV records with a header row.
+func (c CodeCounts) rows() [][]string {
+	rows := [][]string{{"code", "meaning", "count", "percent"}}
+	for _, cc := range c {
+		rows = append(rows, []string{cc.Code, cc.Meaning, strconv.Itoa(cc.Count), c.percent(cc.Count)})
+	}
+
+	return rows
+}
+
+// CodeTally counts the results of a run by HSM error code. Timeouts, other
+// request errors and responses without a valid status are counted in their
+// own buckets. It is safe for concurrent use.
+type CodeTally struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewCodeTally creates an empty tally.
+func NewCodeTally() *CodeTally {
+	return &CodeTally{counts: make(map[string]int)}
+}
+
+// ResultCode returns the tally bucket of r.
+func ResultCode(r Result) string {
+	switch {
+	case IsTimeout(r.Err):
+		return CodeTimeout
+	case r.Err != nil:
+		return CodeError
+	}
+	status, err := utils.ParseHSMResponse(r.Response)
+	if err != nil {
+		return CodeUnparseable
+	}
+
+	return status.ErrorCode
+}
+
+// Record counts r. Warm-up and cancelled requests are not counted.
+func (t *CodeTally) Record(r Result) {
+	if r.Warmup || r.Cancelled() {
+		return
+	}
+	code := ResultCode(r)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.counts[code]++
+}
+
+// Counts returns the distribution, most frequent code first and codes with
+// equal counts in order.
+func (t *CodeTally) Counts() CodeCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(CodeCounts, 0, len(t.counts))
+	for code, n := range t.counts {
+		meaning, ok := bucketMeanings[code]
+		if !ok {
+			meaning = utils.HSMErrorDescription(code)
+		}
+		counts = append(counts, CodeCount{Code: code, Meaning: meaning, Count: n})
+	}
+	slices.SortFunc(counts, func(a, b CodeCount) int {
+		if a.Count != b.Count {
+			return cmp.Compare(b.Count, a.Count)
+		}
+
+		return cmp.Compare(a.Code, b.Code)
+	})
+
+	return counts
+}
@@ -0,0 +1,105 @@
+// nolint:all // test package
+package sender
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cannedExecutor answers call i with replies[i%len(replies)]. A nil reply
+// blocks until the request times out.
+type cannedExecutor struct {
+	replies [][]byte
+	calls   atomic.Int64
+}
+
+func (c *cannedExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	reply := c.replies[int(c.calls.Add(1)-1)%len(c.replies)]
+	if reply == nil {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return reply, nil
+}
+
+func (c *cannedExecutor) Connected() bool {
+	return true
+}
+
+func TestCodeTally_Run(t *testing.T) {
+	exec := &cannedExecutor{replies: [][]byte{
+		[]byte("ND007B44AC1DDEE2A94B"), []byte("ND00"), []byte("A168"), nil, []byte("ND00"),
+		[]byte("nd00"), []byte("NC00XYZ"), []byte("A168"), []byte("ND00"), []byte("??"),
+	}}
+	tally := NewCodeTally()
+
+	Run(make(chan struct{}), exec, Config{
+		Command: []byte("NC"),
+		Count:   20,
+		Warmup:  10,
+		Timeout: 20 * time.Millisecond,
+	}, tally.Record)
+
+	want := CodeCounts{
+		{Code: "00", Meaning: "no error", Count: 10},
+		{Code: "68", Meaning: "command not enabled in security settings", Count: 4},
+		{Code: CodeUnparseable, Meaning: "response without a valid status", Count: 4},
+		{Code: CodeTimeout, Meaning: "no response within the timeout", Count: 2},
+	}
+	got := tally.Counts()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Counts() = %+v, want %+v", got, want)
+	}
+	if got.Total() != 20 {
+		t.Errorf("Total() = %d, want 20", got.Total())
+	}
+
+	table := got.String()
+	for _, line := range []string{"Response codes (20 requests):", "00 ", "50.00", "timeout", "10.00"} {
+		if !strings.Contains(table, line) {
+			t.Errorf("String() missing %q:\n%s", line, table)
+		}
+	}
+}
+
+func TestResultCode(t *testing.T) {
+	tests := []struct {
+		name   string
+		result Result
+		want   string
+	}{
+		{"ok", Result{Response: []byte("ND007B44AC1DDEE2A94B")}, "00"},
+		{"hsm_error", Result{Response: []byte("A168")}, "68"},
+		{"timed_out", Result{Err: errors.New("command timed out")}, CodeTimeout},
+		{"deadline", Result{Err: context.DeadlineExceeded}, CodeTimeout},
+		{"transport", Result{Err: errors.New("connection reset")}, CodeError},
+		{"short", Result{Response: []byte("ND")}, CodeUnparseable},
+		{"empty", Result{}, CodeUnparseable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResultCode(tt.result); got != tt.want {
+				t.Errorf("ResultCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeTally_SkipsWarmupAndCancelled(t *testing.T) {
+	tally := NewCodeTally()
+	tally.Record(Result{Response: []byte("ND00"), Warmup: true})
+	tally.Record(Result{Err: ErrCancelled})
+	tally.Record(Result{Response: []byte("ND01")})
+
+	want := CodeCounts{{Code: "01", Meaning: "verification failure or key parity warning", Count: 1}}
+	if got := tally.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaskRange is a byte range of a response that is ignored when comparing the
+// responses of two targets. End is exclusive; a negative End extends the
+// range to the end of the response.
+type MaskRange struct {
+	Start, End int
+}
+
+// Mask lists the response bytes ignored when comparing two targets, such as
+// an echoed message header or fields holding random keys and their check
+// values.
+type Mask []MaskRange
+
+// ParseMask parses comma separated byte ranges written "start:end", with
+// zero-based offsets and an exclusive end. "start:" runs to the end of the
+// response. An empty spec masks nothing.
+func ParseMask(spec string) (Mask, error) {
+	var m Mask
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(field, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid mask range %q: want start:end", field)
+		}
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("invalid mask range %q: bad start offset", field)
+		}
+		end := -1
+		if to = strings.TrimSpace(to); to != "" {
+			if end, err = strconv.Atoi(to); err != nil || end <= start {
+				return nil, fmt.Errorf("invalid mask range %q: end must be after start", field)
+			}
+		}
+		m = append(m, MaskRange{Start: start, End: end})
+	}
+
+	return m, nil
+}
+
+// apply returns resp with the masked bytes replaced by '*'. Open ended ranges
+// cut the response so that differing lengths past them do not matter.
+func (m Mask) apply(resp []byte) []byte {
+	out := bytes.Clone(resp)
+	for _, r := range m {
+		if r.End < 0 {
+			out = out[:min(r.Start, len(out))]
+		}
+	}
+	for _, r := range m {
+		end := r.End
+		if end < 0 || end > len(out) {
+			end = len(out)
+		}
+		for i := r.Start; i < end; i++ {
+			out[i] = '*'
+		}
+	}
+
+	return out
+}
+
+// Equal reports whether a and b agree outside the mask.
+func (m Mask) Equal(a, b []byte) bool {
+	return bytes.Equal(m.apply(a), m.apply(b))
+}
+
+// Comparison is the outcome of sending a request to the secondary target.
+type Comparison struct {
+	Response []byte
+	Latency  time.Duration
+	Err      error
+	Match    bool // Both targets answered and agree outside the mask.
+}
+
+// sendSecondary sends command to the secondary target of a comparison.
+func sendSecondary(ctx context.Context, exec Executor, command []byte, timeout time.Duration) *Comparison {
+	start := time.Now()
+	resp, err := execute(ctx, exec, command, timeout)
+
+	return &Comparison{Response: resp, Latency: time.Since(start), Err: err}
+}
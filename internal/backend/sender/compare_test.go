@@ -0,0 +1,165 @@
+// nolint:all // test package
+package sender
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hsmStub answers commands from a table after delay, like a configured mock
+// HSM. Commands missing from the table time out.
+type hsmStub struct {
+	delay   time.Duration
+	replies map[string]string
+}
+
+func (h hsmStub) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	reply, ok := h.replies[string(command)]
+	if !ok {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(h.delay)
+
+	return []byte(reply), nil
+}
+
+func (h hsmStub) Connected() bool {
+	return true
+}
+
+func TestParseMask(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    Mask
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"0:4", Mask{{0, 4}}, false},
+		{" 0:4 , 10:26,40:", Mask{{0, 4}, {10, 26}, {40, -1}}, false},
+		{"4", nil, true},
+		{"a:4", nil, true},
+		{"-1:4", nil, true},
+		{"4:4", nil, true},
+		{"4:2", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseMask(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseMask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMask() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask_Equal(t *testing.T) {
+	tests := []struct {
+		name string
+		mask Mask
+		a, b string
+		want bool
+	}{
+		{"identical", nil, "ND00ABC", "ND00ABC", true},
+		{"differ", nil, "ND00ABC", "ND00ABD", false},
+		{"header_masked", Mask{{0, 4}}, "0001ND00", "0002ND00", true},
+		{"key_and_kcv_masked", Mask{{4, 8}, {10, 12}}, "A100KEY1ZZCV", "A100KEY2ZZKV", true},
+		{"outside_mask", Mask{{4, 8}}, "A100KEY1ZZ", "A100KEY2ZY", false},
+		{"open_ended", Mask{{4, -1}}, "ND00", "ND00FIRMWARE-2", true},
+		{"length_differs", Mask{{0, 2}}, "ND00", "ND000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mask.Equal([]byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("Equal(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Compare(t *testing.T) {
+	// The targets echo a header, and differ on the firmware version (B8) and
+	// the random key generated by A0; the key and its check value are masked.
+	primary := hsmStub{delay: time.Millisecond, replies: map[string]string{
+		"0001NC":   "0001ND00FIRMWARE-1",
+		"0001B8":   "0001B9001.0",
+		"0001A0U0": "0001A100UAAAA1111",
+	}}
+	secondary := hsmStub{delay: 20 * time.Millisecond, replies: map[string]string{
+		"0001NC":   "0001ND00FIRMWARE-1",
+		"0001B8":   "0001B9002.0",
+		"0001A0U0": "0001A100UBBBB2222",
+	}}
+	mask, err := ParseMask("0:4,9:")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	results := map[string]Result{}
+	sum := Run(make(chan struct{}), primary, Config{
+		Lines: []Line{
+			{Number: 1, Command: []byte("0001NC")},
+			{Number: 2, Command: []byte("0001B8")},
+			{Number: 3, Command: []byte("0001A0U0")},
+		},
+		Count:     2,
+		Timeout:   time.Second,
+		Secondary: secondary,
+		Mask:      mask,
+	}, func(r Result) {
+		mu.Lock()
+		results[string(r.Request)] = r
+		mu.Unlock()
+	})
+
+	if sum.Sent != 6 || sum.Mismatches != 2 {
+		t.Errorf("Sent = %d, Mismatches = %d, want 6 and 2", sum.Sent, sum.Mismatches)
+	}
+	for cmd, wantMatch := range map[string]bool{"0001NC": true, "0001B8": false, "0001A0U0": true} {
+		r := results[cmd]
+		if r.Compare == nil {
+			t.Fatalf("%s: no comparison recorded", cmd)
+		}
+		if r.Compare.Match != wantMatch || r.Mismatch() == wantMatch {
+			t.Errorf("%s: Match = %v, want %v", cmd, r.Compare.Match, wantMatch)
+		}
+		if string(r.Compare.Response) != secondary.replies[cmd] {
+			t.Errorf("%s: secondary response = %q", cmd, r.Compare.Response)
+		}
+		// The latency of each target is recorded separately.
+		if r.Compare.Latency < 20*time.Millisecond || r.Latency >= 20*time.Millisecond {
+			t.Errorf("%s: latencies = %v primary, %v secondary", cmd, r.Latency, r.Compare.Latency)
+		}
+	}
+}
+
+func TestRun_CompareSecondaryFails(t *testing.T) {
+	primary := hsmStub{replies: map[string]string{"NC": "ND00"}}
+	secondary := hsmStub{replies: map[string]string{}}
+
+	var got []Result
+	sum := Run(make(chan struct{}), primary, Config{
+		Command:   []byte("NC"),
+		Count:     1,
+		Timeout:   20 * time.Millisecond,
+		Secondary: secondary,
+	}, func(r Result) { got = append(got, r) })
+
+	if sum.Mismatches != 1 {
+		t.Errorf("Mismatches = %d, want 1", sum.Mismatches)
+	}
+	if len(got) != 1 || got[0].Err != nil || !errors.Is(got[0].Compare.Err, context.DeadlineExceeded) &&
+		!IsTimeout(got[0].Compare.Err) {
+		t.Errorf("results = %+v", got)
+	}
+}
@@ -0,0 +1,173 @@
+package sender
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// ExportFormat selects the layout of exported results.
+type ExportFormat string
+
+// Supported export formats.
+const (
+	ExportCSV  ExportFormat = "CSV"
+	ExportText ExportFormat = "Text"
+)
+
+// ExportFormats lists the export formats for selection widgets.
+var ExportFormats = []string{string(ExportCSV), string(ExportText)}
+
+// exportTimeLayout formats result timestamps in exports.
+const exportTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Extension returns the file extension for the format.
+func (f ExportFormat) Extension() string {
+	if f == ExportCSV {
+		return ".csv"
+	}
+
+	return ".txt"
+}
+
+// Export writes results to w in the given format. Unless includeSensitive is
+// set, key-like hex runs in requests and responses are masked.
+func Export(w io.Writer, format ExportFormat, results []Result, includeSensitive bool) error {
+	mask := logger.MaskHexRuns
+	if includeSensitive {
+		mask = func(s string) string { return s }
+	}
+
+	switch format {
+	case ExportCSV:
+		return exportCSV(w, results, mask)
+	case ExportText:
+		return exportText(w, results, mask)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// RunReport holds the results of a run together with its response code
+// distribution, latency threshold breaches and throughput over time.
+type RunReport struct {
+	Results []Result
+	Codes   CodeCounts
+	Slow    *SlowStats // Nil when no latency threshold was set.
+	TPS     []int      // Completed requests per second of the run.
+}
+
+// Export writes the results to w as Export does, followed by the response
+// code distribution, the threshold breaches and the per-second throughput
+// when there are any. In CSV each of them is a separate table preceded by an
+// empty line.
+func (r RunReport) Export(w io.Writer, format ExportFormat, includeSensitive bool) error {
+	if err := Export(w, format, r.Results, includeSensitive); err != nil {
+		return err
+	}
+
+	if len(r.Codes) > 0 {
+		if err := exportSection(w, format, r.Codes.String(), r.Codes.rows()); err != nil {
+			return err
+		}
+	}
+
+	if r.Slow != nil {
+		if err := exportSection(w, format, r.Slow.String()+"\n", r.Slow.rows()); err != nil {
+			return err
+		}
+	}
+
+	if len(r.TPS) > 0 {
+		var text strings.Builder
+		text.WriteString("Completed requests per second:\n")
+		rows := [][]string{{"second", "completed"}}
+		for i, n := range r.TPS {
+			fmt.Fprintf(&text, "%6d  %d\n", i+1, n)
+			rows = append(rows, []string{strconv.Itoa(i + 1), strconv.Itoa(n)})
+		}
+		if err := exportSection(w, format, text.String(), rows); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportSection appends a table to an export: text for text exports and the
+// rows, after an empty line, for CSV exports.
+func exportSection(w io.Writer, format ExportFormat, text string, rows [][]string) error {
+	if format == ExportText {
+		_, err := io.WriteString(w, text+"\n")
+
+		return err
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	return csv.NewWriter(w).WriteAll(rows)
+}
+
+// exportCSV writes one row per result with a header row.
+func exportCSV(w io.Writer, results []Result, mask func(string) string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"seq", "timestamp", "latency_ms", "request", "response", "error",
+	}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := cw.Write([]string{
+			strconv.Itoa(r.Seq),
+			r.Timestamp.Format(exportTimeLayout),
+			latencyMillis(r.Latency),
+			mask(string(r.Request)),
+			mask(string(r.Response)),
+			errorText(r.Err),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// exportText writes a readable block per result.
+func exportText(w io.Writer, results []Result, mask func(string) string) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range results {
+		fmt.Fprintf(bw, "[%s] #%d latency %s ms\n",
+			r.Timestamp.Format(exportTimeLayout), r.Seq, latencyMillis(r.Latency))
+		fmt.Fprintf(bw, "Command:  %s\n", mask(string(r.Request)))
+		if r.Err != nil {
+			fmt.Fprintf(bw, "Error:    %s\n\n", r.Err)
+			continue
+		}
+		fmt.Fprintf(bw, "Response: %s\n\n", mask(string(r.Response)))
+	}
+
+	return bw.Flush()
+}
+
+// latencyMillis formats d as milliseconds with microsecond precision.
+func latencyMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
+
+// errorText returns the message of err, or an empty string.
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,141 @@
+// nolint:all // test package
+package sender
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportResults() []Result {
+	at := time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	return []Result{
+		{Seq: 1, Timestamp: at, Request: []byte("NC"), Response: []byte("ND00"), Latency: 1500 * time.Microsecond},
+		{
+			Seq: 2, Timestamp: at, Request: []byte("A0,1;U0123456789ABCDEF"), Response: []byte(`A1"00"`),
+			Latency: 2 * time.Millisecond,
+		},
+		{Seq: 3, Timestamp: at, Request: []byte("NC"), Latency: 5 * time.Second, Err: errors.New("command timed out")},
+	}
+}
+
+func TestExport_CSV(t *testing.T) {
+	tests := []struct {
+		name             string
+		includeSensitive bool
+		wantRequest      string
+	}{
+		{"masked", false, "A0,1;U01************EF"},
+		{"sensitive", true, "A0,1;U0123456789ABCDEF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Export(&buf, ExportCSV, exportResults(), tt.includeSensitive); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			rows, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("exported CSV does not parse: %v\n%s", err, buf.String())
+			}
+			if len(rows) != 4 {
+				t.Fatalf("got %d rows, want header and 3 results", len(rows))
+			}
+			want := []string{"2", "2026-05-01T10:30:00.000Z", "2.000", tt.wantRequest, `A1"00"`, ""}
+			if !reflect.DeepEqual(rows[2], want) {
+				t.Errorf("row 2 = %q, want %q", rows[2], want)
+			}
+			if rows[3][5] != "command timed out" {
+				t.Errorf("error column = %q", rows[3][5])
+			}
+		})
+	}
+}
+
+func TestExport_CSVQuoting(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, ExportCSV, exportResults()[1:2], true); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	line := strings.Split(buf.String(), "\n")[1]
+	if !strings.Contains(line, `"A0,1;U0123456789ABCDEF"`) {
+		t.Errorf("command with a comma not quoted: %s", line)
+	}
+	if !strings.Contains(line, `"A1""00"""`) {
+		t.Errorf("embedded quotes not escaped: %s", line)
+	}
+}
+
+func TestExport_Text(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Export(&buf, ExportText, exportResults(), false); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	text := buf.String()
+	for _, want := range []string{
+		"#1 latency 1.500 ms",
+		"Response: ND00",
+		"Command:  A0,1;U01************EF",
+		"Error:    command timed out",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text export missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "0123456789ABCDEF") {
+		t.Error("text export contains an unmasked key")
+	}
+}
+
+func TestRunReport_Export(t *testing.T) {
+	report := RunReport{
+		Results: exportResults()[:1],
+		Codes:   CodeCounts{{Code: "00", Meaning: "no error", Count: 3}, {Code: CodeTimeout, Meaning: "timed out", Count: 1}},
+		TPS:     []int{12, 0, 7},
+	}
+
+	var csvBuf bytes.Buffer
+	if err := report.Export(&csvBuf, ExportCSV, false); err != nil {
+		t.Fatalf("Export(CSV) error = %v", err)
+	}
+	sections := strings.Split(csvBuf.String(), "\n\n")
+	if len(sections) != 3 {
+		t.Fatalf("CSV export has %d sections, want 3:\n%s", len(sections), csvBuf.String())
+	}
+	tables := [][][]string{
+		{{"code", "meaning", "count", "percent"}, {"00", "no error", "3", "75.00"}, {"timeout", "timed out", "1", "25.00"}},
+		{{"second", "completed"}, {"1", "12"}, {"2", "0"}, {"3", "7"}},
+	}
+	for i, want := range tables {
+		rows, err := csv.NewReader(strings.NewReader(sections[i+1])).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to parse table %d: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(rows, want) {
+			t.Errorf("table %d = %q, want %q", i+1, rows, want)
+		}
+	}
+
+	var textBuf bytes.Buffer
+	if err := report.Export(&textBuf, ExportText, false); err != nil {
+		t.Fatalf("Export(Text) error = %v", err)
+	}
+	for _, want := range []string{"Response codes (4 requests):", "Completed requests per second:\n     1  12\n"} {
+		if !strings.Contains(textBuf.String(), want) {
+			t.Errorf("text export missing %q:\n%s", want, textBuf.String())
+		}
+	}
+}
+
+func TestExport_UnsupportedFormat(t *testing.T) {
+	if err := Export(&bytes.Buffer{}, "XML", nil, false); err == nil {
+		t.Error("Export() with an unknown format expected error")
+	}
+}
@@ -0,0 +1,80 @@
+package sender
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// InputMode is the way command text is turned into bytes.
+type InputMode string
+
+// Supported input modes.
+const (
+	InputASCII   InputMode = "ASCII"
+	InputEscaped InputMode = "ASCII with escapes"
+	InputHex     InputMode = "Hex"
+)
+
+// InputModes lists the input modes in display order.
+var InputModes = []string{string(InputASCII), string(InputEscaped), string(InputHex)}
+
+// MaxCommandBytes caps the size of a command loaded from a file.
+const MaxCommandBytes = 32 * 1024
+
+// maxCommandFileSize caps the size of a command file, leaving room for hex
+// text with separators and line breaks.
+const maxCommandFileSize = 4 * MaxCommandBytes
+
+// ReadCommandFile reads a command from a file holding either hex text or raw
+// bytes; isHex reports which was detected. Hex text may have a 0x prefix,
+// whitespace, colons and dashes. Commands over MaxCommandBytes are rejected.
+func ReadCommandFile(r io.Reader) (command []byte, isHex bool, err error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxCommandFileSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read command file: %v", err)
+	}
+	if len(content) > maxCommandFileSize {
+		return nil, false, fmt.Errorf("command file is larger than %d bytes", maxCommandFileSize)
+	}
+	if len(content) == 0 {
+		return nil, false, errors.New("command file is empty")
+	}
+
+	command = content
+	hexStr, isHex := utils.FileContentToHex(content)
+	if isHex {
+		if command, err = hex.DecodeString(hexStr); err != nil {
+			return nil, false, fmt.Errorf("invalid hex command: %v", err)
+		}
+	}
+	if len(command) > MaxCommandBytes {
+		return nil, false, fmt.Errorf("command is %d bytes, the limit is %d", len(command), MaxCommandBytes)
+	}
+
+	return command, isHex, nil
+}
+
+// EncodeCommand converts command text entered in mode to the bytes to send.
+// Escaped text may contain \xNN sequences; hex text may contain spaces,
+// colons, dashes and line breaks.
+func EncodeCommand(text string, mode InputMode) ([]byte, error) {
+	switch mode {
+	case InputASCII:
+		return []byte(text), nil
+	case InputEscaped:
+		return utils.UnescapeBytes(text)
+	case InputHex:
+		data, err := utils.DecodeHexLenient(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex command: %v", err)
+		}
+
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported input mode %q", mode)
+	}
+}
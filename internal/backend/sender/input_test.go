@@ -0,0 +1,72 @@
+// nolint:all // test package
+package sender
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		mode    InputMode
+		want    []byte
+		wantErr bool
+	}{
+		{"ascii_keeps_backslashes", `NC\x00`, InputASCII, []byte(`NC\x00`), false},
+		{"escaped", `A0\x00B`, InputEscaped, []byte{'A', '0', 0x00, 'B'}, false},
+		{"escaped_incomplete", `A0\x0`, InputEscaped, nil, true},
+		{"hex", "4E 43:00", InputHex, []byte{'N', 'C', 0x00}, false},
+		{"hex_odd_length", "4E4", InputHex, nil, true},
+		{"hex_invalid", "NC", InputHex, nil, true},
+		{"unknown_mode", "NC", InputMode("EBCDIC"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeCommand(tt.text, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeCommand(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("EncodeCommand(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadCommandFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content []byte
+		want    []byte
+		wantHex bool
+		wantErr bool
+	}{
+		{"hex_text", []byte("4E43 00FF\n"), []byte{'N', 'C', 0x00, 0xFF}, true, false},
+		{"hex_prefixed", []byte("0x4e43:00-ff"), []byte{'N', 'C', 0x00, 0xFF}, true, false},
+		{"binary", []byte{'N', 'C', 0x00, 0xFF}, []byte{'N', 'C', 0x00, 0xFF}, false, false},
+		{"odd_hex_digits_are_binary", []byte("4E4"), []byte("4E4"), false, false},
+		{"ascii_command_is_binary", []byte("NC"), []byte("NC"), false, false},
+		{"empty", nil, nil, false, true},
+		{"binary_at_cap", bytes.Repeat([]byte{0x01}, MaxCommandBytes), bytes.Repeat([]byte{0x01}, MaxCommandBytes), false, false},
+		{"binary_over_cap", bytes.Repeat([]byte{0x01}, MaxCommandBytes+1), nil, false, true},
+		{"hex_at_cap", bytes.Repeat([]byte("AB "), MaxCommandBytes), bytes.Repeat([]byte{0xAB}, MaxCommandBytes), true, false},
+		{"hex_over_cap", bytes.Repeat([]byte("AB"), MaxCommandBytes+1), nil, false, true},
+		{"file_over_cap", bytes.Repeat([]byte("AB "), 2*MaxCommandBytes), nil, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isHex, err := ReadCommandFile(bytes.NewReader(tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadCommandFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) || isHex != tt.wantHex {
+				t.Errorf("ReadCommandFile() = %d bytes, hex %v, want %d bytes, hex %v",
+					len(got), isHex, len(tt.want), tt.wantHex)
+			}
+		})
+	}
+}
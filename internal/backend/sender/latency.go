@@ -0,0 +1,170 @@
+package sender
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReservoirSize is the number of latency samples kept per run.
+const DefaultReservoirSize = 100_000
+
+// LatencyStats summarises recorded latencies.
+type LatencyStats struct {
+	Count int64
+	Min   time.Duration
+	P50   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// String formats the statistics in milliseconds.
+func (s LatencyStats) String() string {
+	return fmt.Sprintf(
+		"min %s / p50 %s / p95 %s / p99 %s / max %s ms",
+		ms(s.Min), ms(s.P50), ms(s.P95), ms(s.P99), ms(s.Max),
+	)
+}
+
+// HistogramBucket counts the latencies in [Lower, Upper).
+type HistogramBucket struct {
+	Lower time.Duration
+	Upper time.Duration
+	Count int
+}
+
+// LatencyRecorder collects request latencies. It keeps exact minimum and
+// maximum values and a uniform reservoir sample for percentiles, so memory
+// stays bounded on long runs. It is safe for concurrent use.
+type LatencyRecorder struct {
+	mu      sync.Mutex
+	size    int
+	samples []time.Duration
+	count   int64
+	min     time.Duration
+	max     time.Duration
+	rng     *rand.Rand
+}
+
+// NewLatencyRecorder creates a recorder keeping at most size samples.
+func NewLatencyRecorder(size int) *LatencyRecorder {
+	if size < 1 {
+		size = DefaultReservoirSize
+	}
+
+	return &LatencyRecorder{
+		size: size,
+		rng:  rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+}
+
+// Record adds a latency.
+func (r *LatencyRecorder) Record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.count++
+	if r.count == 1 || d < r.min {
+		r.min = d
+	}
+	if d > r.max {
+		r.max = d
+	}
+
+	if len(r.samples) < r.size {
+		r.samples = append(r.samples, d)
+		return
+	}
+	// Reservoir sampling keeps every latency with equal probability.
+	if i := r.rng.Int64N(r.count); i < int64(r.size) {
+		r.samples[i] = d
+	}
+}
+
+// Stats returns the statistics of the recorded latencies.
+func (r *LatencyRecorder) Stats() LatencyStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.count == 0 {
+		return LatencyStats{}
+	}
+	sorted := slices.Clone(r.samples)
+	slices.Sort(sorted)
+
+	return LatencyStats{
+		Count: r.count,
+		Min:   r.min,
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+		Max:   r.max,
+	}
+}
+
+// Histogram splits the sampled latencies into n equal-width buckets between
+// the minimum and maximum.
+func (r *LatencyRecorder) Histogram(n int) []HistogramBucket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.count == 0 || n < 1 {
+		return nil
+	}
+	width := (r.max - r.min + time.Duration(n) - 1) / time.Duration(n)
+	if width <= 0 {
+		return []HistogramBucket{{Lower: r.min, Upper: r.max, Count: len(r.samples)}}
+	}
+
+	buckets := make([]HistogramBucket, n)
+	for i := range buckets {
+		buckets[i].Lower = r.min + time.Duration(i)*width
+		buckets[i].Upper = buckets[i].Lower + width
+	}
+	for _, d := range r.samples {
+		i := min(int((d-r.min)/width), n-1)
+		buckets[i].Count++
+	}
+
+	return buckets
+}
+
+// FormatHistogram renders buckets as text bars of at most width characters.
+func FormatHistogram(buckets []HistogramBucket, width int) string {
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.Count)
+	}
+
+	var sb strings.Builder
+	for _, b := range buckets {
+		bar := 0
+		if peak > 0 {
+			bar = int(math.Round(float64(b.Count) * float64(width) / float64(peak)))
+		}
+		fmt.Fprintf(&sb, "%8s - %8s ms | %-*s %d\n",
+			ms(b.Lower), ms(b.Upper), width, strings.Repeat("#", bar), b.Count)
+	}
+
+	return sb.String()
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+
+	return sorted[max(rank-1, 0)]
+}
+
+// ms formats d in milliseconds with one decimal.
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
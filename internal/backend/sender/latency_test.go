@@ -0,0 +1,138 @@
+// nolint:all // test package
+package sender
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLatencyRecorder_Stats(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		want      LatencyStats
+	}{
+		{
+			name: "empty",
+			want: LatencyStats{},
+		},
+		{
+			name:      "single",
+			latencies: []time.Duration{7 * time.Millisecond},
+			want: LatencyStats{
+				Count: 1,
+				Min:   7 * time.Millisecond,
+				P50:   7 * time.Millisecond,
+				P95:   7 * time.Millisecond,
+				P99:   7 * time.Millisecond,
+				Max:   7 * time.Millisecond,
+			},
+		},
+		{
+			name:      "one_to_hundred",
+			latencies: millis(1, 100),
+			want: LatencyStats{
+				Count: 100,
+				Min:   1 * time.Millisecond,
+				P50:   50 * time.Millisecond,
+				P95:   95 * time.Millisecond,
+				P99:   99 * time.Millisecond,
+				Max:   100 * time.Millisecond,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLatencyRecorder(0)
+			// Record in reverse to make sure the order does not matter.
+			for i := len(tt.latencies) - 1; i >= 0; i-- {
+				r.Record(tt.latencies[i])
+			}
+			if got := r.Stats(); got != tt.want {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatencyRecorder_Bounded(t *testing.T) {
+	r := NewLatencyRecorder(100)
+	for _, d := range millis(1, 10_000) {
+		r.Record(d)
+	}
+
+	if len(r.samples) != 100 {
+		t.Fatalf("kept %d samples, want 100", len(r.samples))
+	}
+	s := r.Stats()
+	if s.Count != 10_000 || s.Min != time.Millisecond || s.Max != 10_000*time.Millisecond {
+		t.Errorf("Stats() = %+v, want exact count, min and max", s)
+	}
+	// A uniform sample of 1..10000 ms puts the median well inside the range.
+	if s.P50 < 2_000*time.Millisecond || s.P50 > 8_000*time.Millisecond {
+		t.Errorf("P50 = %v, want about 5s", s.P50)
+	}
+}
+
+func TestLatencyRecorder_Concurrent(t *testing.T) {
+	r := NewLatencyRecorder(0)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, d := range millis(1, 500) {
+				r.Record(d)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s := r.Stats(); s.Count != 4000 || s.P50 != 250*time.Millisecond {
+		t.Errorf("Stats() = %+v, want 4000 samples with p50 250ms", s)
+	}
+}
+
+func TestLatencyRecorder_Histogram(t *testing.T) {
+	r := NewLatencyRecorder(0)
+	for _, d := range millis(1, 10) {
+		r.Record(d)
+	}
+	r.Record(10 * time.Millisecond)
+
+	buckets := r.Histogram(3)
+	if len(buckets) != 3 {
+		t.Fatalf("Histogram() returned %d buckets, want 3", len(buckets))
+	}
+	total := 0
+	for _, b := range buckets {
+		total += b.Count
+	}
+	if total != 11 {
+		t.Errorf("histogram holds %d samples, want 11", total)
+	}
+	if buckets[2].Count != 5 {
+		t.Errorf("last bucket = %+v, want 5 samples", buckets[2])
+	}
+
+	text := FormatHistogram(buckets, 10)
+	if lines := strings.Count(text, "\n"); lines != 3 {
+		t.Errorf("FormatHistogram() has %d lines, want 3:\n%s", lines, text)
+	}
+	if !strings.Contains(text, "##########") {
+		t.Errorf("FormatHistogram() lacks a full width bar:\n%s", text)
+	}
+}
+
+// millis returns latencies of from..to milliseconds.
+func millis(from, to int) []time.Duration {
+	out := make([]time.Duration, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		out = append(out, time.Duration(i)*time.Millisecond)
+	}
+
+	return out
+}
@@ -0,0 +1,99 @@
+package sender
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LatencySample is the measurement of one request.
+type LatencySample struct {
+	Seq       int
+	Timestamp time.Time
+	Worker    int
+	Latency   time.Duration
+	Code      string // Response code or one of the Code* buckets.
+	Err       string // Error text, empty on success.
+}
+
+// LatencyLog keeps the per-request measurements of a run, independently of
+// the command history. It is safe for concurrent use.
+type LatencyLog struct {
+	mu      sync.Mutex
+	samples *Ring[LatencySample] // Nil when unbounded.
+	all     []LatencySample
+}
+
+// NewLatencyLog creates a log keeping the latest limit samples, or every
+// sample when limit is zero or less.
+func NewLatencyLog(limit int) *LatencyLog {
+	l := &LatencyLog{}
+	if limit > 0 {
+		l.samples = NewRing[LatencySample](limit)
+	}
+
+	return l
+}
+
+// Record adds the measurement of r. Warm-up and cancelled requests are not
+// recorded.
+func (l *LatencyLog) Record(r Result) {
+	if r.Warmup || r.Cancelled() {
+		return
+	}
+	s := LatencySample{
+		Seq:       r.Seq,
+		Timestamp: r.Timestamp,
+		Worker:    r.Worker,
+		Latency:   r.Latency,
+		Code:      ResultCode(r),
+	}
+	if r.Err != nil {
+		s.Err = r.Err.Error()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.samples != nil {
+		l.samples.Push(s)
+	} else {
+		l.all = append(l.all, s)
+	}
+}
+
+// Samples returns the recorded samples in the order they completed.
+func (l *LatencyLog) Samples() []LatencySample {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.samples != nil {
+		return l.samples.Items()
+	}
+
+	return append([]LatencySample(nil), l.all...)
+}
+
+// ExportLatencies writes one CSV row per sample with the latency in
+// microseconds.
+func ExportLatencies(w io.Writer, samples []LatencySample) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"seq", "timestamp", "worker", "latency_us", "code", "error"}); err != nil {
+		return err
+	}
+	for _, s := range samples {
+		if err := cw.Write([]string{
+			strconv.Itoa(s.Seq),
+			s.Timestamp.Format(exportTimeLayout),
+			strconv.Itoa(s.Worker),
+			strconv.FormatInt(s.Latency.Microseconds(), 10),
+			s.Code,
+			s.Err,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
@@ -0,0 +1,94 @@
+// nolint:all // test package
+package sender
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLatencyLog_ConcurrentRun(t *testing.T) {
+	// A concurrent run, as used when the command history is not logged,
+	// still records every request.
+	exec := &cannedExecutor{replies: [][]byte{[]byte("ND00"), []byte("A168"), nil}}
+	log := NewLatencyLog(0)
+
+	Run(make(chan struct{}), exec, Config{
+		Command: []byte("NC"),
+		Count:   30,
+		Warmup:  3,
+		Workers: 4,
+		Timeout: 20 * time.Millisecond,
+	}, log.Record)
+
+	samples := log.Samples()
+	if len(samples) != 30 {
+		t.Fatalf("Samples() has %d rows, want 30", len(samples))
+	}
+	seen := map[int]bool{}
+	codes := map[string]int{}
+	for _, s := range samples {
+		if s.Seq <= 3 || s.Seq > 33 || seen[s.Seq] {
+			t.Errorf("unexpected or repeated seq %d", s.Seq)
+		}
+		seen[s.Seq] = true
+		if s.Worker < 1 || s.Worker > 4 {
+			t.Errorf("seq %d: worker = %d, want 1-4", s.Seq, s.Worker)
+		}
+		if s.Timestamp.IsZero() || s.Latency <= 0 {
+			t.Errorf("seq %d: missing timestamp or latency: %+v", s.Seq, s)
+		}
+		if (s.Code == CodeTimeout) != (s.Err != "") {
+			t.Errorf("seq %d: code %q with error %q", s.Seq, s.Code, s.Err)
+		}
+		codes[s.Code]++
+	}
+	if want := map[string]int{"00": 10, "68": 10, CodeTimeout: 10}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("codes = %v, want %v", codes, want)
+	}
+}
+
+func TestLatencyLog_Bounded(t *testing.T) {
+	log := NewLatencyLog(3)
+	for i := 1; i <= 5; i++ {
+		log.Record(Result{Seq: i, Response: []byte("ND00")})
+	}
+	log.Record(Result{Seq: 6, Warmup: true})
+	log.Record(Result{Seq: 7, Err: ErrCancelled})
+
+	var seqs []int
+	for _, s := range log.Samples() {
+		seqs = append(seqs, s.Seq)
+	}
+	if !reflect.DeepEqual(seqs, []int{3, 4, 5}) {
+		t.Errorf("Samples() seqs = %v, want [3 4 5]", seqs)
+	}
+}
+
+func TestExportLatencies(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 6_000_000, time.UTC)
+	samples := []LatencySample{
+		{Seq: 1, Timestamp: at, Worker: 2, Latency: 1500 * time.Microsecond, Code: "00"},
+		{Seq: 2, Timestamp: at, Worker: 1, Latency: 2 * time.Second, Code: CodeError, Err: errors.New("broken, pipe").Error()},
+	}
+
+	var buf bytes.Buffer
+	if err := ExportLatencies(&buf, samples); err != nil {
+		t.Fatalf("ExportLatencies() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"seq", "timestamp", "worker", "latency_us", "code", "error"},
+		{"1", "2026-01-02T03:04:05.006Z", "2", "1500", "00", ""},
+		{"2", "2026-01-02T03:04:05.006Z", "1", "2000000", "error", "broken, pipe"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("ExportLatencies() rows = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,25 @@
+package sender
+
+import "strings"
+
+// Line is one command of a multi-command set.
+type Line struct {
+	Number   int // 1-based line number in the command text.
+	Command  []byte
+	Template *Template // Expanded per request instead of Command when set.
+}
+
+// SplitLines returns the non-blank lines of text as a command set. Line
+// numbers count blank lines so they match the text as entered.
+func SplitLines(text string) []Line {
+	var lines []Line
+	for i, s := range strings.Split(text, "\n") {
+		s = strings.TrimSuffix(s, "\r")
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+		lines = append(lines, Line{Number: i + 1, Command: []byte(s)})
+	}
+
+	return lines
+}
@@ -0,0 +1,123 @@
+// nolint:all // test package
+package sender
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Line
+	}{
+		{"single", "NC", []Line{{Number: 1, Command: []byte("NC")}}},
+		{"crlf", "NC\r\nBU02\r\n", []Line{
+			{Number: 1, Command: []byte("NC")},
+			{Number: 2, Command: []byte("BU02")},
+		}},
+		{"blank_lines", "\nNC\n   \n\nA00002\n", []Line{
+			{Number: 2, Command: []byte("NC")},
+			{Number: 5, Command: []byte("A00002")},
+		}},
+		{"empty", "\n \n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitLines(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitLines(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Lines(t *testing.T) {
+	exec := newMockExecutor(0)
+	onResult, results := collect()
+
+	sum := Run(make(chan struct{}), exec, Config{
+		Lines:   SplitLines("NC\n\nBU02\n"),
+		Count:   3,
+		Workers: 1,
+		Timeout: time.Second,
+	}, onResult)
+
+	wantCommands := []string{"NC", "BU02", "NC", "BU02", "NC", "BU02"}
+	if !reflect.DeepEqual(exec.commands, wantCommands) {
+		t.Errorf("sent %q, want %q", exec.commands, wantCommands)
+	}
+	if sum.Sent != len(wantCommands) {
+		t.Errorf("Sent = %d, want %d", sum.Sent, len(wantCommands))
+	}
+	for i, r := range results() {
+		if want := []int{1, 3}[i%2]; r.Line != want {
+			t.Errorf("request %d came from line %d, want %d", r.Seq, r.Line, want)
+		}
+	}
+}
+
+func TestRun_Expected(t *testing.T) {
+	tests := []struct {
+		name          string
+		stopOnFailure bool
+		wantSent      []string
+		wantPassed    int
+		wantFailed    int
+	}{
+		{"count_all", false, []string{"NC", "A00002", "NC", "A00002", "NC", "A00002"}, 3, 3},
+		{"stop_on_failure", true, []string{"NC", "A00002"}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newScriptedExecutor()
+			onResult, results := collect()
+
+			sum := Run(make(chan struct{}), exec, Config{
+				Lines:         SplitLines("NC\nA00002"),
+				Count:         3,
+				Workers:       1,
+				Timeout:       time.Second,
+				Expected:      "ND00",
+				StopOnFailure: tt.stopOnFailure,
+			}, onResult)
+
+			if !reflect.DeepEqual(exec.sent, tt.wantSent) {
+				t.Errorf("sent %q, want %q", exec.sent, tt.wantSent)
+			}
+			if sum.Passed != tt.wantPassed || sum.Failed != tt.wantFailed {
+				t.Errorf("passed %d, failed %d, want %d and %d",
+					sum.Passed, sum.Failed, tt.wantPassed, tt.wantFailed)
+			}
+			if sum.FailureStop != tt.stopOnFailure || sum.Stopped || sum.ConnectionLost {
+				t.Errorf("summary = %+v", sum)
+			}
+			for _, r := range results() {
+				if want := r.Line == 2; r.Failed != want {
+					t.Errorf("request %d (line %d) Failed = %v, want %v", r.Seq, r.Line, r.Failed, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_ExpectedUnchecked(t *testing.T) {
+	exec := newMockExecutor(0)
+	exec.err = errors.New("invalid response")
+	onResult, results := collect()
+
+	sum := Run(make(chan struct{}), exec, Config{Command: []byte("NC"), Count: 2, Timeout: time.Second}, onResult)
+
+	if sum.Passed != 0 || sum.Failed != 0 {
+		t.Errorf("unchecked run counted passed %d, failed %d", sum.Passed, sum.Failed)
+	}
+	for _, r := range results() {
+		if r.Failed {
+			t.Errorf("request %d marked as failed without an expectation", r.Seq)
+		}
+	}
+}
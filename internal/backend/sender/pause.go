@@ -0,0 +1,119 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// span is a finished pause.
+type span struct {
+	from, to time.Time
+}
+
+// Pause holds back the dispatch of new requests while paused. Requests in
+// flight complete normally. Paused time is recorded so that durations and
+// rates can leave it out. A nil *Pause is never paused.
+type Pause struct {
+	mu      sync.Mutex
+	resumed chan struct{} // Closed while running.
+	since   time.Time     // Start of the current pause, zero while running.
+	spans   []span
+}
+
+// NewPause creates a Pause that is running.
+func NewPause() *Pause {
+	resumed := make(chan struct{})
+	close(resumed)
+
+	return &Pause{resumed: resumed}
+}
+
+// Pause stops the dispatch of new requests. It reports false when already
+// paused.
+func (p *Pause) Pause() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.since.IsZero() {
+		return false
+	}
+	p.since = time.Now()
+	p.resumed = make(chan struct{})
+
+	return true
+}
+
+// Resume continues the dispatch of new requests. It reports false when not
+// paused.
+func (p *Pause) Resume() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.since.IsZero() {
+		return false
+	}
+	p.spans = append(p.spans, span{from: p.since, to: time.Now()})
+	p.since = time.Time{}
+	close(p.resumed)
+
+	return true
+}
+
+// Paused reports whether dispatch is paused.
+func (p *Pause) Paused() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return !p.since.IsZero()
+}
+
+// Active returns the time between from and to that was not spent paused.
+func (p *Pause) Active(from, to time.Time) time.Duration {
+	active := to.Sub(from)
+	if p == nil {
+		return active
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	spans := p.spans
+	if !p.since.IsZero() {
+		spans = append(spans[:len(spans):len(spans)], span{from: p.since, to: to})
+	}
+	for _, s := range spans {
+		start, end := s.from, s.to
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			active -= end.Sub(start)
+		}
+	}
+
+	return active
+}
+
+// wait blocks while paused. It reports false when stop or halt closed first.
+func (p *Pause) wait(stop, halt <-chan struct{}) bool {
+	if p == nil {
+		return true
+	}
+	p.mu.Lock()
+	resumed := p.resumed
+	p.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return true
+	case <-stop:
+		return false
+	case <-halt:
+		return false
+	}
+}
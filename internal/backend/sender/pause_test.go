@@ -0,0 +1,135 @@
+// nolint:all // test package
+package sender
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPause_Active(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+	p := &Pause{spans: []span{{at(10), at(20)}, {at(30), at(35)}}}
+
+	tests := []struct {
+		name     string
+		from, to int
+		want     int
+	}{
+		{"before_pauses", 0, 10, 10},
+		{"across_both", 0, 40, 25},
+		{"inside_pause", 12, 18, 0},
+		{"partial_overlap", 15, 32, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Active(at(tt.from), at(tt.to)); got != time.Duration(tt.want)*time.Second {
+				t.Errorf("Active() = %v, want %ds", got, tt.want)
+			}
+		})
+	}
+
+	// The current pause counts up to the end of the interval.
+	p.since = at(50)
+	if got := p.Active(at(40), at(60)); got != 10*time.Second {
+		t.Errorf("Active() during a pause = %v, want 10s", got)
+	}
+
+	var none *Pause
+	if got := none.Active(at(0), at(5)); got != 5*time.Second {
+		t.Errorf("nil Active() = %v, want 5s", got)
+	}
+}
+
+func TestPause_PauseResume(t *testing.T) {
+	p := NewPause()
+	if p.Paused() || !p.wait(nil, nil) {
+		t.Fatal("new Pause is paused")
+	}
+	if !p.Pause() || p.Pause() {
+		t.Error("Pause() should only succeed once")
+	}
+	if !p.Paused() {
+		t.Error("Paused() = false after Pause()")
+	}
+
+	waited := make(chan bool)
+	go func() { waited <- p.wait(nil, nil) }()
+	select {
+	case <-waited:
+		t.Fatal("wait() returned while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !p.Resume() || p.Resume() {
+		t.Error("Resume() should only succeed once")
+	}
+	if !<-waited {
+		t.Error("wait() = false after Resume()")
+	}
+
+	p.Pause()
+	stop := make(chan struct{})
+	close(stop)
+	if p.wait(stop, nil) {
+		t.Error("wait() = true after stop while paused")
+	}
+}
+
+func TestRun_Pause(t *testing.T) {
+	exec := newMockExecutor(time.Millisecond)
+	pause := NewPause()
+	pause.Pause()
+	done := make(chan Summary)
+	go func() {
+		done <- Run(make(chan struct{}), exec, Config{
+			Command: []byte("NC"), Count: 5, Workers: 2, Pause: pause,
+		}, func(Result) {})
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if n := exec.calls.Load(); n != 0 {
+		t.Fatalf("%d requests dispatched while paused", n)
+	}
+	pause.Resume()
+
+	sum := <-done
+	if sum.Sent != 5 {
+		t.Errorf("Sent = %d, want 5", sum.Sent)
+	}
+	if sum.Elapsed >= 50*time.Millisecond {
+		t.Errorf("Elapsed = %v includes the paused time", sum.Elapsed)
+	}
+}
+
+func TestRun_PauseExtendsDuration(t *testing.T) {
+	exec := newMockExecutor(time.Millisecond)
+	pause := NewPause()
+	start := time.Now()
+	time.AfterFunc(20*time.Millisecond, func() { pause.Pause() })
+	time.AfterFunc(120*time.Millisecond, func() { pause.Resume() })
+
+	sum := Run(make(chan struct{}), exec, Config{
+		Command: []byte("NC"), Duration: 100 * time.Millisecond, Pause: pause,
+	}, func(Result) {})
+
+	if wall := time.Since(start); wall < 190*time.Millisecond {
+		t.Errorf("run took %v, want the 100ms pause added to the 100ms duration", wall)
+	}
+	if sum.Elapsed < 100*time.Millisecond || sum.Elapsed > 150*time.Millisecond {
+		t.Errorf("Elapsed = %v, want about 100ms", sum.Elapsed)
+	}
+}
+
+func TestRun_StopWhilePaused(t *testing.T) {
+	exec := newMockExecutor(0)
+	pause := NewPause()
+	pause.Pause()
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+
+	sum := Run(stop, exec, Config{Command: []byte("NC"), Count: 5, Pause: pause}, func(Result) {})
+	if !sum.Stopped || sum.Sent != 0 {
+		t.Errorf("Summary = %+v, want stopped with nothing sent", sum)
+	}
+}
@@ -0,0 +1,134 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// monitorLog tags periodic run entries with the "monitor" module, so that
+// outages can be picked out in the log.
+var monitorLog = logger.WithModule("monitor")
+
+// PeriodicSummary counts the requests of a periodic run.
+type PeriodicSummary struct {
+	Sent    int  // Requests sent.
+	Failed  int  // Requests that failed or got an unexpected response.
+	Skipped int  // Intervals skipped while the connection was down.
+	Outages int  // Runs of consecutive failed requests.
+	Down    bool // The last request failed.
+}
+
+// Uptime returns the percentage of requests that succeeded.
+func (s PeriodicSummary) Uptime() float64 {
+	if s.Sent == 0 {
+		return 100
+	}
+
+	return 100 * float64(s.Sent-s.Failed) / float64(s.Sent)
+}
+
+// String returns a one line summary of the counters.
+func (s PeriodicSummary) String() string {
+	return fmt.Sprintf("Sent: %d, failed: %d, skipped: %d, outages: %d, uptime %.2f%%",
+		s.Sent, s.Failed, s.Skipped, s.Outages, s.Uptime())
+}
+
+// RunPeriodic sends one request per interval, starting immediately, until
+// stop is closed. With cfg.Lines each interval sends the next line. Intervals
+// in which exec is not connected are skipped rather than ending the run, so
+// sending resumes once the connection is back; intervals are also skipped
+// while cfg.Pause is paused. A request fails when it returns an error or,
+// with cfg.Expected, an unexpected response. Every failure is logged, as are
+// the start and end of each outage. onResult is called after every request
+// and onUpdate, which may be nil, after every interval.
+func RunPeriodic(
+	stop <-chan struct{},
+	exec Executor,
+	cfg Config,
+	interval time.Duration,
+	onResult func(Result),
+	onUpdate func(PeriodicSummary),
+) PeriodicSummary {
+	ctx, cancel := stopContext(stop, nil)
+	defer cancel()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var sum PeriodicSummary
+	var outageStart time.Time
+	for seq := 1; ; {
+		switch {
+		case cfg.Pause.Paused():
+		case !exec.Connected():
+			sum.Skipped++
+			monitorLog.Warn("monitor_check", "Skipped", "hsm not connected")
+		default:
+			r := periodicRequest(ctx, exec, &cfg, seq)
+			if r.Cancelled() {
+				return sum
+			}
+			seq++
+			sum.Sent++
+			command := logger.MaskHexRuns(string(r.Request))
+			if r.Err != nil || r.Failed {
+				sum.Failed++
+				if !sum.Down {
+					sum.Down = true
+					sum.Outages++
+					outageStart = r.Timestamp
+					monitorLog.Error("monitor_outage", "Started", command)
+				}
+				monitorLog.Error("monitor_check", "Failed", command+": "+failureText(r))
+			} else if sum.Down {
+				sum.Down = false
+				monitorLog.Info("monitor_outage", "Ended", fmt.Sprintf("%s: down for %s",
+					command, r.Timestamp.Sub(outageStart).Round(time.Millisecond)))
+			}
+			onResult(r)
+		}
+		if onUpdate != nil {
+			onUpdate(sum)
+		}
+
+		select {
+		case <-stop:
+			return sum
+		case <-ticker.C:
+		}
+	}
+}
+
+// periodicRequest sends request seq of a periodic run.
+func periodicRequest(ctx context.Context, exec Executor, cfg *Config, seq int) Result {
+	command, line, err := cfg.request(seq)
+	start := time.Now()
+	var resp []byte
+	if err == nil {
+		resp, err = execute(ctx, exec, command, cfg.Timeout)
+	}
+	r := Result{
+		Seq:       seq,
+		Line:      line,
+		Timestamp: start,
+		Request:   command,
+		Response:  resp,
+		Latency:   time.Since(start),
+		Err:       err,
+		Worker:    1,
+	}
+	r.Failed = cfg.Expected != "" && !matches(cfg.Expected, resp, err)
+
+	return r
+}
+
+// failureText describes why a periodic request failed.
+func failureText(r Result) string {
+	if r.Err != nil {
+		return r.Err.Error()
+	}
+
+	return "unexpected response " + logger.MaskHexRuns(string(r.Response))
+}
@@ -0,0 +1,127 @@
+// nolint:all // test package
+package sender
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// monitorExecutor times out the calls listed in fail and drops the
+// connection for offlineFor checks once offlineAfter calls were made.
+type monitorExecutor struct {
+	fail         map[int]bool
+	offlineAfter int
+	offlineFor   int
+	calls        int
+}
+
+func (m *monitorExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	m.calls++
+	if m.fail[m.calls] {
+		return nil, errors.New("command timed out")
+	}
+
+	return []byte("ND00"), nil
+}
+
+func (m *monitorExecutor) Connected() bool {
+	if m.calls == m.offlineAfter && m.offlineFor > 0 {
+		m.offlineFor--
+		return false
+	}
+
+	return true
+}
+
+func TestRunPeriodic(t *testing.T) {
+	exec := &monitorExecutor{fail: map[int]bool{3: true, 4: true}, offlineAfter: 6, offlineFor: 2}
+	stop := make(chan struct{})
+	var updates []PeriodicSummary
+	var seqs []int
+
+	sum := RunPeriodic(stop, exec, Config{Command: []byte("NC"), Timeout: time.Second}, 5*time.Millisecond,
+		func(r Result) { seqs = append(seqs, r.Seq) },
+		func(s PeriodicSummary) {
+			updates = append(updates, s)
+			if len(updates) == 10 {
+				close(stop)
+			}
+		},
+	)
+
+	want := PeriodicSummary{Sent: 8, Failed: 2, Skipped: 2, Outages: 1}
+	if sum != want {
+		t.Errorf("summary = %+v, want %+v", sum, want)
+	}
+	if !reflect.DeepEqual(seqs, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Errorf("sequence numbers = %v", seqs)
+	}
+	// The outage covers the third and fourth interval; sending resumed after
+	// the seventh and eighth were skipped.
+	down := []bool{false, false, true, true, false, false, false, false, false, false}
+	for i, u := range updates {
+		if u.Down != down[i] {
+			t.Errorf("update %d: Down = %v, want %v", i+1, u.Down, down[i])
+		}
+	}
+	if updates[7].Skipped != 2 || updates[8].Sent != 7 {
+		t.Errorf("updates 8 and 9 = %+v, %+v", updates[7], updates[8])
+	}
+}
+
+func TestRunPeriodic_Expected(t *testing.T) {
+	// The mock answers NDZ000, so every check fails the expectation.
+	exec := newMockExecutor(0)
+	stop := make(chan struct{})
+	var failed []bool
+
+	sum := RunPeriodic(stop, exec, Config{Command: []byte("NC"), Timeout: time.Second, Expected: "ND00"},
+		time.Millisecond,
+		func(r Result) {
+			failed = append(failed, r.Failed)
+			if len(failed) == 2 {
+				close(stop)
+			}
+		},
+		nil,
+	)
+
+	if sum.Sent != 2 || sum.Failed != 2 || sum.Outages != 1 || !sum.Down {
+		t.Errorf("summary = %+v", sum)
+	}
+	if !reflect.DeepEqual(failed, []bool{true, true}) {
+		t.Errorf("Failed flags = %v", failed)
+	}
+}
+
+func TestRunPeriodic_StopDuringRequest(t *testing.T) {
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+	done := make(chan PeriodicSummary)
+	go func() {
+		done <- RunPeriodic(stop, blockingExecutor{}, Config{Command: []byte("NC"), Timeout: time.Minute},
+			time.Hour, func(Result) {}, nil)
+	}()
+
+	select {
+	case sum := <-done:
+		if sum.Sent != 0 {
+			t.Errorf("cancelled request counted: %+v", sum)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("RunPeriodic did not return after stop")
+	}
+}
+
+func TestPeriodicSummary_String(t *testing.T) {
+	s := PeriodicSummary{Sent: 8, Failed: 2, Skipped: 1, Outages: 1}
+	if got, want := s.String(), "Sent: 8, failed: 2, skipped: 1, outages: 1, uptime 75.00%"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got := (PeriodicSummary{}).Uptime(); got != 100 {
+		t.Errorf("Uptime() without requests = %v, want 100", got)
+	}
+}
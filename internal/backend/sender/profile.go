@@ -0,0 +1,132 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ProfileStep holds a target rate for a period of a load profile.
+type ProfileStep struct {
+	TPS      float64
+	Duration time.Duration
+}
+
+// ValidateProfile checks that a load profile has steps with a positive rate
+// and duration.
+func ValidateProfile(steps []ProfileStep) error {
+	if len(steps) == 0 {
+		return errors.New("load profile has no steps")
+	}
+	for i, s := range steps {
+		if s.TPS <= 0 {
+			return fmt.Errorf("step %d: target TPS must be positive", i+1)
+		}
+		if s.Duration <= 0 {
+			return fmt.Errorf("step %d: duration must be positive", i+1)
+		}
+	}
+
+	return nil
+}
+
+// StepResult holds the statistics of one profile step.
+type StepResult struct {
+	Step      ProfileStep
+	Summary   Summary
+	Latencies LatencyStats
+}
+
+// ProfileReport collects the step results of a load profile run.
+type ProfileReport struct {
+	Steps          []StepResult
+	Total          int // Number of steps in the profile.
+	Stopped        bool
+	ConnectionLost bool
+}
+
+// Summary returns a one line summary of the run.
+func (r ProfileReport) Summary() string {
+	s := fmt.Sprintf("Load profile: %d of %d steps run", len(r.Steps), r.Total)
+	switch {
+	case r.ConnectionLost:
+		s += " (connection lost)"
+	case r.Stopped:
+		s += " (stopped)"
+	}
+
+	return s
+}
+
+// String renders the report as a table.
+func (r ProfileReport) String() string {
+	var b strings.Builder
+	b.WriteString(r.Summary() + "\n")
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Step\tTarget TPS\tDuration\tSent\tAchieved TPS\tp95 ms")
+	for i, s := range r.Steps {
+		fmt.Fprintf(tw, "%d\t%.0f\t%s\t%d\t%.2f\t%s\n",
+			i+1, s.Step.TPS, s.Step.Duration, s.Summary.Sent, s.Summary.TPS(), ms(s.Latencies.P95))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+// RunProfile runs cfg once per step, holding the step's target rate for its
+// duration, and collects statistics per step. cfg.Warmup only applies to the
+// first step. The remaining steps are skipped when stop is closed or the
+// connection is lost. onStep is called before each step starts and onResult
+// after every request with the index of its step.
+func RunProfile(
+	stop <-chan struct{},
+	exec Executor,
+	cfg Config,
+	steps []ProfileStep,
+	onStep func(step int),
+	onResult func(step int, r Result),
+) ProfileReport {
+	report := ProfileReport{Total: len(steps)}
+	for i, step := range steps {
+		if done(stop, nil) {
+			report.Stopped = true
+			break
+		}
+		if onStep != nil {
+			onStep(i)
+		}
+
+		stepCfg := cfg
+		stepCfg.Rate = step.TPS
+		stepCfg.Duration = step.Duration
+		stepCfg.Count = 0
+		if i > 0 {
+			stepCfg.Warmup = 0
+		}
+		latencies := NewLatencyRecorder(DefaultReservoirSize)
+		sum := Run(stop, exec, stepCfg, func(r Result) {
+			if !r.Warmup && r.Err == nil {
+				latencies.Record(r.Latency)
+			}
+			onResult(i, r)
+		})
+
+		report.Steps = append(report.Steps, StepResult{
+			Step:      step,
+			Summary:   sum,
+			Latencies: latencies.Stats(),
+		})
+		if sum.ConnectionLost {
+			report.ConnectionLost = true
+			break
+		}
+		if sum.Stopped || sum.FailureStop {
+			report.Stopped = true
+			break
+		}
+	}
+
+	return report
+}
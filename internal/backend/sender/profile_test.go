@@ -0,0 +1,127 @@
+// nolint:all // test package
+package sender
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun_Rate(t *testing.T) {
+	exec := newMockExecutor(0)
+
+	sum := Run(make(chan struct{}), exec, Config{
+		Command:  []byte("NC"),
+		Duration: 300 * time.Millisecond,
+		Workers:  4,
+		Rate:     100,
+		Timeout:  time.Second,
+	}, func(Result) {})
+
+	// 100 TPS for 300 ms is 30 requests, give or take a slot.
+	if sum.Sent < 25 || sum.Sent > 35 {
+		t.Errorf("Sent = %d, want about 30", sum.Sent)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []ProfileStep
+		wantErr bool
+	}{
+		{"valid", []ProfileStep{{50, time.Second}, {100, time.Second}}, false},
+		{"empty", nil, true},
+		{"zero_tps", []ProfileStep{{0, time.Second}}, true},
+		{"zero_duration", []ProfileStep{{50, time.Second}, {100, 0}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateProfile(tt.steps); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	exec := newMockExecutor(0)
+	steps := []ProfileStep{
+		{TPS: 100, Duration: 200 * time.Millisecond},
+		{TPS: 200, Duration: 200 * time.Millisecond},
+	}
+
+	var mu sync.Mutex
+	var starts []time.Time
+	perStep := make([]int, len(steps))
+	begin := time.Now()
+	report := RunProfile(make(chan struct{}), exec, Config{
+		Command: []byte("NC"),
+		Workers: 2,
+		Timeout: time.Second,
+	}, steps, func(step int) {
+		mu.Lock()
+		starts = append(starts, time.Now())
+		mu.Unlock()
+	}, func(step int, r Result) {
+		mu.Lock()
+		perStep[step]++
+		mu.Unlock()
+	})
+
+	if len(report.Steps) != 2 || report.Stopped || report.ConnectionLost {
+		t.Fatalf("report = %+v", report)
+	}
+	if len(starts) != 2 {
+		t.Fatalf("onStep called %d times, want 2", len(starts))
+	}
+	if d := starts[0].Sub(begin); d > 20*time.Millisecond {
+		t.Errorf("first step started after %v", d)
+	}
+	if d := starts[1].Sub(starts[0]); d < 200*time.Millisecond || d > 260*time.Millisecond {
+		t.Errorf("second step started %v after the first, want about 200ms", d)
+	}
+
+	// Statistics are bucketed per step: about 20 and 40 requests.
+	for i, want := range []int{20, 40} {
+		s := report.Steps[i]
+		if s.Summary.Sent != perStep[i] {
+			t.Errorf("step %d Sent = %d, onResult saw %d", i+1, s.Summary.Sent, perStep[i])
+		}
+		if s.Summary.Sent < want-5 || s.Summary.Sent > want+5 {
+			t.Errorf("step %d Sent = %d, want about %d", i+1, s.Summary.Sent, want)
+		}
+		if s.Latencies.Count != int64(s.Summary.Sent) {
+			t.Errorf("step %d recorded %d latencies for %d requests", i+1, s.Latencies.Count, s.Summary.Sent)
+		}
+	}
+
+	text := report.String()
+	for _, want := range []string{"2 of 2 steps run", "Target TPS", "p95 ms"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestRunProfile_Stop(t *testing.T) {
+	exec := newMockExecutor(0)
+	stop := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+	var started []int
+
+	report := RunProfile(stop, exec, Config{Command: []byte("NC"), Timeout: time.Second},
+		[]ProfileStep{{100, 300 * time.Millisecond}, {100, 300 * time.Millisecond}},
+		func(step int) { started = append(started, step) },
+		func(int, Result) {},
+	)
+
+	if !report.Stopped || len(report.Steps) != 1 || len(started) != 1 {
+		t.Errorf("report = %+v, started %v, want the remaining steps aborted", report, started)
+	}
+	if !strings.Contains(report.Summary(), "1 of 2 steps run (stopped)") {
+		t.Errorf("Summary() = %q", report.Summary())
+	}
+}
@@ -0,0 +1,38 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// pacer spaces requests evenly to hold a target rate across all workers.
+// Missed slots are not made up, so a slow HSM causes no bursts.
+type pacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newPacer creates a pacer for rate requests per second.
+func newPacer(rate float64) *pacer {
+	return &pacer{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait blocks until the next request slot and reports false when stop or
+// halt closed first.
+func (p *pacer) wait(stop, halt <-chan struct{}) bool {
+	p.mu.Lock()
+	now := time.Now()
+	if p.next.Before(now) {
+		p.next = now
+	}
+	at := p.next
+	p.next = p.next.Add(p.interval)
+	p.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		return sleep(d, stop, halt)
+	}
+
+	return true
+}
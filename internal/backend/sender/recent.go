@@ -0,0 +1,58 @@
+package sender
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultRecentCommands is the number of commands kept for recall.
+const DefaultRecentCommands = 20
+
+// RecentCommands is a most-recently-used list of distinct commands, newest
+// first.
+type RecentCommands struct {
+	limit int
+	items []string
+}
+
+// NewRecentCommands creates a list keeping at most limit commands.
+func NewRecentCommands(limit int) *RecentCommands {
+	if limit < 1 {
+		limit = DefaultRecentCommands
+	}
+
+	return &RecentCommands{limit: limit}
+}
+
+// Add moves cmd to the front of the list, dropping the oldest command once
+// the list is full. Blank commands are ignored.
+func (r *RecentCommands) Add(cmd string) {
+	if strings.TrimSpace(cmd) == "" {
+		return
+	}
+	if i := slices.Index(r.items, cmd); i >= 0 {
+		r.items = slices.Delete(r.items, i, i+1)
+	}
+	r.items = slices.Insert(r.items, 0, cmd)
+	if len(r.items) > r.limit {
+		r.items = r.items[:r.limit]
+	}
+}
+
+// Items returns the commands, newest first.
+func (r *RecentCommands) Items() []string {
+	return slices.Clone(r.items)
+}
+
+// Load replaces the list with items, as returned by Items.
+func (r *RecentCommands) Load(items []string) {
+	r.items = nil
+	for i := len(items) - 1; i >= 0; i-- {
+		r.Add(items[i])
+	}
+}
+
+// Clear forgets all commands.
+func (r *RecentCommands) Clear() {
+	r.items = nil
+}
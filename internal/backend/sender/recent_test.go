@@ -0,0 +1,71 @@
+// nolint:all // test package
+package sender
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRecentCommands_Add(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		add   []string
+		want  []string
+	}{
+		{"newest_first", 5, []string{"NC", "A0", "BU"}, []string{"BU", "A0", "NC"}},
+		{"consecutive_duplicates", 5, []string{"NC", "NC", "NC"}, []string{"NC"}},
+		{"reused_moves_to_front", 5, []string{"NC", "A0", "BU", "NC"}, []string{"NC", "BU", "A0"}},
+		{"blank_ignored", 5, []string{"NC", "", "  \n"}, []string{"NC"}},
+		{"capped", 3, []string{"C1", "C2", "C3", "C4", "C5"}, []string{"C5", "C4", "C3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRecentCommands(tt.limit)
+			for _, cmd := range tt.add {
+				r.Add(cmd)
+			}
+			if got := r.Items(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Items() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecentCommands_DefaultLimit(t *testing.T) {
+	r := NewRecentCommands(0)
+	for i := 0; i < 30; i++ {
+		r.Add(fmt.Sprintf("C%d", i))
+	}
+	if got := len(r.Items()); got != DefaultRecentCommands {
+		t.Errorf("kept %d commands, want %d", got, DefaultRecentCommands)
+	}
+}
+
+func TestRecentCommands_LoadRoundTrip(t *testing.T) {
+	r := NewRecentCommands(5)
+	for _, cmd := range []string{"NC", "A0", "BU", "A0"} {
+		r.Add(cmd)
+	}
+	saved := r.Items()
+
+	restored := NewRecentCommands(5)
+	restored.Load(saved)
+	if got := restored.Items(); !reflect.DeepEqual(got, saved) {
+		t.Errorf("Items() after Load = %q, want %q", got, saved)
+	}
+
+	// Loading drops duplicates and respects the limit of the new list.
+	small := NewRecentCommands(2)
+	small.Load([]string{"NC", "NC", "A0", "BU"})
+	if got, want := small.Items(), []string{"NC", "A0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Items() = %q, want %q", got, want)
+	}
+
+	restored.Clear()
+	if len(restored.Items()) != 0 {
+		t.Error("Clear() kept commands")
+	}
+}
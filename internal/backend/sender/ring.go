@@ -0,0 +1,71 @@
+package sender
+
+// DefaultHistoryLimit is the default number of history entries kept.
+const DefaultHistoryLimit = 1000
+
+// Ring is a fixed capacity buffer that overwrites its oldest element when
+// full. It is not safe for concurrent use.
+type Ring[T any] struct {
+	items []T
+	start int // Index of the oldest element.
+	n     int
+}
+
+// NewRing creates a ring holding at most capacity elements.
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{items: make([]T, max(capacity, 1))}
+}
+
+// Push appends v, dropping the oldest element when the ring is full.
+func (r *Ring[T]) Push(v T) {
+	if r.n < len(r.items) {
+		r.items[(r.start+r.n)%len(r.items)] = v
+		r.n++
+		return
+	}
+	r.items[r.start] = v
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// Len returns the number of elements.
+func (r *Ring[T]) Len() int {
+	return r.n
+}
+
+// Cap returns the capacity.
+func (r *Ring[T]) Cap() int {
+	return len(r.items)
+}
+
+// At returns the i-th element, oldest first.
+func (r *Ring[T]) At(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
+
+// Items returns the elements, oldest first.
+func (r *Ring[T]) Items() []T {
+	out := make([]T, r.n)
+	for i := range out {
+		out[i] = r.At(i)
+	}
+
+	return out
+}
+
+// Resize changes the capacity, keeping the newest elements.
+func (r *Ring[T]) Resize(capacity int) {
+	items := r.Items()
+	if len(items) > capacity {
+		items = items[len(items)-capacity:]
+	}
+	*r = *NewRing[T](capacity)
+	for _, v := range items {
+		r.Push(v)
+	}
+}
+
+// Clear removes all elements.
+func (r *Ring[T]) Clear() {
+	clear(r.items)
+	r.start, r.n = 0, 0
+}
@@ -0,0 +1,117 @@
+// nolint:all // test package
+package sender
+
+import (
+	"bytes"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		push     []int
+		want     []int
+	}{
+		{"empty", 3, nil, []int{}},
+		{"partial", 3, []int{1, 2}, []int{1, 2}},
+		{"full", 3, []int{1, 2, 3}, []int{1, 2, 3}},
+		{"wrapped", 3, []int{1, 2, 3, 4, 5}, []int{3, 4, 5}},
+		{"zero_capacity", 0, []int{1, 2}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRing[int](tt.capacity)
+			for _, v := range tt.push {
+				r.Push(v)
+			}
+			if got := r.Items(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Items() = %v, want %v", got, tt.want)
+			}
+			if r.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.want))
+			}
+			for i, v := range tt.want {
+				if r.At(i) != v {
+					t.Errorf("At(%d) = %d, want %d", i, r.At(i), v)
+				}
+			}
+		})
+	}
+}
+
+func TestRing_Resize(t *testing.T) {
+	r := NewRing[int](5)
+	for i := 1; i <= 7; i++ {
+		r.Push(i)
+	}
+
+	r.Resize(3)
+	if got, want := r.Items(), []int{5, 6, 7}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Items() after shrinking = %v, want %v", got, want)
+	}
+
+	r.Resize(4)
+	r.Push(8)
+	r.Push(9)
+	if got, want := r.Items(), []int{6, 7, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Items() after growing = %v, want %v", got, want)
+	}
+
+	r.Clear()
+	if r.Len() != 0 || r.Cap() != 4 {
+		t.Errorf("after Clear() Len = %d, Cap = %d", r.Len(), r.Cap())
+	}
+}
+
+// heapAlloc returns the live heap size after a collection.
+func heapAlloc() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return m.HeapAlloc
+}
+
+func TestRing_BoundedMemory(t *testing.T) {
+	const responses = 50_000
+	payload := bytes.Repeat([]byte("A"), 1024)
+
+	before := heapAlloc()
+	r := NewRing[Result](DefaultHistoryLimit)
+	for i := 0; i < responses; i++ {
+		r.Push(Result{
+			Seq:       i + 1,
+			Timestamp: time.Now(),
+			Request:   []byte("NC"),
+			Response:  bytes.Clone(payload),
+			Latency:   time.Millisecond,
+		})
+	}
+	after := heapAlloc()
+
+	if r.Len() != DefaultHistoryLimit {
+		t.Fatalf("Len() = %d, want %d", r.Len(), DefaultHistoryLimit)
+	}
+	if r.At(0).Seq != responses-DefaultHistoryLimit+1 || r.At(r.Len()-1).Seq != responses {
+		t.Errorf("ring holds requests %d..%d, want the newest", r.At(0).Seq, r.At(r.Len()-1).Seq)
+	}
+	// 50,000 unbounded responses would retain about 50 MB.
+	if grown := int64(after) - int64(before); grown > 8<<20 {
+		t.Errorf("heap grew by %d bytes for %d responses, want a bounded history", grown, responses)
+	}
+	runtime.KeepAlive(r)
+}
+
+func BenchmarkRing_Push(b *testing.B) {
+	r := NewRing[Result](DefaultHistoryLimit)
+	res := Result{Request: []byte("NC"), Response: []byte("ND00"), Latency: time.Millisecond}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Push(res)
+	}
+}
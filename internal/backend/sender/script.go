@@ -0,0 +1,266 @@
+package sender
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// ScriptStep is one command of a script.
+type ScriptStep struct {
+	Line     int    // 1-based line number in the script.
+	Command  string // Command to send.
+	Expected string // Expected response prefix, empty when not checked.
+}
+
+// ParseScript reads a script with one command per line. Blank lines and lines
+// starting with # are ignored. A tab separates the command from an optional
+// expected response prefix. Surrounding white space is trimmed.
+func ParseScript(r io.Reader) ([]ScriptStep, error) {
+	var steps []ScriptStep
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := sc.Text()
+		if trimmed := strings.TrimSpace(text); trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		command, expected, _ := strings.Cut(text, "\t")
+		command = strings.TrimSpace(command)
+		if command == "" {
+			return nil, fmt.Errorf("line %d: missing command", line)
+		}
+		steps = append(steps, ScriptStep{
+			Line:     line,
+			Command:  command,
+			Expected: strings.TrimSpace(expected),
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %v", err)
+	}
+	if len(steps) == 0 {
+		return nil, errors.New("script contains no commands")
+	}
+
+	return steps, nil
+}
+
+// ScriptOutcome is the result of one script step.
+type ScriptOutcome struct {
+	Step   ScriptStep
+	Result Result
+	Passed bool
+}
+
+// Code returns the response and error code of the outcome, or "-" when the
+// response has none.
+func (o ScriptOutcome) Code() string {
+	status, err := utils.ParseHSMResponse(o.Result.Response)
+	if o.Result.Err != nil || err != nil {
+		return "-"
+	}
+
+	return status.ResponseCode + status.ErrorCode
+}
+
+// Actual returns the response, or the error text of a failed request.
+func (o ScriptOutcome) Actual() string {
+	if o.Result.Err != nil {
+		return "Error: " + o.Result.Err.Error()
+	}
+
+	return string(o.Result.Response)
+}
+
+// ScriptReport collects the outcomes of a script run.
+type ScriptReport struct {
+	Outcomes       []ScriptOutcome
+	Total          int // Number of steps in the script.
+	Stopped        bool
+	ConnectionLost bool
+}
+
+// Passed returns the number of passed steps.
+func (r ScriptReport) Passed() int {
+	n := 0
+	for _, o := range r.Outcomes {
+		if o.Passed {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Failed returns the number of executed steps that failed.
+func (r ScriptReport) Failed() int {
+	return len(r.Outcomes) - r.Passed()
+}
+
+// Summary returns a one line summary of the run.
+func (r ScriptReport) Summary() string {
+	s := fmt.Sprintf("Script: %d passed, %d failed, %d of %d commands run",
+		r.Passed(), r.Failed(), len(r.Outcomes), r.Total)
+	switch {
+	case r.ConnectionLost:
+		s += " (connection lost)"
+	case r.Stopped:
+		s += " (stopped)"
+	}
+
+	return s
+}
+
+// RunScript sends the steps one after another and checks each response. A
+// step passes when its response starts with the expected prefix or, without
+// an expectation, when the HSM reports error code 00. The run ends early when
+// stop is closed, which also aborts the command in flight, or the connection is
+// lost. No step is sent while pause, which may be nil, is paused.
+func RunScript(
+	stop <-chan struct{},
+	exec Executor,
+	steps []ScriptStep,
+	timeout time.Duration,
+	pause *Pause,
+	onOutcome func(ScriptOutcome),
+) ScriptReport {
+	report := ScriptReport{Total: len(steps)}
+	ctx, cancel := stopContext(stop, nil)
+	defer cancel()
+	for i, step := range steps {
+		if !pause.wait(stop, nil) || done(stop, nil) {
+			report.Stopped = true
+			break
+		}
+		if !exec.Connected() {
+			report.ConnectionLost = true
+			break
+		}
+
+		command := []byte(step.Command)
+		start := time.Now()
+		resp, err := execute(ctx, exec, command, timeout)
+		if errors.Is(err, ErrCancelled) {
+			report.Stopped = true
+			break
+		}
+		o := ScriptOutcome{
+			Step: step,
+			Result: Result{
+				Seq:       i + 1,
+				Timestamp: start,
+				Request:   command,
+				Response:  resp,
+				Latency:   time.Since(start),
+				Err:       err,
+				Worker:    1,
+			},
+		}
+		o.Passed = stepPassed(step, resp, err)
+		report.Outcomes = append(report.Outcomes, o)
+		if onOutcome != nil {
+			onOutcome(o)
+		}
+		if IsConnectionError(err) {
+			report.ConnectionLost = true
+			break
+		}
+	}
+
+	return report
+}
+
+// stepPassed checks a response against the expectation of step.
+func stepPassed(step ScriptStep, resp []byte, err error) bool {
+	if err != nil {
+		return false
+	}
+	if step.Expected != "" {
+		return matches(step.Expected, resp, nil)
+	}
+	status, perr := utils.ParseHSMResponse(resp)
+
+	return perr == nil && status.OK()
+}
+
+// String renders the report as a table.
+func (r ScriptReport) String() string {
+	var b strings.Builder
+	b.WriteString(r.Summary() + "\n")
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Line\tCommand\tCode\tLatency\tExpected\tActual\tResult")
+	for _, o := range r.Outcomes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s ms\t%s\t%s\t%s\n",
+			o.Step.Line, o.Step.Command, o.Code(), latencyMillis(o.Result.Latency),
+			o.Step.Expected, o.Actual(), passText(o.Passed))
+	}
+	tw.Flush()
+
+	return b.String()
+}
+
+// Export writes the report to w. Unless includeSensitive is set, key-like hex
+// runs in commands and responses are masked.
+func (r ScriptReport) Export(w io.Writer, format ExportFormat, includeSensitive bool) error {
+	mask := logger.MaskHexRuns
+	if includeSensitive {
+		mask = func(s string) string { return s }
+	}
+
+	switch format {
+	case ExportText:
+		masked := r
+		masked.Outcomes = make([]ScriptOutcome, len(r.Outcomes))
+		for i, o := range r.Outcomes {
+			o.Step.Command = mask(o.Step.Command)
+			o.Result.Response = []byte(mask(string(o.Result.Response)))
+			masked.Outcomes[i] = o
+		}
+		_, err := io.WriteString(w, masked.String())
+
+		return err
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{
+			"line", "command", "code", "latency_ms", "expected", "actual", "result",
+		}); err != nil {
+			return err
+		}
+		for _, o := range r.Outcomes {
+			if err := cw.Write([]string{
+				strconv.Itoa(o.Step.Line),
+				mask(o.Step.Command),
+				o.Code(),
+				latencyMillis(o.Result.Latency),
+				o.Step.Expected,
+				mask(o.Actual()),
+				passText(o.Passed),
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+
+		return cw.Error()
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// passText renders a pass/fail flag.
+func passText(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+
+	return "FAIL"
+}
@@ -0,0 +1,222 @@
+// nolint:all // test package
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedExecutor answers commands from a fixed table.
+type scriptedExecutor struct {
+	responses map[string]string
+	errs      map[string]error
+	connected bool
+	sent      []string
+}
+
+func (s *scriptedExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	s.sent = append(s.sent, string(command))
+	if err := s.errs[string(command)]; err != nil {
+		return nil, err
+	}
+
+	return []byte(s.responses[string(command)]), nil
+}
+
+func (s *scriptedExecutor) Connected() bool {
+	return s.connected
+}
+
+func newScriptedExecutor() *scriptedExecutor {
+	return &scriptedExecutor{
+		connected: true,
+		responses: map[string]string{
+			"NC":     "ND007B44AC1DDEE2A94B0007-E000",
+			"A00002": "A1680000",
+			"BU02":   "BV00ABCDEF",
+			"XX":     "?",
+		},
+		errs: map[string]error{
+			"HANG": errors.New("command timed out"),
+			"BAD":  errors.New("invalid response"),
+		},
+	}
+}
+
+const testScript = `# firmware regression
+NC	ND00
+
+A00002	A168
+  BU02
+# trailing comment
+XX
+`
+
+func TestParseScript(t *testing.T) {
+	steps, err := ParseScript(strings.NewReader(testScript))
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+	want := []ScriptStep{
+		{Line: 2, Command: "NC", Expected: "ND00"},
+		{Line: 4, Command: "A00002", Expected: "A168"},
+		{Line: 5, Command: "BU02"},
+		{Line: 7, Command: "XX"},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("ParseScript() = %+v, want %+v", steps, want)
+	}
+}
+
+func TestParseScript_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+	}{
+		{"empty", ""},
+		{"only_comments", "# nothing\n\n   \n"},
+		{"missing_command", "NC\n\tND00\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseScript(strings.NewReader(tt.script)); err == nil {
+				t.Error("ParseScript() expected error")
+			}
+		})
+	}
+}
+
+func TestRunScript(t *testing.T) {
+	steps, _ := ParseScript(strings.NewReader(testScript + "BAD\n"))
+	exec := newScriptedExecutor()
+	var seen int
+
+	report := RunScript(make(chan struct{}), exec, steps, time.Second, nil, func(ScriptOutcome) { seen++ })
+
+	wantPassed := []bool{true, true, true, false, false}
+	if len(report.Outcomes) != len(wantPassed) || seen != len(wantPassed) {
+		t.Fatalf("got %d outcomes, %d callbacks, want %d", len(report.Outcomes), seen, len(wantPassed))
+	}
+	for i, o := range report.Outcomes {
+		if o.Passed != wantPassed[i] {
+			t.Errorf("line %d passed = %v, want %v", o.Step.Line, o.Passed, wantPassed[i])
+		}
+	}
+	if report.Passed() != 3 || report.Failed() != 2 {
+		t.Errorf("passed %d, failed %d, want 3 and 2", report.Passed(), report.Failed())
+	}
+	if got := report.Outcomes[1].Code(); got != "A168" {
+		t.Errorf("Code() = %q, want A168", got)
+	}
+	if got := report.Outcomes[3].Code(); got != "-" {
+		t.Errorf("Code() of an unparseable response = %q, want -", got)
+	}
+
+	text := report.String()
+	for _, want := range []string{
+		"Script: 3 passed, 2 failed, 5 of 5 commands run",
+		"Line",
+		"A00002",
+		"FAIL",
+		"Error: invalid response",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("report missing %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestRunScript_ConnectionLost(t *testing.T) {
+	steps, _ := ParseScript(strings.NewReader("NC\nHANG\nBU02\n"))
+	exec := newScriptedExecutor()
+
+	report := RunScript(make(chan struct{}), exec, steps, time.Second, nil, nil)
+
+	if !report.ConnectionLost || len(report.Outcomes) != 2 {
+		t.Errorf("report = %+v, want stop after the timeout", report)
+	}
+	if !reflect.DeepEqual(exec.sent, []string{"NC", "HANG"}) {
+		t.Errorf("sent %q, want NC and HANG only", exec.sent)
+	}
+	if !strings.Contains(report.Summary(), "connection lost") {
+		t.Errorf("Summary() = %q", report.Summary())
+	}
+
+	exec = newScriptedExecutor()
+	exec.connected = false
+	if report := RunScript(make(chan struct{}), exec, steps, time.Second, nil, nil); !report.ConnectionLost ||
+		len(exec.sent) != 0 {
+		t.Errorf("disconnected run sent %q", exec.sent)
+	}
+}
+
+func TestRunScript_Stop(t *testing.T) {
+	steps, _ := ParseScript(strings.NewReader("NC\nBU02\nNC\n"))
+	exec := newScriptedExecutor()
+	stop := make(chan struct{})
+
+	report := RunScript(stop, exec, steps, time.Second, nil, func(o ScriptOutcome) {
+		if o.Step.Line == 1 {
+			close(stop)
+		}
+	})
+
+	if !report.Stopped || len(report.Outcomes) != 1 {
+		t.Errorf("report = %+v, want stop after the first command", report)
+	}
+}
+
+func TestScriptReport_Export(t *testing.T) {
+	steps, _ := ParseScript(strings.NewReader("A0U0123456789ABCDEF0123456789ABCDEF\tA1\nNC\n"))
+	exec := newScriptedExecutor()
+	report := RunScript(make(chan struct{}), exec, steps, time.Second, nil, nil)
+
+	var buf bytes.Buffer
+	if err := report.Export(&buf, ExportCSV, false); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV does not parse: %v", err)
+	}
+	if len(rows) != 3 || rows[0][0] != "line" {
+		t.Fatalf("rows = %q", rows)
+	}
+	if strings.Contains(rows[1][1], "0123456789ABCDEF") {
+		t.Errorf("command not masked: %q", rows[1][1])
+	}
+	if rows[2][6] != "PASS" {
+		t.Errorf("NC result = %q, want PASS", rows[2][6])
+	}
+
+	buf.Reset()
+	if err := report.Export(&buf, ExportText, true); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "A0U0123456789ABCDEF0123456789ABCDEF") {
+		t.Errorf("sensitive export masked the command:\n%s", buf.String())
+	}
+}
+
+func TestRunScript_StopCancelsInFlight(t *testing.T) {
+	steps, _ := ParseScript(strings.NewReader("NC\nNC\n"))
+	stop := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(stop) })
+
+	start := time.Now()
+	report := RunScript(stop, blockingExecutor{}, steps, 5*time.Second, nil, nil)
+
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("RunScript returned after %v, want within about 100ms of Stop", elapsed)
+	}
+	if !report.Stopped || len(report.Outcomes) != 0 {
+		t.Errorf("report = %+v, want a stopped run without outcomes", report)
+	}
+}
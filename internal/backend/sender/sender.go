@@ -0,0 +1,429 @@
+// Package sender drives batches of HSM commands for load and soak tests.
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Executor sends single commands to the HSM.
+type Executor interface {
+	// ExecuteCommandContext sends command and waits for the response until
+	// ctx is done.
+	ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error)
+	// Connected reports whether commands can currently be sent.
+	Connected() bool
+}
+
+// connectionErrors are the transport errors that end a run because the HSM
+// is no longer reachable.
+var connectionErrors = map[string]bool{
+	"hsm client not connected": true,
+	"broker is closed":         true,
+	"command timed out":        true,
+}
+
+// IsConnectionError reports whether err means the connection to the HSM was
+// lost rather than a single request failing.
+func IsConnectionError(err error) bool {
+	return err != nil && connectionErrors[err.Error()]
+}
+
+// IsTimeout reports whether err means a request received no response in time.
+func IsTimeout(err error) bool {
+	return err != nil && (err.Error() == "command timed out" || errors.Is(err, context.DeadlineExceeded))
+}
+
+// Retry limits.
+const (
+	MaxRetries          = 5
+	DefaultRetryBackoff = 200 * time.Millisecond
+)
+
+// Request timeout limits.
+const (
+	DefaultTimeout = 5 * time.Second
+	MaxTimeout     = 120 * time.Second
+)
+
+// ParseTimeout parses a request timeout given in milliseconds. It must be a
+// positive integer of at most MaxTimeout.
+func ParseTimeout(ms string) (time.Duration, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(ms))
+	if err != nil {
+		return 0, errors.New("timeout must be a whole number of milliseconds")
+	}
+	if n <= 0 || n > int(MaxTimeout.Milliseconds()) {
+		return 0, fmt.Errorf("timeout must be between 1 and %d ms", MaxTimeout.Milliseconds())
+	}
+
+	return time.Duration(n) * time.Millisecond, nil
+}
+
+// ErrCancelled is the error of a request aborted because the run was stopped.
+var ErrCancelled = errors.New("cancelled")
+
+// execute sends command with the given timeout. It fails with ErrCancelled
+// when ctx is cancelled before the response arrives.
+func execute(ctx context.Context, exec Executor, command []byte, timeout time.Duration) ([]byte, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := exec.ExecuteCommandContext(reqCtx, command)
+	if err != nil && ctx.Err() != nil {
+		return nil, ErrCancelled
+	}
+
+	return resp, err
+}
+
+// stopContext returns a context that is cancelled once stop or halt is
+// closed, aborting the requests in flight.
+func stopContext(stop, halt <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+		case <-halt:
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	return ctx, cancel
+}
+
+// Config describes a run.
+type Config struct {
+	Command  []byte
+	Template *Template     // Expanded per request instead of Command when set.
+	Lines    []Line        // Sent in order instead of Command when set.
+	Count    int           // Requests, or iterations of Lines, when Duration is zero.
+	Warmup   int           // Initial requests excluded from the statistics.
+	Duration time.Duration // Keep sending until it elapses when non-zero.
+	Workers  int           // Concurrent senders; 1 or less sends sequentially.
+	Timeout  time.Duration // Per-request timeout.
+	Delay    time.Duration // Pause after each request of a worker.
+	Rate     float64       // Target requests per second across workers; unlimited if zero.
+
+	Retries      int           // Resend a timed out request up to this many times.
+	RetryBackoff time.Duration // Base pause between retries, growing linearly.
+
+	Expected      string // Expected response prefix, empty when not checked.
+	StopOnFailure bool   // End the run at the first unexpected response.
+
+	Pause *Pause // Holds back new requests while paused, may be nil.
+
+	Secondary Executor // Also receives every request for comparison when set.
+	Mask      Mask     // Response bytes ignored when comparing with Secondary.
+}
+
+// Result is the outcome of a single request.
+type Result struct {
+	Seq       int // 1-based request number.
+	Timestamp time.Time
+	Request   []byte
+	Response  []byte
+	Latency   time.Duration
+	Err       error
+	Line      int  // Line of the command set that produced the request, 0 if none.
+	Failed    bool // The response did not match Config.Expected.
+	Warmup    bool // Sent during the warm-up, excluded from the statistics.
+	Retries   int  // Times the request was resent after a timeout.
+	Worker    int  // 1-based worker that sent the request.
+
+	Compare *Comparison // Response of the secondary target, nil unless comparing.
+}
+
+// Mismatch reports whether the secondary target disagreed with the response.
+func (r Result) Mismatch() bool {
+	return r.Compare != nil && !r.Compare.Match
+}
+
+// Cancelled reports whether the request was aborted by stopping the run.
+func (r Result) Cancelled() bool {
+	return errors.Is(r.Err, ErrCancelled)
+}
+
+// Summary describes a finished run.
+type Summary struct {
+	Sent           int           // Completed requests after the warm-up, including HSM-level errors.
+	Warmup         int           // Warm-up requests that completed.
+	Elapsed        time.Duration // Time since the warm-up completed.
+	Stopped        bool          // Stop was requested before the run completed.
+	ConnectionLost bool
+	Passed         int  // Responses matching Config.Expected.
+	Failed         int  // Responses not matching Config.Expected.
+	FailureStop    bool // The run ended at an unexpected response.
+	Mismatches     int  // Requests on which the secondary target disagreed.
+	Workers        WorkerStats
+}
+
+// TPS returns the average number of completed requests per second.
+func (s Summary) TPS() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+
+	return float64(s.Sent) / s.Elapsed.Seconds()
+}
+
+// Run sends cfg.Command until cfg.Count requests were sent or cfg.Duration
+// elapsed, stop is closed or the connection is lost. With cfg.Lines every
+// iteration sends each line in turn. Responses are checked against
+// cfg.Expected when set, optionally ending the run at the first failure.
+// The first cfg.Warmup requests are sent in addition to cfg.Count and are
+// excluded from the summary; the duration and the timer start once they have
+// completed. Timed out requests are resent up to cfg.Retries times; responses,
+// including HSM error codes, are never retried. Closing stop also aborts the
+// requests in flight, which are reported with ErrCancelled. While cfg.Pause
+// is paused no new requests are dispatched and the paused time counts neither
+// towards the duration nor the elapsed time. With cfg.Secondary every request
+// is also sent, in parallel and without retries, to the secondary target and
+// the responses are compared outside cfg.Mask; a request that fails on either
+// target counts as a mismatch. onResult is called from the sending goroutines
+// after every request.
+func Run(stop <-chan struct{}, exec Executor, cfg Config, onResult func(Result)) Summary {
+	workers := max(cfg.Workers, 1)
+	warmup := max(cfg.Warmup, 0)
+	backoff := cfg.RetryBackoff
+	if backoff <= 0 {
+		backoff = DefaultRetryBackoff
+	}
+	total := cfg.Count
+	if len(cfg.Lines) > 0 {
+		total *= len(cfg.Lines)
+	}
+	total += warmup
+
+	// measureStart holds the Unix time in nanoseconds when the warm-up
+	// completed, or zero while it is in progress.
+	var measureStart, warmed atomic.Int64
+	if warmup == 0 {
+		measureStart.Store(time.Now().UnixNano())
+	}
+	expired := func() bool {
+		ns := measureStart.Load()
+		return cfg.Duration > 0 && ns != 0 && cfg.Pause.Active(time.Unix(0, ns), time.Now()) >= cfg.Duration
+	}
+
+	var next, sent, passed, failed, mismatches atomic.Int64
+	var lost, failureStop atomic.Bool
+	halt := make(chan struct{})
+	var haltOnce sync.Once
+	ctx, cancel := stopContext(stop, halt)
+	defer cancel()
+	abort := func() {
+		lost.Store(true)
+		haltOnce.Do(func() { close(halt) })
+	}
+	check := func(r *Result) {
+		if r.Warmup {
+			if warmed.Add(1) == int64(warmup) {
+				measureStart.Store(time.Now().UnixNano())
+			}
+			return
+		}
+		if cfg.Expected == "" {
+			return
+		}
+		if r.Failed = !matches(cfg.Expected, r.Response, r.Err); !r.Failed {
+			passed.Add(1)
+			return
+		}
+		failed.Add(1)
+		if cfg.StopOnFailure {
+			failureStop.Store(true)
+			haltOnce.Do(func() { close(halt) })
+		}
+	}
+
+	var limiter *pacer
+	if cfg.Rate > 0 {
+		limiter = newPacer(cfg.Rate)
+	}
+
+	// Every worker only updates its own entry, so no locking is needed.
+	stats := make(WorkerStats, workers)
+	var wg sync.WaitGroup
+	for i := range workers {
+		stats[i].Worker = i + 1
+		stat := &stats[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if done(stop, halt) {
+					return
+				}
+				if !cfg.Pause.wait(stop, halt) {
+					return
+				}
+				if expired() {
+					return
+				}
+				seq := int(next.Add(1))
+				if cfg.Duration <= 0 && seq > total {
+					return
+				}
+				if limiter != nil && !limiter.wait(stop, halt) {
+					return
+				}
+				if !exec.Connected() || (cfg.Secondary != nil && !cfg.Secondary.Connected()) {
+					abort()
+					return
+				}
+
+				command, line, err := cfg.request(seq)
+				if err != nil {
+					r := Result{
+						Seq:       seq,
+						Line:      line,
+						Timestamp: time.Now(),
+						Err:       err,
+						Warmup:    seq <= warmup,
+						Worker:    stat.Worker,
+					}
+					check(&r)
+					onResult(r)
+					continue
+				}
+
+				var secondary chan *Comparison
+				if cfg.Secondary != nil {
+					secondary = make(chan *Comparison, 1)
+					go func() { secondary <- sendSecondary(ctx, cfg.Secondary, command, cfg.Timeout) }()
+				}
+				startTime := time.Now()
+				resp, err := execute(ctx, exec, command, cfg.Timeout)
+				retries := 0
+				for ; retries < cfg.Retries && IsTimeout(err); retries++ {
+					if !sleep(time.Duration(retries+1)*backoff, stop, halt) {
+						err = ErrCancelled
+						break
+					}
+					if !exec.Connected() {
+						break
+					}
+					startTime = time.Now()
+					resp, err = execute(ctx, exec, command, cfg.Timeout)
+				}
+				r := Result{
+					Seq:       seq,
+					Line:      line,
+					Timestamp: startTime,
+					Request:   command,
+					Response:  resp,
+					Latency:   time.Since(startTime),
+					Err:       err,
+					Warmup:    seq <= warmup,
+					Retries:   retries,
+					Worker:    stat.Worker,
+				}
+				if secondary != nil {
+					r.Compare = <-secondary
+					r.Compare.Match = r.Err == nil && r.Compare.Err == nil &&
+						cfg.Mask.Equal(r.Response, r.Compare.Response)
+					if errors.Is(r.Compare.Err, ErrCancelled) {
+						r.Err = ErrCancelled
+					}
+				}
+				if r.Cancelled() {
+					onResult(r)
+					return
+				}
+				check(&r)
+				if !r.Warmup {
+					stat.record(r)
+				}
+				if IsConnectionError(err) || (r.Compare != nil && IsConnectionError(r.Compare.Err)) {
+					abort()
+					onResult(r)
+					return
+				}
+				if !r.Warmup {
+					sent.Add(1)
+					if r.Mismatch() {
+						mismatches.Add(1)
+					}
+				}
+				onResult(r)
+
+				if cfg.Delay > 0 && !sleep(cfg.Delay, stop, halt) {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var elapsed time.Duration
+	if ns := measureStart.Load(); ns != 0 {
+		elapsed = cfg.Pause.Active(time.Unix(0, ns), time.Now())
+	}
+
+	return Summary{
+		Sent:           int(sent.Load()),
+		Warmup:         int(warmed.Load()),
+		Elapsed:        elapsed,
+		Stopped:        done(stop, nil),
+		ConnectionLost: lost.Load(),
+		Passed:         int(passed.Load()),
+		Failed:         int(failed.Load()),
+		FailureStop:    failureStop.Load(),
+		Mismatches:     int(mismatches.Load()),
+		Workers:        stats,
+	}
+}
+
+// request returns the command of request seq and the line it came from.
+func (cfg *Config) request(seq int) ([]byte, int, error) {
+	command, tmpl, line := cfg.Command, cfg.Template, 0
+	if n := len(cfg.Lines); n > 0 {
+		l := cfg.Lines[(seq-1)%n]
+		command, tmpl, line = l.Command, l.Template, l.Number
+	}
+	if tmpl == nil {
+		return command, line, nil
+	}
+	command, err := tmpl.Expand(seq, time.Now())
+
+	return command, line, err
+}
+
+// matches reports whether a response starts with the expected prefix.
+func matches(expected string, resp []byte, err error) bool {
+	return err == nil && strings.HasPrefix(string(resp), expected)
+}
+
+// done reports whether stop or halt is closed.
+func done(stop, halt <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	case <-halt:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for d and reports false when stop or halt closed first.
+func sleep(d time.Duration, stop, halt <-chan struct{}) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-stop:
+		return false
+	case <-halt:
+		return false
+	}
+}
@@ -0,0 +1,505 @@
+// nolint:all // test package
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockExecutor answers every command after delay with response.
+type mockExecutor struct {
+	delay     time.Duration
+	response  []byte
+	err       error
+	failAfter int64 // Return err from this call on when non-zero.
+	slowCalls int64 // The first slowCalls calls take slowDelay.
+	slowDelay time.Duration
+	calls     atomic.Int64
+	connected atomic.Bool
+
+	mu       sync.Mutex
+	commands []string
+}
+
+func newMockExecutor(delay time.Duration) *mockExecutor {
+	m := &mockExecutor{delay: delay, response: []byte("NDZ000")}
+	m.connected.Store(true)
+
+	return m
+}
+
+func (m *mockExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	n := m.calls.Add(1)
+	m.mu.Lock()
+	m.commands = append(m.commands, string(command))
+	m.mu.Unlock()
+	if n <= m.slowCalls {
+		time.Sleep(m.slowDelay)
+	} else {
+		time.Sleep(m.delay)
+	}
+	if m.err != nil && n >= m.failAfter {
+		return nil, m.err
+	}
+
+	return m.response, nil
+}
+
+func (m *mockExecutor) Connected() bool {
+	return m.connected.Load()
+}
+
+// collect returns an onResult callback and a function returning the results.
+func collect() (func(Result), func() []Result) {
+	var mu sync.Mutex
+	var results []Result
+
+	return func(r Result) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}, func() []Result {
+			mu.Lock()
+			defer mu.Unlock()
+
+			return results
+		}
+}
+
+func TestRun_Count(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"sequential", 1},
+		{"concurrent", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newMockExecutor(time.Millisecond)
+			onResult, results := collect()
+
+			sum := Run(make(chan struct{}), exec, Config{
+				Command: []byte("NC"),
+				Count:   25,
+				Workers: tt.workers,
+				Timeout: time.Second,
+			}, onResult)
+
+			if sum.Sent != 25 || len(results()) != 25 || exec.calls.Load() != 25 {
+				t.Errorf("sent %d, results %d, calls %d, want 25", sum.Sent, len(results()), exec.calls.Load())
+			}
+			seen := make(map[int]bool)
+			for _, r := range results() {
+				seen[r.Seq] = true
+			}
+			for seq := 1; seq <= 25; seq++ {
+				if !seen[seq] {
+					t.Errorf("missing result for request %d", seq)
+				}
+			}
+			if sum.Stopped || sum.ConnectionLost {
+				t.Errorf("summary = %+v, want a completed run", sum)
+			}
+		})
+	}
+}
+
+func TestRun_Duration(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		delay   time.Duration
+	}{
+		{"sequential", 1, 10 * time.Millisecond},
+		{"concurrent", 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newMockExecutor(5 * time.Millisecond)
+			onResult, results := collect()
+
+			sum := Run(make(chan struct{}), exec, Config{
+				Command:  []byte("NC"),
+				Duration: time.Second,
+				Workers:  tt.workers,
+				Timeout:  time.Second,
+				Delay:    tt.delay,
+			}, onResult)
+
+			if sum.Elapsed < time.Second || sum.Elapsed > 1200*time.Millisecond {
+				t.Errorf("elapsed = %v, want about 1s", sum.Elapsed)
+			}
+			if sum.Sent == 0 || sum.Sent != len(results()) {
+				t.Errorf("sent = %d, results = %d", sum.Sent, len(results()))
+			}
+			if tps := sum.TPS(); tps <= 0 {
+				t.Errorf("TPS() = %v, want > 0", tps)
+			}
+		})
+	}
+}
+
+func TestRun_Stop(t *testing.T) {
+	for _, duration := range []time.Duration{0, time.Minute} {
+		exec := newMockExecutor(5 * time.Millisecond)
+		onResult, _ := collect()
+		stop := make(chan struct{})
+		time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+
+		start := time.Now()
+		sum := Run(stop, exec, Config{
+			Command:  []byte("NC"),
+			Count:    1_000_000,
+			Duration: duration,
+			Workers:  2,
+			Timeout:  time.Second,
+		}, onResult)
+
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("duration %v: Run returned after %v, want prompt stop", duration, elapsed)
+		}
+		if !sum.Stopped {
+			t.Errorf("duration %v: summary not marked stopped", duration)
+		}
+	}
+}
+
+func TestRun_ConnectionLost(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		workers  int
+	}{
+		{"count_sequential", 0, 1},
+		{"duration_concurrent", time.Minute, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := newMockExecutor(time.Millisecond)
+			exec.err = errors.New("broker is closed")
+			exec.failAfter = 5
+			onResult, results := collect()
+
+			sum := Run(make(chan struct{}), exec, Config{
+				Command:  []byte("NC"),
+				Count:    100,
+				Duration: tt.duration,
+				Workers:  tt.workers,
+				Timeout:  time.Second,
+			}, onResult)
+
+			if !sum.ConnectionLost {
+				t.Fatal("summary not marked as connection lost")
+			}
+			if sum.Sent >= 100 || sum.Sent > len(results()) {
+				t.Errorf("sent = %d, results = %d", sum.Sent, len(results()))
+			}
+		})
+	}
+
+	exec := newMockExecutor(0)
+	exec.connected.Store(false)
+	onResult, results := collect()
+	sum := Run(make(chan struct{}), exec, Config{Command: []byte("NC"), Count: 5}, onResult)
+	if !sum.ConnectionLost || sum.Sent != 0 || len(results()) != 0 || exec.calls.Load() != 0 {
+		t.Errorf("disconnected run = %+v, %d results, %d calls", sum, len(results()), exec.calls.Load())
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("command timed out"), true},
+		{errors.New("hsm client not connected"), true},
+		{errors.New("invalid response"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsConnectionError(tt.err); got != tt.want {
+			t.Errorf("IsConnectionError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+// blockingExecutor never answers and fails once the request context is done.
+type blockingExecutor struct{}
+
+func (blockingExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	<-ctx.Done()
+
+	return nil, ctx.Err()
+}
+
+func (blockingExecutor) Connected() bool {
+	return true
+}
+
+func TestRun_Timeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		timeout time.Duration
+	}{
+		{"sequential_short", 1, 50 * time.Millisecond},
+		{"sequential_long", 1, 300 * time.Millisecond},
+		{"concurrent", 4, 150 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onResult, results := collect()
+
+			Run(make(chan struct{}), blockingExecutor{}, Config{
+				Command: []byte("NC"),
+				Count:   tt.workers,
+				Workers: tt.workers,
+				Timeout: tt.timeout,
+			}, onResult)
+
+			if got := len(results()); got != tt.workers {
+				t.Fatalf("got %d results, want %d", got, tt.workers)
+			}
+			for _, r := range results() {
+				if !errors.Is(r.Err, context.DeadlineExceeded) {
+					t.Errorf("request %d error = %v, want deadline exceeded", r.Seq, r.Err)
+				}
+				if r.Latency < tt.timeout || r.Latency > tt.timeout+250*time.Millisecond {
+					t.Errorf("request %d failed after %v, want about %v", r.Seq, r.Latency, tt.timeout)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTimeout(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"5000", 5 * time.Second, false},
+		{" 250 ", 250 * time.Millisecond, false},
+		{"120000", MaxTimeout, false},
+		{"120001", 0, true},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseTimeout(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTimeout(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimeout(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_Warmup(t *testing.T) {
+	exec := newMockExecutor(time.Millisecond)
+	exec.slowCalls, exec.slowDelay = 5, 50*time.Millisecond
+	onResult, results := collect()
+
+	sum := Run(make(chan struct{}), exec, Config{
+		Command:  []byte("NC"),
+		Count:    10,
+		Warmup:   5,
+		Workers:  1,
+		Timeout:  time.Second,
+		Expected: "NDZ0",
+	}, onResult)
+
+	if got := exec.calls.Load(); got != 15 {
+		t.Fatalf("sent %d requests, want 15", got)
+	}
+	if sum.Sent != 10 || sum.Warmup != 5 {
+		t.Errorf("Sent = %d, Warmup = %d, want 10 and 5", sum.Sent, sum.Warmup)
+	}
+	if sum.Passed != 10 || sum.Failed != 0 {
+		t.Errorf("passed %d, failed %d, want 10 and 0", sum.Passed, sum.Failed)
+	}
+	// The timer starts after the 250 ms warm-up.
+	if sum.Elapsed >= 150*time.Millisecond {
+		t.Errorf("Elapsed = %v includes the warm-up", sum.Elapsed)
+	}
+
+	latencies := NewLatencyRecorder(DefaultReservoirSize)
+	for _, r := range results() {
+		if want := r.Seq <= 5; r.Warmup != want {
+			t.Errorf("request %d Warmup = %v, want %v", r.Seq, r.Warmup, want)
+		}
+		if !r.Warmup {
+			latencies.Record(r.Latency)
+		}
+	}
+	if stats := latencies.Stats(); stats.Count != 10 || stats.Max >= 40*time.Millisecond {
+		t.Errorf("post warm-up latencies = %s, want only the fast requests", stats)
+	}
+}
+
+func TestRun_WarmupDuration(t *testing.T) {
+	exec := newMockExecutor(time.Millisecond)
+	exec.slowCalls, exec.slowDelay = 3, 100*time.Millisecond
+
+	start := time.Now()
+	sum := Run(make(chan struct{}), exec, Config{
+		Command:  []byte("NC"),
+		Duration: 100 * time.Millisecond,
+		Warmup:   3,
+		Workers:  1,
+		Timeout:  time.Second,
+	}, func(Result) {})
+
+	if total := time.Since(start); total < 400*time.Millisecond {
+		t.Errorf("run took %v, want the duration to start after the warm-up", total)
+	}
+	if sum.Warmup != 3 || sum.Sent == 0 {
+		t.Errorf("Warmup = %d, Sent = %d", sum.Warmup, sum.Sent)
+	}
+	if sum.Elapsed < 100*time.Millisecond || sum.Elapsed > 250*time.Millisecond {
+		t.Errorf("Elapsed = %v, want about the duration", sum.Elapsed)
+	}
+}
+
+func TestRun_StopCancelsInFlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+	}{
+		{"sequential", 1},
+		{"concurrent", 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stop := make(chan struct{})
+			onResult, results := collect()
+			time.AfterFunc(50*time.Millisecond, func() { close(stop) })
+
+			start := time.Now()
+			sum := Run(stop, blockingExecutor{}, Config{
+				Command: []byte("NC"),
+				Count:   100,
+				Workers: tt.workers,
+				Timeout: 5 * time.Second,
+			}, onResult)
+
+			if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+				t.Errorf("Run returned %v after start, want within about 100ms of Stop", elapsed)
+			}
+			if !sum.Stopped || sum.Sent != 0 || sum.ConnectionLost {
+				t.Errorf("summary = %+v", sum)
+			}
+			if got := len(results()); got != tt.workers {
+				t.Errorf("got %d results, want one per worker", got)
+			}
+			for _, r := range results() {
+				if !r.Cancelled() {
+					t.Errorf("request %d error = %v, want cancelled", r.Seq, r.Err)
+				}
+			}
+		})
+	}
+}
+
+// flakyExecutor fails the first calls with err, then answers NDZ000.
+type flakyExecutor struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, f.err
+	}
+
+	return []byte("NDZ000"), nil
+}
+
+func (f *flakyExecutor) Connected() bool {
+	return true
+}
+
+func TestRun_Retry(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		retries     int
+		wantCalls   int
+		wantRetries int
+		wantErr     bool
+	}{
+		{"recovers", errors.New("command timed out"), 3, 3, 2, false},
+		{"deadline_exceeded", context.DeadlineExceeded, 2, 3, 2, false},
+		{"exhausted", errors.New("command timed out"), 1, 2, 1, true},
+		{"disabled", errors.New("command timed out"), 0, 1, 0, true},
+		{"not_a_timeout", errors.New("invalid response"), 3, 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := &flakyExecutor{failures: 2, err: tt.err}
+			onResult, results := collect()
+
+			Run(make(chan struct{}), exec, Config{
+				Command:      []byte("NC"),
+				Count:        1,
+				Timeout:      time.Second,
+				Retries:      tt.retries,
+				RetryBackoff: time.Millisecond,
+			}, onResult)
+
+			if exec.calls != tt.wantCalls {
+				t.Errorf("sent %d times, want %d", exec.calls, tt.wantCalls)
+			}
+			got := results()
+			if len(got) != 1 {
+				t.Fatalf("got %d results, want one entry", len(got))
+			}
+			if got[0].Retries != tt.wantRetries || (got[0].Err != nil) != tt.wantErr {
+				t.Errorf("result retries = %d, err = %v, want %d retries, error %v",
+					got[0].Retries, got[0].Err, tt.wantRetries, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRun_RetryStop(t *testing.T) {
+	exec := &flakyExecutor{failures: 10, err: errors.New("command timed out")}
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+	onResult, results := collect()
+
+	sum := Run(stop, exec, Config{
+		Command:      []byte("NC"),
+		Count:        1,
+		Timeout:      time.Second,
+		Retries:      MaxRetries,
+		RetryBackoff: time.Second,
+	}, onResult)
+
+	if exec.calls != 1 || !sum.Stopped || sum.ConnectionLost {
+		t.Errorf("calls = %d, summary = %+v, want a stop during the backoff", exec.calls, sum)
+	}
+	if r := results(); len(r) != 1 || !r[0].Cancelled() {
+		t.Errorf("results = %+v, want one cancelled request", r)
+	}
+}
@@ -0,0 +1,69 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// SparklineWindow is the number of seconds shown by the live TPS sparkline.
+const SparklineWindow = 120
+
+// TPSSeries counts completed requests per second of a run. It is safe for
+// concurrent use.
+type TPSSeries struct {
+	mu     sync.Mutex
+	start  time.Time
+	counts []int // counts[i] holds the completions in second i of the run.
+}
+
+// NewTPSSeries creates a series whose first second begins at start.
+func NewTPSSeries(start time.Time) *TPSSeries {
+	return &TPSSeries{start: start}
+}
+
+// second returns the index of the second of the run that t falls in.
+func (s *TPSSeries) second(t time.Time) int {
+	d := t.Sub(s.start)
+	if d < 0 {
+		return 0
+	}
+
+	return int(d / time.Second)
+}
+
+// Record counts a request completed at t.
+func (s *TPSSeries) Record(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.second(t)
+	for len(s.counts) <= i {
+		s.counts = append(s.counts, 0)
+	}
+	s.counts[i]++
+}
+
+// Counts returns the completions of every second from the start of the run
+// up to and including the second that now falls in. Seconds without
+// completions are zero.
+func (s *TPSSeries) Counts(now time.Time) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make([]int, max(s.second(now)+1, len(s.counts)))
+	copy(counts, s.counts)
+
+	return counts
+}
+
+// Last returns at most the n most recent seconds of Counts. The second that
+// now falls in is still filling and is left out.
+func (s *TPSSeries) Last(now time.Time, n int) []int {
+	counts := s.Counts(now)
+	counts = counts[:len(counts)-1]
+	if len(counts) > n {
+		counts = counts[len(counts)-n:]
+	}
+
+	return counts
+}
@@ -0,0 +1,84 @@
+// nolint:all // test package
+package sender
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTPSSeries_Buckets(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tests := []struct {
+		name   string
+		record []time.Duration
+		now    time.Duration
+		want   []int
+	}{
+		{"empty", nil, 0, []int{0}},
+		{"first_second", []time.Duration{0, 500 * time.Millisecond, 999 * time.Millisecond}, 0, []int{3}},
+		{"boundary", []time.Duration{999 * time.Millisecond, time.Second}, time.Second, []int{1, 1}},
+		{"gap", []time.Duration{100 * time.Millisecond, 3500 * time.Millisecond}, 4 * time.Second, []int{1, 0, 0, 1, 0}},
+		{"before_start", []time.Duration{-time.Second}, 0, []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewTPSSeries(start)
+			for _, d := range tt.record {
+				s.Record(at(d))
+			}
+			if got := s.Counts(at(tt.now)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Counts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTPSSeries_Last(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	s := NewTPSSeries(start)
+	for i := 0; i < 5; i++ {
+		for j := 0; j <= i; j++ {
+			s.Record(start.Add(time.Duration(i)*time.Second + 10*time.Millisecond))
+		}
+	}
+
+	// The current second is still filling and is not reported.
+	if got := s.Last(start.Add(4500*time.Millisecond), 10); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Last(10) = %v", got)
+	}
+	if got := s.Last(start.Add(4500*time.Millisecond), 2); !reflect.DeepEqual(got, []int{3, 4}) {
+		t.Errorf("Last(2) = %v", got)
+	}
+	if got := NewTPSSeries(start).Last(start, 2); len(got) != 0 {
+		t.Errorf("Last() in the first second = %v, want none", got)
+	}
+}
+
+func TestTPSSeries_Concurrent(t *testing.T) {
+	start := time.Now()
+	s := NewTPSSeries(start)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				s.Record(start.Add(time.Duration(i%3) * time.Second))
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for _, n := range s.Counts(start) {
+		total += n
+	}
+	if total != 8000 {
+		t.Errorf("total = %d, want 8000", total)
+	}
+}
@@ -0,0 +1,97 @@
+package sender
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ParseThreshold parses a latency threshold given in milliseconds. An empty
+// value switches the threshold off and returns zero.
+func ParseThreshold(ms string) (time.Duration, error) {
+	ms = strings.TrimSpace(ms)
+	if ms == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(ms)
+	if err != nil || n <= 0 {
+		return 0, errors.New("latency threshold must be a positive number of milliseconds")
+	}
+
+	return time.Duration(n) * time.Millisecond, nil
+}
+
+// SlowStats counts the requests of a run that exceeded a latency threshold.
+type SlowStats struct {
+	Threshold time.Duration
+	Slow      int // Requests with a latency above the threshold.
+	Total     int // Requests checked.
+}
+
+// Percent returns the share of slow requests rounded half up to one decimal.
+func (s SlowStats) Percent() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+
+	return math.Round(1000*float64(s.Slow)/float64(s.Total)) / 10
+}
+
+// Label returns the compact counter shown next to the TPS.
+func (s SlowStats) Label() string {
+	return fmt.Sprintf("Slow: %d (%.1f%%)", s.Slow, s.Percent())
+}
+
+// String returns a one line summary for run reports.
+func (s SlowStats) String() string {
+	return fmt.Sprintf("Latency threshold %d ms: %d of %d requests slower (%.1f%%)",
+		s.Threshold.Milliseconds(), s.Slow, s.Total, s.Percent())
+}
+
+// rows returns the statistics as CSV records with a header row.
+func (s SlowStats) rows() [][]string {
+	return [][]string{
+		{"threshold_ms", "slow", "total", "percent"},
+		{
+			strconv.FormatInt(s.Threshold.Milliseconds(), 10),
+			strconv.Itoa(s.Slow),
+			strconv.Itoa(s.Total),
+			strconv.FormatFloat(s.Percent(), 'f', 1, 64),
+		},
+	}
+}
+
+// SlowCounter counts requests slower than a threshold. It is safe for
+// concurrent use.
+type SlowCounter struct {
+	threshold   time.Duration
+	slow, total atomic.Int64
+}
+
+// NewSlowCounter creates a counter for threshold.
+func NewSlowCounter(threshold time.Duration) *SlowCounter {
+	return &SlowCounter{threshold: threshold}
+}
+
+// IsSlow reports whether latency is above threshold. A zero threshold is
+// never exceeded.
+func IsSlow(latency, threshold time.Duration) bool {
+	return threshold > 0 && latency > threshold
+}
+
+// Record counts a request with the given latency.
+func (c *SlowCounter) Record(latency time.Duration) {
+	c.total.Add(1)
+	if IsSlow(latency, c.threshold) {
+		c.slow.Add(1)
+	}
+}
+
+// Stats returns the counts so far.
+func (c *SlowCounter) Stats() SlowStats {
+	return SlowStats{Threshold: c.threshold, Slow: int(c.slow.Load()), Total: int(c.total.Load())}
+}
@@ -0,0 +1,103 @@
+// nolint:all // test package
+package sender
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{" 50 ", 50 * time.Millisecond, false},
+		{"0", 0, true},
+		{"-5", 0, true},
+		{"5.5", 0, true},
+		{"abc", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseThreshold(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseThreshold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlowCounter(t *testing.T) {
+	threshold := 50 * time.Millisecond
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		wantSlow  int
+		wantLabel string
+	}{
+		{"none", nil, 0, "Slow: 0 (0.0%)"},
+		{"at_threshold_is_not_slow", []time.Duration{threshold, threshold - time.Microsecond}, 0, "Slow: 0 (0.0%)"},
+		{"just_above", []time.Duration{threshold + time.Microsecond, threshold}, 1, "Slow: 1 (50.0%)"},
+		{"third", []time.Duration{49 * time.Millisecond, 51 * time.Millisecond, 10 * time.Millisecond}, 1, "Slow: 1 (33.3%)"},
+		{"two_thirds", []time.Duration{60 * time.Millisecond, 51 * time.Millisecond, 10 * time.Millisecond}, 2, "Slow: 2 (66.7%)"},
+		{
+			// 1/16 is 6.25%, which rounds half up.
+			"half_up",
+			append([]time.Duration{time.Second}, make([]time.Duration, 15)...),
+			1,
+			"Slow: 1 (6.3%)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSlowCounter(threshold)
+			for _, d := range tt.latencies {
+				c.Record(d)
+			}
+			s := c.Stats()
+			if s.Slow != tt.wantSlow || s.Total != len(tt.latencies) {
+				t.Errorf("Stats() = %+v, want %d slow of %d", s, tt.wantSlow, len(tt.latencies))
+			}
+			if got := s.Label(); got != tt.wantLabel {
+				t.Errorf("Label() = %q, want %q", got, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestSlowCounter_Concurrent(t *testing.T) {
+	c := NewSlowCounter(10 * time.Millisecond)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Record(time.Duration(i%4) * 5 * time.Millisecond) // 0, 5, 10, 15 ms
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := SlowStats{Threshold: 10 * time.Millisecond, Slow: 1000, Total: 4000}
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+	if got := want.String(); got != "Latency threshold 10 ms: 1000 of 4000 requests slower (25.0%)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestIsSlow_NoThreshold(t *testing.T) {
+	if IsSlow(time.Hour, 0) {
+		t.Error("IsSlow() with no threshold = true")
+	}
+}
@@ -0,0 +1,115 @@
+package sender
+
+import "fmt"
+
+// RunState is the state of a Command Sender run as seen by its controls.
+type RunState int
+
+// Run states.
+const (
+	StateIdle     RunState = iota // No run in progress.
+	StateSending                  // Requests are being dispatched.
+	StatePaused                   // Dispatch is held back, the run state is kept.
+	StateStopping                 // Stop was requested, the run is winding down.
+)
+
+// String returns the name of the state.
+func (s RunState) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateSending:
+		return "sending"
+	case StatePaused:
+		return "paused"
+	case StateStopping:
+		return "stopping"
+	default:
+		return fmt.Sprintf("RunState(%d)", int(s))
+	}
+}
+
+// RunEvent is a user action or run outcome that changes the run state.
+type RunEvent int
+
+// Run events.
+const (
+	EventStart  RunEvent = iota // A run was started.
+	EventPause                  // Pause was pressed.
+	EventResume                 // Resume was pressed.
+	EventStop                   // Stop was pressed.
+	EventFinish                 // The run ended.
+)
+
+// String returns the name of the event.
+func (e RunEvent) String() string {
+	switch e {
+	case EventStart:
+		return "start"
+	case EventPause:
+		return "pause"
+	case EventResume:
+		return "resume"
+	case EventStop:
+		return "stop"
+	case EventFinish:
+		return "finish"
+	default:
+		return fmt.Sprintf("RunEvent(%d)", int(e))
+	}
+}
+
+// runTransitions lists the state reached by every event allowed in a state.
+var runTransitions = map[RunState]map[RunEvent]RunState{
+	StateIdle: {
+		EventStart: StateSending,
+	},
+	StateSending: {
+		EventPause:  StatePaused,
+		EventStop:   StateStopping,
+		EventFinish: StateIdle,
+	},
+	StatePaused: {
+		EventResume: StateSending,
+		EventStop:   StateStopping,
+		EventFinish: StateIdle,
+	},
+	StateStopping: {
+		EventFinish: StateIdle,
+	},
+}
+
+// Next returns the state reached by e, or an error when e is not allowed in
+// s.
+func (s RunState) Next(e RunEvent) (RunState, error) {
+	next, ok := runTransitions[s][e]
+	if !ok {
+		return s, fmt.Errorf("cannot %s while %s", e, s)
+	}
+
+	return next, nil
+}
+
+// Controls reports which run controls are enabled in a state.
+type Controls struct {
+	Send   bool
+	Pause  bool
+	Resume bool
+	Stop   bool
+}
+
+// Controls returns the controls enabled in s. A control is enabled exactly
+// when its event is allowed.
+func (s RunState) Controls() Controls {
+	allowed := func(e RunEvent) bool {
+		_, err := s.Next(e)
+		return err == nil
+	}
+
+	return Controls{
+		Send:   allowed(EventStart),
+		Pause:  allowed(EventPause),
+		Resume: allowed(EventResume),
+		Stop:   allowed(EventStop),
+	}
+}
@@ -0,0 +1,66 @@
+// nolint:all // test package
+package sender
+
+import "testing"
+
+func TestRunState_Next(t *testing.T) {
+	tests := []struct {
+		from    RunState
+		event   RunEvent
+		want    RunState
+		wantErr bool
+	}{
+		{StateIdle, EventStart, StateSending, false},
+		{StateIdle, EventPause, StateIdle, true},
+		{StateIdle, EventResume, StateIdle, true},
+		{StateIdle, EventStop, StateIdle, true},
+		{StateIdle, EventFinish, StateIdle, true},
+		{StateSending, EventStart, StateSending, true},
+		{StateSending, EventPause, StatePaused, false},
+		{StateSending, EventResume, StateSending, true},
+		{StateSending, EventStop, StateStopping, false},
+		{StateSending, EventFinish, StateIdle, false},
+		{StatePaused, EventStart, StatePaused, true},
+		{StatePaused, EventPause, StatePaused, true},
+		{StatePaused, EventResume, StateSending, false},
+		{StatePaused, EventStop, StateStopping, false},
+		{StatePaused, EventFinish, StateIdle, false},
+		{StateStopping, EventStart, StateStopping, true},
+		{StateStopping, EventPause, StateStopping, true},
+		{StateStopping, EventResume, StateStopping, true},
+		{StateStopping, EventStop, StateStopping, true},
+		{StateStopping, EventFinish, StateIdle, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from.String()+"_"+tt.event.String(), func(t *testing.T) {
+			got, err := tt.from.Next(tt.event)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Next() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunState_Controls(t *testing.T) {
+	tests := []struct {
+		state RunState
+		want  Controls
+	}{
+		{StateIdle, Controls{Send: true}},
+		{StateSending, Controls{Pause: true, Stop: true}},
+		{StatePaused, Controls{Resume: true, Stop: true}},
+		{StateStopping, Controls{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state.String(), func(t *testing.T) {
+			if got := tt.state.Controls(); got != tt.want {
+				t.Errorf("Controls() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
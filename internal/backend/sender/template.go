@@ -0,0 +1,148 @@
+package sender
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Template placeholders expanded before every request:
+//
+//	{{counter}}          request number, optionally zero padded: {{counter:6}}
+//	{{random:N}}         N random hex digits
+//	{{timestamp:LAYOUT}} current time in a Go time layout, 20060102150405 by default
+//	{{key:NAME}}         value of a stored key
+const (
+	placeholderOpen  = "{{"
+	placeholderClose = "}}"
+
+	defaultTimestampLayout = "20060102150405"
+	maxRandomDigits        = 64
+)
+
+// KeyLookup returns the value of the stored key name.
+type KeyLookup func(name string) (string, error)
+
+// templatePart is a literal or a placeholder of a template.
+type templatePart struct {
+	literal string
+	kind    string // Placeholder kind, empty for literals.
+	arg     string
+	width   int
+}
+
+// Template is a parsed command with placeholders.
+type Template struct {
+	parts []templatePart
+}
+
+// ParseTemplate parses a command containing placeholders. Keys are resolved
+// with keys, which may be nil when no key placeholder is used. All errors are
+// reported here so a run can be rejected before anything is sent.
+func ParseTemplate(s string, keys KeyLookup) (*Template, error) {
+	t := &Template{}
+	for s != "" {
+		start := strings.Index(s, placeholderOpen)
+		if start < 0 {
+			t.parts = append(t.parts, templatePart{literal: s})
+			break
+		}
+		if start > 0 {
+			t.parts = append(t.parts, templatePart{literal: s[:start]})
+		}
+		end := strings.Index(s[start:], placeholderClose)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder at offset %d", start)
+		}
+		part, err := parsePlaceholder(s[start+len(placeholderOpen):start+end], keys)
+		if err != nil {
+			return nil, err
+		}
+		t.parts = append(t.parts, part)
+		s = s[start+end+len(placeholderClose):]
+	}
+
+	return t, nil
+}
+
+// parsePlaceholder parses the text between the placeholder braces.
+func parsePlaceholder(p string, keys KeyLookup) (templatePart, error) {
+	kind, arg, hasArg := strings.Cut(strings.TrimSpace(p), ":")
+	part := templatePart{kind: kind, arg: arg}
+
+	switch kind {
+	case "counter":
+		if hasArg {
+			width, err := strconv.Atoi(arg)
+			if err != nil || width < 1 {
+				return part, fmt.Errorf("invalid counter width %q", arg)
+			}
+			part.width = width
+		}
+	case "random":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > maxRandomDigits {
+			return part, fmt.Errorf("invalid random length %q (1-%d hex digits)", arg, maxRandomDigits)
+		}
+		part.width = n
+	case "timestamp":
+		if part.arg == "" {
+			part.arg = defaultTimestampLayout
+		}
+	case "key":
+		if arg == "" {
+			return part, errors.New("key placeholder needs a key name")
+		}
+		if keys == nil {
+			return part, fmt.Errorf("key %q: no key store available", arg)
+		}
+		value, err := keys(arg)
+		if err != nil {
+			return part, fmt.Errorf("key %q: %v", arg, err)
+		}
+		// Stored keys are constant for the run.
+		return templatePart{literal: value}, nil
+	default:
+		return part, fmt.Errorf("unknown placeholder {{%s}}", p)
+	}
+
+	return part, nil
+}
+
+// Static reports whether the template expands to the same command every time.
+func (t *Template) Static() bool {
+	for _, p := range t.parts {
+		if p.kind != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Expand returns the command for request number counter sent at now.
+func (t *Template) Expand(counter int, now time.Time) ([]byte, error) {
+	var b strings.Builder
+	for _, p := range t.parts {
+		switch p.kind {
+		case "":
+			b.WriteString(p.literal)
+		case "counter":
+			fmt.Fprintf(&b, "%0*d", p.width, counter)
+		case "random":
+			buf := make([]byte, (p.width+1)/2)
+			if _, err := rand.Read(buf); err != nil {
+				return nil, fmt.Errorf("failed to generate random digits: %v", err)
+			}
+			b.WriteString(strings.ToUpper(hex.EncodeToString(buf))[:p.width])
+		case "timestamp":
+			b.WriteString(now.Format(p.arg))
+		}
+	}
+
+	return []byte(b.String()), nil
+}
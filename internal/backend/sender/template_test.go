@@ -0,0 +1,116 @@
+// nolint:all // test package
+package sender
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func testKeys(name string) (string, error) {
+	if name == "zmk" {
+		return "U0123456789ABCDEFFEDCBA9876543210", nil
+	}
+
+	return "", errors.New("not found")
+}
+
+func TestParseTemplate(t *testing.T) {
+	at := time.Date(2026, 5, 1, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		input   string
+		counter int
+		want    string
+		static  bool
+		wantErr bool
+	}{
+		{name: "plain", input: "NC", want: "NC", static: true},
+		{name: "counter", input: "0000{{counter}}NC", counter: 42, want: "000042NC"},
+		{name: "padded_counter", input: "{{counter:6}}", counter: 42, want: "000042"},
+		{name: "timestamp_default", input: "T{{timestamp}}", want: "T20260501103015"},
+		{name: "timestamp_layout", input: "{{timestamp:0102}}", want: "0501"},
+		{name: "key", input: "A0{{key:zmk}}", want: "A0U0123456789ABCDEFFEDCBA9876543210", static: true},
+		{name: "spaces", input: "{{ counter }}", counter: 7, want: "7"},
+		{name: "unknown_placeholder", input: "{{nonce}}", wantErr: true},
+		{name: "unterminated", input: "A0{{counter", wantErr: true},
+		{name: "bad_counter_width", input: "{{counter:x}}", wantErr: true},
+		{name: "bad_random_length", input: "{{random:0}}", wantErr: true},
+		{name: "too_long_random", input: "{{random:65}}", wantErr: true},
+		{name: "unknown_key", input: "{{key:zpk}}", wantErr: true},
+		{name: "empty_key_name", input: "{{key:}}", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.input, testKeys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tmpl.Static() != tt.static {
+				t.Errorf("Static() = %v, want %v", tmpl.Static(), tt.static)
+			}
+			got, err := tmpl.Expand(tt.counter, at)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplate_NoKeyStore(t *testing.T) {
+	if _, err := ParseTemplate("{{key:zmk}}", nil); err == nil {
+		t.Error("ParseTemplate() without key lookup expected error")
+	}
+}
+
+func TestTemplate_Random(t *testing.T) {
+	tmpl, err := ParseTemplate("RC{{random:5}}", nil)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	re := regexp.MustCompile(`^RC[0-9A-F]{5}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 20; i++ {
+		got, _ := tmpl.Expand(i, time.Now())
+		if !re.Match(got) {
+			t.Fatalf("Expand() = %q, want RC and 5 hex digits", got)
+		}
+		seen[string(got)] = true
+	}
+	if len(seen) < 2 {
+		t.Error("random placeholder produced identical values")
+	}
+}
+
+func TestRun_Template(t *testing.T) {
+	tmpl, err := ParseTemplate("{{counter:6}}NC", nil)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+	exec := newMockExecutor(0)
+	onResult, results := collect()
+
+	Run(make(chan struct{}), exec, Config{Template: tmpl, Count: 5, Workers: 1}, onResult)
+
+	want := []string{"000001NC", "000002NC", "000003NC", "000004NC", "000005NC"}
+	if len(exec.commands) != len(want) {
+		t.Fatalf("sent %d commands, want %d", len(exec.commands), len(want))
+	}
+	for i, cmd := range exec.commands {
+		if cmd != want[i] {
+			t.Errorf("command %d = %q, want %q", i, cmd, want[i])
+		}
+		if r := results()[i]; string(r.Request) != want[i] {
+			t.Errorf("result %d request = %q, want the expanded command", i, r.Request)
+		}
+	}
+}
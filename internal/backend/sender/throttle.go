@@ -0,0 +1,41 @@
+package sender
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultRefreshInterval limits UI refreshes to ten per second.
+const DefaultRefreshInterval = 100 * time.Millisecond
+
+// Throttle calls flush every interval until the returned function is called.
+// That function stops the ticker and calls flush a final time; later calls do
+// nothing. It lets fast producers accumulate state and publish it at a fixed
+// rate instead of once per event.
+func Throttle(interval time.Duration, flush func()) func() {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-t.C:
+				flush()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+			flush()
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// nolint:all // test package
+package sender
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottle(t *testing.T) {
+	var flushes atomic.Int64
+	stop := Throttle(20*time.Millisecond, func() { flushes.Add(1) })
+	time.Sleep(110 * time.Millisecond)
+	stop()
+	stop()
+
+	// About five ticks plus the final flush.
+	if n := flushes.Load(); n < 3 || n > 8 {
+		t.Errorf("flushed %d times, want about 6", n)
+	}
+	after := flushes.Load()
+	time.Sleep(50 * time.Millisecond)
+	if flushes.Load() != after {
+		t.Error("flushed after stop")
+	}
+}
+
+func TestThrottle_Run(t *testing.T) {
+	const requests = 10_000
+	exec := newMockExecutor(0)
+	var completed, published, flushes atomic.Int64
+
+	stop := Throttle(DefaultRefreshInterval, func() {
+		flushes.Add(1)
+		published.Store(completed.Load())
+	})
+	Run(make(chan struct{}), exec, Config{
+		Command: []byte("NC"),
+		Count:   requests,
+		Workers: 4,
+		Timeout: time.Second,
+	}, func(Result) { completed.Add(1) })
+	stop()
+
+	if published.Load() != requests {
+		t.Errorf("final flush published %d, want %d", published.Load(), requests)
+	}
+	// Updating per response would take 10,000 UI calls.
+	if n := flushes.Load(); n > requests/100 {
+		t.Errorf("flushed %d times for %d responses", n, requests)
+	}
+	t.Logf("%d flushes for %d responses", flushes.Load(), requests)
+}
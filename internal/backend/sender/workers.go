@@ -0,0 +1,74 @@
+package sender
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// MaxWorkersPerConnection bounds the number of concurrent workers relative to
+// the connection pool capacity.
+const MaxWorkersPerConnection = 4
+
+// ParseWorkers parses the number of concurrent workers. An empty value means
+// one worker per pooled connection. More than MaxWorkersPerConnection workers
+// per connection are rejected.
+func ParseWorkers(s string, poolCapacity int) (int, error) {
+	poolCapacity = max(poolCapacity, 1)
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return poolCapacity, nil
+	}
+	limit := MaxWorkersPerConnection * poolCapacity
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > limit {
+		return 0, fmt.Errorf("workers must be a number from 1 to %d", limit)
+	}
+
+	return n, nil
+}
+
+// WorkerStat holds the counters of one sending goroutine of a run.
+type WorkerStat struct {
+	Worker   int // 1-based worker number.
+	Requests int // Completed requests after the warm-up.
+	Errors   int // Requests that failed without a response.
+	Latency  time.Duration
+}
+
+// AvgLatency returns the average latency of the worker's requests.
+func (w WorkerStat) AvgLatency() time.Duration {
+	if w.Requests == 0 {
+		return 0
+	}
+
+	return w.Latency / time.Duration(w.Requests)
+}
+
+// record counts a completed request.
+func (w *WorkerStat) record(r Result) {
+	w.Requests++
+	w.Latency += r.Latency
+	if r.Err != nil {
+		w.Errors++
+	}
+}
+
+// WorkerStats holds the counters of every worker of a run.
+type WorkerStats []WorkerStat
+
+// String renders the counters as a table.
+func (ws WorkerStats) String() string {
+	var b strings.Builder
+	b.WriteString("Per-worker statistics:\n")
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Worker\tRequests\tErrors\tAvg ms")
+	for _, w := range ws {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\n", w.Worker, w.Requests, w.Errors, ms(w.AvgLatency()))
+	}
+	tw.Flush()
+
+	return b.String()
+}
@@ -0,0 +1,144 @@
+// nolint:all // test package
+package sender
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingExecutor holds every request until gate is released and records
+// how many requests were in flight at once.
+type countingExecutor struct {
+	gate     chan struct{}
+	release  sync.Once
+	want     int64
+	inFlight atomic.Int64
+	peak     atomic.Int64
+}
+
+func (c *countingExecutor) ExecuteCommandContext(ctx context.Context, command []byte) ([]byte, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for p := c.peak.Load(); n > p && !c.peak.CompareAndSwap(p, n); p = c.peak.Load() {
+	}
+	if n >= c.want {
+		c.release.Do(func() { close(c.gate) })
+	}
+	select {
+	case <-c.gate:
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	return []byte("ND00"), nil
+}
+
+func (c *countingExecutor) Connected() bool {
+	return true
+}
+
+func TestRun_WorkerCount(t *testing.T) {
+	// The configured number of workers send at once, never more.
+	for _, workers := range []int{1, 2, 5, 8} {
+		exec := &countingExecutor{gate: make(chan struct{}), want: int64(workers)}
+
+		sum := Run(make(chan struct{}), exec, Config{Command: []byte("NC"), Count: 3 * workers, Workers: workers},
+			func(Result) {})
+
+		if got := exec.peak.Load(); got != int64(workers) {
+			t.Errorf("Workers %d: peak concurrent requests = %d", workers, got)
+		}
+		if len(sum.Workers) != workers || sum.Sent != 3*workers {
+			t.Errorf("Workers %d: %d worker stats, %d sent", workers, len(sum.Workers), sum.Sent)
+		}
+	}
+}
+
+func TestParseWorkers(t *testing.T) {
+	tests := []struct {
+		in      string
+		pool    int
+		want    int
+		wantErr bool
+	}{
+		{"", 5, 5, false},
+		{"", 0, 1, false},
+		{"2", 5, 2, false},
+		{" 20 ", 5, 20, false},
+		{"21", 5, 0, true},
+		{"0", 5, 0, true},
+		{"two", 5, 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseWorkers(tt.in, tt.pool)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseWorkers(%q, %d) = %d, %v, want %d, error %v", tt.in, tt.pool, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRun_WorkerStats(t *testing.T) {
+	// The first call is far slower than the rest, so only the worker that
+	// handled it shows a high average.
+	exec := newMockExecutor(time.Millisecond)
+	exec.slowCalls = 1
+	exec.slowDelay = 100 * time.Millisecond
+
+	sum := Run(make(chan struct{}), exec, Config{Command: []byte("NC"), Count: 10, Workers: 2}, func(Result) {})
+
+	if len(sum.Workers) != 2 {
+		t.Fatalf("len(Workers) = %d, want 2", len(sum.Workers))
+	}
+	requests, slow := 0, 0
+	for i, w := range sum.Workers {
+		if w.Worker != i+1 {
+			t.Errorf("Workers[%d].Worker = %d, want %d", i, w.Worker, i+1)
+		}
+		requests += w.Requests
+		if w.AvgLatency() >= 20*time.Millisecond {
+			slow++
+		}
+	}
+	if requests != 10 {
+		t.Errorf("requests across workers = %d, want 10", requests)
+	}
+	if slow != 1 {
+		t.Errorf("%d workers with a high average, want 1: %+v", slow, sum.Workers)
+	}
+}
+
+func TestRun_WorkerStatsErrors(t *testing.T) {
+	exec := newMockExecutor(0)
+	exec.err = errors.New("invalid response")
+	exec.failAfter = 5
+
+	sum := Run(make(chan struct{}), exec, Config{Command: []byte("NC"), Count: 12, Workers: 3}, func(Result) {})
+
+	requests, errs := 0, 0
+	for _, w := range sum.Workers {
+		requests += w.Requests
+		errs += w.Errors
+	}
+	// Calls 5 to 12 fail.
+	if requests != 12 || errs != 8 {
+		t.Errorf("requests = %d, errors = %d, want 12 and 8", requests, errs)
+	}
+}
+
+func TestWorkerStats_String(t *testing.T) {
+	ws := WorkerStats{
+		{Worker: 1, Requests: 4, Errors: 1, Latency: 10 * time.Millisecond},
+		{Worker: 2},
+	}
+	table := ws.String()
+	for _, want := range []string{"Worker  Requests  Errors  Avg ms", "1       4         1       2.5", "2       0         0       0.0"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("String() missing %q:\n%s", want, table)
+		}
+	}
+}
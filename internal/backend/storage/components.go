@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// ListWithValues returns the active entries that carry a clear key value,
+// sorted by name. Cryptograms and values of unknown kind are left out.
+func (ks *KeyStore) ListWithValues() []KeyEntry {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	entries := make([]KeyEntry, 0, len(ks.keys))
+	for _, entry := range ks.keys {
+		if entry.Value != "" && entry.ValueKind == ValueClear && entry.Status() == StatusActive {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return entries
+}
+
+// ComponentEntries maps key components to entries named after baseName with
+// a _comp1.._compN suffix. The type is copied from source and checkValues
+// holds the KCV of each component. The clear components are only kept as
+// values when withValues is set; otherwise the entries hold metadata only.
+func ComponentEntries(
+	baseName string,
+	source KeyEntry,
+	components, checkValues []string,
+	withValues bool,
+) ([]KeyEntry, error) {
+	if baseName == "" {
+		return nil, errors.New("key name cannot be empty")
+	}
+	if len(components) == 0 {
+		return nil, errors.New("no components to store")
+	}
+	if len(checkValues) != len(components) {
+		return nil, fmt.Errorf(
+			"got %d check values for %d components",
+			len(checkValues),
+			len(components),
+		)
+	}
+
+	entries := make([]KeyEntry, len(components))
+	for i, c := range components {
+		if c == "" {
+			return nil, fmt.Errorf("component %d is empty", i+1)
+		}
+		name := ComponentName(baseName, i+1)
+		if err := utils.ValidateKeyName(name); err != nil {
+			return nil, fmt.Errorf("component %d: %v", i+1, err)
+		}
+		entries[i] = KeyEntry{
+			Name:       name,
+			Type:       source.Type,
+			Length:     len(c) / 2,
+			CheckValue: strings.ToUpper(checkValues[i]),
+		}
+		if withValues {
+			entries[i].Value = strings.ToUpper(c)
+			entries[i].ValueKind = ValueClear
+		}
+	}
+
+	return entries, nil
+}
+
+// ComponentName returns the entry name of component n of baseName.
+func ComponentName(baseName string, n int) string {
+	return fmt.Sprintf("%s_comp%d", baseName, n)
+}
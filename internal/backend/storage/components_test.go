@@ -0,0 +1,127 @@
+// nolint:all // test package
+package storage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+func TestKeyStore_ListWithValues(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+
+	for _, e := range []KeyEntry{
+		{Name: "zmk_b", Type: ZMK, Length: 16, CheckValue: "08D7B4", Value: "0123456789ABCDEFFEDCBA9876543210", ValueKind: ValueClear},
+		{Name: "zpk_meta", Type: ZPK, Length: 16, CheckValue: "000000"},
+		{Name: "zmk_a", Type: ZMK, Length: 8, CheckValue: "D5D44F", Value: "0123456789ABCDEF", ValueKind: ValueClear},
+		{Name: "zmk_old", Type: ZMK, Length: 8, Value: "0123456789ABCDEF", ValueKind: ValueClear, TrashedAt: time.Now()},
+		{Name: "zmk_lmk", Type: ZMK, Length: 16, Value: "U0123456789ABCDEFFEDCBA9876543210", ValueKind: ValueCryptogram},
+		{Name: "zmk_unknown", Type: ZMK, Length: 8, Value: "0123456789ABCDEF"},
+	} {
+		if err := ks.Store(e); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+
+	got := ks.ListWithValues()
+	names := make([]string, len(got))
+	for i, e := range got {
+		names[i] = e.Name
+	}
+	if want := []string{"zmk_a", "zmk_b"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListWithValues() names = %v, want %v", names, want)
+	}
+}
+
+func TestComponentEntries(t *testing.T) {
+	source := KeyEntry{Name: "zmk", Type: ZMK, Length: 16, CheckValue: "08D7B4"}
+
+	tests := []struct {
+		name        string
+		baseName    string
+		components  []string
+		checkValues []string
+		withValues  bool
+		want        []KeyEntry
+		wantErr     bool
+	}{
+		{
+			name:        "metadata_only",
+			baseName:    "zmk",
+			components:  []string{"0123456789abcdef", "fedcba9876543210"},
+			checkValues: []string{"d5d44f", "A1B2C3"},
+			want: []KeyEntry{
+				{Name: "zmk_comp1", Type: ZMK, Length: 8, CheckValue: "D5D44F"},
+				{Name: "zmk_comp2", Type: ZMK, Length: 8, CheckValue: "A1B2C3"},
+			},
+		},
+		{
+			name:        "clear_values",
+			baseName:    "zmk",
+			components:  []string{"0123456789abcdef", "fedcba9876543210"},
+			checkValues: []string{"d5d44f", "A1B2C3"},
+			withValues:  true,
+			want: []KeyEntry{
+				{Name: "zmk_comp1", Type: ZMK, Length: 8, CheckValue: "D5D44F", Value: "0123456789ABCDEF", ValueKind: ValueClear},
+				{Name: "zmk_comp2", Type: ZMK, Length: 8, CheckValue: "A1B2C3", Value: "FEDCBA9876543210", ValueKind: ValueClear},
+			},
+		},
+		{
+			name:        "suffixed_name_too_long",
+			baseName:    strings.Repeat("k", utils.MaxKeyNameLength-5),
+			components:  []string{"0123456789ABCDEF"},
+			checkValues: []string{"D5D44F"},
+			wantErr:     true,
+		},
+		{
+			name:        "longest_base_name",
+			baseName:    strings.Repeat("k", utils.MaxKeyNameLength-6),
+			components:  []string{"0123456789ABCDEF"},
+			checkValues: []string{"D5D44F"},
+			want: []KeyEntry{
+				{Name: strings.Repeat("k", utils.MaxKeyNameLength-6) + "_comp1", Type: ZMK, Length: 8, CheckValue: "D5D44F"},
+			},
+		},
+		{
+			name:        "empty_name",
+			baseName:    "",
+			components:  []string{"0123456789ABCDEF"},
+			checkValues: []string{"D5D44F"},
+			wantErr:     true,
+		},
+		{
+			name:        "check_value_count_mismatch",
+			baseName:    "zmk",
+			components:  []string{"0123456789ABCDEF", "FEDCBA9876543210"},
+			checkValues: []string{"D5D44F"},
+			wantErr:     true,
+		},
+		{
+			name:        "empty_component",
+			baseName:    "zmk",
+			components:  []string{"0123456789ABCDEF", ""},
+			checkValues: []string{"D5D44F", ""},
+			wantErr:     true,
+		},
+		{
+			name:     "no_components",
+			baseName: "zmk",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComponentEntries(tt.baseName, source, tt.components, tt.checkValues, tt.withValues)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ComponentEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ComponentEntries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
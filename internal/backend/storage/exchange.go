@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// keyExport is the file format of exported key entries.
+type keyExport struct {
+	Keys []KeyEntry `json:"keys"`
+}
+
+// ExportKeys writes entries to w as JSON. Key values are left out unless
+// withValues is set.
+func ExportKeys(w io.Writer, entries []KeyEntry, withValues bool) error {
+	out := keyExport{Keys: make([]KeyEntry, len(entries))}
+	for i, entry := range entries {
+		if !withValues {
+			entry.Value = ""
+		}
+		out.Keys[i] = entry
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to write keys: %v", err)
+	}
+
+	return nil
+}
+
+// ParseKeys reads entries written by ExportKeys. Every entry must have a
+// valid, unique name.
+func ParseKeys(r io.Reader) ([]KeyEntry, error) {
+	var in keyExport
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&in); err != nil {
+		return nil, fmt.Errorf("invalid key file: %v", err)
+	}
+
+	seen := make(map[string]bool, len(in.Keys))
+	for i, entry := range in.Keys {
+		if err := utils.ValidateKeyName(entry.Name); err != nil {
+			return nil, fmt.Errorf("key %d: %v", i+1, err)
+		}
+		if seen[entry.Name] {
+			return nil, fmt.Errorf("key %s appears more than once", entry.Name)
+		}
+		seen[entry.Name] = true
+	}
+
+	return in.Keys, nil
+}
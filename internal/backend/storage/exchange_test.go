@@ -0,0 +1,81 @@
+// nolint:all // test package
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestExportParseKeys(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []KeyEntry{
+		{Name: "zmk", Type: ZMK, Length: 16, CheckValue: "08D7B4", CreatedAt: created, Value: "U0123", Tags: []string{"prod"}},
+		{Name: "old", Type: ZPK, Length: 16, CreatedAt: created, TrashedAt: created.Add(time.Hour)},
+	}
+
+	tests := []struct {
+		name       string
+		withValues bool
+		wantValue  string
+	}{
+		{"metadata_only", false, ""},
+		{"with_values", true, "U0123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportKeys(&buf, entries, tt.withValues); err != nil {
+				t.Fatalf("ExportKeys() error = %v", err)
+			}
+			got, err := ParseKeys(&buf)
+			if err != nil {
+				t.Fatalf("ParseKeys() error = %v", err)
+			}
+
+			want := append([]KeyEntry{}, entries...)
+			want[0].Value = tt.wantValue
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	if entries[0].Value != "U0123" {
+		t.Error("ExportKeys() modified its input")
+	}
+	if err := ExportKeys(failingWriter{}, entries, false); err == nil {
+		t.Error("ExportKeys() to a failing writer succeeded")
+	}
+}
+
+func TestParseKeys_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"not_json", "keys", "invalid key file"},
+		{"unknown_field", `{"profiles": []}`, "invalid key file"},
+		{"missing_name", `{"keys": [{"type": "ZMK"}]}`, "key 1"},
+		{"bad_name", `{"keys": [{"name": "a b"}]}`, "key 1"},
+		{"duplicate", `{"keys": [{"name": "a"}, {"name": "a"}]}`, "more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseKeys(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseKeys() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// GeneratedKeyEntry maps a key generated by the A0 command, or imported by
+// the A6 command, to an entry named name. The cryptogram is kept as the
+// value, marked as such so it is never taken for a clear key, together with
+// the check value of the LMK it is encrypted under when lmkCheckValue is
+// known.
+func GeneratedKeyEntry(
+	name string,
+	keyType KeyType,
+	key utils.A0Response,
+	lmkCheckValue string,
+) (KeyEntry, error) {
+	if err := utils.ValidateKeyName(name); err != nil {
+		return KeyEntry{}, err
+	}
+	if key.Cryptogram == "" {
+		return KeyEntry{}, errors.New("no generated key to store")
+	}
+
+	return KeyEntry{
+		Name:          name,
+		Type:          keyType,
+		Length:        key.Length(),
+		CheckValue:    strings.ToUpper(key.CheckValue),
+		Value:         key.Cryptogram,
+		ValueKind:     ValueCryptogram,
+		LMKCheckValue: strings.ToUpper(lmkCheckValue),
+	}, nil
+}
+
+// SameCheckValue returns the names of the stored entries, other than the one
+// named except, whose check value is kcv, sorted.
+func (ks *KeyStore) SameCheckValue(kcv, except string) []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var names []string
+	for _, entry := range ks.keys {
+		if entry.Name != except && kcv != "" && strings.EqualFold(entry.CheckValue, kcv) {
+			names = append(names, entry.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
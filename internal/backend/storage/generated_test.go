@@ -0,0 +1,112 @@
+// nolint:all // test package
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+func TestGeneratedKeyEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyName string
+		keyType KeyType
+		key     utils.A0Response
+		lmk     string
+		want    KeyEntry
+		wantErr bool
+	}{
+		{
+			"double_length",
+			"zmk_prod",
+			ZMK,
+			utils.A0Response{ErrorCode: "00", Scheme: 'U', Cryptogram: "U0123456789ABCDEF0123456789ABCDEF", CheckValue: "08d7b4"},
+			"7b44ac1ddee2a94b",
+			KeyEntry{
+				Name:          "zmk_prod",
+				Type:          ZMK,
+				Length:        16,
+				CheckValue:    "08D7B4",
+				Value:         "U0123456789ABCDEF0123456789ABCDEF",
+				ValueKind:     ValueCryptogram,
+				LMKCheckValue: "7B44AC1DDEE2A94B",
+			},
+			false,
+		},
+		{
+			"triple_length_lmk_unknown",
+			"tpk-1",
+			KeyType("TPK"),
+			utils.A0Response{ErrorCode: "00", Scheme: 'T', Cryptogram: "T0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF", CheckValue: "D5D44F"},
+			"",
+			KeyEntry{
+				Name:       "tpk-1",
+				Type:       KeyType("TPK"),
+				Length:     24,
+				CheckValue: "D5D44F",
+				Value:      "T0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF",
+				ValueKind:  ValueCryptogram,
+			},
+			false,
+		},
+		{"invalid_name", "bad name", ZMK, utils.A0Response{Scheme: 'Z', Cryptogram: "0123456789ABCDEF"}, "", KeyEntry{}, true},
+		{"no_key", "zpk", ZPK, utils.A0Response{ErrorCode: "68", Scheme: 'U'}, "", KeyEntry{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeneratedKeyEntry(tt.keyName, tt.keyType, tt.key, tt.lmk)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratedKeyEntry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GeneratedKeyEntry() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratedKeyEntry_NotListedWithValues(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+
+	// The Bitwise Calculator loads clear keys from ListWithValues, so a
+	// cryptogram must never be listed there.
+	entry, err := GeneratedKeyEntry("zmk_lmk", ZMK, utils.A0Response{
+		ErrorCode: "00", Scheme: 'U', Cryptogram: "U0123456789ABCDEF0123456789ABCDEF", CheckValue: "08D7B4",
+	}, "")
+	if err != nil {
+		t.Fatalf("GeneratedKeyEntry() error = %v", err)
+	}
+	if err := ks.Store(entry); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if got := ks.ListWithValues(); len(got) != 0 {
+		t.Errorf("ListWithValues() = %+v, want no cryptograms", got)
+	}
+}
+
+func TestKeyStore_SameCheckValue(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+	for _, e := range []KeyEntry{
+		{Name: "b", CheckValue: "08D7B4"},
+		{Name: "a", CheckValue: "08d7b4"},
+		{Name: "c", CheckValue: "D5D44F"},
+		{Name: "meta"},
+	} {
+		if err := ks.Store(e); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+
+	if got := ks.SameCheckValue("08D7B4", ""); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("SameCheckValue() = %q, want [a b]", got)
+	}
+	if got := ks.SameCheckValue("08D7B4", "a"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("SameCheckValue() excluding a = %q, want [b]", got)
+	}
+	if got := ks.SameCheckValue("", ""); got != nil {
+		t.Errorf("SameCheckValue() of an empty kcv = %q, want none", got)
+	}
+}
@@ -26,6 +26,17 @@ const (
 // KeyType represents the type of cryptographic key.
 type KeyType string
 
+// ValueKind tells what the value of an entry holds.
+type ValueKind string
+
+const (
+	// ValueClear marks a clear key or component. Clear values are only
+	// stored after the user confirmed it.
+	ValueClear ValueKind = "clear"
+	// ValueCryptogram marks a key encrypted under an LMK.
+	ValueCryptogram ValueKind = "cryptogram"
+)
+
 // KeyEntry represents a stored key record.
 type KeyEntry struct {
 	Name       string    `json:"name"`
@@ -33,6 +44,37 @@ type KeyEntry struct {
 	Length     int       `json:"length"`
 	CheckValue string    `json:"check_value"`
 	CreatedAt  time.Time `json:"created_at"`
+	// Value is the key or component in hex; empty when only metadata is kept.
+	Value string `json:"value,omitempty"`
+	// ValueKind tells a clear Value from a cryptogram; empty when unknown.
+	ValueKind ValueKind `json:"value_kind,omitempty"`
+	// LMKCheckValue identifies the LMK a cryptogram in Value is encrypted
+	// under, when known.
+	LMKCheckValue string `json:"lmk_check_value,omitempty"`
+	// Tags are free-form labels for finding entries.
+	Tags []string `json:"tags,omitempty"`
+	// TrashedAt is when the entry was moved to the trash; zero while it is
+	// active.
+	TrashedAt time.Time `json:"trashed_at,omitzero"`
+}
+
+// KeyStatus tells active entries from those in the trash.
+type KeyStatus string
+
+const (
+	// StatusActive marks an entry in use.
+	StatusActive KeyStatus = "Active"
+	// StatusTrashed marks an entry in the trash.
+	StatusTrashed KeyStatus = "Trashed"
+)
+
+// Status returns whether the entry is active or in the trash.
+func (e KeyEntry) Status() KeyStatus {
+	if e.TrashedAt.IsZero() {
+		return StatusActive
+	}
+
+	return StatusTrashed
 }
 
 // KeyStore manages key storage.
@@ -40,6 +82,11 @@ type KeyStore struct {
 	mu       sync.RWMutex
 	keys     map[string]KeyEntry
 	filePath string
+
+	// Change listeners, called after every successful save.
+	listenMu  sync.Mutex
+	listeners map[int]func()
+	nextID    int
 }
 
 // NewKeyStore creates a new key store instance.
@@ -63,20 +110,41 @@ func NewKeyStore(storePath string) (*KeyStore, error) {
 
 // Store adds or updates a key entry.
 func (ks *KeyStore) Store(entry KeyEntry) error {
-	ks.mu.Lock()
-	defer ks.mu.Unlock()
+	return ks.update(func() error {
+		if entry.Name == "" {
+			return errors.New("key name cannot be empty")
+		}
 
-	if entry.Name == "" {
-		return errors.New("key name cannot be empty")
-	}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
 
-	if entry.CreatedAt.IsZero() {
-		entry.CreatedAt = time.Now()
-	}
+		ks.keys[entry.Name] = entry
+
+		return nil
+	})
+}
 
-	ks.keys[entry.Name] = entry
+// StoreAll adds or updates several entries with a single save. Nothing is
+// stored when an entry has no name.
+func (ks *KeyStore) StoreAll(entries []KeyEntry) error {
+	return ks.update(func() error {
+		for _, entry := range entries {
+			if entry.Name == "" {
+				return errors.New("key name cannot be empty")
+			}
+		}
 
-	return ks.save()
+		now := time.Now()
+		for _, entry := range entries {
+			if entry.CreatedAt.IsZero() {
+				entry.CreatedAt = now
+			}
+			ks.keys[entry.Name] = entry
+		}
+
+		return nil
+	})
 }
 
 // Get retrieves a key entry by name.
@@ -104,16 +172,105 @@ func (ks *KeyStore) List() []KeyEntry {
 
 // Delete removes a key entry.
 func (ks *KeyStore) Delete(name string) error {
-	ks.mu.Lock()
-	defer ks.mu.Unlock()
+	return ks.update(func() error {
+		if _, exists := ks.keys[name]; !exists {
+			return errors.New("key not found")
+		}
+
+		delete(ks.keys, name)
+
+		return nil
+	})
+}
 
-	if _, exists := ks.keys[name]; !exists {
-		return errors.New("key not found")
+// Trash moves a key entry to the trash, from which Restore brings it back.
+func (ks *KeyStore) Trash(name string) error {
+	return ks.update(func() error {
+		entry, exists := ks.keys[name]
+		if !exists {
+			return errors.New("key not found")
+		}
+		if entry.Status() == StatusTrashed {
+			return fmt.Errorf("key %s is already in the trash", name)
+		}
+
+		entry.TrashedAt = time.Now()
+		ks.keys[name] = entry
+
+		return nil
+	})
+}
+
+// Restore takes a key entry out of the trash.
+func (ks *KeyStore) Restore(name string) error {
+	return ks.update(func() error {
+		entry, exists := ks.keys[name]
+		if !exists {
+			return errors.New("key not found")
+		}
+		if entry.Status() != StatusTrashed {
+			return fmt.Errorf("key %s is not in the trash", name)
+		}
+
+		entry.TrashedAt = time.Time{}
+		ks.keys[name] = entry
+
+		return nil
+	})
+}
+
+// OnChange registers fn to be called after the stored entries change. It
+// is called on the goroutine that made the change, after the store is
+// unlocked. The returned function unregisters it.
+func (ks *KeyStore) OnChange(fn func()) func() {
+	ks.listenMu.Lock()
+	defer ks.listenMu.Unlock()
+
+	if ks.listeners == nil {
+		ks.listeners = make(map[int]func())
 	}
+	id := ks.nextID
+	ks.nextID++
+	ks.listeners[id] = fn
+
+	return func() {
+		ks.listenMu.Lock()
+		defer ks.listenMu.Unlock()
+		delete(ks.listeners, id)
+	}
+}
 
-	delete(ks.keys, name)
+// update applies fn to the entries and saves them, then notifies the
+// change listeners. Entries are restored when fn or the save fails.
+func (ks *KeyStore) update(fn func() error) error {
+	ks.mu.Lock()
+	backup := make(map[string]KeyEntry, len(ks.keys))
+	for name, entry := range ks.keys {
+		backup[name] = entry
+	}
+	err := fn()
+	if err == nil {
+		err = ks.save()
+	}
+	if err != nil {
+		ks.keys = backup
+	}
+	ks.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ks.listenMu.Lock()
+	listeners := make([]func(), 0, len(ks.listeners))
+	for _, l := range ks.listeners {
+		listeners = append(listeners, l)
+	}
+	ks.listenMu.Unlock()
+	for _, l := range listeners {
+		l()
+	}
 
-	return ks.save()
+	return nil
 }
 
 // load reads key entries from storage file.
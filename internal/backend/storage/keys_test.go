@@ -674,3 +674,79 @@ func TestKeyStore_Store(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyStore_TrashRestore(t *testing.T) {
+	ks, storePath := newTestKeyStore(t)
+	if err := ks.Store(KeyEntry{Name: "zmk", Type: ZMK, Length: 16}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	changes := 0
+	unregister := ks.OnChange(func() { changes++ })
+
+	if err := ks.Trash("zmk"); err != nil {
+		t.Fatalf("Trash() error = %v", err)
+	}
+	if e, _ := ks.Get("zmk"); e.Status() != StatusTrashed || e.TrashedAt.IsZero() {
+		t.Errorf("after Trash() status = %s, trashed at %v", e.Status(), e.TrashedAt)
+	}
+	if err := ks.Trash("zmk"); err == nil {
+		t.Error("Trash() of a trashed key succeeded")
+	}
+
+	// The trash survives a reload.
+	reloaded, err := NewKeyStore(storePath)
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+	if e, _ := reloaded.Get("zmk"); e.Status() != StatusTrashed {
+		t.Errorf("reloaded status = %s, want %s", e.Status(), StatusTrashed)
+	}
+
+	if err := ks.Restore("zmk"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if e, _ := ks.Get("zmk"); e.Status() != StatusActive {
+		t.Errorf("after Restore() status = %s", e.Status())
+	}
+	if err := ks.Restore("zmk"); err == nil {
+		t.Error("Restore() of an active key succeeded")
+	}
+	if err := ks.Restore("missing"); err == nil {
+		t.Error("Restore() of a missing key succeeded")
+	}
+
+	// Only successful changes notify.
+	if changes != 2 {
+		t.Errorf("OnChange called %d times, want 2", changes)
+	}
+	unregister()
+	if err := ks.Store(KeyEntry{Name: "zpk"}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if changes != 2 {
+		t.Errorf("OnChange called after unregistering")
+	}
+}
+
+func TestKeyStore_StoreAll(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+	ks.Store(KeyEntry{Name: "a", Type: ZMK})
+
+	if err := ks.StoreAll([]KeyEntry{{Name: "b"}, {Name: ""}}); err == nil {
+		t.Fatal("StoreAll() with a nameless entry succeeded")
+	}
+	if _, ok := ks.Get("b"); ok {
+		t.Error("StoreAll() stored entries despite the error")
+	}
+
+	if err := ks.StoreAll([]KeyEntry{{Name: "a", Type: ZPK}, {Name: "b"}}); err != nil {
+		t.Fatalf("StoreAll() error = %v", err)
+	}
+	if e, _ := ks.Get("a"); e.Type != ZPK {
+		t.Errorf("a type = %s, want %s", e.Type, ZPK)
+	}
+	if e, ok := ks.Get("b"); !ok || e.CreatedAt.IsZero() {
+		t.Errorf("b = %+v, want a stored entry with a creation time", e)
+	}
+}
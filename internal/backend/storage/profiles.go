@@ -0,0 +1,282 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// ErrProfileExists is returned when saving over a profile without permission
+// to overwrite it.
+var ErrProfileExists = errors.New("profile already exists")
+
+// ConnectionProfile is a named set of HSM connection settings.
+type ConnectionProfile struct {
+	Name        string `json:"name"`
+	Host        string `json:"host"`
+	Port        string `json:"port"`
+	LMKIndex    string `json:"lmk_index"`
+	Connections int    `json:"connections"`
+
+	// Zero or nil values leave the connection defaults in place.
+	CommandTimeoutMillis int  `json:"command_timeout_ms,omitempty"`
+	MaxReconnectAttempts *int `json:"max_reconnect_attempts,omitempty"` // 0 retries forever.
+	MaxBackoffSeconds    int  `json:"max_backoff_s,omitempty"`
+
+	// Pool tuning; zero keeps the connection defaults.
+	IdleTimeoutSeconds        int `json:"idle_timeout_s,omitempty"`
+	KeepAliveSeconds          int `json:"keepalive_s,omitempty"`
+	ValidationIntervalSeconds int `json:"validation_interval_s,omitempty"`
+	HealthCheckSeconds        int `json:"health_check_s,omitempty"` // 0 turns health checks off.
+
+	// TLS settings; the file paths are kept as entered.
+	UseTLS                bool   `json:"use_tls,omitempty"`
+	TLSCAFile             string `json:"tls_ca_file,omitempty"`
+	TLSCertFile           string `json:"tls_cert_file,omitempty"`
+	TLSKeyFile            string `json:"tls_key_file,omitempty"`
+	TLSServerName         string `json:"tls_server_name,omitempty"`
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify,omitempty"`
+}
+
+// profileFile is the on-disk layout of the profile store.
+type profileFile struct {
+	LastUsed string              `json:"last_used,omitempty"`
+	Profiles []ConnectionProfile `json:"profiles"`
+}
+
+// ProfileStore keeps connection profiles in a single JSON file, together
+// with the name of the last used one.
+type ProfileStore struct {
+	mu       sync.RWMutex
+	path     string
+	profiles map[string]ConnectionProfile
+	lastUsed string
+}
+
+// NewProfileStore opens the profile store at path. A file that cannot be
+// parsed is moved aside to path.corrupt and the store starts empty.
+func NewProfileStore(path string) (*ProfileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create profile directory: %v", err)
+	}
+
+	ps := &ProfileStore{path: path, profiles: make(map[string]ConnectionProfile)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %v", err)
+	}
+
+	var f profileFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		corrupt := path + ".corrupt"
+		if err := os.Rename(path, corrupt); err != nil {
+			return nil, fmt.Errorf("failed to move corrupt profiles aside: %v", err)
+		}
+		logger.Warn("profiles_load", "Recovered", fmt.Sprintf("unreadable profiles moved to %s: %v", corrupt, err))
+
+		return ps, nil
+	}
+	for _, p := range f.Profiles {
+		if ValidateProfileName(p.Name) == nil {
+			ps.profiles[p.Name] = p
+		}
+	}
+	if _, ok := ps.profiles[f.LastUsed]; ok {
+		ps.lastUsed = f.LastUsed
+	}
+
+	return ps, nil
+}
+
+// ValidateProfileName checks that name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if !scenarioNamePattern.MatchString(name) {
+		return errors.New("profile name must start with a letter or digit and " +
+			"contain only letters, digits, spaces, '.', '_' and '-'")
+	}
+
+	return nil
+}
+
+// List returns the names of the stored profiles, sorted.
+func (ps *ProfileStore) List() []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	names := make([]string, 0, len(ps.profiles))
+	for name := range ps.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the named profile.
+func (ps *ProfileStore) Get(name string) (ConnectionProfile, bool) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	p, ok := ps.profiles[name]
+
+	return p, ok
+}
+
+// Save stores the profile under its name and makes it the last used one. An
+// existing profile is only replaced when overwrite is set; otherwise
+// ErrProfileExists is returned.
+func (ps *ProfileStore) Save(p ConnectionProfile, overwrite bool) error {
+	if err := ValidateProfileName(p.Name); err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.profiles[p.Name]; ok && !overwrite {
+		return ErrProfileExists
+	}
+	prev, existed := ps.profiles[p.Name]
+	prevLastUsed := ps.lastUsed
+	ps.profiles[p.Name] = p
+	ps.lastUsed = p.Name
+	if err := ps.save(); err != nil {
+		if existed {
+			ps.profiles[p.Name] = prev
+		} else {
+			delete(ps.profiles, p.Name)
+		}
+		ps.lastUsed = prevLastUsed
+
+		return err
+	}
+
+	return nil
+}
+
+// SaveAll adds or replaces the profiles in a single write. Either all of
+// them are stored or none is. The last used profile does not change.
+func (ps *ProfileStore) SaveAll(profiles []ConnectionProfile) error {
+	for _, p := range profiles {
+		if err := ValidateProfileName(p.Name); err != nil {
+			return fmt.Errorf("profile %q: %v", p.Name, err)
+		}
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	prev := make(map[string]ConnectionProfile, len(ps.profiles))
+	for name, p := range ps.profiles {
+		prev[name] = p
+	}
+	for _, p := range profiles {
+		ps.profiles[p.Name] = p
+	}
+	if err := ps.save(); err != nil {
+		ps.profiles = prev
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the named profile.
+func (ps *ProfileStore) Delete(name string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.profiles[name]
+	if !ok {
+		return errors.New("profile not found")
+	}
+	prevLastUsed := ps.lastUsed
+	delete(ps.profiles, name)
+	if ps.lastUsed == name {
+		ps.lastUsed = ""
+	}
+	if err := ps.save(); err != nil {
+		ps.profiles[name] = p
+		ps.lastUsed = prevLastUsed
+
+		return err
+	}
+
+	return nil
+}
+
+// LastUsed returns the name of the last used profile, or "" when none is.
+func (ps *ProfileStore) LastUsed() string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	return ps.lastUsed
+}
+
+// SetLastUsed records name as the last used profile.
+func (ps *ProfileStore) SetLastUsed(name string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.profiles[name]; !ok {
+		return errors.New("profile not found")
+	}
+	if ps.lastUsed == name {
+		return nil
+	}
+	prev := ps.lastUsed
+	ps.lastUsed = name
+	if err := ps.save(); err != nil {
+		ps.lastUsed = prev
+		return err
+	}
+
+	return nil
+}
+
+// save writes the profiles to a temporary file and renames it over the store
+// file, so a failed write never leaves a truncated store behind.
+func (ps *ProfileStore) save() error {
+	f := profileFile{LastUsed: ps.lastUsed, Profiles: make([]ConnectionProfile, 0, len(ps.profiles))}
+	for _, p := range ps.profiles {
+		f.Profiles = append(f.Profiles, p)
+	}
+	sort.Slice(f.Profiles, func(i, j int) bool { return f.Profiles[i].Name < f.Profiles[j].Name })
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal profiles: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(ps.path), filepath.Base(ps.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed.
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), ps.path); err != nil {
+		return fmt.Errorf("failed to save profiles: %v", err)
+	}
+
+	return nil
+}
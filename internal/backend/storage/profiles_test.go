@@ -0,0 +1,176 @@
+// nolint:all // test package
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProfileStore_CRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hsmtool", "profiles.json")
+	ps, err := NewProfileStore(path)
+	if err != nil {
+		t.Fatalf("NewProfileStore() error = %v", err)
+	}
+	if names := ps.List(); len(names) != 0 || ps.LastUsed() != "" {
+		t.Fatalf("new store = %v, last used %q", names, ps.LastUsed())
+	}
+
+	dev := ConnectionProfile{Name: "dev sim", Host: "localhost", Port: "1500", LMKIndex: "00", Connections: 1}
+	uat := ConnectionProfile{Name: "UAT", Host: "10.0.0.5", Port: "1500", LMKIndex: "01", Connections: 4}
+	for _, p := range []ConnectionProfile{dev, uat} {
+		if err := ps.Save(p, false); err != nil {
+			t.Fatalf("Save(%q) error = %v", p.Name, err)
+		}
+	}
+	if err := ps.Save(dev, false); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("Save() existing error = %v, want ErrProfileExists", err)
+	}
+	if err := ps.Save(ConnectionProfile{Name: "../dr"}, true); err == nil {
+		t.Error("Save() accepted an invalid name")
+	}
+	dev.Connections = 2
+	dev.IdleTimeoutSeconds, dev.KeepAliveSeconds = 110, 20
+	if err := ps.Save(dev, true); err != nil {
+		t.Fatalf("Save() overwrite error = %v", err)
+	}
+	if got, want := ps.List(), []string{"UAT", "dev sim"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %v, want %v", got, want)
+	}
+	if ps.LastUsed() != "dev sim" {
+		t.Errorf("LastUsed() = %q, want the last saved profile", ps.LastUsed())
+	}
+	if err := ps.SetLastUsed("UAT"); err != nil {
+		t.Fatalf("SetLastUsed() error = %v", err)
+	}
+	if err := ps.SetLastUsed("missing"); err == nil {
+		t.Error("SetLastUsed() accepted a missing profile")
+	}
+
+	reopened, err := NewProfileStore(path)
+	if err != nil {
+		t.Fatalf("NewProfileStore() reopen error = %v", err)
+	}
+	if got, ok := reopened.Get("dev sim"); !ok || got != dev {
+		t.Errorf("Get() after reopen = %+v, %v, want %+v", got, ok, dev)
+	}
+	if reopened.LastUsed() != "UAT" {
+		t.Errorf("LastUsed() after reopen = %q, want UAT", reopened.LastUsed())
+	}
+
+	if err := reopened.Delete("UAT"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := reopened.Delete("UAT"); err == nil {
+		t.Error("Delete() of a missing profile succeeded")
+	}
+	if _, ok := reopened.Get("UAT"); ok || reopened.LastUsed() != "" {
+		t.Errorf("after Delete() Get ok = %v, last used %q", ok, reopened.LastUsed())
+	}
+}
+
+func TestProfileStore_CorruptFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantNames   []string
+		wantCorrupt bool
+	}{
+		{"garbage", "{not json", []string{}, true},
+		{"wrong_shape", `{"profiles": "dev"}`, []string{}, true},
+		{"bad_names_skipped", `{"last_used": "../x", "profiles": [{"name": "../x"}, {"name": "dev"}]}`, []string{"dev"}, false},
+		{"empty_object", `{}`, []string{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "profiles.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			ps, err := NewProfileStore(path)
+			if err != nil {
+				t.Fatalf("NewProfileStore() error = %v", err)
+			}
+			if got := ps.List(); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("List() = %v, want %v", got, tt.wantNames)
+			}
+			if ps.LastUsed() != "" {
+				t.Errorf("LastUsed() = %q, want none", ps.LastUsed())
+			}
+			data, err := os.ReadFile(path + ".corrupt")
+			if tt.wantCorrupt != (err == nil) {
+				t.Fatalf("corrupt copy present = %v, want %v", err == nil, tt.wantCorrupt)
+			}
+			if tt.wantCorrupt && string(data) != tt.content {
+				t.Errorf("corrupt copy = %q, want the original content", data)
+			}
+			if err := ps.Save(ConnectionProfile{Name: "dr"}, false); err != nil {
+				t.Errorf("Save() after recovery error = %v", err)
+			}
+		})
+	}
+}
+
+func TestProfileStore_AtomicSave(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	path := filepath.Join(dir, "profiles.json")
+	ps, err := NewProfileStore(path)
+	if err != nil {
+		t.Fatalf("NewProfileStore() error = %v", err)
+	}
+	dev := ConnectionProfile{Name: "dev", Host: "localhost", Port: "1500"}
+	if err := ps.Save(dev, false); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "profiles.json" {
+		t.Errorf("directory holds %v, want only profiles.json", entries)
+	}
+	before, _ := os.ReadFile(path)
+
+	// Make the final rename fail: the target becomes a non-empty directory.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(path, "x"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.Save(ConnectionProfile{Name: "uat"}, false); err == nil {
+		t.Fatal("Save() succeeded although the file could not be replaced")
+	}
+	if _, ok := ps.Get("uat"); ok || ps.LastUsed() != "dev" {
+		t.Errorf("failed Save() changed the store: uat present %v, last used %q", ok, ps.LastUsed())
+	}
+	if err := ps.Delete("dev"); err == nil {
+		t.Fatal("Delete() succeeded although the file could not be replaced")
+	}
+	if _, ok := ps.Get("dev"); !ok {
+		t.Error("failed Delete() removed the profile")
+	}
+	entries, _ = os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("failed saves left temporary files: %v", entries)
+	}
+
+	// Restore the file: the store writes out its unchanged state.
+	if err := os.RemoveAll(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ps.SetLastUsed("dev"); err != nil {
+		t.Fatalf("SetLastUsed() error = %v", err)
+	}
+	if err := ps.Save(dev, true); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if after, _ := os.ReadFile(path); string(after) != string(before) {
+		t.Errorf("store file = %s, want %s", after, before)
+	}
+}
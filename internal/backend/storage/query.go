@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+)
+
+// KeySort selects the field ListFiltered orders entries by.
+type KeySort int
+
+const (
+	// SortByName orders entries by name.
+	SortByName KeySort = iota
+	// SortByType orders entries by key type.
+	SortByType
+	// SortByLength orders entries by key length.
+	SortByLength
+	// SortByCheckValue orders entries by check value.
+	SortByCheckValue
+	// SortByCreated orders entries by creation time.
+	SortByCreated
+	// SortByStatus orders active entries before trashed ones.
+	SortByStatus
+)
+
+// KeyFilter selects and orders the entries returned by ListFiltered and
+// ListPage. The zero value lists every entry by name.
+type KeyFilter struct {
+	// Query matches the name, type, check value or a tag, ignoring case.
+	Query  string
+	Type   KeyType   // Any type when empty.
+	Tag    string    // Any tags when empty; matched ignoring case.
+	Status KeyStatus // Any status when empty.
+
+	Sort       KeySort
+	Descending bool
+}
+
+// Matches reports whether entry passes the filter.
+func (f KeyFilter) Matches(entry KeyEntry) bool {
+	if f.Type != "" && !strings.EqualFold(string(entry.Type), string(f.Type)) {
+		return false
+	}
+	if f.Status != "" && entry.Status() != f.Status {
+		return false
+	}
+	if f.Tag != "" && !hasTag(entry.Tags, f.Tag) {
+		return false
+	}
+
+	q := strings.ToLower(strings.TrimSpace(f.Query))
+	if q == "" {
+		return true
+	}
+	for _, s := range append([]string{entry.Name, string(entry.Type), entry.CheckValue}, entry.Tags...) {
+		if strings.Contains(strings.ToLower(s), q) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// less reports whether a sorts before b. Ties are broken by name.
+func (f KeyFilter) less(a, b KeyEntry) bool {
+	var c int
+	switch f.Sort {
+	case SortByType:
+		c = strings.Compare(string(a.Type), string(b.Type))
+	case SortByLength:
+		c = a.Length - b.Length
+	case SortByCheckValue:
+		c = strings.Compare(a.CheckValue, b.CheckValue)
+	case SortByCreated:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	case SortByStatus:
+		c = strings.Compare(string(a.Status()), string(b.Status()))
+	}
+	if c == 0 {
+		c = strings.Compare(a.Name, b.Name)
+	}
+	if f.Descending {
+		return c > 0
+	}
+
+	return c < 0
+}
+
+// ListFiltered returns the entries passing f, in its order.
+func (ks *KeyStore) ListFiltered(f KeyFilter) []KeyEntry {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	var entries []KeyEntry
+	for _, entry := range ks.keys {
+		if f.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return f.less(entries[i], entries[j]) })
+
+	return entries
+}
+
+// ListPage returns at most limit of the entries passing f, skipping the
+// first offset, and the number of entries passing f. A limit of zero or
+// less returns all entries after offset.
+func (ks *KeyStore) ListPage(f KeyFilter, offset, limit int) ([]KeyEntry, int) {
+	entries := ks.ListFiltered(f)
+	total := len(entries)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > total {
+		offset = total
+	}
+	entries = entries[offset:]
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	return entries, total
+}
+
+// Tags returns the distinct tags of all entries, sorted.
+func (ks *KeyStore) Tags() []string {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, entry := range ks.keys {
+		for _, t := range entry.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	sort.Strings(tags)
+
+	return tags
+}
+
+// hasTag reports whether tags holds tag, ignoring case.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,102 @@
+// nolint:all // test package
+package storage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKeyStore_ListFiltered(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	ks.StoreAll([]KeyEntry{
+		{Name: "zmk_prod", Type: ZMK, Length: 16, CheckValue: "08D7B4", CreatedAt: base, Tags: []string{"Prod"}},
+		{Name: "zpk_test", Type: ZPK, Length: 24, CheckValue: "A1B2C3", CreatedAt: base.Add(time.Hour), Tags: []string{"test"}},
+		{Name: "pvk", Type: PVK, Length: 8, CheckValue: "FFEE11", CreatedAt: base.Add(-time.Hour)},
+		{Name: "old_zmk", Type: ZMK, Length: 16, CheckValue: "123456", CreatedAt: base, TrashedAt: base},
+	})
+
+	names := func(entries []KeyEntry) []string {
+		out := make([]string, len(entries))
+		for i, e := range entries {
+			out[i] = e.Name
+		}
+
+		return out
+	}
+
+	tests := []struct {
+		name   string
+		filter KeyFilter
+		want   []string
+	}{
+		{"all_by_name", KeyFilter{}, []string{"old_zmk", "pvk", "zmk_prod", "zpk_test"}},
+		{"active", KeyFilter{Status: StatusActive}, []string{"pvk", "zmk_prod", "zpk_test"}},
+		{"trash", KeyFilter{Status: StatusTrashed}, []string{"old_zmk"}},
+		{"type", KeyFilter{Type: "zmk"}, []string{"old_zmk", "zmk_prod"}},
+		{"tag_ignores_case", KeyFilter{Tag: "prod"}, []string{"zmk_prod"}},
+		{"query_name", KeyFilter{Query: "PROD"}, []string{"zmk_prod"}},
+		{"query_kcv", KeyFilter{Query: "a1b2"}, []string{"zpk_test"}},
+		{"query_tag", KeyFilter{Query: "tes"}, []string{"zpk_test"}},
+		{"query_type", KeyFilter{Query: "pvk"}, []string{"pvk"}},
+		{"query_no_match", KeyFilter{Query: "nothing"}, []string{}},
+		{"length_desc", KeyFilter{Sort: SortByLength, Descending: true}, []string{"zpk_test", "zmk_prod", "old_zmk", "pvk"}},
+		{"created_ties_by_name", KeyFilter{Sort: SortByCreated}, []string{"pvk", "old_zmk", "zmk_prod", "zpk_test"}},
+		{"kcv", KeyFilter{Sort: SortByCheckValue}, []string{"zmk_prod", "old_zmk", "zpk_test", "pvk"}},
+		{"status", KeyFilter{Sort: SortByStatus}, []string{"pvk", "zmk_prod", "zpk_test", "old_zmk"}},
+		{"type_and_status", KeyFilter{Type: ZMK, Status: StatusActive, Sort: SortByType}, []string{"zmk_prod"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(ks.ListFiltered(tt.filter)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListFiltered() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyStore_ListPage(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+	ks.StoreAll([]KeyEntry{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}})
+
+	tests := []struct {
+		name          string
+		offset, limit int
+		want          []string
+	}{
+		{"first_page", 0, 2, []string{"a", "b"}},
+		{"middle_page", 2, 2, []string{"c", "d"}},
+		{"last_page_short", 4, 2, []string{"e"}},
+		{"past_the_end", 10, 2, []string{}},
+		{"negative_offset", -1, 1, []string{"a"}},
+		{"no_limit", 3, 0, []string{"d", "e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, total := ks.ListPage(KeyFilter{}, tt.offset, tt.limit)
+			got := make([]string, len(entries))
+			for i, e := range entries {
+				got[i] = e.Name
+			}
+			if !reflect.DeepEqual(got, tt.want) || total != 5 {
+				t.Errorf("ListPage() = %v, %d, want %v, 5", got, total, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyStore_Tags(t *testing.T) {
+	ks, _ := newTestKeyStore(t)
+	ks.StoreAll([]KeyEntry{
+		{Name: "a", Tags: []string{"prod", "visa"}},
+		{Name: "b", Tags: []string{"test", "visa"}},
+		{Name: "c"},
+	})
+
+	if got, want := ks.Tags(), []string{"prod", "test", "visa"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// scenarioExt is the file extension of stored scenarios.
+const scenarioExt = ".json"
+
+// scenarioNamePattern restricts scenario names to characters that are safe in
+// file names on every platform.
+var scenarioNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]*$`)
+
+// ErrScenarioExists is returned when saving over a scenario without
+// permission to overwrite it.
+var ErrScenarioExists = errors.New("scenario already exists")
+
+// Scenario is a named Command Sender test setup.
+type Scenario struct {
+	Name            string `json:"name"`
+	Command         string `json:"command"`
+	InputMode       string `json:"input_mode,omitempty"` // ASCII when empty.
+	PerLine         bool   `json:"per_line,omitempty"`
+	Count           int    `json:"count,omitempty"`
+	DurationSeconds int    `json:"duration_seconds,omitempty"` // Time based run when non-zero.
+	IntervalSeconds int    `json:"interval_seconds,omitempty"` // Periodic run when non-zero.
+	Concurrent      bool   `json:"concurrent"`
+	Workers         int    `json:"workers,omitempty"` // Pool capacity when zero.
+	TimeoutMillis   int    `json:"timeout_ms"`
+	ThresholdMillis int    `json:"threshold_ms,omitempty"` // Latency threshold, off when zero.
+	Expected        string `json:"expected,omitempty"`
+	StopOnFailure   bool   `json:"stop_on_failure,omitempty"`
+	Warmup          int    `json:"warmup,omitempty"`
+	Retries         int    `json:"retries,omitempty"`
+
+	Profile []ProfileStep `json:"profile,omitempty"` // Load profile run when set.
+}
+
+// ProfileStep is a step of a stored load profile.
+type ProfileStep struct {
+	TPS             float64 `json:"tps"`
+	DurationSeconds int     `json:"duration_seconds"`
+}
+
+// ContainsClearKey reports whether the command holds what looks like a clear
+// key, using the same heuristic as the log masker.
+func (s Scenario) ContainsClearKey() bool {
+	return logger.MaskHexRuns(s.Command) != s.Command
+}
+
+// ScenarioStore keeps scenarios as one JSON document per name in a
+// directory.
+type ScenarioStore struct {
+	dir string
+}
+
+// NewScenarioStore creates a scenario store in dir.
+func NewScenarioStore(dir string) (*ScenarioStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create scenario directory: %v", err)
+	}
+
+	return &ScenarioStore{dir: dir}, nil
+}
+
+// ValidateScenarioName checks that name can be used as a scenario name.
+func ValidateScenarioName(name string) error {
+	if !scenarioNamePattern.MatchString(name) {
+		return errors.New("scenario name must start with a letter or digit and " +
+			"contain only letters, digits, spaces, '.', '_' and '-'")
+	}
+
+	return nil
+}
+
+// path returns the file of the named scenario.
+func (ss *ScenarioStore) path(name string) string {
+	return filepath.Join(ss.dir, name+scenarioExt)
+}
+
+// List returns the names of the stored scenarios, sorted.
+func (ss *ScenarioStore) List() ([]string, error) {
+	entries, err := os.ReadDir(ss.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scenarios: %v", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), scenarioExt)
+		if ok && !e.IsDir() && ValidateScenarioName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// Exists reports whether a scenario with the name is stored.
+func (ss *ScenarioStore) Exists(name string) bool {
+	_, err := os.Stat(ss.path(name))
+
+	return err == nil
+}
+
+// Load reads the named scenario.
+func (ss *ScenarioStore) Load(name string) (Scenario, error) {
+	if err := ValidateScenarioName(name); err != nil {
+		return Scenario{}, err
+	}
+	data, err := os.ReadFile(ss.path(name))
+	if err != nil {
+		return Scenario{}, fmt.Errorf("failed to read scenario %q: %v", name, err)
+	}
+
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Scenario{}, fmt.Errorf("failed to parse scenario %q: %v", name, err)
+	}
+	s.Name = name
+
+	return s, nil
+}
+
+// Save stores the scenario under its name. An existing scenario is only
+// replaced when overwrite is set; otherwise ErrScenarioExists is returned.
+func (ss *ScenarioStore) Save(s Scenario, overwrite bool) error {
+	if err := ValidateScenarioName(s.Name); err != nil {
+		return err
+	}
+	if !overwrite && ss.Exists(s.Name) {
+		return ErrScenarioExists
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal scenario: %v", err)
+	}
+
+	return os.WriteFile(ss.path(s.Name), data, 0o600)
+}
+
+// SaveAll adds or replaces the scenarios. Either all of them are stored or
+// none is. The returned undo restores the scenarios as they were before.
+func (ss *ScenarioStore) SaveAll(scenarios []Scenario) (undo func() error, err error) {
+	data := make([][]byte, len(scenarios))
+	for i, s := range scenarios {
+		if err := ValidateScenarioName(s.Name); err != nil {
+			return nil, fmt.Errorf("scenario %q: %v", s.Name, err)
+		}
+		if data[i], err = json.MarshalIndent(s, "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to marshal scenario: %v", err)
+		}
+	}
+
+	// Previous contents by name; nil for scenarios that did not exist.
+	prev := make(map[string][]byte, len(scenarios))
+	restore := func() error {
+		var errs []error
+		for name, old := range prev {
+			if old == nil {
+				if err := os.Remove(ss.path(name)); err != nil && !os.IsNotExist(err) {
+					errs = append(errs, err)
+				}
+			} else if err := ss.writeFile(name, old); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+
+	for i, s := range scenarios {
+		if _, seen := prev[s.Name]; !seen {
+			old, err := os.ReadFile(ss.path(s.Name))
+			if err != nil && !os.IsNotExist(err) {
+				_ = restore()
+				return nil, fmt.Errorf("failed to read scenario %q: %v", s.Name, err)
+			}
+			prev[s.Name] = old
+		}
+		if err := ss.writeFile(s.Name, data[i]); err != nil {
+			_ = restore()
+			return nil, err
+		}
+	}
+
+	return restore, nil
+}
+
+// writeFile replaces the file of the named scenario through a temporary
+// file, so a failed write leaves the previous contents in place.
+func (ss *ScenarioStore) writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(ss.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save scenario %q: %v", name, err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed.
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save scenario %q: %v", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save scenario %q: %v", name, err)
+	}
+	if err := os.Rename(tmp.Name(), ss.path(name)); err != nil {
+		return fmt.Errorf("failed to save scenario %q: %v", name, err)
+	}
+
+	return nil
+}
+
+// Delete removes the named scenario.
+func (ss *ScenarioStore) Delete(name string) error {
+	if err := ValidateScenarioName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(ss.path(name)); err != nil {
+		if os.IsNotExist(err) {
+			return errors.New("scenario not found")
+		}
+
+		return fmt.Errorf("failed to delete scenario %q: %v", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+// nolint:all // test package
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func newTestScenarioStore(t *testing.T) *ScenarioStore {
+	t.Helper()
+	ss, err := NewScenarioStore(filepath.Join(t.TempDir(), "scenarios"))
+	if err != nil {
+		t.Fatalf("Failed to create test ScenarioStore: %v", err)
+	}
+
+	return ss
+}
+
+func TestScenario_JSON(t *testing.T) {
+	s := Scenario{
+		Name:          "soak NC",
+		Command:       "NC",
+		Count:         1000,
+		Concurrent:    true,
+		TimeoutMillis: 2000,
+		Expected:      "ND00",
+		Warmup:        50,
+		Retries:       2,
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `{"name":"soak NC","command":"NC","count":1000,"concurrent":true,"timeout_ms":2000,` +
+		`"expected":"ND00","warmup":50,"retries":2}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var got Scenario
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("round trip = %+v, want %+v", got, s)
+	}
+}
+
+func TestScenarioStore_SaveLoadDelete(t *testing.T) {
+	ss := newTestScenarioStore(t)
+	a := Scenario{Name: "a-load", Command: "NC", DurationSeconds: 60, TimeoutMillis: 5000, ThresholdMillis: 250}
+	b := Scenario{Name: "B 2", Command: "A0\nNC", PerLine: true, Count: 3, TimeoutMillis: 100}
+	c := Scenario{Name: "c", Command: "NC", Count: 100, Concurrent: true, Workers: 2, TimeoutMillis: 100}
+	monitor := Scenario{Name: "monitor", Command: "NC", IntervalSeconds: 30, TimeoutMillis: 2000}
+	ramp := Scenario{
+		Name:          "ramp",
+		Command:       "NC",
+		TimeoutMillis: 5000,
+		Profile:       []ProfileStep{{TPS: 50, DurationSeconds: 30}, {TPS: 100.5, DurationSeconds: 30}},
+	}
+
+	for _, s := range []Scenario{b, a, ramp, monitor, c} {
+		if err := ss.Save(s, false); err != nil {
+			t.Fatalf("Save(%q) error = %v", s.Name, err)
+		}
+	}
+
+	names, err := ss.List()
+	if err != nil || !reflect.DeepEqual(names, []string{"B 2", "a-load", "c", "monitor", "ramp"}) {
+		t.Errorf("List() = %q, %v", names, err)
+	}
+	got, err := ss.Load("B 2")
+	if err != nil || !reflect.DeepEqual(got, b) {
+		t.Errorf("Load() = %+v, %v, want %+v", got, err, b)
+	}
+
+	for _, want := range []Scenario{a, c, ramp, monitor} {
+		if got, err := ss.Load(want.Name); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Load() = %+v, %v, want %+v", got, err, want)
+		}
+	}
+
+	if err := ss.Delete("B 2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if ss.Exists("B 2") {
+		t.Error("scenario still exists after Delete()")
+	}
+	if err := ss.Delete("B 2"); err == nil {
+		t.Error("Delete() of a missing scenario expected error")
+	}
+	if _, err := ss.Load("B 2"); err == nil {
+		t.Error("Load() of a missing scenario expected error")
+	}
+}
+
+func TestScenarioStore_Overwrite(t *testing.T) {
+	ss := newTestScenarioStore(t)
+	first := Scenario{Name: "nightly", Command: "NC", Count: 10}
+	second := Scenario{Name: "nightly", Command: "BU02", Count: 20}
+
+	if err := ss.Save(first, false); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := ss.Save(second, false); !errors.Is(err, ErrScenarioExists) {
+		t.Fatalf("Save() without overwrite error = %v, want ErrScenarioExists", err)
+	}
+	if got, _ := ss.Load("nightly"); !reflect.DeepEqual(got, first) {
+		t.Errorf("refused save replaced the scenario: %+v", got)
+	}
+
+	if err := ss.Save(second, true); err != nil {
+		t.Fatalf("Save() with overwrite error = %v", err)
+	}
+	if got, _ := ss.Load("nightly"); !reflect.DeepEqual(got, second) {
+		t.Errorf("Load() after overwrite = %+v, want %+v", got, second)
+	}
+}
+
+func TestScenarioStore_InvalidNames(t *testing.T) {
+	ss := newTestScenarioStore(t)
+	for _, name := range []string{"", " lead", "../escape", "a/b", ".hidden", `c:\x`} {
+		if err := ss.Save(Scenario{Name: name}, true); err == nil {
+			t.Errorf("Save(%q) expected error", name)
+		}
+	}
+
+	// Foreign files in the directory are not listed.
+	if err := os.WriteFile(filepath.Join(ss.dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := ss.List(); len(names) != 0 {
+		t.Errorf("List() = %q, want none", names)
+	}
+}
+
+func TestScenario_ContainsClearKey(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"NC", false},
+		{"A0002U", false},
+		{"A0U0123456789ABCDEF0123456789ABCDEF", true},
+	}
+
+	for _, tt := range tests {
+		if got := (Scenario{Command: tt.command}).ContainsClearKey(); got != tt.want {
+			t.Errorf("ContainsClearKey(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,14 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/config"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
 	"github.com/andrei-cloud/hsmtool/internal/ui/tabs"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -10,44 +17,152 @@ import (
 )
 
 const (
+	appID     = "com.github.andrei-cloud.hsmtool"
 	appTitle  = "HSM Key Management Tool"
 	appWidth  = 1024
 	appHeight = 768
 )
 
+// configPrefs are the preferences of the main window included in exported
+// configurations.
+var configPrefs = []config.Pref{
+	{Key: prefTheme, Kind: config.PrefString},
+	{Key: prefWindowWidth, Kind: config.PrefInt},
+	{Key: prefWindowHeight, Kind: config.PrefInt},
+	{Key: prefSelectedTab, Kind: config.PrefInt},
+}
+
+// defaultLogPath returns the per-OS default location of the application log.
+func defaultLogPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "hsmtool", "app.log")
+}
+
+// defaultKeyStorePath returns the per-OS default location of the key store.
+func defaultKeyStorePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "hsmtool", "keys.json")
+}
+
+// defaultScenarioDir returns the per-OS default location of saved Command
+// Sender scenarios.
+func defaultScenarioDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "hsmtool", "scenarios")
+}
+
+// defaultProfilesPath returns the per-OS default location of the saved
+// connection profiles.
+func defaultProfilesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "hsmtool", "profiles.json")
+}
+
 // StartApp initializes and runs the main application window.
 func StartApp() {
-	application := app.New()
+	// Logging is best effort; the application stays usable without it.
+	if err := logger.Init(defaultLogPath(), logger.INFO); err != nil {
+		fmt.Fprintf(os.Stderr, "logging disabled: %v\n", err)
+	}
+	logger.Info("app_start", "Success", "")
+
+	application := app.NewWithID(appID)
 	mainWindow := application.NewWindow(appTitle)
+	// Apply the saved theme before the tabs pick their colours.
+	mainWindow.SetMainMenu(fyne.NewMainMenu(themeMenu(application)))
+
+	// The key store is optional; tabs disable store actions without it.
+	keyStore, err := storage.NewKeyStore(defaultKeyStorePath())
+	if err != nil {
+		logger.Error("keystore_open", "Failed", err.Error())
+	}
+	scenarios, err := storage.NewScenarioStore(defaultScenarioDir())
+	if err != nil {
+		logger.Error("scenarios_open", "Failed", err.Error())
+	}
+
+	profiles, err := storage.NewProfileStore(defaultProfilesPath())
+	if err != nil {
+		logger.Error("profiles_open", "Failed", err.Error())
+	}
 
 	// Create settings tab with HSM connection first
-	settingsTab := tabs.NewSettings()
+	tabs.AddConfigPrefs(configPrefs...)
+	settingsTab := tabs.NewSettings(profiles, scenarios)
+	aesTab := tabs.NewAESCalculator()
+	logsTab := tabs.NewLogsAudit()
+	keyBlockTab := tabs.NewKeyBlockParser()
+	emvTab := tabs.NewEMVCryptogram()
+	keyStorageTab := tabs.NewKeyStorage(keyStore)
+	rsaTab := tabs.NewRSATool()
+	testDataTab := tabs.NewTestDataGenerator()
 
 	// Create tab container with all app tabs
 	tabContainer := container.NewAppTabs(
 		container.NewTabItemWithIcon(
 			"Generate Key",
 			theme.HomeIcon(),
-			tabs.NewKeyManager(settingsTab.GetConnection()),
+			tabs.NewKeyManager(settingsTab.GetConnection(), keyStore),
 		),
 		container.NewTabItemWithIcon(
 			"DES Calculator",
 			theme.ConfirmIcon(),
 			tabs.NewDESCalculator(),
 		),
-		container.NewTabItem("Bitwise Calculator", tabs.NewBitwiseCalculator()),
+		container.NewTabItemWithIcon(
+			"AES Calculator",
+			theme.ConfirmIcon(),
+			aesTab,
+		),
+		container.NewTabItem("Bitwise Calculator", tabs.NewBitwiseCalculator(keyStore)),
+		container.NewTabItemWithIcon("Key Storage", theme.StorageIcon(), keyStorageTab),
+		container.NewTabItemWithIcon("Converter", theme.ViewRefreshIcon(), tabs.NewEncodingConverter()),
+		container.NewTabItemWithIcon("Key Block Parser", theme.SearchIcon(), keyBlockTab),
+		container.NewTabItemWithIcon("EMV Cryptogram", theme.ConfirmIcon(), emvTab),
+		container.NewTabItemWithIcon("RSA", theme.AccountIcon(), rsaTab),
+		container.NewTabItemWithIcon("Test Data", theme.GridIcon(), testDataTab),
 		container.NewTabItemWithIcon(
 			"HSM Command",
 			theme.FileIcon(),
-			tabs.NewHSMCommandSender(settingsTab.GetConnection(), true),
+			tabs.NewHSMCommandSender(settingsTab.GetConnection(), keyStore, scenarios, true),
 		),
+		container.NewTabItemWithIcon("Logs", theme.ListIcon(), logsTab),
 		container.NewTabItemWithIcon("Settings", theme.SettingsIcon(), settingsTab),
 	)
 	tabContainer.SetTabLocation(container.TabLocationTop)
 
+	// Restore the last selected tab and window size, saving them as they
+	// change.
+	state := newWindowState(application.Preferences())
+	tabContainer.SelectIndex(state.Tab(len(tabContainer.Items)))
+	tabContainer.OnSelected = func(*container.TabItem) {
+		state.SaveTab(tabContainer.SelectedIndex())
+	}
+
 	// Set window content and size.
-	mainWindow.SetContent(tabContainer)
-	mainWindow.Resize(fyne.NewSize(appWidth, appHeight))
+	mainWindow.SetContent(container.New(&resizeLayout{onLayout: func() {
+		state.Resized(mainWindow.Canvas().Size())
+	}}, tabContainer))
+	mainWindow.Resize(state.Size(
+		fyne.NewSize(appWidth, appHeight),
+		screenSize(mainWindow.Canvas()),
+	))
 	mainWindow.CenterOnScreen()
 
 	mainWindow.SetOnClosed(func() {
@@ -55,6 +170,18 @@ func StartApp() {
 		if conn := settingsTab.GetConnection(); conn != nil {
 			conn.Disconnect()
 		}
+		state.SaveSize(mainWindow.Canvas().Size())
+		aesTab.Cleanup()
+		logsTab.Cleanup()
+		keyBlockTab.Cleanup()
+		emvTab.Cleanup()
+		keyStorageTab.Cleanup()
+		rsaTab.Cleanup()
+		testDataTab.Cleanup()
+		logger.Info("app_stop", "Success", "")
+		if l := logger.Default(); l != nil {
+			_ = l.Close()
+		}
 	})
 
 	mainWindow.SetMaster()
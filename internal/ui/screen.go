@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"fyne.io/fyne/v2"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// screenSize returns the work area of the primary display in the units of
+// c, or a zero size when it is not known. Fyne does not expose the display
+// size, so it is read from GLFW, which Fyne initialises when the first
+// window is created. It must be called on the main goroutine.
+func screenSize(c fyne.Canvas) (size fyne.Size) {
+	// GLFW reports errors by panicking.
+	defer func() {
+		if recover() != nil {
+			size = fyne.Size{}
+		}
+	}()
+
+	monitor := glfw.GetPrimaryMonitor()
+	if monitor == nil {
+		return fyne.Size{}
+	}
+	_, _, width, height := monitor.GetWorkarea()
+	scale := c.Scale()
+	if scale <= 0 {
+		scale = 1
+	}
+
+	return fyne.NewSize(float32(width)/scale, float32(height)/scale)
+}
@@ -0,0 +1,268 @@
+package tabs
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+const (
+	// maxAESKeyHexDigits is the hex length of an AES-256 key.
+	maxAESKeyHexDigits = 64
+	// aesIVHexDigits is the hex length of an AES IV.
+	aesIVHexDigits = 32
+)
+
+// AESCalculator represents the AES Calculator tab.
+type AESCalculator struct {
+	widget.BaseWidget
+	container *fyne.Container
+	form      *widget.Form
+
+	// Input fields.
+	dataInput   *widget.Entry
+	keyInput    *widget.Entry
+	padding     *widget.Select
+	mode        *widget.Select
+	operation   *widget.Select
+	ivInput     *widget.Entry   // iv input for CBC mode
+	ivContainer *fyne.Container // container for iv row
+
+	// Output fields.
+	kcv    *widget.Label
+	result *widget.Entry
+}
+
+// NewAESCalculator creates a new AES Calculator tab.
+func NewAESCalculator() *AESCalculator {
+	c := &AESCalculator{}
+	c.ExtendBaseWidget(c)
+
+	// Create IV input for CBC mode first.
+	c.ivInput = widget.NewEntry()
+	c.ivInput.SetPlaceHolder("Enter IV in hex format (32 hex digits)")
+	c.ivInput.OnChanged = func(iv string) {
+		if clean, changed := utils.NormalizeHexInput(iv, aesIVHexDigits); changed {
+			c.ivInput.SetText(clean)
+		}
+	}
+
+	c.ivContainer = container.NewHBox(
+		container.NewGridWrap(fyne.NewSize(60, 36), widget.NewLabel("IV:")),
+		container.NewGridWrap(fyne.NewSize(480, 36), c.ivInput),
+		layout.NewSpacer(),
+	)
+
+	// Create Mode/Operation/Padding group items.
+	c.mode = widget.NewSelect(CipherModes, func(value string) {
+		if value == "ECB" {
+			c.ivContainer.Hide()
+		} else {
+			c.ivContainer.Show()
+		}
+	})
+	c.mode.SetSelected("ECB")
+
+	c.operation = widget.NewSelect(Operations, nil)
+	c.operation.SetSelected("Encrypt")
+
+	c.padding = widget.NewSelect(PaddingModes, nil)
+	c.padding.SetSelected(PaddingModes[0])
+
+	c.form = &widget.Form{
+		Items: []*widget.FormItem{
+			{Text: "Mode", Widget: c.mode},
+			{Text: "Operation", Widget: c.operation},
+			{Text: "Padding", Widget: c.padding},
+		},
+	}
+
+	// Create data input field.
+	c.dataInput = widget.NewMultiLineEntry()
+	c.dataInput.SetPlaceHolder("Enter data in hex format")
+	c.dataInput.Wrapping = fyne.TextWrapBreak
+
+	// Create key input field sized for 64 hex digits.
+	c.keyInput = widget.NewEntry()
+	c.keyInput.SetPlaceHolder("Enter AES key in hex format (32/48/64 hex digits)")
+	c.keyInput.OnChanged = func(key string) {
+		if clean, changed := utils.NormalizeHexInput(key, maxAESKeyHexDigits); changed {
+			c.keyInput.SetText(clean) // Re-enters OnChanged with clean text.
+			return
+		}
+		c.calculateKCV(key)
+	}
+
+	c.kcv = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{})
+
+	// Create result field.
+	c.result = widget.NewMultiLineEntry()
+	c.result.Wrapping = fyne.TextWrapBreak
+	c.result.Disable() // Make result read-only
+
+	calculate := widget.NewButton("Calculate", c.calculate)
+
+	c.container = container.NewVBox(
+		widget.NewCard("Settings", "", c.form),
+
+		widget.NewCard("Input Data", "",
+			container.NewVBox(
+				c.dataInput,
+			),
+		),
+
+		widget.NewCard("Key", "",
+			container.NewVBox(
+				container.NewHBox(
+					container.NewGridWrap(fyne.NewSize(560, 36), c.keyInput),
+					layout.NewSpacer(),
+					widget.NewLabelWithStyle(
+						"KCV:",
+						fyne.TextAlignLeading,
+						fyne.TextStyle{Bold: true},
+					),
+					container.NewGridWrap(fyne.NewSize(120, 36), c.kcv),
+					newCopyButton(kcvText(c.kcv)),
+				),
+				widget.NewLabel(""), // Add subtle spacing
+				c.ivContainer,
+			),
+		),
+
+		widget.NewCard("Result", "",
+			container.NewBorder(
+				nil, nil, nil,
+				newCopyButton(func() string { return utils.StripHexFormatting(c.result.Text) }),
+				c.result,
+			),
+		),
+
+		calculate,
+	)
+
+	return c
+}
+
+// validAESKeyLength reports whether hexKey has an AES-128/192/256 length.
+func validAESKeyLength(hexKey string) bool {
+	return len(hexKey) == 32 || len(hexKey) == 48 || len(hexKey) == 64
+}
+
+// calculateKCV calculates and displays the CMAC Key Check Value for key.
+func (c *AESCalculator) calculateKCV(key string) {
+	key, _ = utils.NormalizeHexInput(key, 0)
+	if !validAESKeyLength(key) {
+		c.kcv.SetText("Invalid key length")
+		return
+	}
+
+	keyBytes, err := utils.DecodeHexLenient(key)
+	if err != nil {
+		c.kcv.SetText("Invalid hex format")
+		return
+	}
+
+	kcv, err := crypto.CalculateAESKCV(keyBytes)
+	if err != nil {
+		c.kcv.SetText("KCV error")
+		return
+	}
+	c.kcv.SetText(kcv)
+}
+
+// calculate processes the input data according to the selected options.
+func (c *AESCalculator) calculate() {
+	// Get and validate the key.
+	key, _ := utils.NormalizeHexInput(c.keyInput.Text, 0)
+	if !validAESKeyLength(key) {
+		c.result.SetText("Invalid key length (must be 32, 48 or 64 hex digits)")
+		return
+	}
+	keyBytes, err := utils.DecodeHexLenient(key)
+	if err != nil {
+		c.result.SetText("Invalid key format")
+		return
+	}
+
+	// Get and validate the data.
+	data, _ := utils.NormalizeHexInput(c.dataInput.Text, 0)
+	if data == "" {
+		c.result.SetText("No data provided")
+		return
+	}
+	dataBytes, err := utils.DecodeHexLenient(data)
+	if err != nil {
+		c.result.SetText("Invalid data format")
+		return
+	}
+
+	mode, ok := cipherModeByName[c.mode.Selected]
+	if !ok {
+		c.result.SetText(fmt.Sprintf("Error: unsupported mode %q", c.mode.Selected))
+		return
+	}
+
+	// Get and validate IV for chained modes.
+	var iv []byte
+	if mode != crypto.ECB {
+		ivStr, _ := utils.NormalizeHexInput(c.ivInput.Text, 0)
+		if len(ivStr) != aesIVHexDigits {
+			c.result.SetText("Invalid IV length (must be 32 hex digits)")
+			return
+		}
+		iv, err = utils.DecodeHexLenient(ivStr)
+		if err != nil {
+			c.result.SetText("Invalid IV format")
+			return
+		}
+	}
+
+	padding, ok := paddingModeByName[c.padding.Selected]
+	if !ok {
+		c.result.SetText(fmt.Sprintf("Error: unsupported padding %q", c.padding.Selected))
+		return
+	}
+
+	result, err := crypto.ProcessAES(&crypto.AESParams{
+		Data:    dataBytes,
+		Key:     keyBytes,
+		IV:      iv,
+		Mode:    mode,
+		Padding: padding,
+		Encrypt: c.operation.Selected == "Encrypt",
+	})
+	if err != nil {
+		c.result.SetText(fmt.Sprintf("Error: %v", err))
+		return
+	}
+
+	// Display the result grouped for readability.
+	formatted, err := utils.FormatHex(hex.EncodeToString(result), 8, " ")
+	if err != nil {
+		c.result.SetText(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	c.result.SetText(formatted)
+}
+
+// CreateRenderer returns a new renderer for the AESCalculator widget.
+func (c *AESCalculator) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.container)
+}
+
+// Cleanup implements TabContent interface.
+func (c *AESCalculator) Cleanup() {
+	// Clear sensitive data.
+	c.keyInput.SetText("")
+	c.ivInput.SetText("")
+	c.dataInput.SetText("")
+	c.result.SetText("")
+	c.kcv.SetText("")
+}
@@ -0,0 +1,85 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestAESCalculator_KeyLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantKCV bool
+	}{
+		{"aes_128", "2B7E151628AED2A6ABF7158809CF4F3C", true},
+		{"aes_192", "8E73B0F7DA0E6452C810F32B809079E562F8EAD2522C6B7B", true},
+		{"aes_256", "603DEB1015CA71BE2B73AEF0857D77811F352C073B6108D72D9810A30914DFF4", true},
+		{"des_length", "0123456789ABCDEF", false},
+		{"odd_length", "2B7E151628AED2A6ABF7158809CF4F3", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			c := NewAESCalculator()
+			c.keyInput.SetText(tt.key)
+			c.dataInput.SetText("6BC1BEE22E409F96E93D7E117393172A")
+			c.calculate()
+
+			if gotKCV := len(c.kcv.Text) == 6; gotKCV != tt.wantKCV {
+				t.Errorf("kcv = %q, want valid KCV %v", c.kcv.Text, tt.wantKCV)
+			}
+			if tt.wantKCV {
+				return
+			}
+			if c.result.Text != "Invalid key length (must be 32, 48 or 64 hex digits)" {
+				t.Errorf("result = %q, want key length error", c.result.Text)
+			}
+		})
+	}
+}
+
+func TestAESCalculator_ECBKnownAnswer(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewAESCalculator()
+	c.keyInput.SetText("2B7E151628AED2A6ABF7158809CF4F3C")
+	c.dataInput.SetText("6BC1BEE22E409F96E93D7E117393172A")
+	c.calculate()
+
+	if want := "3AD77BB4 0D7A3660 A89ECAF3 2466EF97"; c.result.Text != want {
+		t.Errorf("result = %q, want %q", c.result.Text, want)
+	}
+}
+
+func TestAESCalculator_CBCRequiresIV(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewAESCalculator()
+	if c.ivContainer.Visible() {
+		t.Error("IV row visible in ECB mode")
+	}
+
+	c.mode.SetSelected("CBC")
+	if !c.ivContainer.Visible() {
+		t.Error("IV row hidden in CBC mode")
+	}
+
+	c.keyInput.SetText("2B7E151628AED2A6ABF7158809CF4F3C")
+	c.dataInput.SetText("6BC1BEE22E409F96E93D7E117393172A")
+
+	c.ivInput.SetText("0001020304050607")
+	c.calculate()
+	if c.result.Text != "Invalid IV length (must be 32 hex digits)" {
+		t.Errorf("short IV result = %q", c.result.Text)
+	}
+
+	c.ivInput.SetText("000102030405060708090A0B0C0D0E0F")
+	c.calculate()
+	if want := "7649ABAC 8119B246 CEE98E9B 12E9197D"; c.result.Text != want {
+		t.Errorf("CBC result = %q, want %q", c.result.Text, want)
+	}
+}
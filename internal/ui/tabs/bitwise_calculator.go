@@ -2,15 +2,23 @@ package tabs
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/bits"
+	"strconv"
 	"strings"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
+	"github.com/andrei-cloud/hsmtool/internal/backend/ceremony"
 	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
 )
 
 var BitwiseOperations = []string{
@@ -22,20 +30,36 @@ var BitwiseOperations = []string{
 
 var ModeOptions = []string{"Regular", "Key Sharing"}
 
+// maxOperandHexDigits is the maximum length of a Regular mode operand.
+const maxOperandHexDigits = 96
+
 // BitwiseCalculator represents the Bitwise Calculator tab.
 type BitwiseCalculator struct {
 	widget.BaseWidget
 	container *fyne.Container
 	content   *fyne.Container
 
+	store       *storage.KeyStore // nil when the key store is unavailable
+	sourceEntry storage.KeyEntry  // entry the combined key was loaded from
+
 	// Mode toggle implemented as a horizontal radio group.
 	modeToggle *widget.RadioGroup
 
 	// Regular mode inputs.
-	operation *widget.RadioGroup
-	blockA    *widget.Entry
-	blockB    *widget.Entry
-	result    *widget.Entry
+	operation    *widget.RadioGroup
+	blockA       *widget.Entry
+	blockB       *widget.Entry
+	blockALen    *widget.Label // byte count of blockA
+	blockBLen    *widget.Label // byte count of blockB
+	asciiA       *widget.Check // interpret blockA as ASCII text
+	asciiB       *widget.Check // interpret blockB as ASCII text
+	result       *widget.Entry
+	resultHex    string        // last result, shown as hex or ASCII
+	resultASCII  *widget.Check // show the result as printable ASCII
+	resultKCV    *widget.Label
+	calculateBtn *widget.Button
+	history      bitwiseHistory // recent operations, never persisted
+	historyList  *widget.List
 
 	// Key sharing mode inputs.
 	combinedKey   *widget.Entry
@@ -49,12 +73,22 @@ type BitwiseCalculator struct {
 	comp1KCV      *widget.Label
 	comp2KCV      *widget.Label
 	comp3KCV      *widget.Label
+	combinedPar   *widget.Label // parity status of combinedKey
+	comp1Par      *widget.Label
+	comp2Par      *widget.Label
+	comp3Par      *widget.Label
+	expectedKCV   *widget.Entry // KCV the combined key must match
+	kcvMatch      *widget.Label // result of the expected KCV check
 	generate64    *widget.Button
 	generate128   *widget.Button
 	generate192   *widget.Button
 	generate256   *widget.Button
 	splitBtn      *widget.Button
 	combineBtn    *widget.Button
+	exportPattern *widget.Entry // file name pattern for component mailers
+	exportBtn     *widget.Button
+	loadBtn       *widget.Button
+	saveBtn       *widget.Button
 	helpText      *widget.Label
 }
 
@@ -64,19 +98,43 @@ func (bc *BitwiseCalculator) initializeComponents() {
 	bc.operation = widget.NewRadioGroup(BitwiseOperations, nil)
 	bc.operation.Horizontal = true
 	bc.operation.SetSelected(BitwiseOperations[0])
+	operandHint := fmt.Sprintf("Enter hex value (up to %d digits)...", maxOperandHexDigits)
 	bc.blockA = widget.NewEntry()
-	bc.blockA.SetPlaceHolder("Enter hex value (up to 16 digits)...")
-	bc.blockA.OnChanged = func(s string) { bc.validateHex(s, bc.blockA, 16) }
+	bc.blockA.SetPlaceHolder(operandHint)
+	bc.blockA.OnChanged = func(s string) { bc.onOperandChanged(s, bc.blockA, bc.asciiA) }
 	bc.blockB = widget.NewEntry()
-	bc.blockB.SetPlaceHolder("Enter hex value (up to 16 digits)...")
-	bc.blockB.OnChanged = func(s string) { bc.validateHex(s, bc.blockB, 16) }
-	bc.result = widget.NewEntry()
+	bc.blockB.SetPlaceHolder(operandHint)
+	bc.blockB.OnChanged = func(s string) { bc.onOperandChanged(s, bc.blockB, bc.asciiB) }
+	bc.asciiA = widget.NewCheck("Interpret as ASCII", func(bool) {
+		bc.onOperandChanged(bc.blockA.Text, bc.blockA, bc.asciiA)
+	})
+	bc.asciiB = widget.NewCheck("Interpret as ASCII", func(bool) {
+		bc.onOperandChanged(bc.blockB.Text, bc.blockB, bc.asciiB)
+	})
+	bc.result = widget.NewMultiLineEntry()
+	bc.result.Wrapping = fyne.TextWrapBreak
 	bc.result.Disable()
+	bc.resultKCV = widget.NewLabel("")
+	bc.resultASCII = widget.NewCheck("Show as ASCII", func(bool) { bc.showResult() })
+	bc.blockALen = widget.NewLabel("0 bytes")
+	bc.blockBLen = widget.NewLabel("0 bytes")
+	bc.calculateBtn = widget.NewButton("Calculate", bc.onCalculate)
+	bc.operation.OnChanged = bc.onOperationChanged
+	bc.historyList = widget.NewList(
+		bc.history.len,
+		bc.newHistoryRow,
+		bc.updateHistoryRow,
+	)
 
 	// Key sharing mode fields.
 	bc.combinedKey = widget.NewEntry()
 	bc.combinedKey.SetPlaceHolder("Combined key (hex, up to 64 chars)...")
-	bc.combinedKey.OnChanged = func(s string) { bc.validateHex(s, bc.combinedKey, 64) }
+	bc.combinedKey.OnChanged = func(s string) {
+		bc.validateHex(s, bc.combinedKey, 64)
+		if !strings.EqualFold(bc.combinedKey.Text, bc.sourceEntry.Value) {
+			bc.sourceEntry = storage.KeyEntry{} // No longer the loaded key.
+		}
+	}
 
 	bc.comp1 = widget.NewEntry()
 	bc.comp1.SetPlaceHolder("Component 1 (hex, up to 64 chars)...")
@@ -102,6 +160,20 @@ func (bc *BitwiseCalculator) initializeComponents() {
 	bc.comp3KCV = widget.NewLabel("KCV:")
 	bc.comp3KCV.Hide()
 
+	// Parity labels, shown only for DES key lengths.
+	bc.combinedPar = newParityLabel()
+	bc.comp1Par = newParityLabel()
+	bc.comp2Par = newParityLabel()
+	bc.comp3Par = newParityLabel()
+
+	bc.expectedKCV = widget.NewEntry()
+	bc.expectedKCV.SetPlaceHolder("Expected KCV (4, 6 or 16 hex digits, optional)...")
+	bc.expectedKCV.OnChanged = func(s string) {
+		bc.validateHex(s, bc.expectedKCV, 16)
+		bc.kcvMatch.SetText("")
+	}
+	bc.kcvMatch = widget.NewLabel("")
+
 	// Radio groups for options
 	bc.numComponents = widget.NewRadioGroup([]string{"2", "3"}, bc.onNumComponentsChanged)
 	bc.numComponents.SetSelected("2")
@@ -115,6 +187,19 @@ func (bc *BitwiseCalculator) initializeComponents() {
 	bc.generate256 = widget.NewButton("256-bit", bc.onGenerateKey(256))
 	bc.splitBtn = widget.NewButton("Split", bc.onSplit)
 	bc.combineBtn = widget.NewButton("Combine", bc.onCombine)
+	bc.loadBtn = widget.NewButtonWithIcon("Load from store…", theme.FolderOpenIcon(), bc.onLoadFromStore)
+	bc.saveBtn = widget.NewButtonWithIcon("Save components…", theme.DocumentSaveIcon(), bc.onSaveComponents)
+	if bc.store == nil {
+		bc.loadBtn.Disable()
+		bc.saveBtn.Disable()
+	}
+	bc.exportPattern = widget.NewEntry()
+	bc.exportPattern.SetText(ceremony.DefaultFilePattern)
+	bc.exportBtn = widget.NewButtonWithIcon(
+		"Export components…",
+		theme.DocumentSaveIcon(),
+		bc.onExportComponents,
+	)
 
 	// Help text
 	bc.helpText = widget.NewLabel(
@@ -124,9 +209,10 @@ func (bc *BitwiseCalculator) initializeComponents() {
 	bc.helpText.Wrapping = fyne.TextWrapWord
 }
 
-// NewBitwiseCalculator creates a new Bitwise Calculator tab.
-func NewBitwiseCalculator() *BitwiseCalculator {
-	bc := &BitwiseCalculator{}
+// NewBitwiseCalculator creates a new Bitwise Calculator tab. store may be nil,
+// which disables loading and saving keys.
+func NewBitwiseCalculator(store *storage.KeyStore) *BitwiseCalculator {
+	bc := &BitwiseCalculator{store: store}
 	bc.ExtendBaseWidget(bc)
 
 	// Initialize all components first.
@@ -169,6 +255,9 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 				fyne.NewSize(kcvWidth, bc.combinedKCV.MinSize().Height),
 				bc.combinedKCV,
 			),
+			newCopyButton(func() string { return bc.combinedKey.Text }),
+			newCopyButton(kcvText(bc.combinedKCV)),
+			bc.combinedPar,
 		)
 
 		// Component 1 Row
@@ -182,6 +271,9 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 				fyne.NewSize(kcvWidth, bc.comp1KCV.MinSize().Height),
 				bc.comp1KCV,
 			),
+			newCopyButton(func() string { return bc.comp1.Text }),
+			newCopyButton(kcvText(bc.comp1KCV)),
+			bc.comp1Par,
 		)
 
 		// Component 2 Row
@@ -192,6 +284,9 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 			),
 			container.NewGridWrap(fyne.NewSize(entryWidth, bc.comp2.MinSize().Height), bc.comp2),
 			container.NewGridWrap(fyne.NewSize(kcvWidth, bc.comp2.MinSize().Height), bc.comp2KCV),
+			newCopyButton(func() string { return bc.comp2.Text }),
+			newCopyButton(kcvText(bc.comp2KCV)),
+			bc.comp2Par,
 		)
 
 		// Component 3 Row
@@ -202,10 +297,27 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 			),
 			container.NewGridWrap(fyne.NewSize(entryWidth, bc.comp3.MinSize().Height), bc.comp3),
 			container.NewGridWrap(fyne.NewSize(kcvWidth, bc.comp3.MinSize().Height), bc.comp3KCV),
+			newCopyButton(func() string { return bc.comp3.Text }),
+			newCopyButton(kcvText(bc.comp3KCV)),
+			bc.comp3Par,
+		)
+
+		// Expected KCV Row
+		expectedKCVRow := container.NewHBox(
+			container.NewGridWrap(
+				fyne.NewSize(labelWidth, bc.expectedKCV.MinSize().Height),
+				widget.NewLabel("Expected KCV"),
+			),
+			container.NewGridWrap(
+				fyne.NewSize(entryWidth/2, bc.expectedKCV.MinSize().Height),
+				bc.expectedKCV,
+			),
+			bc.kcvMatch,
 		)
 
 		keyInputs := container.NewVBox(
 			combinedKeyRow,
+			expectedKCVRow,
 			widget.NewSeparator(),
 			component1Row,
 			component2Row,
@@ -239,6 +351,19 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 			layout.NewSpacer(),
 			bc.splitBtn,
 			bc.combineBtn,
+			bc.loadBtn,
+			bc.saveBtn,
+			layout.NewSpacer(),
+		)
+
+		exportRow := container.NewHBox(
+			layout.NewSpacer(),
+			widget.NewLabel("File pattern"),
+			container.NewGridWrap(
+				fyne.NewSize(labelWidth*2, bc.exportPattern.MinSize().Height),
+				bc.exportPattern,
+			),
+			bc.exportBtn,
 			layout.NewSpacer(),
 		)
 
@@ -249,15 +374,29 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 		bc.content.Add(genButtons)
 		bc.content.Add(widget.NewSeparator())
 		bc.content.Add(actionButtons)
+		bc.content.Add(exportRow)
 		bc.content.Add(widget.NewSeparator())
 		bc.content.Add(bc.helpText)
 	} else {
 		calc := container.NewVBox(
 			bc.operation,
 			bc.blockA,
+			container.NewHBox(bc.blockALen, layout.NewSpacer(), bc.asciiA),
 			bc.blockB,
-			bc.result,
-			widget.NewButton("Calculate", bc.onCalculate),
+			container.NewHBox(bc.blockBLen, layout.NewSpacer(), bc.asciiB),
+			container.NewBorder(
+				nil, nil, nil,
+				container.NewHBox(
+					newCopyButton(func() string { return bc.result.Text }),
+					bc.resultKCV,
+					newCopyButton(kcvText(bc.resultKCV)),
+				),
+				bc.result,
+			),
+			container.NewHBox(bc.resultASCII),
+			bc.calculateBtn,
+			widget.NewLabel("History"),
+			container.NewGridWrap(fyne.NewSize(720, 180), bc.historyList),
 		)
 		bc.content.Add(calc)
 	}
@@ -266,13 +405,15 @@ func (bc *BitwiseCalculator) onModeChange(mode string) {
 
 func (bc *BitwiseCalculator) onCalculate() {
 	op := bc.operation.Selected
-	a := bc.blockA.Text
-	b := bc.blockB.Text
+	a := operandHex(bc.blockA, bc.asciiA)
+	b := operandHex(bc.blockB, bc.asciiB)
 	params := &crypto.BitwiseParams{
 		Operation: crypto.BitwiseOperation(op),
 		BlockA:    a,
 		BlockB:    b,
 	}
+	bc.resultKCV.SetText("")
+	bc.resultHex = ""
 	result, err := crypto.PerformBitwise(params)
 	if err != nil {
 		bc.result.SetText(err.Error())
@@ -280,7 +421,208 @@ func (bc *BitwiseCalculator) onCalculate() {
 		return
 	}
 
-	bc.result.SetText(result)
+	bc.resultHex = result
+	bc.showResult()
+	bc.history.add(bitwiseHistoryEntry{
+		Time:      time.Now(),
+		Operation: params.Operation,
+		A:         strings.ToUpper(a),
+		B:         strings.ToUpper(b),
+		Result:    result,
+	})
+	bc.historyList.Refresh()
+
+	// Operands of a DES key length make the result a key worth checking.
+	if !isDESKeyLength(a) || (op != string(crypto.NOT) && !isDESKeyLength(b)) {
+		return
+	}
+	key, err := hex.DecodeString(result)
+	if err != nil {
+		return
+	}
+	if kcv, err := crypto.CalculateKCV(key); err == nil {
+		bc.resultKCV.SetText("KCV: " + kcv)
+	}
+}
+
+// newHistoryRow creates a history row with copy and reuse actions.
+func (bc *BitwiseCalculator) newHistoryRow() fyne.CanvasObject {
+	text := widget.NewLabel("")
+	text.Truncation = fyne.TextTruncateEllipsis
+	copyBtn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), nil)
+	copyBtn.Importance = widget.LowImportance
+	reuseBtn := widget.NewButtonWithIcon("", theme.ContentUndoIcon(), nil)
+	reuseBtn.Importance = widget.LowImportance
+
+	return container.NewBorder(nil, nil, nil, container.NewHBox(copyBtn, reuseBtn), text)
+}
+
+// updateHistoryRow binds row to the history entry at id.
+func (bc *BitwiseCalculator) updateHistoryRow(id widget.ListItemID, row fyne.CanvasObject) {
+	e, ok := bc.history.entry(id)
+	if !ok {
+		return
+	}
+	border := row.(*fyne.Container)
+	text := border.Objects[0].(*widget.Label)
+	actions := border.Objects[1].(*fyne.Container)
+	copyBtn := actions.Objects[0].(*widget.Button)
+	reuseBtn := actions.Objects[1].(*widget.Button)
+
+	text.SetText(e.String())
+	copyBtn.OnTapped = func() { copyWithFeedback(copyBtn, e.Result) }
+	reuseBtn.OnTapped = func() { bc.reuseHistory(id) }
+}
+
+// reuseHistory loads the operation and operands of a history entry back into
+// the Regular mode inputs.
+func (bc *BitwiseCalculator) reuseHistory(id widget.ListItemID) {
+	e, ok := bc.history.entry(id)
+	if !ok {
+		return
+	}
+	bc.asciiA.SetChecked(false)
+	bc.asciiB.SetChecked(false)
+	bc.operation.SetSelected(string(e.Operation))
+	bc.blockA.SetText(e.A)
+	if e.Operation != crypto.NOT {
+		bc.blockB.SetText(e.B)
+	}
+}
+
+// onOperandChanged filters a Regular mode operand to hex unless it is
+// interpreted as ASCII, then refreshes the operand information.
+func (bc *BitwiseCalculator) onOperandChanged(s string, entry *widget.Entry, ascii *widget.Check) {
+	if ascii != nil && ascii.Checked {
+		bc.updateOperandInfo()
+		return
+	}
+	bc.validateHex(s, entry, maxOperandHexDigits)
+}
+
+// operandHex returns the hex value of an operand entry, converting ASCII text
+// when the operand is interpreted as ASCII.
+func operandHex(entry *widget.Entry, ascii *widget.Check) string {
+	if ascii.Checked {
+		return utils.ASCIIToHex(entry.Text)
+	}
+
+	return entry.Text
+}
+
+// showResult displays the last result as hex, or as printable ASCII with dots
+// for other bytes when requested.
+func (bc *BitwiseCalculator) showResult() {
+	if !bc.resultASCII.Checked || bc.resultHex == "" {
+		bc.result.SetText(bc.resultHex)
+		return
+	}
+	data, err := hex.DecodeString(bc.resultHex)
+	if err != nil {
+		bc.result.SetText(bc.resultHex)
+		return
+	}
+	bc.result.SetText(utils.PrintableASCII(data))
+}
+
+// onOperationChanged hides Block B for NOT, which takes a single operand.
+func (bc *BitwiseCalculator) onOperationChanged(op string) {
+	if op == string(crypto.NOT) {
+		bc.blockB.SetText("")
+		bc.blockB.Hide()
+		bc.blockBLen.Hide()
+	} else {
+		bc.blockB.Show()
+		bc.blockBLen.Show()
+	}
+	bc.updateOperandInfo()
+}
+
+// updateOperandInfo refreshes the operand byte counts, flags mismatched
+// lengths and enables Calculate only when the operands can be combined.
+func (bc *BitwiseCalculator) updateOperandInfo() {
+	a := operandHex(bc.blockA, bc.asciiA)
+	b := operandHex(bc.blockB, bc.asciiB)
+	bc.blockALen.SetText(byteCountText(a))
+	bc.blockBLen.SetText(byteCountText(b))
+
+	mismatch := bc.operation.Selected != string(crypto.NOT) && len(a) != len(b)
+
+	importance := widget.MediumImportance
+	if mismatch {
+		importance = widget.DangerImportance
+		bc.calculateBtn.Disable()
+	} else {
+		bc.calculateBtn.Enable()
+	}
+	bc.blockALen.Importance = importance
+	bc.blockBLen.Importance = importance
+	bc.blockALen.Refresh()
+	bc.blockBLen.Refresh()
+}
+
+// byteCountText describes the length of a hex operand in bytes.
+func byteCountText(hexStr string) string {
+	if len(hexStr)%2 != 0 {
+		return fmt.Sprintf("%d bytes + 1 digit", len(hexStr)/2)
+	}
+
+	return fmt.Sprintf("%d bytes", len(hexStr)/2)
+}
+
+// newParityLabel creates a hidden parity status label.
+func newParityLabel() *widget.Label {
+	l := widget.NewLabel("")
+	l.Hide()
+
+	return l
+}
+
+// updateParityLabel shows the parity status of a hex key on label. The label
+// is hidden unless the key has a DES length and its entry is visible; keys
+// without odd parity are highlighted.
+func updateParityLabel(label *widget.Label, hexKey string, visible bool) {
+	if !visible || !isDESKeyLength(hexKey) {
+		label.Hide()
+		return
+	}
+	key, err := hex.DecodeString(hexKey)
+	if err != nil {
+		label.Hide()
+		return
+	}
+
+	text, odd := parityText(crypto.CheckOddParity(key), len(key))
+	label.Importance = widget.MediumImportance
+	if !odd {
+		label.Importance = widget.WarningImportance
+	}
+	label.SetText(text)
+	label.Show()
+}
+
+// parityText describes the parity of an n-byte key given the indexes of its
+// even parity bytes, and reports whether all bytes have odd parity.
+func parityText(even []int, n int) (string, bool) {
+	switch len(even) {
+	case 0:
+		return "parity: odd", true
+	case n:
+		return "parity: even", false
+	}
+
+	positions := make([]string, len(even))
+	for i, idx := range even {
+		positions[i] = strconv.Itoa(idx + 1)
+	}
+
+	return fmt.Sprintf("parity: mixed (bytes %s)", strings.Join(positions, ",")), false
+}
+
+// isDESKeyLength reports whether hexStr is a single, double or triple length
+// DES key.
+func isDESKeyLength(hexStr string) bool {
+	return len(hexStr) == 16 || len(hexStr) == 32 || len(hexStr) == 48
 }
 
 // onSplit handles splitting the combined key into components.
@@ -292,8 +634,7 @@ func (bc *BitwiseCalculator) onSplit() {
 	parity := bc.parityBits.Selected
 
 	combined := bc.combinedKey.Text
-	_, err := hex.DecodeString(combined)
-	if err != nil {
+	if err := utils.ValidateHexLenient(combined); err != nil {
 		bc.combinedKCV.SetText("KCV: Invalid Key")
 		return
 	}
@@ -330,8 +671,10 @@ func (bc *BitwiseCalculator) onSplit() {
 	// AES-256 combined KCV N/A
 	if data, _ := hex.DecodeString(combined); len(data) == 32 {
 		bc.combinedKCV.SetText("KCV: N/A")
+		crypto.AuditKeySplit(num, "N/A")
 	} else {
 		bc.combinedKCV.SetText("KCV: " + strings.ToUpper(origKCVHexStr))
+		crypto.AuditKeySplit(num, origKCVHexStr)
 	}
 
 	if len(components) > 0 {
@@ -424,8 +767,7 @@ func (bc *BitwiseCalculator) onCombine() {
 	}
 
 	for i, c := range dcomps {
-		_, err := hex.DecodeString(c)
-		if err != nil {
+		if err := utils.ValidateHexLenient(c); err != nil {
 			bc.combinedKey.SetText("")
 			bc.combinedKCV.SetText(fmt.Sprintf("KCV: Comp %d Invalid", i+1))
 			return
@@ -448,6 +790,7 @@ func (bc *BitwiseCalculator) onCombine() {
 	// AES-256 combined KCV N/A
 	if len(data) == 32 {
 		bc.combinedKCV.SetText("KCV: N/A")
+		crypto.AuditKeyCombine(dcomps, "N/A")
 		bc.container.Refresh()
 		return
 	}
@@ -458,46 +801,318 @@ func (bc *BitwiseCalculator) onCombine() {
 		return
 	}
 	bc.combinedKCV.SetText("KCV: " + strings.ToUpper(kcv))
+	crypto.AuditKeyCombine(dcomps, kcv)
+	bc.verifyExpectedKCV(data)
 
 	bc.container.Refresh()
 }
 
-// validateHex checks if the input is valid hexadecimal, enforces maxLength, and calculates KCV.
-func (bc *BitwiseCalculator) validateHex(originalS string, entry *widget.Entry, maxLength int) {
-	processedS := strings.Builder{}
-	processedS.Grow(len(originalS))
-	for _, r := range originalS {
-		if (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') {
-			_, _ = processedS.WriteRune(r)
+// onLoadFromStore lets the user pick a stored key and places its value in the
+// combined key field.
+func (bc *BitwiseCalculator) onLoadFromStore() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	entries := bc.store.ListWithValues()
+	if len(entries) == 0 {
+		dialog.ShowInformation("Load from store", "No stored keys with a value.", w)
+		return
+	}
+
+	options := make([]string, len(entries))
+	for i, e := range entries {
+		options[i] = fmt.Sprintf("%s (%s, KCV %s)", e.Name, e.Type, e.CheckValue)
+	}
+	picker := widget.NewSelect(options, nil)
+	picker.SetSelectedIndex(0)
+
+	dialog.ShowCustomConfirm("Load from store", "Load", "Cancel", picker, func(ok bool) {
+		if !ok || picker.SelectedIndex() < 0 {
+			return
+		}
+		entry := entries[picker.SelectedIndex()]
+		bc.sourceEntry = entry
+		bc.combinedKey.SetText(entry.Value)
+	}, w)
+}
+
+// onSaveComponents stores each entered component as a key entry named after
+// the chosen base name. Only the metadata is stored unless the user asks for
+// the clear values and confirms it.
+func (bc *BitwiseCalculator) onSaveComponents() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	comps, err := bc.enteredComponents()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	name := widget.NewEntry()
+	name.SetText(bc.sourceEntry.Name)
+	name.Validator = func(s string) error {
+		// The longest suffixed name must be valid too.
+		return utils.ValidateKeyName(storage.ComponentName(s, len(comps)))
+	}
+	withValues := widget.NewCheck("Store clear component values", nil)
+
+	dialog.ShowForm("Save components", "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Base name", name),
+		widget.NewFormItem("", withValues),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		kcvs := make([]string, len(comps))
+		for i, c := range comps {
+			kcvs[i] = componentKCV(c)
+		}
+		entries, err := storage.ComponentEntries(name.Text, bc.sourceEntry, comps, kcvs, withValues.Checked)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if !withValues.Checked {
+			bc.confirmOverwrite(entries)
+			return
+		}
+		dialog.ShowConfirm(
+			"Store clear components",
+			"The clear components will be written unencrypted to the key store file. Continue?",
+			func(ok bool) {
+				if ok {
+					bc.confirmOverwrite(entries)
+				}
+			},
+			w,
+		)
+	}, w)
+}
+
+// confirmOverwrite stores the component entries, asking first when some of
+// them already exist.
+func (bc *BitwiseCalculator) confirmOverwrite(entries []storage.KeyEntry) {
+	var existing []string
+	for _, e := range entries {
+		if _, ok := bc.store.Get(e.Name); ok {
+			existing = append(existing, e.Name)
+		}
+	}
+	if len(existing) == 0 {
+		bc.storeComponents(entries)
+		return
+	}
+
+	dialog.ShowConfirm(
+		"Overwrite keys?",
+		fmt.Sprintf("These keys will be overwritten: %s", strings.Join(existing, ", ")),
+		func(ok bool) {
+			if ok {
+				bc.storeComponents(entries)
+			}
+		},
+		fyne.CurrentApp().Driver().AllWindows()[0],
+	)
+}
+
+// storeComponents stores all component entries with a single save, so a
+// failure leaves none of them stored.
+func (bc *BitwiseCalculator) storeComponents(entries []storage.KeyEntry) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	if err := bc.store.StoreAll(entries); err != nil {
+		dialog.ShowError(fmt.Errorf("failed to store components, nothing was changed: %v", err), w)
+		return
+	}
+	dialog.ShowInformation(
+		"Components saved",
+		fmt.Sprintf("Stored %d components, %s to %s", len(entries), entries[0].Name, entries[len(entries)-1].Name),
+		w,
+	)
+}
+
+// enteredComponents returns the components in use, failing on empty ones.
+func (bc *BitwiseCalculator) enteredComponents() ([]string, error) {
+	comps := []string{bc.comp1.Text, bc.comp2.Text}
+	if bc.numComponents.Selected == "3" {
+		comps = append(comps, bc.comp3.Text)
+	}
+	for i, c := range comps {
+		if c == "" {
+			return nil, fmt.Errorf("component %d is empty", i+1)
+		}
+	}
+
+	return comps, nil
+}
+
+// onExportComponents writes one mailer file per component to a folder chosen
+// by the user.
+func (bc *BitwiseCalculator) onExportComponents() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	mailers, combinedKCV, err := bc.componentMailers(time.Now())
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if uri == nil {
+			return // Cancelled.
 		}
+		bc.writeMailers(uri.Path(), mailers, combinedKCV, false)
+	}, w)
+}
+
+// writeMailers writes the mailers to dir, asking before overwriting files.
+func (bc *BitwiseCalculator) writeMailers(
+	dir string,
+	mailers []ceremony.Mailer,
+	combinedKCV string,
+	overwrite bool,
+) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	paths, err := ceremony.WriteMailers(dir, bc.exportPattern.Text, mailers, overwrite)
+	if errors.Is(err, ceremony.ErrFilesExist) {
+		dialog.ShowConfirm("Overwrite files?", err.Error(), func(ok bool) {
+			if ok {
+				bc.writeMailers(dir, mailers, combinedKCV, true)
+			}
+		}, w)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
 	}
 
-	hexInput := processedS.String()
+	crypto.AuditComponentsExport(len(paths), combinedKCV, dir)
+	dialog.ShowInformation(
+		"Components exported",
+		fmt.Sprintf("Wrote %d files to %s", len(paths), dir),
+		w,
+	)
+}
+
+// componentMailers builds a mailer for each entered component. The combined
+// KCV is derived from the components, so the combined key is never written.
+func (bc *BitwiseCalculator) componentMailers(date time.Time) ([]ceremony.Mailer, string, error) {
+	comps, err := bc.enteredComponents()
+	if err != nil {
+		return nil, "", err
+	}
+
+	keyHex, err := crypto.CombineComponents(comps)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to combine components: %v", err)
+	}
+	combinedKCV := componentKCV(keyHex)
+
+	mailers := make([]ceremony.Mailer, len(comps))
+	for i, c := range comps {
+		mailers[i] = ceremony.Mailer{
+			Index:        i + 1,
+			Total:        len(comps),
+			Component:    c,
+			ComponentKCV: componentKCV(c),
+			CombinedKCV:  combinedKCV,
+			KeyBits:      len(c) * 4,
+			Date:         date,
+		}
+	}
+
+	return mailers, combinedKCV, nil
+}
+
+// componentKCV returns the KCV of a hex key, "N/A" for AES-256 keys and
+// "ERROR" when none can be computed.
+func componentKCV(keyHex string) string {
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return "ERROR"
+	}
+	if len(key) == 32 {
+		return "N/A"
+	}
+	kcv, err := crypto.CalculateKCV(key)
+	if err != nil {
+		return "ERROR"
+	}
+
+	return kcv
+}
+
+// verifyExpectedKCV compares the check value of key with the expected KCV, if
+// one was entered, and shows the outcome.
+func (bc *BitwiseCalculator) verifyExpectedKCV(key []byte) {
+	expected := strings.ToUpper(bc.expectedKCV.Text)
+	if expected == "" {
+		bc.setKCVMatch("", widget.MediumImportance)
+		return
+	}
+
+	got, err := crypto.CalculateCheckValue(key, len(expected))
+	if err != nil {
+		bc.setKCVMatch("Expected KCV must be 4, 6 or 16 digits", widget.WarningImportance)
+		return
+	}
+	if got != expected {
+		bc.setKCVMatch(
+			fmt.Sprintf("MISMATCH (got %s, expected %s)", got, expected),
+			widget.DangerImportance,
+		)
+		return
+	}
+	bc.setKCVMatch("MATCH", widget.SuccessImportance)
+}
+
+// setKCVMatch updates the expected KCV outcome label.
+func (bc *BitwiseCalculator) setKCVMatch(text string, importance widget.Importance) {
+	bc.kcvMatch.Importance = importance
+	bc.kcvMatch.SetText(text)
+}
+
+// validateHex checks if the input is valid hexadecimal, enforces maxLength, and calculates KCV.
+func (bc *BitwiseCalculator) validateHex(originalS string, entry *widget.Entry, maxLength int) {
+	// Normalize first so a pasted 0x prefix and separators are stripped
+	// whole, then drop anything that is still not a hex digit and truncate.
+	clean, _ := utils.NormalizeHexInput(originalS, 0)
+	hexInput := strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || (r >= 'A' && r <= 'F') {
+			return r
+		}
+		return -1
+	}, clean)
 	if len(hexInput) > maxLength {
 		hexInput = hexInput[:maxLength]
 	}
 
-	hexInput = strings.ToUpper(hexInput)
-
 	if entry.Text != hexInput {
 		entry.SetText(hexInput)
 	}
 
-	var kcvLabel *widget.Label
+	var kcvLabel, parityLabel *widget.Label
 	switch entry {
 	case bc.combinedKey:
-		kcvLabel = bc.combinedKCV
+		kcvLabel, parityLabel = bc.combinedKCV, bc.combinedPar
 	case bc.comp1:
-		kcvLabel = bc.comp1KCV
+		kcvLabel, parityLabel = bc.comp1KCV, bc.comp1Par
 	case bc.comp2:
-		kcvLabel = bc.comp2KCV
+		kcvLabel, parityLabel = bc.comp2KCV, bc.comp2Par
 	case bc.comp3:
-		kcvLabel = bc.comp3KCV
+		kcvLabel, parityLabel = bc.comp3KCV, bc.comp3Par
 	case bc.blockA, bc.blockB:
+		bc.updateOperandInfo()
 		return
 	default:
 		return
 	}
+	updateParityLabel(parityLabel, hexInput, entry.Visible())
 
 	data, err := hex.DecodeString(hexInput)
 	if err != nil || len(data) == 0 {
@@ -544,8 +1159,10 @@ func (bc *BitwiseCalculator) onGenerateKey(bitLen int) func() {
 		// Display combined KCV or N/A for AES-256
 		if bitLen == 256 {
 			bc.combinedKCV.SetText("KCV: N/A")
+			crypto.AuditKeyGenerate(bitLen, enforceOddParity, "N/A")
 		} else {
 			bc.combinedKCV.SetText("KCV: " + strings.ToUpper(combinedKCVHexStr))
+			crypto.AuditKeyGenerate(bitLen, enforceOddParity, combinedKCVHexStr)
 		}
 
 		// Split the key - components will have same parity as original key
@@ -651,6 +1268,7 @@ func (bc *BitwiseCalculator) clearKCVs() {
 	bc.comp1KCV.SetText("KCV:")
 	bc.comp2KCV.SetText("KCV:")
 	bc.comp3KCV.SetText("KCV:")
+	bc.setKCVMatch("", widget.MediumImportance)
 }
 
 // onNumComponentsChanged handles visibility of component 3 inputs.
@@ -659,10 +1277,12 @@ func (bc *BitwiseCalculator) onNumComponentsChanged(value string) {
 		bc.comp3Label.Show()
 		bc.comp3.Show()
 		bc.comp3KCV.Show()
+		updateParityLabel(bc.comp3Par, bc.comp3.Text, true)
 	} else {
 		bc.comp3Label.Hide()
 		bc.comp3.Hide()
 		bc.comp3KCV.Hide()
+		bc.comp3Par.Hide()
 	}
 
 	if bc.container != nil {
@@ -740,8 +1360,13 @@ func (bc *BitwiseCalculator) Cleanup() {
 	bc.blockA.SetText("")
 	bc.blockB.SetText("")
 	bc.result.SetText("")
+	bc.resultHex = ""
+	bc.resultKCV.SetText("")
+	bc.history.clear()
+	bc.historyList.Refresh()
 
 	bc.clearKeySharingFields()
+	bc.expectedKCV.SetText("")
 
 	bc.numComponents.SetSelected("2")
 	bc.parityBits.SetSelected("Ignore")
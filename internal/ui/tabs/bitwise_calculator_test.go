@@ -0,0 +1,289 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestBitwiseCalculator_LongOperands(t *testing.T) {
+	tests := []struct {
+		name    string
+		op      string
+		a       string
+		b       string
+		want    string
+		wantKCV bool
+	}{
+		{
+			name:    "xor_32_digits",
+			op:      "XOR",
+			a:       "0123456789ABCDEFFEDCBA9876543210",
+			b:       "11111111111111112222222222222222",
+			want:    "1032547698BADCFEDCFE98BA54761032",
+			wantKCV: true,
+		},
+		{
+			name:    "xor_48_digits",
+			op:      "XOR",
+			a:       "0123456789ABCDEFFEDCBA98765432100011223344556677",
+			b:       "111111111111111122222222222222228899AABBCCDDEEFF",
+			want:    "1032547698BADCFEDCFE98BA547610328888888888888888",
+			wantKCV: true,
+		},
+		{
+			name:    "xor_non_key_length",
+			op:      "XOR",
+			a:       "0123456789",
+			b:       "1111111111",
+			want:    "1032547698",
+			wantKCV: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.operation.SetSelected(tt.op)
+			bc.blockA.SetText(tt.a)
+			bc.blockB.SetText(tt.b)
+			bc.onCalculate()
+
+			if bc.result.Text != tt.want {
+				t.Errorf("result = %q, want %q", bc.result.Text, tt.want)
+			}
+			if gotKCV := strings.HasPrefix(bc.resultKCV.Text, "KCV: "); gotKCV != tt.wantKCV {
+				t.Errorf("resultKCV = %q, want KCV shown %v", bc.resultKCV.Text, tt.wantKCV)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_OperandLimit(t *testing.T) {
+	test.NewTempApp(t)
+
+	bc := NewBitwiseCalculator(nil)
+	bc.blockA.SetText(strings.Repeat("A", maxOperandHexDigits+10))
+
+	if got := len(bc.blockA.Text); got != maxOperandHexDigits {
+		t.Errorf("operand length = %d, want %d", got, maxOperandHexDigits)
+	}
+}
+
+func TestBitwiseCalculator_PastedHex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"prefix_only", "0x", ""},
+		{"upper_prefix", "0XABCD", "ABCD"},
+		{"prefix_and_separators", " 0x01:23-45 67", "01234567"},
+		{"lower_case", "abcdef", "ABCDEF"},
+		{"other_characters_dropped", "0x12zz34", "1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.comp1.SetText(tt.input)
+			bc.blockA.SetText(tt.input)
+
+			if bc.comp1.Text != tt.want {
+				t.Errorf("component = %q, want %q", bc.comp1.Text, tt.want)
+			}
+			if bc.blockA.Text != tt.want {
+				t.Errorf("operand = %q, want %q", bc.blockA.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_NotHidesBlockB(t *testing.T) {
+	test.NewTempApp(t)
+
+	bc := NewBitwiseCalculator(nil)
+	test.NewTempWindow(t, bc)
+
+	bc.blockB.SetText("FFFF")
+	bc.operation.SetSelected("NOT")
+	if bc.blockB.Visible() || bc.blockBLen.Visible() {
+		t.Error("Block B visible for NOT")
+	}
+	if bc.blockB.Text != "" {
+		t.Errorf("Block B not cleared, got %q", bc.blockB.Text)
+	}
+
+	bc.operation.SetSelected("XOR")
+	if !bc.blockB.Visible() || !bc.blockBLen.Visible() {
+		t.Error("Block B hidden for XOR")
+	}
+}
+
+func TestBitwiseCalculator_LengthMismatch(t *testing.T) {
+	tests := []struct {
+		name         string
+		op           string
+		a            string
+		b            string
+		wantDisabled bool
+		wantALen     string
+	}{
+		{"equal_lengths", "XOR", "0123", "4567", false, "2 bytes"},
+		{"mismatch", "AND", "012345", "4567", true, "3 bytes"},
+		{"odd_digits", "OR", "012", "4567", true, "1 bytes + 1 digit"},
+		{"not_ignores_b", "NOT", "012345", "", false, "3 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.operation.SetSelected(tt.op)
+			bc.blockA.SetText(tt.a)
+			bc.blockB.SetText(tt.b)
+
+			if bc.calculateBtn.Disabled() != tt.wantDisabled {
+				t.Errorf("Calculate disabled = %v, want %v", bc.calculateBtn.Disabled(), tt.wantDisabled)
+			}
+			if bc.blockALen.Text != tt.wantALen {
+				t.Errorf("Block A length = %q, want %q", bc.blockALen.Text, tt.wantALen)
+			}
+			wantDanger := tt.wantDisabled
+			if (bc.blockALen.Importance == widget.DangerImportance) != wantDanger {
+				t.Errorf("length label danger = %v, want %v", !wantDanger, wantDanger)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_CombineExpectedKCV(t *testing.T) {
+	tests := []struct {
+		name     string
+		comp2    string
+		expected string
+		want     string
+		wantImp  widget.Importance
+	}{
+		{"no_expectation", "0000000000000000", "", "", widget.MediumImportance},
+		{"match_six", "0000000000000000", "D5D44F", "MATCH", widget.SuccessImportance},
+		{"match_four_lowercase", "0000000000000000", "d5d4", "MATCH", widget.SuccessImportance},
+		{"match_sixteen", "0000000000000000", "D5D44FF720683D0D", "MATCH", widget.SuccessImportance},
+		{
+			"wrong_component", "1111111111111111", "D5D44F",
+			"MISMATCH (got ", widget.DangerImportance,
+		},
+		{
+			"bad_length", "0000000000000000", "D5D44",
+			"Expected KCV must be 4, 6 or 16 digits", widget.WarningImportance,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.modeToggle.SetSelected("Key Sharing")
+			bc.comp1.SetText("0123456789ABCDEF")
+			bc.comp2.SetText(tt.comp2)
+			bc.expectedKCV.SetText(tt.expected)
+			bc.onCombine()
+
+			if !strings.HasPrefix(bc.kcvMatch.Text, tt.want) || (tt.want == "" && bc.kcvMatch.Text != "") {
+				t.Errorf("kcvMatch = %q, want prefix %q", bc.kcvMatch.Text, tt.want)
+			}
+			if bc.kcvMatch.Importance != tt.wantImp {
+				t.Errorf("kcvMatch importance = %v, want %v", bc.kcvMatch.Importance, tt.wantImp)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_ParityIndicator(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		wantVisible bool
+		wantText    string
+		wantImp     widget.Importance
+	}{
+		{"odd", "0123456789ABCDEF", true, "parity: odd", widget.MediumImportance},
+		{"one_bad_byte", "0123456789ABCDEE", true, "parity: mixed (bytes 8)", widget.WarningImportance},
+		{"all_even", "0022446688AACCEE", true, "parity: even", widget.WarningImportance},
+		{"non_des_length", "0123456789", false, "", widget.MediumImportance},
+		{"aes_256", strings.Repeat("01", 32), false, "", widget.MediumImportance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.comp1.SetText(tt.key)
+
+			if bc.comp1Par.Visible() != tt.wantVisible {
+				t.Fatalf("parity visible = %v, want %v", bc.comp1Par.Visible(), tt.wantVisible)
+			}
+			if !tt.wantVisible {
+				return
+			}
+			if bc.comp1Par.Text != tt.wantText {
+				t.Errorf("parity = %q, want %q", bc.comp1Par.Text, tt.wantText)
+			}
+			if bc.comp1Par.Importance != tt.wantImp {
+				t.Errorf("parity importance = %v, want %v", bc.comp1Par.Importance, tt.wantImp)
+			}
+			if !strings.HasPrefix(bc.comp1KCV.Text, "KCV: ") || len(bc.comp1KCV.Text) != len("KCV: ")+6 {
+				t.Errorf("KCV label = %q, want a computed KCV", bc.comp1KCV.Text)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_ASCIIOperands(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         string
+		b         string
+		showASCII bool
+		want      string
+	}{
+		{"mixed_ascii_hex", "AB", "0303", false, "4241"},
+		{"ascii_result", "AB", "0303", true, "BA"},
+		{"non_printable_result", "AZ", "415A", true, ".."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			bc := NewBitwiseCalculator(nil)
+			bc.operation.SetSelected("XOR")
+			bc.asciiA.SetChecked(true)
+			bc.blockA.SetText(tt.a)
+			bc.blockB.SetText(tt.b)
+			bc.resultASCII.SetChecked(tt.showASCII)
+
+			if bc.blockA.Text != tt.a {
+				t.Fatalf("ASCII operand filtered to %q", bc.blockA.Text)
+			}
+			if bc.blockALen.Text != bc.blockBLen.Text {
+				t.Errorf("byte counts %q and %q differ", bc.blockALen.Text, bc.blockBLen.Text)
+			}
+
+			bc.onCalculate()
+			if bc.result.Text != tt.want {
+				t.Errorf("result = %q, want %q", bc.result.Text, tt.want)
+			}
+		})
+	}
+}
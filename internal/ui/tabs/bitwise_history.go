@@ -0,0 +1,78 @@
+package tabs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+)
+
+// maxBitwiseHistory is the number of operations kept by the Bitwise Calculator.
+const maxBitwiseHistory = 50
+
+// bitwiseOperators maps bitwise operations to the symbols shown in history.
+var bitwiseOperators = map[crypto.BitwiseOperation]string{
+	crypto.XOR: "⊕",
+	crypto.AND: "∧",
+	crypto.OR:  "∨",
+	crypto.NOT: "¬",
+}
+
+// bitwiseHistoryEntry records one Regular mode operation. Operands and the
+// result are hex strings.
+type bitwiseHistoryEntry struct {
+	Time      time.Time
+	Operation crypto.BitwiseOperation
+	A         string
+	B         string
+	Result    string
+}
+
+// String formats the entry as "15:04:05  A ⊕ B = R".
+func (e bitwiseHistoryEntry) String() string {
+	symbol, ok := bitwiseOperators[e.Operation]
+	if !ok {
+		symbol = string(e.Operation)
+	}
+	expr := fmt.Sprintf("%s %s %s", e.A, symbol, e.B)
+	if e.Operation == crypto.NOT {
+		expr = symbol + e.A
+	}
+
+	return fmt.Sprintf("%s  %s = %s", e.Time.Format("15:04:05"), expr, e.Result)
+}
+
+// bitwiseHistory is an in-memory list of recent operations, newest first.
+// It holds key material and is never persisted.
+type bitwiseHistory struct {
+	entries []bitwiseHistoryEntry
+}
+
+// add records e, dropping the oldest entry once the history is full.
+func (h *bitwiseHistory) add(e bitwiseHistoryEntry) {
+	h.entries = append([]bitwiseHistoryEntry{e}, h.entries...)
+	if len(h.entries) > maxBitwiseHistory {
+		h.entries[maxBitwiseHistory] = bitwiseHistoryEntry{}
+		h.entries = h.entries[:maxBitwiseHistory]
+	}
+}
+
+// len returns the number of recorded operations.
+func (h *bitwiseHistory) len() int {
+	return len(h.entries)
+}
+
+// entry returns the i-th most recent operation.
+func (h *bitwiseHistory) entry(i int) (bitwiseHistoryEntry, bool) {
+	if i < 0 || i >= len(h.entries) {
+		return bitwiseHistoryEntry{}, false
+	}
+
+	return h.entries[i], true
+}
+
+// clear forgets all recorded operations.
+func (h *bitwiseHistory) clear() {
+	clear(h.entries)
+	h.entries = nil
+}
@@ -0,0 +1,88 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+)
+
+func TestBitwiseHistory(t *testing.T) {
+	var h bitwiseHistory
+	for i := 0; i < maxBitwiseHistory+5; i++ {
+		h.add(bitwiseHistoryEntry{Operation: crypto.XOR, A: fmt.Sprintf("%02X", i), B: "FF"})
+	}
+
+	if h.len() != maxBitwiseHistory {
+		t.Fatalf("len() = %d, want %d", h.len(), maxBitwiseHistory)
+	}
+	newest, _ := h.entry(0)
+	if want := fmt.Sprintf("%02X", maxBitwiseHistory+4); newest.A != want {
+		t.Errorf("entry(0).A = %q, want %q", newest.A, want)
+	}
+	oldest, _ := h.entry(maxBitwiseHistory - 1)
+	if oldest.A != "05" {
+		t.Errorf("oldest entry A = %q, want %q", oldest.A, "05")
+	}
+	if _, ok := h.entry(maxBitwiseHistory); ok {
+		t.Error("entry() beyond the cap returned an entry")
+	}
+
+	h.clear()
+	if h.len() != 0 {
+		t.Errorf("len() after clear = %d, want 0", h.len())
+	}
+}
+
+func TestBitwiseHistoryEntry_String(t *testing.T) {
+	at := time.Date(2026, 1, 2, 13, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry bitwiseHistoryEntry
+		want  string
+	}{
+		{"xor", bitwiseHistoryEntry{at, crypto.XOR, "0F", "F0", "FF"}, "13:04:05  0F ⊕ F0 = FF"},
+		{"and", bitwiseHistoryEntry{at, crypto.AND, "0F", "FF", "0F"}, "13:04:05  0F ∧ FF = 0F"},
+		{"not", bitwiseHistoryEntry{at, crypto.NOT, "0F", "", "F0"}, "13:04:05  ¬0F = F0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBitwiseCalculator_HistoryReuse(t *testing.T) {
+	test.NewTempApp(t)
+
+	bc := NewBitwiseCalculator(nil)
+	bc.operation.SetSelected("XOR")
+	bc.blockA.SetText("0F0F")
+	bc.blockB.SetText("00FF")
+	bc.onCalculate()
+	bc.operation.SetSelected("AND")
+	bc.blockA.SetText("1234")
+	bc.blockB.SetText("FFFF")
+	bc.onCalculate()
+
+	if bc.history.len() != 2 {
+		t.Fatalf("history len = %d, want 2", bc.history.len())
+	}
+
+	bc.reuseHistory(1)
+	if bc.operation.Selected != "XOR" || bc.blockA.Text != "0F0F" || bc.blockB.Text != "00FF" {
+		t.Errorf("reuse loaded %s %q %q", bc.operation.Selected, bc.blockA.Text, bc.blockB.Text)
+	}
+
+	bc.Cleanup()
+	if bc.history.len() != 0 {
+		t.Errorf("history len after Cleanup = %d, want 0", bc.history.len())
+	}
+}
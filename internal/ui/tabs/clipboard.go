@@ -0,0 +1,49 @@
+package tabs
+
+import (
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// copyFeedbackDuration is how long a copy button shows its confirmation icon.
+const copyFeedbackDuration = time.Second
+
+// afterCopyFeedback runs reset on the UI goroutine once the confirmation
+// icon has been shown. Tests replace it to reset without a timer.
+var afterCopyFeedback = func(reset func()) {
+	time.AfterFunc(copyFeedbackDuration, func() { fyne.Do(reset) })
+}
+
+// copyToClipboard writes text to the application clipboard.
+// All copy actions go through here so clipboard policies apply in one place.
+func copyToClipboard(text string) {
+	fyne.CurrentApp().Clipboard().SetContent(text)
+}
+
+// newCopyButton returns an icon button that copies the value returned by get.
+// The icon briefly switches to a check mark to confirm the copy.
+func newCopyButton(get func() string) *widget.Button {
+	btn := widget.NewButtonWithIcon("", theme.ContentCopyIcon(), nil)
+	btn.Importance = widget.LowImportance
+	btn.OnTapped = func() { copyWithFeedback(btn, get()) }
+
+	return btn
+}
+
+// copyWithFeedback copies text and briefly shows a check mark on btn.
+func copyWithFeedback(btn *widget.Button, text string) {
+	copyToClipboard(text)
+	btn.SetIcon(theme.ConfirmIcon())
+	afterCopyFeedback(func() { btn.SetIcon(theme.ContentCopyIcon()) })
+}
+
+// kcvText returns the check value shown by a "KCV: ..." label.
+func kcvText(label *widget.Label) func() string {
+	return func() string {
+		return strings.TrimSpace(strings.TrimPrefix(label.Text, "KCV:"))
+	}
+}
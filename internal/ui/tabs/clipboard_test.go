@@ -0,0 +1,60 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestCopyToClipboard(t *testing.T) {
+	app := test.NewTempApp(t)
+
+	copyToClipboard("0123456789ABCDEF")
+	if got := app.Clipboard().Content(); got != "0123456789ABCDEF" {
+		t.Errorf("clipboard = %q, want %q", got, "0123456789ABCDEF")
+	}
+}
+
+func TestNewCopyButton(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		want  string
+	}{
+		{"kcv_value", "KCV: A1B2C3", "A1B2C3"},
+		{"kcv_empty", "KCV: ", ""},
+		{"plain_text", "FEDCBA", "FEDCBA"},
+	}
+
+	var reset func()
+	orig := afterCopyFeedback
+	afterCopyFeedback = func(f func()) { reset = f }
+	defer func() { afterCopyFeedback = orig }()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := test.NewTempApp(t)
+
+			reset = nil
+			btn := newCopyButton(kcvText(widget.NewLabel(tt.label)))
+			test.Tap(btn)
+
+			if got := app.Clipboard().Content(); got != tt.want {
+				t.Errorf("clipboard = %q, want %q", got, tt.want)
+			}
+			if btn.Icon != theme.ConfirmIcon() {
+				t.Errorf("icon not switched to confirmation after copy")
+			}
+			if reset == nil {
+				t.Fatal("icon reset not scheduled")
+			}
+			reset()
+			if btn.Icon != theme.ContentCopyIcon() {
+				t.Errorf("icon not reset after the confirmation")
+			}
+		})
+	}
+}
@@ -4,18 +4,23 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	descrypto "github.com/andrei-cloud/hsmtool/internal/backend/crypto"
 	"github.com/andrei-cloud/hsmtool/pkg/utils"
 )
 
+// maxDataFileSize is the file size above which loading asks for confirmation.
+const maxDataFileSize = 5 << 20
+
 var (
 	// PaddingModes available for DES operations.
 	PaddingModes = []string{"None", "ISO 9797-1 Method 1", "ISO 9797-1 Method 2"}
@@ -25,6 +30,22 @@ var (
 
 	// Operations available for DES calculator.
 	Operations = []string{"Encrypt", "Decrypt"}
+
+	// paddingModeByName maps each PaddingModes option to its backend mode.
+	paddingModeByName = map[string]descrypto.PaddingMode{
+		"None":                descrypto.NoPadding,
+		"ISO 9797-1 Method 1": descrypto.ISO97971,
+		"ISO 9797-1 Method 2": descrypto.ISO97972,
+	}
+
+	// cipherModeByName maps each CipherModes option to its backend mode.
+	cipherModeByName = map[string]descrypto.CipherMode{
+		"ECB": descrypto.ECB,
+		"CBC": descrypto.CBC,
+	}
+
+	// processDES is the backend DES routine, replaceable in tests.
+	processDES = descrypto.ProcessDES
 )
 
 // DESCalculator represents the DES Calculator tab.
@@ -34,17 +55,39 @@ type DESCalculator struct {
 	form      *widget.Form // Added form field for grouped dropdowns
 
 	// Input fields.
-	dataInput   *widget.Entry
-	keyInput    *widget.Entry
-	padding     *widget.Select
-	mode        *widget.Select
-	operation   *widget.Select
-	ivInput     *widget.Entry   // iv input for CBC mode
-	ivContainer *fyne.Container // container for iv row
+	dataInput    *widget.Entry
+	keyInput     *widget.Entry
+	padding      *widget.Select
+	mode         *widget.Select
+	operation    *widget.Select
+	inputFormat  *widget.Select  // format of dataInput
+	outputFormat *widget.Select  // format of result
+	ivInput      *widget.Entry   // iv input for CBC mode
+	ivContainer  *fyne.Container // container for iv row
+
+	// Separate K1/K2/K3 entry; keyInput always holds the composite key.
+	separateParts *widget.Check
+	keyPartInputs [3]*widget.Entry
+	keyEntryBox   *fyne.Container // single key entry
+	keyPartsBox   *fyne.Container // K1..K3 entries
+	splittingKey  bool            // set while parts are filled from keyInput
+
+	// Inline validation hints; Calculate is disabled while any is shown.
+	dataHint     *widget.Label
+	keyHint      *widget.Label
+	ivHint       *widget.Label
+	calculateBtn *widget.Button
 
 	// Output fields.
-	kcv    *widget.Label
-	result *widget.Entry
+	kcv        *widget.Label
+	partLabels [3]*widget.Label // KCV and parity of K1..K3
+	singleDES  *widget.Label    // warning for keys with equal parts
+	result     *widget.Entry
+	lastResult []byte // raw bytes of the last successful result
+
+	// Chaining controls.
+	useAsInputBtn *widget.Button
+	swapOperation *widget.Check
 }
 
 // NewDESCalculator creates a new DES Calculator tab.
@@ -52,10 +95,15 @@ func NewDESCalculator() *DESCalculator {
 	c := &DESCalculator{}
 	c.ExtendBaseWidget(c)
 
+	c.dataHint = newHintLabel()
+	c.keyHint = newHintLabel()
+	c.ivHint = newHintLabel()
+
 	// Create IV input for CBC mode first
 	c.ivInput = widget.NewEntry()
 	c.ivInput.SetPlaceHolder("Enter IV in hex format (16 hex digits)")
 	c.ivInput.Resize(fyne.NewSize(320, 36))
+	c.ivInput.OnChanged = func(string) { c.validateInputs() }
 
 	c.ivContainer = container.NewHBox(
 		container.NewGridWrap(fyne.NewSize(60, 36), widget.NewLabel("IV:")),
@@ -64,20 +112,28 @@ func NewDESCalculator() *DESCalculator {
 	)
 
 	// Create Mode/Operation/Padding group items
-	c.mode = widget.NewSelect([]string{"ECB", "CBC"}, func(value string) {
+	c.mode = widget.NewSelect(CipherModes, func(value string) {
 		if value == "CBC" {
 			c.ivContainer.Show()
 		} else {
 			c.ivContainer.Hide()
 		}
+		c.validateInputs()
 	})
 	c.mode.SetSelected("ECB")
 
-	c.operation = widget.NewSelect([]string{"Encrypt", "Decrypt"}, nil)
+	c.operation = widget.NewSelect(Operations, nil)
 	c.operation.SetSelected("Encrypt")
 
-	c.padding = widget.NewSelect([]string{"None", "PKCS7"}, nil)
-	c.padding.SetSelected("None")
+	c.padding = widget.NewSelect(PaddingModes, nil)
+	c.padding.SetSelected(PaddingModes[0])
+
+	c.inputFormat = widget.NewSelect(utils.DataFormats, func(value string) {
+		c.dataInput.SetPlaceHolder(fmt.Sprintf("Enter data in %s format", value))
+		c.validateInputs()
+	})
+	c.outputFormat = widget.NewSelect(utils.DataFormats, nil)
+	c.outputFormat.SetSelected(string(utils.DataHex))
 
 	// Create form with Mode/Operation/Padding group.
 	c.form = &widget.Form{
@@ -85,36 +141,82 @@ func NewDESCalculator() *DESCalculator {
 			{Text: "Mode", Widget: c.mode},
 			{Text: "Operation", Widget: c.operation},
 			{Text: "Padding", Widget: c.padding},
+			{Text: "Input format", Widget: c.inputFormat},
+			{Text: "Output format", Widget: c.outputFormat},
 		},
 	}
 
 	// Create data input field (640px, multi-line).
 	c.dataInput = widget.NewMultiLineEntry()
-	c.dataInput.SetPlaceHolder("Enter data in hex format")
 	c.dataInput.Wrapping = fyne.TextWrapBreak
 	c.dataInput.Resize(fyne.NewSize(640, 100)) // Set initial size
+	c.dataInput.OnChanged = func(string) { c.validateInputs() }
+	c.inputFormat.SetSelected(string(utils.DataHex))
 
 	// Create key input field with proper sizing for 48 hex digits
 	c.keyInput = widget.NewEntry()
 	c.keyInput.SetPlaceHolder("Enter DES key in hex format (16/32/48 hex digits)")
 	c.keyInput.Resize(fyne.NewSize(480, 36))
 	c.keyInput.OnChanged = func(key string) {
+		if clean, changed := utils.NormalizeHexInput(key, 48); changed {
+			c.keyInput.SetText(clean) // Re-enters OnChanged with clean text.
+			return
+		}
 		c.calculateKCV(key)
+		c.validateInputs()
 	}
 
+	// Create separate part entries, 16 hex digits each; K3 is optional.
+	c.keyEntryBox = container.NewGridWrap(fyne.NewSize(480, 36), c.keyInput)
+	c.keyPartsBox = container.NewVBox()
+	for i := range c.keyPartInputs {
+		part := widget.NewEntry()
+		part.SetPlaceHolder(fmt.Sprintf("K%d (16 hex digits)", i+1))
+		part.OnChanged = func(text string) {
+			if clean, changed := utils.NormalizeHexInput(text, 16); changed {
+				part.SetText(clean) // Re-enters OnChanged with clean text.
+				return
+			}
+			c.joinKeyParts()
+		}
+		c.keyPartInputs[i] = part
+		c.keyPartsBox.Add(container.NewHBox(
+			container.NewGridWrap(fyne.NewSize(60, 36), widget.NewLabel(fmt.Sprintf("K%d:", i+1))),
+			container.NewGridWrap(fyne.NewSize(240, 36), part),
+		))
+	}
+	c.keyPartInputs[2].SetPlaceHolder("K3 (16 hex digits, optional)")
+	c.keyPartsBox.Hide()
+	c.separateParts = widget.NewCheck("Separate parts", c.onSeparatePartsToggled)
+
 	// Create KCV label
 	c.kcv = widget.NewLabelWithStyle("", fyne.TextAlignCenter, fyne.TextStyle{})
 
+	// Create per-part labels, shown for double and triple length keys.
+	keyParts := container.NewVBox()
+	for i := range c.partLabels {
+		c.partLabels[i] = widget.NewLabel("")
+		c.partLabels[i].Hide()
+		keyParts.Add(c.partLabels[i])
+	}
+	c.singleDES = widget.NewLabel("Equal key parts: key is effectively single DES")
+	c.singleDES.Importance = widget.WarningImportance
+	c.singleDES.Hide()
+	keyParts.Add(c.singleDES)
+
 	// Create result field with proper sizing.
 	c.result = widget.NewMultiLineEntry()
 	c.result.Wrapping = fyne.TextWrapBreak
 	c.result.Resize(fyne.NewSize(640, 100))
 	c.result.Disable() // Make result read-only
 
+	// Create chaining controls; the button is enabled by a valid result.
+	c.useAsInputBtn = widget.NewButton("↑ Use as input", c.useResultAsInput)
+	c.useAsInputBtn.Disable()
+	c.swapOperation = widget.NewCheck("Swap Encrypt/Decrypt", nil)
+
 	// Create calculate button.
-	calculate := widget.NewButton("Calculate", func() {
-		c.calculate()
-	})
+	c.calculateBtn = widget.NewButton("Calculate", c.calculate)
 
 	// Layout with visual separators and proper spacing.
 	c.container = container.NewVBox(
@@ -125,14 +227,20 @@ func NewDESCalculator() *DESCalculator {
 		widget.NewCard("Input Data", "",
 			container.NewVBox(
 				c.dataInput,
+				c.dataHint,
+				container.NewHBox(
+					layout.NewSpacer(),
+					widget.NewButtonWithIcon("Open file…", theme.FolderOpenIcon(), c.onOpenFile),
+				),
 			),
 		),
 
 		// Key and KCV section with fixed widths and consistent alignment.
 		widget.NewCard("Key", "",
 			container.NewVBox(
+				c.separateParts,
 				container.NewHBox(
-					container.NewGridWrap(fyne.NewSize(480, 36), c.keyInput),
+					c.keyEntryBox,
 					layout.NewSpacer(),
 					widget.NewLabelWithStyle(
 						"KCV:",
@@ -140,30 +248,172 @@ func NewDESCalculator() *DESCalculator {
 						fyne.TextStyle{Bold: true},
 					),
 					container.NewGridWrap(fyne.NewSize(120, 36), c.kcv),
+					newCopyButton(kcvText(c.kcv)),
 				),
+				c.keyPartsBox,
+				c.keyHint,
+				keyParts,
 				widget.NewLabel(""), // Add subtle spacing
 				c.ivContainer,
+				c.ivHint,
 			),
 		),
 
 		// Result section.
 		widget.NewCard("Result", "",
-			container.NewVBox(
-				c.result,
+			container.NewBorder(
+				nil, nil, nil,
+				container.NewVBox(
+					newCopyButton(c.resultText),
+					widget.NewButtonWithIcon("", theme.DocumentSaveIcon(), c.onSaveResult),
+				),
+				container.NewVBox(
+					c.result,
+					container.NewHBox(c.useAsInputBtn, c.swapOperation),
+				),
 			),
 		),
 
 		// Calculate button.
-		calculate,
+		c.calculateBtn,
 	)
+	c.validateInputs()
 
 	return c
 }
 
+// onSeparatePartsToggled switches between the single key entry and the K1..K3
+// entries, splitting the composite key into parts when its length allows.
+func (c *DESCalculator) onSeparatePartsToggled(on bool) {
+	if !on {
+		c.keyPartsBox.Hide()
+		c.keyEntryBox.Show()
+		return
+	}
+
+	key, _ := utils.NormalizeHexInput(c.keyInput.Text, 0)
+	if len(key)%16 != 0 || len(key) > 48 {
+		key = ""
+	}
+	c.splittingKey = true
+	for i, part := range c.keyPartInputs {
+		if off := i * 16; off < len(key) {
+			part.SetText(key[off : off+16])
+		} else {
+			part.SetText("")
+		}
+	}
+	c.splittingKey = false
+
+	c.keyEntryBox.Hide()
+	c.keyPartsBox.Show()
+}
+
+// joinKeyParts concatenates the part entries into the composite key entry,
+// which updates the KCVs and validation.
+func (c *DESCalculator) joinKeyParts() {
+	if c.splittingKey || !c.separateParts.Checked {
+		return
+	}
+
+	var key strings.Builder
+	for _, part := range c.keyPartInputs {
+		key.WriteString(part.Text)
+	}
+	c.keyInput.SetText(key.String())
+}
+
+// newHintLabel creates a hidden label for inline validation messages.
+func newHintLabel() *widget.Label {
+	l := widget.NewLabel("")
+	l.Importance = widget.DangerImportance
+	l.Hide()
+
+	return l
+}
+
+// setHint shows msg on label, or hides the label when msg is empty.
+func setHint(label *widget.Label, msg string) {
+	label.SetText(msg)
+	if msg == "" {
+		label.Hide()
+	} else {
+		label.Show()
+	}
+}
+
+// validateInputs refreshes the inline hints as the user types and enables
+// Calculate only when none is shown. Empty data and key fields show no hint.
+func (c *DESCalculator) validateInputs() {
+	if c.calculateBtn == nil {
+		return // Still constructing; run once all fields exist.
+	}
+
+	setHint(c.dataHint, c.dataHintText())
+	setHint(c.keyHint, c.keyHintText())
+
+	ivMsg := ""
+	if c.mode.Selected == "CBC" {
+		iv, _ := utils.NormalizeHexInput(c.ivInput.Text, 0)
+		if utils.ValidateHexFixedLength(iv, 8) != nil {
+			ivMsg = "need 16 hex digits"
+		}
+	}
+	setHint(c.ivHint, ivMsg)
+
+	if c.dataHint.Visible() || c.keyHint.Visible() || c.ivHint.Visible() {
+		c.calculateBtn.Disable()
+	} else {
+		c.calculateBtn.Enable()
+	}
+}
+
+// dataHintText returns the validation hint for the data entry.
+func (c *DESCalculator) dataHintText() string {
+	if strings.TrimSpace(c.dataInput.Text) == "" {
+		return ""
+	}
+
+	format := utils.DataFormat(c.inputFormat.Selected)
+	if format != utils.DataHex {
+		if _, err := utils.DecodeData(c.dataInput.Text, format); err != nil {
+			return err.Error()
+		}
+
+		return ""
+	}
+
+	data, _ := utils.NormalizeHexInput(c.dataInput.Text, 0)
+	if err := utils.ValidateHex(data); err != nil {
+		if utils.ValidateHex(data+"0") == nil {
+			return "odd number of hex digits"
+		}
+
+		return "invalid hex characters"
+	}
+
+	return ""
+}
+
+// keyHintText returns the validation hint for the key entry.
+func (c *DESCalculator) keyHintText() string {
+	key, _ := utils.NormalizeHexInput(c.keyInput.Text, 0)
+	if key == "" {
+		return ""
+	}
+	for _, n := range []int{8, 16, 24} {
+		if utils.ValidateHexFixedLength(key, n) == nil {
+			return ""
+		}
+	}
+
+	return "need 16/32/48 hex digits"
+}
+
 // calculateKCV calculates and displays the Key Check Value for the given key.
 func (c *DESCalculator) calculateKCV(key string) {
-	// Remove any spaces from key.
-	key = strings.ToUpper(strings.ReplaceAll(key, " ", ""))
+	key, _ = utils.NormalizeHexInput(key, 0)
+	c.clearKeyParts()
 
 	// Validate key length.
 	if key == "" || len(key)%16 != 0 || len(key) > 48 {
@@ -172,7 +422,7 @@ func (c *DESCalculator) calculateKCV(key string) {
 	}
 
 	// Convert key from hex to bytes.
-	keyBytes, err := hex.DecodeString(key)
+	keyBytes, err := utils.DecodeHexLenient(key)
 	if err != nil {
 		c.kcv.SetText("Invalid hex format")
 		return
@@ -188,7 +438,7 @@ func (c *DESCalculator) calculateKCV(key string) {
 		Encrypt: true,
 	}
 
-	result, err := descrypto.ProcessDES(params)
+	result, err := processDES(params)
 	if err != nil {
 		c.kcv.SetText("KCV error")
 		return
@@ -197,43 +447,81 @@ func (c *DESCalculator) calculateKCV(key string) {
 	// Display first 3 bytes of result as KCV in uppercase.
 	kcv := strings.ToUpper(hex.EncodeToString(result[:3]))
 	c.kcv.SetText(kcv)
+
+	if len(keyBytes) > 8 {
+		c.showKeyParts(keyBytes)
+	}
+}
+
+// showKeyParts shows the KCV and parity of each part of a double or triple
+// length key and warns when equal parts reduce it to single DES.
+func (c *DESCalculator) showKeyParts(key []byte) {
+	analysis, err := descrypto.AnalyzeDESKey(key)
+	if err != nil {
+		return
+	}
+
+	for i, part := range analysis.Parts {
+		parity, odd := parityText(part.EvenBytes, 8)
+		label := c.partLabels[i]
+		label.Importance = widget.MediumImportance
+		if !odd {
+			label.Importance = widget.WarningImportance
+		}
+		label.SetText(fmt.Sprintf("K%d: KCV %s, %s", i+1, part.KCV, parity))
+		label.Show()
+	}
+	if analysis.SingleDES {
+		c.singleDES.Show()
+	}
+}
+
+// clearKeyParts hides the per-part key information.
+func (c *DESCalculator) clearKeyParts() {
+	for _, label := range c.partLabels {
+		label.SetText("")
+		label.Hide()
+	}
+	c.singleDES.Hide()
 }
 
 // calculate processes the input data according to the selected options.
 func (c *DESCalculator) calculate() {
+	c.lastResult = nil
+	c.useAsInputBtn.Disable()
+
 	// Get and validate the key.
-	key := strings.ReplaceAll(c.keyInput.Text, " ", "")
+	key, _ := utils.NormalizeHexInput(c.keyInput.Text, 0)
 	if key == "" || len(key)%16 != 0 || len(key) > 48 {
 		c.result.SetText("Invalid key length")
 		return
 	}
-	keyBytes, err := hex.DecodeString(key)
+	keyBytes, err := utils.DecodeHexLenient(key)
 	if err != nil {
 		c.result.SetText("Invalid key format")
 		return
 	}
 
 	// Get and validate the data.
-	data := strings.ToUpper(strings.ReplaceAll(c.dataInput.Text, " ", ""))
-	if data == "" {
+	if strings.TrimSpace(c.dataInput.Text) == "" {
 		c.result.SetText("No data provided")
 		return
 	}
-	dataBytes, err := hex.DecodeString(data)
+	dataBytes, err := utils.DecodeData(c.dataInput.Text, utils.DataFormat(c.inputFormat.Selected))
 	if err != nil {
-		c.result.SetText("Invalid data format")
+		c.result.SetText(fmt.Sprintf("Invalid %s input: %v", c.inputFormat.Selected, err))
 		return
 	}
 
 	// Get and validate IV if in CBC mode.
 	var iv []byte
 	if c.mode.Selected == "CBC" {
-		ivStr := strings.ToUpper(strings.ReplaceAll(c.ivInput.Text, " ", ""))
+		ivStr, _ := utils.NormalizeHexInput(c.ivInput.Text, 0)
 		if len(ivStr) != 16 {
 			c.result.SetText("Invalid IV length (must be 16 hex digits)")
 			return
 		}
-		iv, err = hex.DecodeString(ivStr)
+		iv, err = utils.DecodeHexLenient(ivStr)
 		if err != nil {
 			c.result.SetText("Invalid IV format")
 			return
@@ -241,24 +529,16 @@ func (c *DESCalculator) calculate() {
 	}
 
 	// Prepare parameters.
-	var mode descrypto.CipherMode
-	switch c.mode.Selected {
-	case "CBC":
-		mode = descrypto.CBC
-	default:
-		mode = descrypto.ECB
-	}
-
-	var padding descrypto.PaddingMode
-	switch c.padding.Selected {
-	case "None":
-		padding = descrypto.NoPadding
-	case "ISO 9797-1 Method 1":
-		padding = descrypto.ISO97971
-	case "ISO 9797-1 Method 2":
-		padding = descrypto.ISO97972
-	default:
-		padding = descrypto.NoPadding
+	mode, ok := cipherModeByName[c.mode.Selected]
+	if !ok {
+		c.result.SetText(fmt.Sprintf("Error: unsupported mode %q", c.mode.Selected))
+		return
+	}
+
+	padding, ok := paddingModeByName[c.padding.Selected]
+	if !ok {
+		c.result.SetText(fmt.Sprintf("Error: unsupported padding %q", c.padding.Selected))
+		return
 	}
 
 	params := &descrypto.DESParams{
@@ -271,139 +551,138 @@ func (c *DESCalculator) calculate() {
 	}
 
 	// Process the data.
-	result, err := descrypto.ProcessDES(params)
+	result, err := processDES(params)
 	if err != nil {
 		c.result.SetText(fmt.Sprintf("Error: %v", err))
 		return
 	}
 
-	// Display the result in uppercase.
-	c.result.SetText(strings.ToUpper(hex.EncodeToString(result)))
-}
-
-// onModeChanged shows or hides iv input based on mode.
-func (c *DESCalculator) onModeChanged(mode string) {
-	if mode == "CBC" {
-		c.ivContainer.Show()
-	} else {
-		c.ivContainer.Hide()
-	}
-	c.container.Refresh()
+	// Display the result in the selected output format.
+	c.setResult(result)
 }
 
-// onKeyChanged updates KCV when key input changes.
-func (c *DESCalculator) onKeyChanged(text string) {
-	clean := strings.ReplaceAll(text, " ", "")
-	if err := utils.ValidateHex(clean); err != nil {
-		c.kcv.SetText("invalid hex string")
-		return
-	}
-	byteLen := len(clean) / 2
-	if byteLen != 8 && byteLen != 16 && byteLen != 24 {
-		c.kcv.SetText("invalid key length")
-		return
-	}
-	key, err := utils.DecodeHex(clean)
-	if err != nil {
-		c.kcv.SetText("invalid hex string")
+// setResult displays data in the selected output format. Hex output is
+// grouped in 8 digits for readability.
+func (c *DESCalculator) setResult(data []byte) {
+	c.lastResult = data
+	c.useAsInputBtn.Enable()
+	format := utils.DataFormat(c.outputFormat.Selected)
+	if format == utils.DataHex {
+		formatted, err := utils.FormatHex(hex.EncodeToString(data), 8, " ")
+		if err != nil {
+			c.result.SetText(fmt.Sprintf("Error: %v", err))
+			return
+		}
+		c.result.SetText(formatted)
 		return
 	}
-	kcvVal, err := descrypto.CalculateKCV(key)
+
+	text, err := utils.EncodeData(data, format)
 	if err != nil {
-		c.kcv.SetText("error calculating kcv")
+		c.result.SetText(fmt.Sprintf("Error: %v", err))
 		return
 	}
-	c.kcv.SetText("KCV: " + strings.ToUpper(kcvVal))
+	c.result.SetText(text)
 }
 
-// onDataChanged validates data input.
-func (c *DESCalculator) onDataChanged(text string) {
-	// no-op; validation on calculate
-}
+// useResultAsInput moves the last result into the data entry so it can feed
+// the next operation, optionally swapping Encrypt and Decrypt.
+func (c *DESCalculator) useResultAsInput() {
+	if len(c.lastResult) == 0 {
+		return // The result field holds an error message, not data.
+	}
+
+	c.inputFormat.SetSelected(string(utils.DataHex))
+	c.dataInput.SetText(strings.ToUpper(hex.EncodeToString(c.lastResult)))
+	c.result.SetText("")
+	c.lastResult = nil
+	c.useAsInputBtn.Disable()
 
-// onIVChanged validates iv input.
-func (c *DESCalculator) onIVChanged(text string) {
-	// no-op; validation on calculate
+	if c.swapOperation.Checked {
+		if c.operation.Selected == "Encrypt" {
+			c.operation.SetSelected("Decrypt")
+		} else {
+			c.operation.SetSelected("Encrypt")
+		}
+	}
 }
 
-// onCalculate performs DES encrypt/decrypt.
-func (c *DESCalculator) onCalculate() {
+// onOpenFile loads a file into the data entry as hex, asking for
+// confirmation when the file is larger than maxDataFileSize.
+func (c *DESCalculator) onOpenFile() {
 	w := fyne.CurrentApp().Driver().AllWindows()[0]
 
-	// validate data
-	dataClean := strings.ToUpper(strings.ReplaceAll(c.dataInput.Text, " ", ""))
-	if err := utils.ValidateHex(dataClean); err != nil {
-		dialog.ShowError(err, w)
+	dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if r == nil {
+			return // Cancelled.
+		}
+		defer r.Close()
 
-		return
-	}
-	data, _ := hex.DecodeString(dataClean)
+		data, err := io.ReadAll(r)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read %s: %v", r.URI().Name(), err), w)
+			return
+		}
 
-	// validate key
-	keyClean := strings.ToUpper(strings.ReplaceAll(c.keyInput.Text, " ", ""))
-	if err := utils.ValidateHex(keyClean); err != nil {
-		dialog.ShowError(err, w)
+		load := func() {
+			hexStr, _ := utils.FileContentToHex(data)
+			c.inputFormat.SetSelected(string(utils.DataHex))
+			c.dataInput.SetText(hexStr)
+		}
+		if len(data) > maxDataFileSize {
+			dialog.ShowConfirm(
+				"Large file",
+				fmt.Sprintf("%s is %d bytes. Load it anyway?", r.URI().Name(), len(data)),
+				func(ok bool) {
+					if ok {
+						load()
+					}
+				},
+				w,
+			)
+			return
+		}
+		load()
+	}, w)
+}
 
-		return
-	}
-	keyBytes, _ := hex.DecodeString(keyClean)
-	if len(keyBytes) != 8 && len(keyBytes) != 16 && len(keyBytes) != 24 {
-		dialog.ShowError(errors.New("invalid key length"), w)
+// onSaveResult writes the raw bytes of the last result to a file.
+func (c *DESCalculator) onSaveResult() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
 
+	if len(c.lastResult) == 0 {
+		dialog.ShowError(errors.New("no result to save"), w)
 		return
 	}
+	data := c.lastResult
 
-	// prepare params
-	params := &descrypto.DESParams{
-		Data:    data,
-		Key:     keyBytes,
-		Encrypt: c.operation.Selected == "Encrypt",
-	}
-	// mode
-	switch c.mode.Selected {
-	case "ECB":
-		params.Mode = descrypto.ECB
-
-	case "CBC":
-		params.Mode = descrypto.CBC
-		ivClean := strings.ToUpper(strings.ReplaceAll(c.ivInput.Text, " ", ""))
-		if err := utils.ValidateHex(ivClean); err != nil {
+	dialog.ShowFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
 			dialog.ShowError(err, w)
-
 			return
 		}
-		ivBytes, _ := hex.DecodeString(ivClean)
-		if len(ivBytes) != 8 {
-			dialog.ShowError(errors.New("invalid iv length"), w)
-
-			return
+		if wc == nil {
+			return // Cancelled.
 		}
-		params.IV = ivBytes
+		defer wc.Close()
 
-	default:
-		dialog.ShowError(errors.New("unsupported mode"), w)
+		if _, err := wc.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+		}
+	}, w)
+}
 
-		return
+// resultText returns the result for copying, without hex grouping.
+func (c *DESCalculator) resultText() string {
+	if utils.DataFormat(c.outputFormat.Selected) == utils.DataHex {
+		return utils.StripHexFormatting(c.result.Text)
 	}
-	// padding
-	switch c.padding.Selected {
-	case "None":
-		params.Padding = descrypto.NoPadding
-	case "ISO 9797-1 Method 1":
-		params.Padding = descrypto.ISO97971
-	case "ISO 9797-1 Method 2":
-		params.Padding = descrypto.ISO97972
-	default:
-		params.Padding = descrypto.NoPadding
-	}
-	// perform operation
-	resultBytes, err := descrypto.ProcessDES(params)
-	if err != nil {
-		dialog.ShowError(err, w)
 
-		return
-	}
-	c.result.SetText(strings.ToUpper(hex.EncodeToString(resultBytes)))
+	return c.result.Text
 }
 
 // CreateRenderer returns a new renderer for the DESCalculator widget.
@@ -414,8 +693,14 @@ func (c *DESCalculator) CreateRenderer() fyne.WidgetRenderer {
 // Cleanup implements TabContent interface.
 func (c *DESCalculator) Cleanup() {
 	// Clear sensitive data.
+	for _, part := range c.keyPartInputs {
+		part.SetText("")
+	}
 	c.keyInput.SetText("")
 	c.dataInput.SetText("")
 	c.result.SetText("")
+	c.lastResult = nil
+	c.useAsInputBtn.Disable()
 	c.kcv.SetText("KCV: ")
+	c.clearKeyParts()
 }
@@ -0,0 +1,351 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	descrypto "github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+)
+
+func TestDESCalculator_CalculatePadding(t *testing.T) {
+	tests := []struct {
+		name    string
+		padding string
+		want    descrypto.PaddingMode
+	}{
+		{"none", "None", descrypto.NoPadding},
+		{"iso_method_1", "ISO 9797-1 Method 1", descrypto.ISO97971},
+		{"iso_method_2", "ISO 9797-1 Method 2", descrypto.ISO97972},
+	}
+
+	if len(tests) != len(PaddingModes) {
+		t.Fatalf("test covers %d padding modes, tab offers %d", len(tests), len(PaddingModes))
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			c := NewDESCalculator()
+			c.keyInput.SetText("0123456789ABCDEF")
+			c.dataInput.SetText("0011223344556677")
+			c.padding.SetSelected(tt.padding)
+
+			var got *descrypto.DESParams
+			orig := processDES
+			processDES = func(p *descrypto.DESParams) ([]byte, error) {
+				got = p
+				return make([]byte, 8), nil
+			}
+			defer func() { processDES = orig }()
+
+			c.calculate()
+
+			if got == nil {
+				t.Fatalf("backend not called, result: %q", c.result.Text)
+			}
+			if got.Padding != tt.want {
+				t.Errorf("Padding = %v, want %v", got.Padding, tt.want)
+			}
+		})
+	}
+}
+
+func TestDESCalculator_CalculateUnknownPadding(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+	c.keyInput.SetText("0123456789ABCDEF")
+	c.dataInput.SetText("0011223344556677")
+	c.padding.Selected = "PKCS7"
+
+	called := false
+	orig := processDES
+	processDES = func(p *descrypto.DESParams) ([]byte, error) {
+		called = true
+		return nil, nil
+	}
+	defer func() { processDES = orig }()
+
+	c.calculate()
+
+	if called {
+		t.Error("backend called with unsupported padding")
+	}
+	if c.result.Text != `Error: unsupported padding "PKCS7"` {
+		t.Errorf("result = %q", c.result.Text)
+	}
+}
+
+func TestDESCalculator_ASCIIRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		plaintext string
+		padding   string
+	}{
+		{"block_aligned", "PIN BLOCK TEST!!", "None"},
+		{"padded_short", "HELLO", "ISO 9797-1 Method 2"},
+		{"padded_full_block", "PIN BLOCK TEST!!", "ISO 9797-1 Method 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			c := NewDESCalculator()
+			c.keyInput.SetText("0123456789ABCDEFFEDCBA9876543210")
+			c.padding.SetSelected(tt.padding)
+
+			// Encrypt ASCII plaintext to Base64.
+			c.operation.SetSelected("Encrypt")
+			c.inputFormat.SetSelected("ASCII")
+			c.outputFormat.SetSelected("Base64")
+			c.dataInput.SetText(tt.plaintext)
+			c.calculate()
+			ciphertext := c.result.Text
+			if ciphertext == "" || ciphertext == tt.plaintext || strings.HasPrefix(ciphertext, "Error") {
+				t.Fatalf("unexpected ciphertext %q", ciphertext)
+			}
+
+			// Decrypt the Base64 ciphertext back to ASCII.
+			c.operation.SetSelected("Decrypt")
+			c.inputFormat.SetSelected("Base64")
+			c.outputFormat.SetSelected("ASCII")
+			c.dataInput.SetText(ciphertext)
+			c.calculate()
+			if c.result.Text != tt.plaintext {
+				t.Errorf("round trip = %q, want %q", c.result.Text, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestDESCalculator_NonPrintableASCIIOutput(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+	c.keyInput.SetText("0123456789ABCDEF")
+	c.operation.SetSelected("Decrypt")
+	c.outputFormat.SetSelected("ASCII")
+	c.dataInput.SetText("0000000000000000")
+	c.calculate()
+
+	if !strings.HasPrefix(c.result.Text, "Error: result is not printable ASCII") {
+		t.Errorf("result = %q, want non-printable explanation", c.result.Text)
+	}
+}
+
+func TestDESCalculator_UseResultAsInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		swap    bool
+		wantOp  string
+		wantKey string
+	}{
+		{"keep_operation", false, "Encrypt", "0123456789ABCDEF"},
+		{"swap_operation", true, "Decrypt", "0123456789ABCDEF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			c := NewDESCalculator()
+			c.keyInput.SetText(tt.wantKey)
+			c.dataInput.SetText("0000000000000000")
+			c.swapOperation.SetChecked(tt.swap)
+			c.calculate()
+
+			test.Tap(c.useAsInputBtn)
+
+			if c.dataInput.Text != "D5D44FF720683D0D" {
+				t.Errorf("data = %q, want previous result", c.dataInput.Text)
+			}
+			if c.result.Text != "" {
+				t.Errorf("result = %q, want cleared", c.result.Text)
+			}
+			if c.operation.Selected != tt.wantOp {
+				t.Errorf("operation = %q, want %q", c.operation.Selected, tt.wantOp)
+			}
+			if !c.useAsInputBtn.Disabled() {
+				t.Error("button enabled without a result")
+			}
+		})
+	}
+}
+
+func TestDESCalculator_UseResultAsInputRefusesErrors(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+	c.keyInput.SetText("0123")
+	c.dataInput.SetText("0000000000000000")
+	c.calculate()
+
+	test.Tap(c.useAsInputBtn)
+
+	if c.dataInput.Text != "0000000000000000" {
+		t.Errorf("data = %q, want unchanged", c.dataInput.Text)
+	}
+	if c.result.Text != "Invalid key length" {
+		t.Errorf("result = %q, want error kept", c.result.Text)
+	}
+}
+
+func TestDESCalculator_KeyParts(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+
+	// K2 ends in 0x11, which has even parity.
+	c.keyInput.SetText("0123456789ABCDEFFEDCBA9876543211")
+
+	if !c.partLabels[0].Visible() || !c.partLabels[1].Visible() || c.partLabels[2].Visible() {
+		t.Fatal("want K1 and K2 shown, K3 hidden")
+	}
+	if got := c.partLabels[0].Text; got != "K1: KCV D5D44F, parity: odd" {
+		t.Errorf("K1 = %q", got)
+	}
+	if got := c.partLabels[1].Text; !strings.HasSuffix(got, "parity: mixed (bytes 8)") {
+		t.Errorf("K2 = %q, want bad parity in byte 8", got)
+	}
+	if c.partLabels[1].Importance != widget.WarningImportance {
+		t.Error("K2 not highlighted")
+	}
+	if c.singleDES.Visible() {
+		t.Error("single DES warning shown for distinct parts")
+	}
+
+	c.keyInput.SetText("0123456789ABCDEF0123456789ABCDEF")
+	if !c.singleDES.Visible() {
+		t.Error("single DES warning hidden for K1 == K2")
+	}
+
+	c.keyInput.SetText("0123456789ABCDEF")
+	for i, l := range c.partLabels {
+		if l.Visible() {
+			t.Errorf("K%d shown for a single length key", i+1)
+		}
+	}
+
+	c.keyInput.SetText("0123456789ABCDEFFEDCBA9876543210")
+	c.Cleanup()
+	if c.partLabels[0].Visible() || c.singleDES.Visible() {
+		t.Error("Cleanup left key part information visible")
+	}
+}
+
+func TestDESCalculator_InlineValidation(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+	if c.calculateBtn.Disabled() {
+		t.Fatal("Calculate disabled on an empty form")
+	}
+
+	steps := []struct {
+		name         string
+		apply        func()
+		hint         *widget.Label
+		wantHint     string
+		wantDisabled bool
+	}{
+		{"odd_data", func() { c.dataInput.SetText("012") }, c.dataHint, "odd number of hex digits", true},
+		{"even_data", func() { c.dataInput.SetText("0123") }, c.dataHint, "", false},
+		{"short_key", func() { c.keyInput.SetText("0123456789") }, c.keyHint, "need 16/32/48 hex digits", true},
+		{"full_key", func() { c.keyInput.SetText("0123456789ABCDEF") }, c.keyHint, "", false},
+		{"cbc_without_iv", func() { c.mode.SetSelected("CBC") }, c.ivHint, "need 16 hex digits", true},
+		{"cbc_short_iv", func() { c.ivInput.SetText("0011") }, c.ivHint, "need 16 hex digits", true},
+		{"cbc_full_iv", func() { c.ivInput.SetText("0011223344556677") }, c.ivHint, "", false},
+		{"back_to_ecb", func() { c.ivInput.SetText(""); c.mode.SetSelected("ECB") }, c.ivHint, "", false},
+	}
+
+	for _, step := range steps {
+		step.apply()
+		if step.hint.Visible() != (step.wantHint != "") || step.hint.Text != step.wantHint {
+			t.Errorf("%s: hint = %q (visible %v), want %q",
+				step.name, step.hint.Text, step.hint.Visible(), step.wantHint)
+		}
+		if c.calculateBtn.Disabled() != step.wantDisabled {
+			t.Errorf("%s: Calculate disabled = %v, want %v",
+				step.name, c.calculateBtn.Disabled(), step.wantDisabled)
+		}
+	}
+}
+
+func TestDESCalculator_SeparateParts(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts [3]string
+		want  string
+	}{
+		{"two_parts", [3]string{"0123456789ABCDEF", "FEDCBA9876543210", ""}, "0123456789ABCDEFFEDCBA9876543210"},
+		{
+			"three_parts",
+			[3]string{"0123456789ABCDEF", "FEDCBA9876543210", "0011223344556677"},
+			"0123456789ABCDEFFEDCBA98765432100011223344556677",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			c := NewDESCalculator()
+			c.separateParts.SetChecked(true)
+			for i, p := range tt.parts {
+				c.keyPartInputs[i].SetText(p)
+			}
+
+			if c.keyInput.Text != tt.want {
+				t.Fatalf("composite key = %q, want %q", c.keyInput.Text, tt.want)
+			}
+			if len(c.kcv.Text) != 6 || !c.partLabels[1].Visible() {
+				t.Errorf("kcv = %q, want composite and per-part KCVs", c.kcv.Text)
+			}
+
+			// Round trip: back to the single entry and into parts again.
+			c.separateParts.SetChecked(false)
+			if c.keyInput.Text != tt.want || !c.keyEntryBox.Visible() || c.keyPartsBox.Visible() {
+				t.Fatal("single key entry not restored")
+			}
+			for _, p := range c.keyPartInputs {
+				p.SetText("")
+			}
+			c.separateParts.SetChecked(true)
+			for i, p := range tt.parts {
+				if c.keyPartInputs[i].Text != p {
+					t.Errorf("K%d = %q, want %q", i+1, c.keyPartInputs[i].Text, p)
+				}
+			}
+			if c.keyInput.Text != tt.want {
+				t.Errorf("composite key after re-split = %q, want %q", c.keyInput.Text, tt.want)
+			}
+
+			c.Cleanup()
+			for i, p := range c.keyPartInputs {
+				if p.Text != "" {
+					t.Errorf("Cleanup left K%d = %q", i+1, p.Text)
+				}
+			}
+		})
+	}
+}
+
+func TestDESCalculator_SeparatePartsUnsplittableKey(t *testing.T) {
+	test.NewTempApp(t)
+
+	c := NewDESCalculator()
+	c.keyInput.SetText("0123456789")
+	c.separateParts.SetChecked(true)
+
+	for i, p := range c.keyPartInputs {
+		if p.Text != "" {
+			t.Errorf("K%d = %q, want empty for a key that cannot be split", i+1, p.Text)
+		}
+	}
+}
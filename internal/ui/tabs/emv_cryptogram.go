@@ -0,0 +1,374 @@
+package tabs
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/internal/backend/emv"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// emvStage is a step of the EMV cryptogram chain.
+type emvStage int
+
+// EMV stages, in chain order.
+const (
+	emvStageICCMK emvStage = iota
+	emvStageSessionKey
+	emvStageARQC
+	emvStageARPC
+)
+
+// Choices of the EMV selects, in the order of the backend constants.
+var (
+	emvOptions        = []string{"Option A", "Option B"}
+	emvPaddings       = []string{"Method 1 (zeros)", "Method 2 (80 00..)"}
+	emvPaddingModes   = []crypto.PaddingMode{crypto.ISO97971, crypto.ISO97972}
+	emvARPCMethods    = []string{"Method 1 (ARC)", "Method 2 (CSU)"}
+	emvARPCMethodKeys = []emv.ARPCMethod{emv.ARPCMethod1, emv.ARPCMethod2}
+)
+
+// emvChain holds the values passed between the EMV stages as typed. Each
+// stage reads its inputs from the chain and stores its output as the input
+// of the next stage, so the chain can start from any intermediate value.
+type emvChain struct {
+	IssuerMK string
+	PAN      string
+	PSN      string
+	Option   emv.Option
+
+	ICCMK string
+	ATC   string
+
+	SessionKey string
+	Data       string
+	Padding    crypto.PaddingMode
+
+	ARQC   string
+	ARC    string // ARC for method 1, CSU for method 2.
+	PAD    string // Proprietary authentication data for method 2.
+	Method emv.ARPCMethod
+
+	ARPC string
+}
+
+// run computes the stages from first to last, stopping at the first error.
+func (c *emvChain) run(first, last emvStage) error {
+	for s := first; s <= last; s++ {
+		if err := c.step(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// step computes stage s. Outputs of the later stages are cleared, since
+// they no longer follow from the chain.
+func (c *emvChain) step(s emvStage) error {
+	outputs := []*string{&c.ICCMK, &c.SessionKey, &c.ARQC, &c.ARPC}
+	for _, out := range outputs[s+1:] {
+		*out = ""
+	}
+
+	switch s {
+	case emvStageICCMK:
+		mk, err := emvHex("Issuer MK-AC", c.IssuerMK, 16)
+		if err != nil {
+			return err
+		}
+		defer clear(mk)
+		key, err := emv.DeriveICCMK(mk, strings.TrimSpace(c.PAN), strings.TrimSpace(c.PSN), c.Option)
+		if err != nil {
+			return err
+		}
+		c.ICCMK = strings.ToUpper(hex.EncodeToString(key))
+		clear(key)
+	case emvStageSessionKey:
+		mk, err := emvHex("ICC MK", c.ICCMK, 16)
+		if err != nil {
+			return err
+		}
+		defer clear(mk)
+		atc, err := emvHex("ATC", c.ATC, 2)
+		if err != nil {
+			return err
+		}
+		key, err := emv.DeriveSessionKey(mk, atc)
+		if err != nil {
+			return err
+		}
+		c.SessionKey = strings.ToUpper(hex.EncodeToString(key))
+		clear(key)
+	case emvStageARQC:
+		sk, err := emvHex("Session key", c.SessionKey, 16)
+		if err != nil {
+			return err
+		}
+		defer clear(sk)
+		data, err := emvHex("Transaction data", c.Data, 0)
+		if err != nil {
+			return err
+		}
+		ac, err := emv.GenerateAC(sk, data, c.Padding)
+		if err != nil {
+			return err
+		}
+		c.ARQC = strings.ToUpper(hex.EncodeToString(ac))
+	case emvStageARPC:
+		sk, err := emvHex("Session key", c.SessionKey, 16)
+		if err != nil {
+			return err
+		}
+		defer clear(sk)
+		arqc, err := emvHex("ARQC", c.ARQC, 8)
+		if err != nil {
+			return err
+		}
+		arc, err := emvHex("ARC/CSU", c.ARC, 0)
+		if err != nil {
+			return err
+		}
+		var pad []byte
+		if strings.TrimSpace(c.PAD) != "" {
+			if pad, err = emvHex("Proprietary data", c.PAD, 0); err != nil {
+				return err
+			}
+		}
+		arpc, err := emv.GenerateARPC(sk, arqc, arc, pad, c.Method)
+		if err != nil {
+			return err
+		}
+		c.ARPC = strings.ToUpper(hex.EncodeToString(arpc))
+	}
+
+	return nil
+}
+
+// emvHex decodes the hex field name, which must have size bytes unless size
+// is 0.
+func emvHex(name, text string, size int) ([]byte, error) {
+	b, err := hex.DecodeString(utils.StripHexFormatting(strings.TrimSpace(text)))
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("%s must be hex", name)
+	case len(b) == 0:
+		return nil, fmt.Errorf("%s is required", name)
+	case size > 0 && len(b) != size:
+		return nil, fmt.Errorf("%s must be %d hex digits", name, 2*size)
+	}
+
+	return b, nil
+}
+
+// EMVCryptogram represents the EMV Cryptogram tab.
+type EMVCryptogram struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	// ICC master key derivation.
+	issuerMK *widget.Entry
+	pan      *widget.Entry
+	psn      *widget.Entry
+	option   *widget.Select
+
+	// Session key derivation.
+	iccMK *widget.Entry
+	atc   *widget.Entry
+
+	// Cryptograms.
+	sessionKey *widget.Entry
+	data       *widget.Entry
+	padding    *widget.Select
+	arqc       *widget.Entry
+	arc        *widget.Entry
+	pad        *widget.Entry
+	method     *widget.Select
+	arpc       *widget.Label
+
+	// KCVs of the keys, by key entry.
+	kcvs map[*widget.Entry]*widget.Label
+
+	reveal *widget.Check
+	errMsg *widget.Label
+}
+
+// NewEMVCryptogram creates a new EMV Cryptogram tab.
+func NewEMVCryptogram() *EMVCryptogram {
+	t := &EMVCryptogram{kcvs: make(map[*widget.Entry]*widget.Label)}
+	t.ExtendBaseWidget(t)
+
+	t.issuerMK = t.newKeyEntry("Issuer master key (32 hex digits)")
+	t.pan = widget.NewEntry()
+	t.pan.SetPlaceHolder("12 to 19 digits")
+	t.psn = widget.NewEntry()
+	t.psn.SetPlaceHolder("00")
+	t.option = widget.NewSelect(emvOptions, nil)
+	t.option.SetSelectedIndex(0)
+
+	t.iccMK = t.newKeyEntry("Derived, or enter an ICC MK")
+	t.atc = widget.NewEntry()
+	t.atc.SetPlaceHolder("4 hex digits, e.g. 001C")
+
+	t.sessionKey = t.newKeyEntry("Derived, or enter a session key")
+	t.data = widget.NewMultiLineEntry()
+	t.data.SetPlaceHolder("CDOL1 / transaction data in hex")
+	t.data.Wrapping = fyne.TextWrapBreak
+	t.padding = widget.NewSelect(emvPaddings, nil)
+	t.padding.SetSelectedIndex(1)
+	t.arqc = widget.NewEntry()
+	t.arqc.SetPlaceHolder("Generated, or enter an ARQC")
+
+	t.arc = widget.NewEntry()
+	t.arc.SetPlaceHolder("ARC (4 hex digits) or CSU (8 hex digits)")
+	t.pad = widget.NewEntry()
+	t.pad.SetPlaceHolder("Optional, method 2 only")
+	t.method = widget.NewSelect(emvARPCMethods, nil)
+	t.method.SetSelectedIndex(0)
+	t.arpc = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+
+	t.reveal = widget.NewCheck("Reveal keys", t.onRevealToggled)
+	t.errMsg = widget.NewLabel("")
+	t.errMsg.Importance = widget.DangerImportance
+	t.errMsg.Wrapping = fyne.TextWrapWord
+	t.errMsg.Hide()
+
+	stageBtn := func(label string, s emvStage) *widget.Button {
+		return widget.NewButton(label, func() { t.compute(s, s) })
+	}
+
+	t.container = container.NewVBox(
+		container.NewHBox(
+			widget.NewButton("Run All", func() { t.compute(emvStageICCMK, emvStageARPC) }),
+			t.reveal,
+		),
+		t.errMsg,
+		widget.NewCard("ICC Master Key", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Issuer MK-AC", t.withKCV(t.issuerMK)),
+				widget.NewFormItem("PAN", t.pan),
+				widget.NewFormItem("PSN", t.psn),
+				widget.NewFormItem("Derivation", t.option),
+			),
+			container.NewHBox(stageBtn("Derive ICC MK", emvStageICCMK)),
+		)),
+		widget.NewCard("Session Key", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("ICC MK", t.withKCV(t.iccMK)),
+				widget.NewFormItem("ATC", t.atc),
+			),
+			container.NewHBox(stageBtn("Derive Session Key", emvStageSessionKey)),
+		)),
+		widget.NewCard("ARQC", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Session Key", t.withKCV(t.sessionKey)),
+				widget.NewFormItem("Data", t.data),
+				widget.NewFormItem("Padding", t.padding),
+			),
+			container.NewHBox(stageBtn("Generate ARQC", emvStageARQC)),
+			widget.NewForm(widget.NewFormItem("ARQC", container.NewBorder(nil, nil, nil,
+				newCopyButton(func() string { return t.arqc.Text }), t.arqc))),
+		)),
+		widget.NewCard("ARPC", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Method", t.method),
+				widget.NewFormItem("ARC / CSU", t.arc),
+				widget.NewFormItem("Proprietary Data", t.pad),
+			),
+			container.NewHBox(stageBtn("Generate ARPC", emvStageARPC)),
+			widget.NewForm(widget.NewFormItem("ARPC", container.NewHBox(t.arpc,
+				newCopyButton(func() string { return t.arpc.Text })))),
+		)),
+	)
+
+	return t
+}
+
+// newKeyEntry creates a masked key entry whose KCV follows its text.
+func (t *EMVCryptogram) newKeyEntry(placeholder string) *widget.Entry {
+	e := widget.NewEntry()
+	e.Password = true
+	e.SetPlaceHolder(placeholder)
+	kcv := widget.NewLabel("")
+	t.kcvs[e] = kcv
+	e.OnChanged = func(text string) {
+		kcv.SetText("")
+		if key := utils.StripHexFormatting(strings.TrimSpace(text)); len(key) == 32 {
+			if v := crypto.CheckValueOf(key); v != "ERROR" {
+				kcv.SetText("KCV: " + v)
+			}
+		}
+	}
+
+	return e
+}
+
+// withKCV lays out a key entry with its KCV.
+func (t *EMVCryptogram) withKCV(e *widget.Entry) fyne.CanvasObject {
+	return container.NewBorder(nil, nil, nil, t.kcvs[e], e)
+}
+
+// onRevealToggled shows or masks the clear keys.
+func (t *EMVCryptogram) onRevealToggled(on bool) {
+	for e := range t.kcvs {
+		e.Password = !on
+		e.Refresh()
+	}
+}
+
+// chain returns the fields as an EMV chain.
+func (t *EMVCryptogram) chain() emvChain {
+	return emvChain{
+		IssuerMK:   t.issuerMK.Text,
+		PAN:        t.pan.Text,
+		PSN:        t.psn.Text,
+		Option:     emv.Option(t.option.SelectedIndex()),
+		ICCMK:      t.iccMK.Text,
+		ATC:        t.atc.Text,
+		SessionKey: t.sessionKey.Text,
+		Data:       t.data.Text,
+		Padding:    emvPaddingModes[t.padding.SelectedIndex()],
+		ARQC:       t.arqc.Text,
+		ARC:        t.arc.Text,
+		PAD:        t.pad.Text,
+		Method:     emvARPCMethodKeys[t.method.SelectedIndex()],
+		ARPC:       t.arpc.Text,
+	}
+}
+
+// compute runs the stages from first to last and shows their outputs in the
+// inputs of the following stages.
+func (t *EMVCryptogram) compute(first, last emvStage) {
+	c := t.chain()
+	err := c.run(first, last)
+
+	t.iccMK.SetText(c.ICCMK)
+	t.sessionKey.SetText(c.SessionKey)
+	t.arqc.SetText(c.ARQC)
+	t.arpc.SetText(c.ARPC)
+	showHint(t.errMsg, err)
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (t *EMVCryptogram) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.container)
+}
+
+// Cleanup implements TabContent interface. It wipes every field, so no key
+// stays on screen.
+func (t *EMVCryptogram) Cleanup() {
+	for _, e := range []*widget.Entry{
+		t.issuerMK, t.pan, t.psn, t.iccMK, t.atc, t.sessionKey, t.data, t.arqc, t.arc, t.pad,
+	} {
+		e.SetText("")
+	}
+	t.arpc.SetText("")
+	t.reveal.SetChecked(false)
+	showHint(t.errMsg, nil)
+}
@@ -0,0 +1,167 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/crypto"
+	"github.com/andrei-cloud/hsmtool/internal/backend/emv"
+)
+
+// EMV option A example: issuer MK-AC, PAN and PSN with the ICC MK they give.
+const (
+	emvIssuerMK = "0123456789ABCDEFFEDCBA9876543210"
+	emvPAN      = "99012345678901234"
+	emvPSN      = "45"
+	emvICCMK    = "67F8292358083E5EA7AB7FDA58D53B6B"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+
+	return b
+}
+
+func TestEMVChain_Run(t *testing.T) {
+	c := emvChain{
+		IssuerMK: emvIssuerMK,
+		PAN:      emvPAN,
+		PSN:      emvPSN,
+		Option:   emv.OptionA,
+		ATC:      "001C",
+		Data:     "00 00 00 01 00 00 00 00 00 00 00 00 08 26 00 00 00 00 00 08 26 26 10 16 00 12 34 56 78 00 1C",
+		Padding:  crypto.ISO97972,
+		ARC:      "3030",
+		Method:   emv.ARPCMethod1,
+	}
+	if err := c.run(emvStageICCMK, emvStageARPC); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	// Each output is the input of the next stage.
+	if c.ICCMK != emvICCMK {
+		t.Errorf("ICC MK = %s, want %s", c.ICCMK, emvICCMK)
+	}
+	sk, _ := emv.DeriveSessionKey(unhex(t, c.ICCMK), unhex(t, "001C"))
+	if c.SessionKey != strings.ToUpper(hex.EncodeToString(sk)) {
+		t.Errorf("session key = %s, want %X", c.SessionKey, sk)
+	}
+	arqc, _ := emv.GenerateAC(sk, unhex(t, "0000000100000000000000000826000000000008262610160012345678001C"), crypto.ISO97972)
+	if c.ARQC != strings.ToUpper(hex.EncodeToString(arqc)) {
+		t.Errorf("ARQC = %s, want %X", c.ARQC, arqc)
+	}
+	arpc, _ := emv.GenerateARPC(sk, arqc, unhex(t, "3030"), nil, emv.ARPCMethod1)
+	if c.ARPC != strings.ToUpper(hex.EncodeToString(arpc)) {
+		t.Errorf("ARPC = %s, want %X", c.ARPC, arpc)
+	}
+}
+
+func TestEMVChain_StartFromIntermediate(t *testing.T) {
+	full := emvChain{
+		IssuerMK: emvIssuerMK, PAN: emvPAN, PSN: emvPSN, ATC: "0001",
+		Data: "0102030405060708", Padding: crypto.ISO97972, ARC: "00820000", Method: emv.ARPCMethod2,
+	}
+	if err := full.run(emvStageICCMK, emvStageARPC); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		chain emvChain
+		first emvStage
+	}{
+		{"from_icc_mk", emvChain{ICCMK: emvICCMK, ATC: full.ATC, Data: full.Data, Padding: full.Padding, ARC: full.ARC, Method: full.Method}, emvStageSessionKey},
+		{"from_session_key", emvChain{SessionKey: full.SessionKey, Data: full.Data, Padding: full.Padding, ARC: full.ARC, Method: full.Method}, emvStageARQC},
+		{"from_arqc", emvChain{SessionKey: full.SessionKey, ARQC: full.ARQC, ARC: full.ARC, Method: full.Method}, emvStageARPC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.chain
+			if err := c.run(tt.first, emvStageARPC); err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if c.ARPC != full.ARPC || c.ARQC != full.ARQC || c.SessionKey != full.SessionKey {
+				t.Errorf("chain = %+v, want outputs of %+v", c, full)
+			}
+			if c.IssuerMK != "" {
+				t.Error("an intermediate start filled the issuer key")
+			}
+		})
+	}
+}
+
+func TestEMVChain_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   emvChain
+		wantErr string
+	}{
+		{"no_issuer_key", emvChain{PAN: emvPAN}, "Issuer MK-AC is required"},
+		{"short_issuer_key", emvChain{IssuerMK: "0123", PAN: emvPAN}, "32 hex digits"},
+		{"bad_pan", emvChain{IssuerMK: emvIssuerMK, PAN: "4000"}, "PAN"},
+		{"no_atc", emvChain{IssuerMK: emvIssuerMK, PAN: emvPAN, PSN: emvPSN}, "ATC is required"},
+		{"bad_data", emvChain{IssuerMK: emvIssuerMK, PAN: emvPAN, ATC: "0001", Data: "XYZ"}, "Transaction data must be hex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.chain
+			err := c.run(emvStageICCMK, emvStageARPC)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("run() error = %v, want %q", err, tt.wantErr)
+			}
+			if c.ARPC != "" {
+				t.Errorf("ARPC = %q after a failed stage", c.ARPC)
+			}
+		})
+	}
+
+	// A recomputed stage clears the outputs that followed from the old value.
+	c := emvChain{IssuerMK: emvIssuerMK, PAN: emvPAN, PSN: emvPSN, SessionKey: emvICCMK, ARQC: "0102030405060708", ARPC: "01020304"}
+	if err := c.step(emvStageICCMK); err != nil {
+		t.Fatal(err)
+	}
+	if c.SessionKey != "" || c.ARQC != "" || c.ARPC != "" {
+		t.Errorf("stale outputs kept: %+v", c)
+	}
+}
+
+func TestEMVCryptogram_RevealAndCleanup(t *testing.T) {
+	test.NewTempApp(t)
+	e := NewEMVCryptogram()
+	e.issuerMK.SetText(emvIssuerMK)
+	e.pan.SetText(emvPAN)
+	e.psn.SetText(emvPSN)
+	e.compute(emvStageICCMK, emvStageICCMK)
+
+	if e.iccMK.Text != emvICCMK || !e.iccMK.Password {
+		t.Fatalf("ICC MK = %q (masked %v), want %s masked", e.iccMK.Text, e.iccMK.Password, emvICCMK)
+	}
+	if e.kcvs[e.iccMK].Text == "" || strings.Contains(e.kcvs[e.iccMK].Text, emvICCMK) {
+		t.Errorf("ICC MK KCV = %q", e.kcvs[e.iccMK].Text)
+	}
+	e.compute(emvStageSessionKey, emvStageSessionKey)
+	if !e.errMsg.Visible() || !strings.Contains(e.errMsg.Text, "ATC") {
+		t.Errorf("error = %q, want the missing ATC", e.errMsg.Text)
+	}
+
+	e.reveal.SetChecked(true)
+	if e.issuerMK.Password || e.iccMK.Password || e.sessionKey.Password {
+		t.Error("keys masked after reveal")
+	}
+
+	e.Cleanup()
+	if e.issuerMK.Text != "" || e.iccMK.Text != "" || e.pan.Text != "" || e.kcvs[e.iccMK].Text != "" {
+		t.Error("Cleanup() kept values")
+	}
+	if !e.iccMK.Password || e.errMsg.Visible() {
+		t.Error("Cleanup() left keys revealed or the error shown")
+	}
+}
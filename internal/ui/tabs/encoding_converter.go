@@ -0,0 +1,196 @@
+package tabs
+
+import (
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// converterFormats are the representations shown by the converter, in
+// display order.
+var converterFormats = []utils.DataFormat{
+	utils.DataHex,
+	utils.DataDecimal,
+	utils.DataBinary,
+	utils.DataASCII,
+	utils.DataEBCDIC,
+	utils.DataBase64,
+}
+
+// EncodingConverter represents the Base/Encoding Converter tab.
+type EncodingConverter struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	input     *widget.Entry
+	source    *widget.Select // Format the input is read as.
+	inputHint *widget.Label
+	length    *widget.Label
+
+	// outputs holds one editable field per format.
+	outputs map[utils.DataFormat]*widget.Entry
+	// updating is set while fields are filled, so their change callbacks
+	// do not convert again.
+	updating bool
+}
+
+// NewEncodingConverter creates a new Base/Encoding Converter tab.
+func NewEncodingConverter() *EncodingConverter {
+	c := &EncodingConverter{outputs: make(map[utils.DataFormat]*widget.Entry)}
+	c.ExtendBaseWidget(c)
+
+	options := make([]string, len(converterFormats))
+	for i, f := range converterFormats {
+		options[i] = string(f)
+	}
+	c.input = widget.NewMultiLineEntry()
+	c.input.SetPlaceHolder("Enter a value in the selected format")
+	c.input.Wrapping = fyne.TextWrapBreak
+	c.input.OnChanged = func(text string) {
+		if !c.updating {
+			c.onInputChanged(text)
+		}
+	}
+	c.source = widget.NewSelect(options, nil)
+	c.source.SetSelected(string(utils.DataHex))
+	c.source.OnChanged = func(string) {
+		if !c.updating {
+			c.onInputChanged(c.input.Text)
+		}
+	}
+
+	c.inputHint = widget.NewLabel("")
+	c.inputHint.Importance = widget.DangerImportance
+	c.inputHint.Wrapping = fyne.TextWrapWord
+	c.inputHint.Hide()
+	c.length = widget.NewLabel("")
+
+	form := widget.NewForm()
+	for _, f := range converterFormats {
+		e := widget.NewMultiLineEntry()
+		e.Wrapping = fyne.TextWrapBreak
+		e.SetMinRowsVisible(1)
+		e.OnChanged = func(text string) {
+			if !c.updating {
+				c.onOutputEdited(f, text)
+			}
+		}
+		c.outputs[f] = e
+		form.Append(string(f), container.NewBorder(nil, nil, nil,
+			newCopyButton(func() string { return e.Text }), e))
+	}
+
+	c.container = container.NewVBox(
+		widget.NewCard("Input", "", container.NewVBox(
+			widget.NewForm(widget.NewFormItem("Format", c.source)),
+			c.input,
+			c.inputHint,
+			c.length,
+		)),
+		widget.NewCard("Representations", "Edit any field to convert from it", form),
+	)
+	c.show(nil, "")
+
+	return c
+}
+
+// onInputChanged reads the input in the selected format and shows it in
+// every format.
+func (c *EncodingConverter) onInputChanged(text string) {
+	c.convert(text, utils.DataFormat(c.source.Selected), "")
+}
+
+// onOutputEdited makes an edited field the input, reading it in its own
+// format. The edited field itself is left as typed.
+func (c *EncodingConverter) onOutputEdited(f utils.DataFormat, text string) {
+	c.updating = true
+	c.source.SetSelected(string(f))
+	c.input.SetText(text)
+	c.updating = false
+
+	c.convert(text, f, f)
+}
+
+// convert decodes text as f and shows the data in every field but skip. An
+// input that is not valid in f greys all outputs.
+func (c *EncodingConverter) convert(text string, f utils.DataFormat, skip utils.DataFormat) {
+	if strings.TrimSpace(text) == "" {
+		showHint(c.inputHint, nil)
+		c.show(nil, skip)
+		return
+	}
+
+	data, err := utils.DecodeData(text, f)
+	if err != nil {
+		showHint(c.inputHint, fmt.Errorf("not valid %s: %v", f, err))
+		c.grey(skip)
+		return
+	}
+	showHint(c.inputHint, nil)
+	c.show(data, skip)
+}
+
+// show fills every field but skip with data. Fields whose format cannot
+// represent data are greyed.
+func (c *EncodingConverter) show(data []byte, skip utils.DataFormat) {
+	c.updating = true
+	defer func() { c.updating = false }()
+
+	for _, f := range converterFormats {
+		e := c.outputs[f]
+		if f == skip {
+			e.Enable()
+			continue
+		}
+		text, err := utils.EncodeData(data, f)
+		if err != nil {
+			e.SetText("")
+			e.SetPlaceHolder("Not representable")
+			e.Disable()
+			continue
+		}
+		e.SetPlaceHolder("")
+		e.SetText(text)
+		e.Enable()
+	}
+	c.length.SetText(converterLength(len(data)))
+}
+
+// grey clears and greys every field but skip.
+func (c *EncodingConverter) grey(skip utils.DataFormat) {
+	c.updating = true
+	defer func() { c.updating = false }()
+
+	for _, f := range converterFormats {
+		if f == skip {
+			continue
+		}
+		c.outputs[f].SetText("")
+		c.outputs[f].SetPlaceHolder("")
+		c.outputs[f].Disable()
+	}
+	c.length.SetText("")
+}
+
+// converterLength describes a length of n bytes in bytes and bits.
+func converterLength(n int) string {
+	unit := "bytes"
+	if n == 1 {
+		unit = "byte"
+	}
+
+	return fmt.Sprintf("Length: %d %s, %d bits", n, unit, 8*n)
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (c *EncodingConverter) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(c.container)
+}
+
+// Cleanup implements TabContent interface.
+func (c *EncodingConverter) Cleanup() {}
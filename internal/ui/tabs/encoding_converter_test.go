@@ -0,0 +1,167 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+func TestEncodingConverter_SourceFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		source utils.DataFormat
+		input  string
+		want   map[utils.DataFormat]string
+		greyed []utils.DataFormat
+	}{
+		{
+			"10_as_hex",
+			utils.DataHex,
+			"10",
+			map[utils.DataFormat]string{
+				utils.DataHex: "10", utils.DataDecimal: "16", utils.DataBinary: "00010000", utils.DataBase64: "EA==",
+			},
+			[]utils.DataFormat{utils.DataASCII, utils.DataEBCDIC},
+		},
+		{
+			"10_as_decimal",
+			utils.DataDecimal,
+			"10",
+			map[utils.DataFormat]string{
+				utils.DataHex: "0A", utils.DataDecimal: "10", utils.DataBinary: "00001010", utils.DataBase64: "Cg==",
+			},
+			[]utils.DataFormat{utils.DataASCII, utils.DataEBCDIC},
+		},
+		{
+			"10_as_ascii",
+			utils.DataASCII,
+			"10",
+			map[utils.DataFormat]string{
+				utils.DataHex: "3130", utils.DataDecimal: "12592", utils.DataASCII: "10", utils.DataBase64: "MTA=",
+			},
+			[]utils.DataFormat{utils.DataEBCDIC},
+		},
+		{
+			"10_as_binary",
+			utils.DataBinary,
+			"10",
+			map[utils.DataFormat]string{utils.DataHex: "02", utils.DataBinary: "00000010"},
+			nil,
+		},
+		{
+			"ebcdic_text",
+			utils.DataEBCDIC,
+			"HSM",
+			map[utils.DataFormat]string{utils.DataHex: "C8E2D4", utils.DataEBCDIC: "HSM"},
+			[]utils.DataFormat{utils.DataASCII},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+			c := NewEncodingConverter()
+			c.source.SetSelected(string(tt.source))
+			c.input.SetText(tt.input)
+
+			for f, want := range tt.want {
+				if got := c.outputs[f].Text; got != want {
+					t.Errorf("%s = %q, want %q", f, got, want)
+				}
+			}
+			for _, f := range tt.greyed {
+				if !c.outputs[f].Disabled() || c.outputs[f].Text != "" {
+					t.Errorf("%s = %q, want greyed", f, c.outputs[f].Text)
+				}
+			}
+			if c.inputHint.Visible() {
+				t.Errorf("input hint shown: %q", c.inputHint.Text)
+			}
+		})
+	}
+}
+
+func TestEncodingConverter_SourceChangeReinterprets(t *testing.T) {
+	test.NewTempApp(t)
+	c := NewEncodingConverter()
+	c.input.SetText("10")
+	if c.outputs[utils.DataDecimal].Text != "16" {
+		t.Fatalf("hex 10 as decimal = %q", c.outputs[utils.DataDecimal].Text)
+	}
+
+	c.source.SetSelected(string(utils.DataDecimal))
+	if got := c.outputs[utils.DataHex].Text; got != "0A" {
+		t.Errorf("decimal 10 as hex = %q, want 0A", got)
+	}
+}
+
+func TestEncodingConverter_OutputEdits(t *testing.T) {
+	test.NewTempApp(t)
+	c := NewEncodingConverter()
+
+	// Editing an output makes it the source and updates the others.
+	c.outputs[utils.DataDecimal].SetText("16706")
+	if c.source.Selected != string(utils.DataDecimal) || c.input.Text != "16706" {
+		t.Errorf("source = %s %q, want the edited decimal field", c.source.Selected, c.input.Text)
+	}
+	want := map[utils.DataFormat]string{
+		utils.DataHex: "4142", utils.DataDecimal: "16706", utils.DataBinary: "0100000101000010",
+		utils.DataASCII: "AB", utils.DataBase64: "QUI=",
+	}
+	for f, w := range want {
+		if got := c.outputs[f].Text; got != w {
+			t.Errorf("%s = %q, want %q", f, got, w)
+		}
+	}
+	if !c.outputs[utils.DataEBCDIC].Disabled() {
+		t.Error("EBCDIC not greyed for bytes without EBCDIC text")
+	}
+
+	// The edited field keeps its text as typed rather than being rewritten.
+	c.outputs[utils.DataHex].SetText("0x41 42")
+	if got := c.outputs[utils.DataHex].Text; got != "0x41 42" {
+		t.Errorf("edited hex field = %q, want it as typed", got)
+	}
+	if got := c.outputs[utils.DataASCII].Text; got != "AB" {
+		t.Errorf("ASCII = %q, want AB", got)
+	}
+
+	// A greyed field comes back once the data can be shown in it.
+	c.outputs[utils.DataHex].SetText("C8E2D4")
+	if c.outputs[utils.DataEBCDIC].Disabled() || c.outputs[utils.DataEBCDIC].Text != "HSM" {
+		t.Errorf("EBCDIC = %q (greyed %v), want HSM", c.outputs[utils.DataEBCDIC].Text, c.outputs[utils.DataEBCDIC].Disabled())
+	}
+	if !c.outputs[utils.DataASCII].Disabled() {
+		t.Error("ASCII not greyed for bytes above 0x7E")
+	}
+	if c.length.Text != "Length: 3 bytes, 24 bits" {
+		t.Errorf("length = %q", c.length.Text)
+	}
+}
+
+func TestEncodingConverter_InvalidInput(t *testing.T) {
+	test.NewTempApp(t)
+	c := NewEncodingConverter()
+	c.input.SetText("XYZ")
+
+	if !c.inputHint.Visible() || !strings.Contains(c.inputHint.Text, "Hex") {
+		t.Errorf("hint = %q (visible %v), want a hex error", c.inputHint.Text, c.inputHint.Visible())
+	}
+	for _, f := range converterFormats {
+		if !c.outputs[f].Disabled() {
+			t.Errorf("%s not greyed for invalid input", f)
+		}
+	}
+
+	c.input.SetText("41")
+	if c.inputHint.Visible() || c.outputs[utils.DataASCII].Text != "A" || c.outputs[utils.DataHex].Disabled() {
+		t.Errorf("valid input after invalid: hint %v, ASCII %q", c.inputHint.Visible(), c.outputs[utils.DataASCII].Text)
+	}
+	if c.length.Text != "Length: 1 byte, 8 bits" {
+		t.Errorf("length = %q", c.length.Text)
+	}
+}
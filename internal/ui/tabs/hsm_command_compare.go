@@ -0,0 +1,151 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// initializeCompare creates the controls of the comparison mode, which sends
+// every request to a second HSM as well.
+func (hs *HSMCommandSender) initializeCompare() {
+	hs.secondary = hsm.NewConnection(func(state hsm.ConnectionState) {
+		fyne.Do(func() { hs.showSecondaryState(state) })
+	})
+
+	hs.secondaryHost = widget.NewEntry()
+	hs.secondaryHost.SetPlaceHolder("Host")
+	hs.secondaryPort = widget.NewEntry()
+	hs.secondaryPort.SetPlaceHolder("Port")
+	hs.secondaryBtn = widget.NewButton("Connect", hs.onSecondaryConnect)
+	hs.secondaryState = widget.NewLabel("")
+	hs.showSecondaryState(hsm.Disconnected)
+
+	hs.compareMask = widget.NewEntry()
+	hs.compareMask.SetPlaceHolder("Ignored response bytes, e.g. 0:4,9:")
+	hs.compareMask.Validator = func(s string) error {
+		_, err := sender.ParseMask(s)
+		return err
+	}
+	hs.compareLbl = widget.NewLabel("")
+
+	hs.compareBox = container.NewVBox(
+		container.NewBorder(nil, nil,
+			widget.NewLabel("Second HSM"),
+			container.NewHBox(hs.secondaryBtn, hs.secondaryState),
+			container.NewGridWithColumns(2, hs.secondaryHost, hs.secondaryPort),
+		),
+		container.NewBorder(nil, nil, widget.NewLabel("Mask"), nil, hs.compareMask),
+	)
+	hs.compareBox.Hide()
+
+	hs.compare = widget.NewCheck("Compare with a second HSM", func(checked bool) {
+		if checked {
+			hs.compareBox.Show()
+		} else {
+			hs.compareBox.Hide()
+		}
+	})
+}
+
+// showSecondaryState renders the state of the second HSM connection.
+func (hs *HSMCommandSender) showSecondaryState(state hsm.ConnectionState) {
+	hs.secondaryState.SetText(state.String())
+	if state == hsm.Connected {
+		hs.secondaryState.Importance = widget.SuccessImportance
+		hs.secondaryBtn.SetText("Disconnect")
+		hs.secondaryHost.Disable()
+		hs.secondaryPort.Disable()
+	} else {
+		hs.secondaryState.Importance = widget.DangerImportance
+		hs.secondaryBtn.SetText("Connect")
+		hs.secondaryHost.Enable()
+		hs.secondaryPort.Enable()
+	}
+	hs.secondaryState.Refresh()
+}
+
+// onSecondaryConnect connects to or disconnects from the second HSM. The
+// second HSM gets as many pooled connections as the primary one.
+func (hs *HSMCommandSender) onSecondaryConnect() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	if hs.secondary.GetState() == hsm.Connected {
+		hs.secondaryBtn.Disable()
+		go func() {
+			err := hs.secondary.Disconnect()
+			fyne.Do(func() {
+				hs.secondaryBtn.Enable()
+				if err != nil {
+					dialog.ShowError(err, w)
+				}
+			})
+		}()
+
+		return
+	}
+
+	host := strings.TrimSpace(hs.secondaryHost.Text)
+	if err := utils.ValidateHostOrIP(host); err != nil {
+		dialog.ShowError(fmt.Errorf("invalid HSM host %q: %v", host, err), w)
+		return
+	}
+	port := strings.TrimSpace(hs.secondaryPort.Text)
+	if port == "" {
+		dialog.ShowError(errors.New("port of the second HSM is required"), w)
+		return
+	}
+
+	hs.secondaryBtn.Disable()
+	hs.secondaryBtn.SetText("Connecting...")
+	conns := max(hs.connection.GetPoolCapacity(), 1)
+	go func() {
+		err := hs.secondary.Connect(host, port, conns)
+		fyne.Do(func() {
+			hs.secondaryBtn.Enable()
+			if err != nil {
+				dialog.ShowError(err, w)
+				hs.showSecondaryState(hs.secondary.GetState())
+			}
+		})
+	}()
+}
+
+// configureCompare sets up cfg to compare every response with the second
+// HSM when the comparison mode is on.
+func (hs *HSMCommandSender) configureCompare(cfg *sender.Config) error {
+	if !hs.compare.Checked {
+		return nil
+	}
+	if hs.secondary.GetState() != hsm.Connected {
+		return errors.New("second HSM not connected - connect it or switch off the comparison")
+	}
+	mask, err := sender.ParseMask(hs.compareMask.Text)
+	if err != nil {
+		return err
+	}
+	cfg.Secondary = connExecutor{hs.secondary}
+	cfg.Mask = mask
+
+	return nil
+}
+
+// showComparison renders the mismatch counter of a comparison run.
+func (hs *HSMCommandSender) showComparison(mismatches, total int) {
+	hs.compareLbl.SetText(fmt.Sprintf("Mismatches: %d/%d", mismatches, total))
+	if mismatches > 0 {
+		hs.compareLbl.Importance = widget.DangerImportance
+	} else {
+		hs.compareLbl.Importance = widget.SuccessImportance
+	}
+	hs.compareLbl.Refresh()
+}
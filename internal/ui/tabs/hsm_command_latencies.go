@@ -0,0 +1,50 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+)
+
+// newLatencyLog creates the per-request latency log of a run. It holds as
+// many requests as the history unless all latencies are kept.
+func (hs *HSMCommandSender) newLatencyLog() *sender.LatencyLog {
+	if hs.keepLatencies.Checked {
+		return sender.NewLatencyLog(0)
+	}
+
+	return sender.NewLatencyLog(hs.history.Cap())
+}
+
+// onExportLatencies writes the per-request latencies of the last run to a
+// CSV file chosen by the user.
+func (hs *HSMCommandSender) onExportLatencies() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	samples := hs.runSamples
+	if len(samples) == 0 {
+		dialog.ShowError(errors.New("no latencies to export"), w)
+		return
+	}
+
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if err := sender.ExportLatencies(wc, samples); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+		}
+	}, w)
+	save.SetFileName("hsm_latencies" + sender.ExportCSV.Extension())
+	save.Show()
+}
@@ -0,0 +1,53 @@
+package tabs
+
+import (
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+)
+
+// runPeriodic sends one request per interval until stopped and keeps the
+// uptime and failure counters up to date.
+func (hs *HSMCommandSender) runPeriodic(
+	stop <-chan struct{},
+	cfg sender.Config,
+	interval time.Duration,
+) {
+	samples := hs.latencyLog
+	summary := sender.RunPeriodic(stop, connExecutor{hs.connection}, cfg, interval,
+		func(r sender.Result) {
+			hs.addResponse(r)
+			samples.Record(r)
+		},
+		func(s sender.PeriodicSummary) {
+			fyne.Do(func() {
+				hs.flushResponses()
+				hs.showPeriodic(s)
+			})
+		},
+	)
+
+	fyne.Do(func() {
+		hs.sendMutex.Lock()
+		defer hs.sendMutex.Unlock()
+
+		hs.transition(sender.EventFinish)
+		hs.runSamples = samples.Samples()
+		hs.flushResponses()
+		hs.showPeriodic(summary)
+		hs.appendHistory("Periodic run: " + summary.String())
+	})
+}
+
+// showPeriodic renders the counters of a periodic run.
+func (hs *HSMCommandSender) showPeriodic(s sender.PeriodicSummary) {
+	hs.counter.SetText(s.String())
+	switch {
+	case s.Down:
+		hs.tpsLabel.SetText("Last request failed")
+	case s.Sent > 0:
+		hs.tpsLabel.SetText("Last request succeeded")
+	}
+}
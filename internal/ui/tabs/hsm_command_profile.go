@@ -0,0 +1,183 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// profileRow is an editable step of the load profile.
+type profileRow struct {
+	tps     *widget.Entry
+	seconds *widget.Entry
+	box     *fyne.Container
+}
+
+// initializeProfile creates the load profile editor with a default ramp.
+func (hs *HSMCommandSender) initializeProfile() {
+	hs.profileList = container.NewVBox()
+	for _, tps := range []string{"50", "100", "200"} {
+		hs.addProfileRow(tps, "30")
+	}
+
+	addBtn := widget.NewButtonWithIcon("Add step", theme.ContentAddIcon(), func() {
+		hs.addProfileRow("", "30")
+	})
+	hs.profileBox = container.NewVBox(
+		widget.NewLabelWithStyle("Load Profile", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWithColumns(3,
+			widget.NewLabel("Target TPS"),
+			widget.NewLabel("Duration (seconds)"),
+			layout.NewSpacer(),
+		),
+		hs.profileList,
+		container.NewHBox(addBtn),
+	)
+	hs.profileBox.Hide()
+}
+
+// addProfileRow appends a step to the load profile editor.
+func (hs *HSMCommandSender) addProfileRow(tps, seconds string) {
+	row := &profileRow{tps: widget.NewEntry(), seconds: widget.NewEntry()}
+	row.tps.SetText(tps)
+	row.tps.SetPlaceHolder("TPS")
+	row.seconds.SetText(seconds)
+	row.seconds.SetPlaceHolder("Seconds")
+
+	removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+		for i, r := range hs.profileRows {
+			if r == row {
+				hs.profileRows = append(hs.profileRows[:i], hs.profileRows[i+1:]...)
+				break
+			}
+		}
+		hs.profileList.Remove(row.box)
+	})
+	row.box = container.NewGridWithColumns(3, row.tps, row.seconds, container.NewHBox(removeBtn))
+
+	hs.profileRows = append(hs.profileRows, row)
+	hs.profileList.Add(row.box)
+}
+
+// setProfile replaces the steps of the load profile editor.
+func (hs *HSMCommandSender) setProfile(steps []storage.ProfileStep) {
+	hs.profileRows = nil
+	hs.profileList.RemoveAll()
+	for _, s := range steps {
+		hs.addProfileRow(strconv.FormatFloat(s.TPS, 'f', -1, 64), strconv.Itoa(s.DurationSeconds))
+	}
+}
+
+// profileSteps parses and validates the load profile.
+func (hs *HSMCommandSender) profileSteps() ([]sender.ProfileStep, error) {
+	steps := make([]sender.ProfileStep, 0, len(hs.profileRows))
+	for i, row := range hs.profileRows {
+		tps, err := strconv.ParseFloat(row.tps.Text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: invalid target TPS %q", i+1, row.tps.Text)
+		}
+		seconds, err := strconv.Atoi(row.seconds.Text)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: invalid duration %q", i+1, row.seconds.Text)
+		}
+		steps = append(steps, sender.ProfileStep{TPS: tps, Duration: time.Duration(seconds) * time.Second})
+	}
+
+	return steps, sender.ValidateProfile(steps)
+}
+
+// storedProfile returns the load profile as stored in scenarios, skipping
+// steps that do not parse.
+func (hs *HSMCommandSender) storedProfile() []storage.ProfileStep {
+	var steps []storage.ProfileStep
+	for _, row := range hs.profileRows {
+		tps, err1 := strconv.ParseFloat(row.tps.Text, 64)
+		seconds, err2 := strconv.Atoi(row.seconds.Text)
+		if err1 == nil && err2 == nil {
+			steps = append(steps, storage.ProfileStep{TPS: tps, DurationSeconds: seconds})
+		}
+	}
+
+	return steps
+}
+
+// runProfile runs the load profile and shows the per-step summary table.
+func (hs *HSMCommandSender) runProfile(
+	stop <-chan struct{},
+	cfg sender.Config,
+	steps []sender.ProfileStep,
+) {
+	var completed, current atomic.Int32
+	start := time.Now()
+	series := sender.NewTPSSeries(start)
+	tally := sender.NewCodeTally()
+	slow := sender.NewSlowCounter(hs.threshold)
+	samples := hs.latencyLog
+
+	// Publish the counters at a fixed rate rather than once per response.
+	stopUpdates := sender.Throttle(sender.DefaultRefreshInterval, func() {
+		n, i := completed.Load(), current.Load()
+		elapsed := cfg.Pause.Active(start, time.Now())
+		paused := cfg.Pause.Paused()
+		recent := series.Last(time.Now(), sender.SparklineWindow)
+		fyne.Do(func() {
+			hs.flushResponses()
+			hs.tpsChart.SetValues(recent)
+			hs.progress.SetValue(min(elapsed.Seconds(), hs.progress.Max))
+			hs.counter.SetText(fmt.Sprintf("Completed: %d", n))
+			if paused {
+				hs.tpsLabel.SetText(fmt.Sprintf("Step %d/%d: paused", i+1, len(steps)))
+			} else {
+				hs.tpsLabel.SetText(fmt.Sprintf("Step %d/%d: target %.0f TPS", i+1, len(steps), steps[i].TPS))
+			}
+			hs.showSlow(slow)
+		})
+	})
+
+	report := sender.RunProfile(stop, connExecutor{hs.connection}, cfg, steps,
+		func(step int) { current.Store(int32(step)) },
+		func(_ int, r sender.Result) {
+			hs.addResponse(r)
+			tally.Record(r)
+			samples.Record(r)
+			if !r.Warmup && !r.Cancelled() && !sender.IsConnectionError(r.Err) {
+				series.Record(time.Now())
+				slow.Record(r.Latency)
+				completed.Add(1)
+			}
+		},
+	)
+	stopUpdates()
+	tps, codes := series.Counts(time.Now()), tally.Counts()
+
+	fyne.Do(func() {
+		hs.sendMutex.Lock()
+		defer hs.sendMutex.Unlock()
+
+		hs.runTPS, hs.runCodes, hs.runSamples = tps, codes, samples.Samples()
+		hs.transition(sender.EventFinish)
+		hs.tpsLabel.SetText(report.Summary())
+		hs.appendHistory(report.String())
+		hs.showCodes(codes)
+		hs.finishSlow(slow)
+
+		if report.ConnectionLost {
+			dialog.ShowError(
+				errors.New("hsm connection lost during load profile"),
+				fyne.CurrentApp().Driver().AllWindows()[0],
+			)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// initializeScenarios creates the scenario controls and returns their row.
+// Without a scenario store the controls are disabled.
+func (hs *HSMCommandSender) initializeScenarios() fyne.CanvasObject {
+	hs.scenarioSel = widget.NewSelect(nil, nil)
+	hs.scenarioSel.PlaceHolder = "Select a scenario"
+	loadBtn := widget.NewButtonWithIcon("Load", theme.FolderOpenIcon(), hs.onLoadScenario)
+	saveBtn := widget.NewButtonWithIcon("Save scenario…", theme.DocumentSaveIcon(), hs.onSaveScenario)
+	deleteBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), hs.onDeleteScenario)
+
+	if hs.scenarios == nil {
+		hs.scenarioSel.Disable()
+		loadBtn.Disable()
+		saveBtn.Disable()
+		deleteBtn.Disable()
+	} else {
+		hs.refreshScenarios()
+	}
+
+	return container.NewBorder(nil, nil,
+		widget.NewLabelWithStyle("Scenario", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(loadBtn, saveBtn, deleteBtn),
+		hs.scenarioSel,
+	)
+}
+
+// refreshScenarios reloads the scenario names into the dropdown.
+func (hs *HSMCommandSender) refreshScenarios() {
+	names, err := hs.scenarios.List()
+	if err != nil {
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+		return
+	}
+	hs.scenarioSel.SetOptions(names)
+}
+
+// currentScenario captures the current test setup under name.
+func (hs *HSMCommandSender) currentScenario(name string) storage.Scenario {
+	s := storage.Scenario{
+		Name:          name,
+		Command:       hs.command.Text,
+		InputMode:     hs.inputMode.Selected,
+		PerLine:       hs.perLine.Checked,
+		Concurrent:    !hs.logHistory,
+		Expected:      hs.expected.Text,
+		StopOnFailure: hs.stopOnFail.Checked,
+	}
+	switch hs.loadMode.Selected {
+	case loadModeProfile:
+		s.Profile = hs.storedProfile()
+	case loadModeDuration:
+		s.DurationSeconds, _ = strconv.Atoi(hs.duration.Text)
+	case loadModeRepeat:
+		s.IntervalSeconds, _ = strconv.Atoi(hs.interval.Text)
+	default:
+		s.Count, _ = strconv.Atoi(hs.reqCount.Text)
+	}
+	if s.Concurrent {
+		s.Workers, _ = strconv.Atoi(hs.workers.Text)
+	}
+	s.TimeoutMillis, _ = strconv.Atoi(hs.timeout.Text)
+	s.Warmup, _ = strconv.Atoi(hs.warmup.Text)
+	s.ThresholdMillis, _ = strconv.Atoi(hs.slowAfter.Text)
+	if hs.retry.Checked {
+		s.Retries, _ = strconv.Atoi(hs.retryCount.Selected)
+	}
+
+	return s
+}
+
+// applyScenario fills the fields from s. It never starts sending.
+func (hs *HSMCommandSender) applyScenario(s storage.Scenario) {
+	if s.InputMode != "" {
+		hs.inputMode.SetSelected(s.InputMode)
+	} else {
+		hs.inputMode.SetSelected(string(sender.InputASCII))
+	}
+	hs.command.SetText(s.Command)
+	hs.perLine.SetChecked(s.PerLine)
+	switch {
+	case len(s.Profile) > 0:
+		hs.loadMode.SetSelected(loadModeProfile)
+		hs.setProfile(s.Profile)
+	case s.DurationSeconds > 0:
+		hs.loadMode.SetSelected(loadModeDuration)
+		hs.duration.SetText(strconv.Itoa(s.DurationSeconds))
+	case s.IntervalSeconds > 0:
+		hs.loadMode.SetSelected(loadModeRepeat)
+		hs.interval.SetText(strconv.Itoa(s.IntervalSeconds))
+	default:
+		hs.loadMode.SetSelected(loadModeCount)
+		hs.reqCount.SetText(strconv.Itoa(s.Count))
+	}
+	hs.logHistoryCheckbox.SetChecked(!s.Concurrent)
+	if s.Workers > 0 {
+		hs.workers.SetText(strconv.Itoa(s.Workers))
+	} else {
+		hs.workers.SetText("")
+	}
+	if s.TimeoutMillis > 0 {
+		hs.timeout.SetText(strconv.Itoa(s.TimeoutMillis))
+	}
+	hs.warmup.SetText(strconv.Itoa(s.Warmup))
+	if s.ThresholdMillis > 0 {
+		hs.slowAfter.SetText(strconv.Itoa(s.ThresholdMillis))
+	} else {
+		hs.slowAfter.SetText("")
+	}
+	hs.expected.SetText(s.Expected)
+	hs.stopOnFail.SetChecked(s.StopOnFailure)
+	hs.retry.SetChecked(s.Retries > 0)
+	if s.Retries > 0 {
+		hs.retryCount.SetSelected(strconv.Itoa(s.Retries))
+	}
+}
+
+// onLoadScenario fills the fields from the selected scenario.
+func (hs *HSMCommandSender) onLoadScenario() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if hs.scenarioSel.Selected == "" {
+		dialog.ShowError(errors.New("select a scenario to load"), w)
+		return
+	}
+
+	s, err := hs.scenarios.Load(hs.scenarioSel.Selected)
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	hs.applyScenario(s)
+}
+
+// onSaveScenario asks for a name and stores the current setup, confirming
+// before an existing scenario is replaced.
+func (hs *HSMCommandSender) onSaveScenario() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	name := widget.NewEntry()
+	name.SetText(hs.scenarioSel.Selected)
+	name.Validator = storage.ValidateScenarioName
+	items := []*widget.FormItem{widget.NewFormItem("Name", name)}
+	if hs.currentScenario("").ContainsClearKey() {
+		warning := widget.NewLabel("The command looks like it contains a clear key.\n" +
+			"Scenarios are stored unencrypted.")
+		warning.Importance = widget.WarningImportance
+		items = append(items, widget.NewFormItem("", warning))
+	}
+
+	dialog.ShowForm("Save Scenario", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		s := hs.currentScenario(name.Text)
+		err := hs.scenarios.Save(s, false)
+		if errors.Is(err, storage.ErrScenarioExists) {
+			dialog.ShowConfirm(
+				"Overwrite Scenario",
+				fmt.Sprintf("Scenario %q already exists. Overwrite it?", s.Name),
+				func(overwrite bool) {
+					if overwrite {
+						hs.finishSaveScenario(s.Name, hs.scenarios.Save(s, true))
+					}
+				},
+				w,
+			)
+
+			return
+		}
+		hs.finishSaveScenario(s.Name, err)
+	}, w)
+}
+
+// finishSaveScenario reports a failed save or selects the saved scenario.
+func (hs *HSMCommandSender) finishSaveScenario(name string, err error) {
+	if err != nil {
+		dialog.ShowError(
+			fmt.Errorf("failed to save scenario: %v", err),
+			fyne.CurrentApp().Driver().AllWindows()[0],
+		)
+
+		return
+	}
+	hs.refreshScenarios()
+	hs.scenarioSel.SetSelected(name)
+}
+
+// onDeleteScenario deletes the selected scenario after confirmation.
+func (hs *HSMCommandSender) onDeleteScenario() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	name := hs.scenarioSel.Selected
+	if name == "" {
+		dialog.ShowError(errors.New("select a scenario to delete"), w)
+		return
+	}
+
+	dialog.ShowConfirm("Delete Scenario", fmt.Sprintf("Delete scenario %q?", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := hs.scenarios.Delete(name); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		hs.scenarioSel.ClearSelected()
+		hs.refreshScenarios()
+	}, w)
+}
@@ -4,6 +4,7 @@ import (
 	"errors" // Added for errors.New.
 	"fmt"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -11,18 +12,33 @@ import (
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
 )
 
-// Response represents a single HSM request/response pair.
-type Response struct {
-	Timestamp time.Time
-	Request   string
-	Response  string
-	Latency   time.Duration
-}
+// Load modes of the Command Sender.
+const (
+	loadModeCount    = "Count"
+	loadModeDuration = "Duration"
+	loadModeProfile  = "Profile"
+	loadModeRepeat   = "Repeat"
+)
+
+// defaultRepeatSeconds is the initial interval of periodic sending.
+const defaultRepeatSeconds = "30"
+
+// Preference keys of the Command Sender.
+const (
+	prefRememberCommands = "sender.remember_commands"
+	prefRecentCommands   = "sender.recent_commands"
+	prefTimeoutMillis    = "sender.timeout_ms"
+)
 
 // HSMCommandSender represents the HSM Command Sender tab.
 type HSMCommandSender struct {
@@ -30,47 +46,127 @@ type HSMCommandSender struct {
 	container *fyne.Container
 
 	// Input fields.
-	command  *widget.Entry
-	reqCount *widget.Entry
-	duration *widget.Entry
+	command     *widget.Entry
+	recent      *sender.RecentCommands // recently sent commands
+	recentCmds  *widget.Select         // recalls a recent command
+	rememberCmd *widget.Check          // persist recent commands
+	perLine     *widget.Check          // send each line as a separate command
+	inputMode   *widget.Select         // how command text is turned into bytes
+	inputErr    *widget.Label          // why the command cannot be sent
+	loadMode    *widget.RadioGroup     // count, duration, profile or periodic load
+	reqCount    *widget.Entry
+	reqCountBox *fyne.Container
+	duration    *widget.Entry // run length in seconds
+	durationBox *fyne.Container
+	interval    *widget.Entry // seconds between periodic requests
+	intervalBox *fyne.Container
+	profileRows []*profileRow   // steps of the load profile
+	profileList *fyne.Container // editor rows of the load profile
+	profileBox  *fyne.Container
+	timeout     *widget.Entry  // per-request timeout in milliseconds
+	slowAfter   *widget.Entry  // latency threshold in milliseconds
+	threshold   time.Duration  // latency threshold of the current run, zero when off
+	warmup      *widget.Entry  // requests excluded from the statistics
+	expected    *widget.Entry  // expected response prefix
+	stopOnFail  *widget.Check  // end the run at the first unexpected response
+	retry       *widget.Check  // resend timed out requests
+	retryCount  *widget.Select // number of retries per request
+	workers     *widget.Entry  // concurrent senders, pool capacity when empty
+	workersWarn *widget.Label  // shown when workers exceed the pool capacity
+
+	// Comparison mode.
+	compare        *widget.Check   // send every request to a second HSM too
+	compareBox     *fyne.Container // second HSM and mask controls
+	secondary      *hsm.Connection // second HSM of the comparison mode
+	secondaryHost  *widget.Entry
+	secondaryPort  *widget.Entry
+	secondaryBtn   *widget.Button
+	secondaryState *widget.Label
+	compareMask    *widget.Entry // response bytes ignored when comparing
+	compareLbl     *widget.Label // mismatch counter of the last comparison run
 
 	// Status indicators.
-	progress   *widget.ProgressBar
-	counter    *widget.Label
-	tpsLabel   *widget.Label
-	responses  []Response
-	respMutex  sync.Mutex
-	connection *hsm.Connection
+	progress    *widget.ProgressBar
+	counter     *widget.Label
+	tpsLabel    *widget.Label
+	tpsChart    *sparkline             // completed requests per second, recent window
+	runTPS      []int                  // completed requests per second of the last run
+	runCodes    sender.CodeCounts      // response code distribution of the last run
+	runSlow     *sender.SlowStats      // threshold breaches of the last run, if a threshold was set
+	latencyLog  *sender.LatencyLog     // per-request latencies of the current run
+	runSamples  []sender.LatencySample // per-request latencies of the last run
+	slowLbl     *widget.Label          // slow request counter
+	latencyLbl  *widget.Label          // latency percentiles of the last run
+	assertLbl   *widget.Label          // pass/fail counters of response checks
+	lastReport  *sender.ScriptReport   // report of the last script run, if any
+	connection  *hsm.Connection
+	store       *storage.KeyStore      // resolves {{key:NAME}}, may be nil
+	scenarios   *storage.ScenarioStore // saved test setups, may be nil
+	scenarioSel *widget.Select         // lists the saved scenarios
 
 	// Response fields.
-	commandResponseField *widget.Entry // Field for the latest command response.
-	commandHistoryField  *widget.Entry // Field for the command history.
+	commandResponseField *widget.Entry              // Printable ASCII of the latest response.
+	responseHex          *widget.Entry              // Grouped hex of the latest response.
+	selectionLbl         *widget.Label              // Offset and length of the pane selection.
+	responseStatus       *widget.Label              // Decoded response and error code.
+	hexView              *widget.Check              // Toggles the hex dump panes.
+	hexPanes             *fyne.Container            // Request and response hex dumps.
+	requestDump          *widget.Entry              // Hex dump of the latest request.
+	responseDump         *widget.Entry              // Hex dump of the latest response.
+	lengthLbl            *widget.Label              // Byte lengths of the latest exchange.
+	lastResult           *sender.Result             // Latest result, rendered by both views.
+	history              *sender.Ring[historyEntry] // Bounded command history.
+	historyList          *widget.List               // Renders the history lazily.
+	historyLimit         *widget.Entry              // Number of history entries kept.
 
 	// Control.
 	sendBtn   *widget.Button
 	stopBtn   *widget.Button
-	isSending bool
+	pauseBtn  *widget.Button // pauses or resumes the current run
+	exportBtn *widget.Button
+	latExpBtn *widget.Button // exports the per-request latencies
+	scriptBtn *widget.Button
+	state     sender.RunState // idle, sending, paused or stopping
+	pause     *sender.Pause   // holds back the current run
 	stopChan  chan struct{}
 	sendMutex sync.Mutex
-	started   sync.WaitGroup // Track if send operation is running
+	pendingMu sync.Mutex
+	pending   []sender.Result // responses awaiting display
+	started   sync.WaitGroup  // Track if send operation is running
 
 	// Logging flag.
 	logHistory         bool // Flag to enable or disable command history logging.
 	logHistoryCheckbox *widget.Check
+	keepLatencies      *widget.Check // Keep every latency rather than as many as the history.
+
+	// adoptedTimeout is the request timeout last taken from Settings.
+	adoptedTimeout string
 }
 
-// NewHSMCommandSender creates a new HSM Command Sender tab.
-func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSender {
+// NewHSMCommandSender creates a new HSM Command Sender tab. store may be nil,
+// which disables {{key:NAME}} placeholders, and scenarios may be nil, which
+// disables saved scenarios.
+func NewHSMCommandSender(
+	conn *hsm.Connection,
+	store *storage.KeyStore,
+	scenarios *storage.ScenarioStore,
+	logHistory bool,
+) *HSMCommandSender {
 	hs := &HSMCommandSender{
 		connection: conn,
-		responses:  make([]Response, 0),
+		store:      store,
+		scenarios:  scenarios,
+		history:    sender.NewRing[historyEntry](sender.DefaultHistoryLimit),
 		logHistory: logHistory, // Initialize the flag.
 	}
 	hs.ExtendBaseWidget(hs)
 
 	// Initialize input fields.
 	hs.command = widget.NewMultiLineEntry()
-	hs.command.SetPlaceHolder("Enter command...")
+	hs.command.SetPlaceHolder(
+		"Enter command... Placeholders: {{counter}}, {{random:N}}, {{timestamp:LAYOUT}}, {{key:NAME}}",
+	)
+	hs.initializeRecentCommands()
 
 	// Initialize request count spinner with up/down buttons.
 	hs.reqCount = widget.NewEntry()
@@ -103,11 +199,156 @@ func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSende
 		container.NewVBox(spinUp, spinDown),
 		hs.reqCount,
 	)
+	hs.reqCountBox = container.NewPadded(
+		widget.NewLabelWithStyle(
+			"Request Count",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true},
+		),
+		reqCountContainer,
+	)
+
+	hs.inputErr = widget.NewLabel("")
+	hs.inputErr.Importance = widget.DangerImportance
+	hs.inputErr.Wrapping = fyne.TextWrapWord
+	hs.inputErr.Hide()
+	hs.perLine = widget.NewCheck("One command per line", func(bool) { hs.validateInput() })
+	hs.inputMode = widget.NewSelect(sender.InputModes, func(string) { hs.validateInput() })
+	hs.inputMode.SetSelected(string(sender.InputASCII))
+	hs.command.OnChanged = func(string) { hs.validateInput() }
+
+	hs.warmup = widget.NewEntry()
+	hs.warmup.SetText("0")
+	hs.warmup.Validator = func(s string) error {
+		if n, err := strconv.Atoi(s); err != nil || n < 0 {
+			return errors.New("warm-up must be a non-negative number of requests")
+		}
+
+		return nil
+	}
+
+	hs.expected = widget.NewEntry()
+	hs.expected.SetPlaceHolder("e.g. ND00 or A100")
+	hs.stopOnFail = widget.NewCheck("Stop on first failure", nil)
+
+	retryOptions := make([]string, 0, sender.MaxRetries+1)
+	for i := 0; i <= sender.MaxRetries; i++ {
+		retryOptions = append(retryOptions, strconv.Itoa(i))
+	}
+	hs.retryCount = widget.NewSelect(retryOptions, nil)
+	hs.retryCount.SetSelected("2")
+	hs.retryCount.Disable()
+	hs.retry = widget.NewCheck("Retry on timeout", func(checked bool) {
+		if checked {
+			hs.retryCount.Enable()
+		} else {
+			hs.retryCount.Disable()
+		}
+	})
+
+	// Initialize duration entry for time based runs.
+	hs.duration = widget.NewEntry()
+	hs.duration.SetPlaceHolder("Seconds")
+	hs.duration.SetText("60")
+	hs.duration.OnChanged = func(s string) {
+		if s == "" {
+			return
+		}
+		if num, err := strconv.Atoi(s); err != nil || num < 0 {
+			hs.duration.SetText("60")
+		}
+	}
+	hs.durationBox = container.NewVBox(
+		widget.NewLabelWithStyle(
+			"Duration (seconds)",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true},
+		),
+		hs.duration,
+	)
+	hs.durationBox.Hide()
+
+	// Initialize the interval entry for periodic sending.
+	hs.interval = widget.NewEntry()
+	hs.interval.SetPlaceHolder("Seconds")
+	hs.interval.SetText(defaultRepeatSeconds)
+	hs.interval.Validator = func(s string) error {
+		if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+			return errors.New("interval must be a positive number of seconds")
+		}
+
+		return nil
+	}
+	hs.intervalBox = container.NewVBox(
+		widget.NewLabelWithStyle(
+			"Repeat every N seconds until stopped",
+			fyne.TextAlignLeading,
+			fyne.TextStyle{Bold: true},
+		),
+		hs.interval,
+	)
+	hs.intervalBox.Hide()
+
+	// Initialize the per-request timeout, remembered across sessions.
+	prefs := fyne.CurrentApp().Preferences()
+	hs.timeout = widget.NewEntry()
+	hs.timeout.SetPlaceHolder("Milliseconds")
+	hs.timeout.SetText(strconv.Itoa(prefs.IntWithFallback(
+		prefTimeoutMillis, int(sender.DefaultTimeout.Milliseconds()),
+	)))
+	hs.timeout.Validator = func(s string) error {
+		_, err := sender.ParseTimeout(s)
+		return err
+	}
+	hs.timeout.OnChanged = func(s string) {
+		if d, err := sender.ParseTimeout(s); err == nil {
+			prefs.SetInt(prefTimeoutMillis, int(d.Milliseconds()))
+		}
+	}
+
+	// Initialize the latency threshold, off when empty.
+	hs.slowAfter = widget.NewEntry()
+	hs.slowAfter.SetPlaceHolder("Off")
+	hs.slowAfter.Validator = func(s string) error {
+		_, err := sender.ParseThreshold(s)
+		return err
+	}
+
+	hs.initializeProfile()
+
+	hs.loadMode = widget.NewRadioGroup(
+		[]string{loadModeCount, loadModeDuration, loadModeProfile, loadModeRepeat},
+		func(mode string) {
+			hs.reqCountBox.Hide()
+			hs.durationBox.Hide()
+			hs.profileBox.Hide()
+			hs.intervalBox.Hide()
+			switch mode {
+			case loadModeDuration:
+				hs.durationBox.Show()
+			case loadModeProfile:
+				hs.profileBox.Show()
+			case loadModeRepeat:
+				hs.intervalBox.Show()
+			default:
+				hs.reqCountBox.Show()
+			}
+		},
+	)
+	hs.loadMode.Horizontal = true
+	hs.loadMode.Required = true
+	hs.loadMode.SetSelected(loadModeCount)
 
 	// Initialize status indicators.
 	hs.progress = widget.NewProgressBar()
 	hs.counter = widget.NewLabel("Completed: 0")
 	hs.tpsLabel = widget.NewLabel("")
+	hs.tpsChart = newSparkline(sender.SparklineWindow)
+	hs.slowLbl = widget.NewLabel("")
+	hs.slowLbl.Importance = widget.WarningImportance
+	hs.latencyLbl = widget.NewLabel("")
+	hs.assertLbl = widget.NewLabel("")
+	hs.initializeCompare()
 
 	// Initialize response fields.
 	hs.initializeCommandResponseUI()
@@ -115,7 +356,11 @@ func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSende
 	// Create control buttons.
 	hs.sendBtn = widget.NewButton("Send", hs.onSend)
 	hs.stopBtn = widget.NewButton("Stop", hs.onStop)
-	hs.stopBtn.Disable()
+	hs.pauseBtn = widget.NewButtonWithIcon("Pause", theme.MediaPauseIcon(), hs.onPause)
+	hs.exportBtn = widget.NewButtonWithIcon("Export…", theme.DocumentSaveIcon(), hs.onExport)
+	hs.latExpBtn = widget.NewButtonWithIcon("Export latencies…", theme.DocumentSaveIcon(), hs.onExportLatencies)
+	hs.scriptBtn = widget.NewButtonWithIcon("Run script…", theme.MediaPlayIcon(), hs.onRunScript)
+	hs.applyControls()
 
 	// Register for connection state changes
 	if conn != nil {
@@ -123,12 +368,13 @@ func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSende
 			// Update UI based on connection state
 			fyne.Do(func() {
 				if state == hsm.Connected {
-					hs.sendBtn.Enable()
+					hs.adoptCommandTimeout(conn.CommandTimeout())
+					setEnabled(hs.sendBtn, hs.state.Controls().Send)
 					if hs.tpsLabel != nil {
 						hs.tpsLabel.SetText("")
 					}
 				} else {
-					if !hs.isSending {
+					if hs.state == sender.StateIdle {
 						hs.sendBtn.Disable()
 					}
 					if hs.tpsLabel != nil {
@@ -141,20 +387,44 @@ func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSende
 
 	// Create form layout with bold section headers.
 	form := container.NewVBox(
-		widget.NewLabelWithStyle("Host Command", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewHBox(
+			widget.NewLabelWithStyle("Host Command", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			layout.NewSpacer(),
+			hs.recentCmds,
+			hs.rememberCmd,
+			widget.NewButtonWithIcon("Load command from file…", theme.FolderOpenIcon(), hs.onLoadCommandFile),
+		),
 		hs.command,
-		container.NewPadded(
-			widget.NewLabelWithStyle(
-				"Request Count",
-				fyne.TextAlignLeading,
-				fyne.TextStyle{Bold: true},
-			),
-			reqCountContainer,
+		hs.inputErr,
+		container.NewHBox(hs.perLine, layout.NewSpacer(), widget.NewLabel("Input"), hs.inputMode),
+		hs.loadMode,
+		hs.reqCountBox,
+		hs.durationBox,
+		hs.profileBox,
+		hs.intervalBox,
+		container.NewGridWithColumns(3,
+			widget.NewLabelWithStyle("Timeout (ms)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabelWithStyle("Warm-up Requests", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabelWithStyle("Latency threshold (ms)", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			hs.timeout,
+			hs.warmup,
+			hs.slowAfter,
 		),
+		container.NewHBox(hs.retry, hs.retryCount, widget.NewLabel("retries")),
+		hs.initializeWorkers(),
+		container.NewHBox(
+			widget.NewLabelWithStyle("Expected Response Prefix", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			layout.NewSpacer(),
+			hs.stopOnFail,
+		),
+		hs.expected,
+		hs.compare,
+		hs.compareBox,
 	)
 
 	// Create status layout with improved visual hierarchy.
 	status := container.NewVBox(
+		hs.tpsChart,
 		container.NewHBox(
 			widget.NewLabelWithStyle(
 				"Progress:",
@@ -164,86 +434,403 @@ func NewHSMCommandSender(conn *hsm.Connection, logHistory bool) *HSMCommandSende
 			hs.progress,
 		),
 		hs.counter,
-		hs.tpsLabel,
+		container.NewHBox(hs.tpsLabel, hs.slowLbl, hs.latencyLbl, hs.assertLbl, hs.compareLbl),
 	)
 
 	// Create buttons layout with padding.
 	buttons := container.NewPadded(
 		container.NewHBox(
 			hs.sendBtn,
+			hs.pauseBtn,
 			hs.stopBtn,
+			hs.scriptBtn,
+			hs.exportBtn,
+			hs.latExpBtn,
 		),
 	)
 
 	// Add a checkbox to toggle logging of command history.
 	hs.logHistoryCheckbox = widget.NewCheck("Log Command History", func(checked bool) {
 		hs.logHistory = checked
+		setEnabled(hs.workers, !checked) // History logging sends sequentially.
+		hs.showWorkersWarning()
 	})
 	hs.logHistoryCheckbox.SetChecked(
 		hs.logHistory,
 	) // Set initial state based on the logHistory flag.
+	hs.keepLatencies = widget.NewCheck("Keep all latencies", nil)
 
 	// Layout everything in the container
 	topContent := container.NewVBox(
+		hs.initializeScenarios(),
 		form,
 		status,
 		buttons,
-		hs.logHistoryCheckbox, // Add the checkbox here.
+		container.NewHBox(
+			hs.logHistoryCheckbox,
+			widget.NewLabel("History size"),
+			container.NewGridWrap(fyne.NewSize(90, hs.historyLimit.MinSize().Height), hs.historyLimit),
+			hs.keepLatencies,
+		),
 		widget.NewSeparator(),
-		hs.commandResponseField,
+		container.NewGridWithColumns(2,
+			container.NewBorder(
+				nil, nil, nil,
+				newCopyButton(hs.latestResponseText),
+				hs.commandResponseField,
+			),
+			container.NewBorder(
+				nil, nil, nil,
+				newCopyButton(hs.latestResponseHex),
+				hs.responseHex,
+			),
+		),
+		container.NewHBox(hs.responseStatus, layout.NewSpacer(), hs.selectionLbl, hs.lengthLbl, hs.hexView),
+		hs.hexPanes,
 	)
 
 	// Use Border layout to make the history window expand to the bottom.
 	hs.container = container.NewBorder(
-		topContent,             // top
-		nil,                    // bottom
-		nil,                    // left
-		nil,                    // right
-		hs.commandHistoryField, // center expands to fill space
+		topContent,     // top
+		nil,            // bottom
+		nil,            // left
+		nil,            // right
+		hs.historyList, // center expands to fill space
 	)
 
 	return hs
 }
 
-func (hs *HSMCommandSender) initializeCommandResponseUI() {
-	// Create a read-only text area for the latest command response.
-	hs.commandResponseField = widget.NewMultiLineEntry()
-	hs.commandResponseField.Disable() // Set to read-only.
-	hs.commandResponseField.SetPlaceHolder("Latest command response will appear here.")
+// initializeRecentCommands creates the recent command recall. Commands are
+// only persisted in preferences when the user opts in.
+func (hs *HSMCommandSender) initializeRecentCommands() {
+	prefs := fyne.CurrentApp().Preferences()
+
+	hs.recent = sender.NewRecentCommands(sender.DefaultRecentCommands)
+	if prefs.Bool(prefRememberCommands) {
+		hs.recent.Load(prefs.StringList(prefRecentCommands))
+	}
+
+	hs.recentCmds = widget.NewSelect(hs.recent.Items(), nil)
+	hs.recentCmds.PlaceHolder = "Recent commands"
+	hs.recentCmds.OnChanged = func(cmd string) {
+		if cmd == "" {
+			return
+		}
+		hs.command.SetText(cmd)
+		hs.recentCmds.ClearSelected()
+	}
 
-	// Create a read-only text area for the command history.
-	hs.commandHistoryField = widget.NewMultiLineEntry()
-	hs.commandHistoryField.Disable() // Set to read-only.
-	hs.commandHistoryField.SetPlaceHolder("Command history will appear here.")
+	hs.rememberCmd = widget.NewCheck("Remember commands", func(checked bool) {
+		prefs.SetBool(prefRememberCommands, checked)
+		hs.saveRecentCommands()
+	})
+	hs.rememberCmd.SetChecked(prefs.Bool(prefRememberCommands))
 }
 
-func (hs *HSMCommandSender) addResponse(req, resp string, latency time.Duration) {
-	fyne.Do(func() {
-		// Update the latest command response field.
-		hs.commandResponseField.SetText(resp)
-
-		if hs.logHistory {
-			// Format the new history entry.
-			newEntry := fmt.Sprintf(
-				"[%s] Command: %s\n[%s] Response: %s\nLatency: %d ms\n\n",
-				time.Now().Format("2006-01-02 15:04:05"), req,
-				time.Now().Format("2006-01-02 15:04:05"), resp,
-				latency.Milliseconds(),
-			)
+// addRecentCommand records cmd for recall.
+func (hs *HSMCommandSender) addRecentCommand(cmd string) {
+	hs.recent.Add(cmd)
+	hs.recentCmds.SetOptions(hs.recent.Items())
+	hs.saveRecentCommands()
+}
 
-			// Append the new entry to the command history.
-			currentHistory := hs.commandHistoryField.Text
-			hs.commandHistoryField.SetText(currentHistory + newEntry)
+// saveRecentCommands persists the recent commands when remembering them is
+// enabled and removes them from preferences otherwise.
+func (hs *HSMCommandSender) saveRecentCommands() {
+	prefs := fyne.CurrentApp().Preferences()
+	if hs.rememberCmd.Checked {
+		prefs.SetStringList(prefRecentCommands, hs.recent.Items())
+	} else {
+		prefs.RemoveValue(prefRecentCommands)
+	}
+}
 
-			// Scroll to the bottom of the command history field.
-			hs.commandHistoryField.CursorRow = len(hs.commandHistoryField.Text)
+func (hs *HSMCommandSender) initializeCommandResponseUI() {
+	// Create read-only ASCII and hex panes for the latest command response.
+	// Both show 16 bytes per row, so a byte sits on the same row in each.
+	newPane := func(placeHolder string, hexPane bool) *widget.Entry {
+		e := widget.NewMultiLineEntry()
+		e.TextStyle = fyne.TextStyle{Monospace: true}
+		e.SetPlaceHolder(placeHolder)
+		e.Disable() // Set to read-only; text can still be selected.
+		e.OnCursorChanged = func() { hs.showSelection(e, hexPane) }
+
+		return e
+	}
+	hs.commandResponseField = newPane("Latest command response will appear here.", false)
+	hs.responseHex = newPane("Hex of the latest response.", true)
+	hs.selectionLbl = widget.NewLabel("")
+	hs.responseStatus = widget.NewLabel("")
+
+	// Create the hex view of the latest request and response.
+	newDump := func(placeHolder string) *widget.Entry {
+		e := widget.NewMultiLineEntry()
+		e.TextStyle = fyne.TextStyle{Monospace: true}
+		e.SetPlaceHolder(placeHolder)
+		e.SetMinRowsVisible(4)
+		e.Disable()
+
+		return e
+	}
+	hs.requestDump = newDump("Latest request")
+	hs.responseDump = newDump("Latest response")
+	hs.hexPanes = container.NewGridWithColumns(2, hs.requestDump, hs.responseDump)
+	hs.hexPanes.Hide()
+	hs.lengthLbl = widget.NewLabel("")
+	hs.hexView = widget.NewCheck("Hex view", func(checked bool) {
+		if checked {
+			hs.hexPanes.Show()
+		} else {
+			hs.hexPanes.Hide()
 		}
 	})
+
+	// Create a list rendering the command history one row at a time.
+	hs.historyList = widget.NewList(
+		hs.history.Len,
+		func() fyne.CanvasObject {
+			row := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+			row.Truncation = fyne.TextTruncateEllipsis
+
+			return row
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			if id < hs.history.Len() {
+				e, row := hs.history.At(id), obj.(*widget.Label)
+				switch {
+				case e.result != nil && e.result.Cancelled():
+					row.Importance = widget.LowImportance
+				case e.result != nil && (e.result.Failed || e.result.Mismatch()):
+					row.Importance = widget.DangerImportance
+				case e.slow:
+					row.Importance = widget.WarningImportance
+				default:
+					row.Importance = widget.MediumImportance
+				}
+				row.SetText(e.String())
+			}
+		},
+	)
+
+	hs.historyLimit = widget.NewEntry()
+	hs.historyLimit.SetText(strconv.Itoa(sender.DefaultHistoryLimit))
+	hs.historyLimit.OnChanged = func(s string) {
+		if n, err := strconv.Atoi(s); err == nil && n > 0 && n <= maxHistoryLimit {
+			hs.history.Resize(n)
+			hs.refreshHistory()
+		}
+	}
+}
+
+// maxHistoryLimit bounds the configurable history size.
+const maxHistoryLimit = 100_000
+
+// historyEntry is a row of the command history: a response or one line of a
+// note such as a run summary.
+type historyEntry struct {
+	result *sender.Result
+	note   string
+	slow   bool // The response took longer than the latency threshold.
+}
+
+// String renders the entry as a single line.
+func (e historyEntry) String() string {
+	if e.result == nil {
+		return e.note
+	}
+	r := e.result
+
+	line := ""
+	if r.Line > 0 {
+		line = fmt.Sprintf("line %d: ", r.Line)
+	}
+
+	retried := ""
+	if r.Retries > 0 {
+		retried = fmt.Sprintf(" (retried %dx)", r.Retries)
+	}
+
+	if c := r.Compare; c != nil {
+		verdict := "match"
+		if !c.Match {
+			verdict = "MISMATCH"
+		}
+
+		return fmt.Sprintf("[%s] %s%s => A: %s (%d ms) | B: %s (%d ms) %s%s",
+			r.Timestamp.Format("2006-01-02 15:04:05"), line, displayBytes(r.Request),
+			responseText(*r), r.Latency.Milliseconds(),
+			responseText(sender.Result{Response: c.Response, Err: c.Err}), c.Latency.Milliseconds(),
+			verdict, retried)
+	}
+
+	return fmt.Sprintf("[%s] %s%s => %s (%d ms)%s",
+		r.Timestamp.Format("2006-01-02 15:04:05"), line, displayBytes(r.Request), responseText(*r),
+		r.Latency.Milliseconds(), retried)
+}
+
+// refreshHistory redraws the history, keeping the newest entry visible.
+func (hs *HSMCommandSender) refreshHistory() {
+	hs.historyList.Refresh()
+	hs.historyList.ScrollToBottom()
+}
+
+// historyResults returns the responses held in the history, oldest first.
+func (hs *HSMCommandSender) historyResults() []sender.Result {
+	var results []sender.Result
+	for _, e := range hs.history.Items() {
+		if e.result != nil {
+			results = append(results, *e.result)
+		}
+	}
+
+	return results
+}
+
+// addResponse queues a result for display by flushResponses. It is safe to
+// call from the sending goroutines.
+func (hs *HSMCommandSender) addResponse(r sender.Result) {
+	hs.pendingMu.Lock()
+	hs.pending = append(hs.pending, r)
+	hs.pendingMu.Unlock()
+}
+
+// flushResponses displays the latest queued result and records the queued
+// results in the history when history logging is enabled. It must run on the
+// UI thread.
+func (hs *HSMCommandSender) flushResponses() {
+	hs.pendingMu.Lock()
+	batch := hs.pending
+	hs.pending = nil
+	hs.pendingMu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	r := batch[len(batch)-1]
+	hs.lastResult = &r
+	hs.showLatest()
+	if r.Err != nil {
+		hs.responseStatus.SetText("")
+	} else {
+		text, importance := responseAnnotation(r.Response)
+		hs.responseStatus.Importance = importance
+		hs.responseStatus.SetText(text)
+	}
+
+	if hs.logHistory {
+		for i := range batch {
+			hs.history.Push(historyEntry{
+				result: &batch[i],
+				slow:   !batch[i].Cancelled() && sender.IsSlow(batch[i].Latency, hs.threshold),
+			})
+		}
+		hs.refreshHistory()
+	}
+}
+
+// showLatest renders the latest result in the text field and the hex panes.
+func (hs *HSMCommandSender) showLatest() {
+	r := hs.lastResult
+	if r == nil {
+		return
+	}
+
+	// Render the panes from the raw response bytes.
+	if r.Err != nil || r.Response == nil {
+		hs.commandResponseField.SetText(responseText(*r))
+		hs.responseHex.SetText("")
+	} else {
+		hs.commandResponseField.SetText(utils.ASCIIPane(r.Response))
+		hs.responseHex.SetText(utils.HexPane(r.Response))
+	}
+	hs.selectionLbl.SetText("")
+	hs.requestDump.SetText(payloadDump(r.Request))
+	if r.Err != nil {
+		hs.responseDump.SetText(responseText(*r))
+	} else {
+		hs.responseDump.SetText(payloadDump(r.Response))
+	}
+	hs.lengthLbl.SetText(lengthDetails(*r))
+}
+
+// displayBytes renders a command as text, escaping it when it holds bytes
+// outside the printable ASCII range.
+func displayBytes(b []byte) string {
+	if utils.PrintableASCII(b) == string(b) {
+		return string(b)
+	}
+
+	return utils.EscapeBytes(b)
+}
+
+// payloadDump renders a request or response for the hex view.
+func payloadDump(b []byte) string {
+	if len(b) == 0 {
+		return "(empty)"
+	}
+
+	return utils.HexDump(b)
+}
+
+// lengthDetails describes the sizes of a request and its response.
+func lengthDetails(r sender.Result) string {
+	return fmt.Sprintf("Request: %d bytes  Response: %d bytes", len(r.Request), len(r.Response))
+}
+
+// responseAnnotation decodes the response and error code of an HSM response,
+// e.g. "A1 68 — command not enabled in security settings". Errors are
+// highlighted and responses without a valid status are marked unparseable.
+func responseAnnotation(resp []byte) (string, widget.Importance) {
+	status, err := utils.ParseHSMResponse(resp)
+	if err != nil {
+		return "unparseable response", widget.WarningImportance
+	}
+	if !status.OK() {
+		return status.String(), widget.DangerImportance
+	}
+
+	return status.String(), widget.SuccessImportance
+}
+
+// showSelection displays the byte offset and length of the selection in a
+// response pane.
+func (hs *HSMCommandSender) showSelection(pane *widget.Entry, hexPane bool) {
+	r := hs.lastResult
+	if r == nil || r.Err != nil || len(r.Response) == 0 {
+		hs.selectionLbl.SetText("")
+		return
+	}
+	offset, n := utils.PaneSelection(r.Response, hexPane, pane.CursorRow, pane.CursorColumn, pane.SelectedText())
+	hs.selectionLbl.SetText(fmt.Sprintf("offset 0x%02X, %d bytes selected", offset, n))
+}
+
+// latestResponseText returns the latest response for copying, escaping
+// non-printable bytes.
+func (hs *HSMCommandSender) latestResponseText() string {
+	r := hs.lastResult
+	if r == nil {
+		return ""
+	}
+	if r.Err != nil || r.Response == nil {
+		return responseText(*r)
+	}
+
+	return displayBytes(r.Response)
+}
+
+// latestResponseHex returns the latest response as hex for copying.
+func (hs *HSMCommandSender) latestResponseHex() string {
+	if r := hs.lastResult; r != nil && r.Err == nil {
+		return utils.ASCIIToHex(string(r.Response))
+	}
+
+	return ""
 }
 
 func (hs *HSMCommandSender) onSend() {
 	hs.sendMutex.Lock()
-	if hs.isSending {
+	if hs.state != sender.StateIdle {
 		hs.sendMutex.Unlock()
 		return
 	}
@@ -273,7 +860,7 @@ func (hs *HSMCommandSender) onSend() {
 		)
 	}
 
-	if hs.command.Text == "" {
+	if strings.TrimSpace(hs.command.Text) == "" {
 		hs.sendMutex.Unlock()
 		dialog.ShowError(
 			errors.New("command cannot be empty"),
@@ -283,258 +870,732 @@ func (hs *HSMCommandSender) onSend() {
 		return
 	}
 
-	// Parse request count
-	reqCount, err := strconv.Atoi(hs.reqCount.Text)
-	if err != nil || reqCount < 0 {
-		reqCount = 0
+	timeout, err := sender.ParseTimeout(hs.timeout.Text)
+	if err != nil {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
+	}
+	threshold, err := sender.ParseThreshold(hs.slowAfter.Text)
+	if err != nil {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
+	}
+
+	workers, err := sender.ParseWorkers(hs.workers.Text, hs.poolCapacity())
+	if err != nil && !hs.logHistory {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
+	}
+
+	if hs.perLine.Checked && !hs.logHistory {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(
+			errors.New("one command per line is sent sequentially; "+
+				"enable \"Log Command History\" to switch off concurrent mode"),
+			fyne.CurrentApp().Driver().AllWindows()[0],
+		)
+
+		return
+	}
+
+	if err := hs.warmup.Validate(); err != nil {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
 	}
-	if reqCount == 0 {
-		reqCount = 1
-		hs.reqCount.SetText("1")
+	warmup, _ := strconv.Atoi(hs.warmup.Text)
+
+	cfg := sender.Config{
+		Warmup:        warmup,
+		Timeout:       timeout,
+		Expected:      strings.TrimSpace(hs.expected.Text),
+		StopOnFailure: hs.stopOnFail.Checked,
+	}
+	if hs.retry.Checked {
+		cfg.Retries, _ = strconv.Atoi(hs.retryCount.Selected)
+	}
+	if err := hs.buildCommands(&cfg); err != nil {
+		hs.sendMutex.Unlock()
+		hs.showInputError(err)
+
+		return
+	}
+	if err := hs.configureCompare(&cfg); err != nil {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
+	}
+	var profile []sender.ProfileStep
+	var interval time.Duration
+	switch hs.loadMode.Selected {
+	case loadModeProfile:
+		if profile, err = hs.profileSteps(); err != nil {
+			hs.sendMutex.Unlock()
+			dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+			return
+		}
+		for _, step := range profile {
+			cfg.Duration += step.Duration
+		}
+	case loadModeDuration:
+		seconds, err := strconv.Atoi(hs.duration.Text)
+		if err != nil || seconds <= 0 {
+			hs.sendMutex.Unlock()
+			dialog.ShowError(
+				errors.New("duration must be a positive number of seconds"),
+				fyne.CurrentApp().Driver().AllWindows()[0],
+			)
+
+			return
+		}
+		cfg.Duration = time.Duration(seconds) * time.Second
+	case loadModeRepeat:
+		err := hs.interval.Validate()
+		if err == nil && cfg.Secondary != nil {
+			err = errors.New("comparison with a second HSM is not available when repeating")
+		}
+		if err != nil {
+			hs.sendMutex.Unlock()
+			dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+			return
+		}
+		seconds, _ := strconv.Atoi(hs.interval.Text)
+		interval = time.Duration(seconds) * time.Second
+		cfg.Warmup = 0
+	default:
+		// Parse request count
+		reqCount, err := strconv.Atoi(hs.reqCount.Text)
+		if err != nil || reqCount < 0 {
+			reqCount = 0
+		}
+		if reqCount == 0 {
+			reqCount = 1
+			hs.reqCount.SetText("1")
+		}
+		cfg.Count = reqCount
 	}
 
 	// Reset state for new command
 	hs.stopChan = make(chan struct{}) // Create new channel for this send operation
 	hs.progress.SetValue(0)
-	hs.progress.Max = float64(reqCount)
-	hs.isSending = true
-	hs.sendBtn.Disable()
-	hs.stopBtn.Enable()
-
+	switch {
+	case interval > 0:
+		hs.progress.Max = 1 // Periodic sending has no end.
+	case cfg.Duration > 0:
+		hs.progress.Max = cfg.Duration.Seconds()
+	default:
+		hs.progress.Max = float64(cfg.Warmup + cfg.Count*max(len(cfg.Lines), 1))
+	}
+	hs.lastReport = nil
+	hs.runTPS = nil
+	hs.runCodes = nil
+	hs.runSlow = nil
+	hs.runSamples = nil
+	hs.latencyLog = hs.newLatencyLog()
+	hs.threshold = threshold
+	hs.tpsChart.SetValues(nil)
+	hs.pause = sender.NewPause()
+	hs.transition(sender.EventStart)
+	hs.latencyLbl.SetText("")
+	hs.assertLbl.SetText("")
+	hs.compareLbl.SetText("")
+	hs.slowLbl.SetText("")
+
+	showTPS := cfg.Duration > 0 || cfg.Count > 10
 	if hs.tpsLabel != nil {
-		if reqCount <= 10 {
-			hs.tpsLabel.SetText("")
-		} else {
+		if showTPS {
 			hs.tpsLabel.SetText("TPS: calculating...")
+		} else {
+			hs.tpsLabel.SetText("")
 		}
 	}
 
-	poolCapacity := hs.connection.GetPoolCapacity()
+	if hs.logHistory {
+		// Default mode: send commands sequentially.
+		cfg.Workers = 1
+		// Add a small delay between commands to prevent overwhelming the connection
+		cfg.Delay = 10 * time.Millisecond
+	} else {
+		// Performance mode: send commands concurrently.
+		cfg.Workers = workers
+	}
+	cfg.Pause = hs.pause
+	hs.addRecentCommand(hs.command.Text)
+	stop := hs.stopChan
 	hs.sendMutex.Unlock() // Unlock before starting goroutine
 
-	if !hs.logHistory {
-		// Performance mode: send commands concurrently.
-		go hs.sendConcurrent(reqCount, int(poolCapacity))
+	if interval > 0 {
+		go hs.runPeriodic(stop, cfg, interval)
+
+		return
+	}
+	if profile != nil {
+		// The profile steps set their own rate and duration.
+		cfg.Delay, cfg.Duration = 0, 0
+		go hs.runProfile(stop, cfg, profile)
+
+		return
+	}
+	go hs.run(stop, cfg, showTPS)
+}
+
+// buildCommands encodes the command text in the selected input mode into cfg,
+// as a single command or one command per line.
+func (hs *HSMCommandSender) buildCommands(cfg *sender.Config) error {
+	mode := sender.InputMode(hs.inputMode.Selected)
+	lines := []sender.Line{{Command: []byte(hs.command.Text)}}
+	if hs.perLine.Checked {
+		lines = sender.SplitLines(hs.command.Text)
+	}
+
+	for i := range lines {
+		l := &lines[i]
+		text := string(l.Command)
+		where := ""
+		if l.Number > 0 {
+			where = fmt.Sprintf("line %d: ", l.Number)
+		}
+		if strings.Contains(text, "{{") && mode != sender.InputASCII {
+			return fmt.Errorf("%splaceholders are only supported in ASCII input mode", where)
+		}
+
+		var err error
+		if l.Template, err = hs.parseTemplate(text); err != nil {
+			return fmt.Errorf("%sinvalid command template: %v", where, err)
+		}
+		if l.Command, err = sender.EncodeCommand(text, mode); err != nil {
+			return fmt.Errorf("%s%v", where, err)
+		}
+	}
+
+	if hs.perLine.Checked {
+		cfg.Lines = lines
 	} else {
-		// Default mode: send commands sequentially.
-		go hs.sendSequential(reqCount)
+		cfg.Command, cfg.Template = lines[0].Command, lines[0].Template
 	}
+
+	return nil
 }
 
-func (hs *HSMCommandSender) sendSequential(reqCount int) {
-	var batchStartTime time.Time
-	if reqCount > 10 {
-		batchStartTime = time.Now()
+// validateInput shows why the command text cannot be sent, if it cannot.
+func (hs *HSMCommandSender) validateInput() {
+	if hs.inputErr == nil || hs.perLine == nil || hs.inputMode == nil {
+		return // Still initializing.
+	}
+	if strings.TrimSpace(hs.command.Text) == "" {
+		hs.showInputError(nil)
+		return
 	}
-	var completed int32
+	var cfg sender.Config
+	hs.showInputError(hs.buildCommands(&cfg))
+}
 
-	defer func() {
-		fyne.Do(func() {
-			hs.sendMutex.Lock()
-			hs.isSending = false
-			hs.sendBtn.Enable()
-			hs.stopBtn.Disable()
-			hs.progress.SetValue(float64(completed))
-			if hs.tpsLabel != nil {
-				if reqCount <= 10 || int(completed) != reqCount {
-					hs.tpsLabel.SetText("")
-				}
-			}
-			hs.sendMutex.Unlock()
-		})
-	}()
-
-	for i := 0; i < reqCount; i++ {
-		select {
-		case <-hs.stopChan:
-			return // Exit the loop immediately if stop is signaled.
-		default:
-			// Check connection state before each send
-			if hs.connection.GetState() != hsm.Connected {
-				fyne.Do(func() {
-					if hs.tpsLabel != nil {
-						hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
-					}
-					dialog.ShowError(
-						errors.New("hsm connection lost during command sequence"),
-						fyne.CurrentApp().Driver().AllWindows()[0],
-					)
-				})
+// showInputError displays err below the command, or hides the message when
+// err is nil.
+func (hs *HSMCommandSender) showInputError(err error) {
+	if err == nil {
+		hs.inputErr.Hide()
+		return
+	}
+	hs.inputErr.SetText(err.Error())
+	hs.inputErr.Show()
+}
 
+// parseTemplate parses command as a template when it contains placeholders
+// and returns nil otherwise.
+func (hs *HSMCommandSender) parseTemplate(command string) (*sender.Template, error) {
+	if !strings.Contains(command, "{{") {
+		return nil, nil
+	}
+
+	return sender.ParseTemplate(command, hs.lookupKey)
+}
+
+// onExport writes the results of the last run to a file chosen by the user.
+// Key-like values are masked unless sensitive data is explicitly included.
+func (hs *HSMCommandSender) onExport() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	results := hs.historyResults()
+	report := hs.lastReport
+	tps, codes, slow := hs.runTPS, hs.runCodes, hs.runSlow
+	if len(results) == 0 && len(codes) == 0 && report == nil {
+		dialog.ShowError(errors.New("no results to export"), w)
+		return
+	}
+
+	format := widget.NewSelect(sender.ExportFormats, nil)
+	format.SetSelected(sender.ExportFormats[0])
+	sensitive := widget.NewCheck("Include sensitive data", nil)
+
+	dialog.ShowForm("Export results", "Export", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Format", format),
+		widget.NewFormItem("", sensitive),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		exportFormat := sender.ExportFormat(format.Selected)
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
 				return
 			}
-
-			startTime := time.Now()
-			respText, err := hs.connection.ExecuteCommand([]byte(hs.command.Text), 5*time.Second)
-			latency := time.Since(startTime)
-
-			var response string
-			switch {
-			case err != nil:
-				response = "Error: " + err.Error()
-				// If this is a connection/broker error, stop the sequence
-				if err.Error() == "hsm client not connected" ||
-					err.Error() == "broker is closed" ||
-					err.Error() == "command timed out" {
-					fyne.Do(func() {
-						if hs.tpsLabel != nil {
-							hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
-						}
-					})
-					hs.addResponse(hs.command.Text, response, latency)
-
-					return
-				}
-			case respText != nil:
-				response = string(respText)
-			default:
-				response = "No response"
+			if wc == nil {
+				return // Cancelled.
+			}
+			defer wc.Close()
+
+			if report != nil {
+				err = report.Export(wc, exportFormat, sensitive.Checked)
+			} else {
+				err = sender.RunReport{Results: results, Codes: codes, Slow: slow, TPS: tps}.Export(
+					wc, exportFormat, sensitive.Checked,
+				)
+			}
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
 			}
+		}, w)
+		save.SetFileName("hsm_results" + exportFormat.Extension())
+		save.Show()
+	}, w)
+}
 
-			hs.addResponse(hs.command.Text, response, latency)
-			completed++
+// onLoadCommandFile loads a prebuilt command from a hex or binary file. The
+// command is shown as hex, since it may not be printable, and sent as is.
+func (hs *HSMCommandSender) onLoadCommandFile() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
 
-			fyne.Do(func() {
-				hs.progress.SetValue(float64(completed))
-				hs.counter.SetText(fmt.Sprintf("Completed: %d", completed))
-				if hs.tpsLabel != nil && reqCount > 10 {
-					elapsedTime := time.Since(batchStartTime)
-					if elapsedTime.Seconds() > 0 {
-						tps := float64(completed) / elapsedTime.Seconds()
-						hs.tpsLabel.SetText(fmt.Sprintf("TPS: %.2f", tps))
-					}
-				}
-			})
+	dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return // Cancelled.
+		}
+		defer rc.Close()
 
-			// Add a small delay between commands to prevent overwhelming the connection
-			time.Sleep(10 * time.Millisecond)
+		command, _, err := sender.ReadCommandFile(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load %s: %v", rc.URI().Name(), err), w)
+			return
 		}
-	}
+		text, err := utils.FormatHex(utils.ASCIIToHex(string(command)), 8, " ")
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		hs.perLine.SetChecked(false)
+		hs.inputMode.SetSelected(string(sender.InputHex))
+		hs.command.SetText(text)
+		hs.hexView.SetChecked(true)
+	}, w)
 }
 
-// sendConcurrent sends commands using multiple goroutines.
-func (hs *HSMCommandSender) sendConcurrent(reqCount, numWorkers int) {
-	var batchStartTime time.Time
-	if reqCount > 10 {
-		batchStartTime = time.Now()
+// onRunScript loads a script of commands and runs it.
+func (hs *HSMCommandSender) onRunScript() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return // Cancelled.
+		}
+		defer rc.Close()
+
+		steps, err := sender.ParseScript(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load %s: %v", rc.URI().Name(), err), w)
+			return
+		}
+		hs.startScript(steps)
+	}, w)
+}
+
+// startScript starts sending the script steps unless a run is in progress.
+func (hs *HSMCommandSender) startScript(steps []sender.ScriptStep) {
+	hs.sendMutex.Lock()
+	if hs.state != sender.StateIdle {
+		hs.sendMutex.Unlock()
+		return
 	}
-	var completedCount atomic.Int32
-	var wg sync.WaitGroup
-	var stopSending atomic.Bool
+	if hs.connection.GetState() != hsm.Connected {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(
+			errors.New("hsm not connected - please wait for reconnection to complete"),
+			fyne.CurrentApp().Driver().AllWindows()[0],
+		)
 
-	jobs := make(chan int, reqCount)
-	for i := 0; i < reqCount; i++ {
-		jobs <- i
+		return
 	}
-	close(jobs)
+	timeout, err := sender.ParseTimeout(hs.timeout.Text)
+	if err != nil {
+		hs.sendMutex.Unlock()
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 
-	defer func() {
-		wg.Wait() // Wait for all workers to finish
-		finalCompleted := completedCount.Load()
+		return
+	}
 
+	hs.stopChan = make(chan struct{})
+	hs.progress.SetValue(0)
+	hs.progress.Max = float64(len(steps))
+	hs.lastReport = nil
+	hs.runTPS = nil
+	hs.runCodes = nil
+	hs.runSlow = nil
+	hs.runSamples = nil
+	hs.threshold = 0
+	hs.tpsChart.SetValues(nil)
+	hs.pause = sender.NewPause()
+	hs.transition(sender.EventStart)
+	hs.tpsLabel.SetText("")
+	hs.slowLbl.SetText("")
+	hs.latencyLbl.SetText("")
+	hs.assertLbl.SetText("")
+	stop, pause := hs.stopChan, hs.pause
+	hs.sendMutex.Unlock()
+
+	go hs.runScript(stop, pause, steps, timeout)
+}
+
+// runScript sends the script steps and shows the pass/fail report.
+func (hs *HSMCommandSender) runScript(
+	stop <-chan struct{},
+	pause *sender.Pause,
+	steps []sender.ScriptStep,
+	timeout time.Duration,
+) {
+	var completed atomic.Int32
+	stopUpdates := sender.Throttle(sender.DefaultRefreshInterval, func() {
+		n := completed.Load()
 		fyne.Do(func() {
-			hs.sendMutex.Lock()
-			hs.isSending = false
-			hs.sendBtn.Enable()
-			hs.stopBtn.Disable()
-			hs.progress.SetValue(float64(finalCompleted))
+			hs.flushResponses()
+			hs.progress.SetValue(float64(n))
+			hs.counter.SetText(fmt.Sprintf("Completed: %d", n))
+		})
+	})
+	report := sender.RunScript(stop, connExecutor{hs.connection}, steps, timeout, pause,
+		func(o sender.ScriptOutcome) {
+			hs.addResponse(o.Result)
+			completed.Store(int32(o.Result.Seq))
+		},
+	)
+	stopUpdates()
 
-			if hs.tpsLabel != nil {
-				if reqCount <= 10 || int(finalCompleted) != reqCount {
-					hs.tpsLabel.SetText("")
-				}
+	fyne.Do(func() {
+		hs.sendMutex.Lock()
+		defer hs.sendMutex.Unlock()
+
+		hs.transition(sender.EventFinish)
+		hs.lastReport = &report
+		hs.tpsLabel.SetText(report.Summary())
+		hs.appendHistory(report.String() + "\n")
+
+		if report.ConnectionLost {
+			dialog.ShowError(
+				errors.New("hsm connection lost during script"),
+				fyne.CurrentApp().Driver().AllWindows()[0],
+			)
+		}
+	})
+}
+
+// showAssertions displays the pass/fail counters of response checks.
+func (hs *HSMCommandSender) showAssertions(passed, failed int, stopped bool) {
+	text := fmt.Sprintf("Pass: %d  Fail: %d", passed, failed)
+	if stopped {
+		text += " (stopped on first failure)"
+	}
+	hs.assertLbl.Importance = widget.SuccessImportance
+	if failed > 0 {
+		hs.assertLbl.Importance = widget.DangerImportance
+	}
+	hs.assertLbl.SetText(text)
+}
+
+// latencyHistogramBuckets is the number of rows of the latency histogram.
+const latencyHistogramBuckets = 10
+
+// showLatencies displays the latency percentiles of a run next to the TPS and
+// appends a latency histogram to the command history.
+func (hs *HSMCommandSender) showLatencies(latencies *sender.LatencyRecorder) {
+	stats := latencies.Stats()
+	if stats.Count == 0 {
+		hs.latencyLbl.SetText("")
+		return
+	}
+	hs.latencyLbl.SetText("Latency: " + stats.String())
+	hs.appendHistory(fmt.Sprintf(
+		"Latency distribution (%d requests):\n%s\n",
+		stats.Count,
+		sender.FormatHistogram(latencies.Histogram(latencyHistogramBuckets), 40),
+	))
+}
+
+// showSlow displays the slow request counter when a latency threshold is set.
+func (hs *HSMCommandSender) showSlow(slow *sender.SlowCounter) {
+	if hs.threshold > 0 {
+		hs.slowLbl.SetText(slow.Stats().Label())
+	}
+}
+
+// finishSlow keeps the threshold breaches of a finished run for export and
+// appends them to the history. The caller must hold sendMutex.
+func (hs *HSMCommandSender) finishSlow(slow *sender.SlowCounter) {
+	if hs.threshold == 0 {
+		return
+	}
+	stats := slow.Stats()
+	hs.runSlow = &stats
+	hs.slowLbl.SetText(stats.Label())
+	hs.appendHistory(stats.String())
+}
+
+// showCodes appends the response code distribution of a finished run to the
+// history.
+func (hs *HSMCommandSender) showCodes(codes sender.CodeCounts) {
+	if len(codes) > 0 {
+		hs.appendHistory(codes.String())
+	}
+}
+
+// appendHistory adds the lines of a note to the command history.
+func (hs *HSMCommandSender) appendHistory(text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		hs.history.Push(historyEntry{note: line})
+	}
+	hs.refreshHistory()
+}
+
+// lookupKey returns the value of a stored key for command templates.
+func (hs *HSMCommandSender) lookupKey(name string) (string, error) {
+	if hs.store == nil {
+		return "", errors.New("key store unavailable")
+	}
+	entry, ok := hs.store.Get(name)
+	if !ok {
+		return "", errors.New("key not found")
+	}
+	if entry.Value == "" {
+		return "", errors.New("key has no stored value")
+	}
+
+	return entry.Value, nil
+}
+
+// adoptCommandTimeout defaults the request timeout to the command timeout
+// configured in Settings, unless the user changed it since it was last
+// adopted.
+func (hs *HSMCommandSender) adoptCommandTimeout(d time.Duration) {
+	if hs.adoptedTimeout != "" && hs.timeout.Text != hs.adoptedTimeout {
+		return
+	}
+	hs.adoptedTimeout = strconv.Itoa(int(d.Milliseconds()))
+	hs.timeout.SetText(hs.adoptedTimeout)
+}
+
+// connExecutor adapts an HSM connection to the sender.Executor interface.
+type connExecutor struct {
+	*hsm.Connection
+}
+
+// Connected reports whether the HSM connection is up.
+func (c connExecutor) Connected() bool {
+	return c.GetState() == hsm.Connected
+}
+
+// responseText renders the outcome of a request for display.
+func responseText(r sender.Result) string {
+	switch {
+	case r.Cancelled():
+		return "Cancelled by Stop"
+	case r.Err != nil:
+		return "Error: " + r.Err.Error()
+	case r.Response != nil:
+		return string(r.Response)
+	default:
+		return "No response"
+	}
+}
+
+// run sends the configured requests and updates progress until the run ends.
+// In duration mode the progress bar tracks elapsed time and the final summary
+// reports the total sent and the average TPS.
+func (hs *HSMCommandSender) run(stop <-chan struct{}, cfg sender.Config, showTPS bool) {
+	var completed, warmed, passed, failed, mismatched atomic.Int32
+	var transportErr atomic.Bool
+	latencies := sender.NewLatencyRecorder(sender.DefaultReservoirSize)
+	secondaryLatencies := sender.NewLatencyRecorder(sender.DefaultReservoirSize)
+	series := sender.NewTPSSeries(time.Now())
+	tally := sender.NewCodeTally()
+	slow := sender.NewSlowCounter(hs.threshold)
+	samples := hs.latencyLog
+	// batchStart holds the Unix time in nanoseconds when the warm-up
+	// completed, or zero while it is in progress.
+	var batchStart atomic.Int64
+	if cfg.Warmup == 0 {
+		batchStart.Store(time.Now().UnixNano())
+	}
+
+	// Publish the counters at a fixed rate rather than once per response.
+	stopUpdates := sender.Throttle(sender.DefaultRefreshInterval, func() {
+		n, w := completed.Load(), warmed.Load()
+		p, f, m := int(passed.Load()), int(failed.Load()), int(mismatched.Load())
+		var elapsedTime time.Duration
+		if ns := batchStart.Load(); ns != 0 {
+			elapsedTime = cfg.Pause.Active(time.Unix(0, ns), time.Now())
+		}
+		paused := cfg.Pause.Paused()
+		recent := series.Last(time.Now(), sender.SparklineWindow)
+		fyne.Do(func() {
+			hs.flushResponses()
+			hs.tpsChart.SetValues(recent)
+			if cfg.Duration > 0 {
+				hs.progress.SetValue(min(elapsedTime.Seconds(), hs.progress.Max))
+			} else {
+				hs.progress.SetValue(float64(n + w))
 			}
-			hs.sendMutex.Unlock()
+			if n == 0 && cfg.Warmup > 0 {
+				hs.counter.SetText(fmt.Sprintf("Warm-up: %d/%d", w, cfg.Warmup))
+			} else {
+				hs.counter.SetText(fmt.Sprintf("Completed: %d", n))
+			}
+			if transportErr.Load() {
+				hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
+			} else if paused {
+				hs.tpsLabel.SetText("Paused")
+			} else if showTPS && n > 0 && elapsedTime > 0 {
+				hs.tpsLabel.SetText(fmt.Sprintf("TPS: %.2f", float64(n)/elapsedTime.Seconds()))
+			}
+			if cfg.Expected != "" {
+				hs.showAssertions(p, f, false)
+			}
+			if cfg.Secondary != nil {
+				hs.showComparison(m, int(n))
+			}
+			hs.showSlow(slow)
 		})
-	}()
-
-	for w := 0; w < numWorkers; w++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for range jobs {
-				// Stop processing if signaled by another worker
-				if stopSending.Load() {
-					return
-				}
+	})
 
-				// Check for external stop signal
-				select {
-				case <-hs.stopChan:
-					stopSending.Store(true)
-					return // Exit the worker loop immediately if stop is signaled.
-				default:
-					// Check connection state before each send
-					if hs.connection.GetState() != hsm.Connected {
-						stopSending.Store(true)
-						fyne.Do(func() {
-							if hs.tpsLabel != nil {
-								hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
-							}
-							dialog.ShowError(
-								errors.New("hsm connection lost during command sequence"),
-								fyne.CurrentApp().Driver().AllWindows()[0],
-							)
-						})
-
-						return
-					}
+	summary := sender.Run(stop, connExecutor{hs.connection}, cfg, func(r sender.Result) {
+		hs.addResponse(r)
+		tally.Record(r)
+		samples.Record(r)
+		if r.Cancelled() {
+			return
+		}
+		if sender.IsConnectionError(r.Err) {
+			// A connection/broker error stops the sequence.
+			transportErr.Store(true)
+			return
+		}
+		if r.Warmup {
+			if int(warmed.Add(1)) == cfg.Warmup {
+				batchStart.Store(time.Now().UnixNano())
+			}
 
-					startTime := time.Now()
-					cmdText := hs.command.Text
-					respText, err := hs.connection.ExecuteCommand([]byte(cmdText), 5*time.Second)
-					latency := time.Since(startTime)
-					response := ""
-					switch {
-					case err != nil:
-						response = "Error: " + err.Error()
-						// If this is a connection/broker error, stop the sequence
-						if err.Error() == "hsm client not connected" ||
-							err.Error() == "broker is closed" ||
-							err.Error() == "command timed out" {
-							stopSending.Store(true)
-							fyne.Do(func() {
-								if hs.tpsLabel != nil {
-									hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
-								}
-							})
-							hs.addResponse(cmdText, response, latency)
-
-							return
-						}
-					case respText != nil:
-						response = string(respText)
-					default:
-						response = "No response"
-					}
+			return
+		}
+		if cfg.Expected != "" {
+			if r.Failed {
+				failed.Add(1)
+			} else {
+				passed.Add(1)
+			}
+		}
+		latencies.Record(r.Latency)
+		slow.Record(r.Latency)
+		if r.Compare != nil && r.Compare.Err == nil {
+			secondaryLatencies.Record(r.Compare.Latency)
+		}
+		if r.Mismatch() {
+			mismatched.Add(1)
+		}
+		series.Record(time.Now())
+		completed.Add(1)
+	})
+	stopUpdates()
+	tps, codes := series.Counts(time.Now()), tally.Counts()
 
-					// Record response and update UI
-					hs.addResponse(cmdText, response, latency)
-					newCount := completedCount.Add(1)
-
-					// Update progress and TPS if needed
-					fyne.Do(func() {
-						hs.progress.SetValue(float64(newCount))
-						hs.counter.SetText(fmt.Sprintf("Completed: %d", newCount))
-						if hs.tpsLabel != nil && reqCount > 10 {
-							elapsedTime := time.Since(batchStartTime)
-							if elapsedTime.Seconds() > 0 {
-								tps := float64(newCount) / elapsedTime.Seconds()
-								hs.tpsLabel.SetText(fmt.Sprintf("TPS: %.2f", tps))
-							}
-						}
-					})
-				}
+	fyne.Do(func() {
+		hs.sendMutex.Lock()
+		defer hs.sendMutex.Unlock()
+
+		hs.runTPS, hs.runCodes, hs.runSamples = tps, codes, samples.Samples()
+		hs.showCodes(codes)
+		hs.finishSlow(slow)
+		if cfg.Workers > 1 {
+			hs.appendHistory(summary.Workers.String())
+		}
+		if cfg.Secondary != nil {
+			hs.showComparison(summary.Mismatches, summary.Sent)
+			hs.appendHistory(fmt.Sprintf(
+				"Comparison: %d of %d responses differ\nLatency A: %s\nLatency B: %s",
+				summary.Mismatches, summary.Sent, latencies.Stats(), secondaryLatencies.Stats(),
+			))
+		}
+		hs.transition(sender.EventFinish)
+		hs.showLatencies(latencies)
+		if cfg.Expected != "" {
+			hs.showAssertions(summary.Passed, summary.Failed, summary.FailureStop)
+		}
+
+		if summary.ConnectionLost {
+			hs.progress.SetValue(float64(completed.Load() + warmed.Load()))
+			if hs.tpsLabel != nil {
+				hs.tpsLabel.SetText("HSM disconnected - reconnecting...")
 			}
-		}()
-	}
+			if !transportErr.Load() {
+				dialog.ShowError(
+					errors.New("hsm connection lost during command sequence"),
+					fyne.CurrentApp().Driver().AllWindows()[0],
+				)
+			}
+
+			return
+		}
+
+		if cfg.Duration > 0 {
+			hs.progress.SetValue(min(summary.Elapsed.Seconds(), hs.progress.Max))
+			if hs.tpsLabel != nil {
+				hs.tpsLabel.SetText(fmt.Sprintf(
+					"Sent: %d in %.1fs, average TPS: %.2f",
+					summary.Sent, summary.Elapsed.Seconds(), summary.TPS(),
+				))
+			}
+
+			return
+		}
+
+		hs.progress.SetValue(float64(summary.Sent + summary.Warmup))
+		if hs.tpsLabel != nil && (!showTPS || summary.Sent+summary.Warmup != int(hs.progress.Max)) {
+			hs.tpsLabel.SetText("")
+		}
+	})
 }
 
 func (hs *HSMCommandSender) onStop() {
 	hs.sendMutex.Lock()
 	defer hs.sendMutex.Unlock()
 
-	if !hs.isSending {
+	// Stopping aborts the requests in flight, so the run finishes promptly
+	// and moves the controls back to idle.
+	if !hs.transition(sender.EventStop) {
 		return
 	}
 
@@ -543,15 +1604,69 @@ func (hs *HSMCommandSender) onStop() {
 		// do not nil the channel so sequential send can detect closure
 	}
 
-	// Reset the state immediately for UI responsiveness.
-	hs.isSending = false
-	hs.sendBtn.Enable()
-	hs.stopBtn.Disable()
 	if hs.tpsLabel != nil {
 		hs.tpsLabel.SetText("")
 	}
 }
 
+// onPause pauses the current run, or resumes it when paused. Requests in
+// flight complete while paused.
+func (hs *HSMCommandSender) onPause() {
+	hs.sendMutex.Lock()
+	defer hs.sendMutex.Unlock()
+
+	switch hs.state {
+	case sender.StateSending:
+		if hs.transition(sender.EventPause) {
+			hs.pause.Pause()
+			hs.tpsLabel.SetText("Paused")
+		}
+	case sender.StatePaused:
+		if hs.transition(sender.EventResume) {
+			hs.pause.Resume()
+		}
+	}
+}
+
+// transition applies a run event and updates the controls. It reports false
+// when the event is not allowed in the current state. The caller must hold
+// sendMutex.
+func (hs *HSMCommandSender) transition(e sender.RunEvent) bool {
+	next, err := hs.state.Next(e)
+	if err != nil {
+		return false
+	}
+	hs.state = next
+	hs.applyControls()
+
+	return true
+}
+
+// applyControls enables the run controls allowed in the current state.
+func (hs *HSMCommandSender) applyControls() {
+	c := hs.state.Controls()
+	setEnabled(hs.sendBtn, c.Send)
+	setEnabled(hs.scriptBtn, c.Send)
+	setEnabled(hs.stopBtn, c.Stop)
+	if c.Resume {
+		hs.pauseBtn.SetText("Resume")
+		hs.pauseBtn.SetIcon(theme.MediaPlayIcon())
+	} else {
+		hs.pauseBtn.SetText("Pause")
+		hs.pauseBtn.SetIcon(theme.MediaPauseIcon())
+	}
+	setEnabled(hs.pauseBtn, c.Pause || c.Resume)
+}
+
+// setEnabled enables or disables w.
+func setEnabled(w fyne.Disableable, enabled bool) {
+	if enabled {
+		w.Enable()
+	} else {
+		w.Disable()
+	}
+}
+
 func (hs *HSMCommandSender) CreateRenderer() fyne.WidgetRenderer {
 	return widget.NewSimpleRenderer(hs.container)
 }
@@ -560,39 +1675,49 @@ func (hs *HSMCommandSender) Cleanup() {
 	hs.sendMutex.Lock()
 	defer hs.sendMutex.Unlock()
 
-	if hs.isSending && hs.stopChan != nil {
+	if hs.transition(sender.EventStop) && hs.stopChan != nil {
 		close(hs.stopChan)
 		// do not nil the channel to allow proper channel semantics
-		hs.isSending = false // Ensure state is reset.
 	}
 
 	// Reset all UI elements
 	hs.command.SetText("")
 	hs.reqCount.SetText("0")
-	if hs.duration != nil {
-		hs.duration.SetText("")
-	}
+	hs.duration.SetText("60")
+	hs.interval.SetText(defaultRepeatSeconds)
+	hs.workers.SetText("")
+	hs.loadMode.SetSelected(loadModeCount)
 	if hs.tpsLabel != nil {
 		hs.tpsLabel.SetText("")
 	}
 	if hs.counter != nil {
 		hs.counter.SetText("Completed: 0")
 	}
+	hs.latencyLbl.SetText("")
+	hs.compareLbl.SetText("")
+	hs.slowLbl.SetText("")
 	if hs.progress != nil {
 		hs.progress.SetValue(0)
 	}
+	hs.runTPS = nil
+	hs.runCodes = nil
+	hs.runSlow = nil
+	hs.runSamples = nil
+	hs.tpsChart.SetValues(nil)
 	if hs.commandResponseField != nil {
 		hs.commandResponseField.SetText("")
 	}
-	if hs.commandHistoryField != nil {
-		hs.commandHistoryField.SetText("")
-	}
-
-	// Reset control elements
-	if hs.sendBtn != nil {
-		hs.sendBtn.Enable()
-	}
-	if hs.stopBtn != nil {
-		hs.stopBtn.Disable()
-	}
+	hs.responseHex.SetText("")
+	hs.selectionLbl.SetText("")
+	hs.responseStatus.SetText("")
+	hs.lastResult = nil
+	hs.requestDump.SetText("")
+	hs.responseDump.SetText("")
+	hs.lengthLbl.SetText("")
+	hs.pendingMu.Lock()
+	hs.pending = nil
+	hs.pendingMu.Unlock()
+	hs.history.Clear()
+	hs.historyList.Refresh()
+	hs.lastReport = nil
 }
@@ -0,0 +1,119 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+)
+
+func TestResponseAnnotation(t *testing.T) {
+	tests := []struct {
+		name           string
+		resp           string
+		want           string
+		wantImportance widget.Importance
+	}{
+		{"success", "ND007B44AC1DDEE2A94B", "ND 00 — no error", widget.SuccessImportance},
+		{"disabled", "A1680000", "A1 68 — command not enabled in security settings", widget.DangerImportance},
+		{"unknown_code", "A1ZZ", "A1 ZZ — unknown error code", widget.DangerImportance},
+		{"truncated", "A1", "unparseable response", widget.WarningImportance},
+		{"binary", "\x00\x01\x02\x03", "unparseable response", widget.WarningImportance},
+		{"empty", "", "unparseable response", widget.WarningImportance},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, importance := responseAnnotation([]byte(tt.resp))
+			if got != tt.want || importance != tt.wantImportance {
+				t.Errorf("responseAnnotation(%q) = %q, %v, want %q, %v",
+					tt.resp, got, importance, tt.want, tt.wantImportance)
+			}
+		})
+	}
+}
+
+func TestPayloadDump(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			"binary",
+			[]byte("ND00\x00\x80\xffOK"),
+			"00000000  4E 44 30 30 00 80 FF 4F  4B                       |ND00...OK|",
+		},
+		{"empty", nil, "(empty)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payloadDump(tt.data); got != tt.want {
+				t.Errorf("payloadDump(%q) =\n%q\nwant\n%q", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLengthDetails(t *testing.T) {
+	r := sender.Result{Request: []byte("NC"), Response: []byte("ND00\x00\x80")}
+	if got, want := lengthDetails(r), "Request: 2 bytes  Response: 6 bytes"; got != want {
+		t.Errorf("lengthDetails() = %q, want %q", got, want)
+	}
+
+	r = sender.Result{Request: []byte("NC"), Err: errors.New("command timed out")}
+	if got, want := lengthDetails(r), "Request: 2 bytes  Response: 0 bytes"; got != want {
+		t.Errorf("lengthDetails() = %q, want %q", got, want)
+	}
+}
+
+func TestHistoryEntry_String(t *testing.T) {
+	at := time.Date(2026, 5, 1, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry historyEntry
+		want  string
+	}{
+		{"note", historyEntry{note: "Script: 2 passed"}, "Script: 2 passed"},
+		{
+			"response",
+			historyEntry{result: &sender.Result{
+				Timestamp: at, Request: []byte("NC"), Response: []byte("ND00"), Latency: 3 * time.Millisecond,
+			}},
+			"[2026-05-01 10:30:00] NC => ND00 (3 ms)",
+		},
+		{
+			"retried_line",
+			historyEntry{result: &sender.Result{
+				Timestamp: at, Request: []byte("NC"), Response: []byte("ND00"), Line: 2, Retries: 2,
+			}},
+			"[2026-05-01 10:30:00] line 2: NC => ND00 (0 ms) (retried 2x)",
+		},
+		{
+			"compared",
+			historyEntry{result: &sender.Result{
+				Timestamp: at, Request: []byte("B8"), Response: []byte("B900V1"), Latency: 3 * time.Millisecond,
+				Compare: &sender.Comparison{Response: []byte("B900V2"), Latency: 5 * time.Millisecond},
+			}},
+			"[2026-05-01 10:30:00] B8 => A: B900V1 (3 ms) | B: B900V2 (5 ms) MISMATCH",
+		},
+		{
+			"cancelled",
+			historyEntry{result: &sender.Result{Timestamp: at, Request: []byte("NC"), Err: sender.ErrCancelled}},
+			"[2026-05-01 10:30:00] NC => Cancelled by Stop (0 ms)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tabs
+
+import (
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+)
+
+// initializeWorkers creates the worker count spinner of the concurrent mode
+// and returns its row. An empty count uses one worker per pooled connection.
+func (hs *HSMCommandSender) initializeWorkers() fyne.CanvasObject {
+	hs.workers = widget.NewEntry()
+	hs.workers.SetPlaceHolder("Pool size")
+	hs.workers.Validator = func(s string) error {
+		_, err := sender.ParseWorkers(s, hs.poolCapacity())
+		return err
+	}
+	hs.workersWarn = widget.NewLabel("")
+	hs.workersWarn.Importance = widget.WarningImportance
+	hs.workers.OnChanged = func(string) { hs.showWorkersWarning() }
+
+	spin := func(delta int) func() {
+		return func() {
+			n, err := sender.ParseWorkers(hs.workers.Text, hs.poolCapacity())
+			if err != nil {
+				n = hs.poolCapacity()
+			}
+			n = min(max(n+delta, 1), sender.MaxWorkersPerConnection*hs.poolCapacity())
+			hs.workers.SetText(strconv.Itoa(n))
+		}
+	}
+	spinner := container.NewBorder(nil, nil, nil,
+		container.NewVBox(widget.NewButton("▲", spin(1)), widget.NewButton("▼", spin(-1))),
+		hs.workers,
+	)
+
+	return container.NewHBox(
+		widget.NewLabelWithStyle("Workers", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewGridWrap(fyne.NewSize(120, spinner.MinSize().Height), spinner),
+		hs.workersWarn,
+	)
+}
+
+// poolCapacity returns the number of pooled HSM connections.
+func (hs *HSMCommandSender) poolCapacity() int {
+	if hs.connection == nil {
+		return 1
+	}
+
+	return max(int(hs.connection.GetPoolCapacity()), 1)
+}
+
+// showWorkersWarning warns when the workers outnumber the pooled connections.
+func (hs *HSMCommandSender) showWorkersWarning() {
+	n, err := sender.ParseWorkers(hs.workers.Text, hs.poolCapacity())
+	if err != nil || n <= hs.poolCapacity() || hs.logHistory {
+		hs.workersWarn.SetText("")
+		return
+	}
+	hs.workersWarn.SetText(fmt.Sprintf(
+		"%d workers share %d connections; requests will queue", n, hs.poolCapacity(),
+	))
+}
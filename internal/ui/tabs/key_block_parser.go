@@ -0,0 +1,283 @@
+package tabs
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/keyblock"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// keyBlockFieldWidth is the width of the field name column.
+const keyBlockFieldWidth = 150
+
+// keyBlockRow is one decoded field of a key block header.
+type keyBlockRow struct {
+	Name    string
+	Value   string
+	Meaning string
+	Problem bool // The value fails a sanity check.
+}
+
+// keyBlockRows returns the decoded header fields of kb, in header order.
+func keyBlockRows(kb keyblock.KeyBlock) []keyBlockRow {
+	length := keyBlockRow{Name: "Length", Value: fmt.Sprintf("%04d", kb.Length)}
+	if kb.LengthOK() {
+		length.Meaning = "matches the key block"
+	} else {
+		length.Meaning = fmt.Sprintf("key block has %d characters", len(kb.Raw))
+		length.Problem = true
+	}
+	reserved := "Reserved"
+	if kb.Format == keyblock.Thales {
+		reserved = "LMK ID"
+	}
+
+	rows := []keyBlockRow{
+		{Name: "Format", Value: kb.Format.String()},
+		{Name: "Version", Value: string(kb.Version), Meaning: keyblock.Versions[kb.Format][kb.Version]},
+		length,
+		{Name: "Key Usage", Value: kb.KeyUsage, Meaning: utils.KeyUsages[kb.KeyUsage]},
+		{Name: "Algorithm", Value: string(kb.Algorithm), Meaning: utils.KeyBlockAlgorithms[kb.Algorithm]},
+		{Name: "Mode of Use", Value: string(kb.ModeOfUse), Meaning: utils.KeyBlockModes[kb.ModeOfUse]},
+		{Name: "Key Version", Value: kb.KeyVersion},
+		{Name: "Exportability", Value: string(kb.Exportability), Meaning: utils.KeyBlockExportability[kb.Exportability]},
+		{Name: "Optional Blocks", Value: fmt.Sprintf("%02d", len(kb.OptionalBlocks))},
+		{Name: reserved, Value: kb.Reserved},
+	}
+	for _, b := range kb.OptionalBlocks {
+		rows = append(rows, keyBlockRow{Name: "Optional Block " + b.ID, Value: b.Data})
+	}
+
+	return rows
+}
+
+// KeyBlockParser represents the Key Block Parser tab.
+type KeyBlockParser struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	// Key block and its decoded header.
+	input    *widget.Entry
+	parseErr *widget.Label
+	fields   *fyne.Container
+	kb       *keyblock.KeyBlock // Nil unless the input parses.
+
+	// Unwrapping under a KBPK, for test environments.
+	allowUnwrap *widget.Check
+	unwrapBox   *fyne.Container
+	kbpk        *widget.Entry
+	unwrapErr   *widget.Label
+	kcv         *widget.Label
+	key         *widget.Label
+	reveal      *widget.Check
+	clearKey    []byte // Unwrapped key, shown only when revealed.
+}
+
+// NewKeyBlockParser creates a new Key Block Parser tab.
+func NewKeyBlockParser() *KeyBlockParser {
+	kp := &KeyBlockParser{}
+	kp.ExtendBaseWidget(kp)
+
+	kp.input = widget.NewMultiLineEntry()
+	kp.input.SetPlaceHolder("Paste a TR-31 key block, or a Thales key block starting with S")
+	kp.input.Wrapping = fyne.TextWrapBreak
+	kp.input.OnChanged = kp.onInputChanged
+
+	newError := func() *widget.Label {
+		l := widget.NewLabel("")
+		l.Importance = widget.DangerImportance
+		l.Wrapping = fyne.TextWrapWord
+		l.Hide()
+
+		return l
+	}
+	kp.parseErr = newError()
+	kp.unwrapErr = newError()
+	kp.fields = container.NewVBox()
+
+	kp.initializeUnwrap()
+
+	kp.container = container.NewVBox(
+		widget.NewCard("Key Block", "", container.NewVBox(kp.input, kp.parseErr)),
+		widget.NewCard("Header", "", kp.fields),
+		widget.NewCard("Unwrap", "", container.NewVBox(kp.allowUnwrap, kp.unwrapBox)),
+	)
+
+	return kp
+}
+
+// initializeUnwrap creates the KBPK fields, hidden until unwrapping is
+// allowed.
+func (kp *KeyBlockParser) initializeUnwrap() {
+	kp.allowUnwrap = widget.NewCheck("Unwrap TR-31 with a KBPK (test environments only)", kp.onAllowUnwrapToggled)
+
+	kp.kbpk = widget.NewPasswordEntry()
+	kp.kbpk.SetPlaceHolder("KBPK in hex (32, 48 or 64 hex digits)")
+	kp.kbpk.OnChanged = func(string) { kp.clearUnwrapped() }
+
+	kp.kcv = widget.NewLabel("")
+	kp.key = widget.NewLabel("")
+	kp.key.TextStyle = fyne.TextStyle{Monospace: true}
+	kp.reveal = widget.NewCheck("Reveal", func(bool) { kp.showKey() })
+	kp.reveal.Disable()
+
+	kp.unwrapBox = container.NewVBox(
+		widget.NewForm(widget.NewFormItem("KBPK", kp.kbpk)),
+		container.NewHBox(widget.NewButton("Unwrap", kp.onUnwrap)),
+		kp.unwrapErr,
+		widget.NewForm(
+			widget.NewFormItem("KCV", container.NewHBox(kp.kcv, newCopyButton(func() string { return kp.kcv.Text }))),
+			widget.NewFormItem("Key", container.NewHBox(kp.key, kp.reveal)),
+		),
+	)
+	kp.unwrapBox.Hide()
+}
+
+// onInputChanged decodes the key block as it is typed or pasted.
+func (kp *KeyBlockParser) onInputChanged(text string) {
+	kp.clearUnwrapped()
+	kp.kb = nil
+	kp.fields.RemoveAll()
+	defer kp.fields.Refresh()
+
+	if strings.TrimSpace(text) == "" {
+		showHint(kp.parseErr, nil)
+		return
+	}
+	kb, err := keyblock.Parse(text)
+	showHint(kp.parseErr, err)
+	if err != nil {
+		return
+	}
+
+	kp.kb = &kb
+	for _, row := range keyBlockRows(kb) {
+		kp.fields.Add(newKeyBlockField(row))
+	}
+}
+
+// newKeyBlockField lays out row with a button copying its value.
+func newKeyBlockField(row keyBlockRow) fyne.CanvasObject {
+	name := widget.NewLabelWithStyle(row.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	value := widget.NewLabelWithStyle(row.Value, fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	meaning := widget.NewLabel(row.Meaning)
+	if row.Problem {
+		value.Importance = widget.DangerImportance
+		meaning.Importance = widget.DangerImportance
+	}
+
+	return container.NewBorder(nil, nil,
+		container.NewGridWrap(fyne.NewSize(keyBlockFieldWidth, name.MinSize().Height), name),
+		newCopyButton(func() string { return row.Value }),
+		container.NewHBox(value, meaning),
+	)
+}
+
+// onAllowUnwrapToggled asks for confirmation before the KBPK fields are
+// shown, and unchecks the box when declined.
+func (kp *KeyBlockParser) onAllowUnwrapToggled(on bool) {
+	if !on {
+		kp.showUnwrap(false)
+		return
+	}
+
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	dialog.ShowConfirm(
+		"Unwrap Key Block",
+		"Unwrapping needs the clear key block protection key on this workstation.\n\n"+
+			"Use only test keys, never production keys. Continue?",
+		func(ok bool) {
+			if !ok {
+				kp.allowUnwrap.SetChecked(false)
+				return
+			}
+			kp.showUnwrap(true)
+		},
+		w,
+	)
+}
+
+// showUnwrap shows or hides the KBPK fields, clearing them when hidden.
+func (kp *KeyBlockParser) showUnwrap(on bool) {
+	if on {
+		kp.unwrapBox.Show()
+		return
+	}
+	kp.kbpk.SetText("")
+	kp.clearUnwrapped()
+	kp.unwrapBox.Hide()
+}
+
+// onUnwrap unwraps the key block under the KBPK and shows the key's KCV.
+func (kp *KeyBlockParser) onUnwrap() {
+	kp.clearUnwrapped()
+	if kp.kb == nil {
+		showHint(kp.unwrapErr, errors.New("paste a valid key block first"))
+		return
+	}
+	kbpk, err := hex.DecodeString(strings.TrimSpace(kp.kbpk.Text))
+	if err != nil || len(kbpk) == 0 {
+		showHint(kp.unwrapErr, errors.New("KBPK must be hex"))
+		return
+	}
+
+	p, err := keyblock.Unwrap(*kp.kb, kbpk)
+	clear(kbpk)
+	if err != nil {
+		showHint(kp.unwrapErr, err)
+		logger.Warn("keyblock_unwrap", "Failed", err.Error())
+		return
+	}
+
+	kp.clearKey = p.Key
+	kcv := p.KCV
+	if kcv == "" {
+		kcv = "n/a"
+	}
+	kp.kcv.SetText(kcv)
+	kp.reveal.Enable()
+	kp.showKey()
+	logger.Info("keyblock_unwrap", "Success", "KCV "+kcv)
+}
+
+// showKey shows the unwrapped key in clear when revealed, masked otherwise.
+func (kp *KeyBlockParser) showKey() {
+	switch {
+	case kp.clearKey == nil:
+		kp.key.SetText("")
+	case kp.reveal.Checked:
+		kp.key.SetText(strings.ToUpper(hex.EncodeToString(kp.clearKey)))
+	default:
+		kp.key.SetText(strings.Repeat("•", 2*len(kp.clearKey)))
+	}
+}
+
+// clearUnwrapped wipes the unwrapped key and its outputs.
+func (kp *KeyBlockParser) clearUnwrapped() {
+	clear(kp.clearKey)
+	kp.clearKey = nil
+	kp.kcv.SetText("")
+	kp.reveal.SetChecked(false)
+	kp.reveal.Disable()
+	kp.showKey()
+	showHint(kp.unwrapErr, nil)
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (kp *KeyBlockParser) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(kp.container)
+}
+
+// Cleanup implements TabContent interface.
+func (kp *KeyBlockParser) Cleanup() {
+	kp.showUnwrap(false)
+}
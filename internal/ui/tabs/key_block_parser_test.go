@@ -0,0 +1,171 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/keyblock"
+)
+
+// Version D example key block from TR-31, with its KBPK and key.
+const (
+	exampleKeyBlock = "D0112P0AE00E0000B82679114F470F540165EDFBF7E250FCEA43F810D215F8D207E2E417C07156A27E8E31DA05F7425509593D03A457DC34"
+	exampleKBPK     = "88E1AB2A2E3DD38C1FA039A536500CC8A87AB9D62DC92C01058FA79F44657DE6"
+	exampleKey      = "3F419E1CB7079442AA37474C2EFBF8B8"
+)
+
+func TestKeyBlockRows(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]keyBlockRow // Checked rows by name.
+		rows  int
+	}{
+		{
+			"tr31",
+			exampleKeyBlock,
+			map[string]keyBlockRow{
+				"Version":   {"Version", "D", "AES key derivation binding", false},
+				"Length":    {"Length", "0112", "matches the key block", false},
+				"Key Usage": {"Key Usage", "P0", "PIN encryption", false},
+				"Reserved":  {"Reserved", "00", "", false},
+			},
+			10,
+		},
+		{
+			"optional_block",
+			"C0096B0TX12S0100KS1800604B120F9292800000BFB9B689CB567E66FC3FEE5AD5F52161FC6545B9D60989015D02155C",
+			map[string]keyBlockRow{
+				"Optional Blocks":   {"Optional Blocks", "01", "", false},
+				"Optional Block KS": {"Optional Block KS", "00604B120F9292800000", "", false},
+			},
+			11,
+		},
+		{
+			"thales_length_mismatch",
+			"S10096K0TB00S0000" + strings.Repeat("0", 40),
+			map[string]keyBlockRow{
+				"Format": {"Format", "Thales", "", false},
+				"Length": {"Length", "0096", "key block has 56 characters", true},
+				"LMK ID": {"LMK ID", "00", "", false},
+			},
+			10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kb, err := keyblock.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows := keyBlockRows(kb)
+			if len(rows) != tt.rows {
+				t.Errorf("keyBlockRows() returned %d rows, want %d", len(rows), tt.rows)
+			}
+			for _, row := range rows {
+				if want, ok := tt.want[row.Name]; ok && row != want {
+					t.Errorf("row %s = %+v, want %+v", row.Name, row, want)
+				}
+				delete(tt.want, row.Name)
+			}
+			for name := range tt.want {
+				t.Errorf("row %s missing", name)
+			}
+		})
+	}
+}
+
+func TestKeyBlockParser_MalformedInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantErr   string // Empty when the input must parse.
+		wantShown bool   // Header fields are shown.
+	}{
+		{"empty", "", "", false},
+		{"blank", " \n ", "", false},
+		{"valid", exampleKeyBlock, "", true},
+		{"valid_with_line_breaks", exampleKeyBlock[:50] + "\n" + exampleKeyBlock[50:], "", true},
+		{"too_short", "D0112P0AE", "too short", false},
+		{"unknown_version", "Z0112P0AE00E0000", "unsupported key block version", false},
+		{"bad_length", "D01X2P0AE00E0000", "invalid key block length", false},
+		{"truncated_optional_block", "D0112P0AE00E0100KS", "truncated", false},
+		{"thales_bad_character", "S10096K0TB00S0000" + strings.Repeat("Z", 10) + "\x01", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+			kp := NewKeyBlockParser()
+			kp.input.SetText(tt.input)
+
+			if got := len(kp.fields.Objects) > 0; got != tt.wantShown {
+				t.Errorf("fields shown = %v, want %v", got, tt.wantShown)
+			}
+			if tt.wantShown || strings.TrimSpace(tt.input) == "" {
+				if kp.parseErr.Visible() {
+					t.Errorf("parse error shown: %q", kp.parseErr.Text)
+				}
+				return
+			}
+			if !kp.parseErr.Visible() || !strings.Contains(kp.parseErr.Text, tt.wantErr) {
+				t.Errorf("parse error = %q (visible %v), want %q", kp.parseErr.Text, kp.parseErr.Visible(), tt.wantErr)
+			}
+			if kp.kb != nil {
+				t.Error("malformed input left a key block to unwrap")
+			}
+		})
+	}
+}
+
+func TestKeyBlockParser_Unwrap(t *testing.T) {
+	test.NewTempApp(t)
+	kp := NewKeyBlockParser()
+	kp.showUnwrap(true)
+	kp.input.SetText(exampleKeyBlock)
+
+	kp.kbpk.SetText(exampleKBPK[:62] + "00")
+	kp.onUnwrap()
+	if !kp.unwrapErr.Visible() || kp.kcv.Text != "" {
+		t.Fatalf("wrong KBPK: error %q, KCV %q", kp.unwrapErr.Text, kp.kcv.Text)
+	}
+
+	kp.kbpk.SetText(exampleKBPK)
+	kp.onUnwrap()
+	if kp.unwrapErr.Visible() || len(kp.kcv.Text) != 6 {
+		t.Fatalf("unwrap: error %q, KCV %q", kp.unwrapErr.Text, kp.kcv.Text)
+	}
+	if strings.Contains(kp.key.Text, exampleKey) || kp.key.Text == "" {
+		t.Errorf("key shown before reveal: %q", kp.key.Text)
+	}
+
+	kp.reveal.SetChecked(true)
+	if kp.key.Text != exampleKey {
+		t.Errorf("revealed key = %q, want %q", kp.key.Text, exampleKey)
+	}
+	kp.reveal.SetChecked(false)
+	if strings.Contains(kp.key.Text, exampleKey) {
+		t.Error("key still shown after hiding")
+	}
+
+	// A new key block clears the unwrapped key.
+	kp.reveal.SetChecked(true)
+	kp.input.SetText(exampleKeyBlock[:len(exampleKeyBlock)-2])
+	if kp.clearKey != nil || kp.key.Text != "" || kp.kcv.Text != "" || kp.reveal.Checked {
+		t.Errorf("unwrapped key kept after input change: key %q, KCV %q", kp.key.Text, kp.kcv.Text)
+	}
+
+	kp.onUnwrap()
+	if !kp.unwrapErr.Visible() || !strings.Contains(kp.unwrapErr.Text, "header says") {
+		t.Errorf("length mismatch error = %q", kp.unwrapErr.Text)
+	}
+
+	kp.Cleanup()
+	if kp.kbpk.Text != "" || kp.unwrapBox.Visible() {
+		t.Error("Cleanup() kept the KBPK")
+	}
+}
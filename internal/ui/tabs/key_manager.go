@@ -1,15 +1,20 @@
 package tabs
 
 import (
+	"errors"
 	"fmt"
+	"runtime/debug"
 	"strings"
-	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
 	"fyne.io/fyne/v2/widget"
 	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
 )
 
 // KeySchemes holds supported Variant-LMK key scheme tags.
@@ -110,49 +115,154 @@ type KeyManager struct {
 	container *fyne.Container
 
 	connection *hsm.Connection
+	store      *storage.KeyStore // saves generated keys, may be nil
 
 	// Input fields.
-	keyType   *widget.Select
-	keyScheme *widget.Select
-	keyInput  *widget.Entry
-	kcv       *widget.Label
+	keyType     *keyTypeSelect
+	keyScheme   *widget.Select
+	keyInput    *widget.Entry
+	kcv         *widget.Label
+	saveBtn     *widget.Button
+	genBtn      *widget.Button
+	batchBtn    *widget.Button
+	ceremonyBtn *widget.Button
+	schemeErr   *widget.Label // scheme and key type incompatibility
+
+	// LMK type selection and key block fields.
+	lmkType      *widget.RadioGroup
+	variantForm  *widget.Form
+	keyBlockForm *widget.Form
+	lmkID        *widget.Entry
+	kbUsage      *widget.Select
+	kbAlgorithm  *widget.Select
+	kbMode       *widget.Select
+	kbExport     *widget.Select
+	kbHeader     *widget.Label // header of a generated key block
+
+	// Export under a ZMK (A0 mode 1).
+	exportCheck  *widget.Check
+	exportForm   *widget.Form
+	zmk          *widget.Entry
+	exportScheme *widget.Select
+	exportedKey  *widget.Label
+	exportedItem *widget.FormItem
+
+	// Import under a ZMK (A6).
+	importType    *keyTypeSelect
+	importZMK     *widget.Entry
+	importKey     *widget.Entry
+	importScheme  *widget.Select
+	importedKey   *widget.Label
+	importedKCV   *widget.Label
+	importSaveBtn *widget.Button
+	imported      *utils.A0Response
+	importedType  storage.KeyType
+
+	response *responseView // parsed view of the last HSM response
+
+	// Check value verification (BU).
+	verifyBtn    *widget.Button
+	verifyResult *widget.Label
+
+	// Last generated key.
+	generated     *utils.A0Response
+	generatedType storage.KeyType
+	lmkCheckValue string // LMK check value reported by the HSM, if known
 }
 
-// NewKeyManager creates a new Key Manager tab.
-func NewKeyManager(conn *hsm.Connection) *KeyManager {
-	km := &KeyManager{connection: conn}
+// NewKeyManager creates a new Key Manager tab. store may be nil, which
+// disables saving generated keys.
+func NewKeyManager(conn *hsm.Connection, store *storage.KeyStore) *KeyManager {
+	km := &KeyManager{connection: conn, store: store}
 	km.ExtendBaseWidget(km)
 
 	// Initialize input fields.
-	km.keyType = widget.NewSelect(KeyTypes, nil)
-	km.keyScheme = widget.NewSelect(KeySchemes, nil)
+	km.keyType = newKeyTypeSelect()
+	km.keyScheme = widget.NewSelect(KeySchemes, func(string) { km.checkScheme() })
+	km.keyType.OnResolved = func(k keyTypeInfo, err error) {
+		if err == nil {
+			km.setSchemeOptions(schemeOptions(k))
+		}
+		km.checkScheme()
+	}
+	km.schemeErr = widget.NewLabel("")
+	km.schemeErr.Importance = widget.WarningImportance
+	km.schemeErr.Wrapping = fyne.TextWrapWord
+	km.schemeErr.Hide()
+	km.genBtn = widget.NewButton("Generate in HSM", km.onGenerateKey)
+	km.genBtn.Importance = widget.HighImportance
+	km.batchBtn = widget.NewButton("Batch generate…", km.onBatchGenerate)
+	km.ceremonyBtn = widget.NewButton("Key ceremony…", km.onKeyCeremony)
 
 	km.keyInput = widget.NewEntry()
 	km.keyInput.SetPlaceHolder("Hex format key value...")
 
 	km.kcv = widget.NewLabel("KCV: ")
+	km.initializeVerify()
+	km.initializeKeyBlock()
+	km.initializeExport()
+	importCard := km.initializeImport()
+	km.response = newResponseView()
 
 	// Create form layout.
-	form := widget.NewForm(
+	km.variantForm = widget.NewForm(
 		&widget.FormItem{Text: "Key Type", Widget: km.keyType},
-		&widget.FormItem{Text: "Key Scheme", Widget: km.keyScheme},
-		&widget.FormItem{Text: "Key Value", Widget: km.keyInput},
-		&widget.FormItem{Text: "Check Value", Widget: km.kcv},
+		&widget.FormItem{Text: "Key Scheme", Widget: container.NewVBox(km.keyScheme, km.schemeErr)},
+	)
+	km.keyBlockForm = widget.NewForm(
+		&widget.FormItem{Text: "LMK Identifier", Widget: km.lmkID},
+		&widget.FormItem{Text: "Key Usage", Widget: km.kbUsage},
+		&widget.FormItem{Text: "Algorithm", Widget: km.kbAlgorithm},
+		&widget.FormItem{Text: "Mode of Use", Widget: km.kbMode},
+		&widget.FormItem{Text: "Exportability", Widget: km.kbExport},
+	)
+	km.keyBlockForm.Hide()
+	km.lmkType = widget.NewRadioGroup([]string{lmkTypeVariant, lmkTypeKeyBlock}, km.onLMKTypeChanged)
+	km.lmkType.Horizontal = true
+	km.lmkType.Required = true
+	km.lmkType.SetSelected(lmkTypeVariant)
+
+	form := widget.NewForm(
+		&widget.FormItem{Text: "Key Value", Widget: container.NewVBox(
+			container.NewBorder(
+				nil, nil, nil,
+				newCopyButton(func() string { return km.keyInput.Text }),
+				km.keyInput,
+			),
+			km.kbHeader,
+		)},
+		km.exportedItem,
+		&widget.FormItem{Text: "Check Value", Widget: container.NewVBox(
+			container.NewBorder(
+				nil, nil, nil,
+				container.NewHBox(km.verifyBtn, newCopyButton(kcvText(km.kcv))),
+				km.kcv,
+			),
+			km.verifyResult,
+		)},
 	)
 
-	// Add generate button to form.
-	form.SubmitText = "Generate in HSM"
-	form.OnSubmit = km.onGenerateKey
+	km.saveBtn = widget.NewButtonWithIcon("Save to store…", theme.DocumentSaveIcon(), km.onSaveToStore)
+	km.saveBtn.Disable()
 
-	// Layout everything in a container - storage removed.
 	km.container = container.NewVBox(
+		widget.NewForm(&widget.FormItem{Text: "LMK Type", Widget: km.lmkType}),
+		km.variantForm,
+		km.keyBlockForm,
+		km.exportCheck,
+		km.exportForm,
 		form,
+		container.NewHBox(km.genBtn, km.batchBtn, km.saveBtn, km.ceremonyBtn),
+		importCard,
+		km.response.card,
 	)
 
 	return km
 }
 
 func (km *KeyManager) onGenerateKey() {
+	defer recoverHandler("key_generate_hsm")
+
 	// check HSM connection.
 	if km.connection.GetState() != hsm.Connected {
 		dialog.ShowError(
@@ -163,71 +273,280 @@ func (km *KeyManager) onGenerateKey() {
 		return
 	}
 
-	// validate selected key scheme.
-	if km.keyScheme.Selected == "" {
-		dialog.ShowError(
-			fmt.Errorf("select key scheme"),
-			fyne.CurrentApp().Driver().AllWindows()[0],
-		)
+	req, err := km.buildA0Command()
+	if err != nil {
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 
 		return
 	}
 
-	// build A0 command: generate key under Variant LMK with scheme.
-	fields := strings.Fields(km.keyType.Selected)
-	keyCode := fields[0]
-	scheme := km.keyScheme.Selected
-	// mode '0' = generate under LMK only.
-	mode := '0'
-	cmdText := fmt.Sprintf("A0%c%s%s", mode, keyCode, scheme)
-	respBytes, err := km.connection.ExecuteCommand([]byte(cmdText), 5*time.Second)
+	respBytes, err := km.connection.ExecuteCommand([]byte(req.cmd), km.connection.CommandTimeout())
 	if err != nil {
+		km.response.clear()
+		logger.Error("key_generate_hsm", "Failed", err.Error())
 		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 
 		return
 	}
-	respStr := string(respBytes)
 
-	// check response code.
-	if !strings.HasPrefix(respStr, "A1") {
-		dialog.ShowError(
-			fmt.Errorf("unexpected response code: %s", respStr[:2]),
-			fyne.CurrentApp().Driver().AllWindows()[0],
-		)
+	key, err := req.parse(respBytes)
+	km.response.show(respBytes, key, err)
+	if err != nil {
+		if key.ErrorCode != "" {
+			logger.Error(
+				"key_generate_hsm",
+				"Failed",
+				fmt.Sprintf("type=%s scheme=%c error=%s", req.keyType, req.scheme, key.ErrorCode),
+			)
+		}
+		dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+
+		return
+	}
+
+	logger.Info(
+		"key_generate_hsm",
+		"Success",
+		fmt.Sprintf("type=%s scheme=%c kcv=%s", req.keyType, req.scheme, key.CheckValue),
+	)
+
+	// display results.
+	km.keyInput.SetText(key.Cryptogram)
+	km.kcv.SetText("KCV: " + key.CheckValue)
+	km.verifyResult.Hide()
+	km.showExported(key)
+	if req.scheme == 'S' {
+		km.kbHeader.SetText(key.Header.String())
+		km.kbHeader.Show()
+	} else {
+		km.kbHeader.Hide()
+	}
+
+	km.generated = &key
+	km.generatedType = storage.KeyType(req.keyType)
+	km.lmkCheckValue = km.queryLMKCheckValue()
+	if km.store != nil {
+		km.saveBtn.Enable()
+	}
+}
+
+// selectedScheme returns the tag of the selected scheme, or "" when none is.
+func (km *KeyManager) selectedScheme() string {
+	if km.keyScheme.Selected == "" {
+		return ""
+	}
+
+	return km.keyScheme.Selected[:1]
+}
 
+// setSchemeOptions replaces the scheme options, keeping the selected scheme.
+func (km *KeyManager) setSchemeOptions(options []string) {
+	selected := km.keyScheme.Selected
+	km.keyScheme.SetOptions(options)
+	if selected == "" {
 		return
 	}
+	for _, option := range options {
+		if option[0] == selected[0] {
+			km.keyScheme.SetSelected(option)
+			return
+		}
+	}
+}
 
-	// parse error code.
-	errCode := respStr[2:4]
-	if errCode != "00" {
-		var msg string
-		switch errCode {
-		case "07":
-			msg = "invalid zka master key type"
-		case "10":
-			msg = "zmk or tmk parity error"
-		case "68":
-			msg = "command disabled"
-		default:
-			msg = "error code " + errCode
+// checkScheme warns about and blocks a scheme the selected key type does not
+// allow. Key block LMKs are not restricted.
+func (km *KeyManager) checkScheme() {
+	var err error
+	if km.lmkType == nil || km.lmkType.Selected != lmkTypeKeyBlock {
+		if k, resolveErr := km.keyType.Selected(); resolveErr == nil {
+			err = schemeCheck(k, km.selectedScheme())
 		}
+	}
 
-		dialog.ShowError(
-			fmt.Errorf(msg),
-			fyne.CurrentApp().Driver().AllWindows()[0],
-		)
+	if err != nil {
+		km.schemeErr.SetText(err.Error())
+		km.schemeErr.Show()
+		km.genBtn.Disable()
+		km.batchBtn.Disable()
+	} else {
+		km.schemeErr.Hide()
+		km.genBtn.Enable()
+		km.batchBtn.Enable()
+	}
+}
+
+// a0Request is an A0 command with what is needed to parse its response.
+type a0Request struct {
+	cmd          string
+	keyType      string // key type name, or the key usage for key blocks
+	scheme       byte
+	exportScheme byte // zero unless the key is also exported under a ZMK
+}
+
+// parse parses the response to the request.
+func (req a0Request) parse(resp []byte) (utils.A0Response, error) {
+	if req.exportScheme != 0 {
+		return utils.ParseA0ExportResponse(resp, req.scheme, req.exportScheme)
+	}
+
+	return utils.ParseA0Response(resp, req.scheme)
+}
+
+// buildA0Command builds the A0 command generating a key under the LMK of the
+// selected type, also exporting it under a ZMK when asked to.
+func (km *KeyManager) buildA0Command() (a0Request, error) {
+	// mode '0' = generate under LMK only.
+	const mode = '0'
+
+	if km.lmkType.Selected == lmkTypeKeyBlock {
+		params := km.keyBlockParams()
+		cmd, err := utils.BuildA0KeyBlockCommand(mode, params)
+
+		return a0Request{cmd: cmd, keyType: params.KeyUsage, scheme: 'S'}, err
+	}
+
+	// validate selected key type and scheme.
+	keyType, err := km.keyType.Selected()
+	if err != nil {
+		return a0Request{}, err
+	}
+	if km.keyScheme.Selected == "" {
+		return a0Request{}, errors.New("select key scheme")
+	}
+	if err := schemeCheck(keyType, km.selectedScheme()); err != nil {
+		return a0Request{}, err
+	}
+	scheme := km.keyScheme.Selected[0]
+	if err := utils.ValidateKeyScheme(scheme); err != nil {
+		return a0Request{}, err
+	}
+	req := a0Request{keyType: keyType.Mnemonic, scheme: scheme}
+
+	if km.exportCheck.Checked {
+		req.exportScheme, req.cmd, err = km.exportCommand(keyType.Code, scheme)
+
+		return req, err
+	}
+
+	// generate key under Variant LMK with scheme.
+	req.cmd = fmt.Sprintf("A0%c%s%c", mode, keyType.Code, scheme)
+
+	return req, nil
+}
+
+// queryLMKCheckValue asks the HSM for the check value of its LMK. It returns
+// an empty string when the HSM does not report one.
+func (km *KeyManager) queryLMKCheckValue() string {
+	resp, err := km.connection.ExecuteCommand([]byte("NC"), km.connection.CommandTimeout())
+	if err != nil {
+		return ""
+	}
+	kcv, err := utils.ParseLMKCheckValue(resp)
+	if err != nil {
+		logger.Warn("lmk_check_value", "Failed", err.Error())
+		return ""
+	}
 
+	return kcv
+}
+
+// onSaveToStore stores the generated key.
+func (km *KeyManager) onSaveToStore() {
+	if km.generated == nil {
+		dialog.ShowError(errors.New("generate a key first"), fyne.CurrentApp().Driver().AllWindows()[0])
 		return
 	}
+	km.saveToStore(*km.generated, km.generatedType)
+}
 
-	// extract encrypted key and kcv.
-	encrypted := respStr[4 : len(respStr)-6]
-	kcvVal := respStr[len(respStr)-6:]
+// saveToStore asks for a name and stores key, confirming before an existing
+// key is replaced.
+func (km *KeyManager) saveToStore(key utils.A0Response, suggestedType storage.KeyType) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
 
-	// display results.
-	km.keyInput.SetText(encrypted)
-	km.kcv.SetText("KCV: " + kcvVal)
+	name := widget.NewEntry()
+	name.Validator = utils.ValidateKeyName
+	keyType := widget.NewEntry()
+	keyType.SetText(string(suggestedType))
+	length := "unknown"
+	if key.Length() > 0 {
+		length = fmt.Sprintf("%d bytes", key.Length())
+	}
+	lmk := km.lmkCheckValue
+	if lmk == "" {
+		lmk = "unknown"
+	}
+	items := []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Type", keyType),
+		widget.NewFormItem("Length", widget.NewLabel(length)),
+		widget.NewFormItem("Check Value", widget.NewLabel(key.CheckValue)),
+		widget.NewFormItem("LMK Check Value", widget.NewLabel(lmk)),
+	}
+	if same := km.store.SameCheckValue(key.CheckValue, ""); len(same) > 0 {
+		warning := widget.NewLabel(fmt.Sprintf(
+			"Stored keys with the same check value: %s", strings.Join(same, ", "),
+		))
+		warning.Importance = widget.WarningImportance
+		warning.Wrapping = fyne.TextWrapWord
+		items = append(items, widget.NewFormItem("", warning))
+	}
+
+	dialog.ShowForm("Save to store", "Save", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		entry, err := storage.GeneratedKeyEntry(
+			name.Text, storage.KeyType(strings.TrimSpace(keyType.Text)), key, km.lmkCheckValue,
+		)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if _, exists := km.store.Get(entry.Name); exists {
+			dialog.ShowConfirm(
+				"Overwrite Key",
+				fmt.Sprintf("Key %q already exists. Overwrite it?", entry.Name),
+				func(overwrite bool) {
+					if overwrite {
+						km.storeKey(entry)
+					}
+				},
+				w,
+			)
+
+			return
+		}
+		km.storeKey(entry)
+	}, w)
+}
+
+// storeKey stores entry and reports the outcome.
+func (km *KeyManager) storeKey(entry storage.KeyEntry) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if err := km.store.Store(entry); err != nil {
+		logger.Error("key_store", "Failed", err.Error())
+		dialog.ShowError(fmt.Errorf("failed to store %s: %v", entry.Name, err), w)
+
+		return
+	}
+	logger.Info("key_store", "Success", fmt.Sprintf("name=%s type=%s kcv=%s", entry.Name, entry.Type, entry.CheckValue))
+	dialog.ShowInformation("Key saved", fmt.Sprintf("Stored %s (KCV %s)", entry.Name, entry.CheckValue), w)
+}
+
+// recoverHandler is deferred by handlers parsing HSM responses. As a last
+// resort it turns a panic into a logged error instead of taking down the UI.
+func recoverHandler(event string) {
+	if r := recover(); r != nil {
+		logger.Error(event, "Panic", fmt.Sprintf("%v\n%s", r, debug.Stack()))
+		fyne.Do(func() {
+			dialog.ShowError(
+				fmt.Errorf("unexpected error handling the HSM response: %v", r),
+				fyne.CurrentApp().Driver().AllWindows()[0],
+			)
+		})
+	}
 }
 
 // CreateRenderer implements fyne.Widget interface.
@@ -240,4 +559,16 @@ func (km *KeyManager) Cleanup() {
 	// Clear sensitive data.
 	km.keyInput.SetText("")
 	km.kcv.SetText("KCV: ")
+	km.verifyResult.Hide()
+	km.kbHeader.SetText("")
+	km.kbHeader.Hide()
+	km.showExported(utils.A0Response{})
+	km.response.clear()
+	km.zmk.SetText("")
+	km.importZMK.SetText("")
+	km.importKey.SetText("")
+	km.clearImport()
+	km.generated = nil
+	km.lmkCheckValue = ""
+	km.saveBtn.Disable()
 }
@@ -0,0 +1,252 @@
+package tabs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/keygen"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// onBatchGenerate asks for a name prefix and count and generates that many
+// keys with the current settings.
+func (km *KeyManager) onBatchGenerate() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if km.connection.GetState() != hsm.Connected {
+		dialog.ShowError(fmt.Errorf("hsm not connected - please connect first"), w)
+		return
+	}
+	req, err := km.buildA0Command()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	prefix := widget.NewEntry()
+	prefix.SetText(strings.ToLower(req.keyType) + "-")
+	prefix.Validator = func(s string) error {
+		return utils.ValidateKeyName(s + "1")
+	}
+	count := widget.NewEntry()
+	count.SetText("10")
+	count.Validator = func(s string) error {
+		_, err := keygen.ParseCount(s)
+		return err
+	}
+	store := widget.NewCheck("Save all to the key store", nil)
+	if km.store == nil {
+		store.Disable()
+	}
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Name Prefix", prefix),
+		widget.NewFormItem("Count", count),
+		widget.NewFormItem("", store),
+	}
+	dialog.ShowForm("Batch Generate", "Generate", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		n, _ := keygen.ParseCount(count.Text)
+		b := keygen.Batch{
+			Prefix:  prefix.Text,
+			Count:   n,
+			Command: []byte(req.cmd),
+			Parse:   req.parse,
+			Timeout: km.connection.CommandTimeout(),
+		}
+		if store.Checked {
+			if existing := km.existingKeys(keygen.Names(b.Prefix, b.Count)); len(existing) > 0 {
+				dialog.ShowError(fmt.Errorf(
+					"the key store already has keys named %s - choose another prefix",
+					strings.Join(existing, ", "),
+				), w)
+
+				return
+			}
+		}
+		km.runBatch(b, storage.KeyType(req.keyType), store.Checked)
+	}, w)
+}
+
+// existingKeys returns the names, out of names, already in the key store,
+// listing at most five.
+func (km *KeyManager) existingKeys(names []string) []string {
+	var existing []string
+	for _, name := range names {
+		if _, ok := km.store.Get(name); ok {
+			existing = append(existing, name)
+			if len(existing) == 5 {
+				break
+			}
+		}
+	}
+
+	return existing
+}
+
+// runBatch generates the keys of b, showing the results as they arrive. The
+// successful keys are saved as keyType when store is set.
+func (km *KeyManager) runBatch(b keygen.Batch, keyType storage.KeyType, store bool) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var rows []string
+	var results []keygen.Result
+	progress := widget.NewProgressBar()
+	progress.Max = float64(b.Count)
+	status := widget.NewLabel("Generating…")
+	list := widget.NewList(
+		func() int { return len(rows) },
+		func() fyne.CanvasObject { return widget.NewLabel("") },
+		func(id widget.ListItemID, o fyne.CanvasObject) { o.(*widget.Label).SetText(rows[id]) },
+	)
+
+	cancelBtn := widget.NewButton("Cancel", cancel)
+	exportBtn := widget.NewButtonWithIcon("Export CSV…", theme.DocumentSaveIcon(), func() {
+		exportBatch(results)
+	})
+	exportBtn.Disable()
+	content := container.NewBorder(
+		container.NewVBox(progress, status),
+		container.NewHBox(exportBtn, cancelBtn),
+		nil, nil,
+		list,
+	)
+	d := dialog.NewCustomWithoutButtons("Batch Generate", content, w)
+	d.Resize(fyne.NewSize(640, 480))
+	cancelBtn.OnTapped = func() {
+		if ctx.Err() == nil {
+			cancel()
+			return
+		}
+		d.Hide()
+	}
+	d.Show()
+
+	go func() {
+		defer recoverHandler("key_batch_generate")
+
+		lmk := km.queryLMKCheckValue()
+		res, err := keygen.Run(ctx, connExecutor{km.connection}, b, func(r keygen.Result) {
+			row := batchRow(r)
+			fyne.Do(func() {
+				rows = append(rows, row)
+				progress.SetValue(float64(len(rows)))
+				list.Refresh()
+			})
+		})
+		cancel()
+
+		saved, storeErrs := 0, 0
+		if store {
+			saved, storeErrs = km.storeBatch(res, keyType, lmk)
+		}
+		summary := batchSummary(res, err, saved, storeErrs, store)
+		logger.Info("key_batch_generate", "Finished", summary)
+		fyne.Do(func() {
+			results = res
+			status.SetText(summary)
+			if err != nil {
+				status.Importance = widget.DangerImportance
+				status.Refresh()
+			}
+			exportBtn.Enable()
+			cancelBtn.SetText("Close")
+		})
+	}()
+}
+
+// batchRow renders the result of one key of a batch.
+func batchRow(r keygen.Result) string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s  error: %v", r.Name, r.Err)
+	}
+
+	return fmt.Sprintf("%s  %s  KCV %s", r.Name, r.Key.Cryptogram, r.Key.CheckValue)
+}
+
+// storeBatch saves the successful keys of a batch and returns how many were
+// saved and how many failed to save.
+func (km *KeyManager) storeBatch(results []keygen.Result, keyType storage.KeyType, lmk string) (int, int) {
+	saved, failed := 0, 0
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		entry, err := storage.GeneratedKeyEntry(r.Name, keyType, r.Key, lmk)
+		if err == nil {
+			err = km.store.Store(entry)
+		}
+		if err != nil {
+			logger.Error("key_store", "Failed", fmt.Sprintf("name=%s error=%v", r.Name, err))
+			failed++
+
+			continue
+		}
+		saved++
+	}
+
+	return saved, failed
+}
+
+// batchSummary describes the outcome of a batch.
+func batchSummary(results []keygen.Result, runErr error, saved, storeErrs int, store bool) string {
+	generated := 0
+	for _, r := range results {
+		if r.Err == nil {
+			generated++
+		}
+	}
+
+	summary := fmt.Sprintf("Generated %d of %d keys", generated, len(results))
+	if failed := len(results) - generated; failed > 0 {
+		summary += fmt.Sprintf(", %d failed", failed)
+	}
+	if store {
+		summary += fmt.Sprintf(", %d saved", saved)
+		if storeErrs > 0 {
+			summary += fmt.Sprintf(" (%d could not be saved)", storeErrs)
+		}
+	}
+	switch {
+	case errors.Is(runErr, context.Canceled):
+		summary += " - cancelled"
+	case runErr != nil:
+		summary += " - stopped: " + runErr.Error()
+	}
+
+	return summary
+}
+
+// exportBatch saves the results of a batch as CSV.
+func exportBatch(results []keygen.Result) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if err := keygen.Export(wc, results); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+		}
+	}, w)
+	save.SetFileName("generated_keys.csv")
+	save.Show()
+}
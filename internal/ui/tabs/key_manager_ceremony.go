@@ -0,0 +1,288 @@
+package tabs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/ceremony"
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// ceremonyKeyLengths are the key lengths offered by the ceremony wizard.
+var ceremonyKeyLengths = []string{"64", "128", "192"}
+
+// onKeyCeremony starts the offline key ceremony wizard. Each step hands its
+// outputs to the next: plan, mailers, optional import in the HSM with
+// verification, and recording in the key store.
+func (km *KeyManager) onKeyCeremony() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	length := widget.NewSelect(ceremonyKeyLengths, nil)
+	length.SetSelected("128")
+	counts := make([]string, 0, utils.MaxA4Components-utils.MinA4Components+1)
+	for n := utils.MinA4Components; n <= utils.MaxA4Components; n++ {
+		counts = append(counts, strconv.Itoa(n))
+	}
+	count := widget.NewSelect(counts, nil)
+	count.SetSelected("3")
+	parity := widget.NewCheck("Odd parity", nil)
+	parity.SetChecked(true)
+
+	items := []*widget.FormItem{
+		widget.NewFormItem("Key Length (bits)", length),
+		widget.NewFormItem("Components", count),
+		widget.NewFormItem("", parity),
+	}
+	dialog.ShowForm("Key Ceremony: Plan", "Generate", "Cancel", items, func(ok bool) {
+		if !ok {
+			return
+		}
+		bits, _ := strconv.Atoi(length.Selected)
+		n, _ := strconv.Atoi(count.Selected)
+		c, err := ceremony.Prepare(ceremony.Plan{KeyBits: bits, Components: n, OddParity: parity.Checked}, time.Now())
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		logger.Info("key_ceremony", "Started", fmt.Sprintf("%d bits, %d components, KCV %s", bits, n, c.KCV))
+		km.ceremonyExport(c)
+	}, w)
+}
+
+// ceremonyExport shows the check values of the ceremony key and its
+// components and asks where to write the mailers. Cancelling discards the
+// components.
+func (km *KeyManager) ceremonyExport(c *ceremony.Ceremony) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	pattern := widget.NewEntry()
+	pattern.SetText(ceremony.DefaultFilePattern)
+	items := []*widget.FormItem{
+		widget.NewFormItem("Key Check Value", widget.NewLabel(c.KCV)),
+	}
+	for _, m := range c.Mailers {
+		items = append(items, widget.NewFormItem(
+			fmt.Sprintf("Component %d KCV", m.Index),
+			widget.NewLabel(m.ComponentKCV),
+		))
+	}
+	items = append(items, widget.NewFormItem("File Pattern", pattern))
+
+	dialog.ShowForm("Key Ceremony: Mailers", "Export…", "Cancel", items, func(ok bool) {
+		if !ok {
+			logger.Warn("key_ceremony", "Cancelled", "components discarded before export")
+			return
+		}
+		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if uri == nil {
+				km.ceremonyExport(c) // Cancelled; ask again rather than lose the components.
+				return
+			}
+			km.writeCeremonyMailers(c, uri.Path(), pattern.Text, false)
+		}, w)
+	}, w)
+}
+
+// writeCeremonyMailers writes the mailers to dir, asking before overwriting
+// files, and moves on to the import step.
+func (km *KeyManager) writeCeremonyMailers(c *ceremony.Ceremony, dir, pattern string, overwrite bool) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	paths, err := c.Export(dir, pattern, overwrite)
+	if errors.Is(err, ceremony.ErrFilesExist) {
+		dialog.ShowConfirm("Overwrite files?", err.Error(), func(ok bool) {
+			if ok {
+				km.writeCeremonyMailers(c, dir, pattern, true)
+			} else {
+				km.ceremonyExport(c)
+			}
+		}, w)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, w)
+		km.ceremonyExport(c)
+		return
+	}
+
+	logger.Info("key_ceremony", "Exported", fmt.Sprintf("%d mailers written to %s", len(paths), dir))
+	km.ceremonyImport(c)
+}
+
+// ceremonySchemes returns the variant schemes holding keys of the ceremony
+// key length.
+func ceremonySchemes(keyBits int) []string {
+	var schemes []string
+	for _, s := range KeySchemes {
+		if utils.SchemeKeyLength(s[0]) == keyBits/8 {
+			schemes = append(schemes, s)
+		}
+	}
+
+	return schemes
+}
+
+// ceremonyImport asks for the components as encrypted under the LMK at the
+// HSM console and forms the key from them with A4, verifying it with BU. The
+// step can be skipped, in which case only the key metadata is recorded.
+func (km *KeyManager) ceremonyImport(c *ceremony.Ceremony) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	keyType := newKeyTypeSelect()
+	scheme := widget.NewSelect(ceremonySchemes(c.KeyBits), nil)
+	scheme.SetSelectedIndex(0)
+	components := make([]*widget.Entry, c.Components)
+	items := []*widget.FormItem{
+		widget.NewFormItem("Key Type", keyType),
+		widget.NewFormItem("Key Scheme", scheme),
+	}
+	for i := range components {
+		components[i] = widget.NewEntry()
+		components[i].SetPlaceHolder("Component under the LMK")
+		items = append(items, widget.NewFormItem(fmt.Sprintf("Component %d", i+1), components[i]))
+	}
+	note := widget.NewLabel(
+		"Mailers written. Once the custodians have entered their components at the HSM console, " +
+			"paste the encrypted components to form and verify the key, or skip to record it.",
+	)
+	note.Wrapping = fyne.TextWrapWord
+	items = append([]*widget.FormItem{widget.NewFormItem("", note)}, items...)
+
+	d := dialog.NewForm("Key Ceremony: Import", "Import", "Skip", items, func(ok bool) {
+		if !ok {
+			km.ceremonyRecord(c, "", "")
+			return
+		}
+		k, err := keyType.Selected()
+		if err == nil && km.connection.GetState() != hsm.Connected {
+			err = errors.New("hsm not connected - please connect first")
+		}
+		if err != nil {
+			dialog.ShowError(err, w)
+			km.ceremonyImport(c)
+			return
+		}
+		encrypted := make([]string, len(components))
+		for i, e := range components {
+			encrypted[i] = strings.ToUpper(strings.TrimSpace(e.Text))
+		}
+		km.runCeremonyImport(c, k, scheme.Selected[0], encrypted)
+	}, w)
+	d.Resize(fyne.NewSize(560, 0))
+	d.Show()
+}
+
+// runCeremonyImport forms and verifies the key in the background.
+func (km *KeyManager) runCeremonyImport(c *ceremony.Ceremony, k keyTypeInfo, scheme byte, encrypted []string) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	progress := dialog.NewCustomWithoutButtons(
+		"Key Ceremony", widget.NewLabel("Forming and verifying the key in the HSM…"), w,
+	)
+	progress.Show()
+
+	go func() {
+		defer recoverHandler("key_ceremony")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		exec := connExecutor{km.connection}
+		err := c.Import(ctx, exec, k.Code, scheme, encrypted)
+		if err == nil {
+			err = c.Verify(ctx, exec)
+		}
+		var lmk string
+		if err == nil {
+			lmk = km.queryLMKCheckValue()
+		}
+		fyne.Do(func() {
+			progress.Hide()
+			if err != nil {
+				logger.Error("key_ceremony", "Failed", err.Error())
+				dialog.ShowError(fmt.Errorf("key ceremony import failed: %v", err), w)
+				km.ceremonyImport(c)
+
+				return
+			}
+			km.ceremonyRecord(c, storage.KeyType(k.Mnemonic), lmk)
+		})
+	}()
+}
+
+// ceremonyRecord saves the ceremony key to the key store, ending the wizard.
+// lmkCheckValue identifies the LMK an imported key is encrypted under.
+func (km *KeyManager) ceremonyRecord(c *ceremony.Ceremony, suggestedType storage.KeyType, lmkCheckValue string) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	summary := fmt.Sprintf("%d-bit key, %d components, KCV %s", c.KeyBits, c.Components, c.KCV)
+	if c.Verified {
+		summary += ", formed and verified in the HSM"
+	}
+	if km.store == nil {
+		logger.Info("key_ceremony", "Completed", summary)
+		dialog.ShowInformation("Key Ceremony", "Ceremony completed: "+summary, w)
+		return
+	}
+
+	name := widget.NewEntry()
+	name.Validator = utils.ValidateKeyName
+	keyType := widget.NewEntry()
+	keyType.SetText(string(suggestedType))
+	value := "metadata only (not imported)"
+	if c.Verified {
+		value = c.Key.Cryptogram
+	}
+	items := []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel(summary)),
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Type", keyType),
+		widget.NewFormItem("Value", widget.NewLabel(value)),
+	}
+	dialog.ShowForm("Key Ceremony: Record", "Save", "Skip", items, func(ok bool) {
+		if !ok {
+			logger.Info("key_ceremony", "Completed", summary+", not recorded")
+			return
+		}
+		keyName := strings.TrimSpace(name.Text)
+		record := func() {
+			entry, err := c.Record(km.store, keyName, storage.KeyType(strings.TrimSpace(keyType.Text)), lmkCheckValue)
+			if err != nil {
+				dialog.ShowError(err, w)
+				km.ceremonyRecord(c, suggestedType, lmkCheckValue)
+				return
+			}
+			logger.Info("key_ceremony", "Completed", summary+", recorded as "+entry.Name)
+			dialog.ShowInformation("Key Ceremony", fmt.Sprintf("Key %q recorded.", entry.Name), w)
+		}
+		if _, exists := km.store.Get(keyName); !exists {
+			record()
+			return
+		}
+		dialog.ShowConfirm(
+			"Overwrite Key",
+			fmt.Sprintf("Key %q already exists. Overwrite it?", keyName),
+			func(overwrite bool) {
+				if overwrite {
+					record()
+				} else {
+					km.ceremonyRecord(c, suggestedType, lmkCheckValue)
+				}
+			},
+			w,
+		)
+	}, w)
+}
@@ -0,0 +1,73 @@
+package tabs
+
+import (
+	"errors"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// initializeExport creates the fields exporting a generated key under a ZMK.
+// Exporting is only offered for Variant LMKs.
+func (km *KeyManager) initializeExport() {
+	km.zmk = widget.NewEntry()
+	km.zmk.SetPlaceHolder("ZMK under the LMK, e.g. U0123...")
+	km.zmk.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+
+		return utils.ValidateZMK(strings.TrimSpace(s))
+	}
+	km.exportScheme = widget.NewSelect(KeySchemes, nil)
+	km.exportScheme.SetSelected("X")
+
+	km.exportForm = widget.NewForm(
+		&widget.FormItem{Text: "ZMK", Widget: km.zmk},
+		&widget.FormItem{Text: "Export Scheme", Widget: km.exportScheme},
+	)
+	km.exportForm.Hide()
+
+	km.exportCheck = widget.NewCheck("Also export under ZMK", func(checked bool) {
+		if checked {
+			km.exportForm.Show()
+		} else {
+			km.exportForm.Hide()
+		}
+	})
+
+	km.exportedKey = widget.NewLabel("")
+	km.exportedKey.TextStyle = fyne.TextStyle{Monospace: true}
+	km.exportedKey.Wrapping = fyne.TextWrapBreak
+	km.exportedItem = &widget.FormItem{Text: "Key under ZMK", Widget: container.NewBorder(
+		nil, nil, nil,
+		newCopyButton(func() string { return km.exportedKey.Text }),
+		km.exportedKey,
+	)}
+}
+
+// exportCommand builds the A0 mode 1 command generating a key of keyType in
+// scheme and exporting it under the entered ZMK. It returns the export scheme
+// with the command.
+func (km *KeyManager) exportCommand(keyType string, scheme byte) (byte, string, error) {
+	zmk := strings.TrimSpace(km.zmk.Text)
+	if zmk == "" {
+		return 0, "", errors.New("enter the ZMK to export under")
+	}
+	if km.exportScheme.Selected == "" {
+		return 0, "", errors.New("select export scheme")
+	}
+	exportScheme := km.exportScheme.Selected[0]
+	cmd, err := utils.BuildA0ExportCommand(keyType, scheme, zmk, exportScheme)
+
+	return exportScheme, cmd, err
+}
+
+// showExported shows the key exported under the ZMK, if any.
+func (km *KeyManager) showExported(key utils.A0Response) {
+	km.exportedKey.SetText(key.ExportCryptogram)
+}
@@ -0,0 +1,166 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// initializeImport creates the section importing a key received under a ZMK
+// and returns it.
+func (km *KeyManager) initializeImport() fyne.CanvasObject {
+	km.importType = newKeyTypeSelect()
+	km.importZMK = widget.NewEntry()
+	km.importZMK.SetPlaceHolder("ZMK under the LMK, e.g. U0123...")
+	km.importZMK.Validator = func(s string) error {
+		if s == "" {
+			return nil
+		}
+
+		return utils.ValidateZMK(strings.TrimSpace(s))
+	}
+	km.importKey = widget.NewEntry()
+	km.importKey.SetPlaceHolder("Key under the ZMK, e.g. X0123...")
+	km.importKey.Validator = func(s string) error {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			return nil
+		}
+
+		return utils.ValidateCryptogramForScheme(s, utils.CryptogramScheme(s))
+	}
+	km.importScheme = widget.NewSelect(KeySchemes, nil)
+	km.importScheme.SetSelected("U")
+
+	km.importedKey = widget.NewLabel("")
+	km.importedKey.TextStyle = fyne.TextStyle{Monospace: true}
+	km.importedKey.Wrapping = fyne.TextWrapBreak
+	km.importedKCV = widget.NewLabel("KCV: ")
+
+	form := widget.NewForm(
+		&widget.FormItem{Text: "Key Type", Widget: km.importType},
+		&widget.FormItem{Text: "ZMK", Widget: km.importZMK},
+		&widget.FormItem{Text: "Key under ZMK", Widget: km.importKey},
+		&widget.FormItem{Text: "LMK Scheme", Widget: km.importScheme},
+		&widget.FormItem{Text: "Key under LMK", Widget: container.NewBorder(
+			nil, nil, nil,
+			newCopyButton(func() string { return km.importedKey.Text }),
+			km.importedKey,
+		)},
+		&widget.FormItem{Text: "Check Value", Widget: container.NewBorder(
+			nil, nil, nil,
+			newCopyButton(kcvText(km.importedKCV)),
+			km.importedKCV,
+		)},
+	)
+	form.SubmitText = "Import in HSM"
+	form.OnSubmit = km.onImportKey
+
+	km.importSaveBtn = widget.NewButtonWithIcon("Save to store…", theme.DocumentSaveIcon(), func() {
+		if km.imported != nil {
+			km.saveToStore(*km.imported, km.importedType)
+		}
+	})
+	km.importSaveBtn.Disable()
+
+	return widget.NewCard("Import", "Translate a key received under a ZMK to the LMK",
+		container.NewVBox(form, container.NewHBox(km.importSaveBtn)),
+	)
+}
+
+// importRequest collects and validates the import fields. It returns the
+// chosen key type with the request.
+func (km *KeyManager) importRequest() (utils.A6Request, keyTypeInfo, error) {
+	keyType, err := km.importType.Selected()
+	if err != nil {
+		return utils.A6Request{}, keyTypeInfo{}, err
+	}
+	if km.importScheme.Selected == "" {
+		return utils.A6Request{}, keyTypeInfo{}, errors.New("select LMK scheme")
+	}
+	req := utils.A6Request{
+		KeyType: keyType.Code,
+		ZMK:     strings.TrimSpace(km.importZMK.Text),
+		Key:     strings.TrimSpace(km.importKey.Text),
+		Scheme:  km.importScheme.Selected[0],
+	}
+
+	return req, keyType, req.Validate()
+}
+
+// onImportKey imports the key under the ZMK with A6 and shows it under the
+// LMK.
+func (km *KeyManager) onImportKey() {
+	defer recoverHandler("key_import_hsm")
+
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if km.connection.GetState() != hsm.Connected {
+		dialog.ShowError(fmt.Errorf("hsm not connected - please connect first"), w)
+		return
+	}
+
+	req, keyType, err := km.importRequest()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	cmd, _ := req.Command()
+
+	km.clearImport()
+	resp, err := km.connection.ExecuteCommand([]byte(cmd), km.connection.CommandTimeout())
+	if err != nil {
+		km.response.clear()
+		logger.Error("key_import_hsm", "Failed", err.Error())
+		dialog.ShowError(err, w)
+
+		return
+	}
+
+	key, err := utils.ParseA6Response(resp, req.Scheme)
+	km.response.show(resp, key, err)
+	if err != nil {
+		logger.Error(
+			"key_import_hsm",
+			"Failed",
+			fmt.Sprintf("type=%s scheme=%c error=%v", req.KeyType, req.Scheme, err),
+		)
+		dialog.ShowError(fmt.Errorf("key import failed: %v", err), w)
+
+		return
+	}
+
+	logger.Info(
+		"key_import_hsm",
+		"Success",
+		fmt.Sprintf("type=%s scheme=%c kcv=%s", req.KeyType, req.Scheme, key.CheckValue),
+	)
+	km.importedKey.SetText(key.Cryptogram)
+	km.importedKCV.SetText("KCV: " + key.CheckValue)
+	km.imported = &key
+	km.importedType = storage.KeyType(keyType.Mnemonic)
+	if km.lmkCheckValue == "" {
+		km.lmkCheckValue = km.queryLMKCheckValue()
+	}
+	if km.store != nil {
+		km.importSaveBtn.Enable()
+	}
+}
+
+// clearImport clears the result of the last import.
+func (km *KeyManager) clearImport() {
+	km.importedKey.SetText("")
+	km.importedKCV.SetText("KCV: ")
+	km.imported = nil
+	km.importSaveBtn.Disable()
+}
@@ -0,0 +1,106 @@
+package tabs
+
+import (
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// LMK types the Key Manager can generate keys under.
+const (
+	lmkTypeVariant  = "Variant"
+	lmkTypeKeyBlock = "Key block"
+)
+
+// keyBlockOptions returns "code - description" options for codes, sorted.
+func keyBlockOptions[K string | byte](codes map[K]string) []string {
+	options := make([]string, 0, len(codes))
+	for code, desc := range codes {
+		options = append(options, string(code)+" - "+desc)
+	}
+	sort.Strings(options)
+
+	return options
+}
+
+// optionCode returns the code an option of keyBlockOptions starts with.
+func optionCode(option string) string {
+	code, _, _ := strings.Cut(option, " ")
+	return code
+}
+
+// initializeKeyBlock creates the key block fields, preset to a double length
+// triple DES key encryption key.
+func (km *KeyManager) initializeKeyBlock() {
+	km.lmkID = widget.NewEntry()
+	km.lmkID.SetPlaceHolder("Default LMK, or a 2-digit identifier")
+
+	km.kbUsage = widget.NewSelect(keyBlockOptions(utils.KeyUsages), nil)
+	km.kbUsage.SetSelectedIndex(indexOf(km.kbUsage.Options, "K0"))
+
+	algorithms := make([]string, 0, len(utils.KeyBlockAlgorithmCodes))
+	for code := range utils.KeyBlockAlgorithmCodes {
+		algorithms = append(algorithms, code)
+	}
+	sort.Strings(algorithms)
+	km.kbAlgorithm = widget.NewSelect(algorithms, nil)
+	km.kbAlgorithm.SetSelected("T2")
+
+	km.kbMode = widget.NewSelect(keyBlockOptions(utils.KeyBlockModes), nil)
+	km.kbMode.SetSelectedIndex(indexOf(km.kbMode.Options, "B"))
+
+	km.kbExport = widget.NewSelect(keyBlockOptions(utils.KeyBlockExportability), nil)
+	km.kbExport.SetSelectedIndex(indexOf(km.kbExport.Options, "E"))
+
+	km.kbHeader = widget.NewLabel("")
+	km.kbHeader.Wrapping = fyne.TextWrapWord
+	km.kbHeader.Hide()
+}
+
+// indexOf returns the index of the option with code in options.
+func indexOf(options []string, code string) int {
+	for i, option := range options {
+		if optionCode(option) == code {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// keyBlockParams collects the key block fields.
+func (km *KeyManager) keyBlockParams() utils.KeyBlockParams {
+	params := utils.KeyBlockParams{
+		LMKID:      strings.TrimSpace(km.lmkID.Text),
+		KeyUsage:   optionCode(km.kbUsage.Selected),
+		Algorithm:  km.kbAlgorithm.Selected,
+		KeyVersion: "00",
+	}
+	if mode := optionCode(km.kbMode.Selected); mode != "" {
+		params.ModeOfUse = mode[0]
+	}
+	if export := optionCode(km.kbExport.Selected); export != "" {
+		params.Exportability = export[0]
+	}
+
+	return params
+}
+
+// onLMKTypeChanged shows the fields of the selected LMK type.
+func (km *KeyManager) onLMKTypeChanged(lmkType string) {
+	if lmkType == lmkTypeKeyBlock {
+		km.variantForm.Hide()
+		km.keyBlockForm.Show()
+		km.exportCheck.SetChecked(false)
+		km.exportCheck.Disable()
+	} else {
+		km.keyBlockForm.Hide()
+		km.variantForm.Show()
+		km.exportCheck.Enable()
+	}
+	km.checkScheme()
+}
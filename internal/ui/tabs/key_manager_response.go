@@ -0,0 +1,86 @@
+package tabs
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// responseView shows the parsed fields of the last Key Manager response,
+// with a toggle revealing the raw response bytes.
+type responseView struct {
+	fields   *fyne.Container
+	shown    []utils.ResponseField
+	showRaw  *widget.Check
+	raw      *widget.Label
+	parseErr *widget.Label
+	card     *widget.Card
+}
+
+// newResponseView creates an empty response view.
+func newResponseView() *responseView {
+	v := &responseView{
+		fields:   container.New(layout.NewFormLayout()),
+		raw:      widget.NewLabel(""),
+		parseErr: widget.NewLabel(""),
+	}
+	v.raw.TextStyle = fyne.TextStyle{Monospace: true}
+	v.raw.Hide()
+	v.parseErr.Importance = widget.DangerImportance
+	v.parseErr.Wrapping = fyne.TextWrapWord
+	v.parseErr.Hide()
+	v.showRaw = widget.NewCheck("Show raw", func(checked bool) {
+		if checked {
+			v.raw.Show()
+		} else {
+			v.raw.Hide()
+		}
+	})
+
+	v.card = widget.NewCard("Last HSM Response", "", container.NewVBox(
+		v.fields,
+		v.parseErr,
+		v.showRaw,
+		v.raw,
+	))
+	v.card.Hide()
+
+	return v
+}
+
+// show renders resp parsed into key. A parse failure, as opposed to an error
+// reported by the HSM, reveals the raw bytes and the error.
+func (v *responseView) show(resp []byte, key utils.A0Response, err error) {
+	v.shown = key.Fields()
+	v.fields.RemoveAll()
+	for _, f := range v.shown {
+		name := widget.NewLabelWithStyle(f.Name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+		value := widget.NewLabel(f.Value)
+		value.Wrapping = fyne.TextWrapBreak
+		v.fields.Add(name)
+		v.fields.Add(value)
+	}
+	v.raw.SetText(utils.HexDump(resp))
+
+	hsmError := key.ErrorCode != "" && key.ErrorCode != "00"
+	if err != nil && !hsmError {
+		v.parseErr.SetText("Could not parse the response: " + err.Error())
+		v.parseErr.Show()
+		v.showRaw.SetChecked(true)
+	} else {
+		v.parseErr.Hide()
+	}
+	v.card.Show()
+}
+
+// clear hides the view.
+func (v *responseView) clear() {
+	v.shown = nil
+	v.fields.RemoveAll()
+	v.raw.SetText("")
+	v.parseErr.Hide()
+	v.card.Hide()
+}
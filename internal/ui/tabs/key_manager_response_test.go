@@ -0,0 +1,77 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+func TestResponseView_Show(t *testing.T) {
+	tests := []struct {
+		name         string
+		resp         string
+		parse        func([]byte) (utils.A0Response, error)
+		wantFields   []string
+		wantRaw      bool
+		wantParseErr bool
+	}{
+		{
+			"success",
+			"A100U0123456789ABCDEF0123456789ABCDEF08D7B4",
+			func(b []byte) (utils.A0Response, error) { return utils.ParseA0Response(b, 'U') },
+			[]string{"Response Code", "Error Code", "Key under LMK", "KCV"},
+			false,
+			false,
+		},
+		{
+			"zmk_parity",
+			"A710",
+			func(b []byte) (utils.A0Response, error) { return utils.ParseA6Response(b, 'U') },
+			[]string{"Response Code", "Error Code"},
+			false,
+			false,
+		},
+		{
+			"truncated",
+			"A100U01",
+			func(b []byte) (utils.A0Response, error) { return utils.ParseA0Response(b, 'U') },
+			[]string{"Response Code", "Error Code"},
+			true,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+
+			v := newResponseView()
+			key, err := tt.parse([]byte(tt.resp))
+			v.show([]byte(tt.resp), key, err)
+
+			var names []string
+			for _, f := range v.shown {
+				names = append(names, f.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantFields) {
+				t.Errorf("fields = %q, want %q", names, tt.wantFields)
+			}
+			if len(v.fields.Objects) != 2*len(tt.wantFields) {
+				t.Errorf("rendered %d objects, want %d", len(v.fields.Objects), 2*len(tt.wantFields))
+			}
+			if v.showRaw.Checked != tt.wantRaw || v.raw.Visible() != tt.wantRaw {
+				t.Errorf("raw shown = %v, want %v", v.showRaw.Checked, tt.wantRaw)
+			}
+			if v.parseErr.Visible() != tt.wantParseErr {
+				t.Errorf("parse error shown = %v, want %v", v.parseErr.Visible(), tt.wantParseErr)
+			}
+			if v.raw.Text != utils.HexDump([]byte(tt.resp)) {
+				t.Errorf("raw = %q, want the hex dump of the response", v.raw.Text)
+			}
+		})
+	}
+}
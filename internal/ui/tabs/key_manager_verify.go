@@ -0,0 +1,103 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// initializeVerify creates the button verifying the check value of the key
+// value with BU, enabled only while the HSM is connected.
+func (km *KeyManager) initializeVerify() {
+	km.verifyBtn = widget.NewButtonWithIcon("Verify KCV", theme.ConfirmIcon(), km.onVerifyKCV)
+	km.verifyResult = widget.NewLabel("")
+	km.verifyResult.Hide()
+
+	if km.connection == nil {
+		km.verifyBtn.Disable()
+		return
+	}
+	km.showVerifyState(km.connection.GetState())
+	km.connection.RegisterStateCallback(func(state hsm.ConnectionState, _ error) {
+		fyne.Do(func() { km.showVerifyState(state) })
+	})
+}
+
+// showVerifyState enables the verify button while the HSM is connected.
+func (km *KeyManager) showVerifyState(state hsm.ConnectionState) {
+	if state == hsm.Connected {
+		km.verifyBtn.Enable()
+	} else {
+		km.verifyBtn.Disable()
+	}
+}
+
+// onVerifyKCV asks the HSM for the check value of the key value and compares
+// it with the displayed one.
+func (km *KeyManager) onVerifyKCV() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	if km.lmkType.Selected == lmkTypeKeyBlock {
+		dialog.ShowError(errors.New("check values of key blocks cannot be verified with BU"), w)
+		return
+	}
+	keyType, err := km.keyType.Selected()
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+	cmd, err := utils.BuildBUCommand(keyType.Code, strings.TrimSpace(km.keyInput.Text))
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	km.verifyBtn.Disable()
+	go func() {
+		defer recoverHandler("key_verify_hsm")
+
+		resp, err := km.connection.ExecuteCommand([]byte(cmd), km.connection.CommandTimeout())
+		var kcv string
+		if err == nil {
+			kcv, err = utils.ParseBUResponse(resp)
+		}
+		fyne.Do(func() {
+			km.showVerifyState(km.connection.GetState())
+			if err != nil {
+				logger.Error("key_verify_hsm", "Failed", err.Error())
+				km.verifyResult.Hide()
+				dialog.ShowError(fmt.Errorf("check value verification failed: %v", err), w)
+
+				return
+			}
+			km.showVerifyResult(kcv, kcvText(km.kcv)())
+		})
+	}()
+}
+
+// showVerifyResult compares the check value reported by the HSM with the
+// displayed one, if any.
+func (km *KeyManager) showVerifyResult(hsmKCV, shown string) {
+	switch {
+	case shown == "":
+		km.verifyResult.SetText("HSM check value: " + hsmKCV)
+		km.verifyResult.Importance = widget.MediumImportance
+	case utils.CheckValuesMatch(hsmKCV, shown):
+		km.verifyResult.SetText("Match: HSM check value " + hsmKCV)
+		km.verifyResult.Importance = widget.SuccessImportance
+	default:
+		km.verifyResult.SetText("Mismatch: HSM check value " + hsmKCV)
+		km.verifyResult.Importance = widget.DangerImportance
+	}
+	logger.Info("key_verify_hsm", "Success", fmt.Sprintf("kcv=%s shown=%s", hsmKCV, shown))
+	km.verifyResult.Refresh()
+	km.verifyResult.Show()
+}
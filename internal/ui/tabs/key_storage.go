@@ -0,0 +1,441 @@
+package tabs
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// keyStoragePageSize is the number of entries shown per page.
+const keyStoragePageSize = 100
+
+// Choices of the type and tag filters that select every entry.
+const (
+	allKeyTypes = "All types"
+	allKeyTags  = "All tags"
+)
+
+// Choices of the status filter.
+const (
+	keyStatusActive = "Active"
+	keyStatusTrash  = "Trash"
+	keyStatusAll    = "All"
+)
+
+// keyStatusOptions are the choices of the status filter.
+var keyStatusOptions = []string{keyStatusActive, keyStatusTrash, keyStatusAll}
+
+// keyStorageColumn is a column of the key table.
+type keyStorageColumn struct {
+	Title    string
+	Sort     storage.KeySort
+	Sortable bool
+	Width    float32
+}
+
+// keyStorageColumns are the columns of the key table, in display order.
+var keyStorageColumns = []keyStorageColumn{
+	{"Name", storage.SortByName, true, 180},
+	{"Type", storage.SortByType, true, 80},
+	{"Length", storage.SortByLength, true, 80},
+	{"KCV", storage.SortByCheckValue, true, 90},
+	{"Created", storage.SortByCreated, true, 170},
+	{"Status", storage.SortByStatus, true, 90},
+	{"Tags", 0, false, 200},
+}
+
+// keyStorageView holds the filters, order and page of the key table,
+// independently of the widgets showing them.
+type keyStorageView struct {
+	Search     string
+	Type       string // allKeyTypes or a key type.
+	Tag        string // allKeyTags or a tag.
+	Status     string // One of keyStatusOptions.
+	Sort       storage.KeySort
+	Descending bool
+	Page       int // Zero-based.
+}
+
+// newKeyStorageView returns a view of the active entries by name.
+func newKeyStorageView() keyStorageView {
+	return keyStorageView{Type: allKeyTypes, Tag: allKeyTags, Status: keyStatusActive}
+}
+
+// filter returns the store query selecting the view's entries.
+func (v keyStorageView) filter() storage.KeyFilter {
+	f := storage.KeyFilter{
+		Query:      strings.TrimSpace(v.Search),
+		Sort:       v.Sort,
+		Descending: v.Descending,
+	}
+	if v.Type != allKeyTypes {
+		f.Type = storage.KeyType(v.Type)
+	}
+	if v.Tag != allKeyTags {
+		f.Tag = v.Tag
+	}
+	switch v.Status {
+	case keyStatusActive:
+		f.Status = storage.StatusActive
+	case keyStatusTrash:
+		f.Status = storage.StatusTrashed
+	}
+
+	return f
+}
+
+// sortBy orders the view by column col, reversing the order when it is
+// already sorted by it. Columns that cannot be sorted are ignored.
+func (v *keyStorageView) sortBy(col int) {
+	if col < 0 || col >= len(keyStorageColumns) || !keyStorageColumns[col].Sortable {
+		return
+	}
+	s := keyStorageColumns[col].Sort
+	if v.Sort == s {
+		v.Descending = !v.Descending
+	} else {
+		v.Sort = s
+		v.Descending = false
+	}
+	v.Page = 0
+}
+
+// header returns the title of column col, marked when the view is sorted
+// by it.
+func (v keyStorageView) header(col int) string {
+	c := keyStorageColumns[col]
+	switch {
+	case !c.Sortable || c.Sort != v.Sort:
+		return c.Title
+	case v.Descending:
+		return c.Title + " ▼"
+	default:
+		return c.Title + " ▲"
+	}
+}
+
+// load returns the entries on the view's page and the number of pages,
+// which is at least one. The page is moved back when the entries no longer
+// reach it.
+func (v *keyStorageView) load(store *storage.KeyStore) ([]storage.KeyEntry, int) {
+	f := v.filter()
+	if v.Page < 0 {
+		v.Page = 0
+	}
+	entries, total := store.ListPage(f, v.Page*keyStoragePageSize, keyStoragePageSize)
+	pages := max(1, (total+keyStoragePageSize-1)/keyStoragePageSize)
+	if v.Page >= pages {
+		v.Page = pages - 1
+		entries, _ = store.ListPage(f, v.Page*keyStoragePageSize, keyStoragePageSize)
+	}
+
+	return entries, pages
+}
+
+// keyStorageCell returns the text of column col for entry.
+func keyStorageCell(entry storage.KeyEntry, col int) string {
+	switch col {
+	case 0:
+		return entry.Name
+	case 1:
+		return string(entry.Type)
+	case 2:
+		if entry.Length == 0 {
+			return ""
+		}
+
+		return fmt.Sprintf("%d bytes", entry.Length)
+	case 3:
+		return entry.CheckValue
+	case 4:
+		return entry.CreatedAt.Local().Format(time.DateTime)
+	case 5:
+		return string(entry.Status())
+	case 6:
+		return strings.Join(entry.Tags, ", ")
+	}
+
+	return ""
+}
+
+// formatKeyEntry describes entry for the detail pane. The key value itself
+// is never shown.
+func formatKeyEntry(entry storage.KeyEntry) string {
+	value := "not stored"
+	if entry.Value != "" {
+		value = fmt.Sprintf("stored (%d characters)", len(entry.Value))
+	}
+	lines := []string{
+		"Name:        " + entry.Name,
+		"Type:        " + string(entry.Type),
+		"Length:      " + keyStorageCell(entry, 2),
+		"Check Value: " + entry.CheckValue,
+	}
+	if entry.LMKCheckValue != "" {
+		lines = append(lines, "LMK KCV:     "+entry.LMKCheckValue)
+	}
+	lines = append(lines,
+		"Created:     "+keyStorageCell(entry, 4),
+		"Status:      "+string(entry.Status()),
+	)
+	if entry.Status() == storage.StatusTrashed {
+		lines = append(lines, "Trashed:     "+entry.TrashedAt.Local().Format(time.DateTime))
+	}
+	lines = append(lines,
+		"Tags:        "+keyStorageCell(entry, 6),
+		"Value:       "+value,
+	)
+
+	return strings.Join(lines, "\n")
+}
+
+// KeyStorage represents the Key Storage tab, a browser of the key store.
+type KeyStorage struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	store   *storage.KeyStore // Nil when the key store is unavailable.
+	unwatch func()            // Unregisters the store change callback.
+
+	// Filters and paging.
+	view      keyStorageView
+	search    *widget.Entry
+	typeSel   *widget.Select
+	tagSel    *widget.Select
+	statusSel *widget.Select
+	pageLabel *widget.Label
+	prevPage  *widget.Button
+	nextPage  *widget.Button
+
+	// Key table and the entries on the current page.
+	table   *widget.Table
+	entries []storage.KeyEntry
+
+	// Toolbar actions that need a selection.
+	editBtn    *widget.Button
+	deleteBtn  *widget.Button
+	restoreBtn *widget.Button
+
+	// Detail pane of the selected entry.
+	detail   *widget.Label
+	selected string // Name of the selected entry, empty when none.
+}
+
+// NewKeyStorage creates a new Key Storage tab. store may be nil, in which
+// case the tab only says the key store is unavailable.
+func NewKeyStorage(store *storage.KeyStore) *KeyStorage {
+	st := &KeyStorage{store: store, view: newKeyStorageView()}
+	st.ExtendBaseWidget(st)
+
+	if store == nil {
+		st.container = container.NewCenter(widget.NewLabel("The key store is unavailable."))
+		return st
+	}
+
+	st.initializeFilters()
+	st.initializeTable()
+	st.detail = widget.NewLabel("Select a key to see its details.")
+	st.detail.TextStyle = fyne.TextStyle{Monospace: true}
+
+	split := container.NewVSplit(st.table, container.NewVScroll(st.detail))
+	split.SetOffset(0.7)
+	st.container = container.NewBorder(
+		container.NewVBox(st.initializeToolbar(), st.filterBar(), widget.NewSeparator()),
+		container.NewHBox(st.prevPage, st.pageLabel, st.nextPage),
+		nil, nil,
+		split,
+	)
+
+	st.unwatch = store.OnChange(func() { fyne.Do(st.refresh) })
+	st.refresh()
+
+	return st
+}
+
+// initializeFilters creates the search box, filters and pager.
+func (st *KeyStorage) initializeFilters() {
+	st.search = widget.NewEntry()
+	st.search.SetPlaceHolder("Search name, type, KCV or tag...")
+	st.search.OnChanged = func(text string) {
+		st.view.Search = text
+		st.view.Page = 0
+		st.refresh()
+	}
+
+	onFilter := func(field *string) func(string) {
+		return func(value string) {
+			if value == "" || *field == value {
+				return
+			}
+			*field = value
+			st.view.Page = 0
+			st.refresh()
+		}
+	}
+	st.typeSel = widget.NewSelect([]string{allKeyTypes}, onFilter(&st.view.Type))
+	st.typeSel.SetSelected(allKeyTypes)
+	st.tagSel = widget.NewSelect([]string{allKeyTags}, onFilter(&st.view.Tag))
+	st.tagSel.SetSelected(allKeyTags)
+	st.statusSel = widget.NewSelect(keyStatusOptions, onFilter(&st.view.Status))
+	st.statusSel.SetSelected(keyStatusActive)
+
+	st.pageLabel = widget.NewLabel("")
+	st.prevPage = widget.NewButton("Previous", func() {
+		st.view.Page--
+		st.refresh()
+	})
+	st.nextPage = widget.NewButton("Next", func() {
+		st.view.Page++
+		st.refresh()
+	})
+}
+
+// filterBar lays out the search box and filters.
+func (st *KeyStorage) filterBar() fyne.CanvasObject {
+	return container.NewBorder(nil, nil, nil,
+		container.NewHBox(st.typeSel, st.tagSel, st.statusSel),
+		st.search,
+	)
+}
+
+// initializeTable creates the key table with sortable column headers.
+func (st *KeyStorage) initializeTable() {
+	st.table = widget.NewTable(
+		func() (int, int) { return len(st.entries), len(keyStorageColumns) },
+		func() fyne.CanvasObject {
+			l := widget.NewLabel("Template")
+			l.Truncation = fyne.TextTruncateEllipsis
+
+			return l
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			if id.Row >= len(st.entries) {
+				return
+			}
+			obj.(*widget.Label).SetText(keyStorageCell(st.entries[id.Row], id.Col))
+		},
+	)
+	st.table.ShowHeaderRow = true
+	st.table.CreateHeader = func() fyne.CanvasObject {
+		return widget.NewButton("", nil)
+	}
+	st.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		if id.Col < 0 || id.Col >= len(keyStorageColumns) {
+			return
+		}
+		btn := obj.(*widget.Button)
+		btn.SetText(st.view.header(id.Col))
+		btn.OnTapped = func() {
+			st.view.sortBy(id.Col)
+			st.refresh()
+		}
+	}
+	for i, c := range keyStorageColumns {
+		st.table.SetColumnWidth(i, c.Width)
+	}
+	st.table.OnSelected = func(id widget.TableCellID) {
+		if id.Row >= 0 && id.Row < len(st.entries) {
+			st.selected = st.entries[id.Row].Name
+			st.showSelected()
+		}
+	}
+}
+
+// refresh reloads the entries and filter choices from the store, keeping
+// the selected entry selected while it is shown.
+func (st *KeyStorage) refresh() {
+	st.refreshChoices()
+
+	var pages int
+	st.entries, pages = st.view.load(st.store)
+	st.pageLabel.SetText(fmt.Sprintf("Page %d of %d", st.view.Page+1, pages))
+	setEnabled(st.prevPage, st.view.Page > 0)
+	setEnabled(st.nextPage, st.view.Page < pages-1)
+
+	st.table.UnselectAll()
+	st.table.Refresh()
+	for i, e := range st.entries {
+		if e.Name == st.selected {
+			st.table.Select(widget.TableCellID{Row: i})
+			return
+		}
+	}
+	st.selected = ""
+	st.showSelected()
+}
+
+// refreshChoices lists the stored types and tags in the filters. A chosen
+// type or tag stays available even when no entry has it any more.
+func (st *KeyStorage) refreshChoices() {
+	types := map[string]bool{}
+	for _, e := range st.store.List() {
+		if e.Type != "" {
+			types[string(e.Type)] = true
+		}
+	}
+	if st.view.Type != allKeyTypes {
+		types[st.view.Type] = true
+	}
+	typeOptions := make([]string, 0, len(types))
+	for t := range types {
+		typeOptions = append(typeOptions, t)
+	}
+	sort.Strings(typeOptions)
+	st.typeSel.SetOptions(append([]string{allKeyTypes}, typeOptions...))
+
+	tags := st.store.Tags()
+	if st.view.Tag != allKeyTags && !slices.Contains(tags, st.view.Tag) {
+		tags = append(tags, st.view.Tag)
+	}
+	st.tagSel.SetOptions(append([]string{allKeyTags}, tags...))
+}
+
+// showSelected shows the selected entry in the detail pane and enables the
+// actions that apply to it.
+func (st *KeyStorage) showSelected() {
+	entry, ok := st.selectedEntry()
+	if !ok {
+		st.detail.SetText("Select a key to see its details.")
+		st.editBtn.Disable()
+		st.deleteBtn.Disable()
+		st.restoreBtn.Disable()
+
+		return
+	}
+
+	st.detail.SetText(formatKeyEntry(entry))
+	trashed := entry.Status() == storage.StatusTrashed
+	setEnabled(st.editBtn, !trashed)
+	st.deleteBtn.Enable()
+	setEnabled(st.restoreBtn, trashed)
+}
+
+// selectedEntry returns the selected entry as currently stored.
+func (st *KeyStorage) selectedEntry() (storage.KeyEntry, bool) {
+	if st.selected == "" {
+		return storage.KeyEntry{}, false
+	}
+
+	return st.store.Get(st.selected)
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (st *KeyStorage) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(st.container)
+}
+
+// Cleanup implements TabContent interface.
+func (st *KeyStorage) Cleanup() {
+	if st.unwatch != nil {
+		st.unwatch()
+		st.unwatch = nil
+	}
+}
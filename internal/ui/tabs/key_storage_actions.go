@@ -0,0 +1,332 @@
+package tabs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// keyEntryForm holds the fields of the Add and Edit dialogs.
+type keyEntryForm struct {
+	Name, Type, Length, CheckValue, Tags, Value string
+}
+
+// apply returns base updated with the form fields. An empty value keeps
+// the value of base.
+func (f keyEntryForm) apply(base storage.KeyEntry) (storage.KeyEntry, error) {
+	name := strings.TrimSpace(f.Name)
+	if err := utils.ValidateKeyName(name); err != nil {
+		return storage.KeyEntry{}, err
+	}
+	length := 0
+	if s := strings.TrimSpace(f.Length); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return storage.KeyEntry{}, fmt.Errorf("length must be a positive number of bytes, got %q", s)
+		}
+		length = n
+	}
+
+	entry := base
+	entry.Name = name
+	entry.Type = storage.KeyType(strings.ToUpper(strings.TrimSpace(f.Type)))
+	entry.Length = length
+	entry.CheckValue = strings.ToUpper(strings.TrimSpace(f.CheckValue))
+	entry.Tags = parseTags(f.Tags)
+	if v := strings.TrimSpace(f.Value); v != "" {
+		entry.Value = strings.ToUpper(v)
+	}
+
+	return entry, nil
+}
+
+// parseTags splits comma separated tags, dropping blanks and repeats.
+func parseTags(s string) []string {
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		if t != "" && !containsFold(tags, t) {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// containsFold reports whether list holds s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// initializeToolbar creates the toolbar actions.
+func (st *KeyStorage) initializeToolbar() fyne.CanvasObject {
+	st.editBtn = widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), st.onEdit)
+	st.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), st.onDelete)
+	st.restoreBtn = widget.NewButtonWithIcon("Restore", theme.ContentUndoIcon(), st.onRestore)
+	st.editBtn.Disable()
+	st.deleteBtn.Disable()
+	st.restoreBtn.Disable()
+
+	return container.NewHBox(
+		widget.NewButtonWithIcon("Add", theme.ContentAddIcon(), st.onAdd),
+		st.editBtn,
+		st.deleteBtn,
+		st.restoreBtn,
+		widget.NewSeparator(),
+		widget.NewButtonWithIcon("Export…", theme.DocumentSaveIcon(), st.onExport),
+		widget.NewButtonWithIcon("Import…", theme.FolderOpenIcon(), st.onImport),
+	)
+}
+
+// onAdd stores a new entry, asking before an existing one is replaced.
+func (st *KeyStorage) onAdd() {
+	st.showEntryForm("Add Key", storage.KeyEntry{}, true)
+}
+
+// onEdit changes the selected entry. Its name cannot be changed.
+func (st *KeyStorage) onEdit() {
+	if entry, ok := st.selectedEntry(); ok {
+		st.showEntryForm("Edit Key", entry, false)
+	}
+}
+
+// showEntryForm asks for the fields of base and stores the result.
+func (st *KeyStorage) showEntryForm(title string, base storage.KeyEntry, isNew bool) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	name := widget.NewEntry()
+	name.SetText(base.Name)
+	name.Validator = utils.ValidateKeyName
+	if !isNew {
+		name.Disable()
+	}
+	keyType := widget.NewEntry()
+	keyType.SetText(string(base.Type))
+	keyType.SetPlaceHolder("e.g. ZMK")
+	length := widget.NewEntry()
+	if base.Length > 0 {
+		length.SetText(strconv.Itoa(base.Length))
+	}
+	length.SetPlaceHolder("Bytes")
+	kcv := widget.NewEntry()
+	kcv.SetText(base.CheckValue)
+	tags := widget.NewEntry()
+	tags.SetText(strings.Join(base.Tags, ", "))
+	tags.SetPlaceHolder("Comma separated")
+	value := widget.NewPasswordEntry()
+	if base.Value != "" {
+		value.SetPlaceHolder("Unchanged")
+	} else {
+		value.SetPlaceHolder("Optional key or cryptogram in hex")
+	}
+
+	dialog.ShowForm(title, "Save", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("Name", name),
+		widget.NewFormItem("Type", keyType),
+		widget.NewFormItem("Length", length),
+		widget.NewFormItem("Check Value", kcv),
+		widget.NewFormItem("Tags", tags),
+		widget.NewFormItem("Value", value),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		entry, err := keyEntryForm{
+			Name:       name.Text,
+			Type:       keyType.Text,
+			Length:     length.Text,
+			CheckValue: kcv.Text,
+			Tags:       tags.Text,
+			Value:      value.Text,
+		}.apply(base)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if _, exists := st.store.Get(entry.Name); isNew && exists {
+			dialog.ShowConfirm(
+				"Overwrite Key",
+				fmt.Sprintf("Key %q already exists. Overwrite it?", entry.Name),
+				func(overwrite bool) {
+					if overwrite {
+						st.storeEntry(entry)
+					}
+				},
+				w,
+			)
+
+			return
+		}
+		st.storeEntry(entry)
+	}, w)
+}
+
+// storeEntry stores entry and selects it.
+func (st *KeyStorage) storeEntry(entry storage.KeyEntry) {
+	st.selected = entry.Name
+	if err := st.store.Store(entry); err != nil {
+		logger.Error("key_store", "Failed", err.Error())
+		st.showError(fmt.Errorf("failed to store %s: %v", entry.Name, err))
+		return
+	}
+	logger.Info("key_store", "Success", fmt.Sprintf("name=%s type=%s kcv=%s", entry.Name, entry.Type, entry.CheckValue))
+}
+
+// onDelete moves the selected entry to the trash, or deletes it for good
+// when it is already there, after confirmation.
+func (st *KeyStorage) onDelete() {
+	entry, ok := st.selectedEntry()
+	if !ok {
+		return
+	}
+
+	title, msg := "Delete Key", fmt.Sprintf("Move key %q to the trash?", entry.Name)
+	remove, event := st.store.Trash, "key_trash"
+	if entry.Status() == storage.StatusTrashed {
+		title = "Delete Key Permanently"
+		msg = fmt.Sprintf("Permanently delete key %q? This cannot be undone.", entry.Name)
+		remove, event = st.store.Delete, "key_delete"
+	}
+	dialog.ShowConfirm(title, msg, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := remove(entry.Name); err != nil {
+			logger.Error(event, "Failed", err.Error())
+			st.showError(err)
+			return
+		}
+		logger.Info(event, "Success", "name="+entry.Name)
+	}, fyne.CurrentApp().Driver().AllWindows()[0])
+}
+
+// onRestore takes the selected entry out of the trash.
+func (st *KeyStorage) onRestore() {
+	entry, ok := st.selectedEntry()
+	if !ok {
+		return
+	}
+	if err := st.store.Restore(entry.Name); err != nil {
+		logger.Error("key_restore", "Failed", err.Error())
+		st.showError(err)
+		return
+	}
+	logger.Info("key_restore", "Success", "name="+entry.Name)
+}
+
+// onExport writes the entries matching the filters, on every page, to a
+// JSON file. Key values are only included when asked for.
+func (st *KeyStorage) onExport() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	entries := st.store.ListFiltered(st.view.filter())
+	if len(entries) == 0 {
+		dialog.ShowInformation("Export Keys", "No keys match the filters.", w)
+		return
+	}
+
+	withValues := widget.NewCheck("Include key values", nil)
+	dialog.ShowForm("Export Keys", "Export", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel(fmt.Sprintf("%d keys match the filters.", len(entries)))),
+		widget.NewFormItem("", withValues),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+			if wc == nil {
+				return // Cancelled.
+			}
+			defer wc.Close()
+
+			if err := storage.ExportKeys(wc, entries, withValues.Checked); err != nil {
+				dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+				return
+			}
+			logger.Info("key_export", "Success", fmt.Sprintf("%d keys to %s", len(entries), wc.URI().Name()))
+		}, w)
+		save.SetFileName("hsmtool-keys.json")
+		save.Show()
+	}, w)
+}
+
+// onImport reads exported entries and stores them after showing which
+// existing entries they replace.
+func (st *KeyStorage) onImport() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return // Cancelled.
+		}
+		defer rc.Close()
+
+		file := rc.URI().Name()
+		entries, err := storage.ParseKeys(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load %s: %v", file, err), w)
+			return
+		}
+		if len(entries) == 0 {
+			dialog.ShowInformation("Import Keys", "The file holds no keys.", w)
+			return
+		}
+
+		msg := fmt.Sprintf("Import %d keys?", len(entries))
+		if replaced := st.existing(entries); len(replaced) > 0 {
+			msg += fmt.Sprintf("\n\nThese keys will be overwritten: %s", strings.Join(replaced, ", "))
+		}
+		dialog.ShowConfirm("Import Keys", msg, func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := st.store.StoreAll(entries); err != nil {
+				logger.Error("key_import", "Failed", err.Error())
+				dialog.ShowError(fmt.Errorf("import failed, nothing was changed: %v", err), w)
+				return
+			}
+			logger.Info("key_import", "Success", fmt.Sprintf("%d keys from %s", len(entries), file))
+		}, w)
+	}, w)
+}
+
+// existing returns the sorted names of entries already in the store.
+func (st *KeyStorage) existing(entries []storage.KeyEntry) []string {
+	var names []string
+	for _, e := range entries {
+		if _, ok := st.store.Get(e.Name); ok {
+			names = append(names, e.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (st *KeyStorage) showError(err error) {
+	dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
+}
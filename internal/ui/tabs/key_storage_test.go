@@ -0,0 +1,238 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+func newTestStore(t *testing.T) *storage.KeyStore {
+	t.Helper()
+	store, err := storage.NewKeyStore(filepath.Join(t.TempDir(), "keys.json"))
+	if err != nil {
+		t.Fatalf("NewKeyStore() error = %v", err)
+	}
+
+	return store
+}
+
+func TestKeyStorageView_Filter(t *testing.T) {
+	tests := []struct {
+		name string
+		view keyStorageView
+		want storage.KeyFilter
+	}{
+		{
+			"defaults",
+			newKeyStorageView(),
+			storage.KeyFilter{Status: storage.StatusActive},
+		},
+		{
+			"search_type_tag",
+			keyStorageView{Search: "  zmk ", Type: "ZMK", Tag: "prod", Status: keyStatusActive},
+			storage.KeyFilter{Query: "zmk", Type: storage.ZMK, Tag: "prod", Status: storage.StatusActive},
+		},
+		{
+			"trash",
+			keyStorageView{Type: allKeyTypes, Tag: allKeyTags, Status: keyStatusTrash},
+			storage.KeyFilter{Status: storage.StatusTrashed},
+		},
+		{
+			"all_statuses_sorted",
+			keyStorageView{Type: allKeyTypes, Tag: allKeyTags, Status: keyStatusAll, Sort: storage.SortByCreated, Descending: true},
+			storage.KeyFilter{Sort: storage.SortByCreated, Descending: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.view.filter(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyStorageView_SortBy(t *testing.T) {
+	v := newKeyStorageView()
+	v.Page = 3
+
+	v.sortBy(2) // Length.
+	if v.Sort != storage.SortByLength || v.Descending || v.Page != 0 {
+		t.Errorf("first sortBy(2) = %+v, want length ascending on page 0", v)
+	}
+	if got := v.header(2); got != "Length ▲" {
+		t.Errorf("header(2) = %q", got)
+	}
+
+	v.sortBy(2)
+	if v.Sort != storage.SortByLength || !v.Descending {
+		t.Errorf("second sortBy(2) = %+v, want length descending", v)
+	}
+	if got := v.header(2); got != "Length ▼" {
+		t.Errorf("header(2) = %q", got)
+	}
+	if got := v.header(0); got != "Name" {
+		t.Errorf("header(0) = %q, want it unmarked", got)
+	}
+
+	v.sortBy(6) // Tags cannot be sorted.
+	v.sortBy(99)
+	if v.Sort != storage.SortByLength || !v.Descending {
+		t.Errorf("sortBy() of an unsortable column changed the order: %+v", v)
+	}
+
+	v.sortBy(4)
+	if v.Sort != storage.SortByCreated || v.Descending {
+		t.Errorf("sortBy(4) = %+v, want created ascending", v)
+	}
+}
+
+func TestKeyStorageView_Load(t *testing.T) {
+	store := newTestStore(t)
+	entries := make([]storage.KeyEntry, keyStoragePageSize+5)
+	for i := range entries {
+		entries[i] = storage.KeyEntry{Name: fmt.Sprintf("key%03d", i), Type: storage.ZPK}
+	}
+	entries[0].Type = storage.ZMK
+	entries[1].TrashedAt = time.Now()
+	if err := store.StoreAll(entries); err != nil {
+		t.Fatalf("StoreAll() error = %v", err)
+	}
+
+	v := newKeyStorageView()
+	got, pages := v.load(store)
+	if pages != 2 || len(got) != keyStoragePageSize || got[0].Name != "key000" {
+		t.Errorf("page 1: %d entries of %d pages, first %s", len(got), pages, got[0].Name)
+	}
+
+	v.Page = 1
+	got, _ = v.load(store)
+	if len(got) != 4 || got[3].Name != "key104" {
+		t.Errorf("page 2: %d entries, want the last 4", len(got))
+	}
+
+	// A narrower filter moves back to its last page.
+	v.Type = string(storage.ZMK)
+	got, pages = v.load(store)
+	if v.Page != 0 || pages != 1 || len(got) != 1 || got[0].Name != "key000" {
+		t.Errorf("ZMK filter: page %d of %d, %d entries", v.Page, pages, len(got))
+	}
+
+	v = newKeyStorageView()
+	v.Status = keyStatusTrash
+	if got, _ = v.load(store); len(got) != 1 || got[0].Name != "key001" {
+		t.Errorf("trash = %v, want key001", got)
+	}
+}
+
+func TestKeyEntryForm_Apply(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	base := storage.KeyEntry{Name: "zmk", Value: "U0123", CreatedAt: created, LMKCheckValue: "7B44AC"}
+
+	tests := []struct {
+		name    string
+		form    keyEntryForm
+		want    storage.KeyEntry
+		wantErr string
+	}{
+		{
+			"edit_keeps_value",
+			keyEntryForm{Name: "zmk", Type: " zmk ", Length: "16", CheckValue: "08d7b4", Tags: "prod, visa,, Prod"},
+			storage.KeyEntry{
+				Name: "zmk", Type: storage.ZMK, Length: 16, CheckValue: "08D7B4", CreatedAt: created,
+				Value: "U0123", LMKCheckValue: "7B44AC", Tags: []string{"prod", "visa"},
+			},
+			"",
+		},
+		{
+			"new_value",
+			keyEntryForm{Name: "zmk", Value: " u4567 "},
+			storage.KeyEntry{Name: "zmk", CreatedAt: created, Value: "U4567", LMKCheckValue: "7B44AC"},
+			"",
+		},
+		{"bad_name", keyEntryForm{Name: "a b"}, storage.KeyEntry{}, "key name"},
+		{"bad_length", keyEntryForm{Name: "zmk", Length: "-1"}, storage.KeyEntry{}, "positive"},
+		{"length_not_number", keyEntryForm{Name: "zmk", Length: "sixteen"}, storage.KeyEntry{}, "positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.form.apply(base)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("apply() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("apply() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatKeyEntry(t *testing.T) {
+	entry := storage.KeyEntry{
+		Name: "zmk", Type: storage.ZMK, Length: 16, CheckValue: "08D7B4",
+		Value: "0123456789ABCDEF0123456789ABCDEF", Tags: []string{"prod"},
+		CreatedAt: time.Now(), TrashedAt: time.Now(),
+	}
+
+	got := formatKeyEntry(entry)
+	for _, want := range []string{"Name:        zmk", "Length:      16 bytes", "Status:      Trashed", "Trashed:", "Tags:        prod", "stored (32 characters)"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatKeyEntry() = %q, missing %q", got, want)
+		}
+	}
+	if strings.Contains(got, entry.Value) {
+		t.Error("formatKeyEntry() shows the key value")
+	}
+}
+
+func TestKeyStorage_RefreshOnChange(t *testing.T) {
+	test.NewTempApp(t)
+	store := newTestStore(t)
+	st := NewKeyStorage(store)
+	defer st.Cleanup()
+
+	if err := store.Store(storage.KeyEntry{Name: "zmk", Type: storage.ZMK, Tags: []string{"prod"}}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if len(st.entries) != 1 || !reflect.DeepEqual(st.tagSel.Options, []string{allKeyTags, "prod"}) {
+		t.Fatalf("after Store() entries = %v, tags = %v", st.entries, st.tagSel.Options)
+	}
+
+	st.table.Select(widget.TableCellID{Row: 0})
+	if st.selected != "zmk" || st.deleteBtn.Disabled() || !st.restoreBtn.Disabled() {
+		t.Errorf("selected %q, delete disabled %v", st.selected, st.deleteBtn.Disabled())
+	}
+
+	// Trashed entries leave the active view and the selection.
+	store.Trash("zmk")
+	if len(st.entries) != 0 || st.selected != "" || !st.deleteBtn.Disabled() {
+		t.Errorf("after Trash() entries = %v, selected %q", st.entries, st.selected)
+	}
+
+	st.statusSel.SetSelected(keyStatusTrash)
+	if len(st.entries) != 1 {
+		t.Errorf("trash view entries = %v", st.entries)
+	}
+
+	st.search.SetText("nothing")
+	if len(st.entries) != 0 {
+		t.Errorf("search entries = %v", st.entries)
+	}
+}
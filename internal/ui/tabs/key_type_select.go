@@ -0,0 +1,253 @@
+package tabs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// keyTypeInfo is a KeyTypes entry split into its parts.
+type keyTypeInfo struct {
+	Code        string // A0/A6 key type code, e.g. "109".
+	Mnemonic    string // e.g. "MK-AC".
+	Description string
+	Label       string // The KeyTypes entry itself.
+
+	// AllowedSchemes lists the Variant LMK schemes A0 can generate the key
+	// type in. It is empty for key types A0 cannot generate.
+	AllowedSchemes []string
+}
+
+// Key scheme groups for the catalogue constraints.
+var (
+	allSchemes        = KeySchemes
+	multiLengthScheme = []string{"U", "T", "X", "Y"}
+	zmkSchemes        = []string{"U", "T"}
+)
+
+// allowedSchemes returns the schemes A0 can generate a key type in: none for
+// RSA and HMAC keys, which have their own commands, double or triple length
+// for ZMKs, derivation and EMV master keys, and any scheme otherwise.
+func allowedSchemes(code, mnemonic string) []string {
+	switch {
+	case code == "00C" || code == "00D" || code == "10C":
+		return []string{}
+	case code == "000" || code == "100":
+		return zmkSchemes
+	case strings.HasPrefix(mnemonic, "BDK"), strings.HasPrefix(mnemonic, "MK-"),
+		mnemonic == "IKEY", mnemonic == "ZKA MK":
+		return multiLengthScheme
+	default:
+		return allSchemes
+	}
+}
+
+// allows reports whether A0 can generate the key type in scheme.
+func (k keyTypeInfo) allows(scheme string) bool {
+	return slices.Contains(k.AllowedSchemes, scheme)
+}
+
+// schemeCheck explains why a key of type k cannot be generated in scheme, or
+// returns nil when it can.
+func schemeCheck(k keyTypeInfo, scheme string) error {
+	switch {
+	case len(k.AllowedSchemes) == 0:
+		return fmt.Errorf("%s keys cannot be generated with A0", k.Mnemonic)
+	case scheme == "" || k.allows(scheme):
+		return nil
+	default:
+		return fmt.Errorf(
+			"%s keys cannot use scheme %s - use %s",
+			k.Mnemonic,
+			scheme,
+			strings.Join(k.AllowedSchemes, ", "),
+		)
+	}
+}
+
+// schemeOptions returns the scheme options for k, marking the schemes k does
+// not allow.
+func schemeOptions(k keyTypeInfo) []string {
+	options := make([]string, len(KeySchemes))
+	for i, scheme := range KeySchemes {
+		options[i] = scheme
+		if !k.allows(scheme) {
+			options[i] += notAllowedSuffix
+		}
+	}
+
+	return options
+}
+
+// notAllowedSuffix marks scheme options the selected key type does not allow.
+const notAllowedSuffix = " (not allowed)"
+
+// keyTypeCatalogue holds KeyTypes in structured form.
+var keyTypeCatalogue = parseKeyTypes(KeyTypes)
+
+// parseKeyTypes splits "code mnemonic - description" entries.
+func parseKeyTypes(labels []string) []keyTypeInfo {
+	infos := make([]keyTypeInfo, 0, len(labels))
+	for _, label := range labels {
+		code, rest, _ := strings.Cut(label, " ")
+		mnemonic, desc, _ := strings.Cut(rest, " - ")
+		mnemonic = strings.TrimSpace(mnemonic)
+		infos = append(infos, keyTypeInfo{
+			Code:           code,
+			Mnemonic:       mnemonic,
+			Description:    strings.TrimRight(desc, ")"),
+			Label:          label,
+			AllowedSchemes: allowedSchemes(code, mnemonic),
+		})
+	}
+
+	return infos
+}
+
+// matches reports whether every word of query occurs in the code, mnemonic
+// or description of k, ignoring case.
+func (k keyTypeInfo) matches(query string) bool {
+	text := strings.ToLower(k.Code + " " + k.Mnemonic + " " + k.Description)
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// filterKeyTypes returns the labels of the key types matching query, in
+// catalogue order. An empty query matches all of them.
+func filterKeyTypes(query string) []string {
+	var labels []string
+	for _, k := range keyTypeCatalogue {
+		if k.matches(query) {
+			labels = append(labels, k.Label)
+		}
+	}
+
+	return labels
+}
+
+// resolveKeyType returns the key type text stands for: an exact label, an
+// exact code or mnemonic, or a query matching a single key type.
+func resolveKeyType(text string) (keyTypeInfo, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return keyTypeInfo{}, fmt.Errorf("select key type")
+	}
+
+	var exact, matched []keyTypeInfo
+	for _, k := range keyTypeCatalogue {
+		if k.Label == text {
+			return k, nil
+		}
+		if strings.EqualFold(k.Code, text) || strings.EqualFold(k.Mnemonic, text) {
+			exact = append(exact, k)
+		}
+		if k.matches(text) {
+			matched = append(matched, k)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return exact[0], nil
+	case len(matched) == 1:
+		return matched[0], nil
+	case len(matched) == 0:
+		return keyTypeInfo{}, fmt.Errorf("no key type matches %q", text)
+	default:
+		return keyTypeInfo{}, fmt.Errorf("%q matches %d key types", text, len(matched))
+	}
+}
+
+// keyTypeSelect is a searchable key type selector. Typing filters the
+// dropdown on code, mnemonic and description, keeping the last chosen key
+// type at the top; Down opens the dropdown and Escape restores the choice.
+type keyTypeSelect struct {
+	widget.SelectEntry
+
+	chosen string // label of the last key type the text resolved to
+
+	// OnResolved, if set, is called after each edit with the key type the
+	// text resolves to, or the resolution error.
+	OnResolved func(keyTypeInfo, error)
+}
+
+// newKeyTypeSelect creates an empty key type selector.
+func newKeyTypeSelect() *keyTypeSelect {
+	s := &keyTypeSelect{}
+	s.ExtendBaseWidget(s)
+	s.SetOptions(KeyTypes)
+	s.SetPlaceHolder("Search code, mnemonic or description")
+	s.Validator = func(text string) error {
+		if text == "" {
+			return nil
+		}
+		_, err := resolveKeyType(text)
+
+		return err
+	}
+	s.OnChanged = s.filter
+
+	return s
+}
+
+// filter narrows the options to the key types matching text.
+func (s *keyTypeSelect) filter(text string) {
+	k, err := resolveKeyType(text)
+	if err == nil {
+		s.chosen = k.Label
+	}
+	s.SetOptions(s.matching(text))
+	if s.OnResolved != nil {
+		s.OnResolved(k, err)
+	}
+}
+
+// matching returns the options for text: all key types while text is the
+// chosen one, otherwise the matching key types after the chosen one.
+func (s *keyTypeSelect) matching(text string) []string {
+	if text == s.chosen {
+		return KeyTypes
+	}
+
+	options := filterKeyTypes(text)
+	if s.chosen == "" {
+		return options
+	}
+	kept := []string{s.chosen}
+	for _, label := range options {
+		if label != s.chosen {
+			kept = append(kept, label)
+		}
+	}
+
+	return kept
+}
+
+// TypedKey opens the dropdown on Down and restores the chosen key type on
+// Escape.
+func (s *keyTypeSelect) TypedKey(key *fyne.KeyEvent) {
+	switch key.Name {
+	case fyne.KeyDown:
+		if btn, ok := s.ActionItem.(*widget.Button); ok && !s.Disabled() {
+			btn.OnTapped()
+		}
+	case fyne.KeyEscape:
+		if s.chosen != "" {
+			s.SetText(s.chosen)
+		}
+	default:
+		s.SelectEntry.TypedKey(key)
+	}
+}
+
+// Selected returns the key type the text resolves to.
+func (s *keyTypeSelect) Selected() (keyTypeInfo, error) {
+	return resolveKeyType(s.Text)
+}
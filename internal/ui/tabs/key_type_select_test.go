@@ -0,0 +1,181 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestParseKeyTypes(t *testing.T) {
+	got := parseKeyTypes([]string{"109 MK-AC - Master Key for Application Cryptograms)"})
+	want := []keyTypeInfo{{
+		Code:        "109",
+		Mnemonic:    "MK-AC",
+		Description: "Master Key for Application Cryptograms",
+		Label:       "109 MK-AC - Master Key for Application Cryptograms)",
+		// MK- keys have no single length scheme.
+		AllowedSchemes: []string{"U", "T", "X", "Y"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeyTypes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFilterKeyTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"code", "109", []string{"109 MK-AC - Master Key for Application Cryptograms)"}},
+		{"mnemonic_any_case", "mk-ac", []string{"109 MK-AC - Master Key for Application Cryptograms)"}},
+		{"words_in_description", "application cryptograms", []string{
+			"109 MK-AC - Master Key for Application Cryptograms)",
+		}},
+		{"no_match", "no such key", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := filterKeyTypes(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterKeyTypes(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+
+	if got := filterKeyTypes(""); len(got) != len(KeyTypes) {
+		t.Errorf("filterKeyTypes(\"\") returned %d key types, want %d", len(got), len(KeyTypes))
+	}
+	if got := filterKeyTypes("zpk"); len(got) == 0 || got[0] != "001 ZPK - Zone PIN Key)" {
+		t.Errorf("filterKeyTypes(\"zpk\") = %q, want ZPK first", got)
+	}
+}
+
+func TestResolveKeyType(t *testing.T) {
+	tests := []struct {
+		text     string
+		wantCode string
+		wantErr  bool
+	}{
+		{"109 MK-AC - Master Key for Application Cryptograms)", "109", false},
+		{"mk-ac", "109", false},
+		{"ZPK", "001", false},
+		{"dynamic numbers", "509", false},
+		{"", "", true},
+		{"no such key", "", true},
+		{"002", "", true}, // several key types share the code.
+	}
+
+	for _, tt := range tests {
+		got, err := resolveKeyType(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveKeyType(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if got.Code != tt.wantCode {
+			t.Errorf("resolveKeyType(%q) code = %q, want %q", tt.text, got.Code, tt.wantCode)
+		}
+	}
+}
+
+func TestKeyTypeSelect_RoundTrip(t *testing.T) {
+	test.NewTempApp(t)
+
+	s := newKeyTypeSelect()
+	w := test.NewWindow(s)
+	defer w.Close()
+
+	test.Type(s, "mk-ac")
+	got, err := s.Selected()
+	if err != nil || got.Code != "109" {
+		t.Fatalf("Selected() = %+v, %v, want code 109", got, err)
+	}
+
+	// Choosing an option sets its label as the text.
+	s.SetText(KeyTypes[0])
+	if got, err := s.Selected(); err != nil || got.Label != KeyTypes[0] {
+		t.Fatalf("Selected() = %+v, %v, want %q", got, err, KeyTypes[0])
+	}
+
+	// Editing keeps the choice at the top of the filtered options.
+	s.SetText("zone")
+	if err := s.Validate(); err == nil {
+		t.Error("Validate() of an ambiguous query expected error")
+	}
+	if _, err := s.Selected(); err == nil {
+		t.Error("Selected() of an ambiguous query expected error")
+	}
+	if options := s.matching(s.Text); len(options) < 2 || options[0] != KeyTypes[0] {
+		t.Errorf("options = %q, want the chosen key type first", options)
+	}
+
+	s.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	if s.Text != KeyTypes[0] {
+		t.Errorf("Text after Escape = %q, want %q", s.Text, KeyTypes[0])
+	}
+}
+
+func TestAllowedSchemes(t *testing.T) {
+	tests := []struct {
+		label string
+		want  []string
+	}{
+		{"000 ZMK - Zone Master Key (also known as ZCMK)", []string{"U", "T"}},
+		{"009 BDK-1 - Base Derivation Key (type 1)", []string{"U", "T", "X", "Y"}},
+		{"109 MK-AC - Master Key for Application Cryptograms)", []string{"U", "T", "X", "Y"}},
+		{"001 ZPK - Zone PIN Key)", KeySchemes},
+		{"00C RSA-SK - RSA Private Key)", []string{}},
+		{"00D RSA-PK - RSA Public Key)", []string{}},
+	}
+
+	for _, tt := range tests {
+		k, err := resolveKeyType(tt.label)
+		if err != nil {
+			t.Fatalf("resolveKeyType(%q) error = %v", tt.label, err)
+		}
+		if !reflect.DeepEqual(k.AllowedSchemes, tt.want) {
+			t.Errorf("%s AllowedSchemes = %q, want %q", k.Mnemonic, k.AllowedSchemes, tt.want)
+		}
+	}
+}
+
+func TestKeyManager_SchemeGating(t *testing.T) {
+	test.NewTempApp(t)
+
+	km := NewKeyManager(nil, nil)
+	for _, k := range keyTypeCatalogue {
+		km.keyType.SetText(k.Label)
+		if !reflect.DeepEqual(km.keyScheme.Options, schemeOptions(k)) {
+			t.Errorf("%s scheme options = %q, want %q", k.Label, km.keyScheme.Options, schemeOptions(k))
+		}
+
+		for i, scheme := range KeySchemes {
+			km.keyScheme.SetSelected(km.keyScheme.Options[i])
+
+			allowed := k.allows(scheme)
+			if (schemeCheck(k, scheme) == nil) != allowed {
+				t.Errorf("%s scheme %s: schemeCheck() = %v, want allowed %v", k.Label, scheme, schemeCheck(k, scheme), allowed)
+			}
+			if km.genBtn.Disabled() == allowed {
+				t.Errorf("%s scheme %s: Generate disabled = %v, want %v", k.Label, scheme, km.genBtn.Disabled(), !allowed)
+			}
+			if km.schemeErr.Visible() == allowed {
+				t.Errorf("%s scheme %s: warning shown = %v, want %v", k.Label, scheme, km.schemeErr.Visible(), !allowed)
+			}
+			if strings.HasSuffix(km.keyScheme.Options[i], notAllowedSuffix) == allowed {
+				t.Errorf("%s scheme option %q marked wrongly", k.Label, km.keyScheme.Options[i])
+			}
+		}
+	}
+
+	// Key block LMKs are not gated.
+	km.keyType.SetText("00C RSA-SK - RSA Private Key)")
+	km.lmkType.SetSelected(lmkTypeKeyBlock)
+	if km.genBtn.Disabled() {
+		t.Error("Generate disabled for a key block LMK")
+	}
+}
@@ -1,11 +1,52 @@
 package tabs
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// logDateLayout is the date format accepted by the range filter.
+const logDateLayout = "2006-01-02"
+
+const (
+	// followRefreshInterval throttles table refreshes while following.
+	followRefreshInterval = 250 * time.Millisecond
+	// maxFollowEntries bounds the table while following; the oldest rows
+	// are dropped first.
+	maxFollowEntries = 5000
 )
 
+// logLevels are the choices of the level filter, least severe first.
+var logLevels = []string{
+	logger.DEBUG.String(), logger.INFO.String(), logger.WARN.String(), logger.ERROR.String(),
+}
+
+// logFilter selects the entries shown in the Logs/Audit tab.
+type logFilter struct {
+	Start, End time.Time // Zero leaves a side open.
+	Search     string
+	Module     string // Any module when empty.
+	Levels     logger.LevelFilter
+}
+
+// matches reports whether e passes the filter.
+func (f logFilter) matches(e logger.Entry) bool {
+	if f.Module != "" && !strings.EqualFold(e.Module, f.Module) {
+		return false
+	}
+
+	return f.Levels.Allows(e.Module, e.Level) && e.Matches(f.Start, f.End, f.Search)
+}
+
 // LogsAudit represents the Logs/Audit tab.
 type LogsAudit struct {
 	widget.BaseWidget
@@ -15,14 +56,34 @@ type LogsAudit struct {
 	startDate  *widget.Entry
 	endDate    *widget.Entry
 	searchTerm *widget.Entry
+	module     *widget.Entry
+	level      *widget.Select // Least severe level shown.
+
+	// levels filters entries by level, honouring per-module minimums.
+	levels logger.LevelFilter
 
 	// Log table.
 	logsTable *widget.Table
+	entries   []logger.Entry
+
+	// Live tail of new entries.
+	log      *logger.Logger // Nil when logging is disabled.
+	filter   logFilter      // Filter of the shown entries.
+	follow   *widget.Check
+	unfollow func()        // Unregisters the follow callback.
+	every    time.Duration // Refresh interval while following.
+	pendMu   sync.Mutex
+	pending  []logger.Entry // Received but not yet shown.
+
+	// Detail pane of the selected entry.
+	detail   *widget.Label
+	copyJSON *widget.Button
+	selected *logger.Entry // Nil when no entry is selected.
 }
 
 // NewLogsAudit creates a new Logs/Audit tab.
 func NewLogsAudit() *LogsAudit {
-	la := &LogsAudit{}
+	la := &LogsAudit{log: logger.Default(), every: followRefreshInterval}
 	la.ExtendBaseWidget(la)
 
 	// Initialize filter fields.
@@ -35,7 +96,21 @@ func NewLogsAudit() *LogsAudit {
 	la.searchTerm = widget.NewEntry()
 	la.searchTerm.SetPlaceHolder("Search logs...")
 
+	la.module = widget.NewEntry()
+	la.module.SetPlaceHolder("Module (e.g. hsm)...")
+
+	la.levels = logger.LevelFilter{Min: logger.DEBUG}
+	la.filter = logFilter{Levels: la.levels}
+	la.level = widget.NewSelect(logLevels, nil)
+	la.level.SetSelected(logger.DEBUG.String())
+	la.level.OnChanged = la.onLevelSelected
+
 	filterBtn := widget.NewButton("Apply Filters", la.onApplyFilters)
+	la.follow = widget.NewCheck("Follow", la.onFollowToggled)
+	if la.log == nil {
+		la.follow.Disable()
+	}
+	exportBtn := widget.NewButton("Export…", la.onExport)
 
 	// Create filters form.
 	filters := container.NewHBox(
@@ -47,17 +122,25 @@ func NewLogsAudit() *LogsAudit {
 		container.NewVBox(
 			widget.NewLabel("Search"),
 			la.searchTerm,
+			la.module,
 			filterBtn,
 		),
+		container.NewVBox(
+			widget.NewLabel("Level"),
+			la.level,
+			la.follow,
+			exportBtn,
+		),
 	)
 
-	// Initialize logs table.
+	// Initialize logs table and the detail pane under it.
 	la.initializeTable()
+	split := container.NewVSplit(la.logsTable, la.initializeDetail())
+	split.SetOffset(0.7)
 
-	la.container = container.NewVBox(
-		filters,
-		widget.NewSeparator(),
-		la.logsTable,
+	la.container = container.NewBorder(
+		container.NewVBox(filters, widget.NewSeparator()), nil, nil, nil,
+		split,
 	)
 
 	return la
@@ -65,18 +148,219 @@ func NewLogsAudit() *LogsAudit {
 
 func (la *LogsAudit) initializeTable() {
 	la.logsTable = widget.NewTable(
-		func() (int, int) { return 0, 3 }, // Initial size (Timestamp, Event, Status).
+		func() (int, int) { return len(la.entries), 4 }, // Timestamp, Module, Event, Status.
 		func() fyne.CanvasObject { // Template object.
 			return widget.NewLabel("Template")
 		},
-		func(_ widget.TableCellID, _ fyne.CanvasObject) { // Renamed both parameters to '_' to avoid unused parameter errors.
-			// Will populate log data here.
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			if id.Row >= len(la.entries) {
+				return
+			}
+			e := la.entries[id.Row]
+			label := obj.(*widget.Label)
+			switch id.Col {
+			case 0:
+				label.SetText(e.Timestamp.Format(time.DateTime))
+			case 1:
+				label.SetText(e.Module)
+			case 2:
+				label.SetText(e.Event)
+			case 3:
+				label.SetText(e.Status)
+			}
 		},
 	)
 }
 
 func (la *LogsAudit) onApplyFilters() {
-	// TODO: Implement log filtering logic.
+	if la.log == nil {
+		return
+	}
+
+	start, err := parseLogDate(la.startDate.Text, 0)
+	if err != nil {
+		la.showError(err)
+		return
+	}
+	end, err := parseLogDate(la.endDate.Text, 24*time.Hour-time.Nanosecond)
+	if err != nil {
+		la.showError(err)
+		return
+	}
+	f := logFilter{
+		Start:  start,
+		End:    end,
+		Search: la.searchTerm.Text,
+		Module: strings.TrimSpace(la.module.Text),
+		Levels: la.levels,
+	}
+
+	entries, err := la.log.GetEntries(f.Start, f.End, f.Search)
+	if err != nil {
+		la.showError(err)
+		return
+	}
+
+	filtered := entries[:0]
+	for _, e := range entries {
+		if f.matches(e) {
+			filtered = append(filtered, e)
+		}
+	}
+
+	la.filter = f
+	la.entries = filtered
+	la.logsTable.Refresh()
+	la.reselect()
+	if la.follow.Checked {
+		la.logsTable.ScrollToBottom()
+	}
+}
+
+// onLevelSelected sets the least severe level shown and reapplies the
+// filters.
+func (la *LogsAudit) onLevelSelected(level string) {
+	min, err := logger.ParseLevel(level)
+	if err != nil {
+		return
+	}
+	la.levels.Min = min
+	la.filter.Levels = la.levels
+	la.onApplyFilters()
+}
+
+// onFollowToggled starts or stops appending new entries as they are
+// logged.
+func (la *LogsAudit) onFollowToggled(on bool) {
+	if !on {
+		la.stopFollowing()
+		return
+	}
+	if la.log == nil || la.unfollow != nil {
+		return
+	}
+
+	_, unregister := la.log.AddCallback(func(e logger.Entry) {
+		la.pendMu.Lock()
+		la.pending = append(la.pending, e)
+		la.pendMu.Unlock()
+	})
+	stop := make(chan struct{})
+	la.unfollow = func() {
+		unregister()
+		close(stop)
+	}
+
+	// Refresh a few times per second rather than once per entry.
+	go func() {
+		ticker := time.NewTicker(la.every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(la.flushPending)
+			}
+		}
+	}()
+	la.logsTable.ScrollToBottom()
+}
+
+// stopFollowing unregisters the follow callback and drops entries not yet
+// shown.
+func (la *LogsAudit) stopFollowing() {
+	if la.unfollow != nil {
+		la.unfollow()
+		la.unfollow = nil
+	}
+
+	la.pendMu.Lock()
+	la.pending = nil
+	la.pendMu.Unlock()
+}
+
+// flushPending shows the entries received since the last refresh that pass
+// the filter and scrolls to the newest.
+func (la *LogsAudit) flushPending() {
+	la.pendMu.Lock()
+	pending := la.pending
+	la.pending = nil
+	la.pendMu.Unlock()
+	if la.unfollow == nil {
+		return
+	}
+
+	added := false
+	for _, e := range pending {
+		if la.filter.matches(e) {
+			la.entries = append(la.entries, e)
+			added = true
+		}
+	}
+	if !added {
+		return
+	}
+	if n := len(la.entries) - maxFollowEntries; n > 0 {
+		la.entries = append(la.entries[:0:0], la.entries[n:]...)
+	}
+	la.logsTable.Refresh()
+	la.reselect()
+	la.logsTable.ScrollToBottom()
+}
+
+// parseLogDate parses an optional YYYY-MM-DD date, adding offset to the
+// start of that day.
+func parseLogDate(text string, offset time.Duration) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.ParseInLocation(logDateLayout, text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+	}
+
+	return t.Add(offset), nil
+}
+
+// onExport writes the shown entries to a CSV file, masked as the log file
+// is.
+func (la *LogsAudit) onExport() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	entries := la.entries
+	if len(entries) == 0 {
+		dialog.ShowInformation("Export Logs", "No entries match the filters.", w)
+		return
+	}
+
+	var mask func(logger.Entry) logger.Entry
+	if la.log != nil {
+		mask = la.log.Mask
+	}
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if err := logger.WriteCSV(wc, entries, mask); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+			return
+		}
+		logger.Info("logs_export", "Success", fmt.Sprintf("%d entries to %s", len(entries), wc.URI().Name()))
+	}, w)
+	save.SetFileName("hsmtool-logs.csv")
+	save.Show()
+}
+
+func (la *LogsAudit) showError(err error) {
+	dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 }
 
 // CreateRenderer implements fyne.Widget interface.
@@ -86,5 +370,6 @@ func (la *LogsAudit) CreateRenderer() fyne.WidgetRenderer {
 
 // Cleanup implements TabContent interface.
 func (la *LogsAudit) Cleanup() {
-	// No sensitive data to clean in logs tab.
+	la.follow.SetChecked(false)
+	la.stopFollowing()
 }
@@ -0,0 +1,170 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+func TestLogFilter_Matches(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.Local)
+	entry := logger.Entry{
+		Timestamp: at,
+		Level:     logger.WARN,
+		Module:    "hsm",
+		Event:     "hsm_reconnect",
+		Status:    "Started",
+		Fields:    map[string]string{"endpoint": "10.0.0.5:1500"},
+	}
+
+	tests := []struct {
+		name   string
+		filter logFilter
+		want   bool
+	}{
+		{"no_filter", logFilter{}, true},
+		{"level_below", logFilter{Levels: logger.LevelFilter{Min: logger.INFO}}, true},
+		{"level_equal", logFilter{Levels: logger.LevelFilter{Min: logger.WARN}}, true},
+		{"level_above", logFilter{Levels: logger.LevelFilter{Min: logger.ERROR}}, false},
+		{
+			"module_override",
+			logFilter{Levels: logger.LevelFilter{Min: logger.ERROR, Modules: map[string]logger.Level{"hsm": logger.DEBUG}}},
+			true,
+		},
+		{"module_match", logFilter{Module: "HSM"}, true},
+		{"module_other", logFilter{Module: "keygen"}, false},
+		{"search_event", logFilter{Search: "Reconnect"}, true},
+		{"search_field", logFilter{Search: "10.0.0.5"}, true},
+		{"search_miss", logFilter{Search: "A0"}, false},
+		{"in_range", logFilter{Start: at.Add(-time.Hour), End: at.Add(time.Hour)}, true},
+		{"before_range", logFilter{Start: at.Add(time.Minute)}, false},
+		{"after_range", logFilter{End: at.Add(-time.Minute)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(entry); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// waitPending waits until la holds n entries not yet shown.
+func waitPending(t *testing.T, la *LogsAudit, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		la.pendMu.Lock()
+		got := len(la.pending)
+		la.pendMu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("pending entries did not reach %d", n)
+}
+
+func TestLogsAudit_Follow(t *testing.T) {
+	test.NewTempApp(t)
+	l, err := logger.NewLogger(filepath.Join(t.TempDir(), "app.log"), logger.DEBUG, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	la := NewLogsAudit()
+	la.log = l
+	la.every = time.Hour // Flushed by the test.
+	la.follow.Enable()
+
+	la.follow.SetChecked(true)
+	if la.unfollow == nil {
+		t.Fatal("following did not register a callback")
+	}
+	l.Info("test_run", "Started", "")
+	waitPending(t, la, 1)
+	la.flushPending()
+	if len(la.entries) != 1 || la.entries[0].Event != "test_run" {
+		t.Fatalf("entries = %+v, want the logged entry", la.entries)
+	}
+
+	// The level filter applies to the history and the live stream.
+	la.level.SetSelected("WARN")
+	if len(la.entries) != 0 {
+		t.Fatalf("entries after WARN filter = %+v, want none", la.entries)
+	}
+	l.Info("test_run", "Progress", "")
+	l.Warn("hsm_reconnect", "Started", "")
+	waitPending(t, la, 2)
+	la.flushPending()
+	if len(la.entries) != 1 || la.entries[0].Level != logger.WARN {
+		t.Fatalf("entries = %+v, want only the warning", la.entries)
+	}
+
+	la.Cleanup()
+	if la.unfollow != nil || la.follow.Checked {
+		t.Fatal("Cleanup() left following on")
+	}
+	l.Error("test_run", "Failed", "")
+	time.Sleep(20 * time.Millisecond)
+	la.pendMu.Lock()
+	pending := len(la.pending)
+	la.pendMu.Unlock()
+	if pending != 0 {
+		t.Errorf("entries received after Cleanup(): %d", pending)
+	}
+}
+
+func TestFormatLogEntry(t *testing.T) {
+	at := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry logger.Entry
+		want  string
+	}{
+		{
+			"minimal",
+			logger.Entry{Timestamp: at, Level: logger.INFO, Event: "app_start", Status: "Success"},
+			"Timestamp: 2026-10-16T12:00:00Z\n" +
+				"Level:     INFO\n" +
+				"Event:     app_start\n" +
+				"Status:    Success",
+		},
+		{
+			"details_and_fields",
+			logger.Entry{
+				Timestamp: at,
+				Level:     logger.WARN,
+				Module:    "hsm",
+				Event:     "hsm_reconnect",
+				Status:    "Started",
+				Details:   "attempt 2\nbackoff 4s",
+				Fields:    map[string]string{"endpoint": "10.0.0.5:1500", "try": "2"},
+			},
+			"Timestamp: 2026-10-16T12:00:00Z\n" +
+				"Level:     WARN\n" +
+				"Module:    hsm\n" +
+				"Event:     hsm_reconnect\n" +
+				"Status:    Started\n" +
+				"\nDetails:\nattempt 2\nbackoff 4s\n" +
+				"\nFields:\n" +
+				"  endpoint = 10.0.0.5:1500\n" +
+				"  try      = 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatLogEntry(tt.entry); got != tt.want {
+				t.Errorf("formatLogEntry() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package tabs
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// noLogSelection is shown in the detail pane when no entry is selected.
+const noLogSelection = "Select an entry to see its details."
+
+// formatLogEntry renders e for the detail pane: one labelled line per
+// attribute, the details in full and the fields as a key/value list.
+func formatLogEntry(e logger.Entry) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			b.WriteString(label + value + "\n")
+		}
+	}
+	line("Timestamp: ", e.Timestamp.Format(time.RFC3339Nano))
+	line("Level:     ", e.Level.String())
+	line("Module:    ", e.Module)
+	line("Event:     ", e.Event)
+	line("Status:    ", e.Status)
+
+	if e.Details != "" {
+		b.WriteString("\nDetails:\n" + e.Details + "\n")
+	}
+
+	if len(e.Fields) > 0 {
+		keys := make([]string, 0, len(e.Fields))
+		width := 0
+		for k := range e.Fields {
+			keys = append(keys, k)
+			width = max(width, len(k))
+		}
+		sort.Strings(keys)
+
+		b.WriteString("\nFields:\n")
+		for _, k := range keys {
+			b.WriteString("  " + k + strings.Repeat(" ", width-len(k)) + " = " + e.Fields[k] + "\n")
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// initializeDetail creates the pane showing the selected entry.
+func (la *LogsAudit) initializeDetail() fyne.CanvasObject {
+	la.detail = widget.NewLabel(noLogSelection)
+	la.detail.TextStyle = fyne.TextStyle{Monospace: true}
+	la.detail.Wrapping = fyne.TextWrapWord
+
+	la.copyJSON = widget.NewButtonWithIcon("Copy JSON", theme.ContentCopyIcon(), nil)
+	la.copyJSON.OnTapped = func() {
+		if la.selected == nil {
+			return
+		}
+		if data, err := json.Marshal(la.selected); err == nil {
+			copyWithFeedback(la.copyJSON, string(data))
+		}
+	}
+	la.copyJSON.Disable()
+
+	la.logsTable.OnSelected = la.onEntrySelected
+	la.logsTable.OnUnselected = func(widget.TableCellID) { la.showDetail(nil) }
+
+	return container.NewBorder(nil, container.NewHBox(la.copyJSON), nil, nil,
+		container.NewVScroll(la.detail))
+}
+
+// onEntrySelected shows the entry of the selected row.
+func (la *LogsAudit) onEntrySelected(id widget.TableCellID) {
+	if id.Row < 0 || id.Row >= len(la.entries) {
+		la.showDetail(nil)
+		return
+	}
+	e := la.entries[id.Row]
+	la.showDetail(&e)
+}
+
+// showDetail shows e in the detail pane, or clears it when e is nil.
+func (la *LogsAudit) showDetail(e *logger.Entry) {
+	la.selected = e
+	if e == nil {
+		la.detail.SetText(noLogSelection)
+		la.copyJSON.Disable()
+		return
+	}
+	la.detail.SetText(formatLogEntry(*e))
+	la.copyJSON.Enable()
+}
+
+// reselect selects the row of the previously selected entry after the
+// entries changed, matching on timestamp and event. The selection is
+// cleared when the entry is no longer shown.
+func (la *LogsAudit) reselect() {
+	if la.selected == nil {
+		return
+	}
+
+	want := *la.selected
+	for i := len(la.entries) - 1; i >= 0; i-- {
+		e := la.entries[i]
+		if e.Timestamp.Equal(want.Timestamp) && e.Event == want.Event {
+			la.logsTable.Select(widget.TableCellID{Row: i})
+			return
+		}
+	}
+	la.logsTable.UnselectAll()
+	la.showDetail(nil)
+}
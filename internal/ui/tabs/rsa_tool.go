@@ -0,0 +1,493 @@
+package tabs
+
+import (
+	gorsa "crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/rsa"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// Choices of the RSA tab selects. Schemes and paddings are in the order of
+// the rsa package constants.
+var (
+	rsaKeySizes      = []string{"2048", "3072", "4096"}
+	rsaSignSchemes   = []string{"Raw", "PKCS#1 v1.5 (SHA-256)", "PSS (SHA-256)"}
+	rsaPaddings      = []string{"OAEP (SHA-256)", "PKCS#1 v1.5"}
+	rsaKeyEncodings  = []string{"PEM", "DER"}
+	rsaResultFormats = []string{string(utils.DataHex), string(utils.DataBase64)}
+)
+
+// rsaStatus shows err on label, or ok as a success when err is nil.
+func rsaStatus(label *widget.Label, err error, ok string) {
+	if err != nil {
+		label.Importance = widget.DangerImportance
+		label.SetText(err.Error())
+	} else {
+		label.Importance = widget.SuccessImportance
+		label.SetText(ok)
+	}
+	label.Show()
+}
+
+// rsaDecode reads the field name in format.
+func rsaDecode(name, text, format string) ([]byte, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("%s is required", name)
+	}
+	data, err := utils.DecodeData(text, utils.DataFormat(format))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not valid %s: %v", name, format, err)
+	}
+
+	return data, nil
+}
+
+// RSATool represents the RSA tab.
+type RSATool struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	// Loaded key; priv is nil when only a public key is loaded.
+	priv *gorsa.PrivateKey
+	pub  *gorsa.PublicKey
+
+	// Key pane.
+	keySize     *widget.Select
+	generateBtn *widget.Button
+	keyInfo     *widget.Label
+	fingerprint *widget.Label
+	publicPEM   *widget.Label
+	privatePEM  *widget.Label // Shown only while revealed.
+	reveal      *widget.Check
+	encoding    *widget.Select // Encoding of saved keys.
+	keyStatus   *widget.Label
+
+	// Sign and verify pane.
+	scheme     *widget.Select
+	message    *widget.Entry
+	messageFmt *widget.Select
+	signature  *widget.Entry
+	sigFmt     *widget.Select
+	signStatus *widget.Label
+
+	// Encrypt and decrypt pane.
+	padding     *widget.Select
+	plaintext   *widget.Entry
+	plainFmt    *widget.Select
+	ciphertext  *widget.Entry
+	cipherFmt   *widget.Select
+	cryptStatus *widget.Label
+}
+
+// NewRSATool creates a new RSA tab.
+func NewRSATool() *RSATool {
+	t := &RSATool{}
+	t.ExtendBaseWidget(t)
+
+	newStatus := func() *widget.Label {
+		l := widget.NewLabel("")
+		l.Wrapping = fyne.TextWrapWord
+		l.Hide()
+
+		return l
+	}
+	newSelect := func(options []string) *widget.Select {
+		s := widget.NewSelect(options, nil)
+		s.SetSelectedIndex(0)
+
+		return s
+	}
+	newData := func(placeholder string) *widget.Entry {
+		e := widget.NewMultiLineEntry()
+		e.SetPlaceHolder(placeholder)
+		e.Wrapping = fyne.TextWrapBreak
+
+		return e
+	}
+	mono := func() *widget.Label {
+		l := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		l.Wrapping = fyne.TextWrapBreak
+
+		return l
+	}
+
+	t.keySize = newSelect(rsaKeySizes)
+	t.generateBtn = widget.NewButton("Generate", t.onGenerate)
+	t.keyInfo = widget.NewLabel("No key loaded")
+	t.fingerprint = mono()
+	t.publicPEM = mono()
+	t.privatePEM = mono()
+	t.privatePEM.Hide()
+	t.reveal = widget.NewCheck("Reveal private key", t.onRevealToggled)
+	t.encoding = newSelect(rsaKeyEncodings)
+	t.keyStatus = newStatus()
+
+	t.scheme = newSelect(rsaSignSchemes)
+	t.message = newData("Data to sign")
+	t.messageFmt = widget.NewSelect(utils.DataFormats, nil)
+	t.messageFmt.SetSelected(string(utils.DataHex))
+	t.signature = newData("Signature, generated or pasted to verify")
+	t.sigFmt = newSelect(rsaResultFormats)
+	t.signStatus = newStatus()
+
+	t.padding = newSelect(rsaPaddings)
+	t.plaintext = newData("Plaintext, or the decrypted result")
+	t.plainFmt = widget.NewSelect(utils.DataFormats, nil)
+	t.plainFmt.SetSelected(string(utils.DataHex))
+	t.ciphertext = newData("Ciphertext, or the encrypted result")
+	t.cipherFmt = newSelect(rsaResultFormats)
+	t.cryptStatus = newStatus()
+
+	withFormat := func(e *widget.Entry, format *widget.Select) fyne.CanvasObject {
+		return container.NewBorder(nil, nil, nil,
+			container.NewVBox(format, newCopyButton(func() string { return e.Text })), e)
+	}
+
+	t.container = container.NewVBox(
+		widget.NewCard("Key", "", container.NewVBox(
+			container.NewHBox(
+				widget.NewLabel("Size"), t.keySize, t.generateBtn,
+				widget.NewButton("Load from File…", t.onLoad),
+			),
+			t.keyInfo,
+			widget.NewForm(
+				widget.NewFormItem("SHA-256 Fingerprint", container.NewBorder(nil, nil, nil,
+					newCopyButton(func() string { return t.fingerprint.Text }), t.fingerprint)),
+				widget.NewFormItem("Public Key", t.publicPEM),
+			),
+			t.reveal,
+			t.privatePEM,
+			container.NewHBox(
+				t.encoding,
+				widget.NewButton("Save Public Key…", func() { t.onSave(false) }),
+				widget.NewButton("Save Private Key…", func() { t.onSave(true) }),
+			),
+			t.keyStatus,
+		)),
+		widget.NewCard("Sign / Verify", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Scheme", t.scheme),
+				widget.NewFormItem("Data", withFormat(t.message, t.messageFmt)),
+				widget.NewFormItem("Signature", withFormat(t.signature, t.sigFmt)),
+			),
+			container.NewHBox(
+				widget.NewButton("Sign", t.onSign),
+				widget.NewButton("Verify", t.onVerify),
+			),
+			t.signStatus,
+		)),
+		widget.NewCard("Encrypt / Decrypt", "", container.NewVBox(
+			widget.NewForm(
+				widget.NewFormItem("Padding", t.padding),
+				widget.NewFormItem("Plaintext", withFormat(t.plaintext, t.plainFmt)),
+				widget.NewFormItem("Ciphertext", withFormat(t.ciphertext, t.cipherFmt)),
+			),
+			container.NewHBox(
+				widget.NewButton("Encrypt", t.onEncrypt),
+				widget.NewButton("Decrypt", t.onDecrypt),
+			),
+			t.cryptStatus,
+		)),
+	)
+
+	return t
+}
+
+// onGenerate generates a key pair of the selected size in the background.
+func (t *RSATool) onGenerate() {
+	bits, _ := strconv.Atoi(t.keySize.Selected)
+	t.generateBtn.Disable()
+	t.keyInfo.SetText(fmt.Sprintf("Generating a %d-bit key pair…", bits))
+
+	go func() {
+		key, err := rsa.Generate(bits)
+		fyne.Do(func() {
+			t.generateBtn.Enable()
+			if err != nil {
+				logger.Error("rsa_generate", "Failed", err.Error())
+				t.setKey(t.priv, t.pub)
+				rsaStatus(t.keyStatus, err, "")
+				return
+			}
+			t.setKey(key, &key.PublicKey)
+			logger.Info("rsa_generate", "Success", fmt.Sprintf("%d bits, fingerprint %s", bits, t.fingerprint.Text))
+		})
+	}()
+}
+
+// onLoad loads a private or public key from a PEM or DER file.
+func (t *RSATool) onLoad() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return // Cancelled.
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read %s: %v", rc.URI().Name(), err), w)
+			return
+		}
+		if err := t.loadKey(data); err != nil {
+			rsaStatus(t.keyStatus, fmt.Errorf("failed to load %s: %v", rc.URI().Name(), err), "")
+			return
+		}
+		logger.Info("rsa_load", "Success", rc.URI().Name())
+	}, w)
+}
+
+// loadKey loads a private key from data, or a public key when data holds
+// no private key.
+func (t *RSATool) loadKey(data []byte) error {
+	if priv, err := rsa.ParsePrivateKey(data); err == nil {
+		t.setKey(priv, &priv.PublicKey)
+		return nil
+	}
+	pub, err := rsa.ParsePublicKey(data)
+	if err != nil {
+		return errors.New("not an RSA private or public key")
+	}
+	t.setKey(nil, pub)
+
+	return nil
+}
+
+// setKey replaces the loaded key, wiping the previous private key, and shows
+// the new one.
+func (t *RSATool) setKey(priv *gorsa.PrivateKey, pub *gorsa.PublicKey) {
+	if t.priv != nil && t.priv != priv {
+		rsa.Wipe(t.priv)
+	}
+	t.priv, t.pub = priv, pub
+	t.keyStatus.Hide()
+
+	t.fingerprint.SetText("")
+	t.publicPEM.SetText("")
+	switch {
+	case pub == nil:
+		t.keyInfo.SetText("No key loaded")
+	case priv == nil:
+		t.keyInfo.SetText(fmt.Sprintf("%d-bit public key", pub.N.BitLen()))
+	default:
+		t.keyInfo.SetText(fmt.Sprintf("%d-bit key pair", pub.N.BitLen()))
+	}
+	if pub != nil {
+		fp, err := rsa.Fingerprint(pub)
+		if err != nil {
+			rsaStatus(t.keyStatus, err, "")
+		}
+		t.fingerprint.SetText(fp)
+		if pem, err := rsa.MarshalPublicKey(pub, rsa.PEM); err == nil {
+			t.publicPEM.SetText(string(pem))
+		}
+	}
+	t.showPrivate()
+}
+
+// onRevealToggled shows or hides the private key.
+func (t *RSATool) onRevealToggled(bool) {
+	t.showPrivate()
+}
+
+// showPrivate shows the private key in PEM while revealed.
+func (t *RSATool) showPrivate() {
+	t.privatePEM.SetText("")
+	t.privatePEM.Hide()
+	if t.priv == nil || !t.reveal.Checked {
+		return
+	}
+	if pem, err := rsa.MarshalPrivateKey(t.priv, rsa.PEM); err == nil {
+		t.privatePEM.SetText(string(pem))
+		t.privatePEM.Show()
+	}
+}
+
+// onSave writes the public or private key to a file in the selected
+// encoding. The private key is written in clear after confirmation.
+func (t *RSATool) onSave(private bool) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	enc := rsa.Encoding(t.encoding.SelectedIndex())
+
+	var (
+		data []byte
+		err  error
+		name = "rsa-public"
+	)
+	switch {
+	case t.pub == nil:
+		err = errors.New("generate or load a key first")
+	case private && t.priv == nil:
+		err = errors.New("only a public key is loaded")
+	case private:
+		data, err = rsa.MarshalPrivateKey(t.priv, enc)
+		name = "rsa-private"
+	default:
+		data, err = rsa.MarshalPublicKey(t.pub, enc)
+	}
+	if err != nil {
+		rsaStatus(t.keyStatus, err, "")
+		return
+	}
+
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		defer clear(data)
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if _, err := wc.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+			return
+		}
+		logger.Info("rsa_save", "Success", wc.URI().Name())
+	}, w)
+	if enc == rsa.DER {
+		save.SetFileName(name + ".der")
+	} else {
+		save.SetFileName(name + ".pem")
+	}
+	if !private {
+		save.Show()
+		return
+	}
+	dialog.ShowConfirm("Save Private Key",
+		"The private key is written unencrypted. Use only test keys. Continue?",
+		func(ok bool) {
+			if !ok {
+				clear(data)
+				return
+			}
+			save.Show()
+		}, w)
+}
+
+// onSign signs the data with the private key.
+func (t *RSATool) onSign() {
+	if t.priv == nil {
+		rsaStatus(t.signStatus, errors.New("signing needs a private key"), "")
+		return
+	}
+	data, err := rsaDecode("data", t.message.Text, t.messageFmt.Selected)
+	if err != nil {
+		rsaStatus(t.signStatus, err, "")
+		return
+	}
+
+	sig, err := rsa.Sign(t.priv, rsa.SignScheme(t.scheme.SelectedIndex()), data)
+	if err != nil {
+		rsaStatus(t.signStatus, err, "")
+		return
+	}
+	text, _ := utils.EncodeData(sig, utils.DataFormat(t.sigFmt.Selected))
+	t.signature.SetText(text)
+	rsaStatus(t.signStatus, nil, fmt.Sprintf("Signed with %s", t.scheme.Selected))
+}
+
+// onVerify checks the signature against the data with the public key.
+func (t *RSATool) onVerify() {
+	if t.pub == nil {
+		rsaStatus(t.signStatus, errors.New("generate or load a key first"), "")
+		return
+	}
+	data, err := rsaDecode("data", t.message.Text, t.messageFmt.Selected)
+	if err != nil {
+		rsaStatus(t.signStatus, err, "")
+		return
+	}
+	sig, err := rsaDecode("signature", t.signature.Text, t.sigFmt.Selected)
+	if err != nil {
+		rsaStatus(t.signStatus, err, "")
+		return
+	}
+
+	err = rsa.Verify(t.pub, rsa.SignScheme(t.scheme.SelectedIndex()), data, sig)
+	rsaStatus(t.signStatus, err, "Signature is valid")
+}
+
+// onEncrypt encrypts the plaintext with the public key.
+func (t *RSATool) onEncrypt() {
+	if t.pub == nil {
+		rsaStatus(t.cryptStatus, errors.New("generate or load a key first"), "")
+		return
+	}
+	data, err := rsaDecode("plaintext", t.plaintext.Text, t.plainFmt.Selected)
+	if err != nil {
+		rsaStatus(t.cryptStatus, err, "")
+		return
+	}
+
+	out, err := rsa.Encrypt(t.pub, rsa.CryptPadding(t.padding.SelectedIndex()), data)
+	if err != nil {
+		rsaStatus(t.cryptStatus, err, "")
+		return
+	}
+	text, _ := utils.EncodeData(out, utils.DataFormat(t.cipherFmt.Selected))
+	t.ciphertext.SetText(text)
+	rsaStatus(t.cryptStatus, nil, fmt.Sprintf("Encrypted %d bytes", len(data)))
+}
+
+// onDecrypt decrypts the ciphertext with the private key.
+func (t *RSATool) onDecrypt() {
+	if t.priv == nil {
+		rsaStatus(t.cryptStatus, errors.New("decryption needs a private key"), "")
+		return
+	}
+	data, err := rsaDecode("ciphertext", t.ciphertext.Text, t.cipherFmt.Selected)
+	if err != nil {
+		rsaStatus(t.cryptStatus, err, "")
+		return
+	}
+
+	out, err := rsa.Decrypt(t.priv, rsa.CryptPadding(t.padding.SelectedIndex()), data)
+	if err != nil {
+		rsaStatus(t.cryptStatus, err, "")
+		return
+	}
+	text, err := utils.EncodeData(out, utils.DataFormat(t.plainFmt.Selected))
+	clear(out)
+	if err != nil {
+		rsaStatus(t.cryptStatus, err, "")
+		return
+	}
+	t.plaintext.SetText(text)
+	rsaStatus(t.cryptStatus, nil, "Decrypted")
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (t *RSATool) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(t.container)
+}
+
+// Cleanup implements TabContent interface.
+func (t *RSATool) Cleanup() {
+	t.reveal.SetChecked(false)
+	t.setKey(nil, nil)
+	t.message.SetText("")
+	t.signature.SetText("")
+	t.plaintext.SetText("")
+	t.ciphertext.SetText("")
+	t.signStatus.Hide()
+	t.cryptStatus.Hide()
+}
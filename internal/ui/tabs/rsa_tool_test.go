@@ -0,0 +1,123 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/rsa"
+)
+
+func TestRSADecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{"hex", "01 23", "Hex", "\x01\x23", ""},
+		{"base64", "SFNN", "Base64", "HSM", ""},
+		{"ascii", "HSM", "ASCII", "HSM", ""},
+		{"empty", "", "Hex", "", "data is required"},
+		{"blank_base64", "  ", "Base64", "", "data is required"},
+		{"invalid", "XY", "Hex", "", "data is not valid Hex"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rsaDecode("data", tt.text, tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("rsaDecode() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || string(got) != tt.want {
+				t.Errorf("rsaDecode() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRSATool_KeyFlow(t *testing.T) {
+	test.NewTempApp(t)
+	key, err := rsa.Generate(2048)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	privPEM, _ := rsa.MarshalPrivateKey(key, rsa.PEM)
+	pubPEM, _ := rsa.MarshalPublicKey(&key.PublicKey, rsa.PEM)
+
+	r := NewRSATool()
+	if err := r.loadKey(privPEM); err != nil {
+		t.Fatalf("loadKey() error = %v", err)
+	}
+	if r.keyInfo.Text != "2048-bit key pair" || len(r.fingerprint.Text) != 64 {
+		t.Errorf("key info %q, fingerprint %q", r.keyInfo.Text, r.fingerprint.Text)
+	}
+
+	// The private key is shown only while revealed.
+	if r.privatePEM.Visible() || r.privatePEM.Text != "" {
+		t.Error("private key shown without reveal")
+	}
+	r.reveal.SetChecked(true)
+	if !r.privatePEM.Visible() || !strings.Contains(r.privatePEM.Text, "PRIVATE KEY") {
+		t.Errorf("revealed private key = %.30q", r.privatePEM.Text)
+	}
+	r.reveal.SetChecked(false)
+	if r.privatePEM.Visible() || r.privatePEM.Text != "" {
+		t.Error("private key still shown after hiding")
+	}
+
+	// Sign and verify, then detect a changed message.
+	r.scheme.SetSelectedIndex(int(rsa.SignPSS))
+	r.messageFmt.SetSelected("ASCII")
+	r.message.SetText("hsmtool")
+	r.onSign()
+	if r.signature.Text == "" || r.signStatus.Importance == 0 {
+		t.Fatalf("sign status %q", r.signStatus.Text)
+	}
+	r.onVerify()
+	if r.signStatus.Text != "Signature is valid" {
+		t.Errorf("verify status = %q", r.signStatus.Text)
+	}
+	r.message.SetText("hsmtoo1")
+	r.onVerify()
+	if !strings.Contains(r.signStatus.Text, "verification failed") {
+		t.Errorf("verify of changed data status = %q", r.signStatus.Text)
+	}
+
+	// Encrypt then decrypt back into the plaintext field.
+	r.cipherFmt.SetSelected("Base64")
+	r.plaintext.SetText("0123456789ABCDEF")
+	r.onEncrypt()
+	r.plaintext.SetText("")
+	r.onDecrypt()
+	if r.plaintext.Text != "0123456789ABCDEF" {
+		t.Errorf("decrypted = %q, status %q", r.plaintext.Text, r.cryptStatus.Text)
+	}
+
+	// A public key alone cannot sign or decrypt.
+	prev := r.priv
+	if err := r.loadKey(pubPEM); err != nil {
+		t.Fatalf("loadKey(public) error = %v", err)
+	}
+	if prev.D.Sign() != 0 {
+		t.Error("replaced private key was not wiped")
+	}
+	r.onSign()
+	if !strings.Contains(r.signStatus.Text, "needs a private key") {
+		t.Errorf("sign with a public key status = %q", r.signStatus.Text)
+	}
+	if err := r.loadKey([]byte("junk")); err == nil {
+		t.Error("loadKey(junk) succeeded")
+	}
+
+	r.Cleanup()
+	if r.priv != nil || r.pub != nil || r.fingerprint.Text != "" || r.plaintext.Text != "" {
+		t.Error("Cleanup() left key material")
+	}
+}
@@ -1,8 +1,12 @@
 package tabs
 
 import (
-	"errors"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
 	"strconv"
+	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/canvas"
@@ -13,6 +17,9 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/probe"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
 )
 
 // LMKPairIndices available for encryption.
@@ -28,16 +35,57 @@ type Settings struct {
 	hsmPort         *widget.Entry
 	lmkIndex        *widget.Select
 	concurrentConns *widget.Entry // Added for concurrent connections.
+	hostHint        *widget.Label
+	portHint        *widget.Label
 	statusLED       *canvas.Circle
 	statusText      *canvas.Text
+	statusColor     fyne.ThemeColorName
 	connection      *hsm.Connection
 	connectBtn      *widget.Button
+	testBtn         *widget.Button
 	currentConn     bool
+	auditCheck      *widget.Check
+	disableAudit    func()
+
+	// Command timeout and reconnection policy, applied on connect.
+	commandTimeout    *widget.Entry
+	reconnectAttempts *widget.Entry
+	maxBackoff        *widget.Entry
+	optionsNote       *widget.Label
+
+	// Pool tuning, applied on connect.
+	idleTimeout        *widget.Entry
+	keepAliveInterval  *widget.Entry
+	validationInterval *widget.Entry
+	healthInterval     *widget.Entry
+	tuningNote         *widget.Label
+	healthStop         chan struct{} // Ends the status refresh while connected.
+
+	// TLS, applied on connect.
+	useTLS        *widget.Check
+	tlsCAFile     *widget.Entry
+	tlsCertFile   *widget.Entry
+	tlsKeyFile    *widget.Entry
+	tlsServerName *widget.Entry
+	tlsInsecure   *widget.Check
+	tlsFields     *fyne.Container
+	tlsBrowseBtns []*widget.Button
+
+	// Connection profiles.
+	profiles         *storage.ProfileStore
+	profileSelect    *widget.Select
+	profileSaveBtn   *widget.Button
+	profileSaveAsBtn *widget.Button
+	profileDeleteBtn *widget.Button
+
+	scenarios *storage.ScenarioStore // Exported with the configuration.
 }
 
-// NewSettings creates a new Settings tab.
-func NewSettings() *Settings {
-	s := &Settings{}
+// NewSettings creates a new Settings tab. profiles may be nil, which
+// disables connection profiles; scenarios may be nil, which leaves Command
+// Sender scenarios out of exported configurations.
+func NewSettings(profiles *storage.ProfileStore, scenarios *storage.ScenarioStore) *Settings {
+	s := &Settings{profiles: profiles, scenarios: scenarios}
 	s.ExtendBaseWidget(s)
 
 	// Initialize HSM connection manager
@@ -52,14 +100,7 @@ func NewSettings() *Settings {
 	s.hsmPort = widget.NewEntry()
 	s.hsmPort.SetPlaceHolder("Enter port number...")
 	s.hsmPort.Text = "1500" // Default HSM port
-	s.hsmPort.OnChanged = func(text string) {
-		// Validate port number.
-		if text != "" {
-			if _, err := strconv.Atoi(text); err != nil {
-				s.hsmPort.SetText(text[:len(text)-1])
-			}
-		}
-	}
+	s.initializeAddressHints()
 
 	s.lmkIndex = widget.NewSelect(LMKPairIndices, nil)
 	s.lmkIndex.SetSelected("00") // Default LMK pair
@@ -76,28 +117,48 @@ func NewSettings() *Settings {
 		}
 	}
 
-	// Status indicators
-	s.statusLED = canvas.NewCircle(theme.ErrorColor())
+	// Status indicators, recoloured by Refresh when the theme changes.
+	s.statusColor = theme.ColorNameError
+	s.statusLED = canvas.NewCircle(theme.Color(s.statusColor))
 	s.statusLED.Resize(fyne.NewSize(20, 20))
 	s.statusLED.StrokeWidth = 2
-	s.statusLED.StrokeColor = theme.ShadowColor()
+	s.statusLED.StrokeColor = theme.Color(s.statusColor)
 
-	s.statusText = canvas.NewText("Disconnected", theme.ErrorColor())
+	s.statusText = canvas.NewText("Disconnected", theme.Color(s.statusColor))
 	s.statusText.TextStyle = fyne.TextStyle{Bold: true}
 	s.statusText.TextSize = theme.TextSize() * 1.2
 
 	// Connection button
 	s.connectBtn = widget.NewButton("Connect", s.onConnectClick)
+	s.testBtn = widget.NewButton("Test Connection", s.onTestConnection)
+
+	// Command audit toggle, enabled by default when logging is available.
+	s.auditCheck = widget.NewCheck("Audit HSM commands", s.onAuditToggled)
+	if logger.Default() != nil {
+		s.auditCheck.SetChecked(true)
+	} else {
+		s.auditCheck.Disable()
+	}
+
+	s.initializeConnectOptions()
+	s.initializeTLS()
+	advanced := s.initializeTuning()
+	profileRow := s.initializeProfiles()
 
 	// Layout forms
 	connForm := widget.NewForm(
-		&widget.FormItem{Text: "HSM IP/Hostname", Widget: s.hsmIP},
-		&widget.FormItem{Text: "Port", Widget: s.hsmPort},
+		&widget.FormItem{Text: "Profile", Widget: profileRow},
+		&widget.FormItem{Text: "HSM IP/Hostname", Widget: container.NewVBox(s.hsmIP, s.hostHint)},
+		&widget.FormItem{Text: "Port", Widget: container.NewVBox(s.hsmPort, s.portHint)},
 		&widget.FormItem{Text: "LMK Pair Index", Widget: s.lmkIndex},
 		&widget.FormItem{
 			Text:   "Concurrent Connections",
 			Widget: s.concurrentConns,
 		}, // Added to form.
+		&widget.FormItem{Text: "Command Timeout (ms)", Widget: s.commandTimeout},
+		&widget.FormItem{Text: "Max Reconnect Attempts", Widget: s.reconnectAttempts},
+		&widget.FormItem{Text: "Max Backoff (s)", Widget: s.maxBackoff},
+		&widget.FormItem{Text: "TLS", Widget: s.useTLS},
 	)
 
 	// Create status bar with some padding around the status text
@@ -105,30 +166,51 @@ func NewSettings() *Settings {
 		layout.NewSpacer(),
 		s.statusLED,
 		container.NewPadded(s.statusText),
+		s.testBtn,
 		s.connectBtn,
 	)
 
 	// Create container
 	hsmConn := widget.NewCard("HSM Connection", "", container.NewVBox(
 		connForm,
+		s.tlsFields,
+		advanced,
+		s.optionsNote,
+		s.auditCheck,
 		statusBar,
 	))
 
+	configCard := widget.NewCard("Configuration", "Share profiles, preferences and scenarios", container.NewHBox(
+		widget.NewButtonWithIcon("Export configuration…", theme.DocumentSaveIcon(), s.onExportConfig),
+		widget.NewButtonWithIcon("Import configuration…", theme.FolderOpenIcon(), s.onImportConfig),
+	))
+
 	s.container = container.NewVBox(
 		hsmConn,
+		configCard,
 	)
 
 	return s
 }
 
+// onAuditToggled enables or disables HSM command audit logging.
+func (s *Settings) onAuditToggled(enabled bool) {
+	if s.disableAudit != nil {
+		s.disableAudit()
+		s.disableAudit = nil
+	}
+
+	if l := logger.Default(); enabled && l != nil {
+		s.disableAudit = hsm.EnableAuditLogging(s.connection, l)
+	}
+}
+
 func (s *Settings) onConnectionStateChanged(state hsm.ConnectionState) {
 	// Update UI on the main thread
 	fyne.Do(func() {
+		s.showStatus(state)
 		if state == hsm.Connected {
-			s.statusLED.FillColor = theme.SuccessColor()
-			s.statusLED.StrokeColor = theme.SuccessColor()
-			s.statusText.Text = "Connected"
-			s.statusText.Color = theme.SuccessColor()
+			s.watchHealth()
 			s.connectBtn.SetText("Disconnect")
 			s.currentConn = true
 			// Disable input fields when connected
@@ -136,11 +218,10 @@ func (s *Settings) onConnectionStateChanged(state hsm.ConnectionState) {
 			s.hsmPort.Disable()
 			s.lmkIndex.Disable()
 			s.concurrentConns.Disable() // Disable when connected.
+			s.showProfilesConnected(true)
+			s.showTLSConnected(true)
 		} else {
-			s.statusLED.FillColor = theme.ErrorColor()
-			s.statusLED.StrokeColor = theme.ErrorColor()
-			s.statusText.Text = "Disconnected"
-			s.statusText.Color = theme.ErrorColor()
+			s.stopWatchingHealth()
 			s.connectBtn.SetText("Connect")
 			s.currentConn = false
 			// Re-enable input fields when disconnected
@@ -148,52 +229,32 @@ func (s *Settings) onConnectionStateChanged(state hsm.ConnectionState) {
 			s.hsmPort.Enable()
 			s.lmkIndex.Enable()
 			s.concurrentConns.Enable() // Enable when disconnected.
+			s.showProfilesConnected(false)
+			s.showTLSConnected(false)
 		}
-		s.statusLED.Refresh()
-		s.statusText.Refresh()
+		s.showOptionsNote()
 		s.connectBtn.Refresh()
 	})
 }
 
 func (s *Settings) onConnectClick() {
 	if !s.currentConn {
-		// Disable button while connecting - this is on UI thread already
-		s.connectBtn.Disable()
-		s.connectBtn.SetText("Connecting...")
-
-		hsmIP := s.hsmIP.Text
-		if hsmIP == "" {
-			hsmIP = "localhost"
-		}
-
-		numConnsStr := s.concurrentConns.Text
-		if numConnsStr == "" {
-			numConnsStr = "1"              // Default to 1 if empty.
-			s.concurrentConns.SetText("1") // Update UI as well.
+		if !s.validateAddress() {
+			return
 		}
-		numConns64, err := strconv.ParseInt(numConnsStr, 10, 32)
-		if err != nil || numConns64 < 1 {
-			dialog.ShowError(
-				errors.New(
-					"invalid number of concurrent connections: '"+numConnsStr+"' please enter a positive integer",
-				),
-				fyne.CurrentApp().Driver().AllWindows()[0],
-			)
-			s.connectBtn.Enable()
-			s.connectBtn.SetText("Connect")
-			s.concurrentConns.SetText("1") // Reset to default valid value.
-
+		opts, err := s.form().options()
+		if err != nil {
+			dialog.ShowError(err, fyne.CurrentApp().Driver().AllWindows()[0])
 			return
 		}
-		numConns := uint32(numConns64)
+
+		// Disable button while connecting - this is on UI thread already
+		s.connectBtn.Disable()
+		s.connectBtn.SetText("Connecting...")
 
 		// Connect in a goroutine to avoid blocking UI
 		go func() {
-			err := s.connection.Connect(
-				hsmIP,
-				s.hsmPort.Text,
-				numConns,
-			) // Pass numConns to Connect method.
+			err := s.connection.ConnectWithOptions(opts)
 
 			// Update UI on the main thread
 			fyne.Do(func() {
@@ -224,12 +285,80 @@ func (s *Settings) onConnectClick() {
 	}
 }
 
+// onTestConnection sends NC over the open connection, or over a one-shot
+// connection to the configured host and port when disconnected, and reports
+// the outcome.
 func (s *Settings) onTestConnection() {
-	// TODO: Implement HSM connection test.
-	// For now, just show a placeholder dialog.
-	dialog.ShowInformation("Connection Test",
-		"Testing connection to HSM...",
-		fyne.CurrentApp().Driver().AllWindows()[0])
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	var addr string
+	var tlsConfig *tls.Config
+	connected := s.connection.GetState() == hsm.Connected
+	if !connected {
+		if !s.validateAddress() {
+			return
+		}
+		opts, err := s.form().options()
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if opts.TLS != nil {
+			if tlsConfig, err = opts.TLS.Config(); err != nil {
+				dialog.ShowError(err, w)
+				return
+			}
+		}
+		addr = net.JoinHostPort(opts.Host, opts.Port)
+	}
+
+	s.testBtn.Disable()
+	s.testBtn.SetText("Testing...")
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		var res probe.Result
+		var err error
+		if connected {
+			res, err = probe.Run(ctx, connExecutor{s.connection})
+		} else {
+			res, err = probe.Dial(ctx, addr, tlsConfig)
+		}
+
+		fyne.Do(func() {
+			s.testBtn.Enable()
+			s.testBtn.SetText("Test Connection")
+			if err != nil {
+				logger.Warn("hsm_test_connection", "Failed", err.Error())
+				dialog.ShowError(fmt.Errorf("connection test failed: %v", err), w)
+
+				return
+			}
+			logger.Info("hsm_test_connection", "Success", fmt.Sprintf("rtt=%s", res.RTT))
+			dialog.ShowInformation("Connection Test", connectionTestReport(res, connected), w)
+		})
+	}()
+}
+
+// connectionTestReport describes a successful connection test.
+func connectionTestReport(res probe.Result, connected bool) string {
+	via := "one-shot connection"
+	if connected {
+		via = "open connection"
+	}
+	firmware := res.Firmware
+	if firmware == "" {
+		firmware = "not reported"
+	}
+
+	return fmt.Sprintf(
+		"HSM reachable over the %s.\n\nRound trip: %s\nFirmware: %s\nLMK check value: %s",
+		via,
+		res.RTT.Round(time.Microsecond),
+		firmware,
+		res.LMKCheckValue,
+	)
 }
 
 // GetConnection returns the HSM connection instance.
@@ -250,6 +379,9 @@ func (s *Settings) Cleanup() {
 	}
 	s.hsmIP.SetText("")
 	s.hsmPort.SetText("1500")
+	showHint(s.hostHint, nil)
 	s.lmkIndex.SetSelected("00")
 	s.concurrentConns.SetText("1") // Reset concurrent connections.
+	s.setProfileOptions(storage.ConnectionProfile{})
+	s.profileSelect.ClearSelected()
 }
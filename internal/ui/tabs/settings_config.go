@@ -0,0 +1,160 @@
+package tabs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/config"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// configPrefs are the preferences included in exported configurations.
+var configPrefs = []config.Pref{
+	{Key: prefTimeoutMillis, Kind: config.PrefInt},
+	{Key: prefRememberCommands, Kind: config.PrefBool},
+	{Key: prefRecentCommands, Kind: config.PrefStringList, Sensitive: true},
+}
+
+// AddConfigPrefs includes prefs, kept outside the tabs, in exported
+// configurations. It must be called before the Settings tab is created.
+func AddConfigPrefs(prefs ...config.Pref) {
+	configPrefs = append(configPrefs, prefs...)
+}
+
+// configSources returns the stores the configuration is exported from and
+// imported into.
+func (s *Settings) configSources() config.Sources {
+	return config.Sources{
+		Profiles:    s.profiles,
+		Scenarios:   s.scenarios,
+		Preferences: fyne.CurrentApp().Preferences(),
+		Prefs:       configPrefs,
+	}
+}
+
+// onExportConfig writes the configuration to a JSON file, asking whether to
+// include recent commands and scenarios that hold clear keys.
+func (s *Settings) onExportConfig() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	sensitive := widget.NewCheck("Include recent commands and scenarios with clear keys", nil)
+	dialog.ShowForm("Export Configuration", "Export", "Cancel", []*widget.FormItem{
+		widget.NewFormItem("", widget.NewLabel("Profiles, preferences and scenarios. Keys are never exported.")),
+		widget.NewFormItem("", sensitive),
+	}, func(ok bool) {
+		if !ok {
+			return
+		}
+		b, err := config.Export(s.configSources(), sensitive.Checked, time.Now())
+		if err == nil {
+			var data []byte
+			if data, err = b.Marshal(); err == nil {
+				s.saveConfigFile(data, w)
+				return
+			}
+		}
+		dialog.ShowError(err, w)
+	}, w)
+}
+
+// saveConfigFile asks where to write the exported configuration.
+func (s *Settings) saveConfigFile(data []byte, w fyne.Window) {
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if _, err := wc.Write(data); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+			return
+		}
+		logger.Info("config_export", "Success", wc.URI().Name())
+	}, w)
+	save.SetFileName("hsmtool-config.json")
+	save.Show()
+}
+
+// onImportConfig reads an exported configuration and applies it after
+// showing what it adds and overwrites.
+func (s *Settings) onImportConfig() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	dialog.ShowFileOpen(func(rc fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if rc == nil {
+			return // Cancelled.
+		}
+		defer rc.Close()
+
+		data, err := io.ReadAll(rc)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to read %s: %v", rc.URI().Name(), err), w)
+			return
+		}
+		b, err := config.Parse(data)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("failed to load %s: %v", rc.URI().Name(), err), w)
+			return
+		}
+		changes, err := b.Plan(s.configSources())
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if len(changes) == 0 {
+			dialog.ShowInformation("Import Configuration", "The configuration is already up to date.", w)
+			return
+		}
+		s.confirmImport(b, changes, w)
+	}, w)
+}
+
+// confirmImport shows the changes an import makes and applies them when
+// confirmed.
+func (s *Settings) confirmImport(b config.Bundle, changes []config.Change, w fyne.Window) {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	summary := widget.NewLabel(strings.Join(lines, "\n"))
+	summary.TextStyle = fyne.TextStyle{Monospace: true}
+	scroll := container.NewVScroll(summary)
+	scroll.SetMinSize(fyne.NewSize(420, 200))
+	note := widget.NewLabel("Preferences take effect on the next start.")
+
+	dialog.ShowCustomConfirm("Import Configuration", "Import", "Cancel",
+		container.NewBorder(nil, note, nil, nil, scroll),
+		func(ok bool) {
+			if !ok {
+				return
+			}
+			if err := b.Apply(s.configSources()); err != nil {
+				logger.Error("config_import", "Failed", err.Error())
+				dialog.ShowError(fmt.Errorf("import failed, nothing was changed: %v", err), w)
+				return
+			}
+			logger.Info("config_import", "Success", fmt.Sprintf("%d changes", len(changes)))
+			switch {
+			case s.profiles == nil:
+			case s.currentConn: // Keep the form of the open connection.
+				s.profileSelect.SetOptions(s.profiles.List())
+			default:
+				s.refreshProfiles(s.profileSelect.Selected)
+			}
+		}, w)
+}
@@ -0,0 +1,422 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/sender"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// Limits of the reconnection fields.
+const (
+	maxReconnectAttempts = 1000
+	maxBackoffSeconds    = 3600
+	maxTuningSeconds     = 3600
+)
+
+// connectionForm holds the Settings connection fields as typed.
+type connectionForm struct {
+	Host              string
+	Port              string
+	Connections       string
+	CommandTimeout    string // Milliseconds.
+	ReconnectAttempts string // 0 retries forever.
+	MaxBackoff        string // Seconds.
+
+	// Pool tuning in seconds; empty or 0 keeps the default.
+	IdleTimeout        string
+	KeepAlive          string
+	ValidationInterval string
+	HealthCheck        string // Empty or 0 turns health checks off.
+
+	TLS tlsForm
+}
+
+// options validates the form and returns the connection options it
+// describes. An empty host means localhost.
+func (f connectionForm) options() (hsm.ConnectOptions, error) {
+	host, err := parseHost(f.Host)
+	if err != nil {
+		return hsm.ConnectOptions{}, fmt.Errorf("invalid HSM host %q: %v", host, err)
+	}
+	port, err := parsePort(f.Port)
+	if err != nil {
+		return hsm.ConnectOptions{}, fmt.Errorf("invalid HSM port %q: %v", strings.TrimSpace(f.Port), err)
+	}
+
+	conns := strings.TrimSpace(f.Connections)
+	if conns == "" {
+		conns = "1"
+	}
+	n, err := strconv.ParseInt(conns, 10, 32)
+	if err != nil || n < 1 {
+		return hsm.ConnectOptions{}, errors.New(
+			"invalid number of concurrent connections: '" + conns + "' please enter a positive integer",
+		)
+	}
+
+	timeout, err := sender.ParseTimeout(f.CommandTimeout)
+	if err != nil {
+		return hsm.ConnectOptions{}, fmt.Errorf("command timeout: %v", err)
+	}
+	attempts, err := parseBoundedInt(f.ReconnectAttempts, 0, maxReconnectAttempts)
+	if err != nil {
+		return hsm.ConnectOptions{}, fmt.Errorf("max reconnect attempts: %v", err)
+	}
+	backoff, err := parseBoundedInt(f.MaxBackoff, 1, maxBackoffSeconds)
+	if err != nil {
+		return hsm.ConnectOptions{}, fmt.Errorf("max backoff: %v", err)
+	}
+	tuning, err := f.tuning()
+	if err != nil {
+		return hsm.ConnectOptions{}, err
+	}
+	tlsOpts, err := f.TLS.options()
+	if err != nil {
+		return hsm.ConnectOptions{}, err
+	}
+
+	opts := hsm.DefaultConnectOptions(host, port, uint32(n))
+	opts.CommandTimeout = timeout
+	opts.Reconnect = hsm.ReconnectPolicy{
+		MaxAttempts: attempts,
+		MaxBackoff:  time.Duration(backoff) * time.Second,
+	}
+	opts.TLS = tlsOpts
+	opts.IdleTimeout = tuning.IdleTimeout
+	opts.KeepAliveInterval = tuning.KeepAliveInterval
+	opts.ValidationInterval = tuning.ValidationInterval
+	opts.HealthCheckInterval = tuning.HealthCheckInterval
+
+	return opts, nil
+}
+
+// tuning returns the pool tuning and health check fields as connection
+// options with only those durations set.
+func (f connectionForm) tuning() (hsm.ConnectOptions, error) {
+	var opts hsm.ConnectOptions
+	for _, field := range []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"idle timeout", f.IdleTimeout, &opts.IdleTimeout},
+		{"keep-alive interval", f.KeepAlive, &opts.KeepAliveInterval},
+		{"validation interval", f.ValidationInterval, &opts.ValidationInterval},
+		{"health check interval", f.HealthCheck, &opts.HealthCheckInterval},
+	} {
+		n, err := parseTuningSeconds(field.value)
+		if err != nil {
+			return hsm.ConnectOptions{}, fmt.Errorf("%s: %v", field.name, err)
+		}
+		*field.dst = time.Duration(n) * time.Second
+	}
+
+	return opts, nil
+}
+
+// parseTuningSeconds parses a pool tuning field, where empty means 0.
+func parseTuningSeconds(s string) (int, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+
+	return parseBoundedInt(s, 0, maxTuningSeconds)
+}
+
+// parseHost returns the trimmed host, or localhost when empty, and an error
+// when it is neither an IP address nor a valid hostname.
+func parseHost(s string) (string, error) {
+	host := strings.TrimSpace(s)
+	if host == "" {
+		host = "localhost"
+	}
+
+	return host, utils.ValidateHostOrIP(host)
+}
+
+// parsePort parses a TCP port number.
+func parsePort(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("port is required")
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return "", errors.New("port must be a number")
+	}
+	if err := utils.ValidatePort(n); err != nil {
+		return "", fmt.Errorf("%v: must be between 1 and 65535", err)
+	}
+
+	return strconv.Itoa(n), nil
+}
+
+// parseBoundedInt parses a whole number between lo and hi.
+func parseBoundedInt(s string, lo, hi int) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("must be a whole number between %d and %d", lo, hi)
+	}
+
+	return n, nil
+}
+
+// initializeAddressHints creates the inline error labels of the host and
+// port fields. The port hint follows typing; the host hint is shown by
+// validateAddress and follows typing from then on.
+func (s *Settings) initializeAddressHints() {
+	newHint := func() *widget.Label {
+		l := widget.NewLabel("")
+		l.Importance = widget.DangerImportance
+		l.Wrapping = fyne.TextWrapWord
+		l.Hide()
+
+		return l
+	}
+	s.hostHint = newHint()
+	s.portHint = newHint()
+
+	s.hsmIP.OnChanged = func(text string) {
+		if s.hostHint.Visible() {
+			_, err := parseHost(text)
+			showHint(s.hostHint, err)
+		}
+	}
+	s.hsmPort.OnChanged = func(text string) {
+		if strings.TrimSpace(text) == "" {
+			showHint(s.portHint, nil)
+			return
+		}
+		_, err := parsePort(text)
+		showHint(s.portHint, err)
+	}
+}
+
+// validateAddress shows the inline errors of the host and port fields and
+// reports whether both are valid.
+func (s *Settings) validateAddress() bool {
+	_, hostErr := parseHost(s.hsmIP.Text)
+	_, portErr := parsePort(s.hsmPort.Text)
+	showHint(s.hostHint, hostErr)
+	showHint(s.portHint, portErr)
+
+	return hostErr == nil && portErr == nil
+}
+
+// showHint shows err in hint, or hides hint when err is nil.
+func showHint(hint *widget.Label, err error) {
+	if err == nil {
+		hint.Hide()
+		return
+	}
+	hint.SetText(err.Error())
+	hint.Show()
+}
+
+// initializeConnectOptions creates the command timeout and reconnection
+// fields with their defaults.
+func (s *Settings) initializeConnectOptions() {
+	s.commandTimeout = widget.NewEntry()
+	s.commandTimeout.SetText(strconv.Itoa(int(hsm.DefaultCommandTimeout.Milliseconds())))
+	s.commandTimeout.Validator = func(v string) error {
+		_, err := sender.ParseTimeout(v)
+		return err
+	}
+	s.reconnectAttempts = widget.NewEntry()
+	s.reconnectAttempts.SetText(strconv.Itoa(hsm.DefaultReconnectAttempts))
+	s.reconnectAttempts.SetPlaceHolder("0 = infinite")
+	s.reconnectAttempts.Validator = func(v string) error {
+		_, err := parseBoundedInt(v, 0, maxReconnectAttempts)
+		return err
+	}
+	s.maxBackoff = widget.NewEntry()
+	s.maxBackoff.SetText(strconv.Itoa(int(hsm.DefaultMaxBackoff.Seconds())))
+	s.maxBackoff.Validator = func(v string) error {
+		_, err := parseBoundedInt(v, 1, maxBackoffSeconds)
+		return err
+	}
+
+	s.optionsNote = widget.NewLabel("Timeout and reconnection changes apply on next connect.")
+	s.optionsNote.Importance = widget.WarningImportance
+	s.optionsNote.Wrapping = fyne.TextWrapWord
+	s.optionsNote.Hide()
+	for _, e := range []*widget.Entry{s.commandTimeout, s.reconnectAttempts, s.maxBackoff} {
+		e.OnChanged = func(string) { s.showOptionsNote() }
+	}
+}
+
+// initializeTuning creates the pool tuning fields, collapsed under an
+// Advanced section.
+func (s *Settings) initializeTuning() fyne.CanvasObject {
+	newField := func(def time.Duration) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetPlaceHolder(fmt.Sprintf("0 = default (%d)", int(def.Seconds())))
+		e.Validator = func(v string) error {
+			_, err := parseTuningSeconds(v)
+			return err
+		}
+		e.OnChanged = func(string) {
+			s.showTuningNote()
+			s.showOptionsNote()
+		}
+
+		return e
+	}
+	s.idleTimeout = newField(hsm.DefaultIdleTimeout)
+	s.keepAliveInterval = newField(hsm.DefaultKeepAliveInterval)
+	s.validationInterval = newField(hsm.DefaultValidationInterval)
+	s.healthInterval = widget.NewEntry()
+	s.healthInterval.SetPlaceHolder("0 = off")
+	s.healthInterval.Validator = func(v string) error {
+		_, err := parseTuningSeconds(v)
+		return err
+	}
+	s.healthInterval.OnChanged = func(string) { s.showOptionsNote() }
+
+	s.tuningNote = widget.NewLabel("")
+	s.tuningNote.Importance = widget.WarningImportance
+	s.tuningNote.Wrapping = fyne.TextWrapWord
+	s.tuningNote.Hide()
+
+	return widget.NewAccordion(widget.NewAccordionItem("Advanced", container.NewVBox(
+		widget.NewForm(
+			widget.NewFormItem("Idle Timeout (s)", s.idleTimeout),
+			widget.NewFormItem("Keep-alive Interval (s)", s.keepAliveInterval),
+			widget.NewFormItem("Validation Interval (s)", s.validationInterval),
+			widget.NewFormItem("Health Check Interval (s)", s.healthInterval),
+		),
+		s.tuningNote,
+	)))
+}
+
+// showTuningNote warns about pool tuning that defeats itself. It does not
+// stop the connection.
+func (s *Settings) showTuningNote() {
+	opts, err := s.form().tuning()
+	if err == nil {
+		err = opts.CheckTuning()
+	}
+	if err == nil {
+		s.tuningNote.Hide()
+		return
+	}
+	s.tuningNote.SetText(err.Error())
+	s.tuningNote.Show()
+}
+
+// showOptionsNote tells that option changes made while connected only apply
+// to the next connection.
+func (s *Settings) showOptionsNote() {
+	if !s.currentConn {
+		s.optionsNote.Hide()
+		return
+	}
+
+	opts := s.connection.Options()
+	f := s.form()
+	if f.CommandTimeout == strconv.Itoa(int(opts.CommandTimeout.Milliseconds())) &&
+		f.ReconnectAttempts == strconv.Itoa(opts.Reconnect.MaxAttempts) &&
+		f.MaxBackoff == strconv.Itoa(int(opts.Reconnect.MaxBackoff.Seconds())) &&
+		sameSeconds(f.IdleTimeout, opts.IdleTimeout) &&
+		sameSeconds(f.KeepAlive, opts.KeepAliveInterval) &&
+		sameSeconds(f.ValidationInterval, opts.ValidationInterval) &&
+		sameSeconds(f.HealthCheck, opts.HealthCheckInterval) {
+		s.optionsNote.Hide()
+	} else {
+		s.optionsNote.Show()
+	}
+}
+
+// sameSeconds reports whether the tuning field v holds d.
+func sameSeconds(v string, d time.Duration) bool {
+	n, err := parseTuningSeconds(v)
+
+	return err == nil && time.Duration(n)*time.Second == d
+}
+
+// form returns the connection fields as typed.
+func (s *Settings) form() connectionForm {
+	return connectionForm{
+		Host:              s.hsmIP.Text,
+		Port:              s.hsmPort.Text,
+		Connections:       s.concurrentConns.Text,
+		CommandTimeout:    strings.TrimSpace(s.commandTimeout.Text),
+		ReconnectAttempts: strings.TrimSpace(s.reconnectAttempts.Text),
+		MaxBackoff:        strings.TrimSpace(s.maxBackoff.Text),
+
+		IdleTimeout:        strings.TrimSpace(s.idleTimeout.Text),
+		KeepAlive:          strings.TrimSpace(s.keepAliveInterval.Text),
+		ValidationInterval: strings.TrimSpace(s.validationInterval.Text),
+		HealthCheck:        strings.TrimSpace(s.healthInterval.Text),
+
+		TLS: s.tlsForm(),
+	}
+}
+
+// setProfileOptions fills the timeout and reconnection fields from p,
+// keeping the defaults for values the profile does not set.
+func (s *Settings) setProfileOptions(p storage.ConnectionProfile) {
+	timeout := hsm.DefaultCommandTimeout
+	if p.CommandTimeoutMillis > 0 {
+		timeout = time.Duration(p.CommandTimeoutMillis) * time.Millisecond
+	}
+	attempts := hsm.DefaultReconnectAttempts
+	if p.MaxReconnectAttempts != nil {
+		attempts = *p.MaxReconnectAttempts
+	}
+	backoff := hsm.DefaultMaxBackoff
+	if p.MaxBackoffSeconds > 0 {
+		backoff = time.Duration(p.MaxBackoffSeconds) * time.Second
+	}
+
+	s.commandTimeout.SetText(strconv.Itoa(int(timeout.Milliseconds())))
+	s.reconnectAttempts.SetText(strconv.Itoa(attempts))
+	s.maxBackoff.SetText(strconv.Itoa(int(backoff.Seconds())))
+	for _, f := range []struct {
+		e       *widget.Entry
+		seconds int
+	}{
+		{s.idleTimeout, p.IdleTimeoutSeconds},
+		{s.keepAliveInterval, p.KeepAliveSeconds},
+		{s.validationInterval, p.ValidationIntervalSeconds},
+		{s.healthInterval, p.HealthCheckSeconds},
+	} {
+		if f.seconds > 0 {
+			f.e.SetText(strconv.Itoa(f.seconds))
+		} else {
+			f.e.SetText("")
+		}
+	}
+	s.setProfileTLS(p)
+}
+
+// profileOptions stores the valid timeout and reconnection fields in p.
+func (s *Settings) profileOptions(p *storage.ConnectionProfile) {
+	if d, err := sender.ParseTimeout(s.commandTimeout.Text); err == nil {
+		p.CommandTimeoutMillis = int(d.Milliseconds())
+	}
+	if n, err := parseBoundedInt(s.reconnectAttempts.Text, 0, maxReconnectAttempts); err == nil {
+		p.MaxReconnectAttempts = &n
+	}
+	if n, err := parseBoundedInt(s.maxBackoff.Text, 1, maxBackoffSeconds); err == nil {
+		p.MaxBackoffSeconds = n
+	}
+	if t, err := s.form().tuning(); err == nil {
+		p.IdleTimeoutSeconds = int(t.IdleTimeout.Seconds())
+		p.KeepAliveSeconds = int(t.KeepAliveInterval.Seconds())
+		p.ValidationIntervalSeconds = int(t.ValidationInterval.Seconds())
+		p.HealthCheckSeconds = int(t.HealthCheckInterval.Seconds())
+	}
+	s.profileTLS(p)
+}
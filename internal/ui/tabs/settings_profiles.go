@@ -0,0 +1,194 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+)
+
+// initializeProfiles creates the connection profile controls and preselects
+// the last used profile. Without a profile store the controls are disabled.
+func (s *Settings) initializeProfiles() fyne.CanvasObject {
+	s.profileSelect = widget.NewSelect(nil, s.onProfileSelected)
+	s.profileSelect.PlaceHolder = "(no profile)"
+	s.profileSaveBtn = widget.NewButtonWithIcon("Save", theme.DocumentSaveIcon(), s.onSaveProfile)
+	s.profileSaveAsBtn = widget.NewButton("Save as…", s.onSaveProfileAs)
+	s.profileDeleteBtn = widget.NewButtonWithIcon("", theme.DeleteIcon(), s.onDeleteProfile)
+
+	if s.profiles == nil {
+		s.profileSelect.Disable()
+		s.profileSaveBtn.Disable()
+		s.profileSaveAsBtn.Disable()
+		s.profileDeleteBtn.Disable()
+	} else {
+		s.refreshProfiles(s.profiles.LastUsed())
+	}
+
+	return container.NewBorder(
+		nil, nil, nil,
+		container.NewHBox(s.profileSaveBtn, s.profileSaveAsBtn, s.profileDeleteBtn),
+		s.profileSelect,
+	)
+}
+
+// refreshProfiles reloads the profile list and selects selected, which may be
+// empty.
+func (s *Settings) refreshProfiles(selected string) {
+	s.profileSelect.SetOptions(s.profiles.List())
+	if selected == "" {
+		s.profileSelect.ClearSelected()
+	} else {
+		s.profileSelect.SetSelected(selected)
+	}
+	s.showProfileButtons()
+}
+
+// showProfileButtons enables the actions that apply to the selected profile.
+func (s *Settings) showProfileButtons() {
+	if s.profileSelect.Selected == "" {
+		s.profileDeleteBtn.Disable()
+	} else {
+		s.profileDeleteBtn.Enable()
+	}
+}
+
+// onProfileSelected fills the connection form from the profile. It never
+// connects.
+func (s *Settings) onProfileSelected(name string) {
+	s.showProfileButtons()
+	p, ok := s.profiles.Get(name)
+	if !ok {
+		return
+	}
+
+	s.hsmIP.SetText(p.Host)
+	s.hsmPort.SetText(p.Port)
+	if p.LMKIndex != "" {
+		s.lmkIndex.SetSelected(p.LMKIndex)
+	}
+	if p.Connections > 0 {
+		s.concurrentConns.SetText(strconv.Itoa(p.Connections))
+	}
+	s.setProfileOptions(p)
+	if err := s.profiles.SetLastUsed(name); err != nil {
+		logger.Warn("profile_select", "Failed", err.Error())
+	}
+}
+
+// currentProfile returns the connection form as a profile named name.
+func (s *Settings) currentProfile(name string) storage.ConnectionProfile {
+	conns, err := strconv.Atoi(s.concurrentConns.Text)
+	if err != nil || conns < 1 {
+		conns = 1
+	}
+
+	p := storage.ConnectionProfile{
+		Name:        name,
+		Host:        strings.TrimSpace(s.hsmIP.Text),
+		Port:        s.hsmPort.Text,
+		LMKIndex:    s.lmkIndex.Selected,
+		Connections: conns,
+	}
+	s.profileOptions(&p)
+
+	return p
+}
+
+// onSaveProfile updates the selected profile from the form, or asks for a
+// name when none is selected.
+func (s *Settings) onSaveProfile() {
+	name := s.profileSelect.Selected
+	if name == "" {
+		s.onSaveProfileAs()
+		return
+	}
+	s.saveProfile(name, true)
+}
+
+// onSaveProfileAs saves the form as a new profile.
+func (s *Settings) onSaveProfileAs() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	name := widget.NewEntry()
+	name.Validator = storage.ValidateProfileName
+	name.SetPlaceHolder("e.g. UAT payShield")
+	dialog.ShowForm("Save Profile", "Save", "Cancel",
+		[]*widget.FormItem{widget.NewFormItem("Name", name)},
+		func(ok bool) {
+			if ok {
+				s.saveProfile(strings.TrimSpace(name.Text), false)
+			}
+		}, w)
+}
+
+// saveProfile stores the form as the named profile, asking before replacing
+// an existing one unless overwrite is set.
+func (s *Settings) saveProfile(name string, overwrite bool) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+
+	err := s.profiles.Save(s.currentProfile(name), overwrite)
+	if errors.Is(err, storage.ErrProfileExists) {
+		dialog.ShowConfirm(
+			"Overwrite Profile",
+			fmt.Sprintf("Profile %q already exists. Overwrite it?", name),
+			func(ok bool) {
+				if ok {
+					s.saveProfile(name, true)
+				}
+			},
+			w,
+		)
+		return
+	}
+	if err != nil {
+		dialog.ShowError(err, w)
+		return
+	}
+
+	logger.Info("profile_save", "Success", name)
+	s.refreshProfiles(name)
+}
+
+// onDeleteProfile deletes the selected profile after confirmation.
+func (s *Settings) onDeleteProfile() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	name := s.profileSelect.Selected
+	if name == "" {
+		return
+	}
+
+	dialog.ShowConfirm("Delete Profile", fmt.Sprintf("Delete profile %q?", name), func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := s.profiles.Delete(name); err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		logger.Info("profile_delete", "Success", name)
+		s.refreshProfiles("")
+	}, w)
+}
+
+// showProfilesConnected stops profiles from changing the form while
+// connected.
+func (s *Settings) showProfilesConnected(connected bool) {
+	if s.profiles == nil {
+		return
+	}
+	if connected {
+		s.profileSelect.Disable()
+	} else {
+		s.profileSelect.Enable()
+	}
+}
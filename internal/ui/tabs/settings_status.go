@@ -0,0 +1,103 @@
+package tabs
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+)
+
+// statusRefreshInterval is how often the status follows the health checks
+// while connected.
+const statusRefreshInterval = time.Second
+
+// connectionStatus returns the status text and theme colour for state and
+// the health checks at now.
+func connectionStatus(state hsm.ConnectionState, h hsm.Health, now time.Time) (string, fyne.ThemeColorName) {
+	if state != hsm.Connected {
+		return "Disconnected", theme.ColorNameError
+	}
+
+	switch {
+	case h.Enabled && h.Failures > 0:
+		silent := now.Sub(h.LastResponse).Truncate(time.Second)
+		return fmt.Sprintf("Degraded · no response %s", silent), theme.ColorNameWarning
+	case h.Enabled && h.RTT > 0:
+		return fmt.Sprintf("Connected · %s", formatRTT(h.RTT)), theme.ColorNameSuccess
+	default:
+		return "Connected", theme.ColorNameSuccess
+	}
+}
+
+// formatRTT formats a round trip in whole milliseconds.
+func formatRTT(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1 ms"
+	}
+
+	return fmt.Sprintf("%d ms", d.Milliseconds())
+}
+
+// showStatus shows the connection status on the LED and status text.
+func (s *Settings) showStatus(state hsm.ConnectionState) {
+	text, c := connectionStatus(state, s.connection.GetHealth(), time.Now())
+	s.statusColor = c
+	s.statusText.Text = text
+	s.showStatusColor()
+}
+
+// Refresh implements fyne.Widget interface. Fyne refreshes every widget when
+// the theme changes, so the status is recoloured here.
+func (s *Settings) Refresh() {
+	s.showStatusColor()
+	s.BaseWidget.Refresh()
+}
+
+// showStatusColor colours the LED and status text in the current theme, so
+// they stay legible when the theme changes.
+func (s *Settings) showStatusColor() {
+	c := theme.Color(s.statusColor)
+	s.statusLED.FillColor = c
+	s.statusLED.StrokeColor = c
+	s.statusText.Color = c
+	s.statusLED.Refresh()
+	s.statusText.Refresh()
+}
+
+// watchHealth refreshes the status while connected, so it follows the
+// health checks and counts the time without a response.
+func (s *Settings) watchHealth() {
+	if s.healthStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	s.healthStop = stop
+	go func() {
+		ticker := time.NewTicker(statusRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				fyne.Do(func() {
+					if s.healthStop == stop {
+						s.showStatus(s.connection.GetState())
+					}
+				})
+			}
+		}
+	}()
+}
+
+// stopWatchingHealth ends the status refresh started by watchHealth.
+func (s *Settings) stopWatchingHealth() {
+	if s.healthStop != nil {
+		close(s.healthStop)
+		s.healthStop = nil
+	}
+}
@@ -0,0 +1,457 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/config"
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+func TestConnectionForm_Options(t *testing.T) {
+	valid := connectionForm{
+		Host:              "10.0.0.5",
+		Port:              "1500",
+		Connections:       "4",
+		CommandTimeout:    "750",
+		ReconnectAttempts: "0",
+		MaxBackoff:        "10",
+	}
+	tests := []struct {
+		name    string
+		edit    func(f *connectionForm)
+		want    hsm.ConnectOptions
+		wantErr string
+	}{
+		{
+			"all_fields",
+			func(*connectionForm) {},
+			hsm.ConnectOptions{
+				Host:           "10.0.0.5",
+				Port:           "1500",
+				Connections:    4,
+				CommandTimeout: 750 * time.Millisecond,
+				Reconnect:      hsm.ReconnectPolicy{MaxAttempts: 0, MaxBackoff: 10 * time.Second},
+			},
+			"",
+		},
+		{
+			"defaults_for_host_and_connections",
+			func(f *connectionForm) { f.Host, f.Connections = " ", "" },
+			hsm.ConnectOptions{
+				Host:           "localhost",
+				Port:           "1500",
+				Connections:    1,
+				CommandTimeout: 750 * time.Millisecond,
+				Reconnect:      hsm.ReconnectPolicy{MaxAttempts: 0, MaxBackoff: 10 * time.Second},
+			},
+			"",
+		},
+		{"bad_host", func(f *connectionForm) { f.Host = "10.0.0.300" }, hsm.ConnectOptions{}, "invalid HSM host"},
+		{"bad_port", func(f *connectionForm) { f.Port = "15000000" }, hsm.ConnectOptions{}, "invalid HSM port"},
+		{"bad_connections", func(f *connectionForm) { f.Connections = "0" }, hsm.ConnectOptions{}, "concurrent connections"},
+		{"timeout_not_numeric", func(f *connectionForm) { f.CommandTimeout = "5s" }, hsm.ConnectOptions{}, "command timeout"},
+		{"timeout_zero", func(f *connectionForm) { f.CommandTimeout = "0" }, hsm.ConnectOptions{}, "command timeout"},
+		{"negative_attempts", func(f *connectionForm) { f.ReconnectAttempts = "-1" }, hsm.ConnectOptions{}, "max reconnect attempts"},
+		{"zero_backoff", func(f *connectionForm) { f.MaxBackoff = "0" }, hsm.ConnectOptions{}, "max backoff"},
+		{"huge_backoff", func(f *connectionForm) { f.MaxBackoff = "86400" }, hsm.ConnectOptions{}, "max backoff"},
+		{
+			"pool_tuning",
+			func(f *connectionForm) { f.IdleTimeout, f.KeepAlive, f.ValidationInterval = "110", "20", "0" },
+			hsm.ConnectOptions{
+				Host:              "10.0.0.5",
+				Port:              "1500",
+				Connections:       4,
+				CommandTimeout:    750 * time.Millisecond,
+				Reconnect:         hsm.ReconnectPolicy{MaxAttempts: 0, MaxBackoff: 10 * time.Second},
+				IdleTimeout:       110 * time.Second,
+				KeepAliveInterval: 20 * time.Second,
+			},
+			"",
+		},
+		{"negative_idle", func(f *connectionForm) { f.IdleTimeout = "-5" }, hsm.ConnectOptions{}, "idle timeout"},
+		{"keepalive_not_numeric", func(f *connectionForm) { f.KeepAlive = "30s" }, hsm.ConnectOptions{}, "keep-alive interval"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := valid
+			tt.edit(&f)
+			got, err := f.options()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("options() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("options() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("options() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettings_ValidateAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		host     string
+		port     string
+		hostHint bool
+		portHint bool
+	}{
+		{"ipv4", "10.0.0.5", "1500", false, false},
+		{"hostname", "hsm-uat.example.com", "9998", false, false},
+		{"ipv6", "::1", "65535", false, false},
+		{"empty_host_is_localhost", "", "1500", false, false},
+		{"bad_ipv4", "10.0.0.300", "1500", true, false},
+		{"bad_hostname", "hsm_uat", "1500", true, false},
+		{"port_too_large", "localhost", "15000000", false, true},
+		{"port_zero", "localhost", "0", false, true},
+		{"port_pasted_text", "localhost", "15o0", false, true},
+		{"port_empty", "localhost", "", false, true},
+		{"both_bad", "10.0.0.300", "70000", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test.NewTempApp(t)
+			s := NewSettings(nil, nil)
+			s.hsmIP.SetText(tt.host)
+			s.hsmPort.SetText(tt.port)
+
+			if got := s.validateAddress(); got != (!tt.hostHint && !tt.portHint) {
+				t.Errorf("validateAddress() = %v", got)
+			}
+			if s.hostHint.Visible() != tt.hostHint || s.portHint.Visible() != tt.portHint {
+				t.Errorf("hints visible = %v/%v, want %v/%v",
+					s.hostHint.Visible(), s.portHint.Visible(), tt.hostHint, tt.portHint)
+			}
+			// Pasted values are kept as typed.
+			if s.hsmPort.Text != tt.port {
+				t.Errorf("port = %q, want %q", s.hsmPort.Text, tt.port)
+			}
+		})
+	}
+}
+
+func TestSettings_AddressHintsFollowTyping(t *testing.T) {
+	test.NewTempApp(t)
+	s := NewSettings(nil, nil)
+
+	s.hsmPort.SetText("99999")
+	if !s.portHint.Visible() {
+		t.Fatal("port hint hidden for an out of range port")
+	}
+	s.hsmPort.SetText("1500")
+	if s.portHint.Visible() {
+		t.Error("port hint still visible for a valid port")
+	}
+
+	// The host hint only appears on connect, then follows typing.
+	s.hsmIP.SetText("10.0.0.300")
+	if s.hostHint.Visible() {
+		t.Fatal("host hint shown before validation")
+	}
+	s.validateAddress()
+	s.hsmIP.SetText("10.0.0.30")
+	if s.hostHint.Visible() {
+		t.Error("host hint still visible for a valid host")
+	}
+}
+
+func TestSettings_FormToOptions(t *testing.T) {
+	test.NewTempApp(t)
+	s := NewSettings(nil, nil)
+
+	want := hsm.DefaultConnectOptions("localhost", "1500", 1)
+	got, err := s.form().options()
+	if err != nil || got != want {
+		t.Fatalf("default form options() = %+v, %v, want %+v", got, err, want)
+	}
+
+	s.hsmIP.SetText("uat-hsm")
+	s.hsmPort.SetText("9998")
+	s.concurrentConns.SetText("3")
+	s.commandTimeout.SetText("2500")
+	s.reconnectAttempts.SetText("0")
+	s.maxBackoff.SetText("60")
+	got, err = s.form().options()
+	if err != nil {
+		t.Fatalf("options() error = %v", err)
+	}
+	want = hsm.ConnectOptions{
+		Host:           "uat-hsm",
+		Port:           "9998",
+		Connections:    3,
+		CommandTimeout: 2500 * time.Millisecond,
+		Reconnect:      hsm.ReconnectPolicy{MaxAttempts: 0, MaxBackoff: time.Minute},
+	}
+	if got != want {
+		t.Errorf("options() = %+v, want %+v", got, want)
+	}
+
+	// The same values survive a round trip through a profile.
+	p := s.currentProfile("uat")
+	if p.CommandTimeoutMillis != 2500 || p.MaxReconnectAttempts == nil || *p.MaxReconnectAttempts != 0 ||
+		p.MaxBackoffSeconds != 60 {
+		t.Errorf("currentProfile() = %+v", p)
+	}
+	s.setProfileOptions(storage.ConnectionProfile{})
+	if s.commandTimeout.Text != "5000" || s.reconnectAttempts.Text != "5" || s.maxBackoff.Text != "30" {
+		t.Errorf("defaults = %q, %q, %q", s.commandTimeout.Text, s.reconnectAttempts.Text, s.maxBackoff.Text)
+	}
+	s.setProfileOptions(p)
+	if got, _ := s.form().options(); got.CommandTimeout != want.CommandTimeout || got.Reconnect != want.Reconnect {
+		t.Errorf("options() after profile = %+v, want %+v", got, want)
+	}
+}
+
+func TestHSMCommandSender_AdoptCommandTimeout(t *testing.T) {
+	test.NewTempApp(t)
+	hs := NewHSMCommandSender(nil, nil, nil, false)
+
+	hs.adoptCommandTimeout(2 * time.Second)
+	if hs.timeout.Text != "2000" {
+		t.Fatalf("timeout = %q, want the connection timeout", hs.timeout.Text)
+	}
+	hs.adoptCommandTimeout(3 * time.Second)
+	if hs.timeout.Text != "3000" {
+		t.Fatalf("timeout = %q, want the new connection timeout", hs.timeout.Text)
+	}
+
+	hs.timeout.SetText("400")
+	hs.adoptCommandTimeout(5 * time.Second)
+	if hs.timeout.Text != "400" {
+		t.Errorf("timeout = %q, want the user's value kept", hs.timeout.Text)
+	}
+}
+
+func TestTLSForm_Options(t *testing.T) {
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.pem")
+	cert := filepath.Join(dir, "client.pem")
+	key := filepath.Join(dir, "client.key")
+	for _, f := range []string{ca, cert, key} {
+		if err := os.WriteFile(f, []byte("pem"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		form    tlsForm
+		want    *hsm.TLSOptions
+		wantErr bool
+	}{
+		{"off", tlsForm{CAFile: ca}, nil, false},
+		{"system_roots", tlsForm{Enabled: true}, &hsm.TLSOptions{}, false},
+		{
+			"all_fields",
+			tlsForm{Enabled: true, CAFile: " " + ca, CertFile: cert, KeyFile: key, ServerName: "hsm.local", InsecureSkipVerify: true},
+			&hsm.TLSOptions{CAFile: ca, CertFile: cert, KeyFile: key, ServerName: "hsm.local", InsecureSkipVerify: true},
+			false,
+		},
+		{"missing_ca", tlsForm{Enabled: true, CAFile: filepath.Join(dir, "none.pem")}, nil, true},
+		{"missing_key_file", tlsForm{Enabled: true, CertFile: cert, KeyFile: filepath.Join(dir, "none.key")}, nil, true},
+		{"cert_without_key", tlsForm{Enabled: true, CertFile: cert}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.form.options()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("options() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("options() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettings_TLSFormToOptions(t *testing.T) {
+	test.NewTempApp(t)
+	s := NewSettings(nil, nil)
+
+	ca := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(ca, []byte("pem"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s.useTLS.SetChecked(true)
+	s.tlsCAFile.SetText(ca)
+	s.tlsServerName.SetText("hsm.local")
+	got, err := s.form().options()
+	if err != nil {
+		t.Fatalf("options() error = %v", err)
+	}
+	want := hsm.TLSOptions{CAFile: ca, ServerName: "hsm.local"}
+	if got.TLS == nil || *got.TLS != want {
+		t.Fatalf("options().TLS = %+v, want %+v", got.TLS, want)
+	}
+
+	// TLS settings survive a round trip through a profile.
+	p := s.currentProfile("tls")
+	s.setProfileOptions(storage.ConnectionProfile{})
+	if s.useTLS.Checked || s.tlsCAFile.Text != "" {
+		t.Fatalf("TLS fields not reset: use=%v ca=%q", s.useTLS.Checked, s.tlsCAFile.Text)
+	}
+	s.setProfileOptions(p)
+	if got, _ := s.form().options(); got.TLS == nil || *got.TLS != want {
+		t.Errorf("options().TLS after profile = %+v, want %+v", got.TLS, want)
+	}
+
+	s.tlsCAFile.SetText(ca + ".missing")
+	if _, err := s.form().options(); err == nil {
+		t.Error("options() with a missing CA file succeeded")
+	}
+}
+
+func TestSettings_TuningProfileRoundTrip(t *testing.T) {
+	test.NewTempApp(t)
+	s := NewSettings(nil, nil)
+
+	s.idleTimeout.SetText("110")
+	s.keepAliveInterval.SetText("20")
+	s.healthInterval.SetText("10")
+	if s.tuningNote.Visible() {
+		t.Errorf("tuning note shown for sound values: %q", s.tuningNote.Text)
+	}
+	p := s.currentProfile("firewall")
+	if p.IdleTimeoutSeconds != 110 || p.KeepAliveSeconds != 20 || p.ValidationIntervalSeconds != 0 ||
+		p.HealthCheckSeconds != 10 {
+		t.Fatalf("currentProfile() = %+v", p)
+	}
+
+	s.setProfileOptions(storage.ConnectionProfile{})
+	if s.idleTimeout.Text != "" || s.keepAliveInterval.Text != "" {
+		t.Fatalf("tuning not reset: %q, %q", s.idleTimeout.Text, s.keepAliveInterval.Text)
+	}
+	s.setProfileOptions(p)
+	got, err := s.form().options()
+	if err != nil {
+		t.Fatalf("options() error = %v", err)
+	}
+	if got.IdleTimeout != 110*time.Second || got.KeepAliveInterval != 20*time.Second || got.ValidationInterval != 0 ||
+		got.HealthCheckInterval != 10*time.Second {
+		t.Errorf("options() after profile = %+v", got)
+	}
+
+	// Keep-alives no more frequent than the idle timeout warn but still connect.
+	s.keepAliveInterval.SetText("110")
+	if !s.tuningNote.Visible() {
+		t.Error("tuning note hidden for keep-alive >= idle timeout")
+	}
+	if _, err := s.form().options(); err != nil {
+		t.Errorf("options() error = %v, want a warning only", err)
+	}
+}
+
+func TestAddConfigPrefs(t *testing.T) {
+	test.NewTempApp(t)
+	saved := configPrefs
+	t.Cleanup(func() { configPrefs = saved })
+
+	AddConfigPrefs(config.Pref{Key: "window.width", Kind: config.PrefInt})
+	prefs := NewSettings(nil, nil).configSources().Prefs
+	if len(prefs) != len(saved)+1 || prefs[len(prefs)-1].Key != "window.width" {
+		t.Errorf("exported prefs = %+v, want the tab prefs and window.width", prefs)
+	}
+}
+
+// sameColor reports whether a and b are the same colour, whatever their
+// types.
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func TestSettings_StatusFollowsTheme(t *testing.T) {
+	a := test.NewTempApp(t)
+	s := NewSettings(nil, nil)
+	test.NewTempWindow(t, s)
+
+	variant := a.Settings().ThemeVariant()
+	next := test.NewTheme()
+	before := a.Settings().Theme().Color(theme.ColorNameError, variant)
+	want := next.Color(theme.ColorNameError, variant)
+	if sameColor(before, want) {
+		t.Fatal("the themes must have different error colours")
+	}
+	if !sameColor(s.statusLED.FillColor, before) {
+		t.Fatalf("status colour = %v, want %v", s.statusLED.FillColor, before)
+	}
+
+	a.Settings().SetTheme(next)
+	if !sameColor(s.statusLED.FillColor, want) || !sameColor(s.statusText.Color, want) {
+		t.Errorf("status colour = %v, %v after a theme change, want %v", s.statusLED.FillColor, s.statusText.Color, want)
+	}
+}
+
+func TestConnectionStatus(t *testing.T) {
+	test.NewTempApp(t)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		state    hsm.ConnectionState
+		health   hsm.Health
+		wantText string
+		wantCol  fyne.ThemeColorName
+	}{
+		{"disconnected", hsm.Disconnected, hsm.Health{}, "Disconnected", theme.ColorNameError},
+		{"reconnecting", hsm.Reconnecting, hsm.Health{Enabled: true, Failures: 3}, "Disconnected", theme.ColorNameError},
+		{"checks_off", hsm.Connected, hsm.Health{}, "Connected", theme.ColorNameSuccess},
+		{"before_first_check", hsm.Connected, hsm.Health{Enabled: true, LastResponse: now}, "Connected", theme.ColorNameSuccess},
+		{
+			"answered",
+			hsm.Connected,
+			hsm.Health{Enabled: true, RTT: 12400 * time.Microsecond, LastResponse: now},
+			"Connected · 12 ms",
+			theme.ColorNameSuccess,
+		},
+		{
+			"fast_answer",
+			hsm.Connected,
+			hsm.Health{Enabled: true, RTT: 300 * time.Microsecond, LastResponse: now},
+			"Connected · <1 ms",
+			theme.ColorNameSuccess,
+		},
+		{
+			"degraded",
+			hsm.Connected,
+			hsm.Health{Enabled: true, RTT: 12 * time.Millisecond, LastResponse: now.Add(-30500 * time.Millisecond), Failures: 2},
+			"Degraded · no response 30s",
+			theme.ColorNameWarning,
+		},
+		{
+			"degraded_without_answer",
+			hsm.Connected,
+			hsm.Health{Enabled: true, LastResponse: now.Add(-10 * time.Second), Failures: 1},
+			"Degraded · no response 10s",
+			theme.ColorNameWarning,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, col := connectionStatus(tt.state, tt.health, now)
+			if text != tt.wantText || col != tt.wantCol {
+				t.Errorf("connectionStatus() = %q, %v, want %q, %v", text, col, tt.wantText, tt.wantCol)
+			}
+		})
+	}
+}
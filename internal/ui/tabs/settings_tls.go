@@ -0,0 +1,186 @@
+package tabs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/hsm"
+	"github.com/andrei-cloud/hsmtool/internal/backend/storage"
+)
+
+// tlsForm holds the Settings TLS fields as typed.
+type tlsForm struct {
+	Enabled            bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// options validates the TLS fields and returns the TLS options they
+// describe, or nil when TLS is off. The files must exist.
+func (f tlsForm) options() (*hsm.TLSOptions, error) {
+	if !f.Enabled {
+		return nil, nil
+	}
+
+	opts := &hsm.TLSOptions{
+		CAFile:             strings.TrimSpace(f.CAFile),
+		CertFile:           strings.TrimSpace(f.CertFile),
+		KeyFile:            strings.TrimSpace(f.KeyFile),
+		ServerName:         strings.TrimSpace(f.ServerName),
+		InsecureSkipVerify: f.InsecureSkipVerify,
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("TLS client certificate and key must be given together")
+	}
+	for _, file := range []struct{ name, path string }{
+		{"CA certificate", opts.CAFile},
+		{"client certificate", opts.CertFile},
+		{"client key", opts.KeyFile},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			return nil, fmt.Errorf("TLS %s: %v", file.name, err)
+		}
+	}
+
+	return opts, nil
+}
+
+// initializeTLS creates the TLS fields, hidden until TLS is turned on.
+func (s *Settings) initializeTLS() {
+	s.tlsCAFile = widget.NewEntry()
+	s.tlsCAFile.SetPlaceHolder("System roots when empty")
+	s.tlsCertFile = widget.NewEntry()
+	s.tlsCertFile.SetPlaceHolder("Optional")
+	s.tlsKeyFile = widget.NewEntry()
+	s.tlsKeyFile.SetPlaceHolder("Required with a client certificate")
+	s.tlsServerName = widget.NewEntry()
+	s.tlsServerName.SetPlaceHolder("Defaults to the HSM host")
+	s.tlsInsecure = widget.NewCheck("Skip server certificate verification", s.onTLSInsecureToggled)
+
+	s.tlsBrowseBtns = nil
+	withBrowse := func(e *widget.Entry) fyne.CanvasObject {
+		btn := widget.NewButtonWithIcon("", theme.FolderOpenIcon(), func() { s.browseTLSFile(e) })
+		s.tlsBrowseBtns = append(s.tlsBrowseBtns, btn)
+
+		return container.NewBorder(nil, nil, nil, btn, e)
+	}
+
+	// A separate form, since a form does not hide the labels of hidden items.
+	s.tlsFields = container.NewVBox(widget.NewForm(
+		widget.NewFormItem("CA Certificate", withBrowse(s.tlsCAFile)),
+		widget.NewFormItem("Client Certificate", withBrowse(s.tlsCertFile)),
+		widget.NewFormItem("Client Key", withBrowse(s.tlsKeyFile)),
+		widget.NewFormItem("Server Name", s.tlsServerName),
+		widget.NewFormItem("", s.tlsInsecure),
+	))
+	s.tlsFields.Hide()
+
+	s.useTLS = widget.NewCheck("Use TLS", func(on bool) {
+		if on {
+			s.tlsFields.Show()
+		} else {
+			s.tlsFields.Hide()
+		}
+	})
+}
+
+// browseTLSFile lets the user pick a PEM file for e.
+func (s *Settings) browseTLSFile(e *widget.Entry) {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	dialog.ShowFileOpen(func(r fyne.URIReadCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if r == nil {
+			return
+		}
+		defer r.Close()
+		e.SetText(r.URI().Path())
+	}, w)
+}
+
+// onTLSInsecureToggled asks for confirmation before certificate verification
+// is turned off, and unchecks the box when declined.
+func (s *Settings) onTLSInsecureToggled(on bool) {
+	if !on {
+		return
+	}
+
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	dialog.ShowConfirm(
+		"Skip Certificate Verification",
+		"The HSM certificate will not be checked, so anyone on the network path "+
+			"can impersonate the HSM and read commands and keys.\n\nUse only for testing. Continue?",
+		func(ok bool) {
+			if !ok {
+				s.tlsInsecure.SetChecked(false)
+			}
+		},
+		w,
+	)
+}
+
+// tlsForm returns the TLS fields as typed.
+func (s *Settings) tlsForm() tlsForm {
+	return tlsForm{
+		Enabled:            s.useTLS.Checked,
+		CAFile:             s.tlsCAFile.Text,
+		CertFile:           s.tlsCertFile.Text,
+		KeyFile:            s.tlsKeyFile.Text,
+		ServerName:         s.tlsServerName.Text,
+		InsecureSkipVerify: s.tlsInsecure.Checked,
+	}
+}
+
+// setProfileTLS fills the TLS fields from p.
+func (s *Settings) setProfileTLS(p storage.ConnectionProfile) {
+	s.useTLS.SetChecked(p.UseTLS)
+	s.tlsCAFile.SetText(p.TLSCAFile)
+	s.tlsCertFile.SetText(p.TLSCertFile)
+	s.tlsKeyFile.SetText(p.TLSKeyFile)
+	s.tlsServerName.SetText(p.TLSServerName)
+	// The profile was confirmed when saved; bypass the confirmation.
+	s.tlsInsecure.Checked = p.TLSInsecureSkipVerify
+	s.tlsInsecure.Refresh()
+}
+
+// profileTLS stores the TLS fields in p.
+func (s *Settings) profileTLS(p *storage.ConnectionProfile) {
+	f := s.tlsForm()
+	p.UseTLS = f.Enabled
+	p.TLSCAFile = strings.TrimSpace(f.CAFile)
+	p.TLSCertFile = strings.TrimSpace(f.CertFile)
+	p.TLSKeyFile = strings.TrimSpace(f.KeyFile)
+	p.TLSServerName = strings.TrimSpace(f.ServerName)
+	p.TLSInsecureSkipVerify = f.InsecureSkipVerify
+}
+
+// showTLSConnected locks the TLS fields while connected.
+func (s *Settings) showTLSConnected(connected bool) {
+	widgets := []fyne.Disableable{s.useTLS, s.tlsCAFile, s.tlsCertFile, s.tlsKeyFile, s.tlsServerName, s.tlsInsecure}
+	for _, btn := range s.tlsBrowseBtns {
+		widgets = append(widgets, btn)
+	}
+	for _, w := range widgets {
+		if connected {
+			w.Disable()
+		} else {
+			w.Enable()
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package tabs
+
+import (
+	"slices"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// sparklineHeight is the minimum height of a sparkline.
+const sparklineHeight = 40
+
+// sparkline draws a series of counts as connected line segments scaled to
+// the largest value. The newest value is drawn at the right edge and slots
+// holds the number of values that fit across the width.
+type sparkline struct {
+	widget.BaseWidget
+	slots  int
+	values []int
+}
+
+// newSparkline creates an empty sparkline showing up to slots values.
+func newSparkline(slots int) *sparkline {
+	s := &sparkline{slots: max(slots, 2)}
+	s.ExtendBaseWidget(s)
+
+	return s
+}
+
+// SetValues replaces the values drawn, keeping the most recent slots.
+func (s *sparkline) SetValues(values []int) {
+	if len(values) > s.slots {
+		values = values[len(values)-s.slots:]
+	}
+	s.values = slices.Clone(values)
+	s.Refresh()
+}
+
+// CreateRenderer implements fyne.Widget.
+func (s *sparkline) CreateRenderer() fyne.WidgetRenderer {
+	r := &sparklineRenderer{s: s, baseline: canvas.NewLine(theme.Color(theme.ColorNameSeparator))}
+	r.lines = make([]*canvas.Line, s.slots-1)
+	for i := range r.lines {
+		r.lines[i] = canvas.NewLine(theme.Color(theme.ColorNamePrimary))
+		r.lines[i].StrokeWidth = 1.5
+	}
+	r.objects = []fyne.CanvasObject{r.baseline}
+	for _, l := range r.lines {
+		r.objects = append(r.objects, l)
+	}
+
+	return r
+}
+
+// sparklineRenderer reuses one line per pair of adjacent slots.
+type sparklineRenderer struct {
+	s        *sparkline
+	baseline *canvas.Line
+	lines    []*canvas.Line
+	objects  []fyne.CanvasObject
+	size     fyne.Size
+}
+
+func (r *sparklineRenderer) Layout(size fyne.Size) {
+	r.size = size
+	r.draw()
+}
+
+func (r *sparklineRenderer) MinSize() fyne.Size {
+	return fyne.NewSize(0, sparklineHeight)
+}
+
+func (r *sparklineRenderer) Refresh() {
+	r.baseline.StrokeColor = theme.Color(theme.ColorNameSeparator)
+	for _, l := range r.lines {
+		l.StrokeColor = theme.Color(theme.ColorNamePrimary)
+	}
+	r.draw()
+	canvas.Refresh(r.s)
+}
+
+// draw positions the segments for the current values and size.
+func (r *sparklineRenderer) draw() {
+	w, h := r.size.Width, r.size.Height
+	r.baseline.Position1 = fyne.NewPos(0, h)
+	r.baseline.Position2 = fyne.NewPos(w, h)
+
+	values := r.s.values
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	step := w / float32(r.s.slots-1)
+	// The first value sits in this slot so the newest one is at the right edge.
+	first := r.s.slots - len(values)
+	point := func(i int) fyne.Position {
+		y := h
+		if peak > 0 {
+			y = h - h*float32(values[i])/float32(peak)
+		}
+
+		return fyne.NewPos(float32(first+i)*step, y)
+	}
+
+	for i, l := range r.lines {
+		// Line i joins values i and i+1 of the visible series.
+		if i+1 >= len(values) {
+			l.Hide()
+			continue
+		}
+		l.Position1, l.Position2 = point(i), point(i+1)
+		l.Show()
+	}
+}
+
+func (r *sparklineRenderer) Objects() []fyne.CanvasObject {
+	return r.objects
+}
+
+func (r *sparklineRenderer) Destroy() {}
@@ -0,0 +1,277 @@
+package tabs
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/andrei-cloud/hsmtool/pkg/logger"
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+// testDataColumns are the columns of the generated data table.
+var testDataColumns = []struct {
+	Title string
+	Width float32
+}{
+	{"PAN", 180},
+	{"Expiry", 70},
+	{"Service Code", 110},
+	{"Track 2", 300},
+	{"KSN", 200},
+	{"Hex", 200},
+}
+
+// testDataCell returns the text of column col for c.
+func testDataCell(c utils.TestCard, col int) string {
+	switch col {
+	case 0:
+		return c.PAN
+	case 1:
+		return c.Expiry
+	case 2:
+		return c.ServiceCode
+	case 3:
+		return c.Track2
+	case 4:
+		return c.KSN
+	case 5:
+		return c.Hex
+	}
+
+	return ""
+}
+
+// testDataForm holds the generator fields.
+type testDataForm struct {
+	BIN, PANLength, ExpiryMonths, HexLength, Count string
+}
+
+// options parses the fields. Expiry dates start with the month of now.
+func (f testDataForm) options(now time.Time) (utils.TestCardOptions, int, error) {
+	number := func(name, s string) (int, error) {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number, got %q", name, s)
+		}
+
+		return n, nil
+	}
+
+	opts := utils.TestCardOptions{BIN: strings.TrimSpace(f.BIN), ExpiryFrom: now}
+	var err error
+	if opts.PANLength, err = number("PAN length", f.PANLength); err != nil {
+		return opts, 0, err
+	}
+	if opts.ExpiryMonths, err = number("expiry window", f.ExpiryMonths); err != nil {
+		return opts, 0, err
+	}
+	if opts.HexLength, err = number("hex length", f.HexLength); err != nil {
+		return opts, 0, err
+	}
+	count, err := number("count", f.Count)
+	if err != nil {
+		return opts, 0, err
+	}
+
+	return opts, count, nil
+}
+
+// TestDataGenerator represents the Test Data Generator tab.
+type TestDataGenerator struct {
+	widget.BaseWidget
+	container *fyne.Container
+
+	bin          *widget.Entry
+	panLength    *widget.Entry
+	expiryMonths *widget.Entry
+	hexLength    *widget.Entry
+	count        *widget.Entry
+	errMsg       *widget.Label
+	summary      *widget.Label
+	exportBtn    *widget.Button
+	table        *widget.Table
+
+	cards []utils.TestCard
+}
+
+// NewTestDataGenerator creates a new Test Data Generator tab.
+func NewTestDataGenerator() *TestDataGenerator {
+	g := &TestDataGenerator{}
+	g.ExtendBaseWidget(g)
+
+	newEntry := func(text, placeholder string) *widget.Entry {
+		e := widget.NewEntry()
+		e.SetText(text)
+		e.SetPlaceHolder(placeholder)
+
+		return e
+	}
+	g.bin = newEntry("999999", "Leading PAN digits")
+	g.panLength = newEntry("16", "12 to 19")
+	g.expiryMonths = newEntry("36", "Months from now")
+	g.hexLength = newEntry("8", "Bytes")
+	g.count = newEntry("10", "")
+
+	g.errMsg = widget.NewLabel("")
+	g.errMsg.Importance = widget.DangerImportance
+	g.errMsg.Wrapping = fyne.TextWrapWord
+	g.errMsg.Hide()
+	g.summary = widget.NewLabel("")
+
+	warning := widget.NewLabelWithStyle(utils.TestDataLabel, fyne.TextAlignCenter, fyne.TextStyle{Bold: true})
+	warning.Importance = widget.WarningImportance
+
+	g.exportBtn = widget.NewButton("Export CSV…", g.onExport)
+	g.exportBtn.Disable()
+	g.initializeTable()
+
+	form := widget.NewForm(
+		widget.NewFormItem("BIN", g.bin),
+		widget.NewFormItem("PAN Length", g.panLength),
+		widget.NewFormItem("Expiry Window", g.expiryMonths),
+		widget.NewFormItem("Hex Length", g.hexLength),
+		widget.NewFormItem("Count", g.countSpinner()),
+	)
+
+	g.container = container.NewBorder(
+		container.NewVBox(
+			warning,
+			form,
+			container.NewHBox(widget.NewButton("Generate", g.onGenerate), g.exportBtn, g.summary),
+			g.errMsg,
+		),
+		nil, nil, nil,
+		g.table,
+	)
+
+	return g
+}
+
+// stepCount adds delta to the count in text, keeping it within the batch
+// limits.
+func stepCount(text string, delta int) string {
+	n, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil {
+		n = 0
+	}
+
+	return strconv.Itoa(min(max(n+delta, 1), utils.MaxTestCards))
+}
+
+// countSpinner lays out the count field with step buttons.
+func (g *TestDataGenerator) countSpinner() fyne.CanvasObject {
+	spin := func(delta int) func() {
+		return func() { g.count.SetText(stepCount(g.count.Text, delta)) }
+	}
+
+	return container.NewBorder(nil, nil, nil,
+		container.NewVBox(widget.NewButton("▲", spin(1)), widget.NewButton("▼", spin(-1))),
+		g.count,
+	)
+}
+
+// initializeTable creates the table of generated data.
+func (g *TestDataGenerator) initializeTable() {
+	g.table = widget.NewTable(
+		func() (int, int) { return len(g.cards), len(testDataColumns) },
+		func() fyne.CanvasObject {
+			return widget.NewLabelWithStyle("Template", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+		},
+		func(id widget.TableCellID, obj fyne.CanvasObject) {
+			if id.Row >= len(g.cards) {
+				return
+			}
+			obj.(*widget.Label).SetText(testDataCell(g.cards[id.Row], id.Col))
+		},
+	)
+	g.table.ShowHeaderRow = true
+	g.table.CreateHeader = func() fyne.CanvasObject {
+		return widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+	}
+	g.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		if id.Col >= 0 && id.Col < len(testDataColumns) {
+			obj.(*widget.Label).SetText(testDataColumns[id.Col].Title)
+		}
+	}
+	for i, c := range testDataColumns {
+		g.table.SetColumnWidth(i, c.Width)
+	}
+}
+
+// onGenerate replaces the table with a new batch read from crypto/rand.
+func (g *TestDataGenerator) onGenerate() {
+	opts, count, err := testDataForm{
+		BIN:          g.bin.Text,
+		PANLength:    g.panLength.Text,
+		ExpiryMonths: g.expiryMonths.Text,
+		HexLength:    g.hexLength.Text,
+		Count:        g.count.Text,
+	}.options(time.Now())
+	var cards []utils.TestCard
+	if err == nil {
+		cards, err = utils.GenerateTestCards(rand.Reader, opts, count)
+	}
+	showHint(g.errMsg, err)
+	if err != nil {
+		logger.Error("test_data_generate", "Failed", err.Error())
+		return
+	}
+
+	g.setCards(cards)
+	logger.Info("test_data_generate", "Success", fmt.Sprintf("%d cards, BIN %s", len(cards), opts.BIN))
+}
+
+// setCards shows cards in the table.
+func (g *TestDataGenerator) setCards(cards []utils.TestCard) {
+	g.cards = cards
+	g.summary.SetText("")
+	if len(cards) > 0 {
+		g.summary.SetText(fmt.Sprintf("%d rows", len(cards)))
+	}
+	setEnabled(g.exportBtn, len(cards) > 0)
+	g.table.Refresh()
+}
+
+// onExport writes the generated data to a CSV file.
+func (g *TestDataGenerator) onExport() {
+	w := fyne.CurrentApp().Driver().AllWindows()[0]
+	cards := g.cards
+
+	save := dialog.NewFileSave(func(wc fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.ShowError(err, w)
+			return
+		}
+		if wc == nil {
+			return // Cancelled.
+		}
+		defer wc.Close()
+
+		if err := utils.WriteTestCardsCSV(wc, cards); err != nil {
+			dialog.ShowError(fmt.Errorf("failed to write %s: %v", wc.URI().Name(), err), w)
+			return
+		}
+		logger.Info("test_data_export", "Success", fmt.Sprintf("%d cards to %s", len(cards), wc.URI().Name()))
+	}, w)
+	save.SetFileName("hsmtool-test-data.csv")
+	save.Show()
+}
+
+// CreateRenderer implements fyne.Widget interface.
+func (g *TestDataGenerator) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(g.container)
+}
+
+// Cleanup implements TabContent interface.
+func (g *TestDataGenerator) Cleanup() {
+	g.setCards(nil)
+	showHint(g.errMsg, nil)
+}
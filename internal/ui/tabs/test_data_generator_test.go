@@ -0,0 +1,98 @@
+// nolint:all // test package
+package tabs
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/test"
+
+	"github.com/andrei-cloud/hsmtool/pkg/utils"
+)
+
+func TestTestDataForm_Options(t *testing.T) {
+	now := time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		form    testDataForm
+		want    utils.TestCardOptions
+		count   int
+		wantErr string
+	}{
+		{
+			name:  "valid",
+			form:  testDataForm{" 411111 ", "16", "24", "8", " 50 "},
+			want:  utils.TestCardOptions{BIN: "411111", PANLength: 16, ExpiryFrom: now, ExpiryMonths: 24, HexLength: 8},
+			count: 50,
+		},
+		{"bad_pan_length", testDataForm{"4", "x", "24", "8", "1"}, utils.TestCardOptions{}, 0, "PAN length must be a number"},
+		{"bad_window", testDataForm{"4", "16", "", "8", "1"}, utils.TestCardOptions{}, 0, "expiry window must be a number"},
+		{"bad_hex", testDataForm{"4", "16", "24", "8.5", "1"}, utils.TestCardOptions{}, 0, "hex length must be a number"},
+		{"bad_count", testDataForm{"4", "16", "24", "8", "ten"}, utils.TestCardOptions{}, 0, "count must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count, err := tt.form.options(now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("options() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want || count != tt.count {
+				t.Errorf("options() = %+v, %d, %v, want %+v, %d", got, count, err, tt.want, tt.count)
+			}
+		})
+	}
+}
+
+func TestTestDataGenerator_Generate(t *testing.T) {
+	test.NewTempApp(t)
+	g := NewTestDataGenerator()
+
+	g.count.SetText("25")
+	g.onGenerate()
+	if len(g.cards) != 25 || g.errMsg.Visible() || g.exportBtn.Disabled() {
+		t.Fatalf("generated %d cards, error %q, export disabled %v", len(g.cards), g.errMsg.Text, g.exportBtn.Disabled())
+	}
+	for _, c := range g.cards {
+		if !strings.HasPrefix(c.PAN, "999999") || !utils.LuhnValid(c.PAN) {
+			t.Errorf("PAN %s is not a Luhn-valid test PAN", c.PAN)
+		}
+	}
+
+	// An invalid field keeps the previous batch.
+	g.bin.SetText("4111111111111111")
+	g.onGenerate()
+	if !g.errMsg.Visible() || len(g.cards) != 25 {
+		t.Errorf("invalid BIN: error shown %v, %d cards", g.errMsg.Visible(), len(g.cards))
+	}
+
+	g.Cleanup()
+	if len(g.cards) != 0 || g.errMsg.Visible() || !g.exportBtn.Disabled() {
+		t.Error("Cleanup() left generated data behind")
+	}
+}
+
+func TestStepCount(t *testing.T) {
+	tests := []struct {
+		text  string
+		delta int
+		want  string
+	}{
+		{"1", 1, "2"},
+		{" 10 ", -1, "9"},
+		{"1", -1, "1"},
+		{"abc", 1, "1"},
+		{"", -1, "1"},
+		{"10000", 1, "10000"},
+		{"20000", -1, "10000"},
+	}
+	for _, tt := range tests {
+		if got := stepCount(tt.text, tt.delta); got != tt.want {
+			t.Errorf("stepCount(%q, %d) = %q, want %q", tt.text, tt.delta, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/theme"
+)
+
+// prefTheme is the preference key of the chosen theme.
+const prefTheme = "ui.theme"
+
+// themeChoice is a theme offered in the View menu.
+type themeChoice string
+
+const (
+	themeSystem themeChoice = "system"
+	themeLight  themeChoice = "light"
+	themeDark   themeChoice = "dark"
+)
+
+// themeChoices are the choices in menu order.
+var themeChoices = []themeChoice{themeSystem, themeLight, themeDark}
+
+// parseThemeChoice returns the choice named s, or themeSystem when s names
+// none.
+func parseThemeChoice(s string) themeChoice {
+	switch c := themeChoice(s); c {
+	case themeLight, themeDark:
+		return c
+	default:
+		return themeSystem
+	}
+}
+
+// label returns the menu label of c.
+func (c themeChoice) label() string {
+	switch c {
+	case themeLight:
+		return "Light"
+	case themeDark:
+		return "Dark"
+	default:
+		return "System"
+	}
+}
+
+// theme returns the Fyne theme of c. The system choice follows the
+// variant of the operating system.
+func (c themeChoice) theme() fyne.Theme {
+	switch c {
+	case themeLight:
+		return &variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantLight}
+	case themeDark:
+		return &variantTheme{Theme: theme.DefaultTheme(), variant: theme.VariantDark}
+	default:
+		return theme.DefaultTheme()
+	}
+}
+
+// variantTheme is a theme fixed to one variant.
+type variantTheme struct {
+	fyne.Theme
+	variant fyne.ThemeVariant
+}
+
+// Color implements fyne.Theme interface, ignoring the system variant.
+func (t *variantTheme) Color(name fyne.ThemeColorName, _ fyne.ThemeVariant) color.Color {
+	return t.Theme.Color(name, t.variant)
+}
+
+// themePrefs is the subset of fyne.Preferences holding the theme choice.
+type themePrefs interface {
+	StringWithFallback(key, fallback string) string
+	SetString(key, value string)
+}
+
+// loadThemeChoice returns the saved choice, or themeSystem.
+func loadThemeChoice(prefs themePrefs) themeChoice {
+	return parseThemeChoice(prefs.StringWithFallback(prefTheme, string(themeSystem)))
+}
+
+// themeMenu returns the View menu choosing the theme of a, starting with
+// the saved choice. A choice is applied and saved at once.
+func themeMenu(a fyne.App) *fyne.Menu {
+	menu := fyne.NewMenu("View")
+	choose := func(c themeChoice) {
+		a.Settings().SetTheme(c.theme())
+		a.Preferences().SetString(prefTheme, string(c))
+		for i, item := range menu.Items {
+			item.Checked = themeChoices[i] == c
+		}
+		menu.Refresh()
+	}
+	for _, c := range themeChoices {
+		menu.Items = append(menu.Items, fyne.NewMenuItem(c.label()+" Theme", func() { choose(c) }))
+	}
+	choose(loadThemeChoice(a.Preferences()))
+
+	return menu
+}
@@ -0,0 +1,99 @@
+// nolint:all // test package
+package ui
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/theme"
+)
+
+func TestParseThemeChoice(t *testing.T) {
+	tests := []struct {
+		name      string
+		want      themeChoice
+		wantLabel string
+	}{
+		{"system", themeSystem, "System"},
+		{"light", themeLight, "Light"},
+		{"dark", themeDark, "Dark"},
+		{"", themeSystem, "System"},
+		{"Dark", themeSystem, "System"},
+		{"solarized", themeSystem, "System"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseThemeChoice(tt.name)
+			if got != tt.want || got.label() != tt.wantLabel {
+				t.Errorf("parseThemeChoice(%q) = %q (%s), want %q (%s)", tt.name, got, got.label(), tt.want, tt.wantLabel)
+			}
+		})
+	}
+}
+
+func TestThemeChoice_Theme(t *testing.T) {
+	tests := []struct {
+		choice      themeChoice
+		asked, want fyne.ThemeVariant
+	}{
+		{themeLight, theme.VariantDark, theme.VariantLight},
+		{themeLight, theme.VariantLight, theme.VariantLight},
+		{themeDark, theme.VariantLight, theme.VariantDark},
+		{themeDark, theme.VariantDark, theme.VariantDark},
+		{themeSystem, theme.VariantLight, theme.VariantLight},
+		{themeSystem, theme.VariantDark, theme.VariantDark},
+	}
+
+	def := theme.DefaultTheme()
+	for _, tt := range tests {
+		want := def.Color(theme.ColorNameBackground, tt.want)
+		if got := tt.choice.theme().Color(theme.ColorNameBackground, tt.asked); got != want {
+			t.Errorf("%s theme background in variant %d = %v, want %v", tt.choice, tt.asked, got, want)
+		}
+	}
+}
+
+func TestThemeMenu(t *testing.T) {
+	a := test.NewTempApp(t)
+
+	// Nothing saved follows the system.
+	menu := themeMenu(a)
+	if len(menu.Items) != len(themeChoices) || !menu.Items[0].Checked {
+		t.Fatalf("menu items = %d, system checked %v", len(menu.Items), menu.Items[0].Checked)
+	}
+
+	// A choice is applied, checked and saved.
+	menu.Items[2].Action()
+	if got := a.Preferences().String(prefTheme); got != string(themeDark) {
+		t.Errorf("saved theme = %q, want %q", got, themeDark)
+	}
+	if vt, ok := a.Settings().Theme().(*variantTheme); !ok || vt.variant != theme.VariantDark {
+		t.Errorf("applied theme = %T, want the dark theme", a.Settings().Theme())
+	}
+	if menu.Items[0].Checked || !menu.Items[2].Checked {
+		t.Error("only the dark theme should be checked")
+	}
+
+	// The saved choice is restored by the next menu.
+	menu = themeMenu(a)
+	if !menu.Items[2].Checked || loadThemeChoice(a.Preferences()) != themeDark {
+		t.Error("dark theme not restored")
+	}
+}
+
+func TestLoadThemeChoice(t *testing.T) {
+	prefs := newMemPrefs()
+	if got := loadThemeChoice(prefs); got != themeSystem {
+		t.Errorf("loadThemeChoice() with nothing saved = %q, want system", got)
+	}
+	prefs.SetString(prefTheme, "light")
+	if got := loadThemeChoice(prefs); got != themeLight {
+		t.Errorf("loadThemeChoice() = %q, want light", got)
+	}
+	prefs.SetString(prefTheme, "neon")
+	if got := loadThemeChoice(prefs); got != themeSystem {
+		t.Errorf("loadThemeChoice() with an unknown theme = %q, want system", got)
+	}
+}
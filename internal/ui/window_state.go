@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+)
+
+// Preference keys of the main window state.
+const (
+	prefWindowWidth  = "window.width"
+	prefWindowHeight = "window.height"
+	prefSelectedTab  = "window.selected_tab"
+)
+
+// minWindowSize is the smallest window size that is restored.
+var minWindowSize = fyne.NewSize(640, 480)
+
+// sizeSaveDelay is how long the window must keep a size before it is saved.
+const sizeSaveDelay = 500 * time.Millisecond
+
+// windowPrefs is the subset of fyne.Preferences holding the window state.
+type windowPrefs interface {
+	IntWithFallback(key string, fallback int) int
+	SetInt(key string, value int)
+}
+
+// windowState saves and restores the main window size and selected tab.
+type windowState struct {
+	prefs windowPrefs
+	delay time.Duration
+
+	mu    sync.Mutex
+	timer *time.Timer
+	last  fyne.Size
+}
+
+// newWindowState returns a window state kept in prefs.
+func newWindowState(prefs windowPrefs) *windowState {
+	return &windowState{prefs: prefs, delay: sizeSaveDelay}
+}
+
+// Size returns the saved window size, or def when none is saved or the
+// saved size is smaller than minWindowSize or larger than screen. A zero
+// screen size is unknown and does not bound the size.
+func (s *windowState) Size(def, screen fyne.Size) fyne.Size {
+	size := fyne.NewSize(
+		float32(s.prefs.IntWithFallback(prefWindowWidth, 0)),
+		float32(s.prefs.IntWithFallback(prefWindowHeight, 0)),
+	)
+	if size.Width < minWindowSize.Width || size.Height < minWindowSize.Height {
+		return def
+	}
+	if !screen.IsZero() && (size.Width > screen.Width || size.Height > screen.Height) {
+		return def
+	}
+
+	return size
+}
+
+// SaveSize saves size, rounded to whole units, and cancels a pending save.
+func (s *windowState) SaveSize(size fyne.Size) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.save(size)
+}
+
+// Resized saves size once the window has kept it for the save delay, so
+// dragging a window edge does not write preferences on every step.
+func (s *windowState) Resized(size fyne.Size) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if size == s.last {
+		return
+	}
+	s.last = size
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(s.delay, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.save(size)
+	})
+}
+
+// save writes size to the preferences. The caller holds mu.
+func (s *windowState) save(size fyne.Size) {
+	s.last = size
+	s.prefs.SetInt(prefWindowWidth, int(math.Round(float64(size.Width))))
+	s.prefs.SetInt(prefWindowHeight, int(math.Round(float64(size.Height))))
+}
+
+// Tab returns the saved index of the selected tab, or 0 when it is not one
+// of count tabs.
+func (s *windowState) Tab(count int) int {
+	i := s.prefs.IntWithFallback(prefSelectedTab, 0)
+	if i < 0 || i >= count {
+		return 0
+	}
+
+	return i
+}
+
+// SaveTab saves the index of the selected tab.
+func (s *windowState) SaveTab(i int) {
+	s.prefs.SetInt(prefSelectedTab, i)
+}
+
+// resizeLayout stacks its objects to fill the container and calls
+// onLayout after each layout, which follows every window resize when the
+// container is the window content.
+type resizeLayout struct {
+	onLayout func()
+}
+
+// Layout implements fyne.Layout interface.
+func (l *resizeLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	for _, o := range objects {
+		o.Move(fyne.NewPos(0, 0))
+		o.Resize(size)
+	}
+	l.onLayout()
+}
+
+// MinSize implements fyne.Layout interface.
+func (l *resizeLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	var size fyne.Size
+	for _, o := range objects {
+		size = size.Max(o.MinSize())
+	}
+
+	return size
+}
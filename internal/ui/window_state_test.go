@@ -0,0 +1,156 @@
+// nolint:all // test package
+package ui
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+
+	"github.com/andrei-cloud/hsmtool/internal/backend/config"
+)
+
+// memPrefs keeps preferences in memory.
+type memPrefs struct {
+	mu sync.Mutex
+	m  map[string]any
+}
+
+func newMemPrefs() *memPrefs { return &memPrefs{m: map[string]any{}} }
+
+func (p *memPrefs) IntWithFallback(k string, f int) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.m[k].(int); ok {
+		return v
+	}
+	return f
+}
+
+func (p *memPrefs) SetInt(k string, v int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.m[k] = v
+}
+
+func (p *memPrefs) StringWithFallback(k, f string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if v, ok := p.m[k].(string); ok {
+		return v
+	}
+	return f
+}
+
+func (p *memPrefs) SetString(k, v string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.m[k] = v
+}
+
+func TestWindowState_Size(t *testing.T) {
+	def := fyne.NewSize(1024, 768)
+	screen := fyne.NewSize(1920, 1080)
+
+	tests := []struct {
+		name          string
+		width, height int
+		saved         bool
+		screen        fyne.Size
+		want          fyne.Size
+	}{
+		{"nothing_saved", 0, 0, false, screen, def},
+		{"saved", 1280, 800, true, screen, fyne.NewSize(1280, 800)},
+		{"minimum", 640, 480, true, screen, fyne.NewSize(640, 480)},
+		{"screen_sized", 1920, 1080, true, screen, fyne.NewSize(1920, 1080)},
+		{"too_narrow", 639, 800, true, screen, def},
+		{"too_short", 1280, 479, true, screen, def},
+		{"wider_than_screen", 1921, 800, true, screen, def},
+		{"taller_than_screen", 1280, 1081, true, screen, def},
+		{"negative", -1280, -800, true, screen, def},
+		{"unknown_screen", 3840, 2160, true, fyne.Size{}, fyne.NewSize(3840, 2160)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := newMemPrefs()
+			if tt.saved {
+				prefs.SetInt(prefWindowWidth, tt.width)
+				prefs.SetInt(prefWindowHeight, tt.height)
+			}
+			if got := newWindowState(prefs).Size(def, tt.screen); got != tt.want {
+				t.Errorf("Size() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowState_Tab(t *testing.T) {
+	tests := []struct {
+		name  string
+		saved int
+		count int
+		want  int
+	}{
+		{"first", 0, 5, 0},
+		{"last", 4, 5, 4},
+		{"removed_tab", 5, 5, 0},
+		{"negative", -1, 5, 0},
+		{"no_tabs", 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newWindowState(newMemPrefs())
+			s.SaveTab(tt.saved)
+			if got := s.Tab(tt.count); got != tt.want {
+				t.Errorf("Tab(%d) = %d, want %d", tt.count, got, tt.want)
+			}
+		})
+	}
+
+	if got := newWindowState(newMemPrefs()).Tab(5); got != 0 {
+		t.Errorf("Tab() with nothing saved = %d, want 0", got)
+	}
+}
+
+func TestWindowState_Resized(t *testing.T) {
+	prefs := newMemPrefs()
+	s := newWindowState(prefs)
+	s.delay = 20 * time.Millisecond
+
+	// Only the last of a burst of resizes is saved.
+	s.Resized(fyne.NewSize(800, 600))
+	s.Resized(fyne.NewSize(900, 650.4))
+	if prefs.IntWithFallback(prefWindowWidth, -1) != -1 {
+		t.Fatal("size saved before the delay")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if got := s.Size(fyne.Size{}, fyne.Size{}); got != fyne.NewSize(900, 650) {
+		t.Errorf("saved size = %v, want 900x650", got)
+	}
+
+	// Saving directly cancels a pending save.
+	s.Resized(fyne.NewSize(1000, 700))
+	s.SaveSize(fyne.NewSize(1100, 750))
+	time.Sleep(100 * time.Millisecond)
+	if got := s.Size(fyne.Size{}, fyne.Size{}); got != fyne.NewSize(1100, 750) {
+		t.Errorf("saved size = %v, want 1100x750", got)
+	}
+}
+
+func TestConfigPrefs(t *testing.T) {
+	kinds := map[string]config.PrefKind{}
+	for _, p := range configPrefs {
+		kinds[p.Key] = p.Kind
+	}
+	for _, key := range []string{prefWindowWidth, prefWindowHeight, prefSelectedTab} {
+		if kind, ok := kinds[key]; !ok || kind != config.PrefInt {
+			t.Errorf("configPrefs[%q] = %v, %v, want PrefInt", key, kind, ok)
+		}
+	}
+	if kinds[prefTheme] != config.PrefString {
+		t.Errorf("configPrefs[%q] = %v, want PrefString", prefTheme, kinds[prefTheme])
+	}
+}
@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+)
+
+// csvHeader names the columns written by WriteCSV.
+var csvHeader = []string{"timestamp", "level", "event", "status", "details", "fields"}
+
+// WriteCSV writes entries to w as CSV, one row per entry after a header row:
+// the RFC 3339 timestamp, level, event, status, details and the structured
+// fields as space separated key=value pairs. Rows are written as they are
+// encoded rather than collected first. mask, when set, is applied to every
+// entry before it is written.
+func WriteCSV(w io.Writer, entries []Entry, mask func(Entry) Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	for _, e := range entries {
+		if mask != nil {
+			e = mask(e)
+		}
+		row := []string{
+			e.Timestamp.Format(time.RFC3339),
+			e.Level.String(),
+			e.Event,
+			e.Status,
+			e.Details,
+			formatFields(e.Fields),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %v", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	return nil
+}
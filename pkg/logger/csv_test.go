@@ -0,0 +1,104 @@
+// nolint:all // test package
+package logger
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry Entry
+		want  string // Row after the header.
+	}{
+		{
+			"plain",
+			Entry{Timestamp: at, Level: INFO, Event: "app_start", Status: "Success"},
+			"2026-10-16T09:30:00Z,INFO,app_start,Success,,\n",
+		},
+		{
+			"comma",
+			Entry{Timestamp: at, Level: WARN, Event: "profile_select", Status: "Failed", Details: "host a, port b"},
+			`2026-10-16T09:30:00Z,WARN,profile_select,Failed,"host a, port b",` + "\n",
+		},
+		{
+			"quotes",
+			Entry{Timestamp: at, Level: ERROR, Event: "hsm_command", Status: "Failed", Details: `code "68"`},
+			`2026-10-16T09:30:00Z,ERROR,hsm_command,Failed,"code ""68""",` + "\n",
+		},
+		{
+			"newline",
+			Entry{Timestamp: at, Level: DEBUG, Event: "script", Status: "Step", Details: "line 1\nline 2"},
+			"2026-10-16T09:30:00Z,DEBUG,script,Step,\"line 1\nline 2\",\n",
+		},
+		{
+			"fields",
+			Entry{
+				Timestamp: at, Level: INFO, Event: "hsm_command", Status: "Success",
+				Fields: map[string]string{"latency_ms": "12", "endpoint": "10.0.0.5:1500", "note": "a, b"},
+			},
+			`2026-10-16T09:30:00Z,INFO,hsm_command,Success,,"endpoint=10.0.0.5:1500 latency_ms=12 note=""a, b"""` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteCSV(&buf, []Entry{tt.entry}, nil); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			want := "timestamp,level,event,status,details,fields\n" + tt.want
+			if buf.String() != want {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), want)
+			}
+
+			records, err := csv.NewReader(&buf).ReadAll()
+			if err != nil {
+				t.Fatalf("reading back: %v", err)
+			}
+			if len(records) != 2 || records[1][4] != tt.entry.Details {
+				t.Errorf("read back = %q, want details %q", records, tt.entry.Details)
+			}
+		})
+	}
+}
+
+func TestWriteCSV_Mask(t *testing.T) {
+	e := Entry{
+		Level:   INFO,
+		Event:   "key_import",
+		Status:  "Success",
+		Details: "key 0123456789ABCDEF0123456789ABCDEF",
+		Fields:  map[string]string{"kcv": "0123456789ABCDEF"},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, []Entry{e}, DefaultMasker); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"0001-01-01T00:00:00Z", "INFO", "key_import", "Success",
+		"key 01****************************EF", "kcv=01************EF"}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("masked row = %q, want %q", records[1], want)
+	}
+}
+
+// failWriter fails every write.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestWriteCSV_WriteError(t *testing.T) {
+	if err := WriteCSV(failWriter{}, []Entry{{Event: "x"}}, nil); err == nil {
+		t.Error("WriteCSV() error = nil, want the write failure")
+	}
+}
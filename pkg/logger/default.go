@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	defaultOnce   sync.Once
+	defaultLogger atomic.Pointer[Logger]
+	defaultErr    error
+)
+
+// Init initialises the package-level default logger. Only the first call has
+// any effect; subsequent calls return the result of the first one.
+func Init(path string, level Level) error {
+	defaultOnce.Do(func() {
+		l, err := NewLogger(path, level, nil)
+		if err != nil {
+			defaultErr = err
+			return
+		}
+		defaultLogger.Store(l)
+	})
+
+	return defaultErr
+}
+
+// Default returns the package-level logger, or nil before a successful Init.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// Debug logs a debug level message via the default logger.
+func Debug(event, status, details string) {
+	if l := Default(); l != nil {
+		l.Debug(event, status, details)
+	}
+}
+
+// Info logs an info level message via the default logger.
+func Info(event, status, details string) {
+	if l := Default(); l != nil {
+		l.Info(event, status, details)
+	}
+}
+
+// Warn logs a warning level message via the default logger.
+func Warn(event, status, details string) {
+	if l := Default(); l != nil {
+		l.Warn(event, status, details)
+	}
+}
+
+// Error logs an error level message via the default logger.
+func Error(event, status, details string) {
+	if l := Default(); l != nil {
+		l.Error(event, status, details)
+	}
+}
@@ -0,0 +1,99 @@
+// nolint:all // test package
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// resetDefault restores the package-level logger to its uninitialised state.
+func resetDefault(t *testing.T) {
+	t.Helper()
+	if l := Default(); l != nil {
+		l.Close()
+	}
+	defaultOnce = sync.Once{}
+	defaultLogger.Store(nil)
+	defaultErr = nil
+}
+
+func TestDefault_BeforeInit(t *testing.T) {
+	resetDefault(t)
+	t.Cleanup(func() { resetDefault(t) })
+
+	if Default() != nil {
+		t.Fatal("Default() before Init() = non-nil, want nil")
+	}
+
+	// Package functions must be safe no-ops before Init.
+	Debug("Event", "Status", "Details")
+	Info("Event", "Status", "Details")
+	Warn("Event", "Status", "Details")
+	Error("Event", "Status", "Details")
+}
+
+func TestInit_Concurrent(t *testing.T) {
+	resetDefault(t)
+	t.Cleanup(func() { resetDefault(t) })
+
+	tempDir := t.TempDir()
+	const workers = 16
+
+	var wg sync.WaitGroup
+	errs := make([]error, workers)
+	loggers := make([]*Logger, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each goroutine asks for a different path; only the first wins.
+			errs[i] = Init(filepath.Join(tempDir, "app"+strings.Repeat("x", i)+".log"), INFO)
+			loggers[i] = Default()
+			Info("ConcurrentInit", "Success", "")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Init() error = %v", errs[i])
+		}
+		if loggers[i] == nil || loggers[i] != loggers[0] {
+			t.Fatalf("Default() returned different loggers across goroutines")
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(tempDir, "*.log"))
+	if err != nil {
+		t.Fatalf("Glob() failed: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("log files created = %v, want exactly one", files)
+	}
+
+	Default().Close()
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if got := strings.Count(string(data), "ConcurrentInit"); got != workers {
+		t.Errorf("entries written = %d, want %d", got, workers)
+	}
+}
+
+func TestInit_Error(t *testing.T) {
+	resetDefault(t)
+	t.Cleanup(func() { resetDefault(t) })
+
+	// A directory cannot be opened as a log file.
+	if err := Init(t.TempDir(), INFO); err == nil {
+		t.Fatal("Init() with a directory path error = nil, want error")
+	}
+	if Default() != nil {
+		t.Error("Default() after failed Init() = non-nil, want nil")
+	}
+	Info("Event", "Status", "Details") // Still a safe no-op.
+}
@@ -0,0 +1,268 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format selects how entries are serialised.
+type Format int
+
+const (
+	// FormatJSON writes one JSON object per line.
+	FormatJSON Format = iota
+	// FormatText writes one human-readable line per entry.
+	FormatText
+)
+
+const (
+	// textTimeLayout is the timestamp layout used by FormatText.
+	textTimeLayout = "2006-01-02 15:04:05"
+	// maxLineSize bounds a single serialised entry when reading logs back.
+	maxLineSize = 1024 * 1024
+)
+
+// ParseLevel converts a level name such as "WARN" to a Level.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(s) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	default:
+		return DEBUG, fmt.Errorf("unknown log level: %q", s)
+	}
+}
+
+// encode serialises the entry as a single newline-terminated line.
+//
+// The text format is "2006-01-02 15:04:05 LEVEL event status details". Event
+// and status are quoted when they are empty or contain whitespace, and
+// backslashes, carriage returns and newlines in details are escaped so every
+// entry stays on one line. Structured fields, when present, are written
+// between status and details as "{key=value ...}" in key order, and a module
+// is written after the level as "[module]".
+func (e Entry) encode(format Format) ([]byte, error) {
+	switch format {
+	case FormatText:
+		var b strings.Builder
+		fmt.Fprintf(&b, "%s %s ", e.Timestamp.Format(textTimeLayout), e.Level)
+		if e.Module != "" {
+			b.WriteString("[" + e.Module + "] ")
+		}
+		fmt.Fprintf(
+			&b,
+			"%s %s ",
+			quoteToken(e.Event),
+			quoteToken(e.Status),
+		)
+		if len(e.Fields) > 0 {
+			b.WriteString("{" + formatFields(e.Fields) + "} ")
+		}
+		details := detailsEscaper.Replace(e.Details)
+		if strings.HasPrefix(details, "{") {
+			// Keep details from being mistaken for a field block.
+			details = `\` + details
+		}
+		b.WriteString(details)
+		b.WriteByte('\n')
+
+		return []byte(b.String()), nil
+	default:
+		data, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(data, '\n'), nil
+	}
+}
+
+// decodeEntry parses a single line written in either format.
+func decodeEntry(line string) (Entry, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.HasPrefix(line, "{") {
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			return Entry{}, fmt.Errorf("invalid json log line: %v", err)
+		}
+
+		return e, nil
+	}
+
+	return decodeText(line)
+}
+
+var (
+	detailsEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+	detailsUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r", `\{`, "{")
+
+	errInvalidTextLine = errors.New("invalid text log line")
+)
+
+// decodeText parses a line produced by the FormatText encoder.
+func decodeText(line string) (Entry, error) {
+	if len(line) < len(textTimeLayout)+1 {
+		return Entry{}, errInvalidTextLine
+	}
+	ts, err := time.ParseInLocation(textTimeLayout, line[:len(textTimeLayout)], time.Local)
+	if err != nil {
+		return Entry{}, errInvalidTextLine
+	}
+	rest := line[len(textTimeLayout):]
+
+	levelName, rest, err := nextToken(rest)
+	if err != nil {
+		return Entry{}, err
+	}
+	level, err := ParseLevel(levelName)
+	if err != nil {
+		return Entry{}, errInvalidTextLine
+	}
+	var module string
+	if strings.HasPrefix(rest, " [") {
+		end := strings.Index(rest, "] ")
+		if end < 0 {
+			return Entry{}, errInvalidTextLine
+		}
+		module, rest = rest[2:end], rest[end+1:]
+	}
+	event, rest, err := nextToken(rest)
+	if err != nil {
+		return Entry{}, err
+	}
+	status, rest, err := nextToken(rest)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var fields map[string]string
+	if strings.HasPrefix(rest, " {") {
+		fields, rest, err = parseFields(rest[2:])
+		if err != nil {
+			return Entry{}, err
+		}
+	}
+
+	return Entry{
+		Timestamp: ts,
+		Level:     level,
+		Module:    module,
+		Event:     event,
+		Status:    status,
+		Details:   detailsUnescaper.Replace(strings.TrimPrefix(rest, " ")),
+		Fields:    fields,
+	}, nil
+}
+
+// parseFields parses "key=value ...}" and returns the fields and the
+// remainder of the line after the closing brace.
+func parseFields(s string) (map[string]string, string, error) {
+	fields := make(map[string]string)
+	for {
+		if strings.HasPrefix(s, "}") {
+			return fields, s[1:], nil
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, "", errInvalidTextLine
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, "", errInvalidTextLine
+			}
+			if value, err = strconv.Unquote(quoted); err != nil {
+				return nil, "", errInvalidTextLine
+			}
+			s = s[len(quoted):]
+		} else {
+			end := strings.IndexAny(s, " }")
+			if end < 0 {
+				return nil, "", errInvalidTextLine
+			}
+			value, s = s[:end], s[end:]
+		}
+		fields[key] = value
+
+		s = strings.TrimPrefix(s, " ")
+	}
+}
+
+// nextToken consumes a single space followed by a plain or quoted token.
+func nextToken(s string) (string, string, error) {
+	if !strings.HasPrefix(s, " ") {
+		return "", "", errInvalidTextLine
+	}
+	s = s[1:]
+
+	if strings.HasPrefix(s, `"`) {
+		quoted, err := strconv.QuotedPrefix(s)
+		if err != nil {
+			return "", "", errInvalidTextLine
+		}
+		tok, err := strconv.Unquote(quoted)
+		if err != nil {
+			return "", "", errInvalidTextLine
+		}
+
+		return tok, s[len(quoted):], nil
+	}
+
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		return s[:i], s[i:], nil
+	}
+
+	return s, "", nil
+}
+
+// formatFields writes fields as space separated key=value pairs in key
+// order, quoting ambiguous values.
+func formatFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k + "=" + quoteField(fields[k]))
+	}
+
+	return b.String()
+}
+
+// quoteField quotes a field value when it would otherwise be ambiguous.
+func quoteField(s string) string {
+	if s == "" || strings.HasPrefix(s, `"`) || strings.ContainsAny(s, " \t\r\n}") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
+
+// quoteToken quotes s when it would otherwise be ambiguous in a text line.
+func quoteToken(s string) string {
+	if s == "" || strings.HasPrefix(s, `"`) || strings.HasPrefix(s, "[") || strings.ContainsAny(s, " \t\r\n") {
+		return strconv.Quote(s)
+	}
+
+	return s
+}
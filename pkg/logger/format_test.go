@@ -0,0 +1,148 @@
+// nolint:all // test package
+package logger
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEntry_EncodeDecode(t *testing.T) {
+	ts := time.Date(2025, 5, 1, 12, 30, 45, 0, time.Local)
+	tests := []struct {
+		name  string
+		entry Entry
+	}{
+		{
+			name:  "plain",
+			entry: Entry{Timestamp: ts, Level: INFO, Event: "Connect", Status: "Success", Details: "127.0.0.1:1500"},
+		},
+		{
+			name: "quotes_and_newlines",
+			entry: Entry{
+				Timestamp: ts,
+				Level:     ERROR,
+				Event:     "Command",
+				Status:    "Failed",
+				Details:   "response \"A1\"\nline two\r\nback\\slash \\n literal",
+			},
+		},
+		{
+			name:  "spaces_in_event_and_status",
+			entry: Entry{Timestamp: ts, Level: WARN, Event: "Key Import", Status: "Partly done", Details: " leading space"},
+		},
+		{
+			name:  "empty_fields",
+			entry: Entry{Timestamp: ts, Level: DEBUG},
+		},
+		{
+			name: "fields",
+			entry: Entry{
+				Timestamp: ts,
+				Level:     INFO,
+				Event:     "hsm_command",
+				Status:    "Success",
+				Details:   "done",
+				Fields:    map[string]string{"code": "A0", "note": "two words}", "empty": ""},
+			},
+		},
+		{
+			name:  "details_look_like_fields",
+			entry: Entry{Timestamp: ts, Level: INFO, Event: "E", Status: "S", Details: "{code=A0}"},
+		},
+		{
+			name:  "quoted_looking_status",
+			entry: Entry{Timestamp: ts, Level: INFO, Event: "E", Status: `"x`, Details: "d"},
+		},
+		{
+			name:  "module",
+			entry: Entry{Timestamp: ts, Level: DEBUG, Module: "hsm", Event: "hsm_command", Status: "Success"},
+		},
+		{
+			name:  "event_looks_like_module",
+			entry: Entry{Timestamp: ts, Level: INFO, Event: "[hsm]", Status: "S"},
+		},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatText} {
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				data, err := tt.entry.encode(format)
+				if err != nil {
+					t.Fatalf("encode() error = %v", err)
+				}
+				if n := len(data); n == 0 || data[n-1] != '\n' {
+					t.Fatalf("encode() = %q, want newline-terminated line", data)
+				}
+				for _, b := range data[:len(data)-1] {
+					if b == '\n' {
+						t.Fatalf("encode() = %q, want a single line", data)
+					}
+				}
+
+				got, err := decodeEntry(string(data))
+				if err != nil {
+					t.Fatalf("decodeEntry(%q) error = %v", data, err)
+				}
+				if !got.Timestamp.Equal(tt.entry.Timestamp) || got.Level != tt.entry.Level ||
+					got.Event != tt.entry.Event || got.Status != tt.entry.Status ||
+					got.Details != tt.entry.Details || !reflect.DeepEqual(got.Fields, tt.entry.Fields) {
+					t.Errorf("round trip (format %d) = %+v, want %+v", format, got, tt.entry)
+				}
+			})
+		}
+	}
+}
+
+func TestDecodeEntry_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"{broken",
+		"2025-05-01 12:30:45",
+		"2025-05-01 12:30:45 NOTALEVEL Event Status",
+		"not a timestamp at all INFO Event Status",
+		`2025-05-01 12:30:45 INFO "unterminated Status`,
+		"2025-05-01 12:30:45 INFO Event Status {code=A0",
+		"2025-05-01 12:30:45 INFO Event Status {novalue}",
+	} {
+		if _, err := decodeEntry(line); err == nil {
+			t.Errorf("decodeEntry(%q) error = nil, want error", line)
+		}
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, l := range []Level{DEBUG, INFO, WARN, ERROR} {
+		got, err := ParseLevel(l.String())
+		if err != nil || got != l {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", l.String(), got, err, l)
+		}
+	}
+	if _, err := ParseLevel("TRACE"); err == nil {
+		t.Error("ParseLevel(\"TRACE\") error = nil, want error")
+	}
+}
+
+func TestLogger_TextFormat_GetEntries(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "text.log")
+	l, err := NewLoggerWithOptions(Options{Path: logPath, Level: DEBUG, Format: FormatText})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	defer l.Close()
+
+	l.Info("Command", "Sent", "first \"quoted\"\nsecond line")
+	l.Error("Command", "Failed", "timeout")
+
+	entries, err := l.GetEntries(time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("GetEntries() returned %d entries, want 2", len(entries))
+	}
+	if entries[0].Details != "first \"quoted\"\nsecond line" || entries[1].Level != ERROR {
+		t.Errorf("GetEntries() = %+v", entries)
+	}
+}
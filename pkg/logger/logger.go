@@ -1,11 +1,15 @@
 package logger
 
 import (
-	"encoding/json"
+	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -19,6 +23,23 @@ const (
 	INFO
 	// ERROR level for error conditions.
 	ERROR
+	// WARN level for degraded but recoverable conditions. It is appended
+	// after ERROR to keep the numeric values of existing entries stable;
+	// use AtLeast for severity comparisons.
+	WARN
+)
+
+const (
+	// DefaultMaxSizeBytes is the log file size that triggers rotation.
+	DefaultMaxSizeBytes = 10 * 1024 * 1024
+	// DefaultMaxBackups is the number of rotated files kept on disk.
+	DefaultMaxBackups = 5
+	// errorReportInterval rate-limits OnError notifications.
+	errorReportInterval = time.Second
+	// fileCheckInterval rate-limits checks for an externally moved log file.
+	fileCheckInterval = time.Second
+	// DefaultCallbackQueueSize is the number of entries buffered per callback.
+	DefaultCallbackQueueSize = 1024
 )
 
 // String returns the string representation of a log level.
@@ -28,6 +49,8 @@ func (l Level) String() string {
 		return "DEBUG"
 	case INFO:
 		return "INFO"
+	case WARN:
+		return "WARN"
 	case ERROR:
 		return "ERROR"
 	default:
@@ -35,67 +58,492 @@ func (l Level) String() string {
 	}
 }
 
+// severity returns the ordering rank of a level.
+func (l Level) severity() int {
+	switch l {
+	case DEBUG:
+		return 0
+	case INFO:
+		return 1
+	case WARN:
+		return 2
+	case ERROR:
+		return 3
+	default:
+		return int(l)
+	}
+}
+
+// AtLeast reports whether l is as severe as or more severe than min.
+func (l Level) AtLeast(min Level) bool {
+	return l.severity() >= min.severity()
+}
+
+// LevelFilter enables entries by severity, with optional per-module overrides
+// of the global minimum.
+type LevelFilter struct {
+	Min     Level
+	Modules map[string]Level
+}
+
+// Allows reports whether an entry at level from module passes the filter. A
+// module without an override falls back to Min.
+func (f LevelFilter) Allows(module string, level Level) bool {
+	min, ok := f.Modules[module]
+	if !ok {
+		min = f.Min
+	}
+
+	return level.AtLeast(min)
+}
+
+// Sink is an additional destination for log entries.
+type Sink struct {
+	Writer io.Writer
+	Format Format
+}
+
 // Entry represents a log entry.
 type Entry struct {
 	Timestamp time.Time `json:"timestamp"`
 	Level     Level     `json:"level"`
+	Module    string    `json:"module,omitempty"`
 	Event     string    `json:"event"`
 	Status    string    `json:"status"`
 	Details   string    `json:"details,omitempty"`
+	// Fields holds optional structured key/value data.
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// Options holds logger configuration.
+type Options struct {
+	Path  string
+	Level Level
+
+	// Callback is registered via AddCallback when the logger is created.
+	Callback func(Entry)
+	// CallbackQueueSize bounds the entries queued per callback (0 = default).
+	CallbackQueueSize int
+
+	// MaxSizeBytes is the file size that triggers rotation (0 = default).
+	MaxSizeBytes int64
+	// MaxBackups is the number of rotated files to keep (0 = default).
+	MaxBackups int
+
+	// Masker redacts sensitive data before an entry is written or passed to
+	// the callback. DefaultMasker is used when nil.
+	Masker func(Entry) Entry
+	// DisableMasking turns masking off entirely (lab use only).
+	DisableMasking bool
+
+	// Sinks receive every entry in addition to the log file, e.g. os.Stderr
+	// in FormatText during development.
+	Sinks []Sink
+
+	// Format controls how entries are written to the log file.
+	Format Format
+
+	// OnError is notified of write failures, at most once per second so a
+	// handler that itself logs cannot cause a feedback loop.
+	OnError func(error)
+}
+
+// Stats reports logger health counters.
+type Stats struct {
+	// ConsecutiveFailures counts entries in a row that failed to be written
+	// to at least one destination. It resets after a fully successful write.
+	ConsecutiveFailures uint64
+	// TotalFailures counts all failed writes since the logger was created.
+	TotalFailures uint64
+	// DroppedCallbacks counts entries not delivered because a callback queue
+	// was full.
+	DroppedCallbacks uint64
+}
+
+// subscriber delivers entries to a callback from its own goroutine.
+type subscriber struct {
+	fn    func(Entry)
+	queue chan Entry
 }
 
 // Logger handles application logging.
 type Logger struct {
-	mu       sync.Mutex
-	file     *os.File
-	level    Level
-	callback func(Entry)
+	mu         sync.Mutex
+	file       *os.File
+	path       string
+	size       int64
+	maxSize    int64
+	maxBackups int
+	level      Level
+	modules    map[string]Level // replaced, never mutated, on update.
+	masker     func(Entry) Entry
+	sinks      []Sink
+	format     Format
+
+	callback    func(Entry)
+	queueSize   int
+	subscribers map[int]*subscriber
+	nextSubID   int
+	delivery    sync.WaitGroup
+	dropped     atomic.Uint64
+
+	checkInterval time.Duration
+	lastCheck     time.Time
+
+	onError         func(error)
+	lastErrorReport time.Time
+	writeErr        error // first write error, returned by Close.
+	consecutive     atomic.Uint64
+	totalFailures   atomic.Uint64
 }
 
-// NewLogger creates a new logger instance.
+// NewLogger creates a new logger instance with default rotation settings.
 func NewLogger(logPath string, level Level, callback func(Entry)) (*Logger, error) {
-	if err := os.MkdirAll(filepath.Dir(logPath), 0o750); err != nil {
+	return NewLoggerWithOptions(Options{
+		Path:     logPath,
+		Level:    level,
+		Callback: callback,
+	})
+}
+
+// NewLoggerWithOptions creates a new logger instance from the given options.
+func NewLoggerWithOptions(opts Options) (*Logger, error) {
+	if opts.MaxSizeBytes <= 0 {
+		opts.MaxSizeBytes = DefaultMaxSizeBytes
+	}
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = DefaultMaxBackups
+	}
+	if opts.CallbackQueueSize <= 0 {
+		opts.CallbackQueueSize = DefaultCallbackQueueSize
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o750); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %v", err)
 	}
 
-	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	l := &Logger{
+		path:       opts.Path,
+		maxSize:    opts.MaxSizeBytes,
+		maxBackups: opts.MaxBackups,
+		level:      opts.Level,
+		callback:   opts.Callback,
+		queueSize:  opts.CallbackQueueSize,
+		masker:     opts.Masker,
+		sinks:      opts.Sinks,
+		format:     opts.Format,
+		onError:    opts.OnError,
+
+		checkInterval: fileCheckInterval,
+	}
+	if l.masker == nil && !opts.DisableMasking {
+		l.masker = DefaultMasker
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	if l.callback != nil {
+		l.AddCallback(l.callback)
+	}
+
+	return l, nil
+}
+
+// AddCallback registers fn to receive every written entry. Entries are queued
+// and delivered from a dedicated goroutine so a slow callback never delays
+// logging; when the queue is full the entry is dropped and counted in Stats.
+// The returned function unregisters the callback.
+func (l *Logger) AddCallback(fn func(Entry)) (int, func()) {
+	sub := &subscriber{fn: fn, queue: make(chan Entry, l.queueSize)}
+
+	l.mu.Lock()
+	if l.subscribers == nil {
+		l.subscribers = make(map[int]*subscriber)
+	}
+	id := l.nextSubID
+	l.nextSubID++
+	l.subscribers[id] = sub
+	l.mu.Unlock()
+
+	l.delivery.Add(1)
+	go func() {
+		defer l.delivery.Done()
+		for entry := range sub.queue {
+			sub.fn(entry)
+		}
+	}()
+
+	return id, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.subscribers[id] == sub {
+			delete(l.subscribers, id)
+			close(sub.queue)
+		}
+	}
+}
+
+// open opens the log file for appending and records its current size.
+func (l *Logger) open() error {
+	file, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open log file: %v", err)
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %v", err)
+	}
+
+	l.file = file
+	l.size = info.Size()
+	l.lastCheck = time.Now()
+
+	return nil
+}
+
+// Reopen closes the log file and opens the configured path again, e.g. after
+// an external tool has rotated it. It also restarts logging after Close.
+func (l *Logger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_ = l.file.Close() // may already be closed.
+
+	return l.open()
+}
+
+// checkFile reopens the log file when the configured path no longer refers to
+// the open file, because it was moved or deleted. It runs at most once per
+// check interval. The caller must hold l.mu.
+func (l *Logger) checkFile() error {
+	if time.Since(l.lastCheck) < l.checkInterval {
+		return nil
+	}
+	l.lastCheck = time.Now()
+
+	current, err := l.file.Stat()
+	if err != nil {
+		return nil // closed; writes will report the failure.
+	}
+	onDisk, err := os.Stat(l.path)
+	if err == nil && os.SameFile(current, onDisk) {
+		return nil
+	}
+
+	_ = l.file.Close()
+	if err := l.open(); err != nil {
+		return fmt.Errorf("failed to reopen log file: %v", err)
+	}
+
+	return nil
+}
+
+// rotate closes the current file, shifts the backups and reopens a fresh file.
+// The caller must hold l.mu.
+func (l *Logger) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %v", err)
+	}
+
+	// Drop the oldest backup and shift the rest up by one.
+	_ = os.Remove(backupName(l.path, l.maxBackups))
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(backupName(l.path, i), backupName(l.path, i+1))
+	}
+	if err := os.Rename(l.path, backupName(l.path, 1)); err != nil && !os.IsNotExist(err) {
+		// Keep logging into the existing file rather than losing entries.
+		if openErr := l.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("failed to rotate log file: %v", err)
+	}
+
+	return l.open()
+}
+
+// hexTokenRegex matches standalone hex tokens.
+var hexTokenRegex = regexp.MustCompile(`\b[0-9A-Fa-f]+\b`)
+
+// DefaultMasker redacts standalone hex tokens of key-like length (16, 32, 48
+// or 64 digits) in the entry's status, details and field values, keeping only
+// the first and last two characters.
+func DefaultMasker(e Entry) Entry {
+	e.Status = MaskHexTokens(e.Status)
+	e.Details = MaskHexTokens(e.Details)
+	if e.Fields != nil {
+		masked := make(map[string]string, len(e.Fields))
+		for k, v := range e.Fields {
+			masked[k] = MaskHexTokens(v)
+		}
+		e.Fields = masked
 	}
 
-	return &Logger{
-		file:     file,
-		level:    level,
-		callback: callback,
-	}, nil
+	return e
+}
+
+// Mask applies the logger's masker to e, as is done before writing. It
+// returns e unchanged when masking is disabled.
+func (l *Logger) Mask(e Entry) Entry {
+	if l.masker == nil {
+		return e
+	}
+
+	return l.masker(e)
+}
+
+// MaskHexTokens replaces the middle of every key-like hex token in s with
+// asterisks.
+func MaskHexTokens(s string) string {
+	return hexTokenRegex.ReplaceAllStringFunc(s, func(tok string) string {
+		switch len(tok) {
+		case 16, 32, 48, 64:
+			return tok[:2] + strings.Repeat("*", len(tok)-4) + tok[len(tok)-2:]
+		default:
+			return tok
+		}
+	})
+}
+
+// hexRunRegex matches runs of hex digits long enough to be key material.
+var hexRunRegex = regexp.MustCompile(`[0-9A-Fa-f]{16,}`)
+
+// MaskHexRuns redacts the middle of every run of 16 or more hex digits in s,
+// even when embedded in a command after a key scheme tag.
+func MaskHexRuns(s string) string {
+	return hexRunRegex.ReplaceAllStringFunc(s, func(run string) string {
+		return run[:2] + strings.Repeat("*", len(run)-4) + run[len(run)-2:]
+	})
+}
+
+// backupName returns the path of the n-th rotated log file.
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
 }
 
 // Log writes a log entry.
 func (l *Logger) Log(level Level, event, status, details string) {
-	if level < l.level {
+	l.LogFields(level, event, status, details, nil)
+}
+
+// LogFields writes a log entry carrying structured fields.
+func (l *Logger) LogFields(level Level, event, status, details string, fields map[string]string) {
+	l.logEntry("", level, event, status, details, fields)
+}
+
+// logEntry filters, masks and writes a single entry for module.
+func (l *Logger) logEntry(module string, level Level, event, status, details string, fields map[string]string) {
+	if !l.levelFilter().Allows(module, level) {
 		return
 	}
 
 	entry := Entry{
 		Timestamp: time.Now(),
 		Level:     level,
+		Module:    module,
 		Event:     event,
 		Status:    status,
 		Details:   details,
 	}
+	if len(fields) > 0 {
+		// Copy so neither the masker nor the caller can alias the other.
+		entry.Fields = make(map[string]string, len(fields))
+		for k, v := range fields {
+			entry.Fields[k] = v
+		}
+	}
+
+	if l.masker != nil {
+		entry = l.masker(entry)
+	}
 
 	l.mu.Lock()
-	defer l.mu.Unlock()
+	err := l.write(entry)
+	report := l.recordWriteResult(err)
+
+	// Queue for callbacks without waiting on slow consumers.
+	for _, sub := range l.subscribers {
+		select {
+		case sub.queue <- entry:
+		default:
+			l.dropped.Add(1)
+		}
+	}
+	l.mu.Unlock()
+
+	// Notify outside the lock so the handler may log itself.
+	if report != nil {
+		report(err)
+	}
+}
+
+// write serialises the entry to the file and all sinks, returning the first
+// failure. A failing destination does not prevent writes to the others. The
+// caller must hold l.mu.
+func (l *Logger) write(entry Entry) error {
+	var firstErr error
+
+	// Write to file, reopening it first if it was moved away.
+	data, err := entry.encode(l.format)
+	if err != nil {
+		firstErr = fmt.Errorf("failed to encode log entry: %v", err)
+	} else if err := l.checkFile(); err != nil {
+		firstErr = err
+	} else {
+		n, err := l.file.Write(data)
+		l.size += int64(n)
+		if err != nil {
+			firstErr = fmt.Errorf("failed to write log file: %w", err)
+		} else if l.size >= l.maxSize {
+			if err := l.rotate(); err != nil {
+				firstErr = err
+			}
+		}
+	}
+
+	// Write to additional sinks.
+	for _, sink := range l.sinks {
+		data, err := entry.encode(sink.Format)
+		if err == nil {
+			_, err = sink.Writer.Write(data)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to write log sink: %w", err)
+		}
+	}
+
+	return firstErr
+}
 
-	// Write to file.
-	data, err := json.Marshal(entry)
+// recordWriteResult updates the failure counters and returns the OnError
+// handler when a failure should be reported. The caller must hold l.mu.
+func (l *Logger) recordWriteResult(err error) func(error) {
 	if err == nil {
-		fmt.Fprintln(l.file, string(data))
+		l.consecutive.Store(0)
+		return nil
 	}
 
-	// Call callback if set.
-	if l.callback != nil {
-		l.callback(entry)
+	l.consecutive.Add(1)
+	l.totalFailures.Add(1)
+	if l.writeErr == nil {
+		l.writeErr = err
+	}
+
+	if l.onError == nil || time.Since(l.lastErrorReport) < errorReportInterval {
+		return nil
+	}
+	l.lastErrorReport = time.Now()
+
+	return l.onError
+}
+
+// Stats returns the current write failure counters.
+func (l *Logger) Stats() Stats {
+	return Stats{
+		ConsecutiveFailures: l.consecutive.Load(),
+		TotalFailures:       l.totalFailures.Load(),
+		DroppedCallbacks:    l.dropped.Load(),
 	}
 }
 
@@ -109,16 +557,36 @@ func (l *Logger) Info(event, status, details string) {
 	l.Log(INFO, event, status, details)
 }
 
+// Warn logs a warning level message.
+func (l *Logger) Warn(event, status, details string) {
+	l.Log(WARN, event, status, details)
+}
+
 // Error logs an error level message.
 func (l *Logger) Error(event, status, details string) {
 	l.Log(ERROR, event, status, details)
 }
 
 // Close closes the logger.
+// It unregisters all callbacks, waits for queued entries to be delivered and
+// returns the close error, or else the first write error encountered.
 func (l *Logger) Close() error {
 	l.mu.Lock()
-	defer l.mu.Unlock()
-	return l.file.Close()
+	for id, sub := range l.subscribers {
+		delete(l.subscribers, id)
+		close(sub.queue)
+	}
+	err := l.file.Close()
+	writeErr := l.writeErr
+	l.mu.Unlock()
+
+	l.delivery.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	return writeErr
 }
 
 // SetLevel changes the logging level.
@@ -128,8 +596,121 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
-// GetEntries retrieves log entries with optional filtering.
+// SetModuleLevel overrides the minimum level for entries logged by module.
+func (l *Logger) SetModuleLevel(module string, level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modules := make(map[string]Level, len(l.modules)+1)
+	for m, lvl := range l.modules {
+		modules[m] = lvl
+	}
+	modules[module] = level
+	l.modules = modules
+}
+
+// ClearModuleLevel removes the override for module so it follows the global
+// level again.
+func (l *Logger) ClearModuleLevel(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	modules := make(map[string]Level, len(l.modules))
+	for m, lvl := range l.modules {
+		if m != module {
+			modules[m] = lvl
+		}
+	}
+	l.modules = modules
+}
+
+// levelFilter returns a snapshot of the current level configuration.
+func (l *Logger) levelFilter() LevelFilter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return LevelFilter{Min: l.level, Modules: l.modules}
+}
+
+// GetEntries retrieves log entries with optional filtering. Zero start or end
+// times leave that side of the range open, and filter is matched
+// case-insensitively against the module, event, status, details and field
+// values. Rotated files are read oldest first, and lines in either format are
+// accepted.
 func (l *Logger) GetEntries(start, end time.Time, filter string) ([]Entry, error) {
-	// TODO: Implement log retrieval and filtering.
-	return nil, fmt.Errorf("not implemented")
+	l.mu.Lock()
+	path := l.path
+	maxBackups := l.maxBackups
+	l.mu.Unlock()
+
+	paths := make([]string, 0, maxBackups+1)
+	for i := maxBackups; i >= 1; i-- {
+		paths = append(paths, backupName(path, i))
+	}
+	paths = append(paths, path)
+
+	filter = strings.ToLower(filter)
+	var entries []Entry
+	for _, p := range paths {
+		file, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		for scanner.Scan() {
+			entry, err := decodeEntry(scanner.Text())
+			if err != nil {
+				continue // Skip corrupt or partial lines.
+			}
+			if entry.matches(start, end, filter) {
+				entries = append(entries, entry)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log file: %v", err)
+		}
+	}
+
+	return entries, nil
+}
+
+// Matches reports whether the entry passes the GetEntries filters: it falls
+// within the time range and contains the filter text, ignoring case.
+func (e Entry) Matches(start, end time.Time, filter string) bool {
+	return e.matches(start, end, strings.ToLower(filter))
+}
+
+// matches reports whether the entry falls within the time range and contains
+// the lower-cased filter text.
+func (e Entry) matches(start, end time.Time, filter string) bool {
+	if !start.IsZero() && e.Timestamp.Before(start) {
+		return false
+	}
+	if !end.IsZero() && e.Timestamp.After(end) {
+		return false
+	}
+	if filter == "" {
+		return true
+	}
+
+	if strings.Contains(strings.ToLower(e.Module), filter) ||
+		strings.Contains(strings.ToLower(e.Event), filter) ||
+		strings.Contains(strings.ToLower(e.Status), filter) ||
+		strings.Contains(strings.ToLower(e.Details), filter) {
+		return true
+	}
+	for _, v := range e.Fields {
+		if strings.Contains(strings.ToLower(v), filter) {
+			return true
+		}
+	}
+
+	return false
 }
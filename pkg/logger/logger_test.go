@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,6 +22,7 @@ func TestLevel_String(t *testing.T) {
 	}{
 		{"debug", DEBUG, "DEBUG"},
 		{"info", INFO, "INFO"},
+		{"warn", WARN, "WARN"},
 		{"error", ERROR, "ERROR"},
 		{"unknown", Level(99), "UNKNOWN"},
 	}
@@ -160,6 +162,38 @@ func TestLogger_Log(t *testing.T) {
 			wantLogged: true,
 			checkFile:  true,
 		},
+		{
+			name:       "log_warn_when_level_is_info",
+			logLevel:   INFO,
+			entryLevel: WARN,
+			event:      "TestWarn", status: "Degraded", details: "Warn details",
+			wantLogged: true,
+			checkFile:  true,
+		},
+		{
+			name:       "log_info_when_level_is_warn_not_logged",
+			logLevel:   WARN,
+			entryLevel: INFO,
+			event:      "TestInfoBelowWarn", status: "Skipped", details: "Info details",
+			wantLogged: false,
+			checkFile:  true,
+		},
+		{
+			name:       "log_warn_when_level_is_error_not_logged",
+			logLevel:   ERROR,
+			entryLevel: WARN,
+			event:      "TestWarnBelowError", status: "Skipped", details: "Warn details",
+			wantLogged: false,
+			checkFile:  true,
+		},
+		{
+			name:       "log_error_when_level_is_warn",
+			logLevel:   WARN,
+			entryLevel: ERROR,
+			event:      "TestErrorAboveWarn", status: "Failure", details: "Error details",
+			wantLogged: true,
+			checkFile:  true,
+		},
 		{
 			name:       "log_with_callback",
 			logLevel:   DEBUG,
@@ -204,11 +238,14 @@ func TestLogger_Log(t *testing.T) {
 				l.Debug(tt.event, tt.status, tt.details)
 			case INFO:
 				l.Info(tt.event, tt.status, tt.details)
+			case WARN:
+				l.Warn(tt.event, tt.status, tt.details)
 			case ERROR:
 				l.Error(tt.event, tt.status, tt.details)
 			default:
 				l.Log(tt.entryLevel, tt.event, tt.status, tt.details)
 			}
+			l.Close() // Wait for asynchronous callback delivery.
 
 			if tt.checkFile {
 				file, err := os.Open(logFilePath)
@@ -354,42 +391,588 @@ func TestLogger_GetEntries(t *testing.T) {
 	}
 	defer l.Close()
 
-	// Log some entries - this part is more for future when GetEntries is implemented.
-	// For now, we just test the "not implemented" error.
+	before := time.Now().Add(-1 * time.Hour)
 	l.Info("Event1", "Status1", "Details1")
-	time.Sleep(10 * time.Millisecond) // Ensure distinct timestamps if implementation relies on it.
+	time.Sleep(10 * time.Millisecond) // Ensure distinct timestamps.
 	l.Debug("Event2", "Status2", "Details2")
 	time.Sleep(10 * time.Millisecond)
+	middle := time.Now()
+	time.Sleep(10 * time.Millisecond)
 	l.Error("Event3", "Status3", "Details3")
 
+	// A corrupt line must be skipped rather than failing the whole read.
+	l.mu.Lock()
+	fmt.Fprintln(l.file, "{not json")
+	l.mu.Unlock()
+
 	tests := []struct {
-		name      string
-		start     time.Time
-		end       time.Time
-		filter    string
-		wantErr   bool
-		expErrStr string
+		name       string
+		start      time.Time
+		end        time.Time
+		filter     string
+		wantEvents []string
 	}{
 		{
-			name:      "not_implemented_error",
-			start:     time.Now().Add(-1 * time.Hour),
-			end:       time.Now(),
-			filter:    "",
-			wantErr:   true,
-			expErrStr: "not implemented",
+			name:       "all_entries",
+			wantEvents: []string{"Event1", "Event2", "Event3"},
+		},
+		{
+			name:       "time_range",
+			start:      before,
+			end:        middle,
+			wantEvents: []string{"Event1", "Event2"},
+		},
+		{
+			name:       "open_start",
+			start:      middle,
+			wantEvents: []string{"Event3"},
+		},
+		{
+			name:       "filter_case_insensitive",
+			filter:     "details2",
+			wantEvents: []string{"Event2"},
+		},
+		{
+			name:       "filter_no_match",
+			filter:     "missing",
+			wantEvents: nil,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := l.GetEntries(tt.start, tt.end, tt.filter)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetEntries() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			got, err := l.GetEntries(tt.start, tt.end, tt.filter)
+			if err != nil {
+				t.Fatalf("GetEntries() error = %v", err)
+			}
+			var events []string
+			for _, e := range got {
+				events = append(events, e.Event)
+			}
+			if strings.Join(events, ",") != strings.Join(tt.wantEvents, ",") {
+				t.Errorf("GetEntries() events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestLogger_Rotation(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "app.log")
+
+	l, err := NewLoggerWithOptions(Options{
+		Path:         logPath,
+		Level:        DEBUG,
+		MaxSizeBytes: 512,
+		MaxBackups:   2,
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+
+	const total = 40
+	for i := 0; i < total; i++ {
+		l.Info("RotationEvent", "Written", strings.Repeat("x", 20))
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+
+	// Only the active file and MaxBackups rotated files may exist.
+	matches, err := filepath.Glob(logPath + "*")
+	if err != nil {
+		t.Fatalf("Glob() failed: %v", err)
+	}
+	want := map[string]bool{logPath: true, logPath + ".1": true, logPath + ".2": true}
+	if len(matches) != len(want) {
+		t.Fatalf("rotated file set = %v, want %d files", matches, len(want))
+	}
+	for _, m := range matches {
+		if !want[m] {
+			t.Errorf("unexpected log file %q", m)
+		}
+	}
+
+	// Rotated files must have reached the threshold and hold complete lines.
+	countLines := func(path string) int {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("os.Open(%q) failed: %v", path, err)
+		}
+		defer f.Close()
+		n := 0
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				t.Errorf("json.Unmarshal() failed in %s: %v", path, err)
+			}
+			n++
+		}
+		return n
+	}
+	for _, m := range []string{logPath + ".1", logPath + ".2"} {
+		info, err := os.Stat(m)
+		if err != nil {
+			t.Fatalf("os.Stat(%q) failed: %v", m, err)
+		}
+		if info.Size() < 512 {
+			t.Errorf("%s size = %d, want at least the rotation threshold", m, info.Size())
+		}
+	}
+
+	// No entries may be lost across the rotation boundary.
+	l2, err := NewLoggerWithOptions(Options{
+		Path:         filepath.Join(tempDir, "lossless.log"),
+		Level:        DEBUG,
+		MaxSizeBytes: 512,
+		MaxBackups:   total,
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	for i := 0; i < total; i++ {
+		l2.Info("RotationEvent", "Written", strings.Repeat("x", 20))
+	}
+	l2.Close()
+
+	files, _ := filepath.Glob(filepath.Join(tempDir, "lossless.log*"))
+	got := 0
+	for _, f := range files {
+		got += countLines(f)
+	}
+	if got != total {
+		t.Errorf("entries across rotated files = %d, want %d", got, total)
+	}
+}
+
+func TestMaskHexTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single_length_key", "key 0123456789ABCDEF", "key 01************EF"},
+		{
+			"double_length_key",
+			"0123456789ABCDEFFEDCBA9876543210 done",
+			"01****************************10 done",
+		},
+		{"short_token_kept", "KCV 1A2B3C", "KCV 1A2B3C"},
+		{"odd_length_token_kept", "0123456789ABCDEF0", "0123456789ABCDEF0"},
+		{"embedded_token_kept", "U0123456789ABCDEFFEDCBA9876543210", "U0123456789ABCDEFFEDCBA9876543210"},
+		{"no_hex", "connected to hsm", "connected to hsm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskHexTokens(tt.input); got != tt.want {
+				t.Errorf("MaskHexTokens(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskHexRuns(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"embedded_key", "A0U0123456789ABCDEF", "A0U01************EF"},
+		{"standalone_key", "key 0123456789ABCDEF", "key 01************EF"},
+		{"short_run_kept", "CA0123ABCD", "CA0123ABCD"},
+		{"no_hex", "NC", "NC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaskHexRuns(tt.input); got != tt.want {
+				t.Errorf("MaskHexRuns(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogger_Masking(t *testing.T) {
+	tempDir := t.TempDir()
+	secrets := []string{
+		"0123456789ABCDEF",
+		"0123456789ABCDEFFEDCBA9876543210",
+		"0123456789ABCDEFFEDCBA98765432100123456789ABCDEF",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+
+	var cbEntries []Entry
+	logPath := filepath.Join(tempDir, "masked.log")
+	l, err := NewLoggerWithOptions(Options{
+		Path:     logPath,
+		Level:    DEBUG,
+		Callback: func(e Entry) { cbEntries = append(cbEntries, e) },
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	for _, secret := range secrets {
+		l.Info("KeyImported", secret, "clear key "+secret+" loaded")
+		l.LogFields(INFO, "KeyImported", "Success", "", map[string]string{"key": secret})
+	}
+	l.Close()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	for _, secret := range secrets {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("log file contains unmasked token %q", secret)
+		}
+	}
+	for _, e := range cbEntries {
+		for _, secret := range secrets {
+			if strings.Contains(e.Details, secret) || strings.Contains(e.Status, secret) ||
+				strings.Contains(e.Fields["key"], secret) {
+				t.Errorf("callback received unmasked token %q", secret)
+			}
+		}
+	}
+
+	// Masking can be disabled for lab use.
+	labPath := filepath.Join(tempDir, "lab.log")
+	lab, err := NewLoggerWithOptions(Options{Path: labPath, Level: DEBUG, DisableMasking: true})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	lab.Info("KeyImported", "Success", secrets[0])
+	lab.Close()
+
+	data, err = os.ReadFile(labPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if !strings.Contains(string(data), secrets[0]) {
+		t.Errorf("log file with masking disabled does not contain %q", secrets[0])
+	}
+}
+
+// failingWriter always returns an error.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("sink unavailable") }
+
+func TestLogger_Sinks(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "sinks.log")
+
+	var jsonBuf, textBuf strings.Builder
+	l, err := NewLoggerWithOptions(Options{
+		Path:  logPath,
+		Level: DEBUG,
+		Sinks: []Sink{
+			{Writer: failingWriter{}, Format: FormatText},
+			{Writer: &jsonBuf, Format: FormatJSON},
+			{Writer: &textBuf, Format: FormatText},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	l.Info("Connect", "Success", "127.0.0.1:1500")
+	l.Close()
+
+	// The JSON sink receives the same line as the file.
+	var e Entry
+	if err := json.Unmarshal([]byte(jsonBuf.String()), &e); err != nil {
+		t.Fatalf("json.Unmarshal() of JSON sink failed: %v", err)
+	}
+	if e.Event != "Connect" || e.Status != "Success" || e.Details != "127.0.0.1:1500" ||
+		e.Level != INFO {
+		t.Errorf("JSON sink entry = %+v", e)
+	}
+
+	wantText := e.Timestamp.Format("2006-01-02 15:04:05") + " INFO Connect Success 127.0.0.1:1500\n"
+	if textBuf.String() != wantText {
+		t.Errorf("text sink = %q, want %q", textBuf.String(), wantText)
+	}
+
+	// The failing sink must not prevent the file write.
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if string(data) != jsonBuf.String() {
+		t.Errorf("file content = %q, want %q", string(data), jsonBuf.String())
+	}
+}
+
+// toggleWriter fails while its fail flag is set.
+type toggleWriter struct {
+	fail bool
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestLogger_WriteFailures(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "failures.log")
+	sink := &toggleWriter{fail: true}
+
+	var reported []error
+	l, err := NewLoggerWithOptions(Options{
+		Path:    logPath,
+		Level:   DEBUG,
+		Sinks:   []Sink{{Writer: sink, Format: FormatJSON}},
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		l.Info("Event", "Status", "Details")
+	}
+
+	stats := l.Stats()
+	if stats.ConsecutiveFailures != 5 || stats.TotalFailures != 5 {
+		t.Errorf("Stats() = %+v, want 5 consecutive and 5 total failures", stats)
+	}
+	// Reports are rate-limited to one per interval.
+	if len(reported) != 1 {
+		t.Errorf("OnError called %d times, want 1", len(reported))
+	}
+
+	// A successful write resets the consecutive counter only.
+	sink.fail = false
+	l.Info("Event", "Status", "Details")
+	stats = l.Stats()
+	if stats.ConsecutiveFailures != 0 || stats.TotalFailures != 5 {
+		t.Errorf("Stats() after recovery = %+v, want 0 consecutive and 5 total failures", stats)
+	}
+
+	// Close surfaces the first write error.
+	err = l.Close()
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Errorf("Close() error = %v, want the first write error", err)
+	}
+
+	// The file itself kept every entry despite the failing sink.
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("os.ReadFile() failed: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 6 {
+		t.Errorf("file lines = %d, want 6", got)
+	}
+}
+
+func TestLogger_OnErrorMayLog(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "reentrant.log")
+
+	var l *Logger
+	calls := 0
+	l, err := NewLoggerWithOptions(Options{
+		Path:  logPath,
+		Level: DEBUG,
+		Sinks: []Sink{{Writer: failingWriter{}, Format: FormatText}},
+		OnError: func(err error) {
+			calls++
+			// Logging from the handler must neither deadlock nor recurse forever.
+			l.Error("LoggingDegraded", "Failed", err.Error())
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	defer l.Close()
+
+	l.Info("Event", "Status", "Details")
+	if calls != 1 {
+		t.Errorf("OnError called %d times, want 1", calls)
+	}
+}
+
+func TestLogger_ModuleLevels(t *testing.T) {
+	var got []Entry
+	l, err := NewLogger(filepath.Join(t.TempDir(), "test.log"), INFO, func(e Entry) {
+		got = append(got, e)
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+	defer l.Close()
+
+	l.SetModuleLevel("hsm", DEBUG)
+	l.SetModuleLevel("storage", INFO)
+	l.SetModuleLevel("cleared", DEBUG)
+	l.ClearModuleLevel("cleared")
+
+	hsmLog := l.WithModule("hsm")
+	storageLog := l.WithModule("storage")
+
+	hsmLog.Debug("hsm_command", "Success", "written")
+	storageLog.Debug("key_store", "Success", "suppressed")
+	storageLog.Info("key_store", "Success", "written")
+	l.Debug("app", "Success", "suppressed by global level")
+	l.WithModule("crypto").Debug("key_generate", "Success", "suppressed by fallback")
+
+	entries, err := l.GetEntries(time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("GetEntries() returned %d entries, want 2: %+v", len(entries), entries)
+	}
+	if entries[0].Module != "hsm" || entries[0].Level != DEBUG {
+		t.Errorf("entries[0] = %s/%s, want hsm/DEBUG", entries[0].Module, entries[0].Level)
+	}
+	if entries[1].Module != "storage" || entries[1].Level != INFO {
+		t.Errorf("entries[1] = %s/%s, want storage/INFO", entries[1].Module, entries[1].Level)
+	}
+	l.WithModule("cleared").Debug("cleared", "Success", "suppressed after clear")
+
+	l.Close() // Wait for asynchronous callback delivery.
+	if len(got) != 2 {
+		t.Errorf("callback received %d entries, want 2", len(got))
+	}
+}
+
+func TestLevelFilter_Allows(t *testing.T) {
+	f := LevelFilter{Min: WARN, Modules: map[string]Level{"hsm": DEBUG}}
+	tests := []struct {
+		name   string
+		module string
+		level  Level
+		want   bool
+	}{
+		{"override_debug", "hsm", DEBUG, true},
+		{"fallback_info", "storage", INFO, false},
+		{"fallback_error", "storage", ERROR, true},
+		{"no_module_warn", "", WARN, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.Allows(tt.module, tt.level); got != tt.want {
+				t.Errorf("LevelFilter.Allows(%q, %s) = %v, want %v", tt.module, tt.level, got, tt.want)
 			}
-			if tt.wantErr && err.Error() != tt.expErrStr {
-				t.Errorf("GetEntries() error string = %q, want %q", err.Error(), tt.expErrStr)
+		})
+	}
+}
+
+func TestLogger_Reopen(t *testing.T) {
+	tests := []struct {
+		name    string
+		disturb func(t *testing.T, l *Logger, path string)
+	}{
+		{
+			name: "rename_and_recreate",
+			disturb: func(t *testing.T, l *Logger, path string) {
+				if err := os.Rename(path, path+".old"); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, nil, 0o640); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "deleted",
+			disturb: func(t *testing.T, l *Logger, path string) {
+				if err := os.Remove(path); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "close_then_reopen",
+			disturb: func(t *testing.T, l *Logger, path string) {
+				if err := l.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if err := l.Reopen(); err != nil {
+					t.Fatalf("Reopen() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			l, err := NewLogger(path, INFO, nil)
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+			defer l.Close()
+			l.checkInterval = 0 // Check on every write.
+
+			l.Info("before", "Success", "")
+			tt.disturb(t, l, path)
+			l.Info("after", "Success", "")
+
+			entries, err := l.GetEntries(time.Time{}, time.Time{}, "after")
+			if err != nil {
+				t.Fatalf("GetEntries() error = %v", err)
+			}
+			if len(entries) != 1 {
+				t.Fatalf("GetEntries() returned %d entries from %s, want 1", len(entries), path)
+			}
+			if stats := l.Stats(); stats.TotalFailures != 0 {
+				t.Errorf("Stats().TotalFailures = %d, want 0", stats.TotalFailures)
 			}
 		})
 	}
 }
+
+func TestLogger_AddCallback(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "callbacks.log")
+	l, err := NewLoggerWithOptions(Options{Path: logPath, Level: DEBUG, CallbackQueueSize: 2})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+
+	release := make(chan struct{})
+	var slow []Entry
+	l.AddCallback(func(e Entry) {
+		<-release // Deliberately stall delivery.
+		slow = append(slow, e)
+	})
+	var removed []Entry
+	_, remove := l.AddCallback(func(e Entry) { removed = append(removed, e) })
+	remove()
+	remove() // Removing twice is harmless.
+
+	const total = 10
+	start := time.Now()
+	for i := 0; i < total; i++ {
+		l.Info("Event", "Status", fmt.Sprintf("entry %d", i))
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("logging %d entries took %v with a stalled callback", total, elapsed)
+	}
+
+	entries, err := l.GetEntries(time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(entries) != total {
+		t.Errorf("file holds %d entries, want %d", len(entries), total)
+	}
+
+	close(release)
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	dropped := l.Stats().DroppedCallbacks
+	if dropped < total-3 {
+		t.Errorf("Stats().DroppedCallbacks = %d, want at least %d", dropped, total-3)
+	}
+	if uint64(len(slow))+dropped != total {
+		t.Errorf("delivered %d + dropped %d, want %d", len(slow), dropped, total)
+	}
+	if len(removed) != 0 {
+		t.Errorf("removed callback received %d entries", len(removed))
+	}
+}
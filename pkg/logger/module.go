@@ -0,0 +1,60 @@
+package logger
+
+// ModuleLogger logs entries tagged with a module name so their minimum level
+// can be tuned independently via SetModuleLevel.
+type ModuleLogger struct {
+	logger *Logger // nil resolves to Default at call time.
+	module string
+}
+
+// WithModule returns a child logger that tags entries with module.
+func (l *Logger) WithModule(module string) *ModuleLogger {
+	return &ModuleLogger{logger: l, module: module}
+}
+
+// WithModule returns a module logger backed by the package-level default
+// logger. It is safe to create before Init; entries are dropped until then.
+func WithModule(module string) *ModuleLogger {
+	return &ModuleLogger{module: module}
+}
+
+// Module returns the module name attached to entries.
+func (m *ModuleLogger) Module() string {
+	return m.module
+}
+
+// Log writes a log entry.
+func (m *ModuleLogger) Log(level Level, event, status, details string) {
+	m.LogFields(level, event, status, details, nil)
+}
+
+// LogFields writes a log entry carrying structured fields.
+func (m *ModuleLogger) LogFields(level Level, event, status, details string, fields map[string]string) {
+	l := m.logger
+	if l == nil {
+		if l = Default(); l == nil {
+			return
+		}
+	}
+	l.logEntry(m.module, level, event, status, details, fields)
+}
+
+// Debug logs a debug level message.
+func (m *ModuleLogger) Debug(event, status, details string) {
+	m.Log(DEBUG, event, status, details)
+}
+
+// Info logs an info level message.
+func (m *ModuleLogger) Info(event, status, details string) {
+	m.Log(INFO, event, status, details)
+}
+
+// Warn logs a warning level message.
+func (m *ModuleLogger) Warn(event, status, details string) {
+	m.Log(WARN, event, status, details)
+}
+
+// Error logs an error level message.
+func (m *ModuleLogger) Error(event, status, details string) {
+	m.Log(ERROR, event, status, details)
+}
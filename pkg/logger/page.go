@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultPageSize is the page size used when Query.PageSize is not set.
+	DefaultPageSize = 100
+	// backwardChunkSize is the read size used when scanning files from the end.
+	backwardChunkSize = 64 * 1024
+)
+
+var errInvalidCursor = errors.New("invalid log cursor")
+
+// Query selects a page of entries for GetEntriesPage.
+type Query struct {
+	// Start and End bound the entry timestamps; zero leaves a side open.
+	Start, End time.Time
+	// Filter is matched like the GetEntries filter.
+	Filter string
+	// MinLevel drops entries less severe than it.
+	MinLevel Level
+	// PageSize is the maximum number of entries returned (0 = default).
+	PageSize int
+	// Cursor continues from a previous page; empty starts at the newest entry.
+	Cursor string
+}
+
+// GetEntriesPage returns the newest page of matching entries older than the
+// cursor, in chronological order, and a cursor for the next older page. The
+// cursor is empty once the oldest entry has been returned. Files are read
+// backwards in chunks so only the requested page is held in memory. Cursors
+// stay valid while entries are appended but not across a rotation.
+func (l *Logger) GetEntriesPage(q Query) ([]Entry, string, error) {
+	backup, offset, err := parseCursor(q.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+
+	l.mu.Lock()
+	path := l.path
+	maxBackups := l.maxBackups
+	l.mu.Unlock()
+
+	filter := strings.ToLower(q.Filter)
+	var page []Entry // newest first.
+	done := false
+	for ; backup <= maxBackups && !done; backup, offset = backup+1, -1 {
+		p := path
+		if backup > 0 {
+			p = backupName(path, backup)
+		}
+
+		file, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, "", fmt.Errorf("failed to open log file: %v", err)
+		}
+		if offset < 0 {
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				return nil, "", fmt.Errorf("failed to stat log file: %v", err)
+			}
+			offset = info.Size()
+		}
+
+		err = scanBackward(file, offset, func(line []byte, start int64) bool {
+			offset = start
+			entry, err := decodeEntry(string(line))
+			if err != nil {
+				return true // Skip corrupt or partial lines.
+			}
+			if !q.Start.IsZero() && entry.Timestamp.Before(q.Start) {
+				done = true // Everything older is out of range too.
+				return false
+			}
+			if entry.Level.AtLeast(q.MinLevel) && entry.matches(q.Start, q.End, filter) {
+				page = append(page, entry)
+			}
+			return len(page) < q.PageSize
+		})
+		file.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read log file: %v", err)
+		}
+
+		if len(page) == q.PageSize && !done {
+			reverseEntries(page)
+			if offset > 0 {
+				return page, formatCursor(backup, offset), nil
+			}
+			if backup < maxBackups {
+				return page, formatCursor(backup+1, -1), nil
+			}
+			return page, "", nil
+		}
+	}
+
+	reverseEntries(page)
+
+	return page, "", nil
+}
+
+// TailEntries returns the last n entries in chronological order.
+func (l *Logger) TailEntries(n int) ([]Entry, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	entries, _, err := l.GetEntriesPage(Query{PageSize: n})
+
+	return entries, err
+}
+
+// scanBackward calls fn for every non-empty line that ends at or before end,
+// newest first, with the offset at which the line starts. It stops early when
+// fn returns false.
+func scanBackward(file *os.File, end int64, fn func(line []byte, start int64) bool) error {
+	var carry []byte
+	pos := end
+	for pos > 0 {
+		size := int64(backwardChunkSize)
+		if size > pos {
+			size = pos
+		}
+		pos -= size
+
+		buf := make([]byte, size, size+int64(len(carry)))
+		if _, err := file.ReadAt(buf, pos); err != nil {
+			return err
+		}
+		buf = append(buf, carry...)
+
+		// Text before the first newline may continue in the previous chunk.
+		for {
+			i := bytes.LastIndexByte(buf, '\n')
+			if i < 0 {
+				break
+			}
+			if line := buf[i+1:]; len(line) > 0 && !fn(line, pos+int64(i)+1) {
+				return nil
+			}
+			buf = buf[:i]
+		}
+		carry = buf
+	}
+	if len(carry) > 0 {
+		fn(carry, 0)
+	}
+
+	return nil
+}
+
+// formatCursor encodes a position in the given backup file (0 = current).
+// An offset of -1 means the end of that file.
+func formatCursor(backup int, offset int64) string {
+	return fmt.Sprintf("%d:%d", backup, offset)
+}
+
+// parseCursor decodes a cursor produced by formatCursor.
+func parseCursor(cursor string) (int, int64, error) {
+	if cursor == "" {
+		return 0, -1, nil
+	}
+
+	b, o, ok := strings.Cut(cursor, ":")
+	if !ok {
+		return 0, 0, errInvalidCursor
+	}
+	backup, err := strconv.Atoi(b)
+	if err != nil || backup < 0 {
+		return 0, 0, errInvalidCursor
+	}
+	offset, err := strconv.ParseInt(o, 10, 64)
+	if err != nil || offset < -1 {
+		return 0, 0, errInvalidCursor
+	}
+
+	return backup, offset, nil
+}
+
+// reverseEntries reverses entries in place.
+func reverseEntries(entries []Entry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+}
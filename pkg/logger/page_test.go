@@ -0,0 +1,158 @@
+// nolint:all // test package
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newSyntheticLog writes n entries spread across rotated files.
+func newSyntheticLog(t *testing.T, n int) *Logger {
+	t.Helper()
+
+	l, err := NewLoggerWithOptions(Options{
+		Path:         filepath.Join(t.TempDir(), "big.log"),
+		Level:        DEBUG,
+		MaxSizeBytes: 1024 * 1024,
+		MaxBackups:   10,
+	})
+	if err != nil {
+		t.Fatalf("NewLoggerWithOptions() failed: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	levels := []Level{DEBUG, INFO, WARN, ERROR}
+	for i := 0; i < n; i++ {
+		l.Log(levels[i%len(levels)], "synthetic", "Success", fmt.Sprintf("entry %06d padding %0100d", i, i))
+	}
+
+	return l
+}
+
+func TestLogger_GetEntriesPage(t *testing.T) {
+	l := newSyntheticLog(t, 20000) // ~3.5 MB over several files.
+
+	all, err := l.GetEntries(time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+	if len(all) != 20000 {
+		t.Fatalf("GetEntries() returned %d entries, want 20000", len(all))
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		wantFunc func(Entry) bool
+	}{
+		{"all", Query{PageSize: 1500}, func(Entry) bool { return true }},
+		{"min_level", Query{PageSize: 700, MinLevel: WARN}, func(e Entry) bool { return e.Level.AtLeast(WARN) }},
+		{"filter", Query{PageSize: 3, Filter: "entry 0123"}, func(e Entry) bool { return e.matches(time.Time{}, time.Time{}, "entry 0123") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want []Entry
+			for _, e := range all {
+				if tt.wantFunc(e) {
+					want = append(want, e)
+				}
+			}
+
+			// Walk pages backwards and stitch them together oldest first.
+			var got []Entry
+			q := tt.query
+			for pages := 0; ; pages++ {
+				if pages > len(want) {
+					t.Fatal("paging did not terminate")
+				}
+				page, next, err := l.GetEntriesPage(q)
+				if err != nil {
+					t.Fatalf("GetEntriesPage() error = %v", err)
+				}
+				if len(page) > q.PageSize {
+					t.Fatalf("GetEntriesPage() returned %d entries, page size %d", len(page), q.PageSize)
+				}
+				got = append(page, got...)
+				if next == "" {
+					break
+				}
+				q.Cursor = next
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("paged %d entries, want %d matching GetEntries", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestLogger_GetEntriesPage_CursorStable(t *testing.T) {
+	l := newSyntheticLog(t, 3000)
+
+	first, cursor, err := l.GetEntriesPage(Query{PageSize: 500})
+	if err != nil || cursor == "" {
+		t.Fatalf("GetEntriesPage() = %d entries, cursor %q, error %v", len(first), cursor, err)
+	}
+	second, _, err := l.GetEntriesPage(Query{PageSize: 500, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("GetEntriesPage() error = %v", err)
+	}
+
+	// New entries appended after the first page must not shift the cursor.
+	for i := 0; i < 10; i++ {
+		l.Info("appended", "Success", "")
+	}
+	again, _, err := l.GetEntriesPage(Query{PageSize: 500, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("GetEntriesPage() error = %v", err)
+	}
+	if !reflect.DeepEqual(second, again) {
+		t.Error("page for the same cursor changed after appending entries")
+	}
+	if len(second) != 500 || second[len(second)-1].Details >= first[0].Details {
+		t.Error("second page does not directly precede the first page")
+	}
+}
+
+func TestLogger_GetEntriesPage_InvalidCursor(t *testing.T) {
+	l := newSyntheticLog(t, 1)
+	for _, cursor := range []string{"x", "1", "-1:0", "0:-2", "a:b"} {
+		if _, _, err := l.GetEntriesPage(Query{Cursor: cursor}); err == nil {
+			t.Errorf("GetEntriesPage(cursor %q) error = nil, want error", cursor)
+		}
+	}
+}
+
+func TestLogger_TailEntries(t *testing.T) {
+	l := newSyntheticLog(t, 12000)
+
+	all, err := l.GetEntries(time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetEntries() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		n    int
+		want []Entry
+	}{
+		{"zero", 0, nil},
+		{"one", 1, all[len(all)-1:]},
+		{"across_files", 9000, all[len(all)-9000:]},
+		{"more_than_available", 50000, all},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.TailEntries(tt.n)
+			if err != nil {
+				t.Fatalf("TailEntries() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TailEntries(%d) returned %d entries, want %d", tt.n, len(got), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// a0KCVLength is the number of check value digits ending an A0 response.
+const a0KCVLength = 6
+
+// a0ErrorMessages describes the A0 specific error codes.
+var a0ErrorMessages = map[string]string{
+	"07": "invalid zka master key type",
+	"10": "zmk parity error: the zmk cryptogram does not decrypt to an odd parity key " +
+		"under the lmk - check the zmk value and that it belongs to this lmk",
+	"68": "command disabled",
+}
+
+// A0Response is a key under the LMK, generated by the A0 command, imported
+// by the A6 command or formed from components by the A4 command.
+type A0Response struct {
+	ResponseCode string
+	ErrorCode    string
+	Scheme       byte   // Key scheme the key was requested in.
+	Cryptogram   string // Key encrypted under the LMK, with its scheme tag.
+	CheckValue   string
+	Header       KeyBlockHeader // Set for key blocks (scheme 'S') only.
+
+	// Set when the key was also exported under a ZMK (mode 1).
+	ExportScheme     byte
+	ExportCryptogram string
+}
+
+// Length returns the key length in bytes, or zero when the scheme does not
+// imply one.
+func (r A0Response) Length() int {
+	return SchemeKeyLength(r.Scheme)
+}
+
+// ParseA0Response parses the response to an A0 command that generated a key
+// in scheme. On an HSM error the returned response holds the error code.
+func ParseA0Response(resp []byte, scheme byte) (A0Response, error) {
+	a0 := A0Response{Scheme: scheme}
+	if err := readA0Status(resp, &a0); err != nil {
+		return a0, err
+	}
+	r := NewFieldReader(resp[4:])
+
+	if scheme == 'S' {
+		return parseA0KeyBlock(r, a0)
+	}
+
+	// The encrypted key is followed by the 6-digit kcv.
+	if r.Remaining() <= a0KCVLength {
+		return a0, fmt.Errorf("response too short: %d bytes", len(resp))
+	}
+	a0.Cryptogram, _ = r.Take(r.Remaining() - a0KCVLength)
+	a0.CheckValue = r.TakeRest()
+	if err := ValidateCryptogramForScheme(a0.Cryptogram, scheme); err != nil {
+		return a0, fmt.Errorf("unexpected key in HSM response: %v", err)
+	}
+
+	return a0, nil
+}
+
+// parseA0KeyBlock reads the key block and kcv of a successful A0 response.
+// The key block is variable length; its header tells where it ends.
+func parseA0KeyBlock(r *FieldReader, a0 A0Response) (A0Response, error) {
+	rest, _ := r.Peek(r.Remaining())
+	hdr, err := ParseThalesKeyBlock(rest)
+	if err != nil {
+		return a0, fmt.Errorf("unexpected key in HSM response: %v", err)
+	}
+	if len(rest) != 1+hdr.Length+a0KCVLength {
+		return a0, fmt.Errorf(
+			"key block length %d does not match the response: %d characters after the error code",
+			hdr.Length,
+			len(rest),
+		)
+	}
+
+	a0.Cryptogram, _ = r.Take(1 + hdr.Length)
+	a0.CheckValue = r.TakeRest()
+	a0.Header = hdr
+
+	return a0, nil
+}
+
+// readA0Status reads the response and error codes of an A0 response into a0.
+// Responses too short for both codes are rejected.
+func readA0Status(resp []byte, a0 *A0Response) error {
+	status, err := ParseHSMResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	a0.ResponseCode = status.ResponseCode
+	if status.ResponseCode != "A1" {
+		return fmt.Errorf("unexpected response code: %s", status.ResponseCode)
+	}
+	a0.ErrorCode = status.ErrorCode
+	if !status.OK() {
+		if msg, ok := a0ErrorMessages[a0.ErrorCode]; ok {
+			return errors.New(msg)
+		}
+
+		return fmt.Errorf("error code %s", a0.ErrorCode)
+	}
+
+	return nil
+}
+
+// a0CryptogramLength returns the length of a key cryptogram in scheme as the
+// HSM returns it: Z keys are untagged, the others carry their tag.
+func a0CryptogramLength(scheme byte) int {
+	if scheme == 'Z' {
+		return schemeHexLengths[scheme]
+	}
+
+	return 1 + schemeHexLengths[scheme]
+}
+
+// ValidateZMK checks a ZMK cryptogram against the scheme its tag implies.
+func ValidateZMK(zmk string) error {
+	if err := ValidateCryptogramForScheme(zmk, CryptogramScheme(zmk)); err != nil {
+		return fmt.Errorf("invalid zmk: %v", err)
+	}
+
+	return nil
+}
+
+// BuildA0ExportCommand builds an A0 mode 1 command, which generates a key of
+// keyType in scheme under the Variant LMK and also exports it under zmk in
+// exportScheme, e.g. "A01001UU0123...CDEFX".
+func BuildA0ExportCommand(keyType string, scheme byte, zmk string, exportScheme byte) (string, error) {
+	if scheme == 'S' || exportScheme == 'S' {
+		return "", errors.New("key blocks cannot be exported by this command")
+	}
+	if err := ValidateKeyScheme(scheme); err != nil {
+		return "", err
+	}
+	if err := ValidateKeyScheme(exportScheme); err != nil {
+		return "", fmt.Errorf("export scheme: %v", err)
+	}
+	if err := ValidateZMK(zmk); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("A01%s%c%s%c", keyType, scheme, zmk, exportScheme), nil
+}
+
+// ParseA0ExportResponse parses the response to an A0 mode 1 command: the key
+// under the LMK in scheme, the key under the ZMK in exportScheme and the kcv.
+func ParseA0ExportResponse(resp []byte, scheme, exportScheme byte) (A0Response, error) {
+	a0 := A0Response{Scheme: scheme, ExportScheme: exportScheme}
+	if err := readA0Status(resp, &a0); err != nil {
+		return a0, err
+	}
+	r := NewFieldReader(resp[4:])
+
+	lmkLen, zmkLen := a0CryptogramLength(scheme), a0CryptogramLength(exportScheme)
+	if want := lmkLen + zmkLen + a0KCVLength; r.Remaining() != want {
+		return a0, fmt.Errorf(
+			"unexpected response length: %d characters after the error code, want %d",
+			r.Remaining(),
+			want,
+		)
+	}
+	a0.Cryptogram, _ = r.Take(lmkLen)
+	a0.ExportCryptogram, _ = r.Take(zmkLen)
+	a0.CheckValue = r.TakeRest()
+	if err := ValidateCryptogramForScheme(a0.Cryptogram, scheme); err != nil {
+		return a0, fmt.Errorf("unexpected key in HSM response: %v", err)
+	}
+	if err := ValidateCryptogramForScheme(a0.ExportCryptogram, exportScheme); err != nil {
+		return a0, fmt.Errorf("unexpected exported key in HSM response: %v", err)
+	}
+
+	return a0, nil
+}
+
+// ResponseField is a named field of a parsed HSM response.
+type ResponseField struct {
+	Name  string
+	Value string
+}
+
+// Fields lists the parsed fields of r for display, skipping the ones the
+// response did not reach.
+func (r A0Response) Fields() []ResponseField {
+	var fields []ResponseField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, ResponseField{Name: name, Value: value})
+		}
+	}
+
+	add("Response Code", r.ResponseCode)
+	if r.ErrorCode != "" {
+		add("Error Code", r.ErrorCode+" — "+HSMErrorDescription(r.ErrorCode))
+	}
+	add("Key under LMK", r.Cryptogram)
+	if r.Scheme == 'S' && r.Cryptogram != "" {
+		add("Key Block Header", r.Header.String())
+	}
+	add("Key under ZMK", r.ExportCryptogram)
+	add("KCV", r.CheckValue)
+
+	return fields
+}
@@ -0,0 +1,286 @@
+// nolint:all // test package
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Key blocks recorded from A0 responses under an AES and a 3DES key block LMK.
+const (
+	aesKeyBlock = "S10096K0TB00E0000CA978112CA1BBDCAFAC231B39A23DC4DA786EFF8147C4E72B9807785AFEE48BB3E23E8160039594A"
+	desKeyBlock = "S00072P0TE00N00013E23E8160039594A33894F6564E1B1348BBD7A0088D42C4ACA978112"
+)
+
+func TestParseA0Response(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		scheme  byte
+		want    A0Response
+		wantErr string
+	}{
+		{
+			"double_length",
+			"A100U0123456789ABCDEF0123456789ABCDEF08D7B4",
+			'U',
+			A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'U', Cryptogram: "U0123456789ABCDEF0123456789ABCDEF", CheckValue: "08D7B4"},
+			"",
+		},
+		{
+			"single_length_untagged",
+			"A1000123456789ABCDEFD5D44F",
+			'Z',
+			A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'Z', Cryptogram: "0123456789ABCDEF", CheckValue: "D5D44F"},
+			"",
+		},
+		{"hsm_error", "A168", 'U', A0Response{ResponseCode: "A1", ErrorCode: "68", Scheme: 'U'}, "command disabled"},
+		{"unknown_error", "A199", 'U', A0Response{ResponseCode: "A1", ErrorCode: "99", Scheme: 'U'}, "error code 99"},
+		{"wrong_command", "NC00", 'U', A0Response{ResponseCode: "NC", Scheme: 'U'}, "unexpected response code: NC"},
+		{"short", "A100U01", 'U', A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'U'}, "response too short: 7 bytes"},
+		{"empty", "", 'U', A0Response{Scheme: 'U'}, "response too short: 0 bytes"},
+		{"one_byte", "A", 'U', A0Response{Scheme: 'U'}, "response too short: 1 bytes"},
+		{"three_bytes", "A10", 'U', A0Response{Scheme: 'U'}, "response too short: 3 bytes"},
+		{"nine_bytes", "A100U0123", 'U', A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'U'},
+			"response too short: 9 bytes"},
+		{"other_service", "HTTP/1.1 400", 'U', A0Response{ResponseCode: "HT", Scheme: 'U'}, "unexpected response code: HT"},
+		{"garbled", "\x00\x01\x02\x03\x04", 'U', A0Response{Scheme: 'U'}, `invalid character '\x00' at offset 0`},
+		{"bad_cryptogram", "A100X0123456789ABCDEF0123456789ABCDEF08D7B4", 'U', A0Response{
+			ResponseCode: "A1", ErrorCode: "00", Scheme: 'U', Cryptogram: "X0123456789ABCDEF0123456789ABCDEF", CheckValue: "08D7B4",
+		}, "unexpected key in HSM response: key cryptogram must start with scheme tag 'U'"},
+		{"key_block_aes_lmk", "A100" + aesKeyBlock + "9C2E1F", 'S', A0Response{
+			ResponseCode: "A1", ErrorCode: "00", Scheme: 'S', Cryptogram: aesKeyBlock, CheckValue: "9C2E1F", Header: KeyBlockHeader{
+				Version: '1', Length: 96, KeyUsage: "K0", Algorithm: 'T', ModeOfUse: 'B',
+				KeyVersion: "00", Exportability: 'E', LMKID: "00",
+			},
+		}, ""},
+		{"key_block_3des_lmk", "A100" + desKeyBlock + "4A1B2C", 'S', A0Response{
+			ResponseCode: "A1", ErrorCode: "00", Scheme: 'S', Cryptogram: desKeyBlock, CheckValue: "4A1B2C", Header: KeyBlockHeader{
+				Version: '0', Length: 72, KeyUsage: "P0", Algorithm: 'T', ModeOfUse: 'E',
+				KeyVersion: "00", Exportability: 'N', LMKID: "01",
+			},
+		}, ""},
+		{"key_block_no_kcv", "A100" + aesKeyBlock, 'S', A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'S'},
+			"key block length 96 does not match the response: 97 characters after the error code"},
+		{"key_block_short", "A100S1009", 'S', A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'S'},
+			"unexpected key in HSM response: key block cryptogram too short: got 5 characters, want at least 17"},
+		{"key_block_error", "A110", 'S', A0Response{ResponseCode: "A1", ErrorCode: "10", Scheme: 'S'}, a0ErrorMessages["10"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseA0Response([]byte(tt.resp), tt.scheme)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseA0Response() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ParseA0Response() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseA0Response() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemeKeyLength(t *testing.T) {
+	for scheme, want := range map[byte]int{'Z': 8, 'U': 16, 'X': 16, 'T': 24, 'Y': 24, 'S': 0} {
+		if got := SchemeKeyLength(scheme); got != want {
+			t.Errorf("SchemeKeyLength(%c) = %d, want %d", scheme, got, want)
+		}
+	}
+}
+
+func TestBuildA0ExportCommand(t *testing.T) {
+	const zmk = "U0123456789ABCDEF0123456789ABCDEF"
+	tests := []struct {
+		name         string
+		keyType      string
+		scheme       byte
+		zmk          string
+		exportScheme byte
+		want         string
+		wantErr      bool
+	}{
+		{"double_length", "001", 'U', zmk, 'X', "A01001U" + zmk + "X", false},
+		{"single_length_zmk", "002", 'Z', "0123456789ABCDEF", 'Z', "A01002Z0123456789ABCDEFZ", false},
+		{"triple_length_zmk", "001", 'T', "T" + strings.Repeat("0123456789ABCDEF", 3), 'Y',
+			"A01001TT" + strings.Repeat("0123456789ABCDEF", 3) + "Y", false},
+		{"zmk_wrong_length", "001", 'U', "U0123456789ABCDEF", 'X', "", true},
+		{"zmk_not_hex", "001", 'U', "U0123456789ABCDEF0123456789ABCDEG", 'X', "", true},
+		{"empty_zmk", "001", 'U', "", 'X', "", true},
+		{"bad_export_scheme", "001", 'U', zmk, 'Q', "", true},
+		{"key_block", "001", 'S', zmk, 'X', "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildA0ExportCommand(tt.keyType, tt.scheme, tt.zmk, tt.exportScheme)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildA0ExportCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BuildA0ExportCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseA0ExportResponse(t *testing.T) {
+	const (
+		underLMK = "U0123456789ABCDEF0123456789ABCDEF"
+		underZMK = "X9876543210FEDCBA9876543210FEDCBA"
+	)
+	tests := []struct {
+		name         string
+		resp         string
+		scheme       byte
+		exportScheme byte
+		want         A0Response
+		wantErr      string
+	}{
+		{
+			"double_length",
+			"A100" + underLMK + underZMK + "08D7B4",
+			'U', 'X',
+			A0Response{
+				ResponseCode: "A1", ErrorCode: "00", Scheme: 'U', Cryptogram: underLMK, CheckValue: "08D7B4",
+				ExportScheme: 'X', ExportCryptogram: underZMK,
+			},
+			"",
+		},
+		{
+			"single_length_untagged",
+			"A1000123456789ABCDEFFEDCBA9876543210D5D44F",
+			'Z', 'Z',
+			A0Response{
+				ResponseCode: "A1", ErrorCode: "00", Scheme: 'Z', Cryptogram: "0123456789ABCDEF", CheckValue: "D5D44F",
+				ExportScheme: 'Z', ExportCryptogram: "FEDCBA9876543210",
+			},
+			"",
+		},
+		{
+			"zmk_parity",
+			"A110",
+			'U', 'X',
+			A0Response{ResponseCode: "A1", ErrorCode: "10", Scheme: 'U', ExportScheme: 'X'},
+			a0ErrorMessages["10"],
+		},
+		{
+			"missing_export",
+			"A100" + underLMK + "08D7B4",
+			'U', 'X',
+			A0Response{ResponseCode: "A1", ErrorCode: "00", Scheme: 'U', ExportScheme: 'X'},
+			"unexpected response length: 39 characters after the error code, want 72",
+		},
+		{
+			"wrong_export_tag",
+			"A100" + underLMK + "U9876543210FEDCBA9876543210FEDCBA" + "08D7B4",
+			'U', 'X',
+			A0Response{
+				ResponseCode: "A1", ErrorCode: "00", Scheme: 'U', Cryptogram: underLMK, CheckValue: "08D7B4",
+				ExportScheme: 'X', ExportCryptogram: "U9876543210FEDCBA9876543210FEDCBA",
+			},
+			"unexpected exported key in HSM response: key cryptogram must start with scheme tag 'X'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseA0ExportResponse([]byte(tt.resp), tt.scheme, tt.exportScheme)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseA0ExportResponse() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ParseA0ExportResponse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseA0ExportResponse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestA0Response_Fields(t *testing.T) {
+	const (
+		underLMK = "U0123456789ABCDEF0123456789ABCDEF"
+		underZMK = "X9876543210FEDCBA9876543210FEDCBA"
+	)
+	tests := []struct {
+		name    string
+		resp    string
+		parse   func([]byte) (A0Response, error)
+		want    []ResponseField
+		wantErr bool
+	}{
+		{
+			"exported",
+			"A100" + underLMK + underZMK + "08D7B4",
+			func(b []byte) (A0Response, error) { return ParseA0ExportResponse(b, 'U', 'X') },
+			[]ResponseField{
+				{"Response Code", "A1"},
+				{"Error Code", "00 — no error"},
+				{"Key under LMK", underLMK},
+				{"Key under ZMK", underZMK},
+				{"KCV", "08D7B4"},
+			},
+			false,
+		},
+		{
+			"key_block",
+			"A100" + aesKeyBlock + "9C2E1F",
+			func(b []byte) (A0Response, error) { return ParseA0Response(b, 'S') },
+			[]ResponseField{
+				{"Response Code", "A1"},
+				{"Error Code", "00 — no error"},
+				{"Key under LMK", aesKeyBlock},
+				{"Key Block Header", "K0 key encryption or wrapping, T triple DES, B encrypt and decrypt, " +
+					"version 00, E exportable under a trusted key, 0 optional blocks, LMK 00"},
+				{"KCV", "9C2E1F"},
+			},
+			false,
+		},
+		{
+			"zmk_parity",
+			"A710",
+			func(b []byte) (A0Response, error) { return ParseA6Response(b, 'U') },
+			[]ResponseField{
+				{"Response Code", "A7"},
+				{"Error Code", "10 — source key parity error"},
+			},
+			true,
+		},
+		{
+			"truncated",
+			"A100U01",
+			func(b []byte) (A0Response, error) { return ParseA0Response(b, 'U') },
+			[]ResponseField{
+				{"Response Code", "A1"},
+				{"Error Code", "00 — no error"},
+			},
+			true,
+		},
+		{
+			"too_short_for_codes",
+			"A1",
+			func(b []byte) (A0Response, error) { return ParseA0Response(b, 'U') },
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := tt.parse([]byte(tt.resp))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := key.Fields(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fields() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A4 component counts accepted by the HSM.
+const (
+	MinA4Components = 2
+	MaxA4Components = 9
+)
+
+// A4Request forms a key under the LMK from components that are themselves
+// encrypted under the LMK, as produced when custodians enter their clear
+// components at the HSM console.
+type A4Request struct {
+	KeyType    string   // 3-character key type code, e.g. "000" for a ZMK.
+	Scheme     byte     // Scheme of the formed key under the LMK.
+	Components []string // Components under the LMK.
+}
+
+// Validate checks the fields of r, including that every component fits the
+// scheme the key is formed in.
+func (r A4Request) Validate() error {
+	if len(r.KeyType) != 3 || !hexRegex.MatchString(r.KeyType) {
+		return fmt.Errorf("key type must be 3 hex characters, got %q", r.KeyType)
+	}
+	if r.Scheme == 'S' {
+		return fmt.Errorf("key blocks cannot be formed by this command")
+	}
+	if err := ValidateKeyScheme(r.Scheme); err != nil {
+		return err
+	}
+	if n := len(r.Components); n < MinA4Components || n > MaxA4Components {
+		return fmt.Errorf(
+			"number of components must be between %d and %d, got %d",
+			MinA4Components,
+			MaxA4Components,
+			n,
+		)
+	}
+	want := SchemeKeyLength(r.Scheme)
+	for i, c := range r.Components {
+		scheme := CryptogramScheme(c)
+		if err := ValidateCryptogramForScheme(c, scheme); err != nil {
+			return fmt.Errorf("invalid component %d: %v", i+1, err)
+		}
+		if got := SchemeKeyLength(scheme); got != want {
+			return fmt.Errorf(
+				"component %d is %d bytes long, scheme %c needs a %d byte key",
+				i+1,
+				got,
+				r.Scheme,
+				want,
+			)
+		}
+	}
+
+	return nil
+}
+
+// Command builds the A4 command, e.g. "A42000UU0123...CDEFU4567...CDEF".
+func (r A4Request) Command() (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"A4%d%s%c%s",
+		len(r.Components),
+		strings.ToUpper(r.KeyType),
+		r.Scheme,
+		strings.Join(r.Components, ""),
+	), nil
+}
+
+// ParseA4Response parses the response to an A4 command forming a key in
+// scheme. On an HSM error the returned response holds the error code.
+func ParseA4Response(resp []byte, scheme byte) (A0Response, error) {
+	return parseLMKKeyResponse(resp, "A5", scheme)
+}
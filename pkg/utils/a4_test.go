@@ -0,0 +1,82 @@
+// nolint:all // test package
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestA4Request_Command(t *testing.T) {
+	const (
+		c1 = "U0123456789ABCDEF0123456789ABCDEF"
+		c2 = "U9876543210FEDCBA9876543210FEDCBA"
+	)
+	tests := []struct {
+		name    string
+		req     A4Request
+		want    string
+		wantErr bool
+	}{
+		{"two_components", A4Request{KeyType: "000", Scheme: 'U', Components: []string{c1, c2}}, "A42000U" + c1 + c2, false},
+		{
+			"single_length",
+			A4Request{KeyType: "00a", Scheme: 'Z', Components: []string{"0123456789ABCDEF", "FEDCBA9876543210", "1111111111111111"}},
+			"A4300AZ0123456789ABCDEFFEDCBA98765432101111111111111111",
+			false,
+		},
+		{"one_component", A4Request{KeyType: "000", Scheme: 'U', Components: []string{c1}}, "", true},
+		{"ten_components", A4Request{KeyType: "000", Scheme: 'U', Components: make([]string, 10)}, "", true},
+		{"bad_key_type", A4Request{KeyType: "0G0", Scheme: 'U', Components: []string{c1, c2}}, "", true},
+		{"key_block", A4Request{KeyType: "000", Scheme: 'S', Components: []string{c1, c2}}, "", true},
+		{"length_mismatch", A4Request{KeyType: "000", Scheme: 'T', Components: []string{c1, c2}}, "", true},
+		{"bad_component", A4Request{KeyType: "000", Scheme: 'U', Components: []string{c1, "U0123"}}, "", true},
+		{"empty_component", A4Request{KeyType: "000", Scheme: 'U', Components: []string{c1, ""}}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Command()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseA4Response(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    A0Response
+		wantErr string
+	}{
+		{
+			"formed",
+			"A500U0123456789ABCDEF0123456789ABCDEF08D7B4",
+			A0Response{ResponseCode: "A5", ErrorCode: "00", Scheme: 'U', Cryptogram: "U0123456789ABCDEF0123456789ABCDEF", CheckValue: "08D7B4"},
+			"",
+		},
+		{"hsm_error", "A510", A0Response{ResponseCode: "A5", ErrorCode: "10", Scheme: 'U'}, "error code 10"},
+		{"wrong_response", "A700U0123456789ABCDEF0123456789ABCDEF08D7B4", A0Response{ResponseCode: "A7", Scheme: 'U'}, "unexpected response code: A7"},
+		{"truncated", "A500U0123456789ABCDEF", A0Response{ResponseCode: "A5", ErrorCode: "00", Scheme: 'U'}, "unexpected response length"},
+		{"empty", "", A0Response{Scheme: 'U'}, "response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseA4Response([]byte(tt.resp), 'U')
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("ParseA4Response() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("ParseA4Response() error = %v, want %q", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseA4Response() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A6Request imports a key received under a ZMK, translating it to the LMK.
+type A6Request struct {
+	KeyType string // 3-character key type code, e.g. "001" for a ZPK.
+	ZMK     string // ZMK under the LMK.
+	Key     string // Key under the ZMK.
+	Scheme  byte   // Scheme of the imported key under the LMK.
+}
+
+// Validate checks the fields of r, including that the key fits the scheme it
+// is imported in.
+func (r A6Request) Validate() error {
+	if len(r.KeyType) != 3 || !hexRegex.MatchString(r.KeyType) {
+		return fmt.Errorf("key type must be 3 hex characters, got %q", r.KeyType)
+	}
+	if err := ValidateZMK(r.ZMK); err != nil {
+		return err
+	}
+	keyScheme := CryptogramScheme(r.Key)
+	if err := ValidateCryptogramForScheme(r.Key, keyScheme); err != nil {
+		return fmt.Errorf("invalid key under zmk: %v", err)
+	}
+	if r.Scheme == 'S' {
+		return fmt.Errorf("key blocks cannot be imported by this command")
+	}
+	if err := ValidateKeyScheme(r.Scheme); err != nil {
+		return err
+	}
+	if got, want := SchemeKeyLength(keyScheme), SchemeKeyLength(r.Scheme); got != want {
+		return fmt.Errorf(
+			"key under zmk is %d bytes long, scheme %c needs a %d byte key",
+			got,
+			r.Scheme,
+			want,
+		)
+	}
+
+	return nil
+}
+
+// Command builds the A6 command, e.g. "A6001U0123...CDEFX0123...CDEFU".
+func (r A6Request) Command() (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("A6%s%s%s%c", strings.ToUpper(r.KeyType), r.ZMK, r.Key, r.Scheme), nil
+}
+
+// ParseA6Response parses the response to an A6 command importing a key in
+// scheme. HSM errors are described through the shared error table; on an
+// HSM error the returned response holds the error code.
+func ParseA6Response(resp []byte, scheme byte) (A0Response, error) {
+	return parseLMKKeyResponse(resp, "A7", scheme)
+}
+
+// parseLMKKeyResponse parses a response with responseCode that carries a key
+// under the LMK in scheme followed by its 6-digit kcv.
+func parseLMKKeyResponse(resp []byte, responseCode string, scheme byte) (A0Response, error) {
+	key := A0Response{Scheme: scheme}
+	status, err := ParseHSMResponse(resp)
+	if err != nil {
+		return key, err
+	}
+	key.ResponseCode = status.ResponseCode
+	if status.ResponseCode != responseCode {
+		return key, fmt.Errorf("unexpected response code: %s", status.ResponseCode)
+	}
+	key.ErrorCode = status.ErrorCode
+	if !status.OK() {
+		return key, fmt.Errorf("error code %s: %s", status.ErrorCode, status.Description())
+	}
+
+	// The key is followed by the 6-digit kcv.
+	body := resp[4:]
+	if want := a0CryptogramLength(scheme) + a0KCVLength; len(body) != want {
+		return key, fmt.Errorf(
+			"unexpected response length: %d characters after the error code, want %d",
+			len(body),
+			want,
+		)
+	}
+	key.Cryptogram = string(body[:len(body)-a0KCVLength])
+	key.CheckValue = string(body[len(body)-a0KCVLength:])
+	if err := ValidateCryptogramForScheme(key.Cryptogram, scheme); err != nil {
+		return key, fmt.Errorf("unexpected key in HSM response: %v", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,93 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestA6Request_Command(t *testing.T) {
+	const (
+		zmk = "U0123456789ABCDEF0123456789ABCDEF"
+		key = "X9876543210FEDCBA9876543210FEDCBA"
+	)
+	tests := []struct {
+		name    string
+		req     A6Request
+		want    string
+		wantErr bool
+	}{
+		{"double_length", A6Request{KeyType: "001", ZMK: zmk, Key: key, Scheme: 'U'}, "A6001" + zmk + key + "U", false},
+		{
+			"single_length",
+			A6Request{KeyType: "00a", ZMK: "0123456789ABCDEF", Key: "FEDCBA9876543210", Scheme: 'Z'},
+			"A600A0123456789ABCDEFFEDCBA9876543210Z",
+			false,
+		},
+		{"bad_key_type", A6Request{KeyType: "01", ZMK: zmk, Key: key, Scheme: 'U'}, "", true},
+		{"non_hex_key_type", A6Request{KeyType: "0G1", ZMK: zmk, Key: key, Scheme: 'U'}, "", true},
+		{"bad_zmk", A6Request{KeyType: "001", ZMK: "U0123", Key: key, Scheme: 'U'}, "", true},
+		{"non_hex_key", A6Request{KeyType: "001", ZMK: zmk, Key: "X9876543210FEDCBA9876543210FEDCBZ", Scheme: 'U'}, "", true},
+		{"length_mismatch", A6Request{KeyType: "001", ZMK: zmk, Key: key, Scheme: 'T'}, "", true},
+		{"key_block", A6Request{KeyType: "001", ZMK: zmk, Key: key, Scheme: 'S'}, "", true},
+		{"empty_key", A6Request{KeyType: "001", ZMK: zmk, Scheme: 'U'}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Command()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Command() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseA6Response(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		scheme  byte
+		want    A0Response
+		wantErr string
+	}{
+		{
+			"imported",
+			"A700U0123456789ABCDEF0123456789ABCDEF08D7B4",
+			'U',
+			A0Response{ResponseCode: "A7", ErrorCode: "00", Scheme: 'U', Cryptogram: "U0123456789ABCDEF0123456789ABCDEF", CheckValue: "08D7B4"},
+			"",
+		},
+		{
+			"single_length",
+			"A7000123456789ABCDEFD5D44F",
+			'Z',
+			A0Response{ResponseCode: "A7", ErrorCode: "00", Scheme: 'Z', Cryptogram: "0123456789ABCDEF", CheckValue: "D5D44F"},
+			"",
+		},
+		{"zmk_parity", "A710", 'U', A0Response{ResponseCode: "A7", ErrorCode: "10", Scheme: 'U'}, "error code 10: source key parity error"},
+		{"key_parity", "A711", 'U', A0Response{ResponseCode: "A7", ErrorCode: "11", Scheme: 'U'},
+			"error code 11: destination key parity error or key all zeros"},
+		{"wrong_command", "A100", 'U', A0Response{ResponseCode: "A1", Scheme: 'U'}, "unexpected response code: A1"},
+		{"short", "A700U0123456789ABCDEF08D7B4", 'U', A0Response{ResponseCode: "A7", ErrorCode: "00", Scheme: 'U'},
+			"unexpected response length: 23 characters after the error code, want 39"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseA6Response([]byte(tt.resp), tt.scheme)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseA6Response() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ParseA6Response() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseA6Response() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
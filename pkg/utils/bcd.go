@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// bcdPadNibble fills the low nibble of the last byte for odd digit counts.
+const bcdPadNibble = 0x0F
+
+// EncodeBCD packs a string of decimal digits into BCD, two digits per byte.
+// The first digit of each pair goes into the high nibble, so "1234" becomes
+// 0x12 0x34. An odd number of digits is left-aligned and the final low nibble
+// is padded with 0xF ("123" becomes 0x12 0x3F).
+func EncodeBCD(digits string) ([]byte, error) {
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return nil, fmt.Errorf("invalid BCD digit %q at position %d", digits[i], i)
+		}
+	}
+
+	out := make([]byte, (len(digits)+1)/2)
+	for i := 0; i < len(digits); i += 2 {
+		hi := digits[i] - '0'
+		lo := byte(bcdPadNibble)
+		if i+1 < len(digits) {
+			lo = digits[i+1] - '0'
+		}
+		out[i/2] = hi<<4 | lo
+	}
+
+	return out, nil
+}
+
+// DecodeBCD unpacks BCD data, high nibble first. Every nibble must be a
+// decimal digit; use DecodeBCDPadded for data padded with 0xF nibbles.
+func DecodeBCD(data []byte) (string, error) {
+	return decodeBCD(data, false)
+}
+
+// DecodeBCDPadded unpacks BCD data like DecodeBCD but treats trailing 0xF
+// nibbles as padding and drops them. An 0xF nibble followed by a digit is
+// still an error.
+func DecodeBCDPadded(data []byte) (string, error) {
+	return decodeBCD(data, true)
+}
+
+// decodeBCD unpacks data, optionally stripping trailing padding nibbles.
+func decodeBCD(data []byte, trimPadding bool) (string, error) {
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+
+	padded := false
+	for i, v := range data {
+		for j, nibble := range [2]byte{v >> 4, v & 0x0F} {
+			switch {
+			case nibble <= 9 && !padded:
+				b.WriteByte('0' + nibble)
+			case nibble == bcdPadNibble && trimPadding:
+				padded = true
+			default:
+				return "", fmt.Errorf(
+					"invalid BCD nibble %X at position %d",
+					nibble,
+					i*2+j,
+				)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,85 @@
+// nolint:all // test package
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeBCD(t *testing.T) {
+	tests := []struct {
+		name    string
+		digits  string
+		want    []byte
+		wantErr bool
+	}{
+		{"empty", "", []byte{}, false},
+		{"even", "1234", []byte{0x12, 0x34}, false},
+		{"odd_padded", "123", []byte{0x12, 0x3F}, false},
+		{"single_digit", "7", []byte{0x7F}, false},
+		{"zeros", "0000", []byte{0x00, 0x00}, false},
+		{"pan", "4111111111111111", []byte{0x41, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x11}, false},
+		{"letter", "12A4", nil, true},
+		{"space", "12 4", nil, true},
+		{"hex_f", "12F", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeBCD(tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeBCD(%q) error = %v, wantErr %v", tt.digits, err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("EncodeBCD(%q) = %X, want %X", tt.digits, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBCD(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		want       string
+		wantErr    bool
+		wantPadded string
+		paddedErr  bool
+	}{
+		{"empty", nil, "", false, "", false},
+		{"even", []byte{0x12, 0x34}, "1234", false, "1234", false},
+		{"odd_padding", []byte{0x12, 0x3F}, "", true, "123", false},
+		{"full_pad_byte", []byte{0x12, 0xFF}, "", true, "12", false},
+		{"pad_then_digit", []byte{0x1F, 0x23}, "", true, "", true},
+		{"non_decimal_nibble", []byte{0x1A}, "", true, "", true},
+		{"high_pad_nibble", []byte{0xF1}, "", true, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeBCD(tt.data)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("DecodeBCD(%X) = %q, %v; want %q, wantErr %v", tt.data, got, err, tt.want, tt.wantErr)
+			}
+
+			got, err = DecodeBCDPadded(tt.data)
+			if (err != nil) != tt.paddedErr || got != tt.wantPadded {
+				t.Errorf(
+					"DecodeBCDPadded(%X) = %q, %v; want %q, wantErr %v",
+					tt.data, got, err, tt.wantPadded, tt.paddedErr,
+				)
+			}
+		})
+	}
+}
+
+func TestBCD_RoundTrip(t *testing.T) {
+	for _, digits := range []string{"", "0", "12", "123", "9876543210", "41111111111111111"} {
+		data, err := EncodeBCD(digits)
+		if err != nil {
+			t.Fatalf("EncodeBCD(%q) error = %v", digits, err)
+		}
+		got, err := DecodeBCDPadded(data)
+		if err != nil || got != digits {
+			t.Errorf("DecodeBCDPadded(EncodeBCD(%q)) = %q, %v", digits, got, err)
+		}
+	}
+}
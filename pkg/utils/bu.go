@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// buErrorMessages describes the BU specific error codes.
+var buErrorMessages = map[string]string{
+	"10": "key parity error: the key does not decrypt to an odd parity key under the lmk " +
+		"- check the key value, its type and that it belongs to this lmk",
+	"68": "command disabled: enable BU in the HSM security settings",
+}
+
+// buLengthFlags maps Variant key schemes to the BU key length flag.
+var buLengthFlags = map[byte]byte{
+	'Z': '0',
+	'U': '1',
+	'X': '1',
+	'T': '2',
+	'Y': '2',
+}
+
+// BuildBUCommand builds a BU command generating the check value of key, a
+// cryptogram of keyType under the Variant LMK, e.g. "BUFF1U0123...CDEF;001".
+func BuildBUCommand(keyType, key string) (string, error) {
+	if len(keyType) != 3 || !hexRegex.MatchString(keyType) {
+		return "", fmt.Errorf("key type must be 3 hex characters, got %q", keyType)
+	}
+	scheme := CryptogramScheme(key)
+	if scheme == 'S' {
+		return "", errors.New("check values of key blocks cannot be verified by this command")
+	}
+	if err := ValidateCryptogramForScheme(key, scheme); err != nil {
+		return "", fmt.Errorf("invalid key: %v", err)
+	}
+
+	return fmt.Sprintf("BUFF%c%s;%s", buLengthFlags[scheme], key, strings.ToUpper(keyType)), nil
+}
+
+// ParseBUResponse returns the check value from the response to a BU command.
+func ParseBUResponse(resp []byte) (string, error) {
+	status, err := ParseHSMResponse(resp)
+	if err != nil {
+		return "", err
+	}
+	if status.ResponseCode != "BV" {
+		return "", fmt.Errorf("unexpected response code: %s", status.ResponseCode)
+	}
+	if !status.OK() {
+		if msg, ok := buErrorMessages[status.ErrorCode]; ok {
+			return "", errors.New(msg)
+		}
+
+		return "", fmt.Errorf("error code %s: %s", status.ErrorCode, status.Description())
+	}
+
+	kcv := string(resp[4:])
+	if len(kcv) != 6 && len(kcv) != 16 {
+		return "", fmt.Errorf("unexpected check value length: %d characters", len(kcv))
+	}
+	if !hexRegex.MatchString(kcv) {
+		return "", fmt.Errorf("check value %q is not hex", kcv)
+	}
+
+	return kcv, nil
+}
+
+// CheckValuesMatch reports whether two check values agree on the digits both
+// have, ignoring case. A 16-digit check value matches its 6-digit prefix.
+func CheckValuesMatch(a, b string) bool {
+	n := min(len(a), len(b))
+	if n == 0 {
+		return false
+	}
+
+	return strings.EqualFold(a[:n], b[:n])
+}
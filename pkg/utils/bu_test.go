@@ -0,0 +1,96 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestBuildBUCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyType string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"single_length", "001", "0123456789ABCDEF", "BUFF00123456789ABCDEF;001", false},
+		{"double_length", "00a", "U0123456789ABCDEF0123456789ABCDEF", "BUFF1U0123456789ABCDEF0123456789ABCDEF;00A", false},
+		{
+			"triple_length",
+			"000",
+			"T0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF",
+			"BUFF2T0123456789ABCDEF0123456789ABCDEF0123456789ABCDEF;000",
+			false,
+		},
+		{"bad_key_type", "1", "0123456789ABCDEF", "", true},
+		{"bad_length", "001", "U0123456789ABCDEF", "", true},
+		{"non_hex", "001", "U0123456789ABCDEF0123456789ABCDEZ", "", true},
+		{"key_block", "001", "S10096K0TB00E0000", "", true},
+		{"empty", "001", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildBUCommand(tt.keyType, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildBUCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BuildBUCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBUResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    string
+		wantErr string
+	}{
+		{"short_kcv", "BV0008D7B4", "08D7B4", ""},
+		{"long_kcv", "BV0008D7B4FB629D0885", "08D7B4FB629D0885", ""},
+		{"parity", "BV10", "", buErrorMessages["10"]},
+		{"disabled", "BV68", "", buErrorMessages["68"]},
+		{"other_error", "BV15", "", "error code 15: invalid input data"},
+		{"wrong_command", "A100", "", "unexpected response code: A1"},
+		{"truncated", "BV0008D7", "", "unexpected check value length: 4 characters"},
+		{"not_hex", "BV0008D7BZ", "", `check value "08D7BZ" is not hex`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBUResponse([]byte(tt.resp))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("ParseBUResponse() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ParseBUResponse() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseBUResponse() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckValuesMatch(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"08D7B4", "08D7B4", true},
+		{"08d7b4", "08D7B4", true},
+		{"08D7B4FB629D0885", "08D7B4", true},
+		{"08D7B4", "08D7B5", false},
+		{"", "08D7B4", false},
+	}
+
+	for _, tt := range tests {
+		if got := CheckValuesMatch(tt.a, tt.b); got != tt.want {
+			t.Errorf("CheckValuesMatch(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+)
+
+const (
+	// MinPINLength is the shortest PIN accepted by ValidatePIN.
+	MinPINLength = 4
+	// MaxPINLength is the longest PIN accepted by ValidatePIN.
+	MaxPINLength = 12
+	// MinPANLength is the shortest PAN accepted by ValidatePAN.
+	MinPANLength = 12
+	// MaxPANLength is the longest PAN accepted by ValidatePAN.
+	MaxPANLength = 19
+)
+
+// ValidatePIN checks that pin has 4 to 12 decimal digits.
+func ValidatePIN(pin string) error {
+	if pin == "" {
+		return fmt.Errorf("PIN cannot be empty")
+	}
+	if !numericRegex.MatchString(pin) {
+		return fmt.Errorf("PIN must contain digits only")
+	}
+	if len(pin) < MinPINLength || len(pin) > MaxPINLength {
+		return fmt.Errorf(
+			"PIN must be %d to %d digits, got %d",
+			MinPINLength,
+			MaxPINLength,
+			len(pin),
+		)
+	}
+
+	return nil
+}
+
+// ValidatePAN checks that pan has 12 to 19 decimal digits and, when
+// checkLuhn is set, a valid Luhn check digit.
+func ValidatePAN(pan string, checkLuhn bool) error {
+	if pan == "" {
+		return fmt.Errorf("PAN cannot be empty")
+	}
+	if !numericRegex.MatchString(pan) {
+		return fmt.Errorf("PAN must contain digits only")
+	}
+	if len(pan) < MinPANLength || len(pan) > MaxPANLength {
+		return fmt.Errorf(
+			"PAN must be %d to %d digits, got %d",
+			MinPANLength,
+			MaxPANLength,
+			len(pan),
+		)
+	}
+	if checkLuhn && !LuhnValid(pan) {
+		return fmt.Errorf("PAN check digit is invalid (Luhn check failed)")
+	}
+
+	return nil
+}
+
+// LuhnValid reports whether a string of decimal digits ends with a valid
+// Luhn (mod 10) check digit. It returns false for empty or non-numeric input.
+func LuhnValid(number string) bool {
+	if !numericRegex.MatchString(number) {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return sum%10 == 0
+}
@@ -0,0 +1,79 @@
+// nolint:all // test package
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePIN(t *testing.T) {
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr bool
+	}{
+		{"min_length", "1234", false},
+		{"max_length", "123456789012", false},
+		{"too_short", "123", true},
+		{"too_long", "1234567890123", true},
+		{"alphabetic", "12a4", true},
+		{"space", "12 34", true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePIN(tt.pin)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePIN(%q) error = %v, wantErr %v", tt.pin, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePAN(t *testing.T) {
+	tests := []struct {
+		name      string
+		pan       string
+		checkLuhn bool
+		wantErr   bool
+	}{
+		{"valid_16_luhn", "4111111111111111", true, false},
+		{"invalid_luhn", "4111111111111112", true, true},
+		{"invalid_luhn_unchecked", "4111111111111112", false, false},
+		{"min_length", "123456789012", false, false},
+		{"max_length", strings.Repeat("1", 19), false, false},
+		{"too_short", "12345678901", false, true},
+		{"too_long", strings.Repeat("1", 20), false, true},
+		{"alphabetic", "41111111111111AB", false, true},
+		{"dashes", "4111-1111-1111-1111", false, true},
+		{"empty", "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePAN(tt.pan, tt.checkLuhn)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePAN(%q, %v) error = %v, wantErr %v", tt.pan, tt.checkLuhn, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		want   bool
+	}{
+		{"4111111111111111", true},
+		{"5500005555555559", true},
+		{"79927398713", true},
+		{"79927398710", false},
+		{"0", true},
+		{"", false},
+		{"12a", false},
+	}
+	for _, tt := range tests {
+		if got := LuhnValid(tt.number); got != tt.want {
+			t.Errorf("LuhnValid(%q) = %v, want %v", tt.number, got, tt.want)
+		}
+	}
+}
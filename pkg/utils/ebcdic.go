@@ -0,0 +1,54 @@
+package utils
+
+import "fmt"
+
+// ebcdicPrintable maps the printable ASCII characters, from space to '~',
+// to EBCDIC code page 037.
+var ebcdicPrintable = [0x7F - 0x20]byte{
+	0x40, 0x5A, 0x7F, 0x7B, 0x5B, 0x6C, 0x50, 0x7D, 0x4D, 0x5D, 0x5C, 0x4E, 0x6B, 0x60, 0x4B, 0x61, // ' '-'/'
+	0xF0, 0xF1, 0xF2, 0xF3, 0xF4, 0xF5, 0xF6, 0xF7, 0xF8, 0xF9, 0x7A, 0x5E, 0x4C, 0x7E, 0x6E, 0x6F, // '0'-'?'
+	0x7C, 0xC1, 0xC2, 0xC3, 0xC4, 0xC5, 0xC6, 0xC7, 0xC8, 0xC9, 0xD1, 0xD2, 0xD3, 0xD4, 0xD5, 0xD6, // '@'-'O'
+	0xD7, 0xD8, 0xD9, 0xE2, 0xE3, 0xE4, 0xE5, 0xE6, 0xE7, 0xE8, 0xE9, 0xBA, 0xE0, 0xBB, 0xB0, 0x6D, // 'P'-'_'
+	0x79, 0x81, 0x82, 0x83, 0x84, 0x85, 0x86, 0x87, 0x88, 0x89, 0x91, 0x92, 0x93, 0x94, 0x95, 0x96, // '`'-'o'
+	0x97, 0x98, 0x99, 0xA2, 0xA3, 0xA4, 0xA5, 0xA6, 0xA7, 0xA8, 0xA9, 0xC0, 0x4F, 0xD0, 0xA1, // 'p'-'~'
+}
+
+// asciiFromEBCDIC is the inverse of ebcdicPrintable; zero marks EBCDIC
+// bytes without a printable ASCII character.
+var asciiFromEBCDIC = func() [256]byte {
+	var t [256]byte
+	for i, e := range ebcdicPrintable {
+		t[e] = byte(0x20 + i)
+	}
+
+	return t
+}()
+
+// encodeEBCDIC converts printable ASCII text to EBCDIC (code page 037).
+func encodeEBCDIC(s string) ([]byte, error) {
+	out := make([]byte, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c > 0x7E {
+			return nil, fmt.Errorf("character at position %d has no EBCDIC equivalent", i)
+		}
+		out[i] = ebcdicPrintable[c-0x20]
+	}
+
+	return out, nil
+}
+
+// decodeEBCDIC converts EBCDIC (code page 037) to printable ASCII text.
+func decodeEBCDIC(data []byte) (string, error) {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		c := asciiFromEBCDIC[b]
+		if c == 0 {
+			return "", fmt.Errorf(
+				"result is not printable EBCDIC (byte 0x%02X at position %d)", b, i)
+		}
+		out[i] = c
+	}
+
+	return string(out), nil
+}
@@ -0,0 +1,237 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DataFormat names a textual representation of binary data.
+type DataFormat string
+
+// Supported data formats.
+const (
+	DataHex    DataFormat = "Hex"
+	DataASCII  DataFormat = "ASCII"
+	DataBase64 DataFormat = "Base64"
+	// DataDecimal is the data as one unsigned big-endian integer. Leading
+	// zero bytes are not represented.
+	DataDecimal DataFormat = "Decimal"
+	// DataBinary is the data as a bit string, eight bits per byte.
+	DataBinary DataFormat = "Binary"
+	// DataEBCDIC is printable text in EBCDIC code page 037.
+	DataEBCDIC DataFormat = "EBCDIC"
+)
+
+// DataFormats lists the supported formats in display order.
+var DataFormats = []string{string(DataHex), string(DataASCII), string(DataBase64)}
+
+// DecodeData converts s from the given format to bytes. Hex input is
+// normalized first, so formatted hex is accepted; Base64 input may contain
+// whitespace.
+func DecodeData(s string, format DataFormat) ([]byte, error) {
+	switch format {
+	case DataHex:
+		clean, _ := NormalizeHexInput(s, 0)
+
+		return DecodeHexLenient(clean)
+	case DataASCII:
+		if i := nonPrintableIndex([]byte(s)); i >= 0 {
+			return nil, fmt.Errorf("non-printable ASCII character at position %d", i)
+		}
+
+		return []byte(s), nil
+	case DataBase64:
+		clean := strings.Join(strings.Fields(s), "")
+		data, err := base64.StdEncoding.DecodeString(clean)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64: %v", err)
+		}
+
+		return data, nil
+	case DataDecimal:
+		return decodeDecimal(s)
+	case DataBinary:
+		return decodeBinary(s)
+	case DataEBCDIC:
+		return encodeEBCDIC(s)
+	default:
+		return nil, fmt.Errorf("unsupported data format %q", format)
+	}
+}
+
+// EncodeData renders data in the given format. Hex output is uppercase and
+// ungrouped. ASCII output fails if data contains non-printable bytes.
+func EncodeData(data []byte, format DataFormat) (string, error) {
+	switch format {
+	case DataHex:
+		return strings.ToUpper(hex.EncodeToString(data)), nil
+	case DataASCII:
+		if i := nonPrintableIndex(data); i >= 0 {
+			return "", fmt.Errorf(
+				"result is not printable ASCII (byte 0x%02X at position %d)",
+				data[i],
+				i,
+			)
+		}
+
+		return string(data), nil
+	case DataBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case DataDecimal:
+		if len(data) == 0 {
+			return "", nil
+		}
+
+		return new(big.Int).SetBytes(data).String(), nil
+	case DataBinary:
+		var b strings.Builder
+		for _, c := range data {
+			fmt.Fprintf(&b, "%08b", c)
+		}
+
+		return b.String(), nil
+	case DataEBCDIC:
+		return decodeEBCDIC(data)
+	default:
+		return "", fmt.Errorf("unsupported data format %q", format)
+	}
+}
+
+// decodeDecimal converts a decimal number to its shortest big-endian bytes;
+// zero is one zero byte.
+func decodeDecimal(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if !numericRegex.MatchString(s) {
+		return nil, fmt.Errorf("invalid decimal number %q", s)
+	}
+	n, _ := new(big.Int).SetString(s, 10)
+	if n.Sign() == 0 {
+		return []byte{0}, nil
+	}
+
+	return n.Bytes(), nil
+}
+
+// decodeBinary converts a bit string, which may contain whitespace, to
+// bytes. Bit strings that are not a whole number of bytes are padded on
+// the left with zeros.
+func decodeBinary(s string) ([]byte, error) {
+	bits := strings.Join(strings.Fields(s), "")
+	if n := len(bits) % 8; n != 0 {
+		bits = strings.Repeat("0", 8-n) + bits
+	}
+	out := make([]byte, len(bits)/8)
+	for i := 0; i < len(bits); i++ {
+		switch bits[i] {
+		case '0':
+		case '1':
+			out[i/8] |= 0x80 >> (i % 8)
+		default:
+			return nil, fmt.Errorf("invalid binary digit %q", bits[i])
+		}
+	}
+
+	return out, nil
+}
+
+// nonPrintableIndex returns the index of the first byte outside the printable
+// ASCII range, or -1 if there is none.
+func nonPrintableIndex(data []byte) int {
+	for i, b := range data {
+		if b < 0x20 || b > 0x7E {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// FileContentToHex returns file content as an uppercase hex string. Content
+// that already is hex text (an optional 0x prefix, whitespace, colons and
+// dashes allowed, even digit count) is normalized instead of encoded; isHex
+// reports which case applied.
+func FileContentToHex(content []byte) (hexStr string, isHex bool) {
+	if clean, _ := NormalizeHexInput(string(content), 0); clean != "" &&
+		len(clean)%2 == 0 && hexRegex.MatchString(clean) {
+		return clean, true
+	}
+
+	return strings.ToUpper(hex.EncodeToString(content)), false
+}
+
+// ASCIIToHex returns the bytes of s as an uppercase hex string.
+func ASCIIToHex(s string) string {
+	return strings.ToUpper(hex.EncodeToString([]byte(s)))
+}
+
+// PrintableASCII renders data as text, replacing bytes outside the printable
+// ASCII range with '.'.
+func PrintableASCII(data []byte) string {
+	out := make([]byte, len(data))
+	for i, b := range data {
+		if b < 0x20 || b > 0x7E {
+			b = '.'
+		}
+		out[i] = b
+	}
+
+	return string(out)
+}
+
+// UnescapeBytes converts text with \xNN escapes to raw bytes. A doubled
+// backslash stands for a literal backslash; any other use of a backslash,
+// including an incomplete trailing escape, is an error.
+func UnescapeBytes(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			out = append(out, s[i])
+			continue
+		}
+		switch {
+		case i+1 < len(s) && s[i+1] == '\\':
+			out = append(out, '\\')
+			i++
+		case i+1 < len(s) && s[i+1] == 'x':
+			if i+4 > len(s) {
+				return nil, fmt.Errorf("incomplete escape at position %d", i)
+			}
+			b, err := hex.DecodeString(s[i+2 : i+4])
+			if err != nil {
+				return nil, fmt.Errorf("invalid escape %q at position %d", s[i:i+4], i)
+			}
+			out = append(out, b[0])
+			i += 3
+		case i+1 == len(s):
+			return nil, fmt.Errorf("incomplete escape at position %d", i)
+		default:
+			return nil, fmt.Errorf("invalid escape %q at position %d", s[i:i+2], i)
+		}
+	}
+
+	return out, nil
+}
+
+// EscapeBytes renders data as text, the inverse of UnescapeBytes: bytes
+// outside the printable ASCII range become \xNN and backslashes are doubled.
+func EscapeBytes(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		switch {
+		case c == '\\':
+			b.WriteString(`\\`)
+		case c < 0x20 || c > 0x7E:
+			fmt.Fprintf(&b, `\x%02X`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
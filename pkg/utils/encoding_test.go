@@ -0,0 +1,215 @@
+// nolint:all // test package
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		format  DataFormat
+		want    []byte
+		wantErr bool
+	}{
+		{"hex", "48656C6C6F", DataHex, []byte("Hello"), false},
+		{"hex_formatted", "0x48 65:6c-6C 6F", DataHex, []byte("Hello"), false},
+		{"hex_invalid", "48656G", DataHex, nil, true},
+		{"ascii", "Hello", DataASCII, []byte("Hello"), false},
+		{"ascii_non_printable", "Hel\nlo", DataASCII, nil, true},
+		{"base64", "SGVsbG8=", DataBase64, []byte("Hello"), false},
+		{"base64_whitespace", "SGVs\nbG8=", DataBase64, []byte("Hello"), false},
+		{"base64_invalid", "SGVsbG8", DataBase64, nil, true},
+		{"decimal", "4294967296", DataDecimal, []byte{0x01, 0x00, 0x00, 0x00, 0x00}, false},
+		{"decimal_zero", "0", DataDecimal, []byte{0x00}, false},
+		{"decimal_invalid", "12A", DataDecimal, nil, true},
+		{"binary", "01001000 01101001", DataBinary, []byte("Hi"), false},
+		{"binary_padded", "1", DataBinary, []byte{0x01}, false},
+		{"binary_invalid", "0102", DataBinary, nil, true},
+		{"ebcdic", "Hi 1!", DataEBCDIC, []byte{0xC8, 0x89, 0x40, 0xF1, 0x5A}, false},
+		{"ebcdic_non_printable", "Hi	", DataEBCDIC, nil, true},
+		{"unknown_format", "Hello", DataFormat("UTF-16"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeData(tt.input, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DecodeData() = %X, want %X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeData(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		format  DataFormat
+		want    string
+		wantErr bool
+	}{
+		{"hex", []byte{0xAB, 0x01}, DataHex, "AB01", false},
+		{"ascii", []byte("Hello"), DataASCII, "Hello", false},
+		{"ascii_non_printable", []byte{0x48, 0x00}, DataASCII, "", true},
+		{"base64", []byte("Hello"), DataBase64, "SGVsbG8=", false},
+		{"empty", nil, DataBase64, "", false},
+		{"decimal", []byte{0x01, 0x00, 0x00, 0x00, 0x00}, DataDecimal, "4294967296", false},
+		{"decimal_empty", nil, DataDecimal, "", false},
+		{"binary", []byte("Hi"), DataBinary, "0100100001101001", false},
+		{"ebcdic", []byte{0xC8, 0x89, 0x40, 0xF1, 0x5A}, DataEBCDIC, "Hi 1!", false},
+		{"ebcdic_non_printable", []byte{0xC8, 0x00}, DataEBCDIC, "", true},
+		{"unknown_format", []byte("Hello"), DataFormat("UTF-16"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeData(tt.input, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeData() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileContentToHex(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   []byte
+		want      string
+		wantIsHex bool
+	}{
+		{"binary", []byte{0x00, 0xFF, 0x10}, "00FF10", false},
+		{"hex_text", []byte("0123abcd\n"), "0123ABCD", true},
+		{"formatted_hex_text", []byte("0x01 23:AB-cd\r\n"), "0123ABCD", true},
+		{"odd_hex_digits", []byte("ABC"), "414243", false},
+		{"ascii_text", []byte("Hello"), "48656C6C6F", false},
+		{"hex_looking_ascii", []byte("CAFE BABE!"), "43414645204241424521", false},
+		{"empty", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isHex := FileContentToHex(tt.content)
+			if got != tt.want || isHex != tt.wantIsHex {
+				t.Errorf("FileContentToHex() = %q, %v, want %q, %v", got, isHex, tt.want, tt.wantIsHex)
+			}
+		})
+	}
+}
+
+func TestASCIIToHex(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"terminal_id", "TERM0001", "5445524D30303031"},
+		{"spaces_kept", "a b", "612062"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ASCIIToHex(tt.input); got != tt.want {
+				t.Errorf("ASCIIToHex() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintableASCII(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"empty", nil, ""},
+		{"printable", []byte("Hello ~"), "Hello ~"},
+		{"non_printable", []byte{0x00, 'A', 0x7F, 0xFF, '\n'}, ".A..."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrintableASCII(tt.input); got != tt.want {
+				t.Errorf("PrintableASCII() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []byte
+		wantErr bool
+	}{
+		{"plain", "NC", []byte("NC"), false},
+		{"delimiter", `A0\x00B\x1c`, []byte{'A', '0', 0x00, 'B', 0x1C}, false},
+		{"lower_case_hex", `\xff\x80`, []byte{0xFF, 0x80}, false},
+		{"literal_backslash", `A\\B`, []byte(`A\B`), false},
+		{"escaped_backslash_before_x", `\\x41`, []byte(`\x41`), false},
+		{"empty", "", []byte{}, false},
+		{"trailing_backslash", `NC\`, nil, true},
+		{"trailing_incomplete_escape", `NC\x4`, nil, true},
+		{"trailing_bare_x", `NC\x`, nil, true},
+		{"invalid_hex", `\xZZ`, nil, true},
+		{"unknown_escape", `\n`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := UnescapeBytes(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnescapeBytes(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, tt.want) {
+				t.Errorf("UnescapeBytes(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeBytes(t *testing.T) {
+	data := []byte{'A', '0', 0x00, '\\', 0x80, '~'}
+	want := `A0\x00\\\x80~`
+	if got := EscapeBytes(data); got != want {
+		t.Errorf("EscapeBytes() = %q, want %q", got, want)
+	}
+	back, err := UnescapeBytes(want)
+	if err != nil || !bytes.Equal(back, data) {
+		t.Errorf("UnescapeBytes(EscapeBytes()) = %q, %v", back, err)
+	}
+}
+
+func TestEBCDICRoundTrip(t *testing.T) {
+	var ascii []byte
+	for c := byte(0x20); c <= 0x7E; c++ {
+		ascii = append(ascii, c)
+	}
+
+	data, err := DecodeData(string(ascii), DataEBCDIC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[byte]bool)
+	for _, b := range data {
+		if seen[b] {
+			t.Fatalf("EBCDIC byte 0x%02X used twice", b)
+		}
+		seen[b] = true
+	}
+	got, err := EncodeData(data, DataEBCDIC)
+	if err != nil || got != string(ascii) {
+		t.Errorf("EncodeData() = %q, %v, want %q", got, err, ascii)
+	}
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+)
+
+// FieldReader consumes fixed-width fields from an HSM response, returning
+// errors instead of panicking when the data runs out.
+type FieldReader struct {
+	data []byte
+	pos  int
+}
+
+// NewFieldReader creates a reader positioned at the start of data.
+func NewFieldReader(data []byte) *FieldReader {
+	return &FieldReader{data: data}
+}
+
+// Take consumes and returns the next n bytes.
+func (r *FieldReader) Take(n int) (string, error) {
+	field, err := r.Peek(n)
+	if err != nil {
+		return "", err
+	}
+	r.pos += n
+
+	return field, nil
+}
+
+// TakeRest consumes and returns all remaining bytes.
+func (r *FieldReader) TakeRest() string {
+	rest := string(r.data[r.pos:])
+	r.pos = len(r.data)
+
+	return rest
+}
+
+// Peek returns the next n bytes without consuming them.
+func (r *FieldReader) Peek(n int) (string, error) {
+	if n < 0 {
+		return "", fmt.Errorf("invalid field length %d", n)
+	}
+	if n > r.Remaining() {
+		return "", fmt.Errorf(
+			"response too short: need %d bytes at offset %d, have %d",
+			n,
+			r.pos,
+			r.Remaining(),
+		)
+	}
+
+	return string(r.data[r.pos : r.pos+n]), nil
+}
+
+// Remaining returns the number of unconsumed bytes.
+func (r *FieldReader) Remaining() int {
+	return len(r.data) - r.pos
+}
+
+// Offset returns the number of bytes consumed so far.
+func (r *FieldReader) Offset() int {
+	return r.pos
+}
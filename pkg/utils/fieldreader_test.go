@@ -0,0 +1,81 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestFieldReader_Take(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		takes   []int
+		want    []string
+		wantErr bool
+		wantRem int
+	}{
+		{"exact_length", "A100U1234", []int{2, 2, 5}, []string{"A1", "00", "U1234"}, false, 0},
+		{"partial", "A100U1234", []int{2, 2}, []string{"A1", "00"}, false, 5},
+		{"zero_length", "A1", []int{0, 2}, []string{"", "A1"}, false, 0},
+		{"over_read", "A10", []int{2, 2}, []string{"A1"}, true, 1},
+		{"empty_input", "", []int{1}, nil, true, 0},
+		{"negative", "A1", []int{-1}, nil, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewFieldReader([]byte(tt.data))
+			var got []string
+			var err error
+			for _, n := range tt.takes {
+				var field string
+				if field, err = r.Take(n); err != nil {
+					break
+				}
+				got = append(got, field)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Take() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Take() fields = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("Take() field %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+			if r.Remaining() != tt.wantRem {
+				t.Errorf("Remaining() = %d, want %d", r.Remaining(), tt.wantRem)
+			}
+		})
+	}
+}
+
+func TestFieldReader_PeekAndRest(t *testing.T) {
+	r := NewFieldReader([]byte("NDversion"))
+
+	if got, err := r.Peek(2); err != nil || got != "ND" {
+		t.Fatalf("Peek(2) = %q, %v; want \"ND\", nil", got, err)
+	}
+	if r.Offset() != 0 || r.Remaining() != 9 {
+		t.Errorf("Peek consumed input: offset %d, remaining %d", r.Offset(), r.Remaining())
+	}
+	if _, err := r.Peek(10); err == nil {
+		t.Error("Peek(10) error = nil, want error")
+	}
+
+	r.Take(2)
+	if got := r.TakeRest(); got != "version" {
+		t.Errorf("TakeRest() = %q, want \"version\"", got)
+	}
+	if got := r.TakeRest(); got != "" {
+		t.Errorf("TakeRest() on exhausted reader = %q, want \"\"", got)
+	}
+	if r.Remaining() != 0 || r.Offset() != 9 {
+		t.Errorf("after TakeRest: offset %d, remaining %d", r.Offset(), r.Remaining())
+	}
+
+	if got := NewFieldReader(nil).TakeRest(); got != "" {
+		t.Errorf("TakeRest() on empty input = %q, want \"\"", got)
+	}
+}
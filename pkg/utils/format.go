@@ -0,0 +1,122 @@
+package utils
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// hexDumpWidth is the number of bytes shown per HexDump line.
+const hexDumpWidth = 16
+
+// hexFormattingStripper removes the separators accepted in formatted hex.
+var hexFormattingStripper = strings.NewReplacer(" ", "", ":", "", "-", "")
+
+// FormatHex validates a hex string, uppercases it and inserts separator every
+// groupSize characters. A groupSize of 0 disables grouping.
+func FormatHex(s string, groupSize int, separator string) (string, error) {
+	clean := strings.ToUpper(StripHexFormatting(s))
+	if clean == "" {
+		return "", nil
+	}
+	if !hexRegex.MatchString(clean) {
+		return "", fmt.Errorf("invalid hex string")
+	}
+	if len(clean)%2 != 0 {
+		return "", fmt.Errorf("hex string length must be even")
+	}
+	if groupSize <= 0 || groupSize >= len(clean) {
+		return clean, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(clean) + len(clean)/groupSize*len(separator))
+	for i := 0; i < len(clean); i += groupSize {
+		if i > 0 {
+			b.WriteString(separator)
+		}
+		b.WriteString(clean[i:min(i+groupSize, len(clean))])
+	}
+
+	return b.String(), nil
+}
+
+// NormalizeHexInput removes a leading 0x prefix, whitespace (including tabs
+// and line breaks), colons and dashes from s, uppercases it and truncates it to maxHexDigits
+// characters (0 = unlimited). Other characters are kept so validation can
+// report them. changed reports whether the result differs from s, letting an
+// entry skip SetText and keep its cursor when nothing changed.
+func NormalizeHexInput(s string, maxHexDigits int) (string, bool) {
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	if strings.HasPrefix(trimmed, "0x") || strings.HasPrefix(trimmed, "0X") {
+		trimmed = trimmed[2:]
+	}
+
+	clean := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == ':' || r == '-' {
+			return -1
+		}
+
+		return unicode.ToUpper(r)
+	}, trimmed)
+	if maxHexDigits > 0 && len(clean) > maxHexDigits {
+		clean = clean[:maxHexDigits]
+	}
+
+	return clean, clean != s
+}
+
+// StripHexFormatting removes spaces, colons and dashes from s, undoing
+// FormatHex.
+func StripHexFormatting(s string) string {
+	return hexFormattingStripper.Replace(s)
+}
+
+// HexDump renders data in the classic 16-bytes-per-line layout: an offset,
+// two groups of eight uppercase hex bytes and an ASCII gutter in which
+// non-printable bytes appear as '.'. Lines are separated by newlines with no
+// trailing newline; empty data yields an empty string.
+func HexDump(data []byte) string {
+	var b strings.Builder
+	for off := 0; off < len(data); off += hexDumpWidth {
+		line := data[off:min(off+hexDumpWidth, len(data))]
+		if off > 0 {
+			b.WriteByte('\n')
+		}
+
+		fmt.Fprintf(&b, "%08X ", off)
+		for i := 0; i < hexDumpWidth; i++ {
+			if i == hexDumpWidth/2 {
+				b.WriteByte(' ')
+			}
+			if i < len(line) {
+				fmt.Fprintf(&b, " %02X", line[i])
+			} else {
+				b.WriteString("   ")
+			}
+		}
+
+		b.WriteString("  |")
+		for _, c := range line {
+			if c < 0x20 || c > 0x7E {
+				c = '.'
+			}
+			b.WriteByte(c)
+		}
+		b.WriteByte('|')
+	}
+
+	return b.String()
+}
+
+// HexDumpString decodes a hex string, ignoring formatting, and renders it with
+// HexDump.
+func HexDumpString(hexStr string) (string, error) {
+	data, err := hex.DecodeString(StripHexFormatting(hexStr))
+	if err != nil {
+		return "", fmt.Errorf("invalid hex string: %v", err)
+	}
+
+	return HexDump(data), nil
+}
@@ -0,0 +1,146 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestFormatHex(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		groupSize int
+		separator string
+		want      string
+		wantErr   bool
+	}{
+		{"empty", "", 8, " ", "", false},
+		{"no_grouping", "0123abcd", 0, " ", "0123ABCD", false},
+		{"group_8", "0123456789abcdef01", 8, " ", "01234567 89ABCDEF 01", false},
+		{"group_2_colon", "0a1b2c", 2, ":", "0A:1B:2C", false},
+		{"shorter_than_group", "abcd", 8, " ", "ABCD", false},
+		{"already_formatted", "01 23-45:67", 4, " ", "0123 4567", false},
+		{"odd_length", "abc", 8, " ", "", true},
+		{"invalid_char", "zz", 8, " ", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatHex(tt.input, tt.groupSize, tt.separator)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FormatHex(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FormatHex(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripHexFormatting_RoundTrip(t *testing.T) {
+	inputs := []string{"", "00", "0123456789ABCDEF", "0123456789ABCDEF0123456789ABCDEF01"}
+	for _, in := range inputs {
+		for _, sep := range []string{" ", ":", "-"} {
+			formatted, err := FormatHex(in, 4, sep)
+			if err != nil {
+				t.Fatalf("FormatHex(%q) error = %v", in, err)
+			}
+			if got := StripHexFormatting(formatted); got != in {
+				t.Errorf("StripHexFormatting(%q) = %q, want %q", formatted, got, in)
+			}
+		}
+	}
+}
+
+func TestHexDump(t *testing.T) {
+	seq := func(n int) []byte {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(0x30 + i)
+		}
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", nil, ""},
+		{
+			"15_bytes",
+			seq(15),
+			"00000000  30 31 32 33 34 35 36 37  38 39 3A 3B 3C 3D 3E     |0123456789:;<=>|",
+		},
+		{
+			"16_bytes",
+			seq(16),
+			"00000000  30 31 32 33 34 35 36 37  38 39 3A 3B 3C 3D 3E 3F  |0123456789:;<=>?|",
+		},
+		{
+			"33_bytes",
+			append(append(seq(16), 0x00, 0x1F, 0x7F, 0xFF, 'A', 'b', ' ', '~'), seq(9)...),
+			"00000000  30 31 32 33 34 35 36 37  38 39 3A 3B 3C 3D 3E 3F  |0123456789:;<=>?|\n" +
+				"00000010  00 1F 7F FF 41 62 20 7E  30 31 32 33 34 35 36 37  |....Ab ~01234567|\n" +
+				"00000020  38                                                |8|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HexDump(tt.data); got != tt.want {
+				t.Errorf("HexDump() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHexDumpString(t *testing.T) {
+	got, err := HexDumpString("41 42:43")
+	if err != nil {
+		t.Fatalf("HexDumpString() error = %v", err)
+	}
+	if want := HexDump([]byte("ABC")); got != want {
+		t.Errorf("HexDumpString() = %q, want %q", got, want)
+	}
+
+	if _, err := HexDumpString("4G"); err == nil {
+		t.Error("HexDumpString(\"4G\") error = nil, want error")
+	}
+	if _, err := HexDumpString("414"); err == nil {
+		t.Error("HexDumpString(\"414\") error = nil, want error")
+	}
+}
+
+func TestNormalizeHexInput(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		max         int
+		want        string
+		wantChanged bool
+	}{
+		{"empty", "", 0, "", false},
+		{"already_clean", "0123ABCD", 0, "0123ABCD", false},
+		{"lowercase", "0123abcd", 0, "0123ABCD", true},
+		{"pasted_block_crlf", "0123 4567\r\n89AB CDEF\r\n", 0, "0123456789ABCDEF", true},
+		{"tabs", "01\t23\t45", 0, "012345", true},
+		{"separators", "01:23-45:67", 0, "01234567", true},
+		{"truncate", "0123456789ABCDEF00", 16, "0123456789ABCDEF", true},
+		{"at_limit", "0123456789ABCDEF", 16, "0123456789ABCDEF", false},
+		{"truncate_after_cleaning", "01 23 45 67", 6, "012345", true},
+		{"keeps_invalid_chars", "01zz", 0, "01ZZ", true},
+		{"prefix", " 0x1a2b", 0, "1A2B", true},
+		{"prefix_not_counted_in_limit", "0X0123456789ABCDEF", 16, "0123456789ABCDEF", true},
+		{"prefix_only", "0x", 0, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := NormalizeHexInput(tt.input, tt.max)
+			if got != tt.want || changed != tt.wantChanged {
+				t.Errorf(
+					"NormalizeHexInput(%q, %d) = %q, %v; want %q, %v",
+					tt.input, tt.max, got, changed, tt.want, tt.wantChanged,
+				)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// hsmErrorCodes maps Thales payShield error codes to their meaning.
+var hsmErrorCodes = map[string]string{
+	"00": "no error",
+	"01": "verification failure or key parity warning",
+	"02": "key inappropriate length for algorithm",
+	"04": "invalid key type code",
+	"05": "invalid key length flag",
+	"10": "source key parity error",
+	"11": "destination key parity error or key all zeros",
+	"12": "contents of user storage not available",
+	"13": "master key parity error",
+	"14": "PIN encrypted under LMK pair 02-03 is invalid",
+	"15": "invalid input data",
+	"16": "console or printer not ready or not connected",
+	"17": "HSM not authorised, or operation prohibited by security settings",
+	"18": "document format definition not loaded",
+	"19": "specified Diebold table is invalid",
+	"20": "PIN block does not contain valid values",
+	"21": "invalid index value, or index/block count would overflow",
+	"22": "invalid account number",
+	"23": "invalid PIN block format code",
+	"24": "PIN is fewer than 4 or more than 12 digits in length",
+	"25": "decimalisation table error",
+	"26": "invalid key scheme",
+	"27": "incompatible key length",
+	"28": "invalid key type",
+	"29": "key function not permitted",
+	"30": "invalid reference number",
+	"31": "insufficient solicitation entries for batch",
+	"33": "LMK key change storage is corrupted",
+	"39": "decryption verification failure",
+	"40": "invalid checksum",
+	"41": "internal hardware/software error",
+	"42": "DES failure",
+	"47": "algorithm not licensed",
+	"49": "private key error",
+	"51": "invalid message header",
+	"65": "transaction key scheme set to none",
+	"67": "command not licensed",
+	"68": "command not enabled in security settings",
+	"69": "PIN block format has been disabled",
+	"74": "invalid digest info syntax",
+	"75": "single length key masquerading as double or triple length key",
+	"76": "public key length error",
+	"77": "clear data block error",
+	"78": "private key length error",
+	"79": "hash algorithm object identifier error",
+	"80": "data length error",
+	"81": "invalid certificate header",
+	"82": "invalid check value length",
+	"83": "key block format error",
+	"84": "key block check value error",
+	"85": "invalid OAEP mask generation function",
+	"86": "invalid OAEP MGF hash function",
+	"87": "OAEP parameter error",
+	"90": "data parity error in the request message",
+	"91": "LRC character error",
+	"92": "count value not between limits",
+}
+
+// HSMResponseStatus is the response and error code that start every HSM
+// response.
+type HSMResponseStatus struct {
+	ResponseCode string
+	ErrorCode    string
+}
+
+// OK reports whether the HSM reported no error.
+func (s HSMResponseStatus) OK() bool {
+	return s.ErrorCode == "00"
+}
+
+// Description returns the meaning of the error code.
+func (s HSMResponseStatus) Description() string {
+	return HSMErrorDescription(s.ErrorCode)
+}
+
+// String formats the status as "A1 68 — command not enabled in security settings".
+func (s HSMResponseStatus) String() string {
+	return fmt.Sprintf("%s %s — %s", s.ResponseCode, s.ErrorCode, s.Description())
+}
+
+// HSMErrorDescription returns the meaning of a Thales error code.
+func HSMErrorDescription(code string) string {
+	if desc, ok := hsmErrorCodes[code]; ok {
+		return desc
+	}
+
+	return "unknown error code"
+}
+
+// ParseHSMResponse extracts the response and error code from an HSM response.
+// Responses that are too short or do not start with two upper case
+// alphanumeric codes are rejected.
+func ParseHSMResponse(resp []byte) (HSMResponseStatus, error) {
+	if len(resp) < 4 {
+		return HSMResponseStatus{}, fmt.Errorf("response too short: %d bytes", len(resp))
+	}
+	for i, c := range resp[:4] {
+		if (c < '0' || c > '9') && (c < 'A' || c > 'Z') {
+			return HSMResponseStatus{}, fmt.Errorf("invalid character %q at offset %d", c, i)
+		}
+	}
+
+	return HSMResponseStatus{
+		ResponseCode: string(resp[:2]),
+		ErrorCode:    string(resp[2:4]),
+	}, nil
+}
+
+// lmkCheckValueLength is the length of the LMK check value in an NC response.
+const lmkCheckValueLength = 16
+
+// NCResponse is the outcome of the NC diagnostics command.
+type NCResponse struct {
+	LMKCheckValue string
+	Firmware      string // Firmware number, e.g. "0007-E000"; empty when not reported.
+}
+
+// ParseNCResponse parses the ND response to the NC diagnostics command.
+func ParseNCResponse(resp []byte) (NCResponse, error) {
+	status, err := ParseHSMResponse(resp)
+	if err != nil {
+		return NCResponse{}, err
+	}
+	if status.ResponseCode != "ND" {
+		return NCResponse{}, fmt.Errorf("unexpected response code: %s", status.ResponseCode)
+	}
+	if !status.OK() {
+		return NCResponse{}, errors.New(status.String())
+	}
+	if len(resp) < 4+lmkCheckValueLength {
+		return NCResponse{}, fmt.Errorf("response too short: %d bytes", len(resp))
+	}
+
+	return NCResponse{
+		LMKCheckValue: string(resp[4 : 4+lmkCheckValueLength]),
+		Firmware:      strings.TrimSpace(string(resp[4+lmkCheckValueLength:])),
+	}, nil
+}
+
+// ParseLMKCheckValue returns the LMK check value reported by the NC
+// diagnostics command.
+func ParseLMKCheckValue(resp []byte) (string, error) {
+	nc, err := ParseNCResponse(resp)
+	if err != nil {
+		return "", err
+	}
+
+	return nc.LMKCheckValue, nil
+}
@@ -0,0 +1,93 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseHSMResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    string
+		wantOK  bool
+		wantErr bool
+	}{
+		{"success", "ND007B44AC1DDEE2A94B0007-E000", "ND 00 — no error", true, false},
+		{"command_disabled", "A1680000", "A1 68 — command not enabled in security settings", false, false},
+		{"parity_error", "HD10", "HD 10 — source key parity error", false, false},
+		{"unknown_code", "A1Q7", "A1 Q7 — unknown error code", false, false},
+		{"truncated", "A1", "", false, true},
+		{"empty", "", "", false, true},
+		{"lower_case", "nd00", "", false, true},
+		{"binary", "\x00\x80A1", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := ParseHSMResponse([]byte(tt.resp))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseHSMResponse(%q) error = %v, wantErr %v", tt.resp, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := status.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if status.OK() != tt.wantOK {
+				t.Errorf("OK() = %v, want %v", status.OK(), tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseLMKCheckValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    string
+		wantErr bool
+	}{
+		{"success", "ND007B44AC1DDEE2A94B0007-E000", "7B44AC1DDEE2A94B", false},
+		{"no_firmware", "ND007B44AC1DDEE2A94B", "7B44AC1DDEE2A94B", false},
+		{"hsm_error", "ND68", "", true},
+		{"other_command", "A1007B44AC1DDEE2A94B", "", true},
+		{"short", "ND007B44", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLMKCheckValue([]byte(tt.resp))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseLMKCheckValue(%q) = %q, %v, want %q, error %v", tt.resp, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseNCResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		resp    string
+		want    NCResponse
+		wantErr bool
+	}{
+		{"success", "ND007B44AC1DDEE2A94B0007-E000", NCResponse{"7B44AC1DDEE2A94B", "0007-E000"}, false},
+		{"no_firmware", "ND007B44AC1DDEE2A94B", NCResponse{"7B44AC1DDEE2A94B", ""}, false},
+		{"padded_firmware", "ND007B44AC1DDEE2A94B1234-5678 ", NCResponse{"7B44AC1DDEE2A94B", "1234-5678"}, false},
+		{"hsm_error", "ND68", NCResponse{}, true},
+		{"other_command", "A1007B44AC1DDEE2A94B", NCResponse{}, true},
+		{"short", "ND007B44", NCResponse{}, true},
+		{"garbled", "n\x00d", NCResponse{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNCResponse([]byte(tt.resp))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ParseNCResponse(%q) = %+v, %v, want %+v, error %v", tt.resp, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyBlockHeaderLength is the length of a Thales key block header.
+const keyBlockHeaderLength = 16
+
+// KeyUsages describes the common Thales key block key usage codes.
+var KeyUsages = map[string]string{
+	"01": "WatchWord key",
+	"02": "RSA public key",
+	"03": "RSA private key for signing",
+	"04": "RSA private key for ICCs",
+	"05": "RSA private key for PIN translation",
+	"06": "RSA private key for TLS",
+	"B0": "BDK base derivation key",
+	"B1": "DUKPT initial key",
+	"C0": "card verification key",
+	"D0": "data encryption key",
+	"E0": "EMV/chip card master key: application cryptograms",
+	"E1": "EMV/chip card master key: secure messaging for confidentiality",
+	"E2": "EMV/chip card master key: secure messaging for integrity",
+	"E3": "EMV/chip card master key: data authentication code",
+	"E4": "EMV/chip card master key: dynamic numbers",
+	"E5": "EMV/chip card master key: card personalization",
+	"E6": "EMV/chip card master key: other",
+	"K0": "key encryption or wrapping",
+	"K1": "TR-31 key block protection key",
+	"M0": "ISO 16609 MAC algorithm 1 (TDEA)",
+	"M1": "ISO 9797-1 MAC algorithm 1",
+	"M3": "ISO 9797-1 MAC algorithm 3",
+	"M6": "ISO 9797-1:2011 MAC algorithm 5 (CMAC)",
+	"P0": "PIN encryption",
+	"V1": "PIN verification, IBM 3624",
+	"V2": "PIN verification, VISA PVV",
+}
+
+// KeyBlockAlgorithms describes the key block algorithm codes.
+var KeyBlockAlgorithms = map[byte]string{
+	'A': "AES",
+	'D': "DES",
+	'E': "elliptic curve",
+	'H': "HMAC",
+	'R': "RSA",
+	'T': "triple DES",
+}
+
+// KeyBlockModes describes the key block mode of use codes.
+var KeyBlockModes = map[byte]string{
+	'B': "encrypt and decrypt",
+	'C': "generate and verify",
+	'D': "decrypt only",
+	'E': "encrypt only",
+	'G': "generate only",
+	'N': "no special restrictions",
+	'S': "signature only",
+	'V': "verify only",
+	'X': "derive keys",
+	'Y': "create key variants",
+}
+
+// KeyBlockExportability describes the key block exportability codes.
+var KeyBlockExportability = map[byte]string{
+	'E': "exportable under a trusted key",
+	'N': "non-exportable",
+	'S': "sensitive, exportable under an untrusted key",
+}
+
+// KeyBlockHeader is the clear header of a Thales key block.
+type KeyBlockHeader struct {
+	Version        byte // '0' for a 3DES, '1' for an AES key block LMK.
+	Length         int  // Length of the key block after the 'S' tag.
+	KeyUsage       string
+	Algorithm      byte
+	ModeOfUse      byte
+	KeyVersion     string
+	Exportability  byte
+	OptionalBlocks int
+	LMKID          string
+}
+
+// String describes the header, e.g. "K0 key encryption or wrapping, triple
+// DES, encrypt and decrypt, version 00, sensitive, ..., LMK 00".
+func (h KeyBlockHeader) String() string {
+	describe := func(code, desc string) string {
+		if desc == "" {
+			return code
+		}
+
+		return code + " " + desc
+	}
+
+	return strings.Join([]string{
+		describe(h.KeyUsage, KeyUsages[h.KeyUsage]),
+		describe(string(h.Algorithm), KeyBlockAlgorithms[h.Algorithm]),
+		describe(string(h.ModeOfUse), KeyBlockModes[h.ModeOfUse]),
+		"version " + h.KeyVersion,
+		describe(string(h.Exportability), KeyBlockExportability[h.Exportability]),
+		fmt.Sprintf("%d optional blocks", h.OptionalBlocks),
+		"LMK " + h.LMKID,
+	}, ", ")
+}
+
+// ParseThalesKeyBlock parses the header of a key block cryptogram, which
+// starts with the 'S' scheme tag. Trailing data after the key block is
+// allowed; the header's Length tells where the key block ends.
+func ParseThalesKeyBlock(cryptogram string) (KeyBlockHeader, error) {
+	if err := ValidateCryptogramForScheme(cryptogram, 'S'); err != nil {
+		return KeyBlockHeader{}, err
+	}
+	hdr := cryptogram[1:minKeyBlockLength]
+
+	length, err := strconv.Atoi(hdr[1:5])
+	if err != nil || length < keyBlockHeaderLength {
+		return KeyBlockHeader{}, fmt.Errorf("invalid key block length %q", hdr[1:5])
+	}
+	optional, err := strconv.Atoi(hdr[12:14])
+	if err != nil {
+		return KeyBlockHeader{}, fmt.Errorf("invalid number of optional blocks %q", hdr[12:14])
+	}
+
+	return KeyBlockHeader{
+		Version:        hdr[0],
+		Length:         length,
+		KeyUsage:       hdr[5:7],
+		Algorithm:      hdr[7],
+		ModeOfUse:      hdr[8],
+		KeyVersion:     hdr[9:11],
+		Exportability:  hdr[11],
+		OptionalBlocks: optional,
+		LMKID:          hdr[14:16],
+	}, nil
+}
+
+// KeyBlockAlgorithmCodes holds the A0 key block algorithm codes with their
+// key lengths in bytes.
+var KeyBlockAlgorithmCodes = map[string]int{
+	"T2": 16, // double length triple DES.
+	"T3": 24, // triple length triple DES.
+	"A1": 16, // AES-128.
+	"A2": 24, // AES-192.
+	"A3": 32, // AES-256.
+}
+
+// KeyBlockParams holds the key block fields of a command generating a key
+// under a key block LMK.
+type KeyBlockParams struct {
+	LMKID         string // Empty selects the default LMK.
+	KeyUsage      string
+	Algorithm     string // One of KeyBlockAlgorithmCodes.
+	ModeOfUse     byte
+	KeyVersion    string
+	Exportability byte
+}
+
+// Validate checks the fields of p.
+func (p KeyBlockParams) Validate() error {
+	if p.LMKID != "" && (len(p.LMKID) != 2 || !numericRegex.MatchString(p.LMKID)) {
+		return fmt.Errorf("LMK identifier must be 2 digits, got %q", p.LMKID)
+	}
+	if _, ok := KeyUsages[p.KeyUsage]; !ok {
+		return fmt.Errorf("unsupported key usage %q", p.KeyUsage)
+	}
+	if _, ok := KeyBlockAlgorithmCodes[p.Algorithm]; !ok {
+		return fmt.Errorf("unsupported key block algorithm %q", p.Algorithm)
+	}
+	if _, ok := KeyBlockModes[p.ModeOfUse]; !ok {
+		return fmt.Errorf("unsupported mode of use %q", p.ModeOfUse)
+	}
+	if len(p.KeyVersion) != 2 {
+		return fmt.Errorf("key version number must be 2 characters, got %q", p.KeyVersion)
+	}
+	if _, ok := KeyBlockExportability[p.Exportability]; !ok {
+		return fmt.Errorf("unsupported exportability %q", p.Exportability)
+	}
+
+	return nil
+}
+
+// BuildA0KeyBlockCommand builds an A0 command generating a key under a key
+// block LMK in mode, e.g. "A00FFFS%01#K0T2B00S00".
+func BuildA0KeyBlockCommand(mode byte, p KeyBlockParams) (string, error) {
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("A0")
+	b.WriteByte(mode)
+	b.WriteString("FFFS")
+	if p.LMKID != "" {
+		b.WriteString("%" + p.LMKID)
+	}
+	b.WriteString("#" + p.KeyUsage + p.Algorithm)
+	b.WriteByte(p.ModeOfUse)
+	b.WriteString(p.KeyVersion)
+	b.WriteByte(p.Exportability)
+	b.WriteString("00") // no optional blocks.
+
+	return b.String(), nil
+}
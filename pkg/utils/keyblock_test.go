@@ -0,0 +1,103 @@
+// nolint:all // test package
+package utils
+
+import (
+	"testing"
+)
+
+func TestParseThalesKeyBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		cryptogram string
+		want       KeyBlockHeader
+		wantErr    bool
+	}{
+		{
+			"aes_lmk",
+			aesKeyBlock,
+			KeyBlockHeader{
+				Version: '1', Length: 96, KeyUsage: "K0", Algorithm: 'T', ModeOfUse: 'B',
+				KeyVersion: "00", Exportability: 'E', LMKID: "00",
+			},
+			false,
+		},
+		{
+			"optional_blocks",
+			"S10112M3AC00S0201",
+			KeyBlockHeader{
+				Version: '1', Length: 112, KeyUsage: "M3", Algorithm: 'A', ModeOfUse: 'C',
+				KeyVersion: "00", Exportability: 'S', OptionalBlocks: 2, LMKID: "01",
+			},
+			false,
+		},
+		{"no_tag", "U10096K0TB00E0000", KeyBlockHeader{}, true},
+		{"short", "S10096K0TB00E00", KeyBlockHeader{}, true},
+		{"bad_length", "S1009XK0TB00E0000", KeyBlockHeader{}, true},
+		{"length_below_header", "S10010K0TB00E0000", KeyBlockHeader{}, true},
+		{"bad_optional_blocks", "S10096K0TB00EX000", KeyBlockHeader{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseThalesKeyBlock(tt.cryptogram)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseThalesKeyBlock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseThalesKeyBlock() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyBlockHeader_String(t *testing.T) {
+	hdr, err := ParseThalesKeyBlock(aesKeyBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "K0 key encryption or wrapping, T triple DES, B encrypt and decrypt, version 00, " +
+		"E exportable under a trusted key, 0 optional blocks, LMK 00"
+	if got := hdr.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestBuildA0KeyBlockCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  KeyBlockParams
+		want    string
+		wantErr bool
+	}{
+		{
+			"default_lmk",
+			KeyBlockParams{KeyUsage: "K0", Algorithm: "T2", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'E'},
+			"A00FFFS#K0T2B00E00",
+			false,
+		},
+		{
+			"lmk_id",
+			KeyBlockParams{LMKID: "01", KeyUsage: "P0", Algorithm: "A1", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'S'},
+			"A00FFFS%01#P0A1B00S00",
+			false,
+		},
+		{"bad_lmk_id", KeyBlockParams{LMKID: "1A", KeyUsage: "K0", Algorithm: "T2", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'E'}, "", true},
+		{"bad_usage", KeyBlockParams{KeyUsage: "ZZ", Algorithm: "T2", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'E'}, "", true},
+		{"bad_algorithm", KeyBlockParams{KeyUsage: "K0", Algorithm: "T", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'E'}, "", true},
+		{"bad_mode", KeyBlockParams{KeyUsage: "K0", Algorithm: "T2", ModeOfUse: 'Q', KeyVersion: "00", Exportability: 'E'}, "", true},
+		{"bad_version", KeyBlockParams{KeyUsage: "K0", Algorithm: "T2", ModeOfUse: 'B', KeyVersion: "0", Exportability: 'E'}, "", true},
+		{"bad_exportability", KeyBlockParams{KeyUsage: "K0", Algorithm: "T2", ModeOfUse: 'B', KeyVersion: "00", Exportability: 'X'}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildA0KeyBlockCommand('0', tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildA0KeyBlockCommand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BuildA0KeyBlockCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"fmt"
+)
+
+// minKeyBlockLength is the shortest key block cryptogram: the 'S' tag plus the
+// 16-character key block header.
+const minKeyBlockLength = 1 + 16
+
+// schemeHexLengths maps fixed-length Thales key scheme tags to the number of
+// hex digits following the tag.
+var schemeHexLengths = map[byte]int{
+	'Z': 16, // single length, ANSI X9.17.
+	'U': 32, // double length, variant.
+	'T': 48, // triple length, variant.
+	'X': 32, // double length, ANSI X9.17.
+	'Y': 48, // triple length, ANSI X9.17.
+}
+
+// ValidateKeyScheme checks if tag is a supported Thales key scheme.
+func ValidateKeyScheme(tag byte) error {
+	if tag == 'S' {
+		return nil
+	}
+	if _, ok := schemeHexLengths[tag]; !ok {
+		return fmt.Errorf("unsupported key scheme %q", tag)
+	}
+
+	return nil
+}
+
+// SchemeKeyLength returns the length in bytes of keys in a fixed-length
+// scheme, or zero for key blocks and unknown schemes.
+func SchemeKeyLength(scheme byte) int {
+	return schemeHexLengths[scheme] / 2
+}
+
+// CryptogramScheme returns the key scheme of a key cryptogram: its tag, or Z
+// for an untagged single length key.
+func CryptogramScheme(cryptogram string) byte {
+	if cryptogram != "" && ValidateKeyScheme(cryptogram[0]) == nil {
+		return cryptogram[0]
+	}
+
+	return 'Z'
+}
+
+// ValidateCryptogramForScheme checks that a key cryptogram is consistent with
+// its key scheme. Tagged schemes must carry their tag as the first character;
+// Z cryptograms may omit it. Key blocks (S) are variable length.
+func ValidateCryptogramForScheme(cryptogram string, scheme byte) error {
+	if err := ValidateKeyScheme(scheme); err != nil {
+		return err
+	}
+	if cryptogram == "" {
+		return fmt.Errorf("key cryptogram cannot be empty")
+	}
+
+	if scheme == 'S' {
+		if cryptogram[0] != 'S' {
+			return fmt.Errorf("key block cryptogram must start with 'S'")
+		}
+		if len(cryptogram) < minKeyBlockLength {
+			return fmt.Errorf(
+				"key block cryptogram too short: got %d characters, want at least %d",
+				len(cryptogram),
+				minKeyBlockLength,
+			)
+		}
+		for i := 0; i < len(cryptogram); i++ {
+			if c := cryptogram[i]; c < 0x21 || c > 0x7E {
+				return fmt.Errorf("key block cryptogram contains non-printable character at %d", i)
+			}
+		}
+
+		return nil
+	}
+
+	body := cryptogram
+	switch {
+	case cryptogram[0] == scheme:
+		body = cryptogram[1:]
+	case scheme != 'Z':
+		return fmt.Errorf("key cryptogram must start with scheme tag %q", scheme)
+	}
+
+	want := schemeHexLengths[scheme]
+	if len(body) != want {
+		return fmt.Errorf(
+			"invalid cryptogram length for scheme %c: got %d hex digits, want %d",
+			scheme,
+			len(body),
+			want,
+		)
+	}
+	if !hexRegex.MatchString(body) {
+		return fmt.Errorf("key cryptogram for scheme %c must be hex", scheme)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+// nolint:all // test package
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateKeyScheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     byte
+		wantErr bool
+	}{
+		{"Z", 'Z', false},
+		{"U", 'U', false},
+		{"T", 'T', false},
+		{"X", 'X', false},
+		{"Y", 'Y', false},
+		{"S", 'S', false},
+		{"lowercase_u", 'u', true},
+		{"unknown_R", 'R', true},
+		{"zero", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateKeyScheme(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateKeyScheme(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCryptogramForScheme(t *testing.T) {
+	hex16 := "0123456789ABCDEF"
+	hex32 := hex16 + hex16
+	hex48 := hex32 + hex16
+	keyBlock := "S10096B0TN00S0000" + hex32 + "1A2B3C4D"
+
+	tests := []struct {
+		name       string
+		cryptogram string
+		scheme     byte
+		wantErr    bool
+	}{
+		{"Z_correct", hex16, 'Z', false},
+		{"Z_correct_tagged", "Z" + hex16, 'Z', false},
+		{"Z_short", hex16[:14], 'Z', true},
+		{"Z_long", hex16 + "00", 'Z', true},
+		{"U_correct", "U" + hex32, 'U', false},
+		{"U_short", "U" + hex32[:30], 'U', true},
+		{"U_long", "U" + hex32 + "00", 'U', true},
+		{"U_missing_tag", hex32, 'U', true},
+		{"U_wrong_tag", "T" + hex32, 'U', true},
+		{"U_not_hex", "U" + hex32[:31] + "G", 'U', true},
+		{"T_correct", "T" + hex48, 'T', false},
+		{"T_short", "T" + hex32, 'T', true},
+		{"T_long", "T" + hex48 + "00", 'T', true},
+		{"X_correct", "X" + hex32, 'X', false},
+		{"X_short", "X" + hex16, 'X', true},
+		{"X_long", "X" + hex48, 'X', true},
+		{"Y_correct", "Y" + hex48, 'Y', false},
+		{"Y_short", "Y" + hex32, 'Y', true},
+		{"Y_long", "Y" + hex48 + hex16, 'Y', true},
+		{"S_correct", keyBlock, 'S', false},
+		{"S_short", keyBlock[:16], 'S', true},
+		{"S_long", keyBlock + strings.Repeat("0", 200), 'S', false},
+		{"S_missing_tag", keyBlock[1:], 'S', true},
+		{"S_non_printable", keyBlock + "\n", 'S', true},
+		{"empty", "", 'U', true},
+		{"unknown_scheme", "R" + hex32, 'R', true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCryptogramForScheme(tt.cryptogram, tt.scheme)
+			if (err != nil) != tt.wantErr {
+				t.Errorf(
+					"ValidateCryptogramForScheme(%q, %q) error = %v, wantErr %v",
+					tt.cryptogram,
+					tt.scheme,
+					err,
+					tt.wantErr,
+				)
+			}
+		})
+	}
+}
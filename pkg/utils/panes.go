@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// hexPaneGroup is the number of bytes per group in HexPane. A group takes
+// two digits per byte plus a separating space.
+const (
+	hexPaneGroup      = 4
+	hexPaneGroupWidth = 2*hexPaneGroup + 1
+)
+
+// ASCIIPane renders data as printable ASCII, non-printable bytes as '.', with
+// 16 bytes per line. It lines up with HexPane row by row.
+func ASCIIPane(data []byte) string {
+	text := PrintableASCII(data)
+	lines := make([]string, 0, len(data)/hexDumpWidth+1)
+	for off := 0; off < len(text); off += hexDumpWidth {
+		lines = append(lines, text[off:min(off+hexDumpWidth, len(text))])
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// HexPane renders data as uppercase hex with 16 bytes per line in groups of
+// four bytes, e.g. "4E443030 00001234".
+func HexPane(data []byte) string {
+	var b strings.Builder
+	for off := 0; off < len(data); off += hexDumpWidth {
+		if off > 0 {
+			b.WriteByte('\n')
+		}
+		line := data[off:min(off+hexDumpWidth, len(data))]
+		for g := 0; g < len(line); g += hexPaneGroup {
+			if g > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(strings.ToUpper(hex.EncodeToString(line[g:min(g+hexPaneGroup, len(line))])))
+		}
+	}
+
+	return b.String()
+}
+
+// PaneSelection maps a cursor position and selected text in the ASCIIPane or
+// HexPane rendering of data to a byte offset and length. The offset is the
+// start of the selection, or the cursor position when nothing is selected.
+// A partly selected hex byte counts as selected.
+func PaneSelection(data []byte, hexPane bool, row, col int, selected string) (offset, length int) {
+	col = max(col, 0)
+	cursor, end := row*hexDumpWidth+min(col, hexDumpWidth), 0
+	text := strings.ReplaceAll(selected, "\n", "")
+	length = len(text)
+	if hexPane {
+		// Past the last digit of a group the cursor is before the next byte.
+		group, digit := col/hexPaneGroupWidth, min(col%hexPaneGroupWidth, 2*hexPaneGroup)
+		cursor = row*hexDumpWidth + min(group*hexPaneGroup+digit/2, hexDumpWidth)
+		if digit%2 == 1 {
+			end = 1 // The cursor is inside a byte.
+		}
+		text = strings.ToUpper(StripHexFormatting(text))
+		length = (len(text) + 1) / 2
+	}
+	cursor = min(max(cursor, 0), len(data))
+	end = min(cursor+end, len(data))
+	if length == 0 {
+		return cursor, 0
+	}
+
+	// The cursor is at whichever end of the selection was dragged last: it
+	// ends a forward selection and starts a backward one.
+	if start := end - length; start >= 0 {
+		before := data[start:end]
+		if hexPane && strings.Contains(strings.ToUpper(hex.EncodeToString(before)), text) ||
+			!hexPane && PrintableASCII(before) == text {
+			return start, length
+		}
+	}
+
+	return cursor, min(length, len(data)-cursor)
+}
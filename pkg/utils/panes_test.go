@@ -0,0 +1,69 @@
+// nolint:all // test package
+package utils
+
+import "testing"
+
+// paneResponse is a response with embedded nulls and other non-printable
+// bytes spanning two pane rows.
+var paneResponse = []byte("NE00\x00\x00\x00\x10ABCDEFGH\x00\x7FIJ")
+
+func TestASCIIPane_HexPane(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		wantASCII string
+		wantHex   string
+	}{
+		{"empty", nil, "", ""},
+		{"short", []byte("ND00"), "ND00", "4E443030"},
+		{"nulls", []byte{0, 'A', 0}, ".A.", "004100"},
+		{
+			"two_rows",
+			paneResponse,
+			"NE00....ABCDEFGH\n..IJ",
+			"4E453030 00000010 41424344 45464748\n007F494A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ASCIIPane(tt.data); got != tt.wantASCII {
+				t.Errorf("ASCIIPane() = %q, want %q", got, tt.wantASCII)
+			}
+			if got := HexPane(tt.data); got != tt.wantHex {
+				t.Errorf("HexPane() = %q, want %q", got, tt.wantHex)
+			}
+		})
+	}
+}
+
+func TestPaneSelection(t *testing.T) {
+	tests := []struct {
+		name       string
+		hexPane    bool
+		row, col   int
+		selected   string
+		wantOffset int
+		wantLength int
+	}{
+		{"ascii_cursor", false, 1, 2, "", 18, 0},
+		{"ascii_forward", false, 0, 12, "ABCD", 8, 4},
+		{"ascii_backward", false, 0, 8, "ABCD", 8, 4},
+		{"ascii_across_rows", false, 1, 2, "EFGH\n..", 12, 6},
+		{"ascii_past_end", false, 3, 0, "", 20, 0},
+		{"hex_cursor", true, 0, 9, "", 4, 0},
+		{"hex_forward_across_groups", true, 0, 22, "00000010 4142", 4, 6},
+		{"hex_partial_byte", true, 0, 3, "4E4", 0, 2},
+		{"hex_backward", true, 1, 4, "494A", 18, 2},
+		{"hex_end_of_group", true, 0, 8, "", 4, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offset, length := PaneSelection(paneResponse, tt.hexPane, tt.row, tt.col, tt.selected)
+			if offset != tt.wantOffset || length != tt.wantLength {
+				t.Errorf("PaneSelection() = %d, %d, want %d, %d", offset, length, tt.wantOffset, tt.wantLength)
+			}
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxTrack2Length is the longest Track 2 equivalent data, without
+	// sentinels and LRC.
+	MaxTrack2Length = 37
+	// KSNLength is the length of a DUKPT key serial number in bytes.
+	KSNLength = 10
+	// MaxTestCards bounds a single GenerateTestCards batch.
+	MaxTestCards = 10000
+)
+
+// TestDataLabel marks generated data so it is not mistaken for real cards.
+const TestDataLabel = "TEST DATA - not real card data"
+
+// LuhnCheckDigit returns the Luhn check digit to append to digits.
+func LuhnCheckDigit(digits string) (byte, error) {
+	if !numericRegex.MatchString(digits) {
+		return 0, fmt.Errorf("%q must contain digits only", digits)
+	}
+
+	for d := byte('0'); d < '9'; d++ {
+		if LuhnValid(digits + string(d)) {
+			return d, nil
+		}
+	}
+
+	return '9', nil
+}
+
+// ValidateExpiry checks that expiry is a YYMM date.
+func ValidateExpiry(expiry string) error {
+	if len(expiry) != 4 || !numericRegex.MatchString(expiry) {
+		return fmt.Errorf("expiry must be 4 digits (YYMM), got %q", expiry)
+	}
+	if m := expiry[2:]; m < "01" || m > "12" {
+		return fmt.Errorf("expiry month must be 01 to 12, got %s", m)
+	}
+
+	return nil
+}
+
+// ValidateServiceCode checks that code is a 3-digit service code with a
+// defined interchange (1, 2, 5, 6, 7 or 9), authorization processing (0, 2
+// or 4) and range of services (0 to 7) digit.
+func ValidateServiceCode(code string) error {
+	if len(code) != 3 || !numericRegex.MatchString(code) {
+		return fmt.Errorf("service code must be 3 digits, got %q", code)
+	}
+	if !strings.ContainsRune("125679", rune(code[0])) {
+		return fmt.Errorf("service code interchange digit %c is not defined", code[0])
+	}
+	if !strings.ContainsRune("024", rune(code[1])) {
+		return fmt.Errorf("service code authorization digit %c is not defined", code[1])
+	}
+	if code[2] > '7' {
+		return fmt.Errorf("service code services digit %c is not defined", code[2])
+	}
+
+	return nil
+}
+
+// BuildTrack2 assembles Track 2 equivalent data: PAN, the '=' separator,
+// expiry (YYMM), service code and discretionary data, without sentinels.
+func BuildTrack2(pan, expiry, serviceCode, discretionary string) (string, error) {
+	if err := ValidatePAN(pan, false); err != nil {
+		return "", err
+	}
+	if err := ValidateExpiry(expiry); err != nil {
+		return "", err
+	}
+	if err := ValidateServiceCode(serviceCode); err != nil {
+		return "", err
+	}
+	if discretionary != "" && !numericRegex.MatchString(discretionary) {
+		return "", fmt.Errorf("discretionary data must contain digits only")
+	}
+
+	track := pan + "=" + expiry + serviceCode + discretionary
+	if len(track) > MaxTrack2Length {
+		return "", fmt.Errorf("track 2 data must be at most %d characters, got %d", MaxTrack2Length, len(track))
+	}
+
+	return track, nil
+}
+
+// randomInt returns a uniform random number in [0, n) read from r.
+func randomInt(r io.Reader, n int) (int, error) {
+	v, err := rand.Int(r, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random data: %v", err)
+	}
+
+	return int(v.Int64()), nil
+}
+
+// RandomDigits returns n uniform random decimal digits read from r.
+func RandomDigits(r io.Reader, n int) (string, error) {
+	b := make([]byte, n)
+	for i := range b {
+		d, err := randomInt(r, 10)
+		if err != nil {
+			return "", err
+		}
+		b[i] = byte('0' + d)
+	}
+
+	return string(b), nil
+}
+
+// validatePANRange checks that PANs of length digits can start with bin.
+func validatePANRange(bin string, length int) error {
+	if length < MinPANLength || length > MaxPANLength {
+		return fmt.Errorf("PAN length must be %d to %d, got %d", MinPANLength, MaxPANLength, length)
+	}
+	if !numericRegex.MatchString(bin) {
+		return fmt.Errorf("BIN must contain digits only")
+	}
+	if len(bin) >= length {
+		return fmt.Errorf("BIN must be shorter than the PAN, got %d digits for %d", len(bin), length)
+	}
+
+	return nil
+}
+
+// RandomPAN returns a Luhn-valid PAN of length digits starting with bin.
+func RandomPAN(r io.Reader, bin string, length int) (string, error) {
+	if err := validatePANRange(bin, length); err != nil {
+		return "", err
+	}
+
+	body, err := RandomDigits(r, length-len(bin)-1)
+	if err != nil {
+		return "", err
+	}
+	check, _ := LuhnCheckDigit(bin + body)
+
+	return bin + body + string(check), nil
+}
+
+// RandomExpiry returns a YYMM expiry in one of the months months starting
+// with the month of from.
+func RandomExpiry(r io.Reader, from time.Time, months int) (string, error) {
+	if months < 1 {
+		return "", fmt.Errorf("expiry window must be at least one month, got %d", months)
+	}
+	n, err := randomInt(r, months)
+	if err != nil {
+		return "", err
+	}
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return first.AddDate(0, n, 0).Format("0601"), nil
+}
+
+// commonServiceCodes are the service codes RandomServiceCode picks from.
+var commonServiceCodes = []string{"101", "120", "121", "201", "220", "221", "501", "601", "702"}
+
+// RandomServiceCode returns a commonly used service code.
+func RandomServiceCode(r io.Reader) (string, error) {
+	i, err := randomInt(r, len(commonServiceCodes))
+	if err != nil {
+		return "", err
+	}
+
+	return commonServiceCodes[i], nil
+}
+
+// RandomHex returns n random bytes read from r as uppercase hex.
+func RandomHex(r io.Reader, n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", fmt.Errorf("failed to read random data: %v", err)
+	}
+
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// RandomKSN returns a random initial DUKPT key serial number: random key
+// set and device identifiers with the 21-bit transaction counter at zero.
+func RandomKSN(r io.Reader) (string, error) {
+	b := make([]byte, KSNLength)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return "", fmt.Errorf("failed to read random data: %v", err)
+	}
+	b[7] &= 0xE0
+	b[8], b[9] = 0, 0
+
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
+
+// TestCardOptions configures GenerateTestCards.
+type TestCardOptions struct {
+	BIN       string
+	PANLength int
+	// Expiry dates fall in ExpiryMonths months starting with the month of
+	// ExpiryFrom.
+	ExpiryFrom   time.Time
+	ExpiryMonths int
+	HexLength    int // Bytes of the random hex block.
+}
+
+// TestCard is one row of generated test data.
+type TestCard struct {
+	PAN         string
+	Expiry      string
+	ServiceCode string
+	Track2      string
+	KSN         string
+	Hex         string
+}
+
+// GenerateTestCards returns count test cards with random data read from r.
+// PANs, KSNs and hex blocks are unique within the batch; an error is
+// returned when the BIN leaves too few PANs to draw from.
+func GenerateTestCards(r io.Reader, opts TestCardOptions, count int) ([]TestCard, error) {
+	if count < 1 || count > MaxTestCards {
+		return nil, fmt.Errorf("count must be 1 to %d, got %d", MaxTestCards, count)
+	}
+	if opts.HexLength < 1 {
+		return nil, fmt.Errorf("hex length must be at least one byte, got %d", opts.HexLength)
+	}
+	if err := validatePANRange(opts.BIN, opts.PANLength); err != nil {
+		return nil, err
+	}
+	if free := opts.PANLength - len(opts.BIN) - 1; free < 10 && pow10(free) < count {
+		return nil, fmt.Errorf("BIN %s leaves only %d distinct %d-digit PANs", opts.BIN, pow10(free), opts.PANLength)
+	}
+	if opts.HexLength < 3 && 1<<(8*opts.HexLength) < count {
+		return nil, fmt.Errorf("%d-byte hex blocks have only %d distinct values", opts.HexLength, 1<<(8*opts.HexLength))
+	}
+
+	pans := newUniqueGen(func() (string, error) { return RandomPAN(r, opts.BIN, opts.PANLength) })
+	ksns := newUniqueGen(func() (string, error) { return RandomKSN(r) })
+	blocks := newUniqueGen(func() (string, error) { return RandomHex(r, opts.HexLength) })
+
+	cards := make([]TestCard, count)
+	for i := range cards {
+		c := &cards[i]
+		var err error
+		if c.PAN, err = pans(); err != nil {
+			return nil, err
+		}
+		if c.Expiry, err = RandomExpiry(r, opts.ExpiryFrom, opts.ExpiryMonths); err != nil {
+			return nil, err
+		}
+		if c.ServiceCode, err = RandomServiceCode(r); err != nil {
+			return nil, err
+		}
+		if c.Track2, err = BuildTrack2(c.PAN, c.Expiry, c.ServiceCode, ""); err != nil {
+			return nil, err
+		}
+		if c.KSN, err = ksns(); err != nil {
+			return nil, err
+		}
+		if c.Hex, err = blocks(); err != nil {
+			return nil, err
+		}
+	}
+
+	return cards, nil
+}
+
+// newUniqueGen wraps gen so it never returns the same value twice.
+func newUniqueGen(gen func() (string, error)) func() (string, error) {
+	seen := make(map[string]bool)
+
+	return func() (string, error) {
+		// Collisions are rare except in nearly exhausted ranges.
+		for range 1000 {
+			v, err := gen()
+			if err != nil {
+				return "", err
+			}
+			if !seen[v] {
+				seen[v] = true
+				return v, nil
+			}
+		}
+
+		return "", fmt.Errorf("could not generate %d distinct values", len(seen)+1)
+	}
+}
+
+// pow10 returns 10 to the power n, for small n.
+func pow10(n int) int {
+	p := 1
+	for range n {
+		p *= 10
+	}
+
+	return p
+}
+
+// WriteTestCardsCSV writes cards as CSV with a header row, preceded by a
+// row carrying TestDataLabel.
+func WriteTestCardsCSV(w io.Writer, cards []TestCard) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"# " + TestDataLabel}); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	if err := cw.Write([]string{"pan", "expiry", "service_code", "track2", "ksn", "hex"}); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+	for _, c := range cards {
+		if err := cw.Write([]string{c.PAN, c.Expiry, c.ServiceCode, c.Track2, c.KSN, c.Hex}); err != nil {
+			return fmt.Errorf("failed to write CSV: %v", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+// nolint:all // test package
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		name    string
+		digits  string
+		want    byte
+		wantErr bool
+	}{
+		{"visa_test_card", "411111111111111", '1', false},
+		{"wikipedia_example", "7992739871", '3', false},
+		{"check_digit_zero", "0", '0', false},
+		{"check_digit_nine", "1", '8', false},
+		{"empty", "", 0, true},
+		{"alphabetic", "41a1", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LuhnCheckDigit(tt.digits)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LuhnCheckDigit(%q) error = %v, wantErr %v", tt.digits, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LuhnCheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+			}
+			if !tt.wantErr && !LuhnValid(tt.digits+string(got)) {
+				t.Errorf("%s%c fails the Luhn check", tt.digits, got)
+			}
+		})
+	}
+}
+
+func TestValidateExpiry(t *testing.T) {
+	tests := []struct {
+		expiry  string
+		wantErr bool
+	}{
+		{"2512", false},
+		{"3001", false},
+		{"2513", true},
+		{"2500", true},
+		{"251", true},
+		{"25-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expiry, func(t *testing.T) {
+			if err := ValidateExpiry(tt.expiry); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateExpiry(%q) error = %v, wantErr %v", tt.expiry, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServiceCode(t *testing.T) {
+	tests := []struct {
+		code    string
+		wantErr bool
+	}{
+		{"101", false},
+		{"221", false},
+		{"702", false},
+		{"947", false},
+		{"301", true},
+		{"111", true},
+		{"108", true},
+		{"10", true},
+		{"1a1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if err := ValidateServiceCode(tt.code); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateServiceCode(%q) error = %v, wantErr %v", tt.code, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBuildTrack2(t *testing.T) {
+	tests := []struct {
+		name          string
+		pan           string
+		expiry        string
+		serviceCode   string
+		discretionary string
+		want          string
+		wantErr       bool
+	}{
+		{"no_discretionary", "4111111111111111", "2512", "101", "", "4111111111111111=2512101", false},
+		{"discretionary", "4111111111111111", "2512", "201", "0000012345", "4111111111111111=25122010000012345", false},
+		{"max_length", "4111111111111111111", "2512", "101", strings.Repeat("0", 10), "4111111111111111111=2512101" + strings.Repeat("0", 10), false},
+		{"too_long", "4111111111111111111", "2512", "101", strings.Repeat("0", 11), "", true},
+		{"bad_pan", "4111", "2512", "101", "", "", true},
+		{"bad_expiry", "4111111111111111", "2513", "101", "", "", true},
+		{"bad_service_code", "4111111111111111", "2512", "301", "", "", true},
+		{"bad_discretionary", "4111111111111111", "2512", "101", "12AB", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BuildTrack2(tt.pan, tt.expiry, tt.serviceCode, tt.discretionary)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("BuildTrack2() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("BuildTrack2() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRandomPAN(t *testing.T) {
+	tests := []struct {
+		name    string
+		bin     string
+		length  int
+		wantErr bool
+	}{
+		{"visa_16", "411111", 16, false},
+		{"amex_15", "37", 15, false},
+		{"max_19", "62", 19, false},
+		{"one_free_digit", "41111111111111", 16, false},
+		{"bin_too_long", "4111111111111111", 16, true},
+		{"length_too_short", "4", 11, true},
+		{"length_too_long", "4", 20, true},
+		{"bin_not_numeric", "41A", 16, true},
+		{"bin_empty", "", 16, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RandomPAN(rand.Reader, tt.bin, tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RandomPAN() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != tt.length || !strings.HasPrefix(got, tt.bin) || !LuhnValid(got) {
+				t.Errorf("RandomPAN() = %s, want a Luhn-valid %d-digit PAN starting %s", got, tt.length, tt.bin)
+			}
+		})
+	}
+}
+
+func TestRandomExpiry(t *testing.T) {
+	from := time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)
+	want := map[string]bool{"2512": true, "2601": true, "2602": true}
+	for range 50 {
+		got, err := RandomExpiry(rand.Reader, from, 3)
+		if err != nil || !want[got] {
+			t.Fatalf("RandomExpiry() = %q, %v, want one of 2512, 2601, 2602", got, err)
+		}
+	}
+
+	if got, _ := RandomExpiry(rand.Reader, from, 1); got != "2512" {
+		t.Errorf("one-month window = %s, want 2512", got)
+	}
+	if _, err := RandomExpiry(rand.Reader, from, 0); err == nil {
+		t.Error("empty window succeeded")
+	}
+}
+
+func TestRandomKSN(t *testing.T) {
+	for range 20 {
+		ksn, err := RandomKSN(rand.Reader)
+		if err != nil {
+			t.Fatalf("RandomKSN() error = %v", err)
+		}
+		b, err := DecodeHex(ksn)
+		if err != nil || len(b) != KSNLength {
+			t.Fatalf("RandomKSN() = %s, want %d bytes of hex", ksn, KSNLength)
+		}
+		if b[7]&0x1F != 0 || b[8] != 0 || b[9] != 0 {
+			t.Errorf("RandomKSN() = %s, want the transaction counter at zero", ksn)
+		}
+	}
+}
+
+func TestGenerateTestCards(t *testing.T) {
+	opts := TestCardOptions{
+		BIN:          "999999",
+		PANLength:    16,
+		ExpiryFrom:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		ExpiryMonths: 24,
+		HexLength:    8,
+	}
+
+	cards, err := GenerateTestCards(rand.Reader, opts, 500)
+	if err != nil {
+		t.Fatalf("GenerateTestCards() error = %v", err)
+	}
+	seen := map[string]bool{}
+	for _, c := range cards {
+		for _, v := range []string{c.PAN, c.KSN, c.Hex} {
+			if seen[v] {
+				t.Fatalf("value %s repeats within the batch", v)
+			}
+			seen[v] = true
+		}
+		if err := ValidatePAN(c.PAN, true); err != nil || !strings.HasPrefix(c.PAN, opts.BIN) {
+			t.Errorf("PAN %s: %v", c.PAN, err)
+		}
+		if c.Expiry < "2601" || c.Expiry > "2712" {
+			t.Errorf("expiry %s outside the window", c.Expiry)
+		}
+		if c.Track2 != c.PAN+"="+c.Expiry+c.ServiceCode {
+			t.Errorf("track 2 %s does not match its fields", c.Track2)
+		}
+		if len(c.Hex) != 16 || len(c.KSN) != 20 {
+			t.Errorf("hex %s, KSN %s have the wrong length", c.Hex, c.KSN)
+		}
+	}
+
+	// A BIN leaving 100 PANs can fill a batch of 100 but not 101.
+	small := opts
+	small.BIN = "9999999999999"
+	if _, err := GenerateTestCards(rand.Reader, small, 100); err != nil {
+		t.Errorf("exhausting the BIN range: %v", err)
+	}
+	if _, err := GenerateTestCards(rand.Reader, small, 101); err == nil {
+		t.Error("batch larger than the BIN range succeeded")
+	}
+}
+
+func TestGenerateTestCards_Invalid(t *testing.T) {
+	valid := TestCardOptions{BIN: "4", PANLength: 16, ExpiryMonths: 12, HexLength: 8}
+
+	tests := []struct {
+		name    string
+		mutate  func(o *TestCardOptions)
+		count   int
+		wantErr string
+	}{
+		{"zero_count", func(o *TestCardOptions) {}, 0, "count must be"},
+		{"count_too_large", func(o *TestCardOptions) {}, MaxTestCards + 1, "count must be"},
+		{"no_hex", func(o *TestCardOptions) { o.HexLength = 0 }, 1, "hex length"},
+		{"short_hex", func(o *TestCardOptions) { o.HexLength = 1 }, 257, "distinct values"},
+		{"bad_bin", func(o *TestCardOptions) { o.BIN = "4X" }, 1, "BIN"},
+		{"no_expiry_window", func(o *TestCardOptions) { o.ExpiryMonths = 0 }, 1, "expiry window"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := valid
+			tt.mutate(&opts)
+			_, err := GenerateTestCards(rand.Reader, opts, tt.count)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateTestCards() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := GenerateTestCards(bytes.NewReader(nil), valid, 1); err == nil {
+		t.Error("GenerateTestCards() with an empty random source succeeded")
+	}
+}
+
+func TestWriteTestCardsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteTestCardsCSV(&buf, []TestCard{
+		{"4111111111111111", "2612", "201", "4111111111111111=2612201", "FFFF9876543210E00000", "0123456789ABCDEF"},
+	})
+	if err != nil {
+		t.Fatalf("WriteTestCardsCSV() error = %v", err)
+	}
+
+	want := "# TEST DATA - not real card data\n" +
+		"pan,expiry,service_code,track2,ksn,hex\n" +
+		"4111111111111111,2612,201,4111111111111111=2612201,FFFF9876543210E00000,0123456789ABCDEF\n"
+	if buf.String() != want {
+		t.Errorf("WriteTestCardsCSV() = %q, want %q", buf.String(), want)
+	}
+}
@@ -12,9 +12,24 @@ import (
 var (
 	// alphanumericRegex validates alphanumeric strings.
 	alphanumericRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+	// keyNameRegex validates key name characters.
+	keyNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
 
 	// hexRegex validates hex strings.
 	hexRegex = regexp.MustCompile(`^[0-9A-Fa-f]+$`)
+	// hostLabelRegex validates a single RFC 1123 hostname label.
+	hostLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+	// numericRegex matches strings made only of digits.
+	numericRegex = regexp.MustCompile(`^[0-9]+$`)
+)
+
+const (
+	// MaxKeyNameLength is the maximum number of characters in a key name.
+	MaxKeyNameLength = 50
+	// maxHostnameLength is the RFC 1123 limit for a full hostname.
+	maxHostnameLength = 253
+	// maxHostLabelLength is the RFC 1123 limit for a single label.
+	maxHostLabelLength = 63
 )
 
 // ValidateHex checks if a string is valid hexadecimal.
@@ -33,6 +48,37 @@ func ValidateHex(input string) error {
 	return nil
 }
 
+// lenientHexStripper removes separators commonly found in pasted hex.
+var lenientHexStripper = strings.NewReplacer(
+	" ", "", ":", "", "-", "", "\t", "", "\r", "", "\n", "",
+)
+
+// cleanHexLenient strips a leading 0x/0X prefix and common separators.
+func cleanHexLenient(input string) string {
+	clean := strings.TrimSpace(input)
+	if strings.HasPrefix(clean, "0x") || strings.HasPrefix(clean, "0X") {
+		clean = clean[2:]
+	}
+
+	return lenientHexStripper.Replace(clean)
+}
+
+// ValidateHexLenient checks if a string is valid hexadecimal after removing a
+// leading 0x prefix, spaces, colons, dashes, tabs and line breaks.
+func ValidateHexLenient(input string) error {
+	return ValidateHex(cleanHexLenient(input))
+}
+
+// DecodeHexLenient decodes a hex string accepted by ValidateHexLenient.
+func DecodeHexLenient(input string) ([]byte, error) {
+	clean := cleanHexLenient(input)
+	if err := ValidateHex(clean); err != nil {
+		return nil, err
+	}
+
+	return hex.DecodeString(clean)
+}
+
 // ValidateKeyLength checks if a hex string has valid key length.
 func ValidateKeyLength(hexKey string, expectedLength int) error {
 	if err := ValidateHex(hexKey); err != nil {
@@ -75,14 +121,25 @@ func DecodeHex(input string) ([]byte, error) {
 	return hex.DecodeString(clean)
 }
 
-// ValidateKeyName checks if a key name is valid.
+// ValidateKeyName checks that a key name starts with a letter or digit, uses
+// only letters, digits, hyphens and underscores, and fits MaxKeyNameLength.
 func ValidateKeyName(name string) error {
 	if name == "" {
 		return fmt.Errorf("key name cannot be empty")
 	}
 
-	if !alphanumericRegex.MatchString(name) {
-		return fmt.Errorf("key name must be alphanumeric")
+	if len(name) > MaxKeyNameLength {
+		return fmt.Errorf(
+			"key name too long: got %d characters, max %d",
+			len(name),
+			MaxKeyNameLength,
+		)
+	}
+	if !alphanumericRegex.MatchString(name[:1]) {
+		return fmt.Errorf("key name must start with a letter or digit")
+	}
+	if !keyNameRegex.MatchString(name) {
+		return fmt.Errorf("key name may only contain letters, digits, hyphens and underscores")
 	}
 
 	return nil
@@ -110,6 +167,55 @@ func ValidateIPAddress(ip string) error {
 	return nil
 }
 
+// ValidateHostOrIP checks if a string is an IPv4 address, an IPv6 address or
+// an RFC 1123 hostname.
+func ValidateHostOrIP(host string) error {
+	if host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if net.ParseIP(host) != nil {
+		return nil
+	}
+
+	if strings.HasSuffix(host, ".") {
+		return fmt.Errorf("hostname must not end with a dot")
+	}
+	if len(host) > maxHostnameLength {
+		return fmt.Errorf(
+			"hostname too long: got %d characters, max %d",
+			len(host),
+			maxHostnameLength,
+		)
+	}
+
+	labels := strings.Split(host, ".")
+	for _, label := range labels {
+		if label == "" {
+			return fmt.Errorf("hostname contains an empty label")
+		}
+		if len(label) > maxHostLabelLength {
+			return fmt.Errorf(
+				"hostname label %q too long: max %d characters",
+				label,
+				maxHostLabelLength,
+			)
+		}
+		if !hostLabelRegex.MatchString(label) {
+			return fmt.Errorf(
+				"invalid hostname label %q: use letters, digits and inner hyphens",
+				label,
+			)
+		}
+	}
+
+	// A numeric top-level label means a malformed IPv4 address, not a name.
+	if numericRegex.MatchString(labels[len(labels)-1]) {
+		return fmt.Errorf("invalid IP address")
+	}
+
+	return nil
+}
+
 // ValidateNumericInput validates a string as a numeric value.
 func ValidateNumericInput(input string) error {
 	if input == "" {
@@ -3,6 +3,7 @@ package utils
 
 import (
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -131,6 +132,54 @@ func TestDecodeHex(t *testing.T) {
 	}
 }
 
+func TestValidateHexLenient(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []byte
+		wantErr bool
+	}{
+		{"plain", "1A2B3C", []byte{0x1A, 0x2B, 0x3C}, false},
+		{"prefix_lower", "0x1A2B", []byte{0x1A, 0x2B}, false},
+		{"prefix_upper", "0X1a2b", []byte{0x1A, 0x2B}, false},
+		{"colons", "1A:2B:3C", []byte{0x1A, 0x2B, 0x3C}, false},
+		{"dashes", "1A-2B-3C", []byte{0x1A, 0x2B, 0x3C}, false},
+		{"spaces_and_newlines", "1a 2b\n3c", []byte{0x1A, 0x2B, 0x3C}, false},
+		{"tabs_and_crlf", "1a\t2b\r\n3c\r\n", []byte{0x1A, 0x2B, 0x3C}, false},
+		{"prefix_and_separators", " 0x1A:2B ", []byte{0x1A, 0x2B}, false},
+		{"odd_after_cleaning", "0x1A:2", nil, true},
+		{"invalid_char", "1A:2G", nil, true},
+		{"prefix_only", "0x", nil, true},
+		{"separators_only", ": - :", nil, true},
+		{"inner_prefix", "1A0x2B", nil, true},
+		{"double_prefix", "0x0x1A", nil, true},
+		{"comma_separated", "1A,2B", nil, true},
+		{"empty", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHexLenient(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHexLenient(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+
+			got, err := DecodeHexLenient(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodeHexLenient(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if string(got) != string(tt.want) {
+				t.Errorf("DecodeHexLenient(%q) = %x, want %x", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	// Strict validation keeps rejecting the same separator styles.
+	if err := ValidateHex("0x1A2B"); err == nil {
+		t.Error("ValidateHex(\"0x1A2B\") error = nil, want error")
+	}
+}
+
 func TestValidateKeyName(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -151,6 +200,12 @@ func TestValidateKeyName(t *testing.T) {
 			"AbcdefghijAbcdefghijAbcdefghijAbcdefghijAbcdefgh",
 			false,
 		}, // 50 chars.
+		{"valid_name_exactly_50", strings.Repeat("a", 50), false},
+		{"invalid_name_exactly_51", strings.Repeat("a", 51), true},
+		{"invalid_name_leading_hyphen", "-MyKey", true},
+		{"invalid_name_leading_underscore", "_MyKey", true},
+		{"valid_name_trailing_hyphen", "MyKey-", false},
+		{"invalid_name_space", "My Key", true},
 	}
 
 	for _, tt := range tests {
@@ -239,6 +294,48 @@ func TestValidateIPAddress(t *testing.T) {
 	}
 }
 
+func TestValidateHostOrIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid_ipv4", "192.168.1.1", false},
+		{"valid_ipv6", "2001:db8::1", false},
+		{"valid_ipv6_loopback", "::1", false},
+		{"single_label", "localhost", false},
+		{"single_label_digits_and_hyphen", "hsm-01", false},
+		{"fqdn", "hsm.example.com", false},
+		{"punycode", "xn--bcher-kva.example", false},
+		{"label_63_chars", strings.Repeat("a", 63) + ".com", false},
+		{"numeric_inner_label", "10.hsm.local", false},
+		{"empty_string", "", true},
+		{"trailing_dot", "hsm.example.com.", true},
+		{"leading_dot", ".example.com", true},
+		{"double_dot", "hsm..example.com", true},
+		{"label_64_chars", strings.Repeat("a", 64) + ".com", true},
+		{"too_long", strings.Repeat("a.", 127) + "com", true},
+		{"leading_hyphen", "-hsm.example.com", true},
+		{"trailing_hyphen", "hsm-.example.com", true},
+		{"underscore", "hsm_01", true},
+		{"space", "hsm 01", true},
+		{"with_port", "localhost:1500", true},
+		{"url", "http://hsm", true},
+		{"bracketed_ipv6", "[::1]", true},
+		{"partial_ipv4", "192.168.1", true},
+		{"ipv4_out_of_range", "256.100.50.1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHostOrIP(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateHostOrIP(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestValidateNumericInput(t *testing.T) {
 	tests := []struct {
 		name    string